@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/board"
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// listAllBoards prints the tasks of every board, prefixed with the board name
+func listAllBoards(status task.Status, context string, blocked, unblocked, stale bool, filter *task.Filter) {
+	tasks, err := newBoardSystem().ListAllTasks(status)
+	if err != nil {
+		fmt.Printf("Error listing tasks: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	now := time.Now()
+	var filtered []board.BoardTask
+	for _, t := range tasks {
+		if context != "" && !t.HasContext(context) {
+			continue
+		}
+		if (blocked || unblocked) && t.IsBlocked() != blocked {
+			continue
+		}
+		if stale && !t.IsStale(now, staleAfter()) {
+			continue
+		}
+		if !filter.Match(t.Task, now) {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	tasks = filtered
+
+	if cfg.OutputFormat == config.FormatJSON {
+		if tasks == nil {
+			tasks = []board.BoardTask{}
+		}
+		printJSON(map[string]interface{}{"tasks": tasks})
+		return
+	}
+
+	if len(tasks) == 0 {
+		fmt.Println("No tasks found on any board")
+		return
+	}
+
+	fmt.Println("Tasks across all boards:")
+	for _, t := range tasks {
+		fmt.Printf("  %s:#%d%s [%s] %s%s%s%s%s\n", t.Board, t.ID, formatPriority(t.Priority), t.Status, t.Title,
+			formatAssignee(t.Assignee), formatContexts(t.Contexts), formatStale(t.Task), formatBlocked(t.Task))
+	}
+}
+
+// searchAllBoards prints the best title matches across every board
+func searchAllBoards(query string) {
+	matches, err := newBoardSystem().SearchAllBoards(query)
+	if err != nil {
+		fmt.Printf("Error searching tasks: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		if matches == nil {
+			matches = []board.BoardTask{}
+		}
+		printJSON(map[string]interface{}{"query": query, "tasks": matches})
+		return
+	}
+
+	if len(matches) == 0 {
+		fmt.Printf("No tasks found matching '%s' on any board\n", query)
+		return
+	}
+
+	fmt.Printf("Search results for '%s' across all boards:\n\n", query)
+	for i, t := range matches {
+		if i >= 10 { // Limit to top 10 results
+			fmt.Printf("... and %d more matches\n", len(matches)-10)
+			break
+		}
+		fmt.Printf("  %s:#%d%s [%s] %s\n", t.Board, t.ID, formatPriority(t.Priority), t.Status, t.Title)
+		if t.Description != "" {
+			fmt.Printf("      %s\n", strings.TrimSpace(t.Description))
+		}
+	}
+}
+
+// formatPriority renders a priority suffix for list output
+func formatPriority(priority int) string {
+	if priority <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", task.GetPriorityName(priority))
+}
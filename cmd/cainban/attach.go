@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// handleAttach attaches files and URLs to a task, removes them with
+// --remove, or lists them when none are given
+func handleAttach(args []string) {
+	fs := newFlagSet("attach")
+	remove := fs.Bool("remove", false, "remove the attachments instead of adding them")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+
+	if len(args) == 0 || (*remove && len(args) < 2) {
+		fmt.Println("Usage: cainban attach <id|title> [path|url...] [--remove] [--exact]")
+		fmt.Println("Examples:")
+		fmt.Println("  cainban attach 12 src/parser.go src/parser_test.go")
+		fmt.Println("  cainban attach 12 https://github.com/hmain/cainban/issues/7")
+		fmt.Println("  cainban attach 12 src/parser.go --remove")
+		fmt.Println("  cainban attach 12                 # list the attachments")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	for _, target := range args[1:] {
+		target = attachmentTarget(target)
+		if *remove {
+			removed, err := taskSystem.Detach(foundTask.ID, target)
+			if err != nil {
+				fmt.Printf("Error removing attachment: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if removed {
+				fmt.Printf("Removed %s from task #%d\n", target, foundTask.ID)
+			} else {
+				fmt.Printf("%s is not attached to task #%d\n", target, foundTask.ID)
+			}
+			continue
+		}
+
+		added, err := taskSystem.Attach(foundTask.ID, target)
+		if err != nil {
+			fmt.Printf("Error adding attachment: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if added {
+			fmt.Printf("Attached %s to task #%d \"%s\" in board '%s'\n", target, foundTask.ID, foundTask.Title, boardName)
+		} else {
+			fmt.Printf("%s is already attached to task #%d\n", target, foundTask.ID)
+		}
+	}
+	if len(args) > 1 {
+		return
+	}
+
+	attachments, err := taskSystem.ListAttachments(foundTask.ID)
+	if err != nil {
+		fmt.Printf("Error loading attachments: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "task_id": foundTask.ID, "attachments": attachments})
+		return
+	}
+
+	fmt.Printf("Attachments of task #%d \"%s\":\n", foundTask.ID, foundTask.Title)
+	if len(attachments) == 0 {
+		fmt.Println("  (none yet)")
+		return
+	}
+	for _, a := range attachments {
+		fmt.Printf("  %s\n", formatAttachment(a))
+	}
+}
+
+// attachmentTarget makes a file path absolute, so the attachment opens
+// from wherever cainban runs; URLs are kept as they are
+func attachmentTarget(target string) string {
+	if task.IsURL(target) {
+		return target
+	}
+	if abs, err := filepath.Abs(target); err == nil {
+		return abs
+	}
+	return target
+}
+
+// formatAttachment shows an attachment, marking files that are gone
+func formatAttachment(a task.Attachment) string {
+	if !a.IsURL() {
+		if _, err := os.Stat(a.Target); os.IsNotExist(err) {
+			return a.Target + " (missing)"
+		}
+	}
+	return a.Target
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/sandbox"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func handleAutomation(args []string) {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	command := args[0]
+	args = args[1:]
+	if command != "list" && command != "add" && command != "remove" && command != "rules" && command != "run" && command != "log" {
+		fmt.Printf("Unknown automation command: %s\n", command)
+		printAutomationUsage()
+		os.Exit(exitUsage)
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	automationSystem := automation.New(db.Conn())
+
+	switch command {
+	case "list":
+		automations, err := automationSystem.List()
+		if err != nil {
+			fmt.Printf("Error listing automations: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "automations": automations})
+			return
+		}
+
+		if len(automations) == 0 {
+			fmt.Printf("No automations in board '%s'\n", boardName)
+			fmt.Println("Add one with: cainban automation add <status> --webhook <url> | --command <cmd>")
+			return
+		}
+
+		fmt.Printf("Automations in board '%s':\n", boardName)
+		for _, a := range automations {
+			fmt.Printf("  #%d on entering %s: %s %s\n", a.ID, a.Status, a.Kind, a.Target)
+		}
+
+	case "add":
+		fs := newFlagSet("automation add")
+		webhook := fs.String("webhook", "", "URL to POST the task to")
+		command := fs.String("command", "", "shell command to run, given the task as JSON on stdin")
+		args = parseFlags(fs, args)
+		if len(args) < 1 || (*webhook == "") == (*command == "") {
+			printAutomationUsage()
+			os.Exit(exitUsage)
+		}
+
+		// The words after the command are part of it, as if it were quoted
+		kind, target := automation.KindWebhook, *webhook
+		if *command != "" {
+			kind, target = automation.KindCommand, strings.Join(append([]string{*command}, args[1:]...), " ")
+		} else if len(args) > 1 {
+			usageError("unknown argument '%s'", args[1])
+		}
+		a, err := automationSystem.Add(task.Status(args[0]), kind, target)
+		if err != nil {
+			fmt.Printf("Error adding automation: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		fmt.Printf("Added automation #%d to board '%s': tasks entering %s run %s %s\n", a.ID, boardName, a.Status, a.Kind, a.Target)
+
+	case "remove":
+		if len(args) < 1 {
+			printAutomationUsage()
+			os.Exit(exitUsage)
+		}
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Printf("Error: invalid automation ID '%s'\n", args[0])
+			os.Exit(exitInvalid)
+		}
+
+		if err := automationSystem.Remove(id); err != nil {
+			fmt.Printf("Error removing automation: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Removed automation #%d from board '%s'\n", id, boardName)
+
+	case "rules":
+		path := automation.RulesPath(db.Path())
+		rules, err := automation.LoadRules(path)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "path": path, "rules": rules})
+			return
+		}
+
+		if len(rules) == 0 {
+			fmt.Printf("No rules for board '%s'\n", boardName)
+			fmt.Printf("Add [rules.<name>] sections to %s\n", path)
+			return
+		}
+
+		fmt.Printf("Rules for board '%s' (%s):\n", boardName, path)
+		for _, r := range rules {
+			fmt.Printf("  %s: %s\n", r.Name, describeRule(r))
+		}
+
+	case "log":
+		fs := newFlagSet("automation log")
+		limit := fs.Int("limit", 20, "")
+		if len(parseFlags(fs, args)) > 0 || *limit < 1 {
+			printAutomationUsage()
+			os.Exit(exitUsage)
+		}
+
+		runs, err := automationSystem.Runs(*limit)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "runs": runs})
+			return
+		}
+
+		if len(runs) == 0 {
+			fmt.Printf("No commands have run in board '%s'\n", boardName)
+			return
+		}
+		for _, r := range runs {
+			outcome := fmt.Sprintf("exit %d", r.ExitCode)
+			if r.ExitCode == -1 {
+				outcome = r.Error
+			}
+			fmt.Printf("%s  %s for task #%d: %s (%s, %dms)\n", r.RanAt.Local().Format("2006-01-02 15:04:05"), r.Rule, r.TaskID, r.Command, outcome, r.Duration)
+			if output := strings.TrimRight(r.Output, "\n"); output != "" {
+				fmt.Println("    " + strings.ReplaceAll(output, "\n", "\n    "))
+			}
+		}
+
+	case "run":
+		if fired := runAutomations(db, boardName); fired == 0 {
+			fmt.Println("No pending task events to run automations for")
+		}
+	}
+}
+
+func printAutomationUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cainban automation list")
+	fmt.Println("  cainban automation add <status> --webhook <url>")
+	fmt.Println("  cainban automation add <status> --command <shell command>")
+	fmt.Println("  cainban automation remove <id>")
+	fmt.Println("  cainban automation rules      Show the rules from the board's rules file")
+	fmt.Println("  cainban automation run")
+	fmt.Println("  cainban automation log [--limit <n>]  Show what the latest commands did, with their output")
+}
+
+// describeRule renders a rule as "on moved to done @deploy: webhook <url>"
+func describeRule(r automation.Rule) string {
+	parts := []string{"on " + r.On}
+	if r.From != "" {
+		parts = append(parts, "from "+string(r.From))
+	}
+	if r.To != "" {
+		parts = append(parts, "to "+string(r.To))
+	}
+	if r.Priority > 0 {
+		parts = append(parts, "priority >= "+task.GetPriorityName(r.Priority))
+	}
+	if r.Assignee != "" {
+		parts = append(parts, "assigned to "+r.Assignee)
+	}
+	if r.Context != "" {
+		parts = append(parts, r.Context)
+	}
+	if r.Title != "" {
+		parts = append(parts, fmt.Sprintf("title ~ %q", r.Title))
+	}
+	return fmt.Sprintf("%s: %s %s", strings.Join(parts, " "), r.Action, r.Target)
+}
+
+// newAutomationSystem returns the automation system of a board with the
+// rules from its rules file. A broken rules file is reported and skipped so
+// it never blocks the change that triggered the automations.
+func newAutomationSystem(db *storage.DB, boardName string) *automation.System {
+	automationSystem := automation.New(db.Conn())
+	// The automations of a remote board come with it from the remote
+	if r, _ := newBoardSystem().Remote(boardName); r != nil && !r.Trusted {
+		automationSystem.SkipExternal()
+	}
+	limits := automation.DefaultLimits()
+	limits.Timeout, limits.MemoryMB = cfg.CommandTimeout, cfg.CommandMemoryMB
+	automationSystem.SetLimits(limits)
+	if err := automationSystem.UseRulesFile(automation.RulesPath(db.Path())); err != nil {
+		fmt.Printf("Warning: ignoring rules: %v\n", err)
+	}
+	return automationSystem
+}
+
+// runAutomations fires the board's automations and rules for the task
+// events since they last ran and reports the outcome. Failures are only
+// warnings: the change itself has already happened. It returns the number
+// of actions fired.
+func runAutomations(db *storage.DB, boardName string) int {
+	// Experiments in a sandbox do not post webhooks or run commands; the
+	// automations fire once the changes are applied to the board
+	if sandbox.IsSandbox(db.Path()) {
+		return 0
+	}
+	firings, err := newAutomationSystem(db, boardName).Run(boardName)
+	for _, f := range firings {
+		if f.Err != nil {
+			fmt.Printf("Warning: %s (%s) failed for task #%d: %v\n", f.Rule.Name, f.Rule.Action, f.TaskID, f.Err)
+		} else {
+			fmt.Printf("Ran %s (%s) for task #%d\n", f.Rule.Name, f.Rule.Action, f.TaskID)
+		}
+	}
+	if err != nil {
+		fmt.Printf("Warning: failed to run automations: %v\n", err)
+	}
+	return len(firings)
+}
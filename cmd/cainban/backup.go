@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// handleBackup copies the current board, while it stays in use, to a
+// timestamped file in the backups directory or to --output
+func handleBackup(args []string) {
+	fs := newFlagSet("backup")
+	output := fs.String("output", "", "file to write the backup to (default: a timestamped file in the profile's backups directory)")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		usageError("Usage: cainban backup [--output <file>]")
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	path := *output
+	if path == "" {
+		path, err = backupBoard(db, boardName)
+	} else {
+		err = db.Backup(path)
+	}
+	if err != nil {
+		fmt.Printf("Error backing up board: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "path": path})
+		return
+	}
+	fmt.Printf("Backed up board '%s' to %s\n", boardName, path)
+	fmt.Printf("Restore it with: cainban restore %s\n", path)
+}
+
+// handleRestoreBackup replaces the current board with a backup, after
+// backing up what it replaces
+func handleRestoreBackup(path string) {
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		fmt.Printf("Error: %s is not a backup file\n", path)
+		os.Exit(exitNotFound)
+	}
+	if _, err := storage.ReadBoard(path); err != nil {
+		fmt.Printf("Error: %s: %v\n", path, err)
+		os.Exit(exitCode(err))
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	backupBefore(db, boardName, "restoring")
+	if err := db.RestoreFrom(path); err != nil {
+		fmt.Printf("Error restoring backup: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Restored board '%s' from %s\n", boardName, path)
+}
+
+// backupBoard takes a timestamped backup of a board in the profile's
+// backups directory, then prunes that board's backups to keep_backups
+func backupBoard(db *storage.DB, boardName string) (string, error) {
+	boardSystem := newBoardSystem()
+	path := boardSystem.BackupPath(boardName, time.Now())
+	if err := db.Backup(path); err != nil {
+		return "", err
+	}
+
+	if cfg.KeepBackups > 0 {
+		if _, err := boardSystem.PruneBackups(boardName, cfg.KeepBackups); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	return path, nil
+}
+
+// backupBefore backs up a board ahead of an operation that cannot be
+// undone, when auto_backup is on. The operation does not go ahead without
+// its backup. The path goes to stderr so it never mixes with JSON output.
+func backupBefore(db *storage.DB, boardName, operation string) {
+	if !cfg.AutoBackup {
+		return
+	}
+	path, err := backupBoard(db, boardName)
+	if err != nil {
+		fmt.Printf("Error backing up board '%s' before %s: %v\n", boardName, operation, err)
+		fmt.Println("Set auto_backup = false in config.toml to go ahead without a backup")
+		os.Exit(exitCode(err))
+	}
+	fmt.Fprintf(os.Stderr, "Backed up board '%s' to %s\n", boardName, path)
+}
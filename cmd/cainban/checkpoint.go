@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/report"
+)
+
+func handleContext(args []string) {
+	// Without a subcommand, export the board for an LLM
+	if len(args) == 0 || strings.HasPrefix(args[0], "--") {
+		handleContextExport(args)
+		return
+	}
+	fs := newFlagSet("context")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 2 {
+		printContextUsage()
+		os.Exit(exitUsage)
+	}
+
+	command, identifier := args[0], args[1]
+	args = args[2:]
+	if command != "set" && command != "get" && command != "clear" {
+		fmt.Printf("Unknown context command: %s\n", command)
+		printContextUsage()
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, identifier, *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	switch command {
+	case "set":
+		content, appendMode, err := readContextArgs(args)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		save := taskSystem.SetCheckpoint
+		if appendMode {
+			save = taskSystem.AppendCheckpoint
+		}
+		checkpoint, err := save(foundTask.ID, content)
+		if err != nil {
+			fmt.Printf("Error saving context: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		fmt.Printf("Saved context for task #%d \"%s\" in board '%s' (%d bytes)\n",
+			foundTask.ID, foundTask.Title, boardName, len(checkpoint.Content))
+
+	case "get":
+		checkpoint, err := taskSystem.GetCheckpoint(foundTask.ID)
+		if err != nil {
+			fmt.Printf("Error loading context: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "task": foundTask, "context": checkpoint})
+			return
+		}
+
+		if checkpoint == nil {
+			fmt.Fprintf(os.Stderr, "No context stored for task #%d \"%s\"\n", foundTask.ID, foundTask.Title)
+			return
+		}
+		// Print the raw content so it can be piped back into a file
+		fmt.Print(checkpoint.Content)
+		if !strings.HasSuffix(checkpoint.Content, "\n") {
+			fmt.Println()
+		}
+
+	case "clear":
+		if err := taskSystem.ClearCheckpoint(foundTask.ID); err != nil {
+			fmt.Printf("Error clearing context: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Cleared context for task #%d \"%s\" in board '%s'\n", foundTask.ID, foundTask.Title, boardName)
+	}
+}
+
+// handleContextExport prints the board as Markdown sized for an LLM context
+// window: `cainban context [--max-tokens N]`
+func handleContextExport(args []string) {
+	fs := newFlagSet("context")
+	maxTokensFlag := fs.Int("max-tokens", 0, "token budget of the export (default: no limit)")
+	if rest := parseFlags(fs, args); len(rest) > 0 {
+		fmt.Printf("Error: unknown argument '%s'\n", rest[0])
+		printContextUsage()
+		os.Exit(exitUsage)
+	}
+	maxTokens := *maxTokensFlag
+	if maxTokens < 0 {
+		fmt.Printf("Error: invalid token budget '%d'\n", maxTokens)
+		os.Exit(exitInvalid)
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	boardContext, err := report.New(db.Conn()).Context(boardName, 1, maxTokens)
+	if err != nil {
+		fmt.Printf("Error exporting board: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(boardContext)
+		return
+	}
+
+	fmt.Print(boardContext.Text)
+	if boardContext.Omitted > 0 {
+		fmt.Fprintf(os.Stderr, "~%d tokens, %d tasks omitted\n", boardContext.Tokens, boardContext.Omitted)
+	}
+}
+
+func printContextUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cainban context [--max-tokens <n>]")
+	fmt.Println("  cainban context set <id|title> [--append] [--exact] (--file <path|-> | <text>)")
+	fmt.Println("  cainban context get <id|title> [--exact]")
+	fmt.Println("  cainban context clear <id|title> [--exact]")
+}
+
+// readContextArgs reads the content for `context set` from --file or the
+// remaining arguments, and whether --append was given
+func readContextArgs(args []string) (string, bool, error) {
+	fs := newFlagSet("context set")
+	appendFlag := fs.Bool("append", false, "add to the context instead of replacing it")
+	fileFlag := fs.String("file", "", "read the context from a file, or - for stdin")
+	fs.StringVar(fileFlag, "f", "", "short for --file")
+	words := parseFlags(fs, args)
+	appendMode, path := *appendFlag, *fileFlag
+
+	if path != "" && len(words) > 0 {
+		return "", false, fmt.Errorf("give either --file or inline text, not both")
+	}
+	if path == "" {
+		if len(words) == 0 {
+			return "", false, fmt.Errorf("context content required (inline text or --file)")
+		}
+		return strings.Join(words, " "), appendMode, nil
+	}
+
+	input, err := openInput(path)
+	if err != nil {
+		return "", false, err
+	}
+	defer input.Close()
+
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return string(data), appendMode, nil
+}
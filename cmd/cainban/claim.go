@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// handleClaim claims a task for the user, or the agent named by
+// CAINBAN_USER, moving it to doing
+func handleClaim(args []string) {
+	fs := newFlagSet("claim")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fmt.Println("Error: task ID or title required")
+		fmt.Println("Usage: cainban claim <id|title> [--exact]")
+		fmt.Println("Example:")
+		fmt.Println("  CAINBAN_USER=agent-2 cainban claim 12")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	// Claiming a task starts it, which the move hooks get to check
+	move := automation.HookEvent{Hook: automation.HookPreMove, Board: boardName, Task: foundTask, FromStatus: foundTask.Status, ToStatus: task.StatusDoing}
+	moves := foundTask.Status != task.StatusDoing && foundTask.InProgressBy == ""
+	if moves {
+		runPreHook(db, move)
+	}
+
+	claimed, err := taskSystem.Claim(foundTask.ID)
+	if err != nil {
+		fmt.Printf("Error claiming task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "task": claimed})
+	} else {
+		fmt.Printf("Claimed task #%d \"%s\" as %s in board '%s'\n", claimed.ID, claimed.Title, claimed.InProgressBy, boardName)
+	}
+	if moves {
+		move.Hook, move.Task = automation.HookPostMove, claimed
+		runPostHook(db, move)
+	}
+	runAutomations(db, boardName)
+}
+
+// handleRelease gives up the user's claim on a task, or with --force
+// anyone's
+func handleRelease(args []string) {
+	fs := newFlagSet("release")
+	force := fs.Bool("force", false, "release a task claimed by someone else, e.g. an agent that stopped")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fmt.Println("Error: task ID or title required")
+		fmt.Println("Usage: cainban release <id|title> [--force] [--exact]")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	released, err := taskSystem.Release(foundTask.ID, *force)
+	if err != nil {
+		fmt.Printf("Error releasing task: %v\n", err)
+		if !*force {
+			fmt.Println("Use --force to release it anyway")
+		}
+		os.Exit(exitCode(err))
+	}
+
+	switch {
+	case cfg.OutputFormat == config.FormatJSON:
+		printJSON(map[string]interface{}{"board": boardName, "task_id": foundTask.ID, "released": released})
+	case released:
+		fmt.Printf("Released task #%d \"%s\" in board '%s'\n", foundTask.ID, foundTask.Title, boardName)
+	default:
+		fmt.Printf("Task #%d \"%s\" was not claimed\n", foundTask.ID, foundTask.Title)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func handleColumn(args []string) {
+	if len(args) == 0 {
+		args = []string{"show"}
+	}
+
+	command := args[0]
+	args = args[1:]
+	if command != "show" && command != "set" && command != "clear" {
+		fmt.Printf("Unknown column command: %s\n", command)
+		printColumnUsage()
+		os.Exit(exitUsage)
+	}
+	if (command == "set" && len(args) < 2) || (command == "clear" && len(args) != 1) {
+		printColumnUsage()
+		os.Exit(exitUsage)
+	}
+
+	statuses := task.ValidStatuses()
+	if len(args) > 0 {
+		if !task.IsValidStatus(args[0]) {
+			fmt.Printf("Error: invalid status '%s'. Valid statuses: todo, doing, done\n", args[0])
+			os.Exit(exitInvalid)
+		}
+		statuses = []task.Status{task.Status(args[0])}
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	switch command {
+	case "set":
+		if _, err := taskSystem.SetColumnNote(statuses[0], strings.Join(args[1:], " ")); err != nil {
+			fmt.Printf("Error saving column note: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Saved the definition of %s in board '%s'\n", statuses[0], boardName)
+
+	case "clear":
+		if _, err := taskSystem.SetColumnNote(statuses[0], ""); err != nil {
+			fmt.Printf("Error clearing column note: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Cleared the definition of %s in board '%s'\n", statuses[0], boardName)
+
+	case "show":
+		notes, err := taskSystem.ColumnNotes()
+		if err != nil {
+			fmt.Printf("Error loading column notes: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			columns := make([]map[string]interface{}, len(statuses))
+			for i, status := range statuses {
+				columns[i] = map[string]interface{}{"status": status, "note": notes[status].Note}
+			}
+			printJSON(map[string]interface{}{"board": boardName, "columns": columns})
+			return
+		}
+
+		fmt.Printf("Board: %s\n", boardName)
+		for _, status := range statuses {
+			fmt.Printf("\n%s:\n", strings.ToUpper(string(status)))
+			note, ok := notes[status]
+			if !ok {
+				fmt.Printf("  (no definition; set one with: cainban column set %s <note>)\n", status)
+				continue
+			}
+			for _, line := range strings.Split(note.Note, "\n") {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+	}
+}
+
+func printColumnUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cainban column show [status]          Show what each column means")
+	fmt.Println("  cainban column set <status> <note>    Define a column, e.g. the definition of done")
+	fmt.Println("  cainban column clear <status>         Remove a column definition")
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// The completion scripts ask `cainban __complete <words...>` for the
+// candidates of the last word, so commands, flags and live task IDs are
+// always those of the installed binary and the current board. Each
+// candidate is printed on its own line as value<TAB>description.
+
+const bashCompletion = `# bash completion for cainban
+_cainban() {
+    local IFS=$'\n'
+    COMPREPLY=($(cainban __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1))
+}
+complete -o default -F _cainban cainban
+`
+
+const zshCompletion = `#compdef cainban
+# zsh completion for cainban
+_cainban() {
+    local -a candidates
+    local line
+    for line in "${(@f)$(cainban __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}"; do
+        [[ -n $line ]] || continue
+        if [[ $line == *$'\t'* ]]; then
+            candidates+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+        else
+            candidates+=("${line//:/\\:}")
+        fi
+    done
+    if (( ${#candidates} )); then
+        _describe 'cainban' candidates
+    else
+        _files
+    fi
+}
+if [ "$funcstack[1]" = "_cainban" ]; then
+    _cainban "$@"
+else
+    compdef _cainban cainban
+fi
+`
+
+const fishCompletion = `# fish completion for cainban
+function __cainban_complete
+    set -l tokens (commandline -opc)
+    set -l current (commandline -ct)
+    cainban __complete $tokens[2..-1] "$current" 2>/dev/null
+end
+complete -c cainban -f -a '(__cainban_complete)'
+`
+
+func handleCompletion(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: cainban completion <bash|zsh|fish>")
+		fmt.Println("Examples:")
+		fmt.Println("  source <(cainban completion bash)                           # in ~/.bashrc")
+		fmt.Println("  source <(cainban completion zsh)                            # in ~/.zshrc")
+		fmt.Println("  cainban completion fish > ~/.config/fish/completions/cainban.fish")
+		os.Exit(exitUsage)
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion)
+	case "zsh":
+		fmt.Print(zshCompletion)
+	case "fish":
+		fmt.Print(fishCompletion)
+	default:
+		usageError("unsupported shell '%s' (use bash, zsh or fish)", args[0])
+	}
+}
+
+// handleComplete prints the completions of the last of the words typed
+// after "cainban". It is run by the completion scripts and stays silent
+// when there is nothing to offer.
+func handleComplete(words []string) {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	current := words[len(words)-1]
+
+	var candidates []completion
+	if len(words) == 1 {
+		candidates = commandCompletions()
+	} else {
+		candidates = argumentCompletions(words[0], words[1:len(words)-1], current)
+	}
+
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if seen[c.value] || !strings.HasPrefix(c.value, current) {
+			continue
+		}
+		seen[c.value] = true
+		if c.description != "" {
+			fmt.Printf("%s\t%s\n", c.value, c.description)
+		} else {
+			fmt.Println(c.value)
+		}
+	}
+}
+
+// completion is one candidate for the word being completed
+type completion struct {
+	value       string
+	description string
+}
+
+// synopsis is the command line of one usage line, split into words, with
+// the description of the line
+type synopsis struct {
+	words       []string
+	description string
+}
+
+// synopses returns the usage lines of a command, or of every command when
+// command is empty
+func synopses(command string) []synopsis {
+	var result []synopsis
+	for _, line := range strings.Split(usage, "\n") {
+		rest, ok := strings.CutPrefix(line, "  cainban ")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if command != "" && fields[0] != command {
+			continue
+		}
+
+		// The description starts at the first capitalized word
+		n := 1
+		for n < len(fields) && !unicode.IsUpper([]rune(fields[n])[0]) {
+			n++
+		}
+		result = append(result, synopsis{words: fields[:n], description: strings.Join(fields[n:], " ")})
+	}
+	return result
+}
+
+// commandCompletions lists every command with the description of its
+// first usage line
+func commandCompletions() []completion {
+	var candidates []completion
+	for _, s := range synopses("") {
+		candidates = append(candidates, completion{s.words[0], s.description})
+	}
+	return candidates
+}
+
+// flagName finds the flags in a usage word, such as "[--blocked|--unblocked]"
+var flagName = regexp.MustCompile(`--[a-z-]+`)
+
+// flagValue matches a flag followed by its value in a usage line, such as
+// "[--priority <level>]" or "[--format csv|json]"
+var flagValue = regexp.MustCompile(`^\[?(--[a-z-]+)$`)
+
+// argumentCompletions completes the argument after the given ones, from
+// the placeholders at that position in the command's usage lines
+func argumentCompletions(command string, args []string, current string) []completion {
+	lines := synopses(command)
+	if command == "help" {
+		return commandCompletions()
+	}
+
+	// Flags, and the value of the flag before the word being completed
+	var candidates []completion
+	for _, s := range lines {
+		for i, word := range s.words[1:] {
+			if strings.HasPrefix(current, "-") {
+				for _, flag := range flagName.FindAllString(word, -1) {
+					candidates = append(candidates, completion{value: flag})
+				}
+				continue
+			}
+			m := flagValue.FindStringSubmatch(word)
+			if m != nil && len(args) > 0 && args[len(args)-1] == m[1] && takesValue(lines, m[1]) {
+				return placeholderCompletions(command, strings.TrimSuffix(s.words[i+2], "]"))
+			}
+		}
+	}
+	if strings.HasPrefix(current, "-") {
+		return candidates
+	}
+
+	// The positional arguments typed so far, without flags and their values
+	var positional []string
+	for i := 0; i < len(args); i++ {
+		if strings.HasPrefix(args[i], "-") {
+			if takesValue(lines, args[i]) {
+				i++
+			}
+			continue
+		}
+		positional = append(positional, args[i])
+	}
+
+	for _, s := range lines {
+		var words []string
+		for i := 1; i < len(s.words); i++ {
+			if flagValue.MatchString(s.words[i]) || strings.HasPrefix(s.words[i], "[--") {
+				if takesValue(lines, strings.Trim(s.words[i], "[]")) {
+					i++
+				}
+				continue
+			}
+			words = append(words, s.words[i])
+		}
+		if len(words) <= len(positional) || !matchesLiterals(words, positional) {
+			continue
+		}
+		candidates = append(candidates, placeholderCompletions(command, words[len(positional)])...)
+	}
+	return candidates
+}
+
+// takesValue reports whether a flag is followed by a value in any of the
+// usage lines
+func takesValue(lines []synopsis, flag string) bool {
+	for _, s := range lines {
+		for i, word := range s.words {
+			if m := flagValue.FindStringSubmatch(word); m != nil && m[1] == flag {
+				return i+1 < len(s.words) && !strings.HasPrefix(strings.Trim(s.words[i+1], "[]"), "-")
+			}
+		}
+	}
+	return false
+}
+
+// matchesLiterals reports whether the typed arguments agree with the
+// subcommand words of a usage line, such as "switch" in "board switch"
+func matchesLiterals(words, positional []string) bool {
+	for i, arg := range positional {
+		if isLiteral(words[i]) && words[i] != arg {
+			return false
+		}
+	}
+	return true
+}
+
+// isLiteral reports whether a usage word is typed as it is
+func isLiteral(word string) bool {
+	return !strings.ContainsAny(word, "<>[]|\"")
+}
+
+// valueNames are placeholders that stand for a value rather than offering
+// their name as a choice, as "when" in "<when|none>"
+var valueNames = map[string]bool{
+	"title": true, "when": true, "text": true, "path": true, "-": true,
+}
+
+// placeholderCompletions completes a usage word: a subcommand, a choice
+// such as <daily|weekly|none>, or a placeholder such as <id|title>, <status>
+// or <level>
+func placeholderCompletions(command, word string) []completion {
+	if isLiteral(word) {
+		return []completion{{value: word}}
+	}
+
+	inner := strings.Trim(word, "[]<>\".")
+	alternatives := strings.Split(inner, "|")
+
+	var candidates []completion
+	for _, alt := range alternatives {
+		switch {
+		case alt == "id" || alt == "task_id" || alt == "from_id" || alt == "to_id" || alt == "parent":
+			candidates = append(candidates, taskCompletions()...)
+		case alt == "status":
+			for _, s := range []task.Status{task.StatusTodo, task.StatusDoing, task.StatusDone} {
+				candidates = append(candidates, completion{value: string(s)})
+			}
+		case alt == "level":
+			for level := task.PriorityNone; level <= task.PriorityCritical; level++ {
+				candidates = append(candidates, completion{task.GetPriorityName(level), strconv.Itoa(level)})
+			}
+		case alt == "board-name" || alt == "name" && command == "board":
+			candidates = append(candidates, boardCompletions()...)
+		case alt == "@context":
+			candidates = append(candidates, contextCompletions()...)
+		case strings.HasPrefix(alt, "--"):
+			candidates = append(candidates, completion{value: alt})
+		case len(alternatives) > 1 && !valueNames[alt]:
+			candidates = append(candidates, completion{value: alt})
+		}
+	}
+	return candidates
+}
+
+// taskCompletions lists the tasks of the current board, unfinished ones
+// first, with their titles
+func taskCompletions() []completion {
+	db, taskSystem, _, err := getCurrentBoardDB()
+	if err != nil {
+		return nil
+	}
+	defer db.Close()
+
+	tasks, err := taskSystem.List(1)
+	if err != nil {
+		return nil
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Status != task.StatusDone && tasks[j].Status == task.StatusDone
+	})
+
+	candidates := make([]completion, len(tasks))
+	for i, t := range tasks {
+		candidates[i] = completion{strconv.Itoa(t.ID), fmt.Sprintf("[%s] %s", t.Status, t.Title)}
+	}
+	return candidates
+}
+
+// boardCompletions lists the boards with their descriptions
+func boardCompletions() []completion {
+	boards, err := newBoardSystem().ListBoards()
+	if err != nil {
+		return nil
+	}
+	candidates := make([]completion, len(boards))
+	for i, b := range boards {
+		candidates[i] = completion{b.Name, b.Description}
+	}
+	return candidates
+}
+
+// contextCompletions lists the GTD contexts in use on the current board
+func contextCompletions() []completion {
+	db, taskSystem, _, err := getCurrentBoardDB()
+	if err != nil {
+		return nil
+	}
+	defer db.Close()
+
+	contexts, err := taskSystem.ListContexts(1)
+	if err != nil {
+		return nil
+	}
+	candidates := make([]completion, len(contexts))
+	for i, c := range contexts {
+		candidates[i] = completion{c.Context, fmt.Sprintf("%d open tasks", c.Count)}
+	}
+	return candidates
+}
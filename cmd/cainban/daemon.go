@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/notify"
+	"github.com/hmain/cainban/src/systems/reminder"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func handleDaemon(args []string) {
+	fs := newFlagSet("daemon")
+	intervalFlag := fs.Duration("interval", time.Minute, "time between checks, e.g. 30s or 5m")
+	onceFlag := fs.Bool("once", false, "check once and exit")
+	if rest := parseFlags(fs, args); len(rest) > 0 {
+		fmt.Printf("Unknown daemon option: %s\n", rest[0])
+		fmt.Println("Usage: cainban daemon [--interval <duration>] [--once]")
+		os.Exit(exitUsage)
+	}
+
+	interval, once := *intervalFlag, *onceFlag
+	if interval < time.Second {
+		fmt.Printf("Error: invalid interval '%s'\n", interval)
+		os.Exit(exitInvalid)
+	}
+
+	notifier := notify.Desktop(os.Stdout)
+
+	if once {
+		if err := runDaemonPass(notifier, time.Now()); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	fmt.Printf("cainban daemon running, checking every %s (Ctrl+C to stop)\n", interval)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := runDaemonPass(notifier, time.Now()); err != nil {
+			// Keep running: a locked or missing board should not stop other reminders
+			fmt.Printf("Error: %v\n", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			fmt.Println("cainban daemon stopped")
+			return
+		}
+	}
+}
+
+// runDaemonPass delivers the due reminders of every board and runs the
+// automations for tasks moved in the TUI or by other tools
+func runDaemonPass(notifier notify.Notifier, now time.Time) error {
+	boards, err := newBoardSystem().ListBoards()
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, b := range boards {
+		if err := fireReminders(b.Path, b.Name, notifier, now); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("board '%s': %w", b.Name, err)
+		}
+		if err := fireAutomations(b.Path, b.Name); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("board '%s': %w", b.Name, err)
+		}
+	}
+	return firstErr
+}
+
+// fireReminders delivers and marks the due reminders of one board
+func fireReminders(dbPath, boardName string, notifier notify.Notifier, now time.Time) error {
+	db, err := storage.New(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	reminderSystem := reminder.New(db.Conn())
+	due, err := reminderSystem.Due(now)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range due {
+		title := fmt.Sprintf("Reminder: #%d %s", r.TaskID, r.TaskTitle)
+		if boardName != "default" {
+			title += fmt.Sprintf(" (%s)", boardName)
+		}
+		if err := notifier.Notify(title, r.Note); err != nil {
+			return err
+		}
+		if err := reminderSystem.MarkFired(r.ID, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fireAutomations runs the automations of one board for tasks that changed
+// column since the last pass
+func fireAutomations(dbPath, boardName string) error {
+	db, err := storage.New(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	runAutomations(db, boardName)
+	return nil
+}
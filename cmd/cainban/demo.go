@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/demo"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func handleDemo(args []string) {
+	fs := newFlagSet("demo")
+	count := fs.Int("tasks", 50, "number of tasks to generate")
+	seed := fs.Int64("seed", 1, "random seed; the same seed generates the same board")
+	boardName := fs.String("board", "demo", "name of the board to fill")
+	replace := fs.Bool("replace", false, "delete and regenerate the board if it exists")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		usageError("unknown argument '%s'", args[0])
+	}
+	if *count < 1 {
+		usageError("--tasks must be at least 1")
+	}
+
+	boardSystem := newBoardSystem()
+	if *replace {
+		if _, err := boardSystem.GetBoard(*boardName); err == nil {
+			if _, err := boardSystem.DeleteBoard(*boardName); err != nil {
+				fmt.Printf("Error replacing board: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+		}
+	}
+
+	created, err := boardSystem.CreateBoard(*boardName, "Generated demo data")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		fmt.Println("Use --replace to regenerate it, or --board <name> for another board")
+		os.Exit(1)
+	}
+	db, err := storage.New(created.Path)
+	if err != nil {
+		fmt.Printf("Error initializing board database: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	start := time.Now()
+	summary, err := demo.Generate(db, demo.Options{Tasks: *count, Seed: *seed, Now: start})
+	if err != nil {
+		fmt.Printf("Error generating demo data: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Generated board '%s' in %s: %d tasks (%d subtasks), %d links, %d comments, %d reactions, %d history events\n",
+		*boardName, time.Since(start).Round(time.Millisecond), summary.Tasks, summary.Subtasks,
+		summary.Links, summary.Comments, summary.Reactions, summary.Events)
+	fmt.Printf("Switch to it with: cainban board switch %s\n", *boardName)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/intent"
+)
+
+func handleDo(args []string) {
+	fs := newFlagSet("do")
+	dryRun := fs.Bool("dry-run", false, "show what the command would do without doing it")
+	args = parseFlags(fs, args)
+	if len(args) == 0 {
+		printDoUsage()
+		os.Exit(exitUsage)
+	}
+
+	cmd, err := intent.Parse(strings.Join(args, " "))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		printDoUsage()
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(cmd)
+			return
+		}
+		fmt.Println(cmd)
+		return
+	}
+
+	// Keep stdout clean for JSON output
+	fmt.Fprintf(os.Stderr, "→ %s\n", cmd)
+
+	switch cmd.Name {
+	case "add":
+		handleAdd(cmd.Args)
+	case "move":
+		handleMove(cmd.Args)
+	case "priority":
+		handlePriority(cmd.Args)
+	case "assign":
+		handleAssign(cmd.Args)
+	case "due":
+		handleDue(cmd.Args)
+	case "list":
+		handleList(cmd.Args)
+	case "next":
+		handleNext(cmd.Args)
+	default:
+		fmt.Printf("Error: unsupported command '%s'\n", cmd.Name)
+		os.Exit(1)
+	}
+}
+
+func printDoUsage() {
+	fmt.Println("Usage: cainban do [--dry-run] \"<sentence>\"")
+	fmt.Println("Examples:")
+	fmt.Println("  cainban do \"add a high priority task to fix the login bug\"")
+	fmt.Println("  cainban do \"move the auth task to done\"")
+	fmt.Println("  cainban do \"start working on the docs task\"")
+	fmt.Println("  cainban do \"make the cache task urgent priority\"")
+	fmt.Println("  cainban do \"assign the auth task to alice\"")
+	fmt.Println("  cainban do \"the release task is due friday\"")
+	fmt.Println("  cainban do \"what should I work on next\"")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func handleDoctor(args []string) {
+	fs := newFlagSet("doctor")
+	fix := fs.Bool("fix", false, "adopt orphaned boards and clean up leftover files")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		usageError("unknown argument '%s'", args[0])
+	}
+
+	boardSystem := newBoardSystem()
+	problems, err := boardSystem.Diagnose()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if len(problems) == 0 {
+		fmt.Println("No problems found")
+		return
+	}
+
+	remaining, fixable := 0, 0
+	for _, p := range problems {
+		fmt.Printf("%s: %s\n", p.Path, p.Message)
+		switch {
+		case p.Fix == "":
+			fmt.Println("  needs fixing by hand")
+			remaining++
+		case !*fix:
+			fmt.Printf("  fix: %s\n", p.Fix)
+			remaining++
+			fixable++
+		default:
+			if err := boardSystem.Fix(p); err != nil {
+				fmt.Printf("  could not %s: %v\n", p.Fix, err)
+				remaining++
+				continue
+			}
+			fmt.Printf("  fixed: %s\n", p.Fix)
+		}
+	}
+
+	if fixable > 0 {
+		fmt.Printf("Run 'cainban doctor --fix' to fix %d of %d problems\n", fixable, len(problems))
+	}
+	if remaining > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// frontMatter fences the fields of a task edited as Markdown
+const frontMatter = "---"
+
+// handleEdit opens a task in the editor as Markdown, its title in the front
+// matter and its description below, and saves what changed
+func handleEdit(args []string) {
+	fs := newFlagSet("edit")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		usageError("Usage: cainban edit <id|title> [--exact]")
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	opts, changed := editTask(foundTask, boardName)
+	if !changed {
+		fmt.Printf("Task #%d unchanged\n", foundTask.ID)
+		return
+	}
+	if err := taskSystem.UpdateFields(foundTask.ID, opts); err != nil {
+		fmt.Printf("Error updating task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Updated task #%d in board '%s': %s\n", foundTask.ID, boardName, *opts.Title)
+}
+
+// editTask opens a task in the editor and returns its edited title and
+// description, and whether either changed. When the edited text cannot be
+// read back, it is kept in a file so the writing is not lost, and the
+// command exits.
+func editTask(t *task.Task, boardName string) (task.UpdateOptions, bool) {
+	edited, err := editText(formatTaskMarkdown(t, boardName), fmt.Sprintf("task-%d-*.md", t.ID))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	title, description, err := parseTaskMarkdown(edited)
+	if err == nil {
+		err = task.ValidateTitle(title)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		kept := filepath.Join(os.TempDir(), fmt.Sprintf("cainban-task-%d-unsaved.md", t.ID))
+		if os.WriteFile(kept, []byte(edited), 0600) == nil {
+			fmt.Printf("Your edit is kept in %s\n", kept)
+		}
+		os.Exit(exitCode(err))
+	}
+
+	changed := title != t.Title || description != t.Description
+	return task.UpdateOptions{Title: &title, Description: &description}, changed
+}
+
+// formatTaskMarkdown writes a task as Markdown with its title in YAML front
+// matter
+func formatTaskMarkdown(t *task.Task, boardName string) string {
+	var b strings.Builder
+	b.WriteString(frontMatter + "\n")
+	fmt.Fprintf(&b, "# Task #%d [%s] on board '%s'; save and quit to apply\n", t.ID, t.Status, boardName)
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(t.Title))
+	b.WriteString(frontMatter + "\n\n")
+	if t.Description != "" {
+		b.WriteString(t.Description + "\n")
+	}
+	return b.String()
+}
+
+// parseTaskMarkdown reads back the title and description of a task written
+// by formatTaskMarkdown
+func parseTaskMarkdown(text string) (title, description string, err error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	rest, ok := strings.CutPrefix(strings.TrimLeft(text, "\n"), frontMatter+"\n")
+	if !ok {
+		return "", "", storage.Errorf(task.ErrInvalidInput, "the front matter with the title is missing")
+	}
+	header, body, ok := strings.Cut(rest, "\n"+frontMatter+"\n")
+	if !ok {
+		// The closing fence may be the very last line
+		if header, ok = strings.CutSuffix(rest, "\n"+frontMatter); !ok {
+			return "", "", storage.Errorf(task.ErrInvalidInput, "the front matter is not closed with %s", frontMatter)
+		}
+	}
+
+	fields, err := config.ParseYAML(strings.NewReader(header))
+	if err != nil {
+		return "", "", storage.Errorf(task.ErrInvalidInput, "front matter: %v", err)
+	}
+	title, _ = fields["title"].(string)
+	return strings.TrimSpace(title), strings.Trim(body, "\n"), nil
+}
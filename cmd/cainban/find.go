@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// findTaskWith finds the task a command line refers to by ID, task number,
+// hash or title. Titles match fuzzily unless fuzzy_match is off or exact is
+// set, as for --exact; when several tasks match on a terminal, the user
+// picks one of them.
+func findTaskWith(taskSystem *task.System, ref string, exact bool) (*task.Task, error) {
+	var found *task.Task
+	var err error
+	if exact || !cfg.FuzzyMatch {
+		found, err = taskSystem.FindTaskExact(1, ref)
+	} else {
+		found, err = taskSystem.FindTaskByFuzzyID(1, ref)
+	}
+
+	var ambiguous *task.AmbiguousError
+	if errors.As(err, &ambiguous) && onTerminal(os.Stdin, os.Stdout) {
+		return pickMatch(ambiguous)
+	}
+	return found, err
+}
+
+// pickMatch asks which of the tasks a reference matched was meant. Giving
+// no answer leaves the reference ambiguous.
+func pickMatch(ambiguous *task.AmbiguousError) (*task.Task, error) {
+	fmt.Printf("Several tasks match '%s':\n", ambiguous.Query)
+	for i, match := range ambiguous.Matches {
+		fmt.Printf("  %d) #%d [%s] %s%s\n", i+1, match.ID, match.Status, match.Title, formatAssignee(match.Assignee))
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Printf("Pick 1-%d (Enter to cancel): ", len(ambiguous.Matches))
+		answer, err := reader.ReadString('\n')
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			return nil, ambiguous
+		}
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(ambiguous.Matches) {
+			return ambiguous.Matches[n-1], nil
+		}
+		if err != nil {
+			// Input ended without a valid answer
+			return nil, ambiguous
+		}
+	}
+}
+
+// taskIDByHash resolves a task hash given where a command takes a task ID,
+// exiting when no task has it
+func taskIDByHash(taskSystem *task.System, hash string) int {
+	found, err := taskSystem.GetByHash(hash)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	return found.ID
+}
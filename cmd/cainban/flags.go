@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Exit codes, following the flag package: a command used wrongly exits
+// with exitUsage so scripts can tell it apart from one that failed. A
+// failure of a known kind has a code of its own, so a script can tell a
+// task that does not exist from a reference that matches several.
+const (
+	exitFailure   = 1
+	exitUsage     = 2
+	exitNotFound  = 3
+	exitAmbiguous = 4
+	exitInvalid   = 5
+	exitConflict  = 6
+)
+
+// exitCode is the code to exit with after err
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, storage.ErrAmbiguous):
+		return exitAmbiguous
+	case errors.Is(err, storage.ErrInvalidInput):
+		return exitInvalid
+	case errors.Is(err, storage.ErrConflict):
+		return exitConflict
+	}
+	return exitFailure
+}
+
+// applyGlobalFlags takes --profile <name> and --board <name>, in either
+// order, off the front of the command line. They go through
+// config.ProfileEnv and config.BoardEnv, so commands cainban runs itself use
+// them too. Without the flags, both come from the environment.
+func applyGlobalFlags() {
+	for {
+		if name, ok := takeGlobalFlag("--profile"); ok {
+			os.Setenv(config.ProfileEnv, name)
+		} else if name, ok := takeGlobalFlag("--board"); ok {
+			os.Setenv(config.BoardEnv, name)
+		} else {
+			break
+		}
+	}
+	checkProfile()
+}
+
+// takeGlobalFlag takes flag and its value, given as "flag value" or
+// "flag=value", off the front of the command line
+func takeGlobalFlag(flag string) (string, bool) {
+	if len(os.Args) < 2 {
+		return "", false
+	}
+	switch arg := os.Args[1]; {
+	case arg == flag:
+		if len(os.Args) < 3 {
+			usageError("%s requires a name", flag)
+		}
+		value := os.Args[2]
+		os.Args = append(os.Args[:1], os.Args[3:]...)
+		return value, true
+	case strings.HasPrefix(arg, flag+"="):
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		return strings.TrimPrefix(arg, flag+"="), true
+	}
+	return "", false
+}
+
+// newFlagSet returns the flags of a command. When they cannot be parsed the
+// error is printed with the command's help and flags.
+func newFlagSet(command string) *flag.FlagSet {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	fs.Usage = func() {
+		printCommandHelp(command)
+		fmt.Println("Flags:")
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the flags of a command wherever they appear among its
+// arguments, not only before them, and returns the other arguments in
+// order. Everything after "--" is an argument. It exits with exitUsage on
+// an unknown flag or a missing value.
+func parseFlags(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				os.Exit(0)
+			}
+			os.Exit(exitUsage)
+		}
+
+		consumed := len(args) - fs.NArg()
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, fs.Args()...)
+		}
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// usageError prints what is wrong with a command line and exits with
+// exitUsage
+func usageError(format string, a ...interface{}) {
+	fmt.Printf("Error: "+format+"\n", a...)
+	os.Exit(exitUsage)
+}
+
+// wantsHelp reports whether a command was run with -h or --help
+func wantsHelp(args []string) bool {
+	for _, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == "-h" || arg == "--help" {
+			return true
+		}
+	}
+	return false
+}
+
+// printCommandHelp prints the usage lines of one command, or all of them
+// when the command has none
+func printCommandHelp(command string) {
+	prefix := "  cainban " + command
+	found := false
+	for _, line := range strings.Split(usage, "\n") {
+		if line == prefix || strings.HasPrefix(line, prefix+" ") {
+			if !found {
+				fmt.Println("Usage:")
+				found = true
+			}
+			fmt.Println(line)
+		}
+	}
+	if !found {
+		printUsage()
+	}
+}
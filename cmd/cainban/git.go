@@ -0,0 +1,280 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/git"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func handleGit(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Error: git command required")
+		fmt.Println("Usage: cainban git <command>")
+		fmt.Println("Commands: branch, log, sync, hook")
+		os.Exit(exitUsage)
+	}
+
+	repo, err := git.Open(".")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	switch args[0] {
+	case "branch":
+		handleGitBranch(repo, args[1:])
+	case "log":
+		handleGitLog(repo, args[1:])
+	case "sync":
+		handleGitSync(repo, args[1:])
+	case "hook":
+		path, err := repo.InstallHook(profileCommand("git sync --limit 1") + " >/dev/null 2>&1 || true")
+		if err != nil {
+			fmt.Printf("Error installing hook: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Installed post-commit hook at: %s\n", path)
+		fmt.Println("Commits mentioning cainban:#<id> are now linked automatically.")
+	default:
+		fmt.Printf("Unknown git command: %s\n", args[0])
+		fmt.Println("Commands: branch, log, sync, hook")
+		os.Exit(exitUsage)
+	}
+}
+
+func handleGitBranch(repo *git.Repo, args []string) {
+	fs := newFlagSet("git branch")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 1 {
+		fmt.Println("Error: task ID/title required")
+		fmt.Println("Usage: cainban git branch <id|title> [--exact]")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, strings.Join(args, " "), *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	branch := git.BranchName(foundTask.ID, foundTask.Title)
+	created, err := repo.CreateBranch(branch)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if _, err := git.New(db.Conn()).AddRef(foundTask.ID, git.RefBranch, branch, ""); err != nil {
+		fmt.Printf("Error linking branch: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if created {
+		fmt.Printf("Created branch %s for task #%d in board '%s'\n", branch, foundTask.ID, boardName)
+	} else {
+		fmt.Printf("Switched to existing branch %s for task #%d\n", branch, foundTask.ID)
+	}
+	fmt.Printf("Reference the task in commits with a trailer: cainban:#%d\n", foundTask.ID)
+}
+
+func handleGitLog(repo *git.Repo, args []string) {
+	fs := newFlagSet("git log")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args, limit := parseLimitFlag(fs, args, 0)
+
+	if len(args) < 1 {
+		fmt.Println("Error: task ID/title required")
+		fmt.Println("Usage: cainban git log <id|title> [--limit <n>] [--exact]")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, _, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, strings.Join(args, " "), *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	commits, err := repo.Log(limit)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Commits for task #%d: %s\n", foundTask.ID, foundTask.Title)
+
+	found := 0
+	for _, commit := range commits {
+		for _, ref := range git.ParseReferences(commit.Message()) {
+			if ref.TaskID != foundTask.ID {
+				continue
+			}
+			found++
+			marker := ""
+			if ref.Closes {
+				marker = " (closes)"
+			}
+			fmt.Printf("  %s %s %-15s %s%s\n", commit.ShortHash(), commit.Date.Format("2006-01-02"), commit.Author, commit.Subject, marker)
+		}
+	}
+
+	if found == 0 {
+		fmt.Printf("  No commits reference this task. Add a trailer: cainban:#%d\n", foundTask.ID)
+	}
+
+	refs, err := git.New(db.Conn()).ListRefs(foundTask.ID)
+	if err != nil {
+		fmt.Printf("Error listing references: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	for _, ref := range refs {
+		if ref.Kind == git.RefBranch {
+			fmt.Printf("Branch: %s\n", ref.Ref)
+		}
+	}
+}
+
+func handleGitSync(repo *git.Repo, args []string) {
+	rest, limit := parseLimitFlag(newFlagSet("git sync"), args, 50)
+	if len(rest) > 0 {
+		usageError("unknown argument '%s'", rest[0])
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	spin := startSpinner("Reading the git log")
+	commits, err := repo.Log(limit)
+	spin.Stop()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	refSystem := git.New(db.Conn())
+	linked, closed := 0, 0
+
+	// Walk oldest first so the board history follows commit order
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		for _, ref := range git.ParseReferences(commit.Message()) {
+			t, err := taskSystem.GetByID(ref.TaskID)
+			if err != nil {
+				continue // Refers to a task on another board or one that was deleted
+			}
+
+			added, err := refSystem.AddRef(t.ID, git.RefCommit, commit.Hash, commit.Subject)
+			if err != nil {
+				fmt.Printf("Error linking commit: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if added {
+				linked++
+				fmt.Printf("Linked %s to task #%d: %s\n", commit.ShortHash(), t.ID, commit.Subject)
+			}
+
+			// Only newly linked commits close tasks, so re-running sync
+			// does not undo a task that was reopened by hand
+			if added && ref.Closes && t.Status != task.StatusDone {
+				if err := taskSystem.UpdateStatus(t.ID, task.StatusDone); err != nil {
+					fmt.Printf("Error moving task: %v\n", err)
+					os.Exit(exitCode(err))
+				}
+				closed++
+				fmt.Printf("Moved task #%d \"%s\" to done (closed by %s)\n", t.ID, t.Title, commit.ShortHash())
+			}
+		}
+	}
+
+	fmt.Printf("Synced %d commits with board '%s': %d new links, %d tasks closed\n", len(commits), boardName, linked, closed)
+	if closed > 0 {
+		runAutomations(db, boardName)
+	}
+}
+
+// parseLimitFlag reads the flags in fs and --limit <n>, returning the
+// remaining args
+func parseLimitFlag(fs *flag.FlagSet, args []string, defaultLimit int) ([]string, int) {
+	limit := fs.Int("limit", defaultLimit, "most commits to read (0: all)")
+	rest := parseFlags(fs, args)
+	if *limit < 0 {
+		fmt.Printf("Error: invalid limit '%d'\n", *limit)
+		os.Exit(exitInvalid)
+	}
+	return rest, *limit
+}
+
+func handleEnrich(args []string) {
+	fs := newFlagSet("enrich")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 1 {
+		fmt.Println("Error: task ID/title required")
+		fmt.Println("Usage: cainban enrich <id|title> [--exact]")
+		fmt.Println("Appends a summary of the task's linked commits (see: cainban git sync) to its description.")
+		os.Exit(exitUsage)
+	}
+
+	repo, err := git.Open(".")
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	result, err := git.New(db.Conn()).Enrich(repo, taskSystem, foundTask.ID, time.Now())
+	if err != nil {
+		fmt.Printf("Error enriching task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "enrich": result})
+		return
+	}
+
+	if len(result.Commits) == 0 {
+		fmt.Printf("Nothing new to add to task #%d \"%s\": no linked commits beyond those already summarized\n", foundTask.ID, foundTask.Title)
+		fmt.Println("Link commits with a cainban:#<id> trailer and run: cainban git sync")
+		return
+	}
+
+	fmt.Printf("Added a summary of %d commits to task #%d \"%s\" in board '%s':\n\n", len(result.Commits), foundTask.ID, foundTask.Title, boardName)
+	fmt.Print(result.Summary)
+}
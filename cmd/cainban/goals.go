@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/goal"
+	"github.com/hmain/cainban/src/systems/milestone"
+)
+
+func handleGoals(args []string) {
+	command := "list"
+	if len(args) > 0 {
+		command = args[0]
+		args = args[1:]
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	goalSystem := goal.New(db.Conn())
+
+	switch command {
+	case "list":
+		goals, err := goalSystem.List(1)
+		if err != nil {
+			fmt.Printf("Error listing goals: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "goals": goals})
+			return
+		}
+
+		if len(goals) == 0 {
+			fmt.Printf("No goals in board '%s'\n", boardName)
+			fmt.Println("Add one with: cainban goals add <title> [description]")
+			return
+		}
+
+		fmt.Printf("Goals for board '%s'\n", boardName)
+		for _, g := range goals {
+			fmt.Println()
+			fmt.Printf("#%d %s  %s %3.0f%%\n", g.ID, g.Title, progressBar(g.Progress, 20), g.Progress*100)
+			if g.Description != "" {
+				fmt.Printf("   %s\n", g.Description)
+			}
+			if len(g.KeyResults) == 0 {
+				fmt.Println("   (no key results)")
+			}
+			for _, kr := range g.KeyResults {
+				var measure string
+				if kr.Target > 0 {
+					measure = fmt.Sprintf("%d/%d", kr.Current, kr.Target)
+				} else {
+					measure = fmt.Sprintf("%d/%d tasks", kr.TasksDone, kr.TasksTotal)
+				}
+				fmt.Printf("   KR %d: %-35s %-12s %3.0f%%", kr.ID, kr.Title, measure, kr.Progress*100)
+				if kr.Milestone != "" {
+					fmt.Printf("  milestone %s", kr.Milestone)
+				}
+				fmt.Println()
+			}
+		}
+
+	case "add":
+		if len(args) < 1 {
+			fmt.Println("Error: goal title required")
+			fmt.Println("Usage: cainban goals add <title> [description]")
+			os.Exit(exitUsage)
+		}
+		description := ""
+		if len(args) > 1 {
+			description = args[1]
+		}
+
+		created, err := goalSystem.Create(1, args[0], description)
+		if err != nil {
+			fmt.Printf("Error creating goal: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Created goal #%d in board '%s': %s\n", created.ID, boardName, created.Title)
+
+	case "kr":
+		fs := newFlagSet("goals kr")
+		targetFlag := fs.Int("target", 0, "numeric target of the key result (default: done when its tasks are)")
+		args = parseFlags(fs, args)
+		if len(args) != 2 {
+			fmt.Println("Error: goal ID and key result title required")
+			fmt.Println("Usage: cainban goals kr <goal_id> <title> [--target <n>]")
+			os.Exit(exitUsage)
+		}
+		goalID := parseGoalID(args[0], "goal")
+		target := *targetFlag
+		if target < 0 {
+			fmt.Printf("Error: invalid target '%d'\n", target)
+			os.Exit(exitInvalid)
+		}
+
+		kr, err := goalSystem.AddKeyResult(goalID, args[1], target)
+		if err != nil {
+			fmt.Printf("Error adding key result: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if target > 0 {
+			fmt.Printf("Added key result %d to goal #%d: %s (target %d)\n", kr.ID, goalID, kr.Title, target)
+		} else {
+			fmt.Printf("Added key result %d to goal #%d: %s\n", kr.ID, goalID, kr.Title)
+			fmt.Printf("Link tasks with: cainban goals link %d <task>\n", kr.ID)
+		}
+
+	case "progress":
+		if len(args) < 2 {
+			fmt.Println("Error: key result ID and value required")
+			fmt.Println("Usage: cainban goals progress <kr_id> <value>")
+			os.Exit(exitUsage)
+		}
+		krID := parseGoalID(args[0], "key result")
+		value, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Printf("Error: invalid value '%s'\n", args[1])
+			os.Exit(exitInvalid)
+		}
+
+		if err := goalSystem.SetProgress(krID, value); err != nil {
+			fmt.Printf("Error updating key result: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Key result %d is now at %d\n", krID, value)
+
+	case "link", "unlink":
+		fs := newFlagSet("goals " + command)
+		exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+		args = parseFlags(fs, args)
+		if len(args) < 2 {
+			fmt.Println("Error: key result ID and task ID/title required")
+			fmt.Printf("Usage: cainban goals %s <kr_id> <id|title> [--exact]\n", command)
+			os.Exit(exitUsage)
+		}
+		krID := parseGoalID(args[0], "key result")
+
+		foundTask, err := findTaskWith(taskSystem, strings.Join(args[1:], " "), *exact)
+		if err != nil {
+			fmt.Printf("Error finding task: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if command == "link" {
+			if err := goalSystem.LinkTask(krID, foundTask.ID); err != nil {
+				fmt.Printf("Error linking task: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			fmt.Printf("Task #%d \"%s\" now rolls up into key result %d\n", foundTask.ID, foundTask.Title, krID)
+		} else {
+			if err := goalSystem.UnlinkTask(krID, foundTask.ID); err != nil {
+				fmt.Printf("Error unlinking task: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			fmt.Printf("Task #%d \"%s\" removed from key result %d\n", foundTask.ID, foundTask.Title, krID)
+		}
+
+	case "milestone":
+		if len(args) != 2 {
+			fmt.Println("Error: key result ID and milestone required")
+			fmt.Println("Usage: cainban goals milestone <kr_id> <milestone|none>")
+			os.Exit(exitUsage)
+		}
+		krID := parseGoalID(args[0], "key result")
+
+		if args[1] == "none" {
+			if err := goalSystem.SetMilestone(krID, nil); err != nil {
+				fmt.Printf("Error updating key result: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			fmt.Printf("Key result %d no longer counts the tasks of a milestone\n", krID)
+			return
+		}
+		found, err := milestone.New(db.Conn()).Find(1, args[1])
+		if err == nil {
+			err = goalSystem.SetMilestone(krID, &found.ID)
+		}
+		if err != nil {
+			fmt.Printf("Error updating key result: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("The tasks of milestone \"%s\" now roll up into key result %d\n", found.Name, krID)
+
+	case "remove":
+		if len(args) < 1 {
+			fmt.Println("Error: goal ID required")
+			fmt.Println("Usage: cainban goals remove <goal_id>")
+			os.Exit(exitUsage)
+		}
+		goalID := parseGoalID(args[0], "goal")
+		if err := goalSystem.Delete(goalID); err != nil {
+			fmt.Printf("Error removing goal: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Removed goal #%d and its key results\n", goalID)
+
+	case "remove-kr":
+		if len(args) < 1 {
+			fmt.Println("Error: key result ID required")
+			fmt.Println("Usage: cainban goals remove-kr <kr_id>")
+			os.Exit(exitUsage)
+		}
+		krID := parseGoalID(args[0], "key result")
+		if err := goalSystem.DeleteKeyResult(krID); err != nil {
+			fmt.Printf("Error removing key result: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Removed key result %d\n", krID)
+
+	default:
+		fmt.Printf("Unknown goals command: %s\n", command)
+		fmt.Println("Commands: list, add, kr, progress, link, unlink, milestone, remove, remove-kr")
+		os.Exit(exitUsage)
+	}
+}
+
+// parseGoalID parses a numeric goal or key result ID, exiting on error
+func parseGoalID(value, kind string) int {
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		fmt.Printf("Error: invalid %s ID '%s'\n", kind, value)
+		os.Exit(exitInvalid)
+	}
+	return id
+}
+
+// progressBar renders a fraction between 0 and 1 as a fixed-width bar
+func progressBar(fraction float64, width int) string {
+	filled := int(fraction*float64(width) + 0.5)
+	if filled > width {
+		filled = width
+	}
+	if filled < 0 {
+		filled = 0
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
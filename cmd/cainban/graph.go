@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/graph"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func handleGraph(args []string) {
+	fs := newFlagSet("graph")
+	taskFlag := fs.String("task", "", "only the graph around this task ID or hash")
+	formatFlag := fs.String("format", "ascii", "ascii, dot or mermaid")
+	if rest := parseFlags(fs, args); len(rest) > 0 {
+		usageError("unknown argument '%s'", rest[0])
+	}
+
+	rootID := 0
+	rootRef := "" // a task hash given for --task
+	format := *formatFlag
+	if *taskFlag != "" {
+		id, err := strconv.Atoi(*taskFlag)
+		if err != nil && !task.IsHash(*taskFlag) {
+			fmt.Printf("Error: invalid task_id '%s'\n", *taskFlag)
+			os.Exit(exitInvalid)
+		}
+		if err != nil {
+			rootRef = *taskFlag
+		}
+		rootID = id
+	}
+	if format != "ascii" && format != "dot" && format != "mermaid" {
+		fmt.Printf("Error: unknown graph format '%s' (use ascii, dot or mermaid)\n", format)
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+	if rootRef != "" {
+		rootID = taskIDByHash(taskSystem, rootRef)
+	}
+
+	g, err := graph.Build(taskSystem, 1, rootID)
+	if err != nil {
+		fmt.Printf("Error building graph: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	cycles := g.Cycles()
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "graph": g, "cycles": cycles})
+		return
+	}
+
+	switch format {
+	case "dot":
+		fmt.Print(g.DOT())
+	case "mermaid":
+		fmt.Print(g.Mermaid())
+	default:
+		if len(g.Tasks) == 0 {
+			fmt.Printf("No linked tasks in board '%s'\n", boardName)
+			fmt.Println("Link tasks with: cainban link <from_id> <to_id> [type]")
+			return
+		}
+		fmt.Printf("Dependency graph for board '%s' (tasks are listed under the tasks blocking them):\n\n", boardName)
+		fmt.Print(g.ASCII())
+	}
+
+	if len(cycles) > 0 {
+		// Keep DOT and Mermaid output renderable when redirected
+		fmt.Fprintf(os.Stderr, "\nWarning: %d circular dependencies found:\n", len(cycles))
+		for _, cycle := range cycles {
+			fmt.Fprintf(os.Stderr, "  %s\n", graph.FormatCycle(cycle))
+		}
+	}
+}
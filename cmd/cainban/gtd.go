@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+)
+
+func handleGTD(args []string) {
+	command := "list"
+	if len(args) > 0 {
+		command = args[0]
+		args = args[1:]
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	switch command {
+	case "list":
+		contexts, err := taskSystem.ListContexts(1)
+		if err != nil {
+			fmt.Printf("Error listing contexts: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "contexts": contexts})
+			return
+		}
+
+		if len(contexts) == 0 {
+			fmt.Printf("No contexts in board '%s'\n", boardName)
+			fmt.Println("Add one with: cainban gtd add <id|title> @context")
+			return
+		}
+
+		fmt.Printf("Contexts in board '%s':\n", boardName)
+		for _, c := range contexts {
+			fmt.Printf("  %-20s %d open\n", c.Context, c.Count)
+		}
+		fmt.Println("Work in a context with: cainban list @context")
+
+	case "add", "remove":
+		fs := newFlagSet("gtd " + command)
+		exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+		args = parseFlags(fs, args)
+		if len(args) < 2 {
+			fmt.Println("Error: task ID/title and context required")
+			fmt.Printf("Usage: cainban gtd %s <id|title> <@context...> [--exact]\n", command)
+			os.Exit(exitUsage)
+		}
+
+		foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+		if err != nil {
+			fmt.Printf("Error finding task: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		for _, context := range args[1:] {
+			if command == "add" {
+				err = taskSystem.AddContext(foundTask.ID, context)
+			} else {
+				err = taskSystem.RemoveContext(foundTask.ID, context)
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+		}
+
+		updated, err := taskSystem.GetByID(foundTask.ID)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		contexts := "no contexts"
+		if len(updated.Contexts) > 0 {
+			contexts = strings.Join(updated.Contexts, " ")
+		}
+		fmt.Printf("Task #%d \"%s\" in board '%s': %s\n", updated.ID, updated.Title, boardName, contexts)
+
+	default:
+		fmt.Printf("Unknown gtd command: %s\n", command)
+		fmt.Println("Commands: list, add, remove")
+		os.Exit(exitUsage)
+	}
+}
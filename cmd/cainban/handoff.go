@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/webhook"
+)
+
+func handleHandoff(args []string) {
+	fs := newFlagSet("handoff")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 2 {
+		fmt.Println("Error: task ID/title and agent required")
+		fmt.Println("Usage: cainban handoff <id|title> <agent> [\"context note\"] [--exact]")
+		fmt.Println("Example:")
+		fmt.Println("  cainban handoff 12 reviewer \"API done; tests in api_test.go still flaky\"")
+		os.Exit(exitUsage)
+	}
+
+	note := ""
+	if len(args) > 2 {
+		note = args[2]
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	handoff, err := taskSystem.Handoff(foundTask.ID, args[1], note)
+	if err != nil {
+		fmt.Printf("Error handing off task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	// The handoff has happened; a failing webhook is only worth a warning
+	var webhookErr error
+	if cfg.HandoffWebhook != "" {
+		var url string
+		if url, webhookErr = resolveSecret("handoff_webhook", cfg.HandoffWebhook); webhookErr == nil {
+			webhookErr = webhook.Post(url, "task_handoff", boardName, handoff)
+		}
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		result := map[string]interface{}{"board": boardName, "handoff": handoff}
+		if webhookErr != nil {
+			result["webhook_error"] = webhookErr.Error()
+		}
+		printJSON(result)
+		return
+	}
+
+	fmt.Printf("Handed off task #%d \"%s\" to %s in board '%s'\n", foundTask.ID, foundTask.Title, handoff.To, boardName)
+	fmt.Printf("Comment: %s\n", handoff.Comment.Body)
+	if handoff.CapacityWarning != nil {
+		fmt.Printf("Warning: %s\n", handoff.CapacityWarning)
+	}
+	if webhookErr != nil {
+		fmt.Printf("Warning: %v\n", webhookErr)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/sandbox"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// newHooks returns the hooks of the profile in use, under the limits of
+// automation commands
+func newHooks() *automation.Hooks {
+	hooks := automation.NewHooks(automation.HooksDir())
+	limits := automation.DefaultLimits()
+	limits.Timeout, limits.MemoryMB = cfg.CommandTimeout, cfg.CommandMemoryMB
+	hooks.SetLimits(limits)
+	return hooks
+}
+
+// runPreHook runs a pre- hook, exiting when it refuses the change.
+// Experiments in a sandbox run no hooks, as they run no automations.
+func runPreHook(db *storage.DB, event automation.HookEvent) {
+	if sandbox.IsSandbox(db.Path()) {
+		return
+	}
+	if err := newHooks().Run(context.Background(), event); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// runPostHook runs a post- hook; its failure is only a warning, since the
+// change has already happened
+func runPostHook(db *storage.DB, event automation.HookEvent) {
+	if sandbox.IsSandbox(db.Path()) {
+		return
+	}
+	if err := newHooks().Run(context.Background(), event); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+// handleHooks shows the hooks directory and which hooks are installed
+func handleHooks(args []string) {
+	fs := newFlagSet("hooks")
+	args = parseFlags(fs, args)
+	if len(args) != 0 {
+		usageError("Usage: cainban hooks")
+	}
+
+	hooks := newHooks()
+	if cfg.OutputFormat == config.FormatJSON {
+		installed := []string{}
+		for _, name := range automation.HookNames {
+			if _, ok := hooks.Path(name); ok {
+				installed = append(installed, name)
+			}
+		}
+		printJSON(map[string]interface{}{"dir": automation.HooksDir(), "hooks": automation.HookNames, "installed": installed})
+		return
+	}
+
+	fmt.Printf("Hooks directory: %s\n\n", automation.HooksDir())
+	for _, name := range automation.HookNames {
+		path, ok := hooks.Path(name)
+		switch {
+		case ok:
+			fmt.Printf("  %-12s installed\n", name)
+		case statOK(path):
+			fmt.Printf("  %-12s not executable (chmod +x %s)\n", name, path)
+		default:
+			fmt.Printf("  %-12s -\n", name)
+		}
+	}
+	fmt.Println("\nHooks get the task as JSON on stdin; a pre- hook that exits non-zero stops the change.")
+}
+
+// statOK reports whether there is a file at path
+func statOK(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
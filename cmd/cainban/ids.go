@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+)
+
+func handleIDs(args []string) {
+	fs := newFlagSet("ids")
+	prefix := fs.String("prefix", "", "number tasks per board with this prefix, e.g. T for T-001")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		usageError("unknown argument '%s'", args[0])
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	if *prefix != "" {
+		numbered, err := taskSystem.SetNumbering(*prefix)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if cfg.OutputFormat != config.FormatJSON {
+			fmt.Printf("Numbering tasks in board '%s' with prefix %s (%d existing tasks numbered)\n",
+				boardName, strings.ToUpper(*prefix), numbered)
+		}
+	}
+
+	report, err := taskSystem.IDs()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "ids": report})
+		return
+	}
+
+	fmt.Printf("Board: %s\n", boardName)
+	fmt.Printf("Tasks: %d (%d in the trash)\n", report.Tasks, len(report.Trashed))
+	fmt.Printf("Highest ID: %d\n", report.Highest)
+	fmt.Printf("Next ID: %d\n", report.Next)
+
+	if len(report.Gaps) == 0 {
+		fmt.Println("Gaps: none")
+	} else {
+		gaps := make([]string, len(report.Gaps))
+		for i, gap := range report.Gaps {
+			gaps[i] = gap.String()
+		}
+		fmt.Printf("Gaps: %s (deleted for good)\n", strings.Join(gaps, ", "))
+	}
+	if len(report.Trashed) > 0 {
+		trashed := make([]string, len(report.Trashed))
+		for i, id := range report.Trashed {
+			trashed[i] = fmt.Sprintf("#%d", id)
+		}
+		fmt.Printf("In the trash: %s (cainban restore <id>)\n", strings.Join(trashed, ", "))
+	}
+
+	if report.Prefix == "" {
+		fmt.Println("Numbering: off (cainban ids --prefix T numbers tasks T-001, T-002, ...)")
+	} else {
+		fmt.Printf("Numbering: next task is %s\n", report.NextRef())
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/bundle"
+	"github.com/hmain/cainban/src/systems/jira"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func handleImport(args []string) {
+	fs := newFlagSet("import")
+	mappingPath := fs.String("mapping", "", "YAML file mapping source statuses, priorities, labels and columns")
+	boardName := fs.String("board", "", "name of the board a bundle creates, instead of the bundled board's name")
+	trust := fs.Bool("trust", false, "keep a bundle's automations and rules that run commands or post to URLs")
+	args = parseFlags(fs, args)
+	if len(args) != 2 {
+		fmt.Println("Error: source and file required")
+		fmt.Println("Usage: cainban import <source> <file|-> [--mapping <file.yaml>] [--board <name>] [--trust]")
+		fmt.Println("Sources: jira (CSV export or REST search JSON), bundle (" + bundle.Extension + " file)")
+		os.Exit(exitUsage)
+	}
+
+	switch args[0] {
+	case "bundle":
+		handleImportBundle(args[1], *boardName, *trust)
+	case "jira":
+		mapping := jira.DefaultMapping()
+		if *mappingPath != "" {
+			var err error
+			if mapping, err = jira.LoadMapping(*mappingPath); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+		}
+		handleImportJira(args[1], mapping)
+	default:
+		fmt.Printf("Unknown import source: %s\n", args[0])
+		fmt.Println("Sources: jira, bundle")
+		os.Exit(exitUsage)
+	}
+}
+
+func handleImportJira(path string, mapping jira.Mapping) {
+	input, err := openInput(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	spin := startSpinner("Reading Jira issues")
+	issues, err := mapping.Parse(input)
+	input.Close()
+	spin.Stop()
+	if err != nil {
+		fmt.Printf("Error reading Jira issues: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	spin = startSpinner(fmt.Sprintf("Importing %d Jira issues", len(issues)))
+	result, err := jira.Import(taskSystem, 1, issues, mapping)
+	spin.Stop()
+	if result != nil {
+		for _, t := range result.Created {
+			fmt.Printf("Imported #%d [%s] %s%s\n", t.ID, t.Status, t.Title, formatContexts(t.Contexts))
+		}
+		for _, issue := range result.Skipped {
+			fmt.Printf("Skipped %s (the task is on the board already)\n", describeIssue(issue))
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error importing: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Imported %d of %d Jira issues into board '%s'\n", len(result.Created), len(issues), boardName)
+}
+
+// handleImportBundle creates a new board from a bundle, after checking that
+// the bundle is complete and uncorrupted. Unless trusted, the automations and
+// rules that would run commands or post to URLs are left out.
+func handleImportBundle(path, boardName string, trust bool) {
+	input, err := openInput(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	spin := startSpinner("Checking the bundle")
+	b, err := bundle.Read(input)
+	input.Close()
+	spin.Stop()
+	if err != nil {
+		fmt.Printf("Error: %s: %v\n", path, err)
+		os.Exit(exitCode(err))
+	}
+
+	var stripped []string
+	if !trust {
+		if stripped, err = b.StripExternal(); err != nil {
+			fmt.Printf("Error: %s: %v\n", path, err)
+			os.Exit(exitCode(err))
+		}
+	}
+
+	if boardName == "" {
+		boardName = b.Manifest.Board
+	}
+	boardSystem := newBoardSystem()
+	// The bundle fills in the database, so only its path is taken
+	boardPath, err := boardSystem.NewBoardPath(boardName)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		if boardName == b.Manifest.Board {
+			fmt.Println("Use --board <name> to import it under another name")
+		}
+		os.Exit(1)
+	}
+
+	spin = startSpinner("Restoring the board")
+	err = b.Restore(boardPath)
+	spin.Stop()
+	if err != nil {
+		fmt.Printf("Error restoring bundle: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	// The bundle carries the name of the board it came from
+	db, err := storage.New(boardPath)
+	if err == nil {
+		err = db.SetBoardName(boardName)
+		db.Close()
+	}
+	if err != nil {
+		fmt.Printf("Error naming imported board: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Imported board '%s' with %d tasks and %d attachments (bundled %s)\n",
+		boardName, b.Tasks(), len(b.Attachments), b.Manifest.CreatedAt.Local().Format("2006-01-02 15:04"))
+	if len(stripped) > 0 {
+		fmt.Println("Left out, as they run commands or post to URLs:")
+		for _, s := range stripped {
+			fmt.Printf("  %s\n", s)
+		}
+		fmt.Println("Import again with --trust to keep them")
+	}
+	fmt.Printf("Switch to it with: cainban board switch %s\n", boardName)
+}
+
+func handleExport(args []string) {
+	fs := newFlagSet("export")
+	format := fs.String("format", "csv", "csv or json")
+	output := fs.String("output", "", "file to write, - for standard output (default: standard output for jira, <board>"+bundle.Extension+" for bundle)")
+	filterExpr := fs.String("filter", "", `only export tasks matching a filter expression, e.g. "tag=client-a"`)
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fmt.Println("Error: export target required")
+		fmt.Println("Usage: cainban export <target> [--format csv|json] [--output <file>] [--filter \"<expr>\"]")
+		fmt.Println("Targets: jira, bundle")
+		os.Exit(exitUsage)
+	}
+
+	if args[0] == "bundle" {
+		if *filterExpr != "" {
+			usageError("a bundle holds the whole board; --filter only applies to jira")
+		}
+		handleExportBundle(*output)
+		return
+	}
+	if *output == "" {
+		*output = "-"
+	}
+
+	filter, err := task.ParseFilter(*filterExpr)
+	if err != nil {
+		usageError("%v", err)
+	}
+
+	switch args[0] {
+	case "jira":
+		handleExportJira(*format, *output, filter)
+	default:
+		fmt.Printf("Unknown export target: %s\n", args[0])
+		fmt.Println("Targets: jira, bundle")
+		os.Exit(exitUsage)
+	}
+}
+
+func handleExportJira(format, output string, filter *task.Filter) {
+	if format != "csv" && format != "json" {
+		fmt.Printf("Error: invalid format '%s' (must be csv or json)\n", format)
+		os.Exit(exitInvalid)
+	}
+
+	db, taskSystem, _, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	tasks, err := taskSystem.List(1)
+	if err != nil {
+		fmt.Printf("Error listing tasks: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	tasks = filter.Apply(tasks, time.Now())
+
+	issues := make([]jira.Issue, 0, len(tasks))
+	for _, t := range tasks {
+		issues = append(issues, jira.FromTask(t))
+	}
+
+	writeOutput(output, func(w io.Writer) error {
+		if format == "json" {
+			return jira.WriteJSON(w, issues)
+		}
+		return jira.WriteCSV(w, issues)
+	})
+
+	if output != "-" {
+		fmt.Printf("Exported %d tasks to %s\n", len(tasks), output)
+	}
+}
+
+// handleExportBundle writes the current board, attachments included, to a
+// checksummed bundle that import bundle turns back into a board
+func handleExportBundle(output string) {
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	b, err := bundle.Create(db, boardName, time.Now())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if output == "" {
+		output = boardName + bundle.Extension
+	}
+	writeOutput(output, b.Write)
+
+	if output != "-" {
+		fmt.Printf("Exported board '%s' (%d tasks, %d attachments) to %s\n", boardName, b.Tasks(), len(b.Attachments), output)
+	}
+}
+
+// describeIssue names an issue by key when it has one
+func describeIssue(issue jira.Issue) string {
+	if issue.Key != "" {
+		return fmt.Sprintf("%s %q", issue.Key, issue.Summary)
+	}
+	return fmt.Sprintf("%q", issue.Summary)
+}
+
+// openInput opens a file for reading, or stdin for "-"
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// writeOutput writes to a file, or stdout for "-", exiting on error
+func writeOutput(path string, write func(io.Writer) error) {
+	if path == "-" {
+		if err := write(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		return
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		fmt.Printf("Error: failed to create %s: %v\n", path, err)
+		os.Exit(exitCode(err))
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if err := file.Close(); err != nil {
+		fmt.Printf("Error: failed to write %s: %v\n", path, err)
+		os.Exit(exitCode(err))
+	}
+}
@@ -1,14 +1,33 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
+	"github.com/charmbracelet/x/term"
+	"github.com/hmain/cainban/src/systems/automation"
 	"github.com/hmain/cainban/src/systems/board"
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/dateparse"
+	"github.com/hmain/cainban/src/systems/markdown"
 	"github.com/hmain/cainban/src/systems/mcp"
+	"github.com/hmain/cainban/src/systems/milestone"
+	"github.com/hmain/cainban/src/systems/report"
+	"github.com/hmain/cainban/src/systems/sandbox"
+	"github.com/hmain/cainban/src/systems/sprint"
 	"github.com/hmain/cainban/src/systems/storage"
 	"github.com/hmain/cainban/src/systems/task"
 	"github.com/hmain/cainban/src/tui"
@@ -23,13 +42,45 @@ const (
 	VersionSuffix = "Full Viewport Navigation" // Description of this dev build
 )
 
+// version is the version of a release build, set with -X main.version; it
+// is empty in development builds
+var version string
+
+// cfg holds the user configuration loaded at startup
+var cfg = config.Default()
+
 func main() {
+	applyGlobalFlags()
 	if len(os.Args) < 2 {
-		printUsage()
-		os.Exit(1)
+		if !onTerminal(os.Stdin, os.Stdout) {
+			printUsage()
+			os.Exit(exitUsage)
+		}
+		// Run bare on a terminal, cainban opens the board
+		os.Args = append(os.Args, "tui")
 	}
 
 	command := os.Args[1]
+	switch {
+	case command == "help" || command == "-h" || command == "--help":
+		if len(os.Args) > 2 {
+			printCommandHelp(os.Args[2])
+		} else {
+			printUsage()
+		}
+		return
+	case command != "__complete" && wantsHelp(os.Args[2:]):
+		printCommandHelp(command)
+		return
+	}
+
+	loaded, err := config.Load(config.DefaultPath())
+	if err != nil {
+		fmt.Printf("Error loading config: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	cfg = loaded
+	storage.BusyTimeout = cfg.BusyTimeout
 
 	switch command {
 	case "init":
@@ -44,10 +95,72 @@ func main() {
 		handleGet(os.Args[2:])
 	case "update":
 		handleUpdate(os.Args[2:])
+	case "edit":
+		handleEdit(os.Args[2:])
 	case "search":
 		handleSearch(os.Args[2:])
+	case "pick":
+		handlePick(os.Args[2:])
 	case "priority":
 		handlePriority(os.Args[2:])
+	case "estimate":
+		handleEstimate(os.Args[2:])
+	case "assign":
+		handleAssign(os.Args[2:])
+	case "capacity":
+		handleCapacity(os.Args[2:])
+	case "handoff":
+		handleHandoff(os.Args[2:])
+	case "claim":
+		handleClaim(os.Args[2:])
+	case "release":
+		handleRelease(os.Args[2:])
+	case "context":
+		handleContext(os.Args[2:])
+	case "worklog":
+		handleWorklog(os.Args[2:])
+	case "size":
+		handleSize(os.Args[2:])
+	case "energy":
+		handleEnergy(os.Args[2:])
+	case "suggest":
+		handleSuggest(os.Args[2:])
+	case "report":
+		handleReport(os.Args[2:])
+	case "stats":
+		handleStats(os.Args[2:])
+	case "recur":
+		handleRecur(os.Args[2:])
+	case "habits":
+		handleHabits(os.Args[2:])
+	case "gtd":
+		handleGTD(os.Args[2:])
+	case "remind":
+		handleRemind(os.Args[2:])
+	case "reminders":
+		handleReminders(os.Args[2:])
+	case "daemon":
+		handleDaemon(os.Args[2:])
+	case "notify":
+		handleNotify(os.Args[2:])
+	case "watch":
+		handleWatch(os.Args[2:])
+	case "sync":
+		handleSync(os.Args[2:])
+	case "import":
+		handleImport(os.Args[2:])
+	case "export":
+		handleExport(os.Args[2:])
+	case "goals":
+		handleGoals(os.Args[2:])
+	case "sprint":
+		handleSprint(os.Args[2:])
+	case "milestone":
+		handleMilestone(os.Args[2:])
+	case "git":
+		handleGit(os.Args[2:])
+	case "enrich":
+		handleEnrich(os.Args[2:])
 	case "board":
 		handleBoard(os.Args[2:])
 	case "link":
@@ -56,59 +169,247 @@ func main() {
 		handleUnlink(os.Args[2:])
 	case "links":
 		handleLinks(os.Args[2:])
+	case "graph":
+		handleGraph(os.Args[2:])
+	case "sandbox":
+		handleSandbox(os.Args[2:])
+	case "column":
+		handleColumn(os.Args[2:])
+	case "due":
+		handleDue(os.Args[2:])
+	case "next":
+		handleNext(os.Args[2:])
+	case "do":
+		handleDo(os.Args[2:])
+	case "react":
+		handleReact(os.Args[2:])
+	case "attach":
+		handleAttach(os.Args[2:])
+	case "hooks":
+		handleHooks(os.Args[2:])
+	case "standup":
+		handleStandup(os.Args[2:])
+	case "parent":
+		handleParent(os.Args[2:])
+	case "vote":
+		handleVote(os.Args[2:])
+	case "grooming":
+		handleGrooming(os.Args[2:])
+	case "automation":
+		handleAutomation(os.Args[2:])
 	case "delete":
 		handleDelete(os.Args[2:])
+	case "backup":
+		handleBackup(os.Args[2:])
 	case "restore":
 		handleRestore(os.Args[2:])
+	case "ids":
+		handleIDs(os.Args[2:])
+	case "config":
+		handleConfig(os.Args[2:])
+	case "demo":
+		handleDemo(os.Args[2:])
+	case "doctor":
+		handleDoctor(os.Args[2:])
+	case "schema":
+		handleSchema(os.Args[2:])
 	case "tui":
-		handleTUI()
+		handleTUI(os.Args[2:])
+	case "profiles":
+		handleProfiles(os.Args[2:])
+	case "secret":
+		handleSecret(os.Args[2:])
 	case "mcp":
 		handleMCP()
 	case "version":
 		handleVersion()
+	case "whatsnew":
+		handleWhatsNew(os.Args[2:])
+	case "completion":
+		handleCompletion(os.Args[2:])
+	case "__complete":
+		handleComplete(os.Args[2:])
 	default:
 		fmt.Printf("Unknown command: %s\n", command)
 		printUsage()
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 }
 
+// usage is the help of every command; "cainban <command> --help" prints the
+// lines of one command
+const usage = `cainban - AI-centric kanban board
+
+Usage:
+  cainban init [board-name|--local]    Initialize new board (--local: in ./.cainban)
+  cainban add <title> [description] [--priority <level>] [--parent <id|title>] [@context...] Add new task
+  cainban list [status] [@context] [--all-boards] List tasks, by status or by context
+  cainban list [--blocked|--unblocked]  Only tasks waiting on unfinished blockers, or only the others
+  cainban list --stale                 Only unfinished tasks not updated for stale_days, marked 💤 everywhere
+  cainban list --filter "<expr>"       Only tasks matching e.g. "tag=client-a status!=done priority>=high"
+  cainban list [--priority <p,...>] [--tag <context>] [--assignee <name>] Only tasks of these priorities, tag or assignee
+  cainban list [--due-before <when>] [--sort created|updated|priority|due|manual] Due before a time, sorted
+  cainban list [--limit <n>] [--page <n>]  Page through the tasks, 200 at a time by default (--limit 0: all)
+  cainban list --sprint <current|name|id|none> Only the tasks planned for a sprint, or for none
+  cainban list --milestone <name|id>   Only the tasks of a milestone
+  cainban column <show|set|clear> [status] What each column means, e.g. the definition of done
+  cainban move <id|title> <status> [--force] [--exact] Move task between columns (no arguments: pick one)
+  cainban get <id|title> [--plain] [--exact] Get task details, rendering the description's Markdown
+  cainban update <id|title> [title] [description] [--title <t>] [--description <d>] [--append-description <d>] [--editor] Change what is given, keep the rest
+  cainban edit <id|title>              Edit the title and description as Markdown in your editor
+  cainban search [--all-boards] <query>   Search tasks by title
+  cainban pick [--print]               Fuzzy find a task interactively, then act on it
+  cainban priority <id|title> <level>     Set task priority
+  cainban estimate <id|title> <points>    Set task estimate in story points
+  cainban parent <id|title> <parent|none> Make a task a subtask; parents roll up its points
+  cainban assign <id|title> [assignee]    Assign task (omit assignee to unassign)
+  cainban capacity [set <who> <points>]   Show or configure assignee capacity
+  cainban handoff <id|title> <agent> [note] Reassign a task with a context note
+  cainban claim <id|title>             Claim a task and move it to doing, so other agents leave it
+  cainban release <id|title> [--force] Give up a claim (--force: someone else's)
+  cainban context <set|get|clear> <id|title> Store agent working state on a task
+  cainban worklog <id|title> ["summary"] [--files <a,b>] [--outcome <o>] Log a step of work on a task, or show its worklog
+  cainban context [--max-tokens <n>]   Export the board for an LLM context window
+  cainban size <id|title> <S|M|L|none>    Set task size (S ~30m, M ~2h, L ~4h)
+  cainban energy <id|title> <low|high|none> Set the energy a task demands
+  cainban suggest [--time <d>] [--energy low|high] [--limit <n>] Propose tasks that fit
+  cainban next                         Pick the best task to work on now
+  cainban due <id|title> <when|none>   Set or clear a task's due date
+  cainban do "<sentence>"             Run a command written in plain English
+  cainban react <id|title> <emoji>     React to a task (--remove to take it back)
+  cainban attach <id|title> [path|url...] [--remove] Attach files or URLs to a task, or list them
+  cainban vote <id|title>              Vote for a backlog task (--remove to withdraw)
+  cainban grooming [--yes]             Reorder the backlog by votes
+  cainban report velocity [--weeks <n>]   Show points completed per week
+  cainban report board [--output <file>]  Write a dated one-page printable HTML summary of the board
+  cainban stats [--weeks <n>]             Show cycle time, throughput and flow
+  cainban standup [--since <when>] [--format md]  Done, in progress and blocked, for a standup
+  cainban recur <id|title> <daily|weekly|none> Make a task recurring
+  cainban habits                          Show streaks for recurring tasks
+  cainban gtd [command]                   GTD contexts such as @home or @deep-work
+  cainban remind <id|title> <when> [note] Schedule a one-off reminder
+  cainban reminders [cancel <id>]         List or cancel pending reminders
+  cainban daemon [--interval <d>] [--once] Deliver due reminders as notifications
+  cainban notify [--stale-days <n>] [--print] [--all-boards] Notify of overdue tasks and tasks stuck in doing, e.g. from cron
+  cainban watch [--interval <d>] [--once] Redraw the board as it changes, for a spare pane
+  cainban import jira <file|-> [--mapping <file.yaml>] Import issues from a Jira CSV or JSON export
+  cainban export jira [--format csv|json] [--output <file>] [--filter "<expr>"] Export tasks for Jira
+  cainban import bundle <file|-> [--board <name>] [--trust] Create a board from a checksummed .cainban-bundle file
+  cainban export bundle [--output <file>] Export the whole board and its attachments as a .cainban-bundle
+  cainban sync [--dir <repo>]             Sync the board with other machines through a git repository
+  cainban sync init [<remote-url>]        Set up the sync repository, cloning the remote if given
+  cainban goals [command]                 Goals and key results with progress
+  cainban sprint [command]                Sprints, the tasks planned for them and their burndown
+  cainban milestone [command]             Milestones grouping tasks, with how much of each is done
+  cainban git <command>                   Link tasks to branches and commits
+  cainban enrich <id|title>               Append a summary of linked commits to a task
+  cainban link <from_id> <to_id> [type]   Link two tasks (board:id for other boards)
+  cainban unlink <from_id> <to_id> [type] Unlink two tasks
+  cainban links <task_id>              Show task links
+  cainban graph [--task <id>] [--format ascii|dot|mermaid]  Show the dependency graph
+  cainban sandbox <start|diff|apply|discard>  Experiment on a copy of the board
+  cainban automation <command>            Webhooks, commands and rules run on task changes
+  cainban hooks                        Show the hooks directory: pre-/post-create, -move and -delete scripts
+  cainban delete <task_id> [--hard]    Delete task (soft delete by default)
+  cainban restore <task_id|file>       Restore a deleted task, or replace the board with a backup
+  cainban backup [--output <file>]     Back up the board while it stays in use
+  cainban ids [--prefix <P>]           Show ID gaps and the next ID; --prefix numbers tasks P-001, P-002, ...
+  cainban board <command>              Board management
+  cainban config [show|path]           Show configuration
+  cainban profiles                     List profiles: separate config and boards, used with cainban --profile <name> <command>
+  cainban secret <command>             Keep tokens and webhook URLs out of config.toml
+  cainban schema [status|down <version>]  Show the board's schema version, or undo migrations for an older cainban
+  cainban doctor [--fix]               Find orphaned board files and stale leftovers; --fix adopts or cleans them up
+  cainban demo [--tasks <n>] [--seed <n>] [--board <name>] [--replace] Fill a throwaway board with generated tasks
+  cainban tui [--theme <name>]         Start interactive TUI mode (also: cainban with no arguments)
+  cainban mcp                          Start MCP server
+  cainban version                      Show version
+  cainban whatsnew [--all] [--since <version>] Release notes new since you last looked
+  cainban help [command]               Show the usage of every command, or of one
+  cainban completion <bash|zsh|fish>   Print a shell completion script
+
+Board commands:
+  cainban board list                   List all boards
+  cainban board current                Show current board
+  cainban board switch <name>          Switch to board
+  cainban board create <name> [desc]   Create new board
+  cainban board describe <name> [desc] Set or clear a board's description (the default board too)
+  cainban board rename <old> <new>     Rename a board, keeping its tasks, backups and links from other boards
+  cainban board delete <name> [--force] Move a board to the trash, after asking unless --force
+  cainban board restore <name>         Bring the most recently deleted board of a name back
+  cainban board trash                  List deleted boards in the trash
+  cainban board readme [edit|set|clear] Show or edit the board's charter (Markdown)
+  cainban board order [manual|priority|due|updated] Show or set the order of the board's columns in list, the TUI and MCP
+  cainban board add-remote <name> <url> Use a board kept on another machine (ssh://host/path.db or http(s)://)
+  cainban board serve [--addr <host:port>] Serve the current board over HTTP for other machines to add
+
+Git commands:
+  cainban git branch <id|title>           Create and check out a branch for a task
+  cainban git log <id|title> [--limit <n>] Show commits referencing a task
+  cainban git sync [--limit <n>]          Link recent commits; "closes #42" moves #42 to done
+  cainban git hook                        Install a post-commit hook that runs git sync
+
+GTD context commands:
+  cainban gtd                             List contexts with their open tasks
+  cainban gtd add <id|title> <@context...> Put a task in one or more contexts
+  cainban gtd remove <id|title> <@context> Take a task out of a context
+
+Goal commands:
+  cainban goals                           List goals with progress
+  cainban goals add <title> [desc]        Create a goal
+  cainban goals kr <goal_id> <title> [--target <n>] Add a key result
+  cainban goals progress <kr_id> <value>  Record progress on a numeric key result
+  cainban goals link <kr_id> <id|title>   Roll a task up into a key result
+  cainban goals unlink <kr_id> <id|title> Remove a task from a key result
+  cainban goals milestone <kr_id> <milestone|none> Roll a milestone's tasks up into a key result
+  cainban goals remove <goal_id>          Delete a goal
+  cainban goals remove-kr <kr_id>         Delete a key result
+
+Sprint commands:
+  cainban sprint                          List sprints with their progress
+  cainban sprint create <name> [--start <date>] [--end <date>] [--capacity <points>] Create a sprint, two weeks from today by default
+  cainban sprint capacity <sprint> <points> Points the sprint can take; planning past them warns (0: no limit)
+  cainban sprint add <sprint> <id|title>...  Plan tasks for a sprint (current for the one under way)
+  cainban sprint remove <id|title>...     Take tasks out of their sprint
+  cainban sprint burndown [sprint]        Work left each day against the ideal, for the current sprint by default
+  cainban sprint delete <sprint>          Delete a sprint, leaving its tasks unplanned
+
+Milestone commands:
+  cainban milestone                       List milestones with the percentage of their tasks done
+  cainban milestone create <name> [--due <date>] [--description <d>] Create a milestone
+  cainban milestone add <milestone> <id|title>...  Add tasks to a milestone
+  cainban milestone remove <id|title>...  Take tasks out of their milestone
+  cainban milestone delete <milestone>    Delete a milestone, keeping its tasks
+
+Secret commands:
+  cainban secret list                     List stored secrets
+  cainban secret set <name> [value]       Store a secret; without a value, prompt for it or read stdin
+  cainban secret get <name>               Print a secret
+  cainban secret delete <name>            Delete a secret
+
+Priority levels: none, low, medium, high, critical (or 0-4)
+Statuses: todo, doing, done
+Link types: blocks, blocked_by, related, depends_on
+
+Flags may come before, between or after arguments. Commands exit with 2
+when used wrongly and 1 when they fail. cainban --board <name> <command>,
+or $CAINBAN_BOARD, works on a board without switching to it.
+`
+
 func printUsage() {
-	fmt.Println("cainban - AI-centric kanban board")
-	fmt.Println()
-	fmt.Println("Usage:")
-	fmt.Println("  cainban init [board-name]            Initialize new board")
-	fmt.Println("  cainban add <title> [description] [--priority <level>]  Add new task with optional priority")
-	fmt.Println("  cainban list [status]                List all tasks or by status")
-	fmt.Println("  cainban move <id|title> <status>        Move task between columns")
-	fmt.Println("  cainban get <id|title>               Get task details")
-	fmt.Println("  cainban update <id|title> <title> [description] Update task")
-	fmt.Println("  cainban search <query>                  Search tasks by title")
-	fmt.Println("  cainban priority <id|title> <level>     Set task priority")
-	fmt.Println("  cainban link <from_id> <to_id> [type]   Link two tasks")
-	fmt.Println("  cainban unlink <from_id> <to_id> [type] Unlink two tasks")
-	fmt.Println("  cainban links <task_id>              Show task links")
-	fmt.Println("  cainban delete <task_id> [--hard]    Delete task (soft delete by default)")
-	fmt.Println("  cainban restore <task_id>            Restore deleted task")
-	fmt.Println("  cainban board <command>              Board management")
-	fmt.Println("  cainban tui                          Start interactive TUI mode")
-	fmt.Println("  cainban mcp                          Start MCP server")
-	fmt.Println("  cainban version                      Show version")
-	fmt.Println()
-	fmt.Println("Board commands:")
-	fmt.Println("  cainban board list                   List all boards")
-	fmt.Println("  cainban board current                Show current board")
-	fmt.Println("  cainban board switch <name>          Switch to board")
-	fmt.Println("  cainban board create <name> [desc]   Create new board")
-	fmt.Println("  cainban board delete <name>          Delete board")
-	fmt.Println()
-	fmt.Println("Priority levels: none, low, medium, high, critical (or 0-4)")
-	fmt.Println("Statuses: todo, doing, done")
-	fmt.Println("Link types: blocks, blocked_by, related, depends_on")
+	fmt.Print(usage)
 }
 
-func getCurrentBoardDB() (*storage.DB, *task.System, string, error) {
+// newBoardSystem creates a board system honouring the configured default board
+func newBoardSystem() *board.System {
 	boardSystem := board.New()
+	boardSystem.SetDefaultBoard(cfg.DefaultBoard)
+	boardSystem.SetAutoBoard(cfg.AutoBoard)
+	return boardSystem
+}
+
+func getCurrentBoardDB() (*storage.DB, *task.System, string, error) {
+	boardSystem := newBoardSystem()
 
 	// Get current board name
 	boardName, err := boardSystem.GetCurrentBoard()
@@ -116,21 +417,99 @@ func getCurrentBoardDB() (*storage.DB, *task.System, string, error) {
 		return nil, nil, "", fmt.Errorf("failed to get current board: %w", err)
 	}
 
-	// Get database path for current board
+	db, taskSystem, err := openBoardDB(boardSystem, boardName)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return db, taskSystem, boardName, nil
+}
+
+// openBoardDB opens the database of a board: the copy of a remote board,
+// fetched first, or its sandbox while one is active
+func openBoardDB(boardSystem *board.System, boardName string) (*storage.DB, *task.System, error) {
+	if err := boardSystem.CheckBoard(boardName); err != nil {
+		if errors.As(err, new(*board.MissingBoardError)) && boardName == boardSystem.Override() {
+			return nil, nil, storage.Errorf(storage.ErrNotFound, "board '%s' given with --board or %s does not exist", boardName, config.BoardEnv)
+		}
+		return nil, nil, fmt.Errorf("%w\n%s", err, missingBoardHelp(boardSystem, err))
+	}
+
+	// Get database path for the board
 	dbPath := boardSystem.GetBoardPath(boardName)
 
+	// Work on the sandbox copy while one is active. The notice goes to
+	// stderr so it never mixes with JSON output or the MCP protocol.
+	if sandbox.Active(dbPath) {
+		fmt.Fprintf(os.Stderr, "Working in the sandbox of board '%s' (see: cainban sandbox diff|apply|discard)\n", boardName)
+		dbPath = sandbox.Path(dbPath)
+	}
+	remoteBoard, err := boardSystem.Remote(boardName)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Initialize database
 	db, err := storage.New(dbPath)
 	if err != nil {
-		return nil, nil, "", fmt.Errorf("failed to initialize database: %w", err)
+		return nil, nil, fmt.Errorf("failed to initialize database: %w", err)
 	}
 
-	taskSystem := task.New(db.Conn())
-	return db, taskSystem, boardName, nil
+	// A remote board is worked on in a fresh copy, sent back on close
+	if remoteBoard != nil {
+		if err := fetchRemote(db, remoteBoard); err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+	}
+
+	taskSystem := task.New(db.Conn()).WithActor(cfg.UserName())
+
+	// Bring recurring tasks completed in an earlier period back to todo
+	if _, err := taskSystem.ResetRecurring(1, time.Now()); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return db, taskSystem, nil
+}
+
+// missingBoardHelp explains how to get past a missing board: switch to one
+// that exists or recreate it empty. Nothing is recreated behind the user's
+// back.
+func missingBoardHelp(boardSystem *board.System, err error) string {
+	var missing *board.MissingBoardError
+	if !errors.As(err, &missing) {
+		return ""
+	}
+
+	var help []string
+	if boards, err := boardSystem.ListBoards(); err == nil && len(boards) > 0 {
+		var names []string
+		for _, b := range boards {
+			names = append(names, b.Name)
+		}
+		help = append(help, fmt.Sprintf("Switch to an existing board (%s) with: cainban board switch <name>", strings.Join(names, ", ")))
+	}
+	help = append(help, fmt.Sprintf("Recreate '%s' as an empty board with: cainban board create %s", missing.Name, missing.Name))
+	return strings.Join(help, "\n")
 }
 
 func handleInit(args []string) {
-	boardSystem := board.New()
+	fs := newFlagSet("init")
+	local := fs.Bool("local", false, "create a repo-local board in ./.cainban, named after the repository")
+	args = parseFlags(fs, args)
+	if len(args) > 1 {
+		usageError("one board name at most; quote a name with spaces")
+	}
+	boardSystem := newBoardSystem()
+
+	if *local {
+		if len(args) > 0 {
+			usageError("a repo-local board is named after its repository; drop '%s' or --local", args[0])
+		}
+		handleInitLocal(boardSystem)
+		return
+	}
 
 	var boardName string
 	if len(args) > 0 {
@@ -150,14 +529,14 @@ func handleInit(args []string) {
 		_, err := boardSystem.CreateBoard(boardName, fmt.Sprintf("Board for %s", boardName))
 		if err != nil && !strings.Contains(err.Error(), "already exists") {
 			fmt.Printf("Error creating board: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 	}
 
 	// Set as current board
 	if err := boardSystem.SetCurrentBoard(boardName); err != nil {
 		fmt.Printf("Error setting current board: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
 	// Initialize database
@@ -165,7 +544,7 @@ func handleInit(args []string) {
 	db, err := storage.New(dbPath)
 	if err != nil {
 		fmt.Printf("Error initializing database: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
 
@@ -173,69 +552,126 @@ func handleInit(args []string) {
 	fmt.Printf("You can now add tasks with: cainban add \"Your task title\"\n")
 }
 
+// handleInitLocal creates a board in ./.cainban that travels with the repository
+func handleInitLocal(boardSystem *board.System) {
+	boardName, err := boardSystem.InitLocal(".")
+	if err != nil {
+		fmt.Printf("Error creating local board: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	dbPath := boardSystem.GetBoardPath(boardName)
+	db, err := storage.New(dbPath)
+	if err != nil {
+		fmt.Printf("Error initializing database: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	fmt.Printf("Repo-local board '%s' initialized at: %s\n", boardName, dbPath)
+	fmt.Println("It takes precedence over ~/.cainban boards in this directory and below.")
+	fmt.Printf("Commit %s to share the board with the repository.\n", board.LocalDirName)
+}
+
 func handleAdd(args []string) {
+	fs := newFlagSet("add")
+	priorityFlag := fs.String("priority", "", "priority level: none, low, medium, high, critical (or 0-4)")
+	fs.StringVar(priorityFlag, "p", "", "shorthand for --priority")
+	parent := fs.String("parent", "", "ID or title of the task this one is a subtask of")
+	exact := fs.Bool("exact", false, "match the --parent title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+
 	if len(args) == 0 {
 		fmt.Println("Error: task title required")
-		fmt.Println("Usage: cainban add <title> [description] [--priority <level>]")
+		fmt.Println("Usage: cainban add <title> [description] [--priority <level>] [--parent <id|title>] [@context...]")
 		fmt.Println("Priority levels: none, low, medium, high, critical (or 0-4)")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	title := args[0]
 	description := ""
-	var priority interface{} = task.PriorityNone
-
-	// Parse arguments for description and priority
-	i := 1
-	for i < len(args) {
-		if args[i] == "--priority" || args[i] == "-p" {
-			if i+1 >= len(args) {
-				fmt.Println("Error: --priority requires a value")
-				os.Exit(1)
-			}
-			priorityStr := args[i+1]
+	var contexts []string
+	var priority interface{} = cfg.DefaultPriority
+	if !task.IsValidPriority(priority) {
+		fmt.Printf("Error: invalid default_priority '%s' in %s\n", cfg.DefaultPriority, cfg.Path())
+		os.Exit(exitInvalid)
+	}
 
-			// Try to convert numeric strings to integers
-			if priorityInt, err := strconv.Atoi(priorityStr); err == nil {
-				priority = priorityInt
-			} else {
-				priority = priorityStr
-			}
+	if *priorityFlag != "" {
+		// Try to convert numeric strings to integers
+		if priorityInt, err := strconv.Atoi(*priorityFlag); err == nil {
+			priority = priorityInt
+		} else {
+			priority = *priorityFlag
+		}
+
+		if !task.IsValidPriority(priority) {
+			fmt.Println("Error: invalid priority level")
+			fmt.Println("Valid levels: none, low, medium, high, critical (or 0-4)")
+			os.Exit(exitUsage)
+		}
+	}
 
-			if !task.IsValidPriority(priority) {
-				fmt.Println("Error: invalid priority level")
-				fmt.Println("Valid levels: none, low, medium, high, critical (or 0-4)")
-				os.Exit(1)
+	// The other arguments are @contexts and the description
+	for _, arg := range args[1:] {
+		if task.IsContext(arg) {
+			context, err := task.NormalizeContext(arg)
+			if err != nil {
+				usageError("%v", err)
 			}
-			i += 2
+			contexts = append(contexts, context)
+			continue
+		}
+		if description == "" {
+			description = arg
 		} else {
-			// Treat as part of description
-			if description == "" {
-				description = args[i]
-			} else {
-				description += " " + args[i]
-			}
-			i++
+			description += " " + arg
 		}
 	}
 
 	db, taskSystem, boardName, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
 
-	var createdTask *task.Task
-	if priority != task.PriorityNone {
-		createdTask, err = taskSystem.CreateWithPriority(1, title, description, priority)
-	} else {
-		createdTask, err = taskSystem.Create(1, title, description)
+	var parentTask *task.Task
+	if *parent != "" {
+		if parentTask, err = findTaskWith(taskSystem, *parent, *exact); err != nil {
+			fmt.Printf("Error finding parent task: %v\n", err)
+			os.Exit(exitCode(err))
+		}
 	}
 
+	// Validated above, so the level parses
+	level, _ := task.ParsePriority(priority)
+	draft := &task.Task{Title: title, Description: description, Priority: level, Status: task.StatusTodo, Contexts: contexts}
+	if parentTask != nil {
+		draft.ParentID = &parentTask.ID
+	}
+	runPreHook(db, automation.HookEvent{Hook: automation.HookPreCreate, Board: boardName, Task: draft, ToStatus: task.StatusTodo})
+
+	createdTask, err := taskSystem.CreateWithPriority(1, title, description, priority)
+
 	if err != nil {
 		fmt.Printf("Error creating task: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+
+	for _, context := range contexts {
+		if err := taskSystem.AddContext(createdTask.ID, context); err != nil {
+			fmt.Printf("Error adding context: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+	}
+	createdTask.Contexts = contexts
+
+	if parentTask != nil {
+		if err := taskSystem.SetParent(createdTask.ID, &parentTask.ID); err != nil {
+			fmt.Printf("Error setting parent task: %v\n", err)
+			os.Exit(exitCode(err))
+		}
 	}
 
 	priorityStr := ""
@@ -243,39 +679,163 @@ func handleAdd(args []string) {
 		priorityStr = fmt.Sprintf(" [%s]", task.GetPriorityName(createdTask.Priority))
 	}
 
-	fmt.Printf("Created task #%d%s in board '%s': %s\n", createdTask.ID, priorityStr, boardName, createdTask.Title)
+	fmt.Printf("Created task #%d%s%s in board '%s': %s%s\n", createdTask.ID, formatRef(createdTask), priorityStr, boardName, createdTask.Title, formatContexts(createdTask.Contexts))
 	if createdTask.Description != "" {
 		fmt.Printf("Description: %s\n", createdTask.Description)
 	}
+	if parentTask != nil {
+		fmt.Printf("Subtask of #%d: %s\n", parentTask.ID, parentTask.Title)
+	}
+	if t, err := taskSystem.GetByID(createdTask.ID); err == nil {
+		createdTask = t
+	}
+	runPostHook(db, automation.HookEvent{Hook: automation.HookPostCreate, Board: boardName, Task: createdTask, ToStatus: createdTask.Status})
+	runAutomations(db, boardName)
 }
 
 func handleList(args []string) {
+	fs := newFlagSet("list")
+	allBoardsFlag := fs.Bool("all-boards", false, "list the tasks of every board")
+	blockedFlag := fs.Bool("blocked", false, "only tasks waiting on unfinished blockers")
+	unblockedFlag := fs.Bool("unblocked", false, "only tasks not waiting on anything")
+	staleFlag := fs.Bool("stale", false, "only unfinished tasks not updated for stale_days (see cainban config)")
+	filterFlag := fs.String("filter", "", `only tasks matching a filter expression, e.g. "tag=client-a priority>=high"`)
+	priorityFlag := fs.String("priority", "", "only tasks of these priorities, e.g. high,critical")
+	tagFlag := fs.String("tag", "", "only tasks with this tag (GTD context)")
+	assigneeFlag := fs.String("assignee", "", "only tasks assigned to this person")
+	dueBeforeFlag := fs.String("due-before", "", `only tasks due before a time, e.g. friday or "2026-11-01"`)
+	milestoneFlag := fs.String("milestone", "", "only tasks of a milestone, by name or ID")
+	sprintFlag := fs.String("sprint", "", "only tasks planned for a sprint: current, its name or ID, or none for the backlog")
+	sortFlag := fs.String("sort", "", "order by "+strings.Join(task.ListSorts, ", ")+" (default: the board's order)")
+	limitFlag := fs.Int("limit", defaultListLimit, "list at most this many tasks, a page; 0 for all")
+	pageFlag := fs.Int("page", 1, "the page of --limit tasks to list")
+	args = parseFlags(fs, args)
+	allBoards, blocked, unblocked := *allBoardsFlag, *blockedFlag, *unblockedFlag
+	if blocked && unblocked {
+		usageError("use either --blocked or --unblocked")
+	}
+	if *staleFlag && cfg.StaleDays == 0 {
+		usageError("--stale needs stale_days in %s to be above 0", config.DefaultPath())
+	}
+	filter, err := task.ParseFilter(*filterFlag)
+	if err != nil {
+		usageError("%v", err)
+	}
+	opts, err := listOptions(*priorityFlag, *tagFlag, *assigneeFlag, *dueBeforeFlag, *sortFlag, *limitFlag, *pageFlag)
+	if err != nil {
+		usageError("%v", err)
+	}
+	var tasks []*task.Task
+	status := ""
+	context := ""
+
+	// A status and an @context may be given in either order
+	for _, arg := range args {
+		if task.IsContext(arg) {
+			context, err = task.NormalizeContext(arg)
+			if err != nil {
+				usageError("%v", err)
+			}
+			continue
+		}
+		if !task.IsValidStatus(arg) {
+			usageError("invalid status '%s'. Valid statuses: todo, doing, done", arg)
+		}
+		status = arg
+	}
+
+	// --tag is the @context argument spelled as a flag
+	if opts.Tag != "" {
+		if context != "" && context != opts.Tag {
+			usageError("give either an @context or --tag")
+		}
+		context = opts.Tag
+	}
+
+	if allBoards {
+		paged := false
+		fs.Visit(func(f *flag.Flag) { paged = paged || f.Name == "limit" || f.Name == "page" })
+		if opts.Priorities != nil || opts.Assignee != "" || opts.DueBefore != nil || opts.Sort != "" || *sprintFlag != "" || *milestoneFlag != "" || paged {
+			usageError("--priority, --assignee, --due-before, --sprint, --milestone, --sort, --limit and --page list the current board only")
+		}
+		listAllBoards(task.Status(status), context, blocked, unblocked, *staleFlag, filter)
+		return
+	}
+	opts.Status, opts.Tag, opts.BoardOrder = task.Status(status), context, true
+	if blocked || unblocked {
+		opts.Blocked = &blocked
+	}
+	if *staleFlag {
+		before := time.Now().Add(-staleAfter())
+		opts.StaleBefore = &before
+	}
+
+	// A filter expression narrows the tasks down after the query, so the
+	// page is cut after it
+	limit, offset := opts.Limit, opts.Offset
+	if *filterFlag != "" {
+		opts.Limit, opts.Offset = 0, 0
+	}
+
 	db, taskSystem, boardName, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
 
-	var tasks []*task.Task
-
-	if len(args) > 0 {
-		status := args[0]
-		if !task.IsValidStatus(status) {
-			fmt.Printf("Error: invalid status '%s'. Valid statuses: todo, doing, done\n", status)
-			os.Exit(1)
+	var inSprint *sprint.Sprint
+	if strings.EqualFold(*sprintFlag, sprint.None) {
+		opts.NoSprint = true
+	} else if *sprintFlag != "" {
+		if inSprint, err = sprint.New(db.Conn()).Find(1, *sprintFlag, time.Now()); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
 		}
-		tasks, err = taskSystem.ListByStatus(1, task.Status(status))
-	} else {
-		tasks, err = taskSystem.List(1)
+		opts.SprintID = inSprint.ID
+	}
+	var inMilestone *milestone.Milestone
+	if *milestoneFlag != "" {
+		if inMilestone, err = milestone.New(db.Conn()).Find(1, *milestoneFlag); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.MilestoneID = inMilestone.ID
 	}
 
+	tasks, err = taskSystem.ListWith(1, opts)
+	var total int
+	if err == nil && *filterFlag != "" {
+		tasks = filter.Apply(tasks, time.Now())
+		total = len(tasks)
+		tasks = tasks[min(offset, total):]
+		if limit > 0 && len(tasks) > limit {
+			tasks = tasks[:limit]
+		}
+	} else if err == nil {
+		total, err = taskSystem.CountWith(1, opts)
+	}
 	if err != nil {
 		fmt.Printf("Error listing tasks: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
-	fmt.Printf("Board: %s\n", boardName)
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "tasks": tasks, "total": total})
+		return
+	}
+
+	if context != "" {
+		fmt.Printf("Board: %s (context %s)\n", boardName, context)
+	} else if inSprint != nil {
+		fmt.Printf("Board: %s (sprint %s, %s to %s)\n", boardName, inSprint.Name, inSprint.Start, inSprint.End)
+	} else if opts.NoSprint {
+		fmt.Printf("Board: %s (no sprint)\n", boardName)
+	} else if inMilestone != nil {
+		fmt.Printf("Board: %s (milestone %s, %d%% done)\n", boardName, inMilestone.Name, inMilestone.Percent)
+	} else {
+		fmt.Printf("Board: %s\n", boardName)
+	}
 
 	if len(tasks) == 0 {
 		fmt.Println("No tasks found")
@@ -297,62 +857,152 @@ func handleList(args []string) {
 				if t.Priority > 0 {
 					priorityStr = fmt.Sprintf(" [%s]", task.GetPriorityName(t.Priority))
 				}
-				fmt.Printf("  #%d%s %s\n", t.ID, priorityStr, t.Title)
+				fmt.Printf("  #%d%s%s %s%s%s%s%s%s%s%s%s%s%s%s\n", t.ID, formatRef(t), priorityStr, t.Title, formatEstimate(t.Estimate), formatAssignee(t.Assignee), formatClaim(t), formatRecurrence(t.Recurrence), formatEffort(t.Size, t.Energy), formatContexts(t.Contexts), formatDue(t), formatStale(t), formatBlocked(t), formatReactions(t), formatRollup(t))
 				if t.Description != "" {
 					fmt.Printf("      %s\n", t.Description)
 				}
 			}
 		}
 	}
+
+	// Say where the rest of a cut-off list is
+	if shown := offset + len(tasks); shown < total {
+		fmt.Printf("\nShowing %d-%d of %d tasks; --page %d for more, --limit 0 for all\n", offset+1, shown, total, *pageFlag+1)
+	}
+}
+
+// defaultListLimit caps list output on large boards unless --limit says
+// otherwise
+const defaultListLimit = 200
+
+// listOptions turns the filter and sort flags of list into options for
+// the query
+func listOptions(priorities, tag, assignee, dueBefore, sortBy string, limit, page int) (task.ListOptions, error) {
+	opts := task.ListOptions{Assignee: assignee, Sort: sortBy, Limit: limit, Offset: (page - 1) * limit}
+	if priorities != "" {
+		for _, name := range strings.Split(priorities, ",") {
+			// A level may be given by name or as 0-4
+			var value interface{} = strings.TrimSpace(name)
+			if level, err := strconv.Atoi(strings.TrimSpace(name)); err == nil {
+				value = level
+			}
+			priority, err := task.ParsePriority(value)
+			if err != nil {
+				return opts, err
+			}
+			opts.Priorities = append(opts.Priorities, priority)
+		}
+	}
+	if tag != "" {
+		context, err := task.NormalizeContext(tag)
+		if err != nil {
+			return opts, err
+		}
+		opts.Tag = context
+	}
+	if dueBefore != "" {
+		at, err := dateparse.Parse(dueBefore, time.Now())
+		if err != nil {
+			return opts, err
+		}
+		opts.DueBefore = &at
+	}
+	if sortBy != "" && !slices.Contains(task.ListSorts, sortBy) {
+		return opts, fmt.Errorf("invalid sort '%s'. Valid sorts: %s", sortBy, strings.Join(task.ListSorts, ", "))
+	}
+	if limit < 0 {
+		return opts, fmt.Errorf("--limit cannot be negative")
+	}
+	if page < 1 || page > 1 && limit == 0 {
+		return opts, fmt.Errorf("--page counts from 1, in pages of --limit tasks")
+	}
+	return opts, nil
 }
 
 func handleMove(args []string) {
-	if len(args) < 2 {
+	fs := newFlagSet("move")
+	forceFlag := fs.Bool("force", false, "move even when the column is at its WIP limit")
+	exactFlag := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) == 0 {
+		picked, status := pickTask([]string{string(task.StatusTodo), string(task.StatusDoing), string(task.StatusDone)})
+		if picked == nil {
+			os.Exit(exitFailure)
+		}
+		args = []string{strconv.Itoa(picked.ID), status}
+	}
+	if len(args) != 2 {
 		fmt.Println("Error: task ID/title and status required")
-		fmt.Println("Usage: cainban move <id|title> <status>")
+		fmt.Println("Usage: cainban move <id|title> <status> [--force] [--exact]")
 		fmt.Println("Examples:")
 		fmt.Println("  cainban move 5 doing")
 		fmt.Println("  cainban move \"bubble tea\" doing")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	taskIdentifier := args[0]
 	status := args[1]
 	if !task.IsValidStatus(status) {
-		fmt.Printf("Error: invalid status '%s'. Valid statuses: todo, doing, done\n", status)
-		os.Exit(1)
+		usageError("invalid status '%s'. Valid statuses: todo, doing, done", status)
 	}
+	force := *forceFlag
 
 	db, taskSystem, boardName, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
 
 	// Find task by ID or fuzzy match
-	foundTask, err := taskSystem.FindTaskByFuzzyID(1, taskIdentifier)
+	foundTask, err := findTaskWith(taskSystem, taskIdentifier, *exactFlag)
 	if err != nil {
 		fmt.Printf("Error finding task: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+
+	if limit := cfg.WIPLimit(status); limit > 0 && foundTask.Status != task.Status(status) && !force {
+		inColumn, err := taskSystem.ListByStatus(1, task.Status(status))
+		if err != nil {
+			fmt.Printf("Error checking WIP limit: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if len(inColumn) >= limit {
+			fmt.Printf("Error: WIP limit reached for %s (%d/%d tasks)\n", status, len(inColumn), limit)
+			fmt.Println("Finish something first, or use --force to move anyway")
+			os.Exit(1)
+		}
 	}
 
+	move := automation.HookEvent{Hook: automation.HookPreMove, Board: boardName, Task: foundTask, FromStatus: foundTask.Status, ToStatus: task.Status(status)}
+	runPreHook(db, move)
+
 	if err := taskSystem.UpdateStatus(foundTask.ID, task.Status(status)); err != nil {
 		fmt.Printf("Error moving task: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
 	fmt.Printf("Moved task #%d \"%s\" to %s in board '%s'\n", foundTask.ID, foundTask.Title, status, boardName)
+	if t, err := taskSystem.GetByID(foundTask.ID); err == nil {
+		move.Task = t
+	}
+	move.Hook = automation.HookPostMove
+	runPostHook(db, move)
+	runAutomations(db, boardName)
 }
 
 func handleGet(args []string) {
+	fs := newFlagSet("get")
+	plain := fs.Bool("plain", false, "show the description as written instead of rendering its Markdown")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
 	if len(args) == 0 {
 		fmt.Println("Error: task ID or title required")
-		fmt.Println("Usage: cainban get <id|title>")
+		fmt.Println("Usage: cainban get <id|title> [--plain] [--exact]")
 		fmt.Println("Examples:")
 		fmt.Println("  cainban get 5")
 		fmt.Println("  cainban get \"bubble tea\"")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	taskIdentifier := args[0]
@@ -360,92 +1010,300 @@ func handleGet(args []string) {
 	db, taskSystem, boardName, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
 
 	// Find task by ID or fuzzy match
-	t, err := taskSystem.FindTaskByFuzzyID(1, taskIdentifier)
+	t, err := findTaskWith(taskSystem, taskIdentifier, *exact)
 	if err != nil {
 		fmt.Printf("Error finding task: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+
+	comments, err := taskSystem.ListComments(t.ID)
+	if err != nil {
+		fmt.Printf("Error loading comments: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	checkpoint, err := taskSystem.GetCheckpoint(t.ID)
+	if err != nil {
+		fmt.Printf("Error loading context: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	subtasks, err := taskSystem.Subtasks(t.ID)
+	if err != nil {
+		fmt.Printf("Error loading subtasks: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	attachments, err := taskSystem.ListAttachments(t.ID)
+	if err != nil {
+		fmt.Printf("Error loading attachments: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	history, err := taskSystem.GetHistory(t.ID)
+	if err != nil {
+		fmt.Printf("Error loading history: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	worklogs, err := taskSystem.ListWorklogs(t.ID)
+	if err != nil {
+		fmt.Printf("Error loading worklog: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "task": t, "subtasks": subtasks, "comments": comments, "attachments": attachments, "history": history, "worklogs": worklogs})
+		return
 	}
 
 	fmt.Printf("Board: %s\n", boardName)
-	fmt.Printf("Task #%d [%s]\n", t.ID, t.Status)
+	fmt.Printf("Task #%d%s [%s]\n", t.ID, formatRef(t), t.Status)
 	fmt.Printf("Title: %s\n", t.Title)
+	if t.Hash != "" {
+		fmt.Printf("Hash: %s\n", t.ShortHash())
+	}
 	if t.Priority > 0 {
 		fmt.Printf("Priority: %s (%d)\n", task.GetPriorityName(t.Priority), t.Priority)
 	}
+	if t.Estimate > 0 {
+		fmt.Printf("Estimate: %d pts\n", t.Estimate)
+	}
+	if t.Assignee != "" {
+		fmt.Printf("Assignee: %s\n", t.Assignee)
+	}
+	if t.InProgressBy != "" {
+		fmt.Printf("Claimed: by %s since %s\n", t.InProgressBy, t.ClaimedAt.Local().Format("2006-01-02 15:04"))
+	}
+	if t.Recurrence != task.RecurrenceNone {
+		fmt.Printf("Repeats: %s\n", t.Recurrence)
+	}
+	if t.Size != task.SizeNone {
+		fmt.Printf("Size: %s (~%s)\n", t.Size, formatDuration(t.Size.Duration()))
+	}
+	if t.Energy != task.EnergyNone {
+		fmt.Printf("Energy: %s\n", t.Energy)
+	}
+	if len(t.Contexts) > 0 {
+		fmt.Printf("Contexts: %s\n", strings.Join(t.Contexts, " "))
+	}
+	if t.DueAt != nil {
+		fmt.Printf("Due: %s\n", t.DueAt.Local().Format("2006-01-02 15:04"))
+	}
+	if len(t.Reactions) > 0 {
+		fmt.Printf("Reactions: %s\n", task.FormatReactions(t.Reactions))
+	}
+	if t.Votes > 0 {
+		fmt.Printf("Votes: %d\n", t.Votes)
+	}
+	if t.ParentID != nil {
+		fmt.Printf("Parent: #%d\n", *t.ParentID)
+	}
+	if t.SprintID != nil {
+		if sp, err := sprint.New(db.Conn()).Get(*t.SprintID); err == nil {
+			fmt.Printf("Sprint: %s (%s to %s)\n", sp.Name, sp.Start, sp.End)
+		}
+	}
+	if t.MilestoneID != nil {
+		if ms, err := milestone.New(db.Conn()).Get(*t.MilestoneID); err == nil {
+			fmt.Printf("Milestone: %s (%d%% done)\n", ms.Name, ms.Percent)
+		}
+	}
+	if t.Rollup != nil {
+		fmt.Printf("Subtasks: %s (%d/%d done)\n", t.Rollup, t.Rollup.DoneSubtasks, t.Rollup.Subtasks)
+	}
 	if t.Description != "" {
-		fmt.Printf("Description: %s\n", t.Description)
+		if !*plain && onTerminal(os.Stdout) {
+			fmt.Printf("Description:\n%s\n", renderDescription(t.Description))
+		} else {
+			fmt.Printf("Description: %s\n", t.Description)
+		}
+	}
+	fmt.Printf("Created: %s%s\n", t.CreatedAt.Format("2006-01-02 15:04:05"), formatActor(t.CreatedBy))
+	fmt.Printf("Updated: %s%s\n", t.UpdatedAt.Format("2006-01-02 15:04:05"), formatActor(t.UpdatedBy))
+	if checkpoint != nil {
+		fmt.Printf("Context: %d bytes, saved %s (cainban context get %d)\n",
+			len(checkpoint.Content), checkpoint.UpdatedAt.Local().Format("2006-01-02 15:04"), t.ID)
+	}
+	if len(worklogs) > 0 {
+		last := worklogs[len(worklogs)-1]
+		fmt.Printf("Worklog: %d logged, last %s%s (cainban worklog %d)\n",
+			len(worklogs), last.CreatedAt.Local().Format("2006-01-02 15:04"), formatActor(last.Agent), t.ID)
+	}
+
+	if len(subtasks) > 0 {
+		fmt.Println()
+		fmt.Println("Subtasks:")
+		for _, s := range subtasks {
+			fmt.Printf("  #%d [%s] %s%s%s\n", s.ID, s.Status, s.Title, formatEstimate(s.Estimate), formatRollup(s))
+		}
+	}
+
+	if len(attachments) > 0 {
+		fmt.Println()
+		fmt.Println("Attachments:")
+		for _, a := range attachments {
+			fmt.Printf("  %s\n", formatAttachment(a))
+		}
+	}
+
+	if len(history) > 0 {
+		fmt.Println()
+		fmt.Println("History:")
+		for _, e := range history {
+			what := "created in " + string(e.ToStatus)
+			if e.Type == task.EventStatusChanged {
+				what = fmt.Sprintf("moved %s → %s", e.FromStatus, e.ToStatus)
+			}
+			fmt.Printf("  %s %s%s\n", e.CreatedAt.Local().Format("2006-01-02 15:04"), what, formatActor(e.Actor))
+		}
+	}
+
+	if len(comments) > 0 {
+		fmt.Println()
+		fmt.Println("Comments:")
+		for _, c := range comments {
+			author := c.Author
+			if author == "" {
+				author = "anonymous"
+			}
+			fmt.Printf("  %s %s: %s\n", c.CreatedAt.Local().Format("2006-01-02 15:04"), author, c.Body)
+		}
 	}
-	fmt.Printf("Created: %s\n", t.CreatedAt.Format("2006-01-02 15:04:05"))
-	fmt.Printf("Updated: %s\n", t.UpdatedAt.Format("2006-01-02 15:04:05"))
 }
 
+// handleUpdate changes a task's title, description or both; what is not
+// given is left as it is
 func handleUpdate(args []string) {
-	if len(args) < 2 {
-		fmt.Println("Error: task ID/title and new title required")
-		fmt.Println("Usage: cainban update <id|title> <new_title> [description]")
+	fs := newFlagSet("update")
+	fs.String("title", "", "new title")
+	fs.String("description", "", "new description; \"\" clears it")
+	appendDescription := fs.String("append-description", "", "text to add to the end of the description, on a line of its own")
+	useEditor := fs.Bool("editor", false, "edit the title and description in your editor, as cainban edit does")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+
+	var opts task.UpdateOptions
+	fs.Visit(func(f *flag.Flag) {
+		value := f.Value.String()
+		switch f.Name {
+		case "title":
+			opts.Title = &value
+		case "description":
+			opts.Description = &value
+		}
+	})
+	opts.AppendDescription = *appendDescription
+
+	// The title and description may also follow the task, as they always could
+	if len(args) > 1 {
+		if opts.Title != nil {
+			usageError("give the new title either as an argument or with --title")
+		}
+		opts.Title = &args[1]
+	}
+	if len(args) > 2 {
+		if opts.Description != nil {
+			usageError("give the new description either as arguments or with --description")
+		}
+		description := strings.Join(args[2:], " ")
+		opts.Description = &description
+	}
+
+	changes := opts.Title != nil || opts.Description != nil || opts.AppendDescription != ""
+	if len(args) < 1 || (!changes && !*useEditor) {
+		fmt.Println("Error: task ID/title and something to change required")
+		fmt.Println("Usage: cainban update <id|title> [title] [description] [--title <t>] [--description <d>] [--append-description <d>] [--editor] [--exact]")
 		fmt.Println("Examples:")
 		fmt.Println("  cainban update 5 \"New title\"")
-		fmt.Println("  cainban update \"bubble tea\" \"Updated TUI task\"")
-		os.Exit(1)
+		fmt.Println("  cainban update 5 --description \"Only the description changes\"")
+		fmt.Println("  cainban update \"bubble tea\" --append-description \"Found the resize bug\"")
+		fmt.Println("  cainban update 5 --editor")
+		os.Exit(exitUsage)
 	}
-
-	taskIdentifier := args[0]
-	title := args[1]
-	description := ""
-	if len(args) > 2 {
-		description = strings.Join(args[2:], " ")
+	if changes && *useEditor {
+		usageError("--editor edits the title and description itself; leave out the other changes")
 	}
+	taskIdentifier := args[0]
 
 	db, taskSystem, boardName, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
 
 	// Find task by ID or fuzzy match
-	foundTask, err := taskSystem.FindTaskByFuzzyID(1, taskIdentifier)
+	foundTask, err := findTaskWith(taskSystem, taskIdentifier, *exact)
 	if err != nil {
 		fmt.Printf("Error finding task: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+
+	if *useEditor {
+		var changed bool
+		if opts, changed = editTask(foundTask, boardName); !changed {
+			fmt.Printf("Task #%d unchanged\n", foundTask.ID)
+			return
+		}
 	}
 
-	if err := taskSystem.Update(foundTask.ID, title, description); err != nil {
+	if err := taskSystem.UpdateFields(foundTask.ID, opts); err != nil {
 		fmt.Printf("Error updating task: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
+	title := foundTask.Title
+	if opts.Title != nil {
+		title = *opts.Title
+	}
 	fmt.Printf("Updated task #%d in board '%s': %s\n", foundTask.ID, boardName, title)
 }
 
 func handleSearch(args []string) {
+	fs := newFlagSet("search")
+	allBoards := fs.Bool("all-boards", false, "search the tasks of every board")
+	args = parseFlags(fs, args)
 	if len(args) == 0 {
 		fmt.Println("Error: search query required")
-		fmt.Println("Usage: cainban search <query>")
+		fmt.Println("Usage: cainban search [--all-boards] <query>")
 		fmt.Println("Examples:")
 		fmt.Println("  cainban search \"bubble tea\"")
 		fmt.Println("  cainban search \"prep public\"")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	query := strings.Join(args, " ")
+	if strings.TrimSpace(query) == "" {
+		usageError("search query required")
+	}
 
-	db, taskSystem, boardName, err := getCurrentBoardDB()
-	if err != nil {
+	if *allBoards {
+		searchAllBoards(query)
+		return
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
 
 	matches, err := taskSystem.SearchTasks(1, query)
 	if err != nil {
 		fmt.Printf("Error searching tasks: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "query": query, "tasks": matches})
+		return
 	}
 
 	if len(matches) == 0 {
@@ -473,14 +1331,17 @@ func handleSearch(args []string) {
 }
 
 func handlePriority(args []string) {
+	fs := newFlagSet("priority")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
 	if len(args) < 2 {
 		fmt.Println("Error: task ID/title and priority level required")
-		fmt.Println("Usage: cainban priority <id|title> <level>")
+		fmt.Println("Usage: cainban priority <id|title> <level> [--exact]")
 		fmt.Println("Priority levels: none, low, medium, high, critical (or 0-4)")
 		fmt.Println("Examples:")
 		fmt.Println("  cainban priority 5 high")
 		fmt.Println("  cainban priority \"bubble tea\" critical")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	taskIdentifier := args[0]
@@ -495,26 +1356,26 @@ func handlePriority(args []string) {
 	if !task.IsValidPriority(priorityValue) {
 		fmt.Printf("Error: invalid priority '%s'\n", args[1])
 		fmt.Println("Valid priorities: none, low, medium, high, critical (or 0-4)")
-		os.Exit(1)
+		os.Exit(exitInvalid)
 	}
 
 	db, taskSystem, boardName, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
 
 	// Find task by ID or fuzzy match
-	foundTask, err := taskSystem.FindTaskByFuzzyID(1, taskIdentifier)
+	foundTask, err := findTaskWith(taskSystem, taskIdentifier, *exact)
 	if err != nil {
 		fmt.Printf("Error finding task: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
 	if err := taskSystem.UpdatePriority(foundTask.ID, priorityValue); err != nil {
 		fmt.Printf("Error updating task priority: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
 	priorityLevel, _ := task.ParsePriority(priorityValue)
@@ -522,15 +1383,527 @@ func handlePriority(args []string) {
 	fmt.Printf("Updated task #%d \"%s\" priority to %s (%d) in board '%s'\n", foundTask.ID, foundTask.Title, priorityName, priorityLevel, boardName)
 }
 
+func handleEstimate(args []string) {
+	fs := newFlagSet("estimate")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 2 {
+		fmt.Println("Error: task ID/title and points required")
+		fmt.Println("Usage: cainban estimate <id|title> <points> [--exact]")
+		fmt.Println("Examples:")
+		fmt.Println("  cainban estimate 5 3")
+		fmt.Println("  cainban estimate \"bubble tea\" 8")
+		os.Exit(exitUsage)
+	}
+
+	taskIdentifier := args[0]
+	points, err := strconv.Atoi(args[1])
+	if err != nil {
+		fmt.Printf("Error: invalid points '%s'\n", args[1])
+		os.Exit(exitInvalid)
+	}
+	if err := task.ValidateEstimate(points); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	// Find task by ID or fuzzy match
+	foundTask, err := findTaskWith(taskSystem, taskIdentifier, *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if err := taskSystem.UpdateEstimate(foundTask.ID, points); err != nil {
+		fmt.Printf("Error updating task estimate: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Updated task #%d \"%s\" estimate to %d pts in board '%s'\n", foundTask.ID, foundTask.Title, points, boardName)
+}
+
+func handleAssign(args []string) {
+	fs := newFlagSet("assign")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 1 {
+		fmt.Println("Error: task ID/title required")
+		fmt.Println("Usage: cainban assign <id|title> [assignee] [--exact]")
+		fmt.Println("Examples:")
+		fmt.Println("  cainban assign 5 alice")
+		fmt.Println("  cainban assign \"bubble tea\"        # unassign")
+		os.Exit(exitUsage)
+	}
+
+	taskIdentifier := args[0]
+	assignee := ""
+	if len(args) > 1 {
+		assignee = args[1]
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	// Find task by ID or fuzzy match
+	foundTask, err := findTaskWith(taskSystem, taskIdentifier, *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	warning, err := taskSystem.Assign(foundTask.ID, assignee)
+	if err != nil {
+		fmt.Printf("Error assigning task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if assignee == "" {
+		fmt.Printf("Unassigned task #%d \"%s\" in board '%s'\n", foundTask.ID, foundTask.Title, boardName)
+		return
+	}
+
+	fmt.Printf("Assigned task #%d \"%s\" to %s in board '%s'\n", foundTask.ID, foundTask.Title, assignee, boardName)
+	if warning != nil {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+}
+
+func handleCapacity(args []string) {
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	if len(args) > 0 && args[0] == "set" {
+		if len(args) < 3 {
+			fmt.Println("Error: assignee and points required")
+			fmt.Println("Usage: cainban capacity set <assignee> <points>")
+			os.Exit(exitUsage)
+		}
+
+		points, err := strconv.Atoi(args[2])
+		if err != nil {
+			fmt.Printf("Error: invalid points '%s'\n", args[2])
+			os.Exit(exitInvalid)
+		}
+
+		if err := taskSystem.SetCapacity(args[1], points); err != nil {
+			fmt.Printf("Error setting capacity: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if points == 0 {
+			fmt.Printf("Removed capacity limit for %s in board '%s'\n", args[1], boardName)
+		} else {
+			fmt.Printf("Set capacity for %s to %d pts in board '%s'\n", args[1], points, boardName)
+		}
+
+		if warning, err := taskSystem.CheckCapacity(args[1]); err == nil && warning != nil {
+			fmt.Printf("Warning: %s\n", warning)
+		}
+		return
+	}
+
+	capacities, err := taskSystem.ListCapacities()
+	if err != nil {
+		fmt.Printf("Error listing capacities: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if len(capacities) == 0 {
+		fmt.Printf("No capacities configured in board '%s'\n", boardName)
+		fmt.Println("Set one with: cainban capacity set <assignee> <points>")
+		return
+	}
+
+	fmt.Printf("Capacity in board '%s':\n", boardName)
+	for _, c := range capacities {
+		marker := ""
+		if c.Load > c.Points {
+			marker = "  ⚠ over capacity"
+		}
+		fmt.Printf("  %-15s %3d / %3d pts%s\n", c.Assignee, c.Load, c.Points, marker)
+	}
+}
+
+func handleReport(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Error: report type required")
+		fmt.Println("Usage: cainban report <type>")
+		fmt.Println("Types: velocity, board")
+		os.Exit(exitUsage)
+	}
+
+	switch args[0] {
+	case "velocity":
+		handleVelocityReport(args[1:])
+	case "board":
+		handleBoardReport(args[1:])
+	default:
+		fmt.Printf("Unknown report type: %s\n", args[0])
+		fmt.Println("Types: velocity, board")
+		os.Exit(exitUsage)
+	}
+}
+
+func handleRecur(args []string) {
+	fs := newFlagSet("recur")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 2 {
+		fmt.Println("Error: task ID/title and recurrence required")
+		fmt.Println("Usage: cainban recur <id|title> <daily|weekly|none> [--exact]")
+		os.Exit(exitUsage)
+	}
+
+	recurrence, err := task.ParseRecurrence(args[1])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if err := taskSystem.SetRecurrence(foundTask.ID, recurrence); err != nil {
+		fmt.Printf("Error updating task recurrence: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if recurrence == task.RecurrenceNone {
+		fmt.Printf("Task #%d \"%s\" no longer repeats in board '%s'\n", foundTask.ID, foundTask.Title, boardName)
+		return
+	}
+	fmt.Printf("Task #%d \"%s\" now repeats %s in board '%s'\n", foundTask.ID, foundTask.Title, recurrence, boardName)
+	fmt.Println("Once done, it returns to todo at the start of the next period.")
+}
+
+func handleHabits(args []string) {
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	habits, err := report.New(db.Conn()).Habits(1, time.Now())
+	if err != nil {
+		fmt.Printf("Error computing habits: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "habits": habits})
+		return
+	}
+
+	if len(habits) == 0 {
+		fmt.Printf("No recurring tasks in board '%s'\n", boardName)
+		fmt.Println("Make one with: cainban recur <id|title> <daily|weekly>")
+		return
+	}
+
+	fmt.Printf("Habits in board '%s' (last %d periods, oldest first)\n\n", boardName, report.HabitWindow)
+	for _, h := range habits {
+		fmt.Printf("  #%-3d %-28s %-6s %s  streak %2d (best %2d)  %3.0f%%\n",
+			h.TaskID, truncate(h.Title, 28), h.Recurrence, h.Row(), h.Streak, h.BestStreak, h.Rate*100)
+	}
+}
+
+func handleVelocityReport(args []string) {
+	weeks := parseWeeksFlag("report velocity", args, 4)
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	velocity, err := report.New(db.Conn()).Velocity(1, weeks, time.Now())
+	if err != nil {
+		fmt.Printf("Error computing velocity: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	maxPoints := 0
+	for _, w := range velocity {
+		if w.Points > maxPoints {
+			maxPoints = w.Points
+		}
+	}
+
+	fmt.Printf("Velocity for board '%s' (last %d weeks):\n\n", boardName, weeks)
+	for _, w := range velocity {
+		bar := ""
+		if maxPoints > 0 {
+			bar = strings.Repeat("█", w.Points*30/maxPoints)
+		}
+		fmt.Printf("  %s  %-30s %3d pts (%d tasks)\n", w.WeekStart.Format("2006-01-02"), bar, w.Points, w.Tasks)
+	}
+	fmt.Printf("\nAverage: %.1f pts/week\n", report.AverageVelocity(velocity))
+}
+
+// handleBoardReport writes the one-page printout of the current board, to
+// <board>-report-<date>.html unless told otherwise
+func handleBoardReport(args []string) {
+	fs := newFlagSet("report board")
+	output := fs.String("output", "", "file to write, - for standard output (default: <board>-report-<date>.html)")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		usageError("unknown argument '%s'", args[0])
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	now := time.Now()
+	printout, err := report.New(db.Conn()).Printout(boardName, 1, now)
+	if err != nil {
+		fmt.Printf("Error building report: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(printout)
+		return
+	}
+
+	if *output == "" {
+		*output = fmt.Sprintf("%s-report-%s.html", boardName, now.Format("2006-01-02"))
+	}
+	writeOutput(*output, func(w io.Writer) error {
+		_, err := io.WriteString(w, printout.HTML())
+		return err
+	})
+
+	if *output != "-" {
+		fmt.Printf("Wrote the report of board '%s' to %s\n", boardName, *output)
+		fmt.Println("Open it in a browser to print it or save it as PDF")
+	}
+}
+
+func handleStats(args []string) {
+	weeks := parseWeeksFlag("stats", args, 6)
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	stats, err := report.New(db.Conn()).Stats(1, weeks, time.Now())
+	if err != nil {
+		fmt.Printf("Error computing statistics: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Statistics for board '%s'\n\n", boardName)
+
+	fmt.Println("Tasks per column:")
+	for _, status := range task.ValidStatuses() {
+		fmt.Printf("  %-6s %d\n", status, stats.Counts[string(status)])
+	}
+
+	if stats.CompletedTasks > 0 {
+		fmt.Printf("\nAverage cycle time (todo → done): %s over %d tasks\n",
+			report.FormatDuration(stats.AverageCycleTime), stats.CompletedTasks)
+	} else {
+		fmt.Println("\nAverage cycle time (todo → done): n/a")
+	}
+
+	fmt.Printf("\nThroughput (last %d weeks):\n", weeks)
+	for _, w := range stats.Throughput {
+		fmt.Printf("  %s  %d tasks\n", w.WeekStart.Format("2006-01-02"), w.Tasks)
+	}
+
+	fmt.Println("\nCumulative flow:")
+	fmt.Print(report.RenderFlow(stats.Flow, 40))
+}
+
+// parseWeeksFlag reads the flags of a report command, an optional --weeks
+func parseWeeksFlag(command string, args []string, defaultWeeks int) int {
+	fs := newFlagSet(command)
+	weeks := fs.Int("weeks", defaultWeeks, "number of weeks to report on")
+	fs.IntVar(weeks, "w", defaultWeeks, "short for --weeks")
+	if rest := parseFlags(fs, args); len(rest) > 0 {
+		usageError("unknown argument '%s'", rest[0])
+	}
+	if *weeks <= 0 {
+		fmt.Printf("Error: invalid number of weeks '%d'\n", *weeks)
+		os.Exit(exitInvalid)
+	}
+	return *weeks
+}
+
+// printJSON writes a value as indented JSON for machine-readable output
+func printJSON(value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Printf("Error encoding JSON: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Println(string(data))
+}
+
+// formatAssignee renders a task assignee suffix for list output
+func formatAssignee(assignee string) string {
+	if assignee == "" {
+		return ""
+	}
+	return " → " + assignee
+}
+
+// formatClaim renders the agent a task is claimed by for list output
+func formatClaim(t *task.Task) string {
+	if t.InProgressBy == "" {
+		return ""
+	}
+	return " (claimed by " + t.InProgressBy + ")"
+}
+
+// formatDue renders the due date of an unfinished task for list output
+func formatDue(t *task.Task) string {
+	if t.DueAt == nil || t.Status == task.StatusDone {
+		return ""
+	}
+	due := t.DueAt.Local().Format("Mon Jan 2 15:04")
+	if t.IsOverdue(time.Now()) {
+		return " ⚠ overdue since " + due
+	}
+	return " ⏰ due " + due
+}
+
+// staleAfter is how long an unfinished task may go without an update
+// before it is stale, from stale_days; 0 when nothing is
+func staleAfter() time.Duration {
+	return time.Duration(cfg.StaleDays) * 24 * time.Hour
+}
+
+// formatStale renders the stale badge of a task for list output
+func formatStale(t *task.Task) string {
+	if !t.IsStale(time.Now(), staleAfter()) {
+		return ""
+	}
+	return fmt.Sprintf(" 💤 no update for %d days", t.StaleDays(time.Now()))
+}
+
+// formatBlocked renders the blocked badge of a task for list output
+func formatBlocked(t *task.Task) string {
+	if !t.IsBlocked() {
+		return ""
+	}
+	ids := make([]string, len(t.BlockedBy))
+	for i, id := range t.BlockedBy {
+		ids[i] = fmt.Sprintf("#%d", id)
+	}
+	return " 🚫 blocked by " + strings.Join(ids, ", ")
+}
+
+// formatReactions renders the reaction counts of a task for list output
+func formatReactions(t *task.Task) string {
+	if len(t.Reactions) == 0 {
+		return ""
+	}
+	return "  " + task.FormatReactions(t.Reactions)
+}
+
+// formatRollup renders the subtask progress of a parent task for list output
+func formatRollup(t *task.Task) string {
+	if t.Rollup == nil {
+		return ""
+	}
+	return " [" + t.Rollup.String() + "]"
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// formatRecurrence renders a recurring task marker for list output
+func formatRecurrence(recurrence task.Recurrence) string {
+	if recurrence == task.RecurrenceNone {
+		return ""
+	}
+	return fmt.Sprintf(" ↻ %s", recurrence)
+}
+
+// formatContexts renders a task's GTD contexts for list output
+func formatContexts(contexts []string) string {
+	if len(contexts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(contexts, " ")
+}
+
+// formatRef renders a task's number, e.g. " T-007", when the board numbers
+// its tasks
+func formatRef(t *task.Task) string {
+	if t.Ref == "" {
+		return ""
+	}
+	return " " + t.Ref
+}
+
+// formatActor renders who made a change, e.g. " by alice", for changes
+// recorded with who made them
+func formatActor(actor string) string {
+	if actor == "" {
+		return ""
+	}
+	return " by " + actor
+}
+
+// formatEstimate renders a task estimate suffix for list output
+func formatEstimate(points int) string {
+	if points <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d pts)", points)
+}
+
 func handleBoard(args []string) {
 	if len(args) == 0 {
 		fmt.Println("Error: board command required")
 		fmt.Println("Usage: cainban board <command>")
-		fmt.Println("Commands: list, current, switch, create, delete")
-		os.Exit(1)
+		fmt.Println("Commands: list, current, switch, create, delete, readme")
+		os.Exit(exitUsage)
 	}
 
-	boardSystem := board.New()
+	boardSystem := newBoardSystem()
 	command := args[0]
 
 	switch command {
@@ -538,7 +1911,7 @@ func handleBoard(args []string) {
 		boards, err := boardSystem.ListBoards()
 		if err != nil {
 			fmt.Printf("Error listing boards: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 
 		if len(boards) == 0 {
@@ -547,15 +1920,31 @@ func handleBoard(args []string) {
 		}
 
 		currentBoard, _ := boardSystem.GetCurrentBoard()
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"current": currentBoard, "boards": boards})
+			return
+		}
+		if err := boardSystem.CheckBoard(currentBoard); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
 		fmt.Println("Available boards:")
 		for _, b := range boards {
 			marker := "  "
-			if b.Name == currentBoard {
+			if b.Path == boardSystem.GetBoardPath(currentBoard) {
 				marker = "* "
 			}
-			fmt.Printf("%s%s\n", marker, b.Name)
-			if b.Description != "" {
+			if b.Remote != "" {
+				fmt.Printf("%s%s (remote: %s)\n", marker, b.Name, b.Remote)
+			} else {
+				fmt.Printf("%s%s\n", marker, b.Name)
+			}
+			switch {
+			case b.Description != "" && !b.CreatedAt.IsZero():
+				fmt.Printf("    %s (created %s)\n", b.Description, b.CreatedAt.Local().Format("2006-01-02"))
+			case b.Description != "":
 				fmt.Printf("    %s\n", b.Description)
+			case !b.CreatedAt.IsZero():
+				fmt.Printf("    created %s\n", b.CreatedAt.Local().Format("2006-01-02"))
 			}
 		}
 
@@ -563,38 +1952,54 @@ func handleBoard(args []string) {
 		currentBoard, err := boardSystem.GetCurrentBoard()
 		if err != nil {
 			fmt.Printf("Error getting current board: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 		fmt.Printf("Current board: %s\n", currentBoard)
+		if err := boardSystem.CheckBoard(currentBoard); err != nil {
+			fmt.Printf("Warning: %v\n%s\n", err, missingBoardHelp(boardSystem, err))
+		}
+		if boardSystem.Override() != "" {
+			fmt.Printf("Selected with --board or %s\n", config.BoardEnv)
+		} else if localDir := boardSystem.LocalDir(); localDir != "" {
+			fmt.Printf("Repo-local board at: %s\n", localDir)
+		} else if boardSystem.ProjectBoard() == currentBoard {
+			fmt.Println("Picked for this directory (auto_board)")
+		}
 
 	case "switch":
 		if len(args) < 2 {
 			fmt.Println("Error: board name required")
 			fmt.Println("Usage: cainban board switch <name>")
-			os.Exit(1)
+			os.Exit(exitUsage)
 		}
 
-		boardName := args[1]
-
-		// Check if board exists
-		_, err := boardSystem.GetBoard(boardName)
+		// Check if board exists, and switch to it under its own name
+		found, err := boardSystem.GetBoard(args[1])
 		if err != nil {
 			fmt.Printf("Error: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
+		boardName := found.Name
 
 		if err := boardSystem.SetCurrentBoard(boardName); err != nil {
 			fmt.Printf("Error switching board: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 
 		fmt.Printf("Switched to board: %s\n", boardName)
+		if override := boardSystem.Override(); override != "" && override != boardName {
+			fmt.Printf("Note: board '%s' from --board or %s takes precedence for this command\n", override, config.BoardEnv)
+		} else if localDir := boardSystem.LocalDir(); localDir != "" {
+			fmt.Printf("Note: the repo-local board in %s takes precedence in this directory\n", localDir)
+		} else if project := boardSystem.ProjectBoard(); project != "" && project != boardName {
+			fmt.Printf("Note: board '%s' takes precedence in this directory (auto_board)\n", project)
+		}
 
 	case "create":
 		if len(args) < 2 {
 			fmt.Println("Error: board name required")
 			fmt.Println("Usage: cainban board create <name> [description]")
-			os.Exit(1)
+			os.Exit(exitUsage)
 		}
 
 		boardName := args[1]
@@ -606,265 +2011,616 @@ func handleBoard(args []string) {
 		board, err := boardSystem.CreateBoard(boardName, description)
 		if err != nil {
 			fmt.Printf("Error creating board: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
+		}
+
+		fmt.Printf("Created board '%s' at: %s\n", boardName, board.Path)
+
+	case "delete":
+		fs := newFlagSet("board delete")
+		force := fs.Bool("force", false, "delete without asking")
+		args = parseFlags(fs, args[1:])
+		if len(args) != 1 {
+			fmt.Println("Error: board name required")
+			fmt.Println("Usage: cainban board delete <name> [--force]")
+			os.Exit(exitUsage)
 		}
 
-		// Initialize the database
-		db, err := storage.New(board.Path)
+		boardName := args[0]
+		if r, err := boardSystem.Remote(boardName); err == nil && r != nil {
+			// Only the local copy goes; the board stays where it is kept
+			if err := boardSystem.RemoveRemote(boardName); err != nil {
+				fmt.Printf("Error deleting board: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			fmt.Printf("Removed remote board '%s'; the board at %s is left as it is\n", boardName, r.URL)
+			return
+		}
+		if err := boardSystem.CheckDeletable(boardName); err != nil {
+			fmt.Printf("Error deleting board: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		// Without a terminal to answer on, nothing would confirm it
+		if !*force && !onTerminal(os.Stdin) {
+			fmt.Printf("Error: deleting board '%s' needs confirming on a terminal; use --force to delete it without asking\n", boardName)
+			os.Exit(exitUsage)
+		}
+		if !*force {
+			fmt.Printf("Delete board '%s'? It is moved to the trash until you empty it [y/N] ", boardName)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			if answer != "y" && answer != "yes" {
+				fmt.Println("Kept the board")
+				return
+			}
+		}
+
+		db, err := storage.New(boardSystem.GetBoardPath(boardName))
 		if err != nil {
-			fmt.Printf("Error initializing board database: %v\n", err)
-			os.Exit(1)
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
 		}
+		backupBefore(db, boardName, "deleting it")
 		db.Close()
 
-		fmt.Printf("Created board '%s' at: %s\n", boardName, board.Path)
+		trashed, err := boardSystem.DeleteBoard(boardName)
+		if err != nil {
+			fmt.Printf("Error deleting board: %v\n", err)
+			os.Exit(exitCode(err))
+		}
 
-	case "delete":
+		fmt.Printf("Deleted board '%s' (moved to %s)\n", boardName, trashed.Path)
+		restoreName := trashed.Name
+		if strings.ContainsAny(restoreName, " '\"") {
+			restoreName = strconv.Quote(restoreName)
+		}
+		fmt.Printf("Bring it back with: cainban board restore %s\n", restoreName)
+
+	case "restore":
+		if len(args) != 2 {
+			fmt.Println("Error: board name required")
+			fmt.Println("Usage: cainban board restore <name>")
+			os.Exit(exitUsage)
+		}
+
+		restored, err := boardSystem.RestoreBoard(args[1])
+		if err != nil {
+			fmt.Printf("Error restoring board: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Restored board '%s', deleted %s\n", restored.Name, restored.DeletedAt.Format("2006-01-02 15:04"))
+
+	case "trash":
+		if len(args) != 1 {
+			usageError("Usage: cainban board trash")
+		}
+		trashed, err := boardSystem.TrashedBoards()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"trash": boardSystem.TrashDir(), "boards": trashed})
+			return
+		}
+
+		if len(trashed) == 0 {
+			fmt.Println("No deleted boards in the trash")
+			return
+		}
+		fmt.Printf("Deleted boards in %s:\n", boardSystem.TrashDir())
+		for _, t := range trashed {
+			fmt.Printf("  %-30s deleted %s\n", t.Name, t.DeletedAt.Format("2006-01-02 15:04"))
+		}
+		fmt.Println("Bring one back with: cainban board restore <name>")
+
+	case "describe":
 		if len(args) < 2 {
 			fmt.Println("Error: board name required")
-			fmt.Println("Usage: cainban board delete <name>")
-			os.Exit(1)
+			fmt.Println("Usage: cainban board describe <name> [description]")
+			os.Exit(exitUsage)
 		}
 
 		boardName := args[1]
-		if err := boardSystem.DeleteBoard(boardName); err != nil {
-			fmt.Printf("Error deleting board: %v\n", err)
-			os.Exit(1)
+		description := strings.Join(args[2:], " ")
+		if err := boardSystem.SetDescription(boardName, description); err != nil {
+			fmt.Printf("Error describing board: %v\n", err)
+			os.Exit(exitCode(err))
 		}
 
-		fmt.Printf("Deleted board: %s\n", boardName)
+		if description == "" {
+			fmt.Printf("Cleared the description of board '%s'\n", boardName)
+		} else {
+			fmt.Printf("Described board '%s': %s\n", boardName, description)
+		}
+
+	case "rename":
+		handleBoardRename(args[1:])
+
+	case "readme":
+		handleBoardReadme(args[1:])
+
+	case "order":
+		handleBoardOrder(args[1:])
+
+	case "add-remote":
+		handleBoardAddRemote(args[1:])
+
+	case "serve":
+		handleBoardServe(args[1:])
 
 	default:
 		fmt.Printf("Unknown board command: %s\n", command)
-		fmt.Println("Commands: list, current, switch, create, delete")
-		os.Exit(1)
+		fmt.Println("Commands: list, current, switch, create, describe, rename, delete, restore, trash, readme, order, add-remote, serve")
+		os.Exit(exitUsage)
 	}
 }
 
 func handleLink(args []string) {
 	if len(args) < 2 {
 		fmt.Println("Error: from_task_id and to_task_id required")
-		fmt.Println("Usage: cainban link <from_task_id> <to_task_id> [link_type]")
+		fmt.Println("Usage: cainban link [board:]<from_task_id> [board:]<to_task_id> [link_type]")
 		fmt.Println("Link types: blocks (default), blocked_by, related, depends_on")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
-	fromTaskID, err := strconv.Atoi(args[0])
-	if err != nil {
-		fmt.Printf("Error: invalid from_task_id '%s'\n", args[0])
-		os.Exit(1)
+	linkType := "blocks" // default
+	if len(args) > 2 {
+		linkType = args[2]
 	}
 
-	toTaskID, err := strconv.Atoi(args[1])
+	boardSystem := newBoardSystem()
+	currentBoard, err := boardSystem.GetCurrentBoard()
 	if err != nil {
-		fmt.Printf("Error: invalid to_task_id '%s'\n", args[1])
-		os.Exit(1)
+		fmt.Printf("Error: failed to get current board: %v\n", err)
+		os.Exit(exitCode(err))
 	}
 
-	linkType := "blocks" // default
-	if len(args) > 2 {
-		linkType = args[2]
+	fromBoard, fromTaskID, err := parseLinkRef(boardSystem, args[0], currentBoard)
+	if err != nil {
+		fmt.Printf("Error: invalid from_task_id: %v\n", err)
+		os.Exit(exitInvalid)
 	}
 
-	db, taskSystem, _, err := getCurrentBoardDB()
+	toBoard, toTaskID, err := parseLinkRef(boardSystem, args[1], currentBoard)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("Error: invalid to_task_id: %v\n", err)
+		os.Exit(exitInvalid)
 	}
-	defer db.Close()
 
-	if err := taskSystem.LinkTasks(fromTaskID, toTaskID, task.LinkType(linkType)); err != nil {
+	if fromBoard == currentBoard && toBoard == currentBoard {
+		db, taskSystem, _, err := getCurrentBoardDB()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		defer db.Close()
+		err = taskSystem.LinkTasks(fromTaskID, toTaskID, task.LinkType(linkType))
+	} else {
+		err = boardSystem.LinkAcrossBoards(fromBoard, fromTaskID, toBoard, toTaskID, task.LinkType(linkType))
+	}
+	if err != nil {
 		fmt.Printf("Error linking tasks: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
-	fmt.Printf("Linked task %d %s task %d\n", fromTaskID, linkType, toTaskID)
+	fmt.Printf("Linked task %s %s task %s\n", formatLinkRef(fromBoard, fromTaskID), linkType, formatLinkRef(toBoard, toTaskID))
 }
 
 func handleUnlink(args []string) {
 	if len(args) < 2 {
 		fmt.Println("Error: from_task_id and to_task_id required")
-		fmt.Println("Usage: cainban unlink <from_task_id> <to_task_id> [link_type]")
+		fmt.Println("Usage: cainban unlink [board:]<from_task_id> [board:]<to_task_id> [link_type]")
 		fmt.Println("Link types: blocks (default), blocked_by, related, depends_on")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
-	fromTaskID, err := strconv.Atoi(args[0])
-	if err != nil {
-		fmt.Printf("Error: invalid from_task_id '%s'\n", args[0])
-		os.Exit(1)
+	linkType := "blocks" // default
+	if len(args) > 2 {
+		linkType = args[2]
 	}
 
-	toTaskID, err := strconv.Atoi(args[1])
+	boardSystem := newBoardSystem()
+	currentBoard, err := boardSystem.GetCurrentBoard()
 	if err != nil {
-		fmt.Printf("Error: invalid to_task_id '%s'\n", args[1])
-		os.Exit(1)
+		fmt.Printf("Error: failed to get current board: %v\n", err)
+		os.Exit(exitCode(err))
 	}
 
-	linkType := "blocks" // default
-	if len(args) > 2 {
-		linkType = args[2]
+	fromBoard, fromTaskID, err := parseLinkRef(boardSystem, args[0], currentBoard)
+	if err != nil {
+		fmt.Printf("Error: invalid from_task_id: %v\n", err)
+		os.Exit(exitInvalid)
 	}
 
-	db, taskSystem, _, err := getCurrentBoardDB()
+	toBoard, toTaskID, err := parseLinkRef(boardSystem, args[1], currentBoard)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		fmt.Printf("Error: invalid to_task_id: %v\n", err)
+		os.Exit(exitInvalid)
 	}
-	defer db.Close()
 
-	if err := taskSystem.UnlinkTasks(fromTaskID, toTaskID, task.LinkType(linkType)); err != nil {
+	if fromBoard == currentBoard && toBoard == currentBoard {
+		db, taskSystem, _, err := getCurrentBoardDB()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		defer db.Close()
+		err = taskSystem.UnlinkTasks(fromTaskID, toTaskID, task.LinkType(linkType))
+	} else {
+		err = boardSystem.UnlinkAcrossBoards(fromBoard, fromTaskID, toBoard, toTaskID, task.LinkType(linkType))
+	}
+	if err != nil {
 		fmt.Printf("Error unlinking tasks: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
-	fmt.Printf("Unlinked task %d %s task %d\n", fromTaskID, linkType, toTaskID)
+	fmt.Printf("Unlinked task %s %s task %s\n", formatLinkRef(fromBoard, fromTaskID), linkType, formatLinkRef(toBoard, toTaskID))
 }
 
 func handleLinks(args []string) {
 	if len(args) < 1 {
 		fmt.Println("Error: task_id required")
 		fmt.Println("Usage: cainban links <task_id>")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	taskID, err := strconv.Atoi(args[0])
-	if err != nil {
+	byHash := err != nil
+	if byHash && !task.IsHash(args[0]) {
 		fmt.Printf("Error: invalid task_id '%s'\n", args[0])
-		os.Exit(1)
+		os.Exit(exitInvalid)
 	}
 
-	db, taskSystem, _, err := getCurrentBoardDB()
+	db, taskSystem, boardName, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
+	if byHash {
+		taskID = taskIDByHash(taskSystem, args[0])
+	}
 
 	links, err := taskSystem.GetTaskLinks(taskID)
 	if err != nil {
 		fmt.Printf("Error getting task links: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
 	if len(links) == 0 {
-		fmt.Printf("Task %d has no links\n", taskID)
+		fmt.Printf("Task %s has no links\n", formatLinkRef(boardName, taskID))
 		return
 	}
 
-	fmt.Printf("Task %d links:\n", taskID)
+	// Links without a board are on the current one
+	boardOf := func(board string) string {
+		if board == "" {
+			return boardName
+		}
+		return board
+	}
+
+	fmt.Printf("Task %s links:\n", formatLinkRef(boardName, taskID))
 	for _, link := range links {
-		if link.FromTaskID == taskID {
-			fmt.Printf("• %s task %d\n", link.LinkType, link.ToTaskID)
+		if link.FromTaskID == taskID && link.FromBoard == "" {
+			fmt.Printf("• %s task %s\n", link.LinkType, formatLinkRef(boardOf(link.ToBoard), link.ToTaskID))
 		} else {
-			fmt.Printf("• %s by task %d\n", link.LinkType, link.FromTaskID)
+			fmt.Printf("• %s by task %s\n", link.LinkType, formatLinkRef(boardOf(link.FromBoard), link.FromTaskID))
 		}
 	}
 }
 
+// parseLinkRef parses a link endpoint: a task ID or hash, optionally
+// prefixed with the name of the board it lives on ("api:12", "api:3f9a2c1")
+func parseLinkRef(boardSystem *board.System, arg, currentBoard string) (string, int, error) {
+	boardName, id := currentBoard, arg
+	if i := strings.LastIndex(arg, ":"); i >= 0 {
+		boardName, id = arg[:i], arg[i+1:]
+		if boardName == "" {
+			return "", 0, fmt.Errorf("missing board name in '%s'", arg)
+		}
+	}
+
+	taskID, err := strconv.Atoi(strings.TrimPrefix(id, "#"))
+	if err != nil && task.IsHash(id) {
+		found, err := boardSystem.FindByHash(boardName, id)
+		if err != nil {
+			return "", 0, err
+		}
+		return boardName, found.ID, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("'%s' is not a task ID or hash", arg)
+	}
+	return boardName, taskID, nil
+}
+
+// formatLinkRef renders a link endpoint as board:#id
+func formatLinkRef(boardName string, taskID int) string {
+	return fmt.Sprintf("%s:#%d", boardName, taskID)
+}
+
 func handleDelete(args []string) {
-	if len(args) < 1 {
+	fs := newFlagSet("delete")
+	hardDelete := fs.Bool("hard", false, "delete permanently instead of leaving it restorable")
+	// Tasks are only deleted by ID or hash, never by title, so --exact is
+	// what delete always does; it is taken for scripts that pass it to
+	// every command finding a task
+	fs.Bool("exact", false, "match only an ID or hash, as delete always does")
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
 		fmt.Println("Error: task_id required")
 		fmt.Println("Usage: cainban delete <task_id> [--hard]")
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	taskID, err := strconv.Atoi(args[0])
-	if err != nil {
-		fmt.Printf("Error: invalid task_id '%s'\n", args[0])
-		os.Exit(1)
+	byHash := err != nil
+	if byHash && !task.IsHash(args[0]) {
+		usageError("invalid task_id '%s'", args[0])
 	}
 
-	hardDelete := len(args) > 1 && args[1] == "--hard"
-
-	db, taskSystem, _, err := getCurrentBoardDB()
+	db, taskSystem, boardName, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
+	if byHash {
+		taskID = taskIDByHash(taskSystem, args[0])
+	}
 
-	if hardDelete {
+	// A task already in the trash can still be deleted for good
+	deleted, err := taskSystem.GetByID(taskID)
+	if err != nil {
+		deleted = &task.Task{ID: taskID}
+	}
+	runPreHook(db, automation.HookEvent{Hook: automation.HookPreDelete, Board: boardName, Task: deleted, FromStatus: deleted.Status})
+
+	if *hardDelete {
+		backupBefore(db, boardName, "deleting task "+strconv.Itoa(taskID))
 		if err := taskSystem.HardDelete(taskID); err != nil {
 			fmt.Printf("Error permanently deleting task: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 		fmt.Printf("Task %d permanently deleted\n", taskID)
 	} else {
 		if err := taskSystem.SoftDelete(taskID); err != nil {
 			fmt.Printf("Error deleting task: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitCode(err))
 		}
 		fmt.Printf("Task %d deleted (can be restored)\n", taskID)
 	}
+	runPostHook(db, automation.HookEvent{Hook: automation.HookPostDelete, Board: boardName, Task: deleted, FromStatus: deleted.Status})
 }
 
+// handleRestore brings back a soft-deleted task, or, given a file rather
+// than a task ID, replaces the current board with that backup
 func handleRestore(args []string) {
 	if len(args) < 1 {
-		fmt.Println("Error: task_id required")
-		fmt.Println("Usage: cainban restore <task_id>")
-		os.Exit(1)
-	}
-
-	taskID, err := strconv.Atoi(args[0])
-	if err != nil {
-		fmt.Printf("Error: invalid task_id '%s'\n", args[0])
-		os.Exit(1)
+		fmt.Println("Error: task_id or backup file required")
+		fmt.Println("Usage: cainban restore <task_id|file>")
+		os.Exit(exitUsage)
 	}
 
 	db, taskSystem, _, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
-	defer db.Close()
 
-	if err := taskSystem.RestoreTask(taskID); err != nil {
+	// A deleted task by ID, number or hash comes first; an argument that is
+	// none of them is a backup file, if there is one
+	deleted, err := taskSystem.FindDeleted(args[0])
+	if err != nil && statOK(args[0]) {
+		db.Close()
+		handleRestoreBackup(args[0])
+		return
+	}
+	defer db.Close()
+	if err == nil {
+		err = taskSystem.RestoreTask(deleted.ID)
+	}
+	if err != nil {
 		fmt.Printf("Error restoring task: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 
-	fmt.Printf("Task %d restored\n", taskID)
+	fmt.Printf("Task %d restored\n", deleted.ID)
+}
+
+func handleConfig(args []string) {
+	command := "show"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "path":
+		fmt.Println(config.DefaultPath())
+	case "show":
+		if _, err := os.Stat(config.DefaultPath()); os.IsNotExist(err) {
+			fmt.Printf("# %s does not exist; showing defaults\n", config.DefaultPath())
+		} else {
+			fmt.Printf("# %s\n", config.DefaultPath())
+		}
+		fmt.Printf("default_priority = %q\n", cfg.DefaultPriority)
+		fmt.Printf("default_board = %q\n", cfg.DefaultBoard)
+		fmt.Printf("output_format = %q\n", cfg.OutputFormat)
+		fmt.Printf("theme = %q\n", cfg.Theme)
+		fmt.Printf("keymap = %q\n", cfg.Keymap)
+		fmt.Printf("editor = %q\n", cfg.EditorCommand())
+		fmt.Printf("user = %q\n", cfg.UserName())
+		if cfg.HandoffWebhook != "" {
+			fmt.Printf("handoff_webhook = %q\n", cfg.HandoffWebhook)
+		}
+		fmt.Printf("busy_timeout = %q\n", cfg.BusyTimeout.String())
+		fmt.Printf("command_timeout = %q\n", cfg.CommandTimeout.String())
+		fmt.Printf("command_memory_mb = %d\n", cfg.CommandMemoryMB)
+		fmt.Printf("auto_backup = %t\n", cfg.AutoBackup)
+		fmt.Printf("auto_board = %t\n", cfg.AutoBoard)
+		fmt.Printf("keep_backups = %d\n", cfg.KeepBackups)
+		fmt.Printf("stale_days = %d\n", cfg.StaleDays)
+		fmt.Printf("fuzzy_match = %t\n", cfg.FuzzyMatch)
+		fmt.Printf("sync_dir = %q\n", cfg.SyncDirectory())
+		fmt.Println()
+		fmt.Println("[wip_limits]")
+		for _, status := range task.ValidStatuses() {
+			if limit := cfg.WIPLimit(string(status)); limit > 0 {
+				fmt.Printf("%s = %d\n", status, limit)
+			}
+		}
+		if len(cfg.Keys) > 0 {
+			fmt.Println()
+			fmt.Println("[keys]")
+			actions := make([]string, 0, len(cfg.Keys))
+			for action := range cfg.Keys {
+				actions = append(actions, action)
+			}
+			sort.Strings(actions)
+			for _, action := range actions {
+				fmt.Printf("%s = %q\n", action, cfg.Keys[action])
+			}
+		}
+	default:
+		fmt.Printf("Unknown config command: %s\n", command)
+		fmt.Println("Commands: show, path")
+		os.Exit(exitUsage)
+	}
 }
 
 func handleMCP() {
 	fmt.Println("Starting MCP server...")
 
-	db, taskSystem, _, err := getCurrentBoardDB()
+	boardSystem := newBoardSystem()
+	boardName, err := boardSystem.GetCurrentBoard()
+	if err != nil {
+		fmt.Printf("Error getting current board: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	// change_board opens the board it changes to the same way
+	openBoard := func(name string) (*mcp.Board, error) {
+		db, taskSystem, err := openBoardDB(boardSystem, name)
+		if err != nil {
+			return nil, err
+		}
+		b := &mcp.Board{Name: name, DB: db, Tasks: taskSystem, Sprints: sprint.New(db.Conn())}
+		if !sandbox.IsSandbox(db.Path()) {
+			b.Automations = newAutomationSystem(db, name)
+			b.Hooks = newHooks()
+		}
+		return b, nil
+	}
+	first, err := openBoard(boardName)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+
+	server := mcp.New(first.Tasks, os.Stdin, os.Stdout)
+	server.UseBoard(first)
+	server.SetBoardOpener(openBoard)
+	defer server.Close()
+	server.SetActor(os.Getenv(config.UserEnv))
+	// Without its secret the webhook is skipped, not the whole server;
+	// stdout is the protocol's, so the warning goes to stderr
+	if handoffWebhook, err := resolveSecret("handoff_webhook", cfg.HandoffWebhook); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; handoffs will not be posted\n", err)
+	} else {
+		server.SetHandoffWebhook(handoffWebhook)
+	}
+	server.SetWIPLimit(cfg.WIPLimit(string(task.StatusDoing)))
+	server.SetBoards(boardSystem, boardName)
+	if cfg.AutoBackup {
+		server.SetAutoBackup(cfg.KeepBackups)
 	}
-	defer db.Close()
 
-	server := mcp.New(taskSystem, os.Stdin, os.Stdout)
-	if err := server.Start(); err != nil {
+	// An interrupt stops the server, cancelling the request in flight
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := server.Start(ctx); err != nil && !errors.Is(err, context.Canceled) {
 		fmt.Printf("Error starting MCP server: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
-func handleTUI() {
+func handleTUI(args []string) {
+	fs := newFlagSet("tui")
+	theme := fs.String("theme", "", "color theme: "+strings.Join(tui.Themes, ", ")+" (default: theme in config)")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		usageError("unknown argument '%s'", args[0])
+	}
+	if *theme == "" {
+		*theme = cfg.Theme
+		if _, err := tui.ParseTheme(*theme); err != nil {
+			fmt.Printf("Error in %s: %v\n", config.DefaultPath(), err)
+			os.Exit(exitCode(err))
+		}
+	} else if _, err := tui.ParseTheme(*theme); err != nil {
+		usageError("%v", err)
+	}
+	if !onTerminal(os.Stdin, os.Stderr) {
+		fmt.Println("Error: the TUI needs an interactive terminal")
+		os.Exit(1)
+	}
+
 	fmt.Println("Starting interactive TUI...")
 	
-	db, _, _, err := getCurrentBoardDB()
+	db, _, boardName, err := getCurrentBoardDB()
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 	defer db.Close()
 	
-	// Start the TUI
-	if err := tui.Run(db); err != nil {
+	keymap, err := tui.NewKeymap(cfg.Keymap, cfg.Keys)
+	if err != nil {
+		fmt.Printf("Error in %s: %v\n", config.DefaultPath(), err)
+		os.Exit(exitCode(err))
+	}
+	
+	// Start the TUI; experiments in a sandbox run no hooks
+	opts := tui.Options{Board: boardName, Theme: *theme, Keymap: keymap, StaleAfter: staleAfter(), Actor: cfg.UserName()}
+	if !sandbox.IsSandbox(db.Path()) {
+		opts.Hooks = newHooks()
+	}
+	if err := tui.Run(db, opts); err != nil {
 		fmt.Printf("Error starting TUI: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// onTerminal reports whether every one of files is an interactive terminal
+func onTerminal(files ...*os.File) bool {
+	for _, f := range files {
+		if !term.IsTerminal(f.Fd()) {
+			return false
+		}
 	}
+	return true
+}
+
+// renderDescription renders a description's Markdown for the terminal,
+// indented under its label and wrapped to the terminal's width
+func renderDescription(description string) string {
+	width := 0
+	if w, _, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 2 {
+		width = min(w, 100) - 2
+	}
+	rendered := markdown.Render(description, width, markdown.DefaultStyles())
+	return "  " + strings.ReplaceAll(rendered, "\n", "\n  ")
 }
 
 func handleVersion() {
-	version := fmt.Sprintf("v%s.%s.%s-dev.%s", VersionMajor, VersionMinor, VersionPatch, VersionDev)
+	shown := version
+	if shown == "" {
+		shown = fmt.Sprintf("v%s.%s.%s-dev.%s", VersionMajor, VersionMinor, VersionPatch, VersionDev)
+	}
 	buildTime := time.Now().Format("2006-01-02 15:04:05 MST")
-	fmt.Printf("cainban %s\n", version)
+	fmt.Printf("cainban %s\n", shown)
 	fmt.Printf("Build: %s\n", VersionSuffix)
 	fmt.Printf("Compiled: %s\n", buildTime)
 	fmt.Println("\nFeatures:")
@@ -876,4 +2632,7 @@ func handleVersion() {
 	fmt.Println("  ✅ Full keyboard navigation (j/k, PgUp/PgDn, Home/End)")
 	fmt.Println("  ✅ Auto-scroll with selection indicators [X/Y]")
 	fmt.Println("  🔧 Conditional debug logging (CAINBAN_DEBUG=1)")
+	if hasUnseenNotes() {
+		fmt.Println("\nSee what changed since you last looked: cainban whatsnew")
+	}
 }
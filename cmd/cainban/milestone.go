@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/dateparse"
+	"github.com/hmain/cainban/src/systems/milestone"
+)
+
+// handleMilestone manages the milestones of the current board
+func handleMilestone(args []string) {
+	command := "list"
+	if len(args) > 0 {
+		command = args[0]
+		args = args[1:]
+	}
+
+	switch command {
+	case "list":
+		handleMilestoneList()
+	case "create":
+		handleMilestoneCreate(args)
+	case "add", "remove":
+		handleMilestonePlan(command, args)
+	case "delete":
+		handleMilestoneDelete(args)
+	default:
+		fmt.Printf("Unknown milestone command: %s\n", command)
+		fmt.Println("Commands: list, create, add, remove, delete")
+		os.Exit(exitUsage)
+	}
+}
+
+func handleMilestoneList() {
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	milestones, err := milestone.New(db.Conn()).List(1)
+	if err != nil {
+		fmt.Printf("Error listing milestones: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "milestones": milestones})
+		return
+	}
+
+	if len(milestones) == 0 {
+		fmt.Printf("No milestones in board '%s'\n", boardName)
+		fmt.Println("Create one with: cainban milestone create \"Beta\" --due 2026-12-01")
+		return
+	}
+
+	now := time.Now()
+	fmt.Printf("Milestones of board '%s'\n", boardName)
+	for _, m := range milestones {
+		due := ""
+		if m.Due != "" {
+			due = "  due " + m.Due
+		}
+		if m.IsOverdue(now) {
+			due += " (overdue)"
+		}
+		fmt.Printf("  #%d %-20s %s %3d%%  %d/%d tasks done%s\n", m.ID, m.Name, progressBar(float64(m.Percent)/100, 20), m.Percent, m.DoneTasks, m.Tasks, due)
+		if m.Description != "" {
+			fmt.Printf("      %s\n", m.Description)
+		}
+	}
+}
+
+func handleMilestoneCreate(args []string) {
+	fs := newFlagSet("milestone create")
+	dueFlag := fs.String("due", "", `date it is due by, e.g. friday or "2026-12-01"`)
+	descriptionFlag := fs.String("description", "", "what reaching the milestone means")
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fmt.Println("Error: milestone name required")
+		fmt.Println("Usage: cainban milestone create <name> [--due <date>] [--description <d>]")
+		fmt.Println("Example:")
+		fmt.Println("  cainban milestone create \"Beta\" --due 2026-12-01 --description \"Invite the first users\"")
+		os.Exit(exitUsage)
+	}
+
+	var due *time.Time
+	if *dueFlag != "" {
+		parsed, err := dateparse.Parse(*dueFlag, time.Now())
+		if err != nil {
+			usageError("invalid --due: %v", err)
+		}
+		due = &parsed
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	created, err := milestone.New(db.Conn()).Create(1, args[0], *descriptionFlag, due)
+	if err != nil {
+		fmt.Printf("Error creating milestone: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "milestone": created})
+		return
+	}
+	fmt.Printf("Created milestone #%d \"%s\" in board '%s'", created.ID, created.Name, boardName)
+	if created.Due != "" {
+		fmt.Printf(", due %s", created.Due)
+	}
+	fmt.Println()
+	fmt.Printf("Add tasks to it with: cainban milestone add %d <id|title>...\n", created.ID)
+}
+
+// handleMilestonePlan adds tasks to a milestone, or takes them out of theirs
+func handleMilestonePlan(command string, args []string) {
+	fs := newFlagSet("milestone " + command)
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if command == "add" && len(args) < 2 || command == "remove" && len(args) < 1 {
+		fmt.Println("Error: milestone and tasks required")
+		fmt.Println("Usage: cainban milestone add <milestone> <id|title>... [--exact]")
+		fmt.Println("       cainban milestone remove <id|title>... [--exact]")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	var m *milestone.Milestone
+	if command == "add" {
+		if m, err = milestone.New(db.Conn()).Find(1, args[0]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		args = args[1:]
+	}
+
+	for _, ref := range args {
+		foundTask, err := findTaskWith(taskSystem, ref, *exact)
+		if err != nil {
+			fmt.Printf("Error finding task: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if m == nil {
+			err = taskSystem.SetMilestone(foundTask.ID, nil)
+		} else {
+			err = taskSystem.SetMilestone(foundTask.ID, &m.ID)
+		}
+		if err != nil {
+			fmt.Printf("Error updating task milestone: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if m == nil {
+			fmt.Printf("Took task #%d \"%s\" out of its milestone\n", foundTask.ID, foundTask.Title)
+		} else {
+			fmt.Printf("Added task #%d \"%s\" to milestone \"%s\" in board '%s'\n", foundTask.ID, foundTask.Title, m.Name, boardName)
+		}
+	}
+}
+
+func handleMilestoneDelete(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: cainban milestone delete <milestone>")
+		os.Exit(exitUsage)
+	}
+
+	db, _, _, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	milestoneSystem := milestone.New(db.Conn())
+	m, err := milestoneSystem.Find(1, args[0])
+	if err == nil {
+		err = milestoneSystem.Delete(m.ID)
+	}
+	if err != nil {
+		fmt.Printf("Error deleting milestone: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Deleted milestone \"%s\"; its %d tasks stay on the board\n", m.Name, m.Tasks)
+}
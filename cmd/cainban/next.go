@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/dateparse"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func handleDue(args []string) {
+	fs := newFlagSet("due")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 2 {
+		fmt.Println("Error: task ID/title and due date required")
+		fmt.Println("Usage: cainban due <id|title> <when|none> [--exact]")
+		fmt.Println("Examples:")
+		fmt.Println("  cainban due 5 friday")
+		fmt.Println("  cainban due \"release notes\" \"2026-11-01 17:00\"")
+		os.Exit(exitUsage)
+	}
+
+	var due *time.Time
+	when := strings.Join(args[1:], " ")
+	if when != "none" {
+		at, err := dateparse.Parse(when, time.Now())
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		due = &at
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if err := taskSystem.SetDue(foundTask.ID, due); err != nil {
+		fmt.Printf("Error updating task due date: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if due == nil {
+		fmt.Printf("Task #%d \"%s\" no longer has a due date in board '%s'\n", foundTask.ID, foundTask.Title, boardName)
+		return
+	}
+	fmt.Printf("Task #%d \"%s\" is due %s in board '%s'\n", foundTask.ID, foundTask.Title, due.Format("Mon Jan 2 15:04"), boardName)
+}
+
+func handleNext(args []string) {
+	if len(args) > 0 {
+		fmt.Printf("Error: unknown argument '%s'\n", args[0])
+		fmt.Println("Usage: cainban next")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	pick, err := taskSystem.Next(1, cfg.WIPLimit(string(task.StatusDoing)), time.Now())
+	if err != nil {
+		fmt.Printf("Error picking next task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "next": pick})
+		return
+	}
+
+	if pick == nil {
+		fmt.Printf("Nothing to pick up in board '%s': no unblocked todo tasks\n", boardName)
+		return
+	}
+
+	t := pick.Task
+	fmt.Printf("Next in board '%s':\n", boardName)
+	fmt.Printf("  #%d%s %s%s%s\n", t.ID, formatPriority(t.Priority), t.Title, formatAssignee(t.Assignee), formatContexts(t.Contexts))
+	fmt.Printf("  Why: %s\n", pick.Reason)
+	if t.Status == task.StatusTodo {
+		fmt.Printf("Start it with: cainban move %d doing\n", t.ID)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/notify"
+	"github.com/hmain/cainban/src/systems/report"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// notifyListed is how many tasks a desktop notification names before it
+// only counts the rest
+const notifyListed = 5
+
+// handleNotify reports overdue tasks and tasks stuck in doing, as desktop
+// notifications or, with --print, as a summary that exits 1 when anything
+// needs attention. It is meant to be run from cron.
+func handleNotify(args []string) {
+	fs := newFlagSet("notify")
+	staleDays := fs.Int("stale-days", cfg.StaleDays, "a task in doing for more than this many days is stale; 0 to not check")
+	printSummary := fs.Bool("print", false, "print a summary instead of notifying, exiting 1 when anything needs attention")
+	allBoards := fs.Bool("all-boards", false, "check every board, not only the current one")
+	args = parseFlags(fs, args)
+	if len(args) != 0 {
+		usageError("Usage: cainban notify [--stale-days <n>] [--print] [--all-boards]")
+	}
+	if *staleDays < 0 {
+		fmt.Printf("Error: invalid --stale-days '%d', it must not be negative\n", *staleDays)
+		os.Exit(exitInvalid)
+	}
+
+	now := time.Now()
+	staleAfter := time.Duration(*staleDays) * 24 * time.Hour
+	var found []*report.Attention
+	if *allBoards {
+		boards, err := newBoardSystem().ListBoards()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		for _, b := range boards {
+			attention, err := boardAttention(b.Path, b.Name, staleAfter, now)
+			if err != nil {
+				fmt.Printf("Error: board '%s': %v\n", b.Name, err)
+				os.Exit(exitCode(err))
+			}
+			found = append(found, attention)
+		}
+	} else {
+		db, _, boardName, err := getCurrentBoardDB()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		attention, err := report.New(db.Conn()).Attention(boardName, 1, staleAfter, now)
+		db.Close()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		found = append(found, attention)
+	}
+
+	needed := false
+	for _, attention := range found {
+		needed = needed || !attention.Empty()
+	}
+
+	if !*printSummary {
+		notifier := notify.Desktop(os.Stdout)
+		for _, attention := range found {
+			if attention.Empty() {
+				continue
+			}
+			title, message := attentionNotification(attention, now)
+			if err := notifier.Notify(title, message); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+		}
+		return
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"stale_days": *staleDays, "boards": found})
+	} else {
+		for i, attention := range found {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Print(formatAttention(attention, *staleDays, now))
+		}
+	}
+	if needed {
+		os.Exit(exitFailure)
+	}
+}
+
+// boardAttention opens a board and collects what on it needs attention
+func boardAttention(dbPath, boardName string, staleAfter time.Duration, now time.Time) (*report.Attention, error) {
+	db, err := storage.New(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return report.New(db.Conn()).Attention(boardName, 1, staleAfter, now)
+}
+
+// attentionNotification is the title and message of one board's
+// notification, naming the first notifyListed tasks
+func attentionNotification(a *report.Attention, now time.Time) (string, string) {
+	var counts []string
+	if n := len(a.Overdue); n > 0 {
+		counts = append(counts, fmt.Sprintf("%d overdue", n))
+	}
+	if n := len(a.Stale); n > 0 {
+		counts = append(counts, fmt.Sprintf("%d stuck in doing", n))
+	}
+	title := strings.Join(counts, ", ")
+	if a.Board != "default" {
+		title += fmt.Sprintf(" (%s)", a.Board)
+	}
+
+	var lines []string
+	for _, t := range a.Overdue {
+		lines = append(lines, fmt.Sprintf("#%d %s, due %s", t.ID, t.Title, formatAge(now.Sub(*t.DueAt))))
+	}
+	for _, t := range a.Stale {
+		lines = append(lines, fmt.Sprintf("#%d %s, in doing for %s", t.ID, t.Title, formatDays(now.Sub(t.DoingSince))))
+	}
+	if len(lines) > notifyListed {
+		lines = append(lines[:notifyListed], fmt.Sprintf("and %d more", len(lines)-notifyListed))
+	}
+	return title, strings.Join(lines, "\n")
+}
+
+// formatAttention is the summary of one board for --print
+func formatAttention(a *report.Attention, staleDays int, now time.Time) string {
+	var b strings.Builder
+	if a.Empty() {
+		fmt.Fprintf(&b, "Nothing overdue or stale in board '%s'\n", a.Board)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Board '%s':\n", a.Board)
+	if len(a.Overdue) > 0 {
+		fmt.Fprintf(&b, "  Overdue (%d):\n", len(a.Overdue))
+		for _, t := range a.Overdue {
+			fmt.Fprintf(&b, "    #%d [%s] %s%s — due %s, %s\n", t.ID, t.Status, t.Title, formatAssignee(t.Assignee),
+				t.DueAt.Local().Format("Mon Jan 2 15:04"), formatAge(now.Sub(*t.DueAt)))
+		}
+	}
+	if len(a.Stale) > 0 {
+		fmt.Fprintf(&b, "  In doing for more than %d days (%d):\n", staleDays, len(a.Stale))
+		for _, t := range a.Stale {
+			fmt.Fprintf(&b, "    #%d %s%s — since %s, %s\n", t.ID, t.Title, formatAssignee(t.Assignee),
+				t.DoingSince.Local().Format("Mon Jan 2"), formatDays(now.Sub(t.DoingSince)))
+		}
+	}
+	return b.String()
+}
+
+// formatAge says how long ago something was due, e.g. "3 days ago"
+func formatAge(d time.Duration) string {
+	switch hours := int(d.Hours()); {
+	case hours < 2:
+		return "1 hour ago"
+	case hours < 24:
+		return fmt.Sprintf("%d hours ago", hours)
+	}
+	return formatDays(d) + " ago"
+}
+
+// formatDays is a duration in whole days, at least one
+func formatDays(d time.Duration) string {
+	days := max(int(d.Hours()/24), 1)
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// handleBoardOrder shows or sets the order the current board keeps its
+// columns in: `cainban board order [manual|priority|due|updated]`
+func handleBoardOrder(args []string) {
+	if len(args) > 1 {
+		usageError("Usage: cainban board order [%s]", strings.Join(task.Orders, "|"))
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	if len(args) == 1 {
+		if err := taskSystem.SetOrder(args[0]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Board '%s' now keeps its columns in %s order\n", boardName, args[0])
+		if args[0] == task.OrderManual {
+			fmt.Println("Reorder tasks in the TUI with the move_up and move_down keys")
+		}
+		return
+	}
+
+	order, err := taskSystem.Order()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "order": order, "orders": task.Orders})
+		return
+	}
+	fmt.Printf("Board '%s' keeps its columns in %s order (one of: %s)\n", boardName, order, strings.Join(task.Orders, ", "))
+}
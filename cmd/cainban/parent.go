@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func handleParent(args []string) {
+	fs := newFlagSet("parent")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) != 2 {
+		fmt.Println("Usage: cainban parent <id|title> <parent-id|title|none> [--exact]")
+		fmt.Println("Examples:")
+		fmt.Println("  cainban parent 12 4       # task 12 becomes a subtask of task 4")
+		fmt.Println("  cainban parent 12 none    # task 12 is a top-level task again")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if args[1] == "none" {
+		if err := taskSystem.SetParent(foundTask.ID, nil); err != nil {
+			fmt.Printf("Error clearing parent task: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Task #%d \"%s\" is a top-level task in board '%s'\n", foundTask.ID, foundTask.Title, boardName)
+		return
+	}
+
+	parentTask, err := findTaskWith(taskSystem, args[1], *exact)
+	if err != nil {
+		fmt.Printf("Error finding parent task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if err := taskSystem.SetParent(foundTask.ID, &parentTask.ID); err != nil {
+		fmt.Printf("Error setting parent task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	parentTask, err = taskSystem.GetByID(parentTask.ID)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Task #%d \"%s\" is now a subtask of #%d \"%s\" in board '%s'\n", foundTask.ID, foundTask.Title, parentTask.ID, parentTask.Title, boardName)
+	if parentTask.Rollup != nil {
+		fmt.Printf("#%d: %s\n", parentTask.ID, parentTask.Rollup)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/hmain/cainban/src/systems/task"
+	"github.com/hmain/cainban/src/tui"
+)
+
+// pickActions are offered for the task picked with `cainban pick`, each
+// running the command it names
+var pickActions = []struct {
+	label string
+	run   func(id string)
+}{
+	{"show details", func(id string) { handleGet([]string{id}) }},
+	{"start (move to doing)", func(id string) { handleMove([]string{id, "doing"}) }},
+	{"finish (move to done)", func(id string) { handleMove([]string{id, "done"}) }},
+	{"back to todo", func(id string) { handleMove([]string{id, "todo"}) }},
+	{"assign to me", func(id string) { handleAssign([]string{id, cfg.UserName()}) }},
+	{"vote", func(id string) { handleVote([]string{id}) }},
+}
+
+func handlePick(args []string) {
+	fs := newFlagSet("pick")
+	printID := fs.Bool("print", false, "print the picked task's ID instead of choosing an action")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		usageError("unknown argument '%s'", args[0])
+	}
+
+	var labels []string
+	if !*printID {
+		for _, a := range pickActions {
+			labels = append(labels, a.label)
+		}
+	}
+
+	picked, action := pickTask(labels)
+	if picked == nil {
+		os.Exit(exitFailure)
+	}
+
+	id := strconv.Itoa(picked.ID)
+	if *printID {
+		fmt.Println(id)
+		return
+	}
+	for _, a := range pickActions {
+		if a.label == action {
+			a.run(id)
+		}
+	}
+}
+
+// pickTask lets the user fuzzy find a task of the current board and then
+// one of the actions, if any. It returns a nil task when the picker was
+// canceled, and exits when there is no terminal to show it on.
+func pickTask(actions []string) (*task.Task, string) {
+	if !onTerminal(os.Stdin, os.Stderr) {
+		fmt.Println("Error: picking a task needs an interactive terminal")
+		os.Exit(1)
+	}
+
+	db, taskSystem, _, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	tasks, err := taskSystem.List(1)
+	db.Close()
+	if err != nil {
+		fmt.Printf("Error listing tasks: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if len(tasks) == 0 {
+		fmt.Println("No tasks to pick from")
+		os.Exit(1)
+	}
+
+	picked, action, err := tui.Pick(tasks, actions, cfg.Theme)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	return picked, action
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/hmain/cainban/src/systems/config"
+)
+
+// checkProfile exits with exitUsage when the profile selected, with
+// --profile or in the environment, cannot name one
+func checkProfile() {
+	if profile := config.Profile(); profile != "" {
+		if err := config.ValidateProfile(profile); err != nil {
+			usageError("%v", err)
+		}
+	}
+}
+
+// profileCommand returns the cainban command line for args, keeping the
+// profile in use, for hooks and other places that run cainban later
+func profileCommand(args string) string {
+	if profile := config.Profile(); profile != "" {
+		return "cainban --profile " + profile + " " + args
+	}
+	return "cainban " + args
+}
+
+// handleProfiles lists the profiles, marking the one in use
+func handleProfiles(args []string) {
+	fs := newFlagSet("profiles")
+	if len(parseFlags(fs, args)) > 0 {
+		usageError("profiles takes no arguments")
+	}
+
+	names, err := config.Profiles()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	names = append([]string{""}, names...)
+
+	current := config.Profile()
+	if cfg.OutputFormat == config.FormatJSON {
+		type profileInfo struct {
+			Name    string `json:"name"`
+			Dir     string `json:"dir"`
+			Current bool   `json:"current"`
+		}
+		var profiles []profileInfo
+		for _, name := range names {
+			profiles = append(profiles, profileInfo{Name: profileName(name), Dir: config.ProfileDir(name), Current: name == current})
+		}
+		printJSON(profiles)
+		return
+	}
+
+	fmt.Println("Profiles:")
+	for _, name := range names {
+		marker := "  "
+		if name == current {
+			marker = "* "
+		}
+		fmt.Printf("%s%-12s %s\n", marker, profileName(name), config.ProfileDir(name))
+	}
+	fmt.Println("Use one with: cainban --profile <name> <command>")
+}
+
+// profileName is how a profile is shown, by name for the default one too
+func profileName(name string) string {
+	if name == "" {
+		return config.DefaultProfile
+	}
+	return name
+}
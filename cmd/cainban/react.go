@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+)
+
+func handleReact(args []string) {
+	fs := newFlagSet("react")
+	remove := fs.Bool("remove", false, "take the reaction back")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	as := fs.String("as", cfg.UserName(), "who to act as")
+	rest := parseFlags(fs, args)
+	actor := *as
+
+	if len(rest) == 0 || len(rest) > 2 || (*remove && len(rest) != 2) {
+		fmt.Println("Usage: cainban react <id|title> [emoji] [--remove] [--as <name>] [--exact]")
+		fmt.Println("Examples:")
+		fmt.Println("  cainban react 12 👍")
+		fmt.Println("  cainban react 12 👍 --remove")
+		fmt.Println("  cainban react 12                 # who reacted with what")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, rest[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if len(rest) == 2 {
+		emoji := rest[1]
+		if *remove {
+			removed, err := taskSystem.Unreact(foundTask.ID, actor, emoji)
+			if err != nil {
+				fmt.Printf("Error removing reaction: %v\n", err)
+				os.Exit(exitCode(err))
+			}
+			if !removed {
+				fmt.Printf("%s had not reacted %s to task #%d\n", actor, emoji, foundTask.ID)
+				return
+			}
+			fmt.Printf("Removed %s's %s from task #%d \"%s\" in board '%s'\n", actor, emoji, foundTask.ID, foundTask.Title, boardName)
+			return
+		}
+
+		added, err := taskSystem.React(foundTask.ID, actor, emoji)
+		if err != nil {
+			fmt.Printf("Error adding reaction: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if !added {
+			fmt.Printf("%s already reacted %s to task #%d\n", actor, emoji, foundTask.ID)
+			return
+		}
+		fmt.Printf("%s reacted %s to task #%d \"%s\" in board '%s'\n", actor, emoji, foundTask.ID, foundTask.Title, boardName)
+		return
+	}
+
+	reactions, err := taskSystem.ListReactions(foundTask.ID)
+	if err != nil {
+		fmt.Printf("Error loading reactions: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "task_id": foundTask.ID, "reactions": reactions})
+		return
+	}
+
+	fmt.Printf("Reactions to task #%d \"%s\":\n", foundTask.ID, foundTask.Title)
+	if len(reactions) == 0 {
+		fmt.Println("  (none yet)")
+		return
+	}
+	for _, r := range reactions {
+		fmt.Printf("  %s %d  %s\n", r.Emoji, r.Count, strings.Join(r.Actors, ", "))
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+)
+
+// handleBoardReadme shows or changes the current board's readme:
+// `cainban board readme [show|edit|set --file <path|->|clear]`
+func handleBoardReadme(args []string) {
+	if len(args) == 0 {
+		args = []string{"show"}
+	}
+
+	command := args[0]
+	if command != "show" && command != "edit" && command != "set" && command != "clear" {
+		fmt.Printf("Unknown readme command: %s\n", command)
+		printReadmeUsage()
+		os.Exit(exitUsage)
+	}
+	if command == "set" && (len(args) != 3 || (args[1] != "--file" && args[1] != "-f")) {
+		printReadmeUsage()
+		os.Exit(1)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	readme, err := taskSystem.GetReadme()
+	if err != nil {
+		fmt.Printf("Error loading readme: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	switch command {
+	case "show":
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "readme": readme})
+			return
+		}
+		if readme == nil {
+			fmt.Fprintf(os.Stderr, "Board '%s' has no readme yet; write one with: cainban board readme edit\n", boardName)
+			return
+		}
+		// Print the raw Markdown so it can be piped or redirected
+		fmt.Print(readme.Content)
+		if !strings.HasSuffix(readme.Content, "\n") {
+			fmt.Println()
+		}
+		return
+
+	case "edit":
+		current := ""
+		if readme != nil {
+			current = readme.Content
+		}
+		content, err := editText(current, boardName+"-readme-*.md")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if content == current {
+			fmt.Println("Readme unchanged")
+			return
+		}
+		if _, err := taskSystem.SetReadme(content); err != nil {
+			fmt.Printf("Error saving readme: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+	case "set":
+		input, err := openInput(args[2])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		data, err := io.ReadAll(input)
+		input.Close()
+		if err != nil {
+			fmt.Printf("Error: failed to read %s: %v\n", args[2], err)
+			os.Exit(exitCode(err))
+		}
+		if _, err := taskSystem.SetReadme(string(data)); err != nil {
+			fmt.Printf("Error saving readme: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+	case "clear":
+		if _, err := taskSystem.SetReadme(""); err != nil {
+			fmt.Printf("Error clearing readme: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Cleared the readme of board '%s'\n", boardName)
+		return
+	}
+
+	fmt.Printf("Saved the readme of board '%s'\n", boardName)
+}
+
+func printReadmeUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  cainban board readme [show]               Print the board's charter")
+	fmt.Println("  cainban board readme edit                 Edit it in your editor")
+	fmt.Println("  cainban board readme set --file <path|->  Replace it with a file or stdin")
+	fmt.Println("  cainban board readme clear                Remove it")
+}
+
+// editText opens text in the configured editor and returns the edited text.
+// The editor command may carry arguments, e.g. "code --wait".
+func editText(text, pattern string) (string, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+
+	if _, err := file.WriteString(text); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	cmd := exec.Command("sh", "-c", cfg.EditorCommand()+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor failed: %w", err)
+	}
+
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read edited file: %w", err)
+	}
+	return string(edited), nil
+}
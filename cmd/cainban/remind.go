@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/dateparse"
+	"github.com/hmain/cainban/src/systems/reminder"
+)
+
+func handleRemind(args []string) {
+	fs := newFlagSet("remind")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 2 {
+		fmt.Println("Error: task ID/title and time required")
+		fmt.Println("Usage: cainban remind <id|title> <when> [note] [--exact]")
+		fmt.Println("Examples:")
+		fmt.Println("  cainban remind 5 \"in 2 hours\"")
+		fmt.Println("  cainban remind \"call bank\" \"tomorrow 9am\" \"before they close\"")
+		os.Exit(exitUsage)
+	}
+
+	at, err := dateparse.Parse(args[1], time.Now())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if !at.After(time.Now()) {
+		fmt.Printf("Error: %s is in the past\n", at.Format("2006-01-02 15:04"))
+		os.Exit(1)
+	}
+
+	note := ""
+	if len(args) > 2 {
+		note = args[2]
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	r, err := reminder.New(db.Conn()).Add(foundTask.ID, at, note)
+	if err != nil {
+		fmt.Printf("Error scheduling reminder: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Reminder %d set for task #%d \"%s\" at %s in board '%s'\n",
+		r.ID, foundTask.ID, foundTask.Title, at.Local().Format("Mon 2006-01-02 15:04"), boardName)
+	fmt.Println("Reminders are delivered by: cainban daemon")
+}
+
+func handleReminders(args []string) {
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	reminderSystem := reminder.New(db.Conn())
+
+	if len(args) > 0 && args[0] == "cancel" {
+		if len(args) < 2 {
+			fmt.Println("Error: reminder ID required")
+			fmt.Println("Usage: cainban reminders cancel <reminder_id>")
+			os.Exit(exitUsage)
+		}
+		id, err := strconv.Atoi(args[1])
+		if err != nil {
+			fmt.Printf("Error: invalid reminder ID '%s'\n", args[1])
+			os.Exit(exitInvalid)
+		}
+		if err := reminderSystem.Cancel(id); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Cancelled reminder %d\n", id)
+		return
+	}
+
+	pending, err := reminderSystem.Pending()
+	if err != nil {
+		fmt.Printf("Error listing reminders: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "reminders": pending})
+		return
+	}
+
+	if len(pending) == 0 {
+		fmt.Printf("No pending reminders in board '%s'\n", boardName)
+		return
+	}
+
+	fmt.Printf("Pending reminders in board '%s':\n", boardName)
+	for _, r := range pending {
+		line := fmt.Sprintf("  [%d] %s  #%d %s", r.ID, r.RemindAt.Local().Format("Mon 2006-01-02 15:04"), r.TaskID, r.TaskTitle)
+		if r.Note != "" {
+			line += " — " + r.Note
+		}
+		fmt.Println(line)
+	}
+}
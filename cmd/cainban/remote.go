@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/hmain/cainban/src/systems/board"
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/remote"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// fetchRemote fetches a remote board into its local copy, open as db, and
+// sends the copy back when db is closed if the command changed it. Long
+// sessions send it on each flush too.
+func fetchRemote(db *storage.DB, r *board.RemoteBoard) error {
+	transport, err := remoteTransport(r)
+	if err != nil {
+		return err
+	}
+
+	spin := startSpinner("Fetching board '" + r.Name + "'")
+	copy, err := remote.Fetch(transport, db)
+	spin.Stop()
+	if err != nil {
+		return err
+	}
+
+	db.BeforeClose(func() error {
+		spin := startSpinner("Sending board '" + r.Name + "'")
+		_, err := copy.Send()
+		spin.Stop()
+		if err != nil {
+			reportUnsent(db, r, err)
+		}
+		return err
+	})
+	db.OnFlush(func() error {
+		return flushRemote(db, r, copy)
+	})
+	return nil
+}
+
+// flushRemote sends the changes a long session made to a remote board.
+// Changes refused because someone else changed the board meanwhile are kept
+// in a backup, and the session goes on with the board as they left it.
+func flushRemote(db *storage.DB, r *board.RemoteBoard, copy *remote.Copy) error {
+	_, err := copy.Send()
+	if !errors.Is(err, remote.ErrConflict) {
+		return err
+	}
+	path, backupErr := backupBoard(db, r.Name)
+	if backupErr != nil {
+		return fmt.Errorf("%w; the changes could not be kept either: %v", err, backupErr)
+	}
+	if refreshErr := copy.Refresh(); refreshErr != nil {
+		return fmt.Errorf("%w; the changes are kept in %s, but the board could not be fetched again: %v", err, path, refreshErr)
+	}
+	return fmt.Errorf("%w; the changes are kept in %s and the board now shows the other changes", err, path)
+}
+
+// remoteTransport returns the transport to a remote board, with its token
+// looked up when it is a secret
+func remoteTransport(r *board.RemoteBoard) (remote.Transport, error) {
+	token, err := resolveSecret("token of board '"+r.Name+"'", r.Token)
+	if err != nil {
+		return nil, err
+	}
+	return remote.Open(r.URL, token)
+}
+
+// reportUnsent tells the user that changes to a remote board did not reach
+// it, keeping them in a backup so they are not lost with the next fetch.
+// It goes to stderr, after whatever the command printed.
+func reportUnsent(db *storage.DB, r *board.RemoteBoard, err error) {
+	fmt.Fprintf(os.Stderr, "Error: the changes to board '%s' were not sent to %s: %v\n", r.Name, r.URL, err)
+	path, backupErr := backupBoard(db, r.Name)
+	if backupErr != nil {
+		fmt.Fprintf(os.Stderr, "They could not be kept either: %v\n", backupErr)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "They are kept in %s\n", path)
+	if errors.Is(err, remote.ErrConflict) {
+		fmt.Fprintln(os.Stderr, "Someone else changed the board meanwhile; run the command again to make the change on top of theirs")
+	} else {
+		fmt.Fprintf(os.Stderr, "Once the board can be reached, cainban restore %s sends them, replacing the board there\n", path)
+	}
+}
+
+// handleBoardAddRemote registers a board kept on another machine and
+// fetches it once, to check that it can be reached
+func handleBoardAddRemote(args []string) {
+	fs := newFlagSet("board add-remote")
+	token := fs.String("token", "", "token for a board served over HTTP, or secret:<name> for one in the secret store")
+	create := fs.Bool("create", false, "create the board at the ssh URL, where there is none yet")
+	trust := fs.Bool("trust", false, "run the board's automations and rules that run commands or post to URLs")
+	args = parseFlags(fs, args)
+	if len(args) != 2 {
+		usageError("Usage: cainban board add-remote <name> <ssh://host/path.db|https://host/> [--token <token>] [--create] [--trust]")
+	}
+	name, url := args[0], args[1]
+
+	boardSystem := newBoardSystem()
+	r, err := boardSystem.AddRemote(name, url, *token, *trust)
+	if err != nil {
+		fmt.Printf("Error adding remote board: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	err = addRemoteCopy(boardSystem, r, *create)
+	if err != nil {
+		if removeErr := boardSystem.RemoveRemote(name); removeErr != nil {
+			fmt.Printf("Warning: %v\n", removeErr)
+		}
+		fmt.Printf("Error adding remote board: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if *create {
+		fmt.Printf("Created board '%s' at %s\n", name, url)
+	} else {
+		fmt.Printf("Added remote board '%s' at %s\n", name, url)
+	}
+	fmt.Printf("Switch to it with: cainban board switch %s\n", name)
+}
+
+// addRemoteCopy makes the local copy of a newly added remote board, from
+// the board there or, with create, as a new board sent there
+func addRemoteCopy(boardSystem *board.System, r *board.RemoteBoard, create bool) error {
+	transport, err := remoteTransport(r)
+	if err != nil {
+		return err
+	}
+	db, err := storage.New(boardSystem.GetBoardPath(r.Name))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if !create {
+		_, err = remote.Fetch(transport, db)
+		return err
+	}
+	if err := db.SetBoardName(r.Name); err != nil {
+		return err
+	}
+	if _, err := remote.Publish(transport, db); errors.Is(err, remote.ErrConflict) {
+		return fmt.Errorf("there is a board at %s already; add it without --create", r.URL)
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// handleBoardServe serves the current board over HTTP, for other machines
+// to add with board add-remote, until interrupted
+func handleBoardServe(args []string) {
+	fs := newFlagSet("board serve")
+	addr := fs.String("addr", "localhost:8787", "address to listen on; :8787 for every interface")
+	token := fs.String("token", "", "token clients must send, or secret:<name> for one in the secret store")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		usageError("Usage: cainban board serve [--addr <host:port>] [--token <token>]")
+	}
+	resolved, err := resolveSecret("token", *token)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+	if r, _ := newBoardSystem().Remote(boardName); r != nil {
+		fmt.Printf("Error: board '%s' is kept at %s; serve it from there\n", boardName, r.URL)
+		os.Exit(exitInvalid)
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "url": "http://" + listener.Addr().String() + "/"})
+	} else {
+		fmt.Printf("Serving board '%s' at http://%s/\n", boardName, listener.Addr())
+		fmt.Printf("Add it on another machine with: cainban board add-remote %s http://<this host>:<port>/", boardName)
+		if resolved != "" {
+			fmt.Print(" --token <token>")
+		} else {
+			fmt.Print("\nWarning: without --token anyone who can reach the address can change the board")
+		}
+		fmt.Println()
+	}
+
+	if err := http.Serve(listener, remote.Handler(db, resolved)); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
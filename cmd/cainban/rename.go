@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hmain/cainban/src/systems/boardsync"
+	"github.com/hmain/cainban/src/systems/config"
+)
+
+// handleBoardRename renames a board: `cainban board rename <old> <new>`
+func handleBoardRename(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Error: old and new board names required")
+		fmt.Println("Usage: cainban board rename <old> <new>")
+		os.Exit(exitUsage)
+	}
+
+	oldName := args[0]
+	renamed, err := newBoardSystem().RenameBoard(oldName, args[1])
+	if renamed == nil {
+		fmt.Printf("Error renaming board: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Renamed board '%s' to '%s', now at: %s\n", oldName, renamed.Name, renamed.Path)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	if cfg.DefaultBoard == oldName {
+		fmt.Printf("Note: default_board in %s still names '%s'; set it to '%s'\n", config.DefaultPath(), oldName, renamed.Name)
+	}
+	if statOK(filepath.Join(cfg.SyncDirectory(), boardsync.File(oldName))) {
+		fmt.Printf("Note: the board syncs as %s from now on; rename it on the other machines too\n", boardsync.File(renamed.Name))
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/sandbox"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func handleSandbox(args []string) {
+	if len(args) == 0 {
+		args = []string{"status"}
+	}
+
+	boardSystem := newBoardSystem()
+	boardName, err := boardSystem.GetCurrentBoard()
+	if err != nil {
+		fmt.Printf("Error: failed to get current board: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	dbPath := boardSystem.GetBoardPath(boardName)
+
+	command := args[0]
+	args = args[1:]
+
+	switch command {
+	case "start":
+		if r, err := boardSystem.Remote(boardName); err == nil && r != nil {
+			fmt.Printf("Error: board '%s' is kept at %s; sandboxes are for boards kept on this machine\n", boardName, r.URL)
+			os.Exit(exitInvalid)
+		}
+		if err := sandbox.Start(dbPath); err != nil {
+			fmt.Printf("Error starting sandbox: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Started a sandbox of board '%s'\n", boardName)
+		fmt.Println("Commands now work on the sandbox copy; the board itself is left alone.")
+		fmt.Println("Review with: cainban sandbox diff")
+		fmt.Println("Then: cainban sandbox apply [task_ids...] or cainban sandbox discard")
+
+	case "status":
+		active := sandbox.Active(dbPath)
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "active": active})
+			return
+		}
+		if !active {
+			fmt.Printf("No sandbox for board '%s'\n", boardName)
+			fmt.Println("Start one with: cainban sandbox start")
+			return
+		}
+		fmt.Printf("A sandbox of board '%s' is active at %s\n", boardName, sandbox.Path(dbPath))
+
+	case "diff":
+		requireSandbox(dbPath, boardName)
+		diff, err := sandbox.Compare(dbPath)
+		if err != nil {
+			fmt.Printf("Error comparing sandbox: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "diff": diff})
+			return
+		}
+
+		if diff.Empty() {
+			fmt.Printf("The sandbox of board '%s' has no changes\n", boardName)
+			return
+		}
+		fmt.Printf("Changes in the sandbox of board '%s':\n", boardName)
+		printSandboxDiff(diff.Tasks, diff.Links)
+
+	case "apply":
+		requireSandbox(dbPath, boardName)
+		var only []int
+		for _, arg := range args {
+			id, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+			if err != nil {
+				fmt.Printf("Error: invalid task_id '%s'\n", arg)
+				os.Exit(exitInvalid)
+			}
+			only = append(only, id)
+		}
+
+		result, err := sandbox.Apply(dbPath, only)
+		if err != nil {
+			fmt.Printf("Error applying sandbox: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "result": result})
+		} else {
+			if len(result.Applied) == 0 && len(result.Links) == 0 {
+				fmt.Printf("Nothing applied to board '%s'\n", boardName)
+			} else {
+				fmt.Printf("Applied to board '%s':\n", boardName)
+				printSandboxDiff(result.Applied, result.Links)
+			}
+			for sandboxID, boardID := range result.Created {
+				if sandboxID != boardID {
+					fmt.Printf("Sandbox task #%d was added as #%d\n", sandboxID, boardID)
+				}
+			}
+			for _, conflict := range result.Conflicts {
+				fmt.Printf("Skipped: %s\n", conflict)
+			}
+			fmt.Println("The sandbox has ended.")
+		}
+
+		db, err := storage.New(dbPath)
+		if err != nil {
+			fmt.Printf("Warning: failed to run automations: %v\n", err)
+			return
+		}
+		defer db.Close()
+		runAutomations(db, boardName)
+
+	case "discard":
+		if err := sandbox.Discard(dbPath); err != nil {
+			fmt.Printf("Error discarding sandbox: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Discarded the sandbox of board '%s'\n", boardName)
+
+	default:
+		fmt.Printf("Unknown sandbox command: %s\n", command)
+		fmt.Println("Usage: cainban sandbox [start|status|diff|apply [task_ids...]|discard]")
+		os.Exit(exitUsage)
+	}
+}
+
+// requireSandbox exits when the board has no active sandbox
+func requireSandbox(dbPath, boardName string) {
+	if !sandbox.Active(dbPath) {
+		fmt.Printf("Error: no sandbox is active for board '%s'\n", boardName)
+		fmt.Println("Start one with: cainban sandbox start")
+		os.Exit(1)
+	}
+}
+
+// printSandboxDiff lists task and link changes, one per line
+func printSandboxDiff(tasks []sandbox.TaskChange, links []sandbox.LinkChange) {
+	for _, c := range tasks {
+		switch c.Kind {
+		case sandbox.Added:
+			fmt.Printf("  + #%d %s\n", c.Task.ID, c.Task.Title)
+		case sandbox.Removed:
+			fmt.Printf("  - #%d %s\n", c.Task.ID, c.Task.Title)
+		default:
+			fmt.Printf("  ~ #%d %s\n", c.Task.ID, c.Task.Title)
+			for _, f := range c.Fields {
+				fmt.Printf("      %s: %q -> %q\n", f.Field, f.From, f.To)
+			}
+		}
+	}
+	for _, c := range links {
+		sign := "+"
+		if c.Kind == sandbox.Removed {
+			sign = "-"
+		}
+		fmt.Printf("  %s link #%d %s #%d\n", sign, c.Link.FromTaskID, c.Link.LinkType, c.Link.ToTaskID)
+	}
+}
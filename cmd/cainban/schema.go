@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// handleSchema shows the schema version of the current board, or takes it
+// back to an older one with "down <version>"
+func handleSchema(args []string) {
+	command := "status"
+	if len(args) > 0 {
+		command = args[0]
+		args = args[1:]
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	switch command {
+	case "status":
+		if len(args) > 0 {
+			usageError("Usage: cainban schema [status]")
+		}
+		version, err := db.SchemaVersion()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		applied, err := db.AppliedMigrations()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		appliedAt := make(map[int]string, len(applied))
+		for _, m := range applied {
+			appliedAt[m.Version] = m.AppliedAt.Local().Format("2006-01-02 15:04")
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{
+				"board":      boardName,
+				"version":    version,
+				"latest":     storage.LatestVersion(),
+				"migrations": applied,
+			})
+			return
+		}
+
+		fmt.Printf("Board '%s' is at schema version %d of %d\n", boardName, version, storage.LatestVersion())
+		for _, m := range storage.Migrations() {
+			when, ok := appliedAt[m.Version]
+			if !ok {
+				when = "pending"
+			}
+			fmt.Printf("  %3d  %-40s %s\n", m.Version, m.Name, when)
+		}
+
+	case "down":
+		if len(args) != 1 {
+			usageError("Usage: cainban schema down <version>")
+		}
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Printf("Error: invalid schema version '%s'\n", args[0])
+			os.Exit(exitInvalid)
+		}
+		if err := db.MigrateDown(version); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Board '%s' is now at schema version %d\n", boardName, version)
+		fmt.Println("Any cainban command of this version migrates it up again")
+
+	default:
+		fmt.Printf("Unknown schema command: %s\n", command)
+		fmt.Println("Commands: status, down")
+		os.Exit(exitUsage)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/secret"
+)
+
+// openSecrets opens the secret store of the profile in use
+func openSecrets() (secret.Store, error) {
+	return secret.Open(config.Dir(), config.Profile())
+}
+
+// resolveSecret returns a config value, looking it up in the secret store
+// when it is a reference such as "secret:slack-webhook"
+func resolveSecret(key, value string) (string, error) {
+	resolved, err := secret.Resolve(value, openSecrets)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	return resolved, nil
+}
+
+func handleSecret(args []string) {
+	command := "list"
+	if len(args) > 0 {
+		command = args[0]
+		args = args[1:]
+	}
+
+	store, err := openSecrets()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	switch command {
+	case "list":
+		names, err := store.List()
+		if err != nil {
+			fmt.Printf("Error listing secrets: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"backend": store.Backend(), "secrets": names})
+			return
+		}
+
+		if len(names) == 0 {
+			fmt.Printf("No secrets in the %s\n", store.Backend())
+			fmt.Println("Add one with: cainban secret set <name>")
+			return
+		}
+		fmt.Printf("Secrets in the %s:\n", store.Backend())
+		for _, name := range names {
+			fmt.Printf("  %s\n", name)
+		}
+		fmt.Printf("Use one in config.toml as \"%s<name>\"\n", secret.RefPrefix)
+
+	case "set":
+		if len(args) < 1 || len(args) > 2 {
+			usageError("Usage: cainban secret set <name> [value]")
+		}
+		name := args[0]
+		if err := secret.ValidateName(name); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+
+		var value string
+		if len(args) == 2 {
+			value = args[1]
+		} else if value, err = readSecret(name); err != nil {
+			fmt.Printf("Error reading secret: %v\n", err)
+			os.Exit(exitFailure)
+		}
+		if value == "" {
+			fmt.Println("Error: secret value cannot be empty")
+			os.Exit(exitInvalid)
+		}
+
+		if err := store.Set(name, value); err != nil {
+			fmt.Printf("Error storing secret: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Stored secret '%s' in the %s\n", name, store.Backend())
+		fmt.Printf("Use it in config.toml as \"%s%s\"\n", secret.RefPrefix, name)
+
+	case "get":
+		if len(args) != 1 {
+			usageError("Usage: cainban secret get <name>")
+		}
+		value, err := store.Get(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Println(value)
+
+	case "delete":
+		if len(args) != 1 {
+			usageError("Usage: cainban secret delete <name>")
+		}
+		if err := store.Delete(args[0]); err != nil {
+			fmt.Printf("Error deleting secret: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		fmt.Printf("Deleted secret '%s'\n", args[0])
+
+	default:
+		fmt.Printf("Unknown secret command: %s\n", command)
+		fmt.Println("Commands: list, set, get, delete")
+		os.Exit(exitUsage)
+	}
+}
+
+// readSecret reads a secret's value from the terminal without echoing it,
+// or else the first line of stdin, so it stays out of the shell history
+func readSecret(name string) (string, error) {
+	if onTerminal(os.Stdin) {
+		fmt.Fprintf(os.Stderr, "Value for '%s': ", name)
+		value, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Fprintln(os.Stderr)
+		return string(value), err
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", nil
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
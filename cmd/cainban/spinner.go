@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// spinnerFrames are drawn in turn while a long operation runs
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const (
+	// spinnerDelay keeps quick operations from flashing a spinner
+	spinnerDelay = 200 * time.Millisecond
+	// spinnerInterval is how often the spinner turns
+	spinnerInterval = 100 * time.Millisecond
+)
+
+// spinner tells the user a long operation is under way. It draws on
+// stderr, and only when stderr is a terminal, so output that is piped or
+// parsed stays clean.
+type spinner struct {
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startSpinner shows label with a spinner until Stop is called
+func startSpinner(label string) *spinner {
+	s := &spinner{stop: make(chan struct{}), done: make(chan struct{})}
+	if !onTerminal(os.Stderr) {
+		close(s.done)
+		return s
+	}
+
+	go func() {
+		defer close(s.done)
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(spinnerDelay):
+		}
+
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			fmt.Fprintf(os.Stderr, "\r%s %s…", spinnerFrames[frame%len(spinnerFrames)], label)
+			select {
+			case <-s.stop:
+				// Clear the line for the output that follows
+				fmt.Fprint(os.Stderr, "\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+// Stop removes the spinner, waiting until it is gone from the terminal
+func (s *spinner) Stop() {
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+	}
+	<-s.done
+}
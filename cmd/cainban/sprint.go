@@ -0,0 +1,336 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/dateparse"
+	"github.com/hmain/cainban/src/systems/sprint"
+)
+
+// handleSprint manages the sprints of the current board
+func handleSprint(args []string) {
+	command := "list"
+	if len(args) > 0 {
+		command = args[0]
+		args = args[1:]
+	}
+
+	switch command {
+	case "list":
+		handleSprintList()
+	case "create":
+		handleSprintCreate(args)
+	case "add", "remove":
+		handleSprintPlan(command, args)
+	case "capacity":
+		handleSprintCapacity(args)
+	case "burndown":
+		handleSprintBurndown(args)
+	case "delete":
+		handleSprintDelete(args)
+	default:
+		fmt.Printf("Unknown sprint command: %s\n", command)
+		fmt.Println("Commands: list, create, add, remove, capacity, burndown, delete")
+		os.Exit(exitUsage)
+	}
+}
+
+func handleSprintList() {
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	sprints, err := sprint.New(db.Conn()).List(1)
+	if err != nil {
+		fmt.Printf("Error listing sprints: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "sprints": sprints})
+		return
+	}
+
+	if len(sprints) == 0 {
+		fmt.Printf("No sprints in board '%s'\n", boardName)
+		fmt.Println("Create one with: cainban sprint create \"Sprint 1\"")
+		return
+	}
+
+	now := time.Now()
+	fmt.Printf("Sprints of board '%s'\n", boardName)
+	for _, sp := range sprints {
+		marker := ""
+		if sp.IsCurrent(now) {
+			marker = "  ← current"
+		}
+		fmt.Printf("  #%d %-20s %s to %s  %s%s\n", sp.ID, sp.Name, sp.Start, sp.End, formatSprintProgress(sp), marker)
+	}
+}
+
+// formatSprintProgress shows how much of a sprint is done, in points when
+// its tasks are estimated, and its capacity if it has one
+func formatSprintProgress(sp *sprint.Sprint) string {
+	progress := fmt.Sprintf("%d/%d tasks done", sp.DoneTasks, sp.Tasks)
+	if sp.Points > 0 {
+		progress = fmt.Sprintf("%d/%d pts done", sp.DonePoints, sp.Points)
+	}
+	if sp.Capacity > 0 {
+		progress += fmt.Sprintf(", capacity %d pts", sp.Capacity)
+	}
+	return progress
+}
+
+func handleSprintCreate(args []string) {
+	fs := newFlagSet("sprint create")
+	startFlag := fs.String("start", "today", `first day, e.g. monday or "2026-11-02"`)
+	endFlag := fs.String("end", "", "last day (default: two weeks from the start)")
+	capacity := fs.Int("capacity", 0, "points of estimated work the sprint can take, warned about when planning past it")
+	args = parseFlags(fs, args)
+	if len(args) != 1 {
+		fmt.Println("Error: sprint name required")
+		fmt.Println("Usage: cainban sprint create <name> [--start <date>] [--end <date>] [--capacity <points>]")
+		fmt.Println("Example:")
+		fmt.Println("  cainban sprint create \"Sprint 12\" --start monday --end 2026-11-13")
+		os.Exit(exitUsage)
+	}
+
+	now := time.Now()
+	start, err := dateparse.Parse(*startFlag, now)
+	if err != nil {
+		usageError("invalid --start: %v", err)
+	}
+	end := start.Add(sprint.DefaultLength - 24*time.Hour)
+	if *endFlag != "" {
+		if end, err = dateparse.Parse(*endFlag, now); err != nil {
+			usageError("invalid --end: %v", err)
+		}
+	}
+	if *capacity < 0 {
+		usageError("--capacity cannot be negative")
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	sprintSystem := sprint.New(db.Conn())
+	created, err := sprintSystem.Create(1, args[0], start, end)
+	if err == nil && *capacity > 0 {
+		err = sprintSystem.SetCapacity(created.ID, *capacity)
+		created.Capacity = *capacity
+	}
+	if err != nil {
+		fmt.Printf("Error creating sprint: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "sprint": created})
+		return
+	}
+	fmt.Printf("Created sprint #%d \"%s\" in board '%s', %s to %s\n", created.ID, created.Name, boardName, created.Start, created.End)
+	fmt.Printf("Plan tasks for it with: cainban sprint add %d <id|title>...\n", created.ID)
+}
+
+// handleSprintPlan plans tasks for a sprint, or takes them out of theirs
+func handleSprintPlan(command string, args []string) {
+	fs := newFlagSet("sprint " + command)
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if command == "add" && len(args) < 2 || command == "remove" && len(args) < 1 {
+		fmt.Println("Error: sprint and tasks required")
+		fmt.Println("Usage: cainban sprint add <sprint> <id|title>... [--exact]")
+		fmt.Println("       cainban sprint remove <id|title>... [--exact]")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	var sp *sprint.Sprint
+	if command == "add" {
+		if sp, err = sprint.New(db.Conn()).Find(1, args[0], time.Now()); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		args = args[1:]
+	}
+
+	for _, ref := range args {
+		foundTask, err := findTaskWith(taskSystem, ref, *exact)
+		if err != nil {
+			fmt.Printf("Error finding task: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if sp == nil {
+			err = taskSystem.SetSprint(foundTask.ID, nil)
+		} else {
+			err = taskSystem.SetSprint(foundTask.ID, &sp.ID)
+		}
+		if err != nil {
+			fmt.Printf("Error planning task: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if sp == nil {
+			fmt.Printf("Took task #%d \"%s\" out of its sprint\n", foundTask.ID, foundTask.Title)
+		} else {
+			fmt.Printf("Planned task #%d \"%s\" for sprint \"%s\" in board '%s'\n", foundTask.ID, foundTask.Title, sp.Name, boardName)
+		}
+	}
+
+	// The plan is kept; going past the capacity is only a warning
+	if sp != nil {
+		if planned, err := sprint.New(db.Conn()).Get(sp.ID); err == nil {
+			if warning := planned.CheckCapacity(); warning != nil {
+				fmt.Printf("Warning: %s\n", warning)
+			}
+		}
+	}
+}
+
+// handleSprintCapacity sets the points of estimated work a sprint can take
+func handleSprintCapacity(args []string) {
+	if len(args) != 2 {
+		fmt.Println("Usage: cainban sprint capacity <sprint> <points>")
+		fmt.Println("  0 points removes the limit")
+		os.Exit(exitUsage)
+	}
+	points, err := strconv.Atoi(args[1])
+	if err != nil || points < 0 {
+		usageError("invalid capacity '%s': use a number of points, 0 for no limit", args[1])
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	sprintSystem := sprint.New(db.Conn())
+	sp, err := sprintSystem.Find(1, args[0], time.Now())
+	if err == nil {
+		err = sprintSystem.SetCapacity(sp.ID, points)
+	}
+	if err != nil {
+		fmt.Printf("Error setting capacity: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	sp.Capacity = points
+
+	if points == 0 {
+		fmt.Printf("Sprint \"%s\" in board '%s' has no capacity limit\n", sp.Name, boardName)
+	} else {
+		fmt.Printf("Sprint \"%s\" in board '%s' can take %d pts\n", sp.Name, boardName, points)
+	}
+	if warning := sp.CheckCapacity(); warning != nil {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+}
+
+func handleSprintBurndown(args []string) {
+	if len(args) > 1 {
+		fmt.Println("Usage: cainban sprint burndown [sprint]")
+		os.Exit(exitUsage)
+	}
+	ref := sprint.Current
+	if len(args) == 1 {
+		ref = args[0]
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	now := time.Now()
+	sprintSystem := sprint.New(db.Conn())
+	sp, err := sprintSystem.Find(1, ref, now)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	burndown, err := sprintSystem.Burndown(sp.ID, now)
+	if err != nil {
+		fmt.Printf("Error working out the burndown: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "burndown": burndown})
+		return
+	}
+
+	fmt.Printf("Sprint \"%s\" of board '%s', %s to %s\n", sp.Name, boardName, sp.Start, sp.End)
+	fmt.Printf("%s, day %d of %d\n", formatSprintProgress(sp), len(burndown.Days), sp.Days())
+	if burndown.Unestimated > 0 {
+		fmt.Printf("%d tasks without an estimate are not counted\n", burndown.Unestimated)
+	}
+	if len(burndown.Days) == 0 {
+		fmt.Println("The sprint has not started yet")
+		return
+	}
+	if burndown.Total == 0 {
+		fmt.Printf("No tasks planned yet; add some with: cainban sprint add %d <id|title>...\n", sp.ID)
+		return
+	}
+
+	fmt.Println()
+	for _, day := range burndown.Days {
+		bar := progressBar(float64(day.Remaining)/float64(burndown.Total), 30)
+		fmt.Printf("  %s %s %3d %s (ideal %.1f)\n", day.Date, bar, day.Remaining, burndown.Unit, day.Ideal)
+	}
+
+	last := burndown.Days[len(burndown.Days)-1]
+	switch {
+	case last.Remaining == 0:
+		fmt.Println("\nEverything planned is done")
+	case float64(last.Remaining) > last.Ideal:
+		fmt.Printf("\nBehind: %d %s left, %.1f on the ideal line\n", last.Remaining, burndown.Unit, last.Ideal)
+	default:
+		fmt.Printf("\nOn track: %d %s left\n", last.Remaining, burndown.Unit)
+	}
+}
+
+func handleSprintDelete(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: cainban sprint delete <sprint>")
+		os.Exit(exitUsage)
+	}
+
+	db, _, _, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	sprintSystem := sprint.New(db.Conn())
+	sp, err := sprintSystem.Find(1, strings.TrimSpace(args[0]), time.Now())
+	if err == nil {
+		err = sprintSystem.Delete(sp.ID)
+	}
+	if err != nil {
+		fmt.Printf("Error deleting sprint: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Deleted sprint \"%s\"; its %d tasks are unplanned\n", sp.Name, sp.Tasks)
+}
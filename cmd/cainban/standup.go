@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/dateparse"
+	"github.com/hmain/cainban/src/systems/report"
+)
+
+// agoPattern matches lookbacks such as "24h", "3d" or "2w"
+var agoPattern = regexp.MustCompile(`^(\d+)([hdw])$`)
+
+func handleStandup(args []string) {
+	fs := newFlagSet("standup")
+	sinceFlag := fs.String("since", "", `lookback such as 3d, or a date (default: the start of the previous workday)`)
+	formatFlag := fs.String("format", "text", "text or md")
+	if rest := parseFlags(fs, args); len(rest) > 0 {
+		fmt.Printf("Error: unknown argument '%s'\n", rest[0])
+		fmt.Println("Usage: cainban standup [--since <when>] [--format text|md]")
+		fmt.Println("Examples:")
+		fmt.Println("  cainban standup                     # since the start of the previous workday")
+		fmt.Println("  cainban standup --since 3d --format md")
+		fmt.Println("  cainban standup --since 2026-10-12")
+		os.Exit(exitUsage)
+	}
+
+	now := time.Now()
+	since := report.PreviousWorkday(now)
+	format := *formatFlag
+	if format != "text" && format != "md" {
+		usageError("--format must be text or md")
+	}
+	if *sinceFlag != "" {
+		at, err := parseSince(*sinceFlag, now)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		since = at
+	}
+
+	db, _, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	standup, err := report.New(db.Conn()).Standup(boardName, 1, since)
+	if err != nil {
+		fmt.Printf("Error building standup: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	switch {
+	case cfg.OutputFormat == config.FormatJSON:
+		printJSON(standup)
+	case format == "md":
+		fmt.Print(standup.Markdown())
+	default:
+		fmt.Print(standup.Text())
+	}
+}
+
+// parseSince reads the start of a standup period: "yesterday", "today", a
+// lookback such as "3d", or a date or time understood by dateparse. A day
+// name means the last one, not the next one as it does for due dates.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch value {
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+
+	if m := agoPattern.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "h":
+			return now.Add(-time.Duration(n) * time.Hour), nil
+		case "d":
+			return now.AddDate(0, 0, -n), nil
+		default:
+			return now.AddDate(0, 0, -7*n), nil
+		}
+	}
+
+	at, err := dateparse.Parse(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if at.After(now) && isWeekday(value) {
+		at = at.AddDate(0, 0, -7)
+	}
+	if at.After(now) {
+		return time.Time{}, fmt.Errorf("--since %q is in the future", value)
+	}
+	return at, nil
+}
+
+// isWeekday reports whether a time expression starts with a day name
+func isWeekday(value string) bool {
+	value = strings.ToLower(value)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.HasPrefix(value, strings.ToLower(day.String()[:3])) {
+			return true
+		}
+	}
+	return false
+}
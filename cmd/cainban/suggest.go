@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func handleSize(args []string) {
+	fs := newFlagSet("size")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 2 {
+		fmt.Println("Error: task ID/title and size required")
+		fmt.Println("Usage: cainban size <id|title> <S|M|L|none> [--exact]")
+		os.Exit(exitUsage)
+	}
+
+	size, err := task.ParseSize(args[1])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if err := taskSystem.SetSize(foundTask.ID, size); err != nil {
+		fmt.Printf("Error updating task size: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if size == task.SizeNone {
+		fmt.Printf("Cleared size of task #%d \"%s\" in board '%s'\n", foundTask.ID, foundTask.Title, boardName)
+		return
+	}
+	fmt.Printf("Set size of task #%d \"%s\" to %s (~%s) in board '%s'\n",
+		foundTask.ID, foundTask.Title, size, formatDuration(size.Duration()), boardName)
+}
+
+func handleEnergy(args []string) {
+	fs := newFlagSet("energy")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+	if len(args) < 2 {
+		fmt.Println("Error: task ID/title and energy required")
+		fmt.Println("Usage: cainban energy <id|title> <low|high|none> [--exact]")
+		os.Exit(exitUsage)
+	}
+
+	energy, err := task.ParseEnergy(args[1])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if err := taskSystem.SetEnergy(foundTask.ID, energy); err != nil {
+		fmt.Printf("Error updating task energy: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if energy == task.EnergyNone {
+		fmt.Printf("Cleared energy of task #%d \"%s\" in board '%s'\n", foundTask.ID, foundTask.Title, boardName)
+		return
+	}
+	fmt.Printf("Task #%d \"%s\" needs %s energy in board '%s'\n", foundTask.ID, foundTask.Title, energy, boardName)
+}
+
+func handleSuggest(args []string) {
+	fs := newFlagSet("suggest")
+	timeFlag := fs.String("time", "", "time available, e.g. 30m, 1h, 2h30m")
+	energyFlag := fs.String("energy", "", "energy available: low or high")
+	limitFlag := fs.Int("limit", 5, "most tasks to suggest")
+	if rest := parseFlags(fs, args); len(rest) > 0 {
+		usageError("unknown argument '%s'", rest[0])
+	}
+
+	opts := task.SuggestOptions{Limit: *limitFlag}
+	if *timeFlag != "" {
+		d, err := time.ParseDuration(*timeFlag)
+		if err != nil || d <= 0 {
+			fmt.Printf("Error: invalid time '%s' (e.g. 30m, 1h, 2h30m)\n", *timeFlag)
+			os.Exit(exitInvalid)
+		}
+		opts.Time = d
+	}
+	if *energyFlag != "" {
+		energy, err := task.ParseEnergy(*energyFlag)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		opts.Energy = energy
+	}
+	if opts.Limit < 1 {
+		fmt.Printf("Error: invalid limit '%d'\n", opts.Limit)
+		os.Exit(exitInvalid)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	suggestions, err := taskSystem.Suggest(1, opts)
+	if err != nil {
+		fmt.Printf("Error suggesting tasks: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "suggestions": suggestions})
+		return
+	}
+
+	if len(suggestions) == 0 {
+		fmt.Printf("No open tasks in board '%s' fit%s\n", boardName, describeSuggestOptions(opts))
+		fmt.Println("Label tasks with: cainban size <id|title> S|M|L and cainban energy <id|title> low|high")
+		return
+	}
+
+	fmt.Printf("Suggested tasks in board '%s'%s:\n", boardName, describeSuggestOptions(opts))
+	for _, t := range suggestions {
+		priorityStr := ""
+		if t.Priority > 0 {
+			priorityStr = fmt.Sprintf(" [%s]", task.GetPriorityName(t.Priority))
+		}
+		doing := ""
+		if t.Status == task.StatusDoing {
+			doing = " (in progress)"
+		}
+		fmt.Printf("  #%d%s %s%s%s\n", t.ID, priorityStr, t.Title, formatEffort(t.Size, t.Energy), doing)
+	}
+}
+
+// describeSuggestOptions renders the constraints of a suggestion request
+func describeSuggestOptions(opts task.SuggestOptions) string {
+	var parts []string
+	if opts.Time > 0 {
+		parts = append(parts, formatDuration(opts.Time))
+	}
+	if opts.Energy != task.EnergyNone {
+		parts = append(parts, string(opts.Energy)+" energy")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " for " + strings.Join(parts, ", ")
+}
+
+// formatDuration renders a duration without trailing zero units, e.g. 30m or 2h
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// formatEffort renders a task's size and energy suffix for list output
+func formatEffort(size task.Size, energy task.Energy) string {
+	var parts []string
+	if size != task.SizeNone {
+		parts = append(parts, string(size))
+	}
+	if energy != task.EnergyNone {
+		parts = append(parts, string(energy)+" energy")
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " {" + strings.Join(parts, ", ") + "}"
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hmain/cainban/src/systems/boardsync"
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/git"
+	"github.com/hmain/cainban/src/systems/sandbox"
+)
+
+// syncUsage is shown for bad arguments to sync
+const syncUsage = "Usage: cainban sync [--dir <repo>] | cainban sync init [--dir <repo>] [<remote-url>]"
+
+// handleSync syncs the current board with other machines through a git
+// repository: it pulls what they pushed, merges it into the board task by
+// task, and commits and pushes the result
+func handleSync(args []string) {
+	if len(args) > 0 && args[0] == "init" {
+		handleSyncInit(args[1:])
+		return
+	}
+
+	fs := newFlagSet("sync")
+	dir := fs.String("dir", cfg.SyncDirectory(), "git repository to sync through")
+	args = parseFlags(fs, args)
+	if len(args) != 0 {
+		usageError(syncUsage)
+	}
+
+	if !statOK(filepath.Join(*dir, ".git")) {
+		fmt.Printf("Error: %s is not a git repository\n", *dir)
+		fmt.Println("Set one up with: cainban sync init [<remote-url>]")
+		os.Exit(exitInvalid)
+	}
+	repo, err := git.Open(*dir)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+	if sandbox.Active(newBoardSystem().GetBoardPath(boardName)) {
+		fmt.Printf("Error: board '%s' has a sandbox; apply or discard it before syncing\n", boardName)
+		os.Exit(exitInvalid)
+	}
+
+	syncer := boardsync.New(repo, taskSystem, boardName)
+	spin := startSpinner("Pulling changes to board '" + boardName + "'")
+	plan, err := syncer.Pull()
+	spin.Stop()
+	if err != nil {
+		fmt.Printf("Error syncing: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if !plan.Empty() {
+		backupBefore(db, boardName, "syncing")
+		if err := syncer.Apply(plan); err != nil {
+			fmt.Printf("Error syncing: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+	}
+
+	host, _ := os.Hostname()
+	spin = startSpinner("Pushing board '" + boardName + "'")
+	committed, err := syncer.Push(fmt.Sprintf("Sync board '%s' from %s", boardName, host))
+	spin.Stop()
+	if err != nil {
+		fmt.Printf("Error syncing: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{
+			"board":      boardName,
+			"repository": repo.Dir(),
+			"remote":     syncer.Remote(),
+			"added":      nonNil(plan.Create),
+			"updated":    nonNil(plan.Update),
+			"deleted":    nonNil(plan.Delete),
+			"committed":  committed,
+		})
+		return
+	}
+
+	for _, r := range plan.Create {
+		fmt.Printf("Added %s [%s] %s\n", r.ShortHash(), r.Status, r.Title)
+	}
+	for _, r := range plan.Update {
+		fmt.Printf("Updated %s [%s] %s\n", r.ShortHash(), r.Status, r.Title)
+	}
+	for _, r := range plan.Delete {
+		fmt.Printf("Deleted %s %s (restorable from the trash)\n", r.ShortHash(), r.Title)
+	}
+	fmt.Printf("Synced board '%s' through %s: %d added, %d updated, %d deleted\n",
+		boardName, repo.Dir(), len(plan.Create), len(plan.Update), len(plan.Delete))
+	if syncer.Remote() == "" {
+		fmt.Println("The repository has no remote, so the board was only committed locally")
+	}
+}
+
+// nonNil keeps an empty list of records an empty JSON array
+func nonNil(records []boardsync.Record) []boardsync.Record {
+	if records == nil {
+		return []boardsync.Record{}
+	}
+	return records
+}
+
+// handleSyncInit sets up the repository boards sync through: a clone of
+// the remote given, or a new repository kept on this machine
+func handleSyncInit(args []string) {
+	fs := newFlagSet("sync init")
+	dir := fs.String("dir", cfg.SyncDirectory(), "where to keep the repository")
+	args = parseFlags(fs, args)
+	if len(args) > 1 {
+		usageError(syncUsage)
+	}
+
+	if statOK(filepath.Join(*dir, ".git")) {
+		fmt.Printf("Error: %s is a git repository already; run cainban sync\n", *dir)
+		os.Exit(exitInvalid)
+	}
+
+	var repo *git.Repo
+	var err error
+	if len(args) == 1 {
+		repo, err = git.Clone(args[0], *dir)
+	} else {
+		repo, err = git.Init(*dir)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	fmt.Printf("Boards sync through %s\n", repo.Dir())
+	if len(args) == 0 {
+		fmt.Printf("Add a remote to share it with other machines: git -C %s remote add origin <url>\n", repo.Dir())
+	}
+	fmt.Println("Run cainban sync on each machine to sync the current board")
+}
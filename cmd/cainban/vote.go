@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func handleVote(args []string) {
+	fs := newFlagSet("vote")
+	remove := fs.Bool("remove", false, "take the vote back")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	as := fs.String("as", cfg.UserName(), "who to vote as")
+	rest := parseFlags(fs, args)
+	actor := *as
+	if len(rest) != 1 {
+		fmt.Println("Usage: cainban vote <id|title> [--remove] [--as <name>] [--exact]")
+		fmt.Println("Votes order the backlog in: cainban grooming")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, rest[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if *remove {
+		removed, err := taskSystem.Unvote(foundTask.ID, actor)
+		if err != nil {
+			fmt.Printf("Error removing vote: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if !removed {
+			fmt.Printf("%s had not voted for task #%d\n", actor, foundTask.ID)
+			return
+		}
+		fmt.Printf("%s withdrew their vote for task #%d \"%s\" in board '%s'\n", actor, foundTask.ID, foundTask.Title, boardName)
+		return
+	}
+
+	added, err := taskSystem.Vote(foundTask.ID, actor)
+	if err != nil {
+		fmt.Printf("Error adding vote: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	if !added {
+		fmt.Printf("%s already voted for task #%d\n", actor, foundTask.ID)
+		return
+	}
+	fmt.Printf("%s voted for task #%d \"%s\" in board '%s' (%d in total)\n", actor, foundTask.ID, foundTask.Title, boardName, foundTask.Votes+1)
+}
+
+func handleGrooming(args []string) {
+	fs := newFlagSet("grooming")
+	yes := fs.Bool("yes", false, "apply the proposed order without asking")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		fmt.Printf("Error: unknown argument '%s'\n", args[0])
+		fmt.Println("Usage: cainban grooming [--yes]")
+		os.Exit(exitUsage)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	entries, err := taskSystem.ProposeGrooming(1)
+	if err != nil {
+		fmt.Printf("Error ordering backlog: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	changed := 0
+	for _, e := range entries {
+		if e.Changed() {
+			changed++
+		}
+	}
+
+	if cfg.OutputFormat == config.FormatJSON && !*yes {
+		printJSON(map[string]interface{}{"board": boardName, "proposed": entries, "changed": changed})
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("The backlog of board '%s' is empty\n", boardName)
+		return
+	}
+
+	fmt.Printf("Backlog of board '%s' ordered by votes:\n", boardName)
+	for _, e := range entries {
+		priority := task.GetPriorityName(e.Priority)
+		if e.Priority != e.Task.Priority {
+			priority = task.GetPriorityName(e.Task.Priority) + " -> " + priority
+		}
+		fmt.Printf("  %2d. #%-4d %-40s ▲%-3d %s\n", e.Position, e.Task.ID, truncate(e.Task.Title, 40), e.Task.Votes, priority)
+	}
+
+	if changed == 0 {
+		fmt.Println("\nThe backlog already follows the votes.")
+		return
+	}
+
+	if !*yes {
+		fmt.Printf("\nAccept this order? %d tasks get a new priority or position [y/N] ", changed)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		if answer != "y" && answer != "yes" {
+			fmt.Println("Left the backlog as it was")
+			return
+		}
+	}
+
+	if err := taskSystem.AcceptGrooming(entries); err != nil {
+		fmt.Printf("Error saving backlog order: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Reordered %d tasks in board '%s'\n", changed, boardName)
+}
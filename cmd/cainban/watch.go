@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// watchPollInterval is how often watch checks the board for changes and
+// the terminal for a new size
+const watchPollInterval = 500 * time.Millisecond
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\x1b[H\x1b[2J"
+
+// handleWatch draws the board's columns and redraws them as the board
+// changes, and at least every --interval for due dates, until interrupted
+func handleWatch(args []string) {
+	fs := newFlagSet("watch")
+	interval := fs.Duration("interval", 5*time.Second, "redraw at least this often, e.g. 10s or 1m")
+	once := fs.Bool("once", false, "draw the board once and exit")
+	args = parseFlags(fs, args)
+	if len(args) != 0 {
+		usageError("Usage: cainban watch [--interval <duration>] [--once]")
+	}
+	if *interval < time.Second {
+		fmt.Printf("Error: invalid interval '%s', it must be at least 1s\n", *interval)
+		os.Exit(exitInvalid)
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	interactive := onTerminal(os.Stdout)
+	width, height := watchSize(interactive)
+	draw := func() {
+		frame, err := renderWatchFrame(taskSystem, boardName, width, height, time.Now())
+		if err != nil {
+			// Keep watching: the board may be busy or briefly locked
+			frame = fmt.Sprintf("Error: %v", err)
+		}
+		if interactive {
+			fmt.Print(clearScreen + frame)
+		} else {
+			fmt.Println(frame)
+		}
+	}
+
+	draw()
+	if *once {
+		return
+	}
+
+	watcher, err := db.Watch()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer watcher.Close()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	drawn := time.Now()
+	for {
+		select {
+		case <-stop:
+			if interactive {
+				// Leave the shell prompt under the board
+				fmt.Println()
+			}
+			return
+		case now := <-ticker.C:
+			changed, _ := watcher.Changed()
+			w, h := watchSize(interactive)
+			if changed || w != width || h != height || now.Sub(drawn) >= *interval {
+				width, height, drawn = w, h, now
+				draw()
+			}
+		}
+	}
+}
+
+// watchSize returns the size of the terminal watch draws in, or a size
+// that fits a log when its output is not a terminal
+func watchSize(interactive bool) (int, int) {
+	if interactive {
+		if w, h, err := term.GetSize(os.Stdout.Fd()); err == nil && w > 0 && h > 0 {
+			return w, h
+		}
+	}
+	return 120, 0
+}
+
+// renderWatchFrame draws the board's columns side by side, cut to width by
+// height cells; a height of 0 or less shows every task
+func renderWatchFrame(taskSystem *task.System, boardName string, width, height int, now time.Time) (string, error) {
+	statuses := []task.Status{task.StatusTodo, task.StatusDoing, task.StatusDone}
+	columns := make([][]*task.Task, len(statuses))
+	for i, status := range statuses {
+		tasks, err := taskSystem.ListWith(1, task.ListOptions{Status: status, BoardOrder: true})
+		if err != nil {
+			return "", err
+		}
+		columns[i] = tasks
+	}
+
+	bold := lipgloss.NewStyle().Bold(true)
+	faint := lipgloss.NewStyle().Faint(true)
+	gap := "  "
+	columnWidth := max((width-len(gap)*(len(statuses)-1))/len(statuses), 12)
+
+	var lines []string
+	lines = append(lines, ansi.Truncate(fmt.Sprintf("%s  %s", bold.Render("Board: "+boardName),
+		faint.Render("updated "+now.Format("15:04:05")+" • Ctrl+C to stop")), width, "…"), "")
+
+	// The header, the blank line under it and the column headings leave
+	// the rest of the terminal to the cards
+	rows := 0
+	for _, tasks := range columns {
+		rows = max(rows, len(tasks))
+	}
+	if height > 0 {
+		rows = min(rows, max(height-4, 1))
+	}
+
+	cells := make([][]string, len(statuses))
+	for i, status := range statuses {
+		heading := fmt.Sprintf("%s (%d", strings.ToUpper(string(status)), len(columns[i]))
+		if limit := cfg.WIPLimit(string(status)); limit > 0 {
+			heading += fmt.Sprintf("/%d", limit)
+		}
+		cells[i] = append(cells[i], bold.Render(heading+")"))
+
+		tasks := columns[i]
+		if len(tasks) > rows {
+			// The last row says how many did not fit
+			tasks = tasks[:max(rows-1, 0)]
+		}
+		for _, t := range tasks {
+			cells[i] = append(cells[i], formatWatchCard(t, now))
+		}
+		if hidden := len(columns[i]) - len(tasks); hidden > 0 {
+			cells[i] = append(cells[i], faint.Render(fmt.Sprintf("… %d more", hidden)))
+		}
+	}
+
+	for row := 0; row <= rows; row++ {
+		var line strings.Builder
+		for i := range cells {
+			cell := ""
+			if row < len(cells[i]) {
+				cell = ansi.Truncate(cells[i][row], columnWidth, "…")
+			}
+			if i < len(cells)-1 {
+				cell += strings.Repeat(" ", columnWidth-ansi.StringWidth(cell)) + gap
+			}
+			line.WriteString(cell)
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// formatWatchCard is a task as one line of a watch column
+func formatWatchCard(t *task.Task, now time.Time) string {
+	card := fmt.Sprintf("#%d", t.ID)
+	if t.Priority > 0 {
+		card += fmt.Sprintf(" [%s]", task.GetPriorityName(t.Priority))
+	}
+	card += " " + t.Title + formatAssignee(t.Assignee)
+	if t.DueAt != nil && t.Status != task.StatusDone && t.DueAt.Before(now) {
+		card += " " + lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render("(overdue)")
+	}
+	return card
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/changelog"
+	"github.com/hmain/cainban/src/systems/config"
+)
+
+// installedVersion returns the version of this build as the release notes
+// name it; development builds have the unreleased notes
+func installedVersion() string {
+	if version == "" {
+		return changelog.Unreleased
+	}
+	return changelog.Normalize(version)
+}
+
+// hasUnseenNotes reports whether this build has release notes the user
+// has not been shown yet
+func hasUnseenNotes() bool {
+	seen := changelog.LastSeen(config.Dir())
+	return seen == "" || changelog.Compare(seen, installedVersion()) < 0
+}
+
+// handleWhatsNew shows the release notes of the versions since the one
+// whose notes were shown last, up to the installed one
+func handleWhatsNew(args []string) {
+	fs := newFlagSet("whatsnew")
+	all := fs.Bool("all", false, "show the notes of every release up to this one")
+	since := fs.String("since", "", "show the notes of the releases after this version")
+	args = parseFlags(fs, args)
+	if len(args) > 0 {
+		usageError("unknown argument '%s'", args[0])
+	}
+
+	releases, err := changelog.Releases()
+	if err != nil {
+		fmt.Printf("Error reading release notes: %v\n", err)
+		os.Exit(exitFailure)
+	}
+
+	installed := installedVersion()
+	seen := changelog.LastSeen(config.Dir())
+	var shown []changelog.Release
+	switch {
+	case *all:
+		shown = changelog.Between(releases, "", installed)
+	case *since != "":
+		shown = changelog.Between(releases, *since, installed)
+	case seen == "":
+		// Nothing was shown before: the notes of this version only
+		for _, r := range releases {
+			if changelog.Compare(r.Version, installed) == 0 {
+				shown = append(shown, r)
+			}
+		}
+	default:
+		shown = changelog.Between(releases, seen, installed)
+	}
+
+	if !*all && *since == "" {
+		if err := changelog.MarkSeen(config.Dir(), installed); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"installed": installed, "last_seen": seen, "releases": shown})
+		return
+	}
+
+	if len(shown) == 0 {
+		build := "cainban " + installed
+		if installed == changelog.Unreleased {
+			build = "this development build"
+		}
+		fmt.Printf("Nothing new in %s since you last looked\n", build)
+		fmt.Println("See every release with: cainban whatsnew --all")
+		return
+	}
+
+	breaking := 0
+	for i, r := range shown {
+		if i > 0 {
+			fmt.Println()
+		}
+		title := r.Version
+		if r.Date != "" {
+			title += " (" + r.Date + ")"
+		}
+		fmt.Println(title)
+		for _, s := range r.Sections {
+			fmt.Printf("  %s\n", s.Title)
+			for _, item := range s.Items {
+				text := strings.ReplaceAll(item.Text, "`", "")
+				if item.Breaking {
+					breaking++
+					fmt.Printf("    ! BREAKING: %s\n", text)
+				} else {
+					fmt.Printf("    • %s\n", text)
+				}
+			}
+		}
+	}
+	if breaking > 0 {
+		fmt.Printf("\n%d breaking changes; scripts and agents relying on them may need updating\n", breaking)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// handleWorklog adds an entry to the worklog of a task, or shows the
+// worklog when no summary is given
+func handleWorklog(args []string) {
+	fs := newFlagSet("worklog")
+	files := fs.String("files", "", "comma-separated paths of the files changed")
+	outcome := fs.String("outcome", "", "how the step ended: success, partial, failed or blocked")
+	exact := fs.Bool("exact", false, "match a title only in full, never fuzzily")
+	args = parseFlags(fs, args)
+
+	if len(args) == 0 || len(args) > 2 {
+		fmt.Println("Usage: cainban worklog <id|title> [\"summary\"] [--files <a,b>] [--outcome <outcome>] [--exact]")
+		fmt.Println("Examples:")
+		fmt.Println("  cainban worklog 12 \"Cached the token table\" --files lexer.go,lexer_test.go --outcome success")
+		fmt.Println("  cainban worklog 12                 # show the worklog")
+		os.Exit(exitUsage)
+	}
+	if len(args) == 1 && (*files != "" || *outcome != "") {
+		usageError("--files and --outcome go with a summary")
+	}
+
+	db, taskSystem, boardName, err := getCurrentBoardDB()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+	defer db.Close()
+
+	foundTask, err := findTaskWith(taskSystem, args[0], *exact)
+	if err != nil {
+		fmt.Printf("Error finding task: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if len(args) == 2 {
+		entry := task.Worklog{Summary: args[1], Outcome: task.Outcome(*outcome)}
+		if *files != "" {
+			entry.FilesChanged = strings.Split(*files, ",")
+		}
+		worklog, err := taskSystem.AppendWorklog(foundTask.ID, entry)
+		if err != nil {
+			fmt.Printf("Error adding to worklog: %v\n", err)
+			os.Exit(exitCode(err))
+		}
+		if cfg.OutputFormat == config.FormatJSON {
+			printJSON(map[string]interface{}{"board": boardName, "worklog": worklog})
+			return
+		}
+		fmt.Printf("Logged work on task #%d \"%s\" in board '%s'\n", foundTask.ID, foundTask.Title, boardName)
+		return
+	}
+
+	worklogs, err := taskSystem.ListWorklogs(foundTask.ID)
+	if err != nil {
+		fmt.Printf("Error loading worklog: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	if cfg.OutputFormat == config.FormatJSON {
+		printJSON(map[string]interface{}{"board": boardName, "task_id": foundTask.ID, "worklogs": worklogs})
+		return
+	}
+
+	fmt.Printf("Worklog of task #%d \"%s\":\n", foundTask.ID, foundTask.Title)
+	if len(worklogs) == 0 {
+		fmt.Println("  (none yet)")
+		return
+	}
+	for _, w := range worklogs {
+		fmt.Printf("  %s %s%s", w.CreatedAt.Local().Format("2006-01-02 15:04"), w.Summary, formatActor(w.Agent))
+		if w.Outcome != task.OutcomeNone {
+			fmt.Printf(" [%s]", w.Outcome)
+		}
+		fmt.Println()
+		if len(w.FilesChanged) > 0 {
+			fmt.Printf("    files: %s\n", strings.Join(w.FilesChanged, ", "))
+		}
+	}
+}
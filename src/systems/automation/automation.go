@@ -0,0 +1,384 @@
+package automation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/task"
+	"github.com/hmain/cainban/src/systems/webhook"
+)
+
+// Kind is what an automation or rule does when it fires
+type Kind string
+
+const (
+	KindWebhook Kind = "webhook" // POST the event to a URL
+	KindCommand Kind = "command" // run a shell command
+	KindMove    Kind = "move"    // move the task to a column
+	KindTag     Kind = "tag"     // put the task in a context
+	KindAssign  Kind = "assign"  // assign the task to an agent
+	KindComment Kind = "comment" // comment on the task
+)
+
+// IsExternal reports whether actions of the kind reach outside the board,
+// running a command or posting to a URL. Those are only run as set up on
+// this machine, never as they come with a board from elsewhere.
+func (k Kind) IsExternal() bool {
+	return k == KindWebhook || k == KindCommand
+}
+
+// maxRounds bounds how often Run goes back for events caused by its own
+// actions, so two rules moving a task back and forth cannot loop forever
+const maxRounds = 5
+
+// rulesCursor is the automation_cursors entry of the rules file
+const rulesCursor = "rules"
+
+// Automation runs an action whenever a task enters a column
+type Automation struct {
+	ID        int         `json:"id"`
+	Status    task.Status `json:"status"`
+	Kind      Kind        `json:"kind"`
+	Target    string      `json:"target"`
+	CreatedAt time.Time   `json:"created_at"`
+
+	lastEventID int
+}
+
+// rule is the automation as a rule: on entering its column, run its action
+func (a Automation) rule() Rule {
+	return Rule{Name: fmt.Sprintf("automation #%d", a.ID), On: OnMoved, To: a.Status, Action: a.Kind, Target: a.Target}
+}
+
+// Event is the payload handed to an automation: posted as the webhook data,
+// or written as JSON to the command's stdin
+type Event struct {
+	Type       task.EventType `json:"event_type"`
+	Task       *task.Task     `json:"task"`
+	FromStatus task.Status    `json:"from_status,omitempty"`
+	ToStatus   task.Status    `json:"to_status"`
+}
+
+// Firing is the outcome of running one automation or rule for one task
+type Firing struct {
+	Rule   Rule  `json:"rule"`
+	TaskID int   `json:"task_id"`
+	Err    error `json:"-"`
+}
+
+// System handles automation operations
+type System struct {
+	db     *sql.DB
+	ctx    context.Context
+	rules  []Rule
+	since  time.Time // when the rules were written
+	post   func(url, event, board string, data interface{}) error
+	run    func(ctx context.Context, command string, env []string, stdin []byte, limits Limits) commandResult
+	limits Limits
+	// skipExternal passes over commands and webhooks, see SkipExternal
+	skipExternal bool
+}
+
+// New creates a new automation system
+func New(db *sql.DB) *System {
+	return &System{db: db, ctx: context.Background(), post: webhook.Post, run: runCommand, limits: DefaultLimits()}
+}
+
+// SetLimits sets the limits the commands of automations and rules run under
+func (s *System) SetLimits(limits Limits) {
+	s.limits = limits
+}
+
+// SkipExternal makes Run pass over the automations and rules that run a
+// command or post to a URL, for a board worked on in a copy of one kept
+// elsewhere, whose automations whoever can change that board set up
+func (s *System) SkipExternal() {
+	s.skipExternal = true
+}
+
+// WithContext returns a copy of the system whose queries run under ctx.
+// The commands it runs are killed when ctx is done, or at their timeout.
+func (s *System) WithContext(ctx context.Context) *System {
+	copy := *s
+	copy.ctx = ctx
+	return &copy
+}
+
+// SetRules sets the rules Run evaluates besides the stored automations.
+// The first time rules run on a board they skip the events from before
+// since, the time the rules were written.
+func (s *System) SetRules(rules []Rule, since time.Time) {
+	s.rules = rules
+	s.since = since
+}
+
+// UseRulesFile sets the rules of a board's rules file, see LoadRules
+func (s *System) UseRulesFile(path string) error {
+	rules, err := LoadRules(path)
+	if err != nil || rules == nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read rules file: %w", err)
+	}
+	s.SetRules(rules, info.ModTime())
+	return nil
+}
+
+// Add creates an automation for a column. It only reacts to tasks entering
+// the column from now on, not to earlier moves.
+func (s *System) Add(status task.Status, kind Kind, target string) (*Automation, error) {
+	if !task.IsValidStatus(string(status)) {
+		return nil, fmt.Errorf("invalid status %q", status)
+	}
+	if kind != KindWebhook && kind != KindCommand {
+		return nil, fmt.Errorf("invalid automation kind %q (use %s or %s)", kind, KindWebhook, KindCommand)
+	}
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil, fmt.Errorf("automation %s cannot be empty", kind)
+	}
+
+	a := Automation{Status: status, Kind: kind, Target: target}
+	err := s.db.QueryRowContext(s.ctx, `
+		INSERT INTO automations (status, kind, target, last_event_id)
+		VALUES (?, ?, ?, (SELECT COALESCE(MAX(id), 0) FROM task_events))
+		RETURNING id, created_at
+	`, status, kind, target).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create automation: %w", err)
+	}
+
+	return &a, nil
+}
+
+// List returns the automations of the board
+func (s *System) List() ([]Automation, error) {
+	rows, err := s.db.QueryContext(s.ctx, `SELECT id, status, kind, target, last_event_id, created_at FROM automations ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list automations: %w", err)
+	}
+	defer rows.Close()
+
+	var automations []Automation
+	for rows.Next() {
+		var a Automation
+		if err := rows.Scan(&a.ID, &a.Status, &a.Kind, &a.Target, &a.lastEventID, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan automation: %w", err)
+		}
+		automations = append(automations, a)
+	}
+
+	return automations, rows.Err()
+}
+
+// Remove deletes an automation
+func (s *System) Remove(id int) error {
+	result, err := s.db.ExecContext(s.ctx, `DELETE FROM automations WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove automation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("automation with ID %d not found", id)
+	}
+	return nil
+}
+
+// pendingEvent is a task event an automation or rule has not handled yet
+type pendingEvent struct {
+	id     int
+	taskID int
+	kind   task.EventType
+	from   task.Status
+	to     task.Status
+}
+
+// Run fires the stored automations and the rules for the task events since
+// they last ran, reading them from the task history. Each event is handled
+// at most once: a failed action is reported in its Firing and not retried.
+// Events caused by the actions themselves, such as a rule moving a task,
+// are handled in the same run.
+func (s *System) Run(boardName string) ([]Firing, error) {
+	var firings []Firing
+	rules := s.rules
+	if s.skipExternal {
+		rules = nil
+		for _, r := range s.rules {
+			if !r.Action.IsExternal() {
+				rules = append(rules, r)
+			}
+		}
+	}
+	for round := 0; round < maxRounds; round++ {
+		var latest int
+		if err := s.db.QueryRowContext(s.ctx, `SELECT COALESCE(MAX(id), 0) FROM task_events`).Scan(&latest); err != nil {
+			return firings, fmt.Errorf("failed to query task history: %w", err)
+		}
+
+		fired := len(firings)
+
+		automations, err := s.List()
+		if err != nil {
+			return firings, err
+		}
+		for _, a := range automations {
+			events, err := s.pending(a.lastEventID, latest)
+			if err != nil {
+				return firings, err
+			}
+			if _, err := s.db.ExecContext(s.ctx, `UPDATE automations SET last_event_id = ? WHERE id = ?`, latest, a.ID); err != nil {
+				return firings, fmt.Errorf("failed to update automation: %w", err)
+			}
+			if s.skipExternal && a.Kind.IsExternal() {
+				continue
+			}
+			firings = append(firings, s.apply([]Rule{a.rule()}, boardName, events)...)
+		}
+
+		if len(rules) > 0 {
+			after, err := s.cursor(rulesCursor, s.since)
+			if err != nil {
+				return firings, err
+			}
+			events, err := s.pending(after, latest)
+			if err != nil {
+				return firings, err
+			}
+			if _, err := s.db.ExecContext(s.ctx, `UPDATE automation_cursors SET last_event_id = ? WHERE name = ?`, latest, rulesCursor); err != nil {
+				return firings, fmt.Errorf("failed to update rules cursor: %w", err)
+			}
+			firings = append(firings, s.apply(rules, boardName, events)...)
+		}
+
+		if len(firings) == fired {
+			break
+		}
+	}
+
+	return firings, nil
+}
+
+// cursor returns the last event a named set of rules has seen. A new cursor
+// starts at the last event before since, so rules never fire for what
+// happened before they were written.
+func (s *System) cursor(name string, since time.Time) (int, error) {
+	var after int
+	err := s.db.QueryRowContext(s.ctx, `
+		INSERT INTO automation_cursors (name, last_event_id)
+		VALUES (?, (SELECT COALESCE(MAX(id), 0) FROM task_events WHERE created_at < ?))
+		ON CONFLICT(name) DO UPDATE SET name = name
+		RETURNING last_event_id
+	`, name, since.UTC().Format("2006-01-02 15:04:05")).Scan(&after)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read rules cursor: %w", err)
+	}
+	return after, nil
+}
+
+// pending returns the task events after one event ID up to another
+func (s *System) pending(after, upTo int) ([]pendingEvent, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, task_id, event_type, COALESCE(from_status, ''), COALESCE(to_status, '')
+		FROM task_events
+		WHERE id > ? AND id <= ?
+		ORDER BY id
+	`, after, upTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task history: %w", err)
+	}
+	defer rows.Close()
+
+	var events []pendingEvent
+	for rows.Next() {
+		var e pendingEvent
+		if err := rows.Scan(&e.id, &e.taskID, &e.kind, &e.from, &e.to); err != nil {
+			return nil, fmt.Errorf("failed to scan task event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// apply runs every matching rule for each event, in order
+func (s *System) apply(rules []Rule, boardName string, events []pendingEvent) []Firing {
+	taskSystem := task.New(s.db).WithActor("automation")
+
+	var firings []Firing
+	for _, e := range events {
+		for _, rule := range rules {
+			// Reload the task for each rule so filters see earlier actions
+			t, err := taskSystem.GetByID(e.taskID)
+			if err != nil {
+				// Deleted since the event
+				break
+			}
+			if !rule.matches(e, t) {
+				continue
+			}
+
+			firing := Firing{Rule: rule, TaskID: t.ID}
+			firing.Err = s.fire(taskSystem, rule, boardName, Event{Type: e.kind, Task: t, FromStatus: e.from, ToStatus: e.to})
+			firings = append(firings, firing)
+		}
+	}
+	return firings
+}
+
+// fire runs the action of a rule for an event
+func (s *System) fire(taskSystem *task.System, rule Rule, boardName string, event Event) error {
+	t := event.Task
+
+	switch rule.Action {
+	case KindMove:
+		if t.Status == task.Status(rule.Target) {
+			return nil
+		}
+		return taskSystem.UpdateStatus(t.ID, task.Status(rule.Target))
+	case KindTag:
+		return taskSystem.AddContext(t.ID, rule.Target)
+	case KindAssign:
+		_, err := taskSystem.Assign(t.ID, rule.Target)
+		return err
+	case KindComment:
+		_, err := taskSystem.AddComment(t.ID, "cainban", rule.Target)
+		return err
+	case KindWebhook:
+		name := "task_entered_column"
+		if event.Type == task.EventCreated {
+			name = "task_created"
+		}
+		return s.post(rule.Target, name, boardName, event)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode automation payload: %w", err)
+	}
+
+	env := []string{
+		"CAINBAN_BOARD=" + boardName,
+		"CAINBAN_TASK_ID=" + strconv.Itoa(t.ID),
+		"CAINBAN_TASK_TITLE=" + t.Title,
+		"CAINBAN_FROM_STATUS=" + string(event.FromStatus),
+		"CAINBAN_STATUS=" + string(event.ToStatus),
+	}
+	result := s.run(s.ctx, rule.Target, env, payload, s.limits)
+	if err := s.logRun(rule, t.ID, result); err != nil && result.Err == nil {
+		return err
+	}
+	return result.Err
+}
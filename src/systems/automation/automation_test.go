@@ -0,0 +1,255 @@
+package automation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestRun(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	automationSystem := New(db.Conn())
+
+	var posted []Event
+	automationSystem.post = func(url, event, board string, data interface{}) error {
+		if url != "https://ci.example.com/deploy" || event != "task_entered_column" || board != "api" {
+			return fmt.Errorf("unexpected webhook %s %s %s", url, event, board)
+		}
+		posted = append(posted, data.(Event))
+		return nil
+	}
+
+	deploy, err := taskSystem.Create(1, "Deploy rate limiting", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	// Moves before the automation exists never fire it
+	if err := taskSystem.UpdateStatus(deploy.ID, task.StatusDone); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+	if err := taskSystem.UpdateStatus(deploy.ID, task.StatusTodo); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+
+	if _, err := automationSystem.Add(task.StatusDone, KindWebhook, "https://ci.example.com/deploy"); err != nil {
+		t.Fatalf("Failed to add automation: %v", err)
+	}
+
+	firings, err := automationSystem.Run("api")
+	if err != nil || len(firings) != 0 {
+		t.Fatalf("Expected nothing to fire yet, got %d (%v)", len(firings), err)
+	}
+
+	if err := taskSystem.UpdateStatus(deploy.ID, task.StatusDoing); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+	if err := taskSystem.UpdateStatus(deploy.ID, task.StatusDone); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+
+	firings, err = automationSystem.Run("api")
+	if err != nil {
+		t.Fatalf("Failed to run automations: %v", err)
+	}
+	if len(firings) != 1 || firings[0].Err != nil || firings[0].TaskID != deploy.ID {
+		t.Fatalf("Expected one successful firing, got %+v", firings)
+	}
+	if len(posted) != 1 || posted[0].FromStatus != task.StatusDoing || posted[0].ToStatus != task.StatusDone || posted[0].Task.Title != deploy.Title {
+		t.Errorf("Unexpected webhook payload: %+v", posted)
+	}
+
+	// Each move fires once
+	if firings, _ := automationSystem.Run("api"); len(firings) != 0 {
+		t.Errorf("Expected no firings on a second run, got %d", len(firings))
+	}
+}
+
+func TestRun_SkipExternal(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	automationSystem := New(db.Conn())
+	automationSystem.post = func(url, event, board string, data interface{}) error {
+		t.Errorf("Unexpected webhook to %s", url)
+		return nil
+	}
+	automationSystem.run = func(ctx context.Context, command string, env []string, stdin []byte, limits Limits) commandResult {
+		t.Errorf("Unexpected command %q", command)
+		return commandResult{}
+	}
+	automationSystem.SkipExternal()
+
+	if _, err := automationSystem.Add(task.StatusDone, KindCommand, "make deploy"); err != nil {
+		t.Fatalf("Failed to add automation: %v", err)
+	}
+	automationSystem.SetRules([]Rule{
+		{Name: "notify", On: OnMoved, To: task.StatusDone, Action: KindWebhook, Target: "https://chat.example.com/hook"},
+		{Name: "shipped", On: OnMoved, To: task.StatusDone, Action: KindComment, Target: "Shipped"},
+	}, time.Now().Add(-time.Hour))
+
+	deploy, err := taskSystem.Create(1, "Deploy rate limiting", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if err := taskSystem.UpdateStatus(deploy.ID, task.StatusDone); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+
+	firings, err := automationSystem.Run("api")
+	if err != nil {
+		t.Fatalf("Failed to run automations: %v", err)
+	}
+	if len(firings) != 1 || firings[0].Rule.Name != "shipped" {
+		t.Errorf("Expected only the comment rule to fire, got %+v", firings)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	automationSystem := New(db.Conn())
+
+	out := filepath.Join(t.TempDir(), "out")
+	command := fmt.Sprintf(`echo "$CAINBAN_BOARD #$CAINBAN_TASK_ID $CAINBAN_STATUS" > %s && cat >> %s`, out, out)
+	if _, err := automationSystem.Add(task.StatusDoing, KindCommand, command); err != nil {
+		t.Fatalf("Failed to add automation: %v", err)
+	}
+	if _, err := automationSystem.Add(task.StatusDoing, KindCommand, "echo broken >&2; exit 3"); err != nil {
+		t.Fatalf("Failed to add automation: %v", err)
+	}
+
+	created, err := taskSystem.Create(1, "Start the migration", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if err := taskSystem.UpdateStatus(created.ID, task.StatusDoing); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+
+	firings, err := automationSystem.Run("default")
+	if err != nil {
+		t.Fatalf("Failed to run automations: %v", err)
+	}
+	if len(firings) != 2 {
+		t.Fatalf("Expected 2 firings, got %d", len(firings))
+	}
+	if firings[0].Err != nil {
+		t.Errorf("Expected the first command to succeed: %v", firings[0].Err)
+	}
+	if firings[1].Err == nil || !strings.Contains(firings[1].Err.Error(), "broken") {
+		t.Errorf("Expected the second command's output in its error, got %v", firings[1].Err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read command output: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "default #1 doing\n") || !strings.Contains(string(data), `"title":"Start the migration"`) {
+		t.Errorf("Unexpected command output: %s", data)
+	}
+
+	// Both runs are in the log, with their output
+	runs, err := automationSystem.Runs(10)
+	if err != nil {
+		t.Fatalf("Failed to read command log: %v", err)
+	}
+	if len(runs) != 2 || runs[0].ExitCode != 0 || runs[1].ExitCode != 3 || runs[1].Output != "broken\n" || runs[1].Error == "" {
+		t.Errorf("Unexpected command log: %+v", runs)
+	}
+	if runs, _ := automationSystem.Runs(1); len(runs) != 1 || runs[0].ExitCode != 3 {
+		t.Errorf("Expected the latest run alone, got %+v", runs)
+	}
+}
+
+func TestRunCommand_Limits(t *testing.T) {
+	limits := Limits{Timeout: 200 * time.Millisecond, MaxOutput: 8}
+
+	// A command leaving a child behind with its output pipe cannot outlast
+	// its timeout
+	started := time.Now()
+	result := runCommand(context.Background(), "echo started; sleep 10 & sleep 10", nil, nil, limits)
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Errorf("Expected the command to be killed at its timeout, took %s", elapsed)
+	}
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "timed out") || result.ExitCode != -1 {
+		t.Errorf("Expected a timeout, got %v (exit %d)", result.Err, result.ExitCode)
+	}
+	if result.Output != "started\n" {
+		t.Errorf("Expected the output up to the timeout, got %q", result.Output)
+	}
+
+	// Output beyond the cap is dropped, and says so
+	result = runCommand(context.Background(), "echo 0123456789", nil, nil, limits)
+	if result.Err != nil || result.Output != "01234567\n[3 more bytes of output dropped]" {
+		t.Errorf("Expected capped output, got %q, %v", result.Output, result.Err)
+	}
+
+	// Cancelling the context stops the command too
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result := runCommand(ctx, "sleep 10", nil, nil, DefaultLimits()); result.Err == nil || !strings.Contains(result.Err.Error(), "cancelled") {
+		t.Errorf("Expected a cancelled command, got %v", result.Err)
+	}
+}
+
+func TestAddValidation(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	automationSystem := New(db.Conn())
+
+	tests := []struct {
+		name   string
+		status task.Status
+		kind   Kind
+		target string
+	}{
+		{"unknown status", "ready-for-deploy", KindCommand, "make deploy"},
+		{"unknown kind", task.StatusDone, "email", "ops@example.com"},
+		{"empty target", task.StatusDone, KindWebhook, "  "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := automationSystem.Add(tt.status, tt.kind, tt.target); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	a, err := automationSystem.Add(task.StatusDone, KindCommand, "make deploy")
+	if err != nil {
+		t.Fatalf("Failed to add automation: %v", err)
+	}
+	if err := automationSystem.Remove(a.ID); err != nil {
+		t.Fatalf("Failed to remove automation: %v", err)
+	}
+	if err := automationSystem.Remove(a.ID); err == nil {
+		t.Error("Expected error removing a missing automation")
+	}
+}
@@ -0,0 +1,128 @@
+package automation
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CommandTimeout bounds how long an automation command may run, unless the
+// limits say otherwise
+const CommandTimeout = time.Minute
+
+// Limits bound the commands automations and rules run, so that a hanging
+// or runaway command cannot hold up the daemon or the MCP server
+type Limits struct {
+	// Timeout is how long a command may run before it is killed, together
+	// with everything it started
+	Timeout time.Duration
+	// MemoryMB caps the virtual memory of each of its processes; 0 for no
+	// cap. Runtimes such as Node, the JVM and Go reserve far more address
+	// space than they use, so a cap is only set when asked for.
+	MemoryMB int
+	// MaxOutput is how many bytes of its output are kept in the log
+	MaxOutput int
+}
+
+// DefaultLimits returns the limits commands run under unless set otherwise
+func DefaultLimits() Limits {
+	return Limits{Timeout: CommandTimeout, MaxOutput: 16 << 10}
+}
+
+// commandResult is the outcome of running a command
+type commandResult struct {
+	Output   string
+	ExitCode int // -1 when it did not exit by itself
+	Duration time.Duration
+	Err      error
+}
+
+// runCommand runs a shell command with extra environment variables and the
+// event on stdin, under the limits. The command gets a process group of its
+// own, killed as a whole when ctx is done or the timeout passes, so that
+// nothing it left running can keep it from returning. Output is captured
+// rather than printed, since the MCP server's stdout is its protocol
+// channel; it is part of the error when the command fails.
+func runCommand(ctx context.Context, command string, env []string, stdin []byte, limits Limits) commandResult {
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", ulimits(limits)+command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	output := &cappedBuffer{max: limits.MaxOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	killProcessGroup(cmd)
+	// Output pipes still held open by a killed command's children are given
+	// up on after this
+	cmd.WaitDelay = time.Second
+
+	started := time.Now()
+	err := cmd.Run()
+	result := commandResult{Output: output.String(), ExitCode: -1, Duration: time.Since(started)}
+	if cmd.ProcessState != nil && cmd.ProcessState.Exited() {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Err = fmt.Errorf("command timed out after %s", result.Duration.Round(time.Millisecond))
+	case ctx.Err() != nil:
+		result.Err = fmt.Errorf("command cancelled: %w", ctx.Err())
+	default:
+		if out := strings.TrimSpace(result.Output); out != "" {
+			result.Err = fmt.Errorf("command failed: %w: %s", err, out)
+		} else {
+			result.Err = fmt.Errorf("command failed: %w", err)
+		}
+	}
+	return result
+}
+
+// ulimits returns the shell commands applying the limits to the command
+// and everything it starts. A limit the system does not know is skipped.
+func ulimits(limits Limits) string {
+	var b strings.Builder
+	if limits.Timeout > 0 {
+		// CPU time cannot exceed the time the command has
+		fmt.Fprintf(&b, "ulimit -t %d 2>/dev/null; ", int(limits.Timeout.Seconds())+1)
+	}
+	if limits.MemoryMB > 0 {
+		fmt.Fprintf(&b, "ulimit -v %d 2>/dev/null; ", limits.MemoryMB*1024)
+	}
+	return b.String()
+}
+
+// cappedBuffer keeps the first max bytes written to it, and counts the rest
+type cappedBuffer struct {
+	buf     bytes.Buffer
+	max     int
+	dropped int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	keep := len(p)
+	if room := b.max - b.buf.Len(); b.max > 0 && keep > room {
+		keep = max(room, 0)
+	}
+	b.buf.Write(p[:keep])
+	b.dropped += len(p) - keep
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	if b.dropped > 0 {
+		return fmt.Sprintf("%s\n[%d more bytes of output dropped]", b.buf.String(), b.dropped)
+	}
+	return b.buf.String()
+}
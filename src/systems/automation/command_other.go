@@ -0,0 +1,9 @@
+//go:build !unix
+
+package automation
+
+import "os/exec"
+
+// killProcessGroup leaves cmd as it is: without process groups, cancelling
+// it kills the shell alone, and WaitDelay covers what the shell started
+func killProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,119 @@
+package automation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// Hooks cainban runs, each an executable of that name in the hooks
+// directory. A pre- hook runs before the change and refuses it by exiting
+// with a failure; a post- hook runs after it, and its failure is only
+// reported.
+const (
+	HookPreCreate  = "pre-create"
+	HookPostCreate = "post-create"
+	HookPreMove    = "pre-move"
+	HookPostMove   = "post-move"
+	HookPreDelete  = "pre-delete"
+	HookPostDelete = "post-delete"
+)
+
+// HookNames lists the hooks cainban runs, in the order of a task's life
+var HookNames = []string{HookPreCreate, HookPostCreate, HookPreMove, HookPostMove, HookPreDelete, HookPostDelete}
+
+// HookEvent is written as JSON to a hook's stdin. The task of a pre-create
+// hook has no ID yet.
+type HookEvent struct {
+	Hook       string      `json:"hook"`
+	Board      string      `json:"board"`
+	Task       *task.Task  `json:"task"`
+	FromStatus task.Status `json:"from_status,omitempty"`
+	ToStatus   task.Status `json:"to_status,omitempty"`
+}
+
+// HooksDir returns the hooks directory of the profile in use
+func HooksDir() string {
+	return filepath.Join(config.Dir(), "hooks")
+}
+
+// Hooks runs the executables in a hooks directory, under the same limits
+// as automation commands
+type Hooks struct {
+	dir    string
+	limits Limits
+	run    func(ctx context.Context, command string, env []string, stdin []byte, limits Limits) commandResult
+}
+
+// NewHooks returns the hooks of dir; a missing directory has none
+func NewHooks(dir string) *Hooks {
+	return &Hooks{dir: dir, limits: DefaultLimits(), run: runCommand}
+}
+
+// SetLimits sets the limits hooks run under
+func (h *Hooks) SetLimits(limits Limits) {
+	h.limits = limits
+}
+
+// Path returns the executable of a hook and whether it is installed. As
+// with git hooks, a file that is not executable is not run.
+func (h *Hooks) Path(name string) (string, bool) {
+	path := filepath.Join(h.dir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+		return path, false
+	}
+	return path, true
+}
+
+// Run runs a hook, if installed, with the event on stdin. The error of a
+// pre- hook that fails is invalid input, so that the change it refuses is
+// reported as refused rather than as broken.
+func (h *Hooks) Run(ctx context.Context, event HookEvent) error {
+	path, ok := h.Path(event.Hook)
+	if !ok {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook payload: %w", err)
+	}
+	env := []string{
+		"CAINBAN_HOOK=" + event.Hook,
+		"CAINBAN_BOARD=" + event.Board,
+		"CAINBAN_TASK_ID=" + strconv.Itoa(event.Task.ID),
+		"CAINBAN_TASK_TITLE=" + event.Task.Title,
+		"CAINBAN_FROM_STATUS=" + string(event.FromStatus),
+		"CAINBAN_STATUS=" + string(event.ToStatus),
+	}
+
+	// exec hands the shell's process over to the hook, which still gets
+	// the limits the shell set
+	result := h.run(ctx, "exec "+shellQuote(path), env, payload, h.limits)
+	if result.Err == nil {
+		return nil
+	}
+	if strings.HasPrefix(event.Hook, "pre-") {
+		// What the hook said is the reason for refusing
+		reason := result.Err.Error()
+		if out := strings.TrimSpace(result.Output); result.ExitCode > 0 && out != "" {
+			reason = out
+		}
+		return storage.Errorf(storage.ErrInvalidInput, "%s hook refused: %s", event.Hook, reason)
+	}
+	return fmt.Errorf("%s hook: %w", event.Hook, result.Err)
+}
+
+// shellQuote quotes s as a single shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
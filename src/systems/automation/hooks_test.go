@@ -0,0 +1,61 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestHooks(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "post-move.out")
+	writeHook := func(name, script string, mode os.FileMode) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), mode); err != nil {
+			t.Fatalf("Failed to write hook: %v", err)
+		}
+	}
+	writeHook(HookPostMove, `echo "$CAINBAN_HOOK $CAINBAN_TASK_ID $CAINBAN_FROM_STATUS>$CAINBAN_STATUS" > '`+out+`'; cat >> '`+out+`'`, 0755)
+	writeHook(HookPreDelete, "echo 'keep the release tasks'; exit 1", 0755)
+	writeHook(HookPostDelete, "exit 3", 0755)
+	writeHook(HookPreCreate, "exit 1", 0644)
+
+	hooks := NewHooks(dir)
+	ctx := context.Background()
+	ship := &task.Task{ID: 7, Title: "Ship the release", Status: task.StatusDone}
+
+	if err := hooks.Run(ctx, HookEvent{Hook: HookPostMove, Board: "api", Task: ship, FromStatus: task.StatusDoing, ToStatus: task.StatusDone}); err != nil {
+		t.Fatalf("Run(post-move) error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Expected the hook to run: %v", err)
+	}
+	if lines := strings.SplitN(string(data), "\n", 2); lines[0] != "post-move 7 doing>done" || !strings.Contains(lines[1], `"title":"Ship the release"`) {
+		t.Errorf("Expected the event in the environment and on stdin, got:\n%s", data)
+	}
+
+	err = hooks.Run(ctx, HookEvent{Hook: HookPreDelete, Board: "api", Task: ship})
+	if !errors.Is(err, storage.ErrInvalidInput) || !strings.Contains(err.Error(), "pre-delete hook refused: keep the release tasks") {
+		t.Errorf("Expected a failing pre- hook to refuse with its output, got %v", err)
+	}
+	err = hooks.Run(ctx, HookEvent{Hook: HookPostDelete, Board: "api", Task: ship})
+	if err == nil || errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("Expected a failing post- hook to be an error, not a refusal, got %v", err)
+	}
+
+	// Hooks that are not executable, or not there, are not run
+	if _, ok := hooks.Path(HookPreCreate); ok {
+		t.Error("Expected a hook that is not executable not to be installed")
+	}
+	for _, name := range []string{HookPreCreate, HookPostCreate} {
+		if err := hooks.Run(ctx, HookEvent{Hook: name, Board: "api", Task: &task.Task{Title: "New"}}); err != nil {
+			t.Errorf("Run(%s) error = %v, want nothing run", name, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package automation
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxLoggedRuns is how many command runs a board keeps in its log; older
+// ones are dropped as new ones come in
+const maxLoggedRuns = 500
+
+// CommandRun is a command an automation or rule ran, as kept in the log
+type CommandRun struct {
+	ID       int       `json:"id"`
+	Rule     string    `json:"rule"`
+	TaskID   int       `json:"task_id"`
+	Command  string    `json:"command"`
+	ExitCode int       `json:"exit_code"` // -1 when killed or never started
+	Error    string    `json:"error,omitempty"`
+	Output   string    `json:"output"`
+	Duration int64     `json:"duration_ms"`
+	RanAt    time.Time `json:"ran_at"`
+}
+
+// logRun adds a command run to the log, dropping the oldest beyond
+// maxLoggedRuns
+func (s *System) logRun(rule Rule, taskID int, result commandResult) error {
+	var errText string
+	if result.Err != nil {
+		errText = result.Err.Error()
+	}
+	_, err := s.db.ExecContext(s.ctx, `
+		INSERT INTO command_runs (rule, task_id, command, exit_code, error, output, duration_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, rule.Name, taskID, rule.Target, result.ExitCode, errText, result.Output, result.Duration.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("failed to log command: %w", err)
+	}
+
+	_, err = s.db.ExecContext(s.ctx, `DELETE FROM command_runs WHERE id <= (SELECT MAX(id) FROM command_runs) - ?`, maxLoggedRuns)
+	if err != nil {
+		return fmt.Errorf("failed to log command: %w", err)
+	}
+	return nil
+}
+
+// Runs returns the latest limit command runs from the log, oldest first
+func (s *System) Runs(limit int) ([]CommandRun, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, rule, task_id, command, exit_code, error, output, duration_ms, created_at
+		FROM (SELECT * FROM command_runs ORDER BY id DESC LIMIT ?)
+		ORDER BY id
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read command log: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []CommandRun
+	for rows.Next() {
+		var r CommandRun
+		if err := rows.Scan(&r.ID, &r.Rule, &r.TaskID, &r.Command, &r.ExitCode, &r.Error, &r.Output, &r.Duration, &r.RanAt); err != nil {
+			return nil, fmt.Errorf("failed to read command log: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
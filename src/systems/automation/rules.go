@@ -0,0 +1,247 @@
+package automation
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// Events a rule can be triggered by
+const (
+	OnCreated = "created" // a task was added
+	OnMoved   = "moved"   // a task changed column
+)
+
+// Rule is a trigger, optional filters and one action. Rules are read from a
+// board's rules file; stored automations are rules too, with a column-entry
+// trigger and a webhook or command action.
+type Rule struct {
+	Name string `json:"name"`
+	On   string `json:"on"`
+
+	// Filters; empty ones match every task
+	From     task.Status `json:"from,omitempty"`
+	To       task.Status `json:"to,omitempty"`
+	Priority int         `json:"priority,omitempty"` // minimum priority
+	Assignee string      `json:"assignee,omitempty"`
+	Context  string      `json:"context,omitempty"`
+	Title    string      `json:"title,omitempty"` // case-insensitive substring
+
+	Action Kind `json:"action"`
+	// Target is the action's argument: a status, context, agent, URL,
+	// comment text or shell command
+	Target string `json:"target"`
+}
+
+// targetKeys names the rules file key holding the target of each action
+var targetKeys = map[Kind]string{
+	KindMove:    "status",
+	KindTag:     "tag",
+	KindAssign:  "agent",
+	KindWebhook: "url",
+	KindComment: "text",
+	KindCommand: "command",
+}
+
+// RulesPath returns the rules file of the board stored at dbPath: the
+// database path with its extension replaced by .rules.toml
+func RulesPath(dbPath string) string {
+	return strings.TrimSuffix(dbPath, filepath.Ext(dbPath)) + ".rules.toml"
+}
+
+// LoadRules reads a rules file. Each [rules.<name>] section is one rule:
+//
+//	[rules.deploy]
+//	on = "moved"            # or "created"
+//	to = "done"             # filters: from, to, priority, assignee, context, title
+//	context = "@deploy"
+//	action = "webhook"      # move, tag, assign, webhook, comment or command
+//	url = "https://ci.example.com/deploy"
+//
+// The action's argument goes in status, tag, agent, url, text or command
+// respectively. Rules run in name order. A missing file means no rules.
+func LoadRules(path string) ([]Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open rules file: %w", err)
+	}
+	defer file.Close()
+	return ParseRules(file, path)
+}
+
+// ParseRules reads rules in the format of LoadRules from r, naming path in
+// its errors
+func ParseRules(r io.Reader, path string) ([]Rule, error) {
+	values, err := config.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	sections := make(map[string]map[string]interface{})
+	for key, value := range values {
+		rest, ok := strings.CutPrefix(key, "rules.")
+		if !ok {
+			return nil, fmt.Errorf("%s: %s is outside a [rules.<name>] section", path, key)
+		}
+		dot := strings.LastIndex(rest, ".")
+		if dot <= 0 {
+			return nil, fmt.Errorf("%s: %s is outside a [rules.<name>] section", path, key)
+		}
+		name, field := rest[:dot], rest[dot+1:]
+		if sections[name] == nil {
+			sections[name] = make(map[string]interface{})
+		}
+		sections[name][field] = value
+	}
+
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rules := make([]Rule, 0, len(names))
+	for _, name := range names {
+		rule, err := parseRule(name, sections[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule %s: %w", path, name, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// parseRule builds and validates a rule from the keys of its section
+func parseRule(name string, fields map[string]interface{}) (Rule, error) {
+	rule := Rule{Name: name}
+
+	str := func(key string) (string, error) {
+		value, ok := fields[key]
+		if !ok {
+			return "", nil
+		}
+		s, ok := value.(string)
+		if !ok {
+			return "", fmt.Errorf("%s must be a string", key)
+		}
+		return strings.TrimSpace(s), nil
+	}
+	status := func(key string) (task.Status, error) {
+		s, err := str(key)
+		if err != nil || s == "" {
+			return "", err
+		}
+		if !task.IsValidStatus(s) {
+			return "", fmt.Errorf("%s: invalid status %q", key, s)
+		}
+		return task.Status(s), nil
+	}
+
+	var err error
+	if rule.On, err = str("on"); err != nil {
+		return rule, err
+	}
+	if rule.On != OnCreated && rule.On != OnMoved {
+		return rule, fmt.Errorf("on must be %q or %q", OnCreated, OnMoved)
+	}
+	if rule.From, err = status("from"); err != nil {
+		return rule, err
+	}
+	if rule.To, err = status("to"); err != nil {
+		return rule, err
+	}
+	if value, ok := fields["priority"]; ok {
+		priority, ok := value.(int)
+		if !ok || priority < 0 {
+			return rule, fmt.Errorf("priority must be a non-negative integer")
+		}
+		rule.Priority = priority
+	}
+	if rule.Assignee, err = str("assignee"); err != nil {
+		return rule, err
+	}
+	if rule.Context, err = str("context"); err != nil {
+		return rule, err
+	}
+	if rule.Context != "" {
+		if rule.Context, err = task.NormalizeContext(rule.Context); err != nil {
+			return rule, err
+		}
+	}
+	if rule.Title, err = str("title"); err != nil {
+		return rule, err
+	}
+
+	action, err := str("action")
+	if err != nil {
+		return rule, err
+	}
+	rule.Action = Kind(action)
+	key, ok := targetKeys[rule.Action]
+	if !ok {
+		return rule, fmt.Errorf("unknown action %q (use move, tag, assign, webhook, comment or command)", action)
+	}
+	if rule.Target, err = str(key); err != nil {
+		return rule, err
+	}
+	if rule.Target == "" {
+		return rule, fmt.Errorf("%s action requires %s", rule.Action, key)
+	}
+
+	switch rule.Action {
+	case KindMove:
+		if !task.IsValidStatus(rule.Target) {
+			return rule, fmt.Errorf("status: invalid status %q", rule.Target)
+		}
+	case KindTag:
+		if rule.Target, err = task.NormalizeContext(rule.Target); err != nil {
+			return rule, err
+		}
+	}
+
+	return rule, nil
+}
+
+// matches reports whether an event on a task triggers the rule
+func (r Rule) matches(e pendingEvent, t *task.Task) bool {
+	switch r.On {
+	case OnCreated:
+		if e.kind != task.EventCreated {
+			return false
+		}
+	case OnMoved:
+		if e.kind != task.EventStatusChanged {
+			return false
+		}
+	}
+
+	if r.From != "" && e.from != r.From {
+		return false
+	}
+	if r.To != "" && e.to != r.To {
+		return false
+	}
+	if t.Priority < r.Priority {
+		return false
+	}
+	if r.Assignee != "" && !strings.EqualFold(t.Assignee, r.Assignee) {
+		return false
+	}
+	if r.Context != "" && !t.HasContext(r.Context) {
+		return false
+	}
+	if r.Title != "" && !strings.Contains(strings.ToLower(t.Title), strings.ToLower(r.Title)) {
+		return false
+	}
+	return true
+}
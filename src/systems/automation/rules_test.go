@@ -0,0 +1,156 @@
+package automation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func writeRules(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "cainban.rules.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write rules file: %v", err)
+	}
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	rules, err := LoadRules(writeRules(t, `
+[rules.urgent]
+on = "created"
+priority = 3
+action = "tag"
+tag = "Urgent"
+
+[rules.deploy]
+on = "moved"
+to = "done"
+context = "@deploy"
+action = "webhook"
+url = "https://ci.example.com/deploy"
+`))
+	if err != nil {
+		t.Fatalf("Failed to load rules: %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %d", len(rules))
+	}
+	// Rules run in name order
+	if rules[0].Name != "deploy" || rules[0].To != task.StatusDone || rules[0].Target != "https://ci.example.com/deploy" {
+		t.Errorf("Unexpected first rule: %+v", rules[0])
+	}
+	if rules[1].Name != "urgent" || rules[1].Priority != 3 || rules[1].Target != "@urgent" {
+		t.Errorf("Unexpected second rule: %+v", rules[1])
+	}
+
+	if rules, err := LoadRules(filepath.Join(t.TempDir(), "missing.rules.toml")); err != nil || rules != nil {
+		t.Errorf("Expected no rules for a missing file, got %v, %v", rules, err)
+	}
+
+	invalid := []struct {
+		name    string
+		content string
+	}{
+		{"unknown trigger", "[rules.a]\non = \"deleted\"\naction = \"tag\"\ntag = \"x\""},
+		{"unknown action", "[rules.a]\non = \"created\"\naction = \"email\""},
+		{"missing target", "[rules.a]\non = \"created\"\naction = \"assign\""},
+		{"invalid status filter", "[rules.a]\non = \"moved\"\nto = \"shipped\"\naction = \"tag\"\ntag = \"x\""},
+		{"invalid move target", "[rules.a]\non = \"created\"\naction = \"move\"\nstatus = \"shipped\""},
+		{"key outside a rule", "action = \"tag\""},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadRules(writeRules(t, tt.content)); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestRunRules(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	// The rules were written after the first task was created
+	first, err := taskSystem.Create(1, "Hotfix from before the rules", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	automationSystem := New(db.Conn())
+	automationSystem.SetRules([]Rule{
+		{Name: "hotfix", On: OnCreated, Title: "hotfix", Action: KindMove, Target: string(task.StatusDoing)},
+		{Name: "oncall", On: OnMoved, To: task.StatusDoing, Action: KindAssign, Target: "oncall"},
+		{Name: "shipped", On: OnMoved, To: task.StatusDone, Context: "@deploy", Action: KindComment, Target: "Shipped"},
+	}, first.CreatedAt.Add(time.Second))
+
+	if firings, err := automationSystem.Run("default"); err != nil || len(firings) != 0 {
+		t.Fatalf("Expected no firings for earlier events, got %d (%v)", len(firings), err)
+	}
+
+	hotfix, err := taskSystem.Create(1, "Hotfix login crash", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	plain, err := taskSystem.Create(1, "Write release notes", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	firings, err := automationSystem.Run("default")
+	if err != nil {
+		t.Fatalf("Failed to run rules: %v", err)
+	}
+	// The move made by "hotfix" triggers "oncall" in the same run
+	if len(firings) != 2 || firings[0].Rule.Name != "hotfix" || firings[1].Rule.Name != "oncall" {
+		t.Fatalf("Unexpected firings: %+v", firings)
+	}
+	for _, f := range firings {
+		if f.Err != nil {
+			t.Errorf("Rule %s failed: %v", f.Rule.Name, f.Err)
+		}
+	}
+
+	got, _ := taskSystem.GetByID(hotfix.ID)
+	if got.Status != task.StatusDoing || got.Assignee != "oncall" {
+		t.Errorf("Expected hotfix in doing and assigned, got %s/%q", got.Status, got.Assignee)
+	}
+	if got, _ := taskSystem.GetByID(plain.ID); got.Status != task.StatusTodo {
+		t.Errorf("Expected other task untouched, got %s", got.Status)
+	}
+
+	// Filters see the task as it is when the rule runs
+	if err := taskSystem.AddContext(plain.ID, "@deploy"); err != nil {
+		t.Fatalf("Failed to add context: %v", err)
+	}
+	for _, id := range []int{hotfix.ID, plain.ID} {
+		if err := taskSystem.UpdateStatus(id, task.StatusDone); err != nil {
+			t.Fatalf("Failed to move task: %v", err)
+		}
+	}
+	firings, err = automationSystem.Run("default")
+	if err != nil {
+		t.Fatalf("Failed to run rules: %v", err)
+	}
+	if len(firings) != 1 || firings[0].TaskID != plain.ID {
+		t.Fatalf("Expected only the @deploy task to get a comment, got %+v", firings)
+	}
+	comments, _ := taskSystem.ListComments(plain.ID)
+	if len(comments) != 1 || comments[0].Body != "Shipped" {
+		t.Errorf("Unexpected comments: %+v", comments)
+	}
+}
+
+func TestRulesPath(t *testing.T) {
+	if got := RulesPath("/home/me/.cainban/boards/api.db"); got != "/home/me/.cainban/boards/api.rules.toml" {
+		t.Errorf("RulesPath() = %q", got)
+	}
+}
@@ -0,0 +1,111 @@
+package board
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// BoardTask is a task tagged with the name of the board it lives on
+type BoardTask struct {
+	Board string `json:"board"`
+	*task.Task
+}
+
+// ForEachBoard opens every board database in turn and calls fn with its
+// task system. The database is closed when fn returns.
+func (s *System) ForEachBoard(fn func(b *Board, taskSystem *task.System) error) error {
+	boards, err := s.ListBoards()
+	if err != nil {
+		return err
+	}
+
+	for _, b := range boards {
+		if err := s.withBoard(b, fn); err != nil {
+			return fmt.Errorf("board '%s': %w", b.Name, err)
+		}
+	}
+	return nil
+}
+
+// withBoard runs fn against a single board database
+func (s *System) withBoard(b *Board, fn func(b *Board, taskSystem *task.System) error) error {
+	db, err := storage.New(b.Path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return fn(b, task.New(db.Conn()))
+}
+
+// FindByHash looks a task up by its hash on the named board, for commands
+// that refer to tasks on other boards
+func (s *System) FindByHash(boardName, hash string) (*task.Task, error) {
+	b, err := s.GetBoard(boardName)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *task.Task
+	err = s.withBoard(b, func(_ *Board, taskSystem *task.System) error {
+		found, err = taskSystem.GetByHash(hash)
+		return err
+	})
+	return found, err
+}
+
+// ListAllTasks lists the tasks of every board, optionally only those with
+// the given status, grouped by board
+func (s *System) ListAllTasks(status task.Status) ([]BoardTask, error) {
+	var all []BoardTask
+	err := s.ForEachBoard(func(b *Board, taskSystem *task.System) error {
+		var tasks []*task.Task
+		var err error
+		if status != "" {
+			tasks, err = taskSystem.ListByStatus(1, status)
+		} else {
+			tasks, err = taskSystem.List(1)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, t := range tasks {
+			all = append(all, BoardTask{Board: b.Name, Task: t})
+		}
+		return nil
+	})
+	return all, err
+}
+
+// SearchAllBoards fuzzy-searches task titles on every board, best matches
+// first regardless of board
+func (s *System) SearchAllBoards(query string) ([]BoardTask, error) {
+	if query == "" {
+		return nil, storage.Errorf(ErrInvalidInput, "search query cannot be empty")
+	}
+
+	var matches []BoardTask
+	err := s.ForEachBoard(func(b *Board, taskSystem *task.System) error {
+		tasks, err := taskSystem.SearchTasks(1, query)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range tasks {
+			matches = append(matches, BoardTask{Board: b.Name, Task: t})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return task.MatchScore(matches[i].Task, query) > task.MatchScore(matches[j].Task, query)
+	})
+	return matches, nil
+}
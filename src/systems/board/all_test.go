@@ -0,0 +1,59 @@
+package board
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestSearchAllBoards(t *testing.T) {
+	configDir := t.TempDir()
+	s := &System{configDir: configDir, defaultBoard: "default"}
+	if err := os.MkdirAll(filepath.Join(configDir, "boards"), 0755); err != nil {
+		t.Fatalf("Failed to create boards directory: %v", err)
+	}
+
+	seed := func(name string, titles ...string) {
+		db, err := storage.New(s.GetBoardPath(name))
+		if err != nil {
+			t.Fatalf("Failed to create board %s: %v", name, err)
+		}
+		defer db.Close()
+		taskSystem := task.New(db.Conn())
+		for _, title := range titles {
+			if _, err := taskSystem.Create(1, title, ""); err != nil {
+				t.Fatalf("Failed to create task: %v", err)
+			}
+		}
+	}
+	seed("default", "Fix login redirect", "Write docs")
+	seed("api", "Login", "Rate limiting")
+
+	matches, err := s.SearchAllBoards("login")
+	if err != nil {
+		t.Fatalf("Failed to search all boards: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %d", len(matches))
+	}
+	// The exact title match on "api" outranks the partial one on "default"
+	if matches[0].Board != "api" || matches[0].Title != "Login" || matches[1].Board != "default" {
+		t.Errorf("Unexpected order: %s/%s, %s/%s", matches[0].Board, matches[0].Title, matches[1].Board, matches[1].Title)
+	}
+
+	all, err := s.ListAllTasks("")
+	if err != nil {
+		t.Fatalf("Failed to list all tasks: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("Expected 4 tasks across boards, got %d", len(all))
+	}
+
+	todo, err := s.ListAllTasks(task.StatusDone)
+	if err != nil || len(todo) != 0 {
+		t.Errorf("Expected no done tasks, got %d (%v)", len(todo), err)
+	}
+}
@@ -0,0 +1,87 @@
+package board
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupLayout is the timestamp in backup file names. It has milliseconds
+// so two destructive operations in the same second get a backup each.
+const backupLayout = "20060102-150405.000"
+
+// BackupDir returns the directory backups of this profile's boards go in
+func (s *System) BackupDir() string {
+	return filepath.Join(s.configDir, "backups")
+}
+
+// BackupPath returns where a backup of a board taken at the given time goes
+func (s *System) BackupPath(name string, at time.Time) string {
+	if name == "" {
+		name = s.defaultBoard
+	}
+	return filepath.Join(s.BackupDir(), sanitizeBoardName(name)+"-"+at.Format(backupLayout)+".db")
+}
+
+// Backups returns the paths of a board's timestamped backups, newest first
+func (s *System) Backups(name string) ([]string, error) {
+	if name == "" {
+		name = s.defaultBoard
+	}
+	entries, err := os.ReadDir(s.BackupDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backups directory: %w", err)
+	}
+
+	// Names are matched by their timestamp, so the backups of "api" never
+	// include those of "api-v2"
+	prefix := sanitizeBoardName(name) + "-"
+	type backup struct {
+		path string
+		at   time.Time
+	}
+	var backups []backup
+	for _, entry := range entries {
+		file := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(file, prefix) || !strings.HasSuffix(file, ".db") {
+			continue
+		}
+		at, err := time.Parse(backupLayout, strings.TrimSuffix(strings.TrimPrefix(file, prefix), ".db"))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{filepath.Join(s.BackupDir(), file), at})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].at.After(backups[j].at) })
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+	return paths, nil
+}
+
+// PruneBackups removes all but the newest keep timestamped backups of a
+// board, returning the paths removed. Backups written elsewhere with an
+// explicit path are never touched.
+func (s *System) PruneBackups(name string, keep int) ([]string, error) {
+	backups, err := s.Backups(name)
+	if err != nil || len(backups) <= keep {
+		return nil, err
+	}
+
+	var removed []string
+	for _, path := range backups[keep:] {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,55 @@
+package board
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupsAndPrune(t *testing.T) {
+	s := &System{configDir: t.TempDir(), defaultBoard: "default"}
+
+	if backups, err := s.Backups("api"); err != nil || len(backups) != 0 {
+		t.Fatalf("Expected no backups before any were taken, got %v, %v", backups, err)
+	}
+
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	var paths []string
+	for i := 0; i < 4; i++ {
+		paths = append(paths, s.BackupPath("api", start.Add(time.Duration(i)*time.Millisecond)))
+	}
+	others := []string{
+		s.BackupPath("api-v2", start),
+		filepath.Join(s.BackupDir(), "api-manual.db"),
+	}
+	if err := os.MkdirAll(s.BackupDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range append(paths, others...) {
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := s.Backups("api")
+	if err != nil {
+		t.Fatalf("Backups() error = %v", err)
+	}
+	if len(backups) != 4 || backups[0] != paths[3] || backups[3] != paths[0] {
+		t.Errorf("Expected the four backups of api, newest first, got %v", backups)
+	}
+
+	removed, err := s.PruneBackups("api", 2)
+	if err != nil {
+		t.Fatalf("PruneBackups() error = %v", err)
+	}
+	if len(removed) != 2 || removed[0] != paths[1] || removed[1] != paths[0] {
+		t.Errorf("Expected the two oldest backups removed, got %v", removed)
+	}
+	for _, path := range append(paths[2:], others...) {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be kept: %v", filepath.Base(path), err)
+		}
+	}
+}
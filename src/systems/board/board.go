@@ -6,39 +6,147 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/storage"
 )
 
 // Board represents a kanban board
 type Board struct {
-	ID          int       `json:"id"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
-	Path        string    `json:"path"` // Database file path
+	Path        string    `json:"path"`             // Database file path
+	Remote      string    `json:"remote,omitempty"` // URL of a remote board, whose local copy Path is
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	// Settings are kept in the board's database, such as its column order
+	Settings map[string]string `json:"settings,omitempty"`
 }
 
+// LocalDirName is the directory holding a repo-local board
+const LocalDirName = ".cainban"
+
+// localDBName is the database file inside a repo-local board directory
+const localDBName = "cainban.db"
+
 // System handles board operations
 type System struct {
-	configDir string
+	configDir    string
+	defaultBoard string
+	localDir     string // repo-local .cainban directory, if one was found
+	autoBoard    bool   // whether the board of the working directory is current
+	workDir      string
+	override     string // board selected for this process, over all others
 }
 
-// New creates a new board system
+// New creates a new board system, on the boards of the profile in use
 func New() *System {
-	homeDir, err := os.UserHomeDir()
+	configDir := config.Dir()
+
+	s := &System{
+		configDir:    configDir,
+		defaultBoard: "default",
+		override:     strings.TrimSpace(os.Getenv(config.BoardEnv)),
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		s.workDir = cwd
+		s.localDir = s.findLocalDir(cwd)
+	}
+
+	return s
+}
+
+// findLocalDir walks up from dir looking for a repo-local board directory.
+// The global config directory is skipped even though it has the same name.
+func (s *System) findLocalDir(dir string) string {
+	globalDir, _ := filepath.Abs(s.configDir)
+
+	for {
+		candidate := filepath.Join(dir, LocalDirName)
+		if candidate != globalDir {
+			if _, err := os.Stat(filepath.Join(candidate, localDBName)); err == nil {
+				return candidate
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LocalDir returns the repo-local board directory in use, or an empty string
+// when boards come from the global config directory
+func (s *System) LocalDir() string {
+	return s.localDir
+}
+
+// localBoardName names the repo-local board after the directory containing it
+func (s *System) localBoardName() string {
+	return filepath.Base(filepath.Dir(s.localDir))
+}
+
+// InitLocal creates a repo-local board directory in dir and makes it take
+// precedence over the global boards. It returns the local board name.
+func (s *System) InitLocal(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
 	if err != nil {
-		// Fallback to current directory
-		homeDir = "."
+		return "", fmt.Errorf("failed to resolve directory: %w", err)
+	}
+
+	localDir := filepath.Join(absDir, LocalDirName)
+	if globalDir, _ := filepath.Abs(s.configDir); localDir == globalDir {
+		return "", fmt.Errorf("%s is the global config directory", localDir)
 	}
-	configDir := filepath.Join(homeDir, ".cainban")
 
-	return &System{
-		configDir: configDir,
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create local board directory: %w", err)
 	}
+
+	// Keep SQLite's transient files out of version control
+	gitignore := filepath.Join(localDir, ".gitignore")
+	if _, err := os.Stat(gitignore); os.IsNotExist(err) {
+		content := localDBName + "-wal\n" + localDBName + "-shm\ncainban.sandbox*\n"
+		if err := os.WriteFile(gitignore, []byte(content), 0644); err != nil {
+			return "", fmt.Errorf("failed to write .gitignore: %w", err)
+		}
+	}
+
+	s.localDir = localDir
+	return s.localBoardName(), nil
+}
+
+// SetDefaultBoard changes the board used when no current board has been
+// selected, e.g. from the default_board config setting
+func (s *System) SetDefaultBoard(boardName string) {
+	if boardName == "" {
+		boardName = "default"
+	}
+	s.defaultBoard = boardName
+}
+
+// SetAutoBoard makes the board of the repository or directory in use, if
+// there is one, take precedence over the board selected with
+// SetCurrentBoard, e.g. from the auto_board config setting
+func (s *System) SetAutoBoard(on bool) {
+	s.autoBoard = on
+}
+
+// Override returns the board selected for this process with
+// config.BoardEnv, or "" when there is none
+func (s *System) Override() string {
+	return s.override
 }
 
 // GetBoardPath returns the database path for a board
 func (s *System) GetBoardPath(boardName string) string {
+	if s.localDir != "" && boardName == s.localBoardName() {
+		return filepath.Join(s.localDir, localDBName)
+	}
+
 	if boardName == "" || boardName == "default" {
 		return filepath.Join(s.configDir, "cainban.db")
 	}
@@ -48,24 +156,84 @@ func (s *System) GetBoardPath(boardName string) string {
 	return filepath.Join(s.configDir, "boards", safeName+".db")
 }
 
-// GetCurrentBoard returns the currently active board name
+// GetCurrentBoard returns the currently active board name. A repo-local
+// board takes precedence over the board selected with SetCurrentBoard, and
+// so does the project board with SetAutoBoard on. The board given in
+// config.BoardEnv comes before all of them.
 func (s *System) GetCurrentBoard() (string, error) {
-	currentFile := filepath.Join(s.configDir, "current-board")
+	if s.override != "" {
+		return s.override, nil
+	}
+	if s.localDir != "" {
+		return s.localBoardName(), nil
+	}
+	if s.autoBoard {
+		if project := s.ProjectBoard(); project != "" {
+			return project, nil
+		}
+	}
 
-	data, err := os.ReadFile(currentFile)
+	boardName, err := s.savedBoard()
+	if err != nil {
+		return "", err
+	}
+	if boardName == "" {
+		return s.defaultBoard, nil // Default board if no current board set
+	}
+
+	return boardName, nil
+}
+
+// savedBoard returns the board selected with SetCurrentBoard, or "" when
+// none is. Unlike GetCurrentBoard it ignores the boards that take
+// precedence, so that changes to the selection are made against it and not
+// against a board chosen for this process alone.
+func (s *System) savedBoard() (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.configDir, "current-board"))
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "default", nil // Default board if no current board set
+			return "", nil
 		}
 		return "", fmt.Errorf("failed to read current board: %w", err)
 	}
+	return strings.TrimSpace(string(data)), nil
+}
 
-	boardName := strings.TrimSpace(string(data))
-	if boardName == "" {
-		return "default", nil
+// MissingBoardError reports a board whose database file is gone, e.g. after
+// it was deleted by hand while still selected
+type MissingBoardError struct {
+	Name string
+	Path string
+}
+
+func (e *MissingBoardError) Error() string {
+	return fmt.Sprintf("board '%s' is missing: its database %s no longer exists", e.Name, e.Path)
+}
+
+// Unwrap makes a missing board an ErrNotFound to errors.Is, so it exits and
+// fails over MCP as one
+func (e *MissingBoardError) Unwrap() error {
+	return ErrNotFound
+}
+
+// CheckBoard returns a *MissingBoardError if the named board's database is
+// gone. The default and repo-local boards are created on first use, so they
+// are never missing, and a remote board is fetched; any other board was made
+// with CreateBoard, and opening it anyway would silently start an empty
+// board in its place.
+func (s *System) CheckBoard(name string) error {
+	if name == "" || name == "default" || (s.localDir != "" && name == s.localBoardName()) {
+		return nil
+	}
+	if r, err := s.Remote(name); err != nil || r != nil {
+		return err
 	}
 
-	return boardName, nil
+	path := s.GetBoardPath(name)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return &MissingBoardError{Name: name, Path: path}
+	}
+	return nil
 }
 
 // SetCurrentBoard sets the active board
@@ -76,7 +244,7 @@ func (s *System) SetCurrentBoard(boardName string) error {
 
 	currentFile := filepath.Join(s.configDir, "current-board")
 
-	if boardName == "" || boardName == "default" {
+	if boardName == "" || boardName == s.defaultBoard {
 		// Remove current board file to use default
 		os.Remove(currentFile)
 		return nil
@@ -85,48 +253,75 @@ func (s *System) SetCurrentBoard(boardName string) error {
 	return os.WriteFile(currentFile, []byte(boardName), 0644)
 }
 
-// CreateBoard creates a new board
+// CreateBoard creates a new board, with its database recording its name,
+// description and when it was created, which ListBoards and GetBoard read
+// back
 func (s *System) CreateBoard(name, description string) (*Board, error) {
+	boardPath, err := s.NewBoardPath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := storage.New(boardPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create board database: %w", err)
+	}
+	err = db.SetBoardName(name)
+	if err == nil {
+		err = db.SetBoardDescription(description)
+	}
+	db.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	board := &Board{Name: name, Path: boardPath}
+	readRecord(board)
+	return board, nil
+}
+
+// NewBoardPath returns the database path of a board that does not exist
+// yet, ready to be created there. CreateBoard starts from it, as do
+// imports that fill in the database themselves.
+func (s *System) NewBoardPath(name string) (string, error) {
 	if name == "" {
-		return nil, fmt.Errorf("board name cannot be empty")
+		return "", storage.Errorf(ErrInvalidInput, "board name cannot be empty")
 	}
 
 	// Create boards directory
 	boardsDir := filepath.Join(s.configDir, "boards")
 	if err := os.MkdirAll(boardsDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create boards directory: %w", err)
+		return "", fmt.Errorf("failed to create boards directory: %w", err)
 	}
 
 	boardPath := s.GetBoardPath(name)
 
 	// Check if board already exists
 	if _, err := os.Stat(boardPath); err == nil {
-		return nil, fmt.Errorf("board '%s' already exists", name)
-	}
-
-	board := &Board{
-		Name:        name,
-		Description: description,
-		Path:        boardPath,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		return "", storage.Errorf(ErrInvalidInput, "board '%s' already exists", name)
 	}
-
-	return board, nil
+	return boardPath, nil
 }
 
 // ListBoards returns all available boards
 func (s *System) ListBoards() ([]*Board, error) {
 	var boards []*Board
 
+	// Add repo-local board
+	if s.localDir != "" {
+		local := &Board{Name: s.localBoardName(), Path: s.GetBoardPath(s.localBoardName())}
+		if readRecord(local); local.Description == "" {
+			local.Description = "Repo-local board"
+		}
+		boards = append(boards, local)
+	}
+
 	// Add default board
 	defaultPath := s.GetBoardPath("default")
 	if _, err := os.Stat(defaultPath); err == nil {
-		boards = append(boards, &Board{
-			Name:        "default",
-			Description: "Default kanban board",
-			Path:        defaultPath,
-		})
+		defaultBoard := &Board{Name: "default", Path: defaultPath}
+		readRecord(defaultBoard)
+		boards = append(boards, defaultBoard)
 	}
 
 	// Add custom boards
@@ -143,19 +338,91 @@ func (s *System) ListBoards() ([]*Board, error) {
 			}
 
 			name := strings.TrimSuffix(entry.Name(), ".db")
-			boardPath := filepath.Join(boardsDir, entry.Name())
+			// Sanitized board names have no dots; sandbox copies such as
+			// name.sandbox.db do
+			if strings.Contains(name, ".") {
+				continue
+			}
+			// The file name is the board name sanitized; the record keeps
+			// the name as given, e.g. "my board" for my_board.db
+			b := &Board{Name: name, Path: filepath.Join(boardsDir, entry.Name())}
+			if recorded := readRecord(b); recorded != "" && sanitizeBoardName(recorded) == name {
+				b.Name = recorded
+			}
+			boards = append(boards, b)
+		}
+	}
 
-			boards = append(boards, &Board{
-				Name: name,
-				Path: boardPath,
-			})
+	// Mark remote boards, and add those not fetched yet
+	remotes, err := s.RemoteBoards()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range remotes {
+		found := false
+		for _, b := range boards {
+			if b.Path == s.GetBoardPath(r.Name) {
+				b.Name, b.Remote, found = r.Name, r.URL, true
+			}
+		}
+		if !found {
+			boards = append(boards, &Board{Name: r.Name, Path: s.GetBoardPath(r.Name), Remote: r.URL})
 		}
 	}
 
 	return boards, nil
 }
 
-// GetBoard returns a specific board by name
+// readRecord fills in what the board's database records about it, and
+// returns the name the record gives the board, or "" when it was never
+// named. Every database starts out with the default board's record, so for
+// any other board a record that was never named is not trusted to describe
+// it.
+func readRecord(b *Board) string {
+	record, err := storage.ReadBoard(b.Path)
+	if err != nil {
+		return ""
+	}
+	b.CreatedAt, b.UpdatedAt, b.Settings = record.CreatedAt, record.UpdatedAt, record.Settings
+	if record.Name != storage.DefaultBoardName || b.Name == "default" {
+		b.Description = record.Description
+	}
+	if record.Name == storage.DefaultBoardName {
+		return ""
+	}
+	return record.Name
+}
+
+// SetDescription records what a board is for. The default board is no
+// different from the others here.
+func (s *System) SetDescription(name, description string) error {
+	b, err := s.GetBoard(name)
+	if err != nil && name != "default" {
+		return err
+	}
+	if b != nil {
+		name = b.Name
+	}
+
+	db, err := storage.New(s.GetBoardPath(name))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Name a record left over from before boards were named, so that its
+	// description is trusted from now on
+	if name != "default" {
+		if err := db.SetBoardName(name); err != nil {
+			return err
+		}
+	}
+	return db.SetBoardDescription(description)
+}
+
+// GetBoard returns a specific board by name. A name that is no board's
+// finds the board stored under it, so the sanitized name of a board whose
+// name needed sanitizing finds it too.
 func (s *System) GetBoard(name string) (*Board, error) {
 	boards, err := s.ListBoards()
 	if err != nil {
@@ -167,25 +434,31 @@ func (s *System) GetBoard(name string) (*Board, error) {
 			return board, nil
 		}
 	}
+	if name != "" {
+		path := s.GetBoardPath(name)
+		for _, board := range boards {
+			if board.Path == path {
+				return board, nil
+			}
+		}
+	}
 
-	return nil, fmt.Errorf("board '%s' not found", name)
+	return nil, storage.Errorf(ErrNotFound, "board '%s' not found", name)
 }
 
-// DeleteBoard removes a board (except default)
-func (s *System) DeleteBoard(name string) error {
-	if name == "" || name == "default" {
-		return fmt.Errorf("cannot delete default board")
+// DeleteBoard moves a board (except default) to the trash, from where
+// RestoreBoard brings it back
+func (s *System) DeleteBoard(name string) (*TrashedBoard, error) {
+	if err := s.CheckDeletable(name); err != nil {
+		return nil, err
 	}
-
-	boardPath := s.GetBoardPath(name)
-
-	if _, err := os.Stat(boardPath); os.IsNotExist(err) {
-		return fmt.Errorf("board '%s' does not exist", name)
+	trashed, err := s.trashBoard(name, s.GetBoardPath(name))
+	if err != nil {
+		return nil, err
 	}
 
 	// If this is the current board, switch to default
-	currentBoard, _ := s.GetCurrentBoard()
-	if currentBoard == name {
+	if saved, _ := s.savedBoard(); saved != "" && s.GetBoardPath(saved) == s.GetBoardPath(name) {
 		if err := s.SetCurrentBoard("default"); err != nil {
 			// Log error but don't fail deletion - the board deletion can continue
 			// even if setting default fails as it's just a convenience operation
@@ -193,13 +466,28 @@ func (s *System) DeleteBoard(name string) error {
 		}
 	}
 
-	return os.Remove(boardPath)
+	return trashed, nil
+}
+
+// CheckDeletable returns the error DeleteBoard would refuse a board with,
+// or nil if it can be deleted
+func (s *System) CheckDeletable(name string) error {
+	if name == "" || name == "default" {
+		return storage.Errorf(ErrInvalidInput, "cannot delete default board")
+	}
+	if s.localDir != "" && name == s.localBoardName() {
+		return fmt.Errorf("board '%s' is repo-local; remove %s instead", name, s.localDir)
+	}
+	if _, err := os.Stat(s.GetBoardPath(name)); os.IsNotExist(err) {
+		return storage.Errorf(ErrNotFound, "board '%s' does not exist", name)
+	}
+	return nil
 }
 
 // DetectProjectBoard attempts to detect board name from current directory
 func (s *System) DetectProjectBoard() string {
 	// Try to get git repository name
-	if gitName := getGitRepoName(); gitName != "" {
+	if gitName := getGitRepoName("."); gitName != "" {
 		return gitName
 	}
 
@@ -217,6 +505,59 @@ func (s *System) DetectProjectBoard() string {
 	return dirName
 }
 
+// ProjectBoard returns the board of the git repository or directory in
+// use, when SetAutoBoard is on: the first existing board named after the
+// repository's origin, its top directory or the working directory itself.
+// It returns "" when there is none, or when SetAutoBoard is off.
+func (s *System) ProjectBoard() string {
+	if !s.autoBoard || s.workDir == "" {
+		return ""
+	}
+	return s.projectBoard(s.workDir)
+}
+
+func (s *System) projectBoard(dir string) string {
+	var candidates []string
+	if root := findGitRoot(dir); root != "" {
+		candidates = append(candidates, getGitRepoName(root), filepath.Base(root))
+	}
+	candidates = append(candidates, filepath.Base(dir))
+
+	for _, name := range candidates {
+		if name == "" || name == "." || name == string(filepath.Separator) {
+			continue
+		}
+		if _, err := os.Stat(s.GetBoardPath(name)); err == nil {
+			return name
+		}
+		if r, err := s.Remote(name); err == nil && r != nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// findGitRoot walks up from dir to the top directory of the git repository
+// containing it, or returns "" outside of one
+func findGitRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// FileStem returns what the files of the board named name are named after,
+// the name with the characters unsafe in file names replaced
+func FileStem(name string) string {
+	return sanitizeBoardName(name)
+}
+
 // sanitizeBoardName creates a safe filename from board name
 func sanitizeBoardName(name string) string {
 	// Replace unsafe characters with underscores
@@ -235,10 +576,10 @@ func sanitizeBoardName(name string) string {
 	return safe
 }
 
-// getGitRepoName tries to get the git repository name
-func getGitRepoName() string {
+// getGitRepoName tries to get the name of the git repository in dir
+func getGitRepoName(dir string) string {
 	// Try to read .git/config for remote origin
-	gitConfig := ".git/config"
+	gitConfig := filepath.Join(dir, ".git", "config")
 	if _, err := os.Stat(gitConfig); err != nil {
 		return ""
 	}
@@ -0,0 +1,295 @@
+package board
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestLocalBoardTakesPrecedence(t *testing.T) {
+	root := t.TempDir()
+	home := filepath.Join(root, "home")
+	repo := filepath.Join(home, "src", "myrepo")
+	nested := filepath.Join(repo, "internal", "pkg")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+
+	s := &System{configDir: filepath.Join(home, ".cainban"), defaultBoard: "default"}
+
+	// The global directory has the same name but must not be picked up
+	if err := os.MkdirAll(s.configDir, 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.configDir, localDBName), nil, 0644); err != nil {
+		t.Fatalf("Failed to create global database: %v", err)
+	}
+	if dir := s.findLocalDir(nested); dir != "" {
+		t.Fatalf("Expected no local board, found %s", dir)
+	}
+
+	name, err := s.InitLocal(repo)
+	if err != nil {
+		t.Fatalf("Failed to init local board: %v", err)
+	}
+	if name != "myrepo" {
+		t.Errorf("Local board name = %q, want myrepo", name)
+	}
+	if err := os.WriteFile(s.GetBoardPath(name), nil, 0644); err != nil {
+		t.Fatalf("Failed to create local database: %v", err)
+	}
+
+	// A fresh system started in a subdirectory finds the board
+	fresh := &System{configDir: s.configDir, defaultBoard: "default"}
+	fresh.localDir = fresh.findLocalDir(nested)
+	if want := filepath.Join(repo, LocalDirName); fresh.LocalDir() != want {
+		t.Fatalf("LocalDir() = %q, want %q", fresh.LocalDir(), want)
+	}
+
+	if err := fresh.SetCurrentBoard("other"); err != nil {
+		t.Fatalf("Failed to set current board: %v", err)
+	}
+	current, err := fresh.GetCurrentBoard()
+	if err != nil {
+		t.Fatalf("Failed to get current board: %v", err)
+	}
+	if current != "myrepo" {
+		t.Errorf("Current board = %q, want the local board", current)
+	}
+	if want := filepath.Join(repo, LocalDirName, localDBName); fresh.GetBoardPath(current) != want {
+		t.Errorf("GetBoardPath() = %q, want %q", fresh.GetBoardPath(current), want)
+	}
+
+	if _, err := fresh.DeleteBoard("myrepo"); err == nil {
+		t.Error("Expected error deleting the repo-local board")
+	}
+}
+
+func TestAutoBoard(t *testing.T) {
+	root := t.TempDir()
+	repo := filepath.Join(root, "src", "checkout")
+	nested := filepath.Join(repo, "cmd")
+	elsewhere := filepath.Join(root, "notes")
+	for _, dir := range []string{filepath.Join(repo, ".git"), nested, elsewhere} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create directories: %v", err)
+		}
+	}
+	gitConfig := "[remote \"origin\"]\n\turl = git@github.com:alice/webshop.git\n"
+	if err := os.WriteFile(filepath.Join(repo, ".git", "config"), []byte(gitConfig), 0644); err != nil {
+		t.Fatalf("Failed to write git config: %v", err)
+	}
+
+	s := &System{configDir: filepath.Join(root, ".cainban"), defaultBoard: "default", workDir: nested}
+	for _, name := range []string{"webshop", "checkout", "notes", "work"} {
+		if _, err := s.CreateBoard(name, ""); err != nil {
+			t.Fatalf("Failed to create board: %v", err)
+		}
+	}
+	if err := s.SetCurrentBoard("work"); err != nil {
+		t.Fatalf("Failed to set current board: %v", err)
+	}
+
+	if current, _ := s.GetCurrentBoard(); current != "work" {
+		t.Errorf("Current board = %q, want the selected board while auto_board is off", current)
+	}
+	s.SetAutoBoard(true)
+	if current, _ := s.GetCurrentBoard(); current != "webshop" {
+		t.Errorf("Current board = %q, want the board named after the repository's origin", current)
+	}
+	if got := s.projectBoard(elsewhere); got != "notes" {
+		t.Errorf("projectBoard() = %q, want the board named after the directory", got)
+	}
+
+	// Without a board of the origin's name, the checkout's name is next,
+	// and without any the selected board is current
+	if _, err := s.DeleteBoard("webshop"); err != nil {
+		t.Fatalf("Failed to delete board: %v", err)
+	}
+	if got := s.projectBoard(nested); got != "checkout" {
+		t.Errorf("projectBoard() = %q, want the board named after the repository's directory", got)
+	}
+	s.workDir = root
+	if current, _ := s.GetCurrentBoard(); current != "work" {
+		t.Errorf("Current board = %q, want the selected board outside any project", current)
+	}
+}
+
+func TestBoardOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(config.BoardEnv, " team ")
+	s := New()
+	if err := s.SetCurrentBoard("work"); err != nil {
+		t.Fatalf("Failed to set current board: %v", err)
+	}
+	s.SetAutoBoard(true)
+	if current, _ := s.GetCurrentBoard(); current != "team" || s.Override() != "team" {
+		t.Errorf("Current board = %q, want the board of %s", current, config.BoardEnv)
+	}
+}
+
+func TestCheckBoard(t *testing.T) {
+	s := &System{configDir: filepath.Join(t.TempDir(), ".cainban"), defaultBoard: "default"}
+
+	// The default board is created on first use
+	if err := s.CheckBoard("default"); err != nil {
+		t.Errorf("Expected the default board never to be missing, got %v", err)
+	}
+
+	board, err := s.CreateBoard("work", "")
+	if err != nil {
+		t.Fatalf("Failed to create board: %v", err)
+	}
+	if err := os.WriteFile(board.Path, nil, 0644); err != nil {
+		t.Fatalf("Failed to create board database: %v", err)
+	}
+	if err := s.CheckBoard("work"); err != nil {
+		t.Errorf("Expected the board to exist, got %v", err)
+	}
+
+	// Deleting the file by hand leaves the board selected but missing
+	if err := s.SetCurrentBoard("work"); err != nil {
+		t.Fatalf("Failed to set current board: %v", err)
+	}
+	os.Remove(board.Path)
+	var missing *MissingBoardError
+	if err := s.CheckBoard("work"); !errors.As(err, &missing) || missing.Name != "work" || missing.Path != board.Path {
+		t.Errorf("Expected the board to be reported missing, got %v", err)
+	}
+	if err := s.CheckBoard("work"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a missing board to be ErrNotFound, got %v", err)
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	s := &System{configDir: filepath.Join(t.TempDir(), ".cainban"), defaultBoard: "default"}
+
+	// A board made before boards were named carries the default record
+	legacyPath, _ := s.NewBoardPath("legacy")
+	db, err := storage.New(legacyPath)
+	if err != nil {
+		t.Fatalf("Failed to create board database: %v", err)
+	}
+	db.Close()
+
+	for _, name := range []string{"default", "legacy"} {
+		if err := s.SetDescription(name, "About "+name); err != nil {
+			t.Fatalf("Failed to describe %s: %v", name, err)
+		}
+	}
+	if err := s.SetDescription("nope", "x"); err == nil {
+		t.Error("Expected an error describing a board that does not exist")
+	}
+
+	boards, err := s.ListBoards()
+	if err != nil {
+		t.Fatalf("Failed to list boards: %v", err)
+	}
+	if len(boards) != 2 {
+		t.Fatalf("Expected the default and legacy boards, got %d", len(boards))
+	}
+	for _, b := range boards {
+		if b.Description != "About "+b.Name || b.CreatedAt.IsZero() {
+			t.Errorf("Board %s: expected its own description and creation time, got %q %v", b.Name, b.Description, b.CreatedAt)
+		}
+	}
+}
+
+func TestCreateBoardPersists(t *testing.T) {
+	s := &System{configDir: filepath.Join(t.TempDir(), ".cainban"), defaultBoard: "default"}
+
+	created, err := s.CreateBoard("work", "Client projects")
+	if err != nil {
+		t.Fatalf("CreateBoard() error = %v", err)
+	}
+	if created.CreatedAt.IsZero() {
+		t.Error("Expected the creation time recorded in the database")
+	}
+	if _, err := s.CreateBoard("work", ""); err == nil {
+		t.Error("Expected an error creating a board that exists")
+	}
+
+	db, err := storage.New(created.Path)
+	if err != nil {
+		t.Fatalf("Failed to open board database: %v", err)
+	}
+	if _, err := db.Conn().Exec(`INSERT INTO board_settings (key, value) VALUES ('order', 'priority')`); err != nil {
+		t.Fatalf("Failed to store a setting: %v", err)
+	}
+	db.Close()
+
+	// A fresh system knows nothing but the files on disk
+	fresh := &System{configDir: s.configDir, defaultBoard: "default"}
+	got, err := fresh.GetBoard("work")
+	if err != nil {
+		t.Fatalf("GetBoard() error = %v", err)
+	}
+	if got.Description != "Client projects" || !got.CreatedAt.Equal(created.CreatedAt) || got.Settings["order"] != "priority" {
+		t.Errorf("GetBoard() = %+v, want the description, creation time and settings stored", got)
+	}
+}
+
+func TestBoardNamedByRecord(t *testing.T) {
+	s := &System{configDir: filepath.Join(t.TempDir(), ".cainban"), defaultBoard: "default"}
+
+	created, err := s.CreateBoard("my board", "")
+	if err != nil {
+		t.Fatalf("CreateBoard() error = %v", err)
+	}
+	if filepath.Base(created.Path) != "my_board.db" {
+		t.Errorf("Path = %s, want the name sanitized", created.Path)
+	}
+
+	boards, err := s.ListBoards()
+	if err != nil || len(boards) != 1 || boards[0].Name != "my board" {
+		t.Fatalf("ListBoards() = %v, %v, want the board under its own name", boards, err)
+	}
+	for _, name := range []string{"my board", "my_board"} {
+		if b, err := s.GetBoard(name); err != nil || b.Name != "my board" {
+			t.Errorf("GetBoard(%q) = %v, %v, want my board", name, b, err)
+		}
+	}
+	if err := s.SetDescription("my_board", "Described"); err != nil {
+		t.Fatalf("SetDescription() error = %v", err)
+	}
+	if b, _ := s.GetBoard("my board"); b == nil || b.Name != "my board" || b.Description != "Described" {
+		t.Errorf("Expected describing the board to keep its name, got %+v", b)
+	}
+}
+
+func TestOverrideLeavesCurrentBoard(t *testing.T) {
+	s := &System{configDir: filepath.Join(t.TempDir(), ".cainban"), defaultBoard: "default"}
+	for _, name := range []string{"one", "two"} {
+		if _, err := s.CreateBoard(name, ""); err != nil {
+			t.Fatalf("CreateBoard() error = %v", err)
+		}
+	}
+	if _, err := s.AddRemote("team", "ssh://host/srv/team.db", "", false); err != nil {
+		t.Fatalf("AddRemote() error = %v", err)
+	}
+	if err := s.SetCurrentBoard("one"); err != nil {
+		t.Fatalf("SetCurrentBoard() error = %v", err)
+	}
+
+	// Each change is made with the board it changes chosen for the process
+	s.override = "two"
+	if _, err := s.RenameBoard("two", "three"); err != nil {
+		t.Fatalf("RenameBoard() error = %v", err)
+	}
+	s.override = "three"
+	if _, err := s.DeleteBoard("three"); err != nil {
+		t.Fatalf("DeleteBoard() error = %v", err)
+	}
+	s.override = "team"
+	if err := s.RemoveRemote("team"); err != nil {
+		t.Fatalf("RemoveRemote() error = %v", err)
+	}
+
+	if saved, _ := s.savedBoard(); saved != "one" {
+		t.Errorf("Current board = %q, want one left alone under an override", saved)
+	}
+}
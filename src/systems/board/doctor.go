@@ -0,0 +1,246 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/sandbox"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// ProblemKind classifies what Diagnose found
+type ProblemKind string
+
+const (
+	// ProblemMissingCurrent is a selected board whose database is gone
+	ProblemMissingCurrent ProblemKind = "missing-current"
+	// ProblemUnreachable is a board database named so that no board name
+	// leads to it, e.g. a file copied in by hand
+	ProblemUnreachable ProblemKind = "unreachable"
+	// ProblemForeign is a .db file that is not a board database at all
+	ProblemForeign ProblemKind = "foreign"
+	// ProblemNameMismatch is a database recording another board's name,
+	// typically a copy of that board's file
+	ProblemNameMismatch ProblemKind = "name-mismatch"
+	// ProblemStale is a WAL, shared-memory, journal or sandbox file whose
+	// board database is gone
+	ProblemStale ProblemKind = "stale"
+)
+
+// Problem is one thing Diagnose found wrong with the board files
+type Problem struct {
+	Kind    ProblemKind `json:"kind"`
+	Path    string      `json:"path"`
+	Board   string      `json:"board,omitempty"`
+	Message string      `json:"message"`
+	// Fix describes what Fix does about it, empty when it takes a person
+	// to decide
+	Fix string `json:"fix,omitempty"`
+
+	// target is the new path or board name Fix uses
+	target string
+}
+
+// Diagnose checks the global board directory: the current board exists,
+// every database in it is a board reachable by name and recording that
+// name, and no files are left behind by databases that are gone. It only
+// reads; Fix changes things.
+func (s *System) Diagnose() ([]Problem, error) {
+	var problems []Problem
+
+	current, err := s.GetCurrentBoard()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckBoard(current); err != nil {
+		p := Problem{Kind: ProblemMissingCurrent, Path: s.GetBoardPath(current), Board: current, Message: err.Error()}
+		// A board chosen with an override is not switched away from
+		if current != s.defaultBoard && s.override == "" {
+			p.Fix = fmt.Sprintf("switch back to the default board '%s'", s.defaultBoard)
+		}
+		problems = append(problems, p)
+	}
+
+	boardsDir := filepath.Join(s.configDir, "boards")
+	files, err := s.boardFiles(boardsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range files {
+		base := strings.TrimSuffix(filepath.Base(path), ".db")
+
+		if sandbox.IsSandbox(path) {
+			owner := strings.TrimSuffix(path, ".sandbox.db") + ".db"
+			if !exists(owner) {
+				problems = append(problems, Problem{
+					Kind: ProblemStale, Path: path,
+					Message: "sandbox of a board that no longer exists",
+					Fix:     "delete it",
+				})
+			}
+			continue
+		}
+
+		record, err := storage.ReadBoard(path)
+		if err != nil {
+			p := Problem{Kind: ProblemForeign, Path: path, Message: err.Error()}
+			if errors.Is(err, storage.ErrNotBoard) {
+				p.Message = "not a cainban board database; move it out of the boards directory"
+			}
+			problems = append(problems, p)
+			continue
+		}
+
+		// The default board lives outside the boards directory and keeps
+		// the name every new database starts with
+		boardName, want := base, base
+		if filepath.Dir(path) != boardsDir {
+			boardName, want = "default", storage.DefaultBoardName
+		} else if safe := sanitizeBoardName(base); safe != base {
+			p := Problem{
+				Kind: ProblemUnreachable, Path: path, Board: base,
+				Message: "no board name leads to this file",
+				target:  filepath.Join(boardsDir, safe+".db"),
+			}
+			if exists(p.target) {
+				p.Message += fmt.Sprintf(", and board '%s' is taken", safe)
+			} else {
+				p.Fix = fmt.Sprintf("adopt it as board '%s'", safe)
+			}
+			problems = append(problems, p)
+			continue
+		}
+
+		// Board names are recorded as typed, files named after them sanitized
+		name := record.Name
+		if sanitizeBoardName(name) != want && name != storage.DefaultBoardName {
+			problems = append(problems, Problem{
+				Kind: ProblemNameMismatch, Path: path, Board: boardName,
+				Message: fmt.Sprintf("the database says it holds board '%s', likely a copied file", name),
+				Fix:     fmt.Sprintf("adopt it as board '%s'", boardName),
+				target:  want,
+			})
+		}
+	}
+
+	for _, dir := range []string{s.configDir, boardsDir} {
+		stale, err := staleFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range stale {
+			problems = append(problems, Problem{
+				Kind: ProblemStale, Path: path,
+				Message: "left behind by a database that no longer exists",
+				Fix:     "delete it",
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+// Fix repairs a problem found by Diagnose, if it can be fixed without a
+// person deciding what the file is
+func (s *System) Fix(p Problem) error {
+	switch {
+	case p.Fix == "":
+		return fmt.Errorf("%s needs fixing by hand", p.Path)
+
+	case p.Kind == ProblemMissingCurrent:
+		return s.SetCurrentBoard("")
+
+	case p.Kind == ProblemUnreachable:
+		if exists(p.target) {
+			return fmt.Errorf("%s already exists", p.target)
+		}
+		// SQLite's companion files and the rules file move with it
+		for _, suffix := range []string{"-wal", "-shm"} {
+			if exists(p.Path + suffix) {
+				if err := os.Rename(p.Path+suffix, p.target+suffix); err != nil {
+					return err
+				}
+			}
+		}
+		if rules := automation.RulesPath(p.Path); exists(rules) {
+			if err := os.Rename(rules, automation.RulesPath(p.target)); err != nil {
+				return err
+			}
+		}
+		if err := os.Rename(p.Path, p.target); err != nil {
+			return err
+		}
+		return nameBoard(p.target, strings.TrimSuffix(filepath.Base(p.target), ".db"))
+
+	case p.Kind == ProblemNameMismatch:
+		return nameBoard(p.Path, p.target)
+
+	case p.Kind == ProblemStale:
+		return os.Remove(p.Path)
+	}
+	return fmt.Errorf("unknown problem %q", p.Kind)
+}
+
+// nameBoard records name in the board database at path
+func nameBoard(path, name string) error {
+	db, err := storage.New(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.SetBoardName(name)
+}
+
+// boardFiles lists the default board database and the .db files in the
+// boards directory
+func (s *System) boardFiles(boardsDir string) ([]string, error) {
+	var files []string
+	if path := s.GetBoardPath("default"); exists(path) {
+		files = append(files, path)
+	}
+
+	entries, err := os.ReadDir(boardsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read boards directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".db") {
+			files = append(files, filepath.Join(boardsDir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// staleFiles lists the SQLite companion files in dir whose database is gone
+func staleFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	var stale []string
+	for _, entry := range entries {
+		for _, suffix := range []string{"-wal", "-shm", "-journal"} {
+			name := entry.Name()
+			if strings.HasSuffix(name, ".db"+suffix) && !exists(filepath.Join(dir, strings.TrimSuffix(name, suffix))) {
+				stale = append(stale, filepath.Join(dir, name))
+			}
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
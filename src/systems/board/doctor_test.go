@@ -0,0 +1,84 @@
+package board
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestDiagnoseAndFix(t *testing.T) {
+	s := &System{configDir: filepath.Join(t.TempDir(), ".cainban"), defaultBoard: "default"}
+	boardsDir := filepath.Join(s.configDir, "boards")
+
+	// makeBoard creates a board database at path holding board name
+	makeBoard := func(path, name string) {
+		t.Helper()
+		db, err := storage.New(path)
+		if err != nil {
+			t.Fatalf("Failed to create %s: %v", path, err)
+		}
+		defer db.Close()
+		if err := db.SetBoardName(name); err != nil {
+			t.Fatalf("Failed to name board: %v", err)
+		}
+	}
+
+	if problems, err := s.Diagnose(); err != nil || len(problems) != 0 {
+		t.Fatalf("Expected no problems without any boards, got %v (%v)", problems, err)
+	}
+
+	makeBoard(s.GetBoardPath("default"), storage.DefaultBoardName)
+	makeBoard(filepath.Join(boardsDir, "api.db"), "api")
+	makeBoard(filepath.Join(boardsDir, "api-copy.db"), "api")
+	makeBoard(filepath.Join(boardsDir, "Side Project.db"), "Side Project")
+	os.WriteFile(filepath.Join(boardsDir, "notes.db"), []byte("plain text"), 0644)
+	os.WriteFile(filepath.Join(boardsDir, "gone.db-wal"), nil, 0644)
+	os.WriteFile(filepath.Join(boardsDir, "gone.sandbox.db"), nil, 0644)
+	if err := s.SetCurrentBoard("deleted"); err != nil {
+		t.Fatalf("Failed to set current board: %v", err)
+	}
+
+	problems, err := s.Diagnose()
+	if err != nil {
+		t.Fatalf("Diagnose failed: %v", err)
+	}
+	want := map[string]ProblemKind{
+		s.GetBoardPath("deleted"):                   ProblemMissingCurrent,
+		filepath.Join(boardsDir, "api-copy.db"):     ProblemNameMismatch,
+		filepath.Join(boardsDir, "Side Project.db"): ProblemUnreachable,
+		filepath.Join(boardsDir, "notes.db"):        ProblemForeign,
+		filepath.Join(boardsDir, "gone.db-wal"):     ProblemStale,
+		filepath.Join(boardsDir, "gone.sandbox.db"): ProblemStale,
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Expected %d problems, got %d: %+v", len(want), len(problems), problems)
+	}
+	for _, p := range problems {
+		if want[p.Path] != p.Kind {
+			t.Errorf("%s: expected %q, got %q (%s)", p.Path, want[p.Path], p.Kind, p.Message)
+		}
+		if err := s.Fix(p); (err != nil) != (p.Kind == ProblemForeign) {
+			t.Errorf("%s: unexpected fix result %v", p.Path, err)
+		}
+	}
+
+	// Only the file that takes a person to judge is left
+	problems, err = s.Diagnose()
+	if err != nil || len(problems) != 1 || problems[0].Kind != ProblemForeign {
+		t.Fatalf("Expected only the foreign file to remain, got %+v (%v)", problems, err)
+	}
+	if current, _ := s.GetCurrentBoard(); current != "default" {
+		t.Errorf("Expected to be back on the default board, got %s", current)
+	}
+	if record, err := storage.ReadBoard(filepath.Join(boardsDir, "Side_Project.db")); err != nil || record.Name != "Side_Project" {
+		t.Errorf("Expected the unreachable board to be adopted, got %+v (%v)", record, err)
+	}
+	if record, err := storage.ReadBoard(filepath.Join(boardsDir, "api-copy.db")); err != nil || record.Name != "api-copy" {
+		t.Errorf("Expected the copy to be renamed, got %+v (%v)", record, err)
+	}
+	if _, err := os.Stat(filepath.Join(boardsDir, "notes.db")); err != nil {
+		t.Errorf("Expected the foreign file to be left alone: %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+package board
+
+import "github.com/hmain/cainban/src/systems/storage"
+
+// The kinds of failure the board system reports, shared with the other
+// systems so callers can branch on them with errors.Is wherever they came
+// from
+var (
+	ErrNotFound     = storage.ErrNotFound
+	ErrAmbiguous    = storage.ErrAmbiguous
+	ErrInvalidInput = storage.ErrInvalidInput
+)
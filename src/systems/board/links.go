@@ -0,0 +1,89 @@
+package board
+
+import (
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// LinkAcrossBoards links a task on one board to a task on another. Both
+// boards record the link, so `links` shows it from either side.
+func (s *System) LinkAcrossBoards(fromBoard string, fromTaskID int, toBoard string, toTaskID int, linkType task.LinkType) error {
+	from, to, err := s.linkBoards(fromBoard, toBoard)
+	if err != nil {
+		return err
+	}
+
+	if from.Name == to.Name {
+		return s.withBoard(from, func(_ *Board, taskSystem *task.System) error {
+			return taskSystem.LinkTasks(fromTaskID, toTaskID, linkType)
+		})
+	}
+
+	// Check the target first so a bad ID leaves no half-made link behind
+	err = s.withBoard(to, func(_ *Board, taskSystem *task.System) error {
+		_, err := taskSystem.GetByID(toTaskID)
+		return err
+	})
+	if err != nil {
+		return storage.Errorf(ErrNotFound, "to task not found on board '%s': %w", to.Name, err)
+	}
+
+	err = s.withBoard(from, func(_ *Board, taskSystem *task.System) error {
+		return taskSystem.AddBoardLink(fromTaskID, to.Name, toTaskID, linkType, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = s.withBoard(to, func(_ *Board, taskSystem *task.System) error {
+		return taskSystem.AddBoardLink(toTaskID, from.Name, fromTaskID, linkType, true)
+	})
+	if err != nil {
+		_ = s.withBoard(from, func(_ *Board, taskSystem *task.System) error {
+			return taskSystem.RemoveBoardLink(fromTaskID, to.Name, toTaskID, linkType, false)
+		})
+		return err
+	}
+
+	return nil
+}
+
+// UnlinkAcrossBoards removes a link made with LinkAcrossBoards from both
+// boards
+func (s *System) UnlinkAcrossBoards(fromBoard string, fromTaskID int, toBoard string, toTaskID int, linkType task.LinkType) error {
+	from, to, err := s.linkBoards(fromBoard, toBoard)
+	if err != nil {
+		return err
+	}
+
+	if from.Name == to.Name {
+		return s.withBoard(from, func(_ *Board, taskSystem *task.System) error {
+			return taskSystem.UnlinkTasks(fromTaskID, toTaskID, linkType)
+		})
+	}
+
+	err = s.withBoard(from, func(_ *Board, taskSystem *task.System) error {
+		return taskSystem.RemoveBoardLink(fromTaskID, to.Name, toTaskID, linkType, false)
+	})
+	if err != nil {
+		return err
+	}
+
+	// The mirror may already be gone, e.g. if the target task was deleted
+	_ = s.withBoard(to, func(_ *Board, taskSystem *task.System) error {
+		return taskSystem.RemoveBoardLink(toTaskID, from.Name, fromTaskID, linkType, true)
+	})
+	return nil
+}
+
+func (s *System) linkBoards(fromBoard, toBoard string) (*Board, *Board, error) {
+	from, err := s.GetBoard(fromBoard)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := s.GetBoard(toBoard)
+	if err != nil {
+		return nil, nil, err
+	}
+	return from, to, nil
+}
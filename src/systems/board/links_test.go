@@ -0,0 +1,72 @@
+package board
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestLinkAcrossBoards(t *testing.T) {
+	configDir := t.TempDir()
+	s := &System{configDir: configDir, defaultBoard: "default"}
+	if err := os.MkdirAll(filepath.Join(configDir, "boards"), 0755); err != nil {
+		t.Fatalf("Failed to create boards directory: %v", err)
+	}
+
+	for _, name := range []string{"api", "frontend"} {
+		db, err := storage.New(s.GetBoardPath(name))
+		if err != nil {
+			t.Fatalf("Failed to create board %s: %v", name, err)
+		}
+		if _, err := task.New(db.Conn()).Create(1, "Task on "+name, ""); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		db.Close()
+	}
+
+	linksOn := func(name string) []task.TaskLink {
+		var links []task.TaskLink
+		b, err := s.GetBoard(name)
+		if err != nil {
+			t.Fatalf("Failed to get board %s: %v", name, err)
+		}
+		err = s.withBoard(b, func(_ *Board, taskSystem *task.System) error {
+			links, err = taskSystem.GetTaskLinks(1)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Failed to get links on %s: %v", name, err)
+		}
+		return links
+	}
+
+	if err := s.LinkAcrossBoards("api", 1, "frontend", 1, task.LinkTypeBlocks); err != nil {
+		t.Fatalf("Failed to link across boards: %v", err)
+	}
+
+	api := linksOn("api")
+	if len(api) != 1 || api[0].FromBoard != "" || api[0].ToBoard != "frontend" || api[0].ToTaskID != 1 {
+		t.Errorf("Unexpected links on api: %+v", api)
+	}
+	frontend := linksOn("frontend")
+	if len(frontend) != 1 || frontend[0].FromBoard != "api" || frontend[0].ToBoard != "" || frontend[0].LinkType != task.LinkTypeBlocks {
+		t.Errorf("Unexpected links on frontend: %+v", frontend)
+	}
+
+	if err := s.LinkAcrossBoards("api", 1, "frontend", 99, task.LinkTypeBlocks); err == nil {
+		t.Error("Expected error linking to a missing task")
+	}
+	if err := s.LinkAcrossBoards("api", 1, "mobile", 1, task.LinkTypeBlocks); err == nil {
+		t.Error("Expected error linking to a missing board")
+	}
+
+	if err := s.UnlinkAcrossBoards("api", 1, "frontend", 1, task.LinkTypeBlocks); err != nil {
+		t.Fatalf("Failed to unlink across boards: %v", err)
+	}
+	if len(linksOn("api")) != 0 || len(linksOn("frontend")) != 0 {
+		t.Error("Expected the link to be removed from both boards")
+	}
+}
@@ -0,0 +1,142 @@
+package board
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hmain/cainban/src/systems/remote"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// RemoteBoard is a board kept on another machine. Commands work on a local
+// copy at the board's usual path, fetched before and sent back after them.
+type RemoteBoard struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Token is sent to boards served over HTTP; a "secret:<name>"
+	// reference, or the token itself
+	Token string `json:"token,omitempty"`
+	// Trusted boards run their automations and rules that run commands or
+	// post to URLs on this machine; others skip them
+	Trusted bool `json:"trusted,omitempty"`
+}
+
+// remotesPath returns the file the remote boards are registered in
+func (s *System) remotesPath() string {
+	return filepath.Join(s.configDir, "remotes.json")
+}
+
+// RemoteBoards returns the remote boards, by name
+func (s *System) RemoteBoards() ([]RemoteBoard, error) {
+	data, err := os.ReadFile(s.remotesPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read remote boards: %w", err)
+	}
+	var remotes []RemoteBoard
+	if err := json.Unmarshal(data, &remotes); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.remotesPath(), err)
+	}
+	return remotes, nil
+}
+
+// Remote returns the remote board named name, or nil if that board is kept
+// on this machine
+func (s *System) Remote(name string) (*RemoteBoard, error) {
+	remotes, err := s.RemoteBoards()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range remotes {
+		if r.Name == name {
+			return &r, nil
+		}
+	}
+	return nil, nil
+}
+
+// AddRemote registers the board at url as a board named name. Its local
+// copy is fetched on first use.
+func (s *System) AddRemote(name, url, token string, trusted bool) (*RemoteBoard, error) {
+	if name == "" || name == "default" || sanitizeBoardName(name) != name {
+		return nil, storage.Errorf(ErrInvalidInput, "invalid remote board name '%s': use letters, digits, - and _", name)
+	}
+	if s.localDir != "" && name == s.localBoardName() {
+		return nil, storage.Errorf(ErrInvalidInput, "board '%s' is repo-local", name)
+	}
+	if _, err := remote.Open(url, token); err != nil {
+		return nil, err
+	}
+
+	remotes, err := s.RemoteBoards()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range remotes {
+		if r.Name == name {
+			return nil, fmt.Errorf("board '%s' is a remote board already, at %s", name, r.URL)
+		}
+	}
+	if _, err := os.Stat(s.GetBoardPath(name)); err == nil {
+		return nil, fmt.Errorf("board '%s' already exists", name)
+	}
+
+	added := RemoteBoard{Name: name, URL: url, Token: token, Trusted: trusted}
+	remotes = append(remotes, added)
+	if err := s.saveRemotes(remotes); err != nil {
+		return nil, err
+	}
+	return &added, nil
+}
+
+// RemoveRemote unregisters a remote board and removes its local copy. The
+// board on the remote is left alone.
+func (s *System) RemoveRemote(name string) error {
+	remotes, err := s.RemoteBoards()
+	if err != nil {
+		return err
+	}
+	kept := remotes[:0]
+	for _, r := range remotes {
+		if r.Name != name {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == len(remotes) {
+		return storage.Errorf(ErrNotFound, "board '%s' is not a remote board", name)
+	}
+	if err := s.saveRemotes(kept); err != nil {
+		return err
+	}
+
+	for _, path := range boardFileSet(s.GetBoardPath(name)) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove the local copy: %w", err)
+		}
+	}
+	if saved, _ := s.savedBoard(); saved == name {
+		_ = s.SetCurrentBoard("default")
+	}
+	return nil
+}
+
+func (s *System) saveRemotes(remotes []RemoteBoard) error {
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].Name < remotes[j].Name })
+	data, err := json.MarshalIndent(remotes, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.configDir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	// Tokens may be in it
+	if err := os.WriteFile(s.remotesPath(), append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to save remote boards: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package board
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestRemoteBoards(t *testing.T) {
+	s := &System{configDir: t.TempDir(), defaultBoard: "default"}
+
+	if _, err := s.AddRemote("team", "ftp://host/team.db", "", false); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected an unsupported URL to be refused, got %v", err)
+	}
+	if _, err := s.AddRemote("team", "ssh://host/srv/team.db", "", false); err != nil {
+		t.Fatalf("AddRemote() error = %v", err)
+	}
+	if _, err := s.AddRemote("team", "https://host/", "", false); err == nil {
+		t.Error("Expected a second remote board of the same name to be refused")
+	}
+
+	// Listed, and not missing, before it was ever fetched
+	boards, err := s.ListBoards()
+	if err != nil || len(boards) != 1 || boards[0].Remote != "ssh://host/srv/team.db" {
+		t.Fatalf("Expected the remote board listed, got %v, %v", boards, err)
+	}
+	if err := s.CheckBoard("team"); err != nil {
+		t.Errorf("CheckBoard() error = %v", err)
+	}
+
+	// Removing it removes the local copy and leaves other boards alone
+	copyPath := s.GetBoardPath("team")
+	db, err := storage.New(copyPath)
+	if err != nil {
+		t.Fatalf("Failed to create local copy: %v", err)
+	}
+	db.Close()
+	if err := s.RemoveRemote("team"); err != nil {
+		t.Fatalf("RemoveRemote() error = %v", err)
+	}
+	if _, err := os.Stat(copyPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the local copy removed, got %v", err)
+	}
+	if r, err := s.Remote("team"); err != nil || r != nil {
+		t.Errorf("Expected no remote board left, got %v, %v", r, err)
+	}
+	if err := s.RemoveRemote("team"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// A board kept here cannot be shadowed by a remote one
+	if err := os.MkdirAll(filepath.Join(s.configDir, "boards"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(s.GetBoardPath("mine"), nil, 0644)
+	if _, err := s.AddRemote("mine", "https://host/", "", false); err == nil {
+		t.Error("Expected a remote board named after a local one to be refused")
+	}
+}
@@ -0,0 +1,139 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// RenameBoard gives a board a new name: its files move to the new name's
+// path, its record and backups take the name, the current board follows it
+// and links from other boards point to it under the new name. The default,
+// repo-local and remote boards keep their names.
+//
+// Once its files have moved the board is renamed, and it is returned even
+// if what follows it fails; the error then tells what did not follow.
+func (s *System) RenameBoard(oldName, newName string) (*Board, error) {
+	newName = strings.TrimSpace(newName)
+	switch {
+	case newName == "":
+		return nil, storage.Errorf(ErrInvalidInput, "board name cannot be empty")
+	case oldName == "default" || newName == "default":
+		return nil, storage.Errorf(ErrInvalidInput, "the default board cannot be renamed, nor another board to it")
+	case s.localDir != "" && (oldName == s.localBoardName() || newName == s.localBoardName()):
+		return nil, storage.Errorf(ErrInvalidInput, "board '%s' is repo-local; it is named after its repository", s.localBoardName())
+	}
+	board, err := s.GetBoard(oldName)
+	if err != nil {
+		return nil, err
+	}
+	if board.Remote != "" {
+		return nil, storage.Errorf(ErrInvalidInput, "board '%s' is remote; remove it and add it again under the new name", oldName)
+	}
+	oldName = board.Name
+	if newName == oldName {
+		return nil, storage.Errorf(ErrInvalidInput, "board '%s' already has that name", oldName)
+	}
+	// The new name may be stored where the board already is, e.g. my_board
+	// for "my board"; then only its record changes
+	newPath := s.GetBoardPath(newName)
+	if existing, err := s.GetBoard(newName); err == nil && existing.Path != board.Path {
+		return nil, storage.Errorf(ErrInvalidInput, "board '%s' already exists", existing.Name)
+	}
+	if newPath != board.Path {
+		if _, err := os.Stat(newPath); err == nil {
+			return nil, storage.Errorf(ErrInvalidInput, "board '%s' would be stored at %s, which is taken", newName, newPath)
+		}
+		if err := renameFiles(boardFileSet(board.Path), boardFileSet(newPath)); err != nil {
+			return nil, fmt.Errorf("failed to rename board: %w", err)
+		}
+	}
+
+	renamed := &Board{Name: newName, Path: newPath}
+	var errs []error
+	db, err := storage.New(newPath)
+	if err == nil {
+		err = db.SetBoardName(newName)
+		db.Close()
+	}
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to record the new name: %w", err))
+	}
+	readRecord(renamed)
+
+	if saved, _ := s.savedBoard(); saved != "" && s.GetBoardPath(saved) == board.Path {
+		if err := s.SetCurrentBoard(newName); err != nil {
+			errs = append(errs, fmt.Errorf("failed to make it the current board: %w", err))
+		}
+	}
+	if newPath != board.Path {
+		if err := s.renameBackups(oldName, newName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// Other boards refer to the board by name in their links to it. Remote
+	// boards are left alone; their links are their owner's to change.
+	boards, err := s.ListBoards()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, b := range boards {
+		if b.Remote != "" {
+			continue
+		}
+		err := s.withBoard(b, func(_ *Board, taskSystem *task.System) error {
+			_, err := taskSystem.RenameBoardLinks(oldName, newName)
+			return err
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to update the links of board '%s' to it: %w", b.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return renamed, fmt.Errorf("renamed the board, but %w", errors.Join(errs...))
+	}
+	return renamed, nil
+}
+
+// renameBackups gives the timestamped backups of a board its new name, so
+// that restore finds them
+func (s *System) renameBackups(oldName, newName string) error {
+	backups, err := s.Backups(oldName)
+	if err != nil {
+		return err
+	}
+	for _, backup := range backups {
+		stamp := strings.TrimPrefix(filepath.Base(backup), sanitizeBoardName(oldName))
+		if err := os.Rename(backup, filepath.Join(s.BackupDir(), sanitizeBoardName(newName)+stamp)); err != nil {
+			return fmt.Errorf("failed to rename backup: %w", err)
+		}
+	}
+	return nil
+}
+
+// renameFiles renames those of the files in from that exist to the path at
+// the same index in to. If one cannot be renamed, those already renamed are
+// put back.
+func renameFiles(from, to []string) error {
+	var renamed [][2]string
+	for i := range from {
+		if _, err := os.Stat(from[i]); os.IsNotExist(err) {
+			continue
+		}
+		if err := os.Rename(from[i], to[i]); err != nil {
+			for j := len(renamed) - 1; j >= 0; j-- {
+				os.Rename(renamed[j][1], renamed[j][0])
+			}
+			return err
+		}
+		renamed = append(renamed, [2]string{from[i], to[i]})
+	}
+	return nil
+}
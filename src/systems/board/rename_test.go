@@ -0,0 +1,121 @@
+package board
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestRenameBoard(t *testing.T) {
+	s := &System{configDir: t.TempDir(), defaultBoard: "default"}
+
+	api, err := s.CreateBoard("api", "Backend services")
+	if err != nil {
+		t.Fatalf("CreateBoard() error = %v", err)
+	}
+	if _, err := s.CreateBoard("web", ""); err != nil {
+		t.Fatalf("CreateBoard() error = %v", err)
+	}
+	if err := os.WriteFile(automation.RulesPath(api.Path), []byte("[]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backup := s.BackupPath("api", time.Now())
+	os.MkdirAll(s.BackupDir(), 0755)
+	if err := os.WriteFile(backup, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A task on web links to one on api
+	var fromID, toID int
+	s.withBoard(api, func(_ *Board, tasks *task.System) error {
+		created, err := tasks.Create(1, "Endpoint", "")
+		toID = created.ID
+		return err
+	})
+	web, _ := s.GetBoard("web")
+	s.withBoard(web, func(_ *Board, tasks *task.System) error {
+		created, err := tasks.Create(1, "Page", "")
+		fromID = created.ID
+		return err
+	})
+	if err := s.LinkAcrossBoards("web", fromID, "api", toID, task.LinkTypeBlocks); err != nil {
+		t.Fatalf("LinkAcrossBoards() error = %v", err)
+	}
+	s.SetCurrentBoard("api")
+
+	for _, name := range []string{"web", "", "default"} {
+		if _, err := s.RenameBoard("api", name); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("RenameBoard(api, %q) error = %v, want ErrInvalidInput", name, err)
+		}
+	}
+	if _, err := s.RenameBoard("nope", "other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected renaming a missing board to fail, got %v", err)
+	}
+
+	renamed, err := s.RenameBoard("api", "backend")
+	if err != nil {
+		t.Fatalf("RenameBoard() error = %v", err)
+	}
+	if renamed.Description != "Backend services" || !renamed.CreatedAt.Equal(api.CreatedAt) {
+		t.Errorf("Expected the board to keep its record, got %+v", renamed)
+	}
+	if _, err := s.GetBoard("api"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the old name gone, got %v", err)
+	}
+	if _, err := os.Stat(automation.RulesPath(renamed.Path)); err != nil {
+		t.Errorf("Expected the rules file to move with the board: %v", err)
+	}
+	if backups, _ := s.Backups("backend"); len(backups) != 1 {
+		t.Errorf("Expected the backup to take the new name, got %v", backups)
+	}
+	if current, _ := s.GetCurrentBoard(); current != "backend" {
+		t.Errorf("Current board = %q, want backend", current)
+	}
+
+	web, _ = s.GetBoard("web")
+	s.withBoard(web, func(_ *Board, tasks *task.System) error {
+		links, err := tasks.GetTaskLinks(fromID)
+		if err != nil || len(links) != 1 || links[0].ToBoard != "backend" {
+			t.Errorf("Expected the link to point to backend, got %+v, %v", links, err)
+		}
+		return nil
+	})
+}
+
+func TestRenameBoardSanitizedName(t *testing.T) {
+	s := &System{configDir: t.TempDir(), defaultBoard: "default"}
+	for _, name := range []string{"api", "web app"} {
+		if _, err := s.CreateBoard(name, ""); err != nil {
+			t.Fatalf("CreateBoard() error = %v", err)
+		}
+	}
+
+	if _, err := s.RenameBoard("api", "web_app"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected a name stored where another board is to be refused, got %v", err)
+	}
+
+	renamed, err := s.RenameBoard("api", "back end")
+	if err != nil {
+		t.Fatalf("RenameBoard() error = %v", err)
+	}
+	if renamed.Name != "back end" || filepath.Base(renamed.Path) != "back_end.db" {
+		t.Errorf("RenameBoard() = %+v, want back end stored as back_end.db", renamed)
+	}
+	if b, err := s.GetBoard("back end"); err != nil || b.Path != renamed.Path {
+		t.Errorf("GetBoard(back end) = %v, %v", b, err)
+	}
+
+	// A name stored where the board already is only changes its record
+	renamed, err = s.RenameBoard("back end", "back_end")
+	if err != nil {
+		t.Fatalf("RenameBoard() error = %v", err)
+	}
+	if b, err := s.GetBoard("back_end"); err != nil || b.Name != "back_end" || b.Path != renamed.Path {
+		t.Errorf("GetBoard(back_end) = %v, %v, want the board renamed in place", b, err)
+	}
+}
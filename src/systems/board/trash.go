@@ -0,0 +1,169 @@
+package board
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/sandbox"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// TrashedBoard is a deleted board that RestoreBoard can bring back
+type TrashedBoard struct {
+	// Name is the name the board's record keeps, e.g. "my board" for the
+	// files of my_board
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// Path is the directory in the trash holding the board's files
+	Path string `json:"path"`
+}
+
+// TrashDir returns the directory deleted boards are moved to
+func (s *System) TrashDir() string {
+	return filepath.Join(s.configDir, "trash")
+}
+
+// trashBoard moves the files of the board stored at path into a directory
+// of their own in the trash, named after the board and the time. The files
+// keep their names, so SQLite still pairs the database with its WAL.
+func (s *System) trashBoard(name, path string) (*TrashedBoard, error) {
+	deletedAt := time.Now()
+	if record, err := storage.ReadBoard(path); err == nil && sanitizeBoardName(record.Name) == sanitizeBoardName(name) {
+		name = record.Name
+	}
+	trashed := &TrashedBoard{
+		Name:      name,
+		DeletedAt: deletedAt,
+		Path:      filepath.Join(s.TrashDir(), sanitizeBoardName(name)+"-"+deletedAt.Format(backupLayout)),
+	}
+	if err := os.MkdirAll(trashed.Path, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	if err := moveFiles(boardFileSet(path), trashed.Path); err != nil {
+		os.Remove(trashed.Path)
+		return nil, fmt.Errorf("failed to move board to the trash: %w", err)
+	}
+	return trashed, nil
+}
+
+// TrashedBoards returns the boards in the trash, most recently deleted first
+func (s *System) TrashedBoards() ([]TrashedBoard, error) {
+	entries, err := os.ReadDir(s.TrashDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var trashed []TrashedBoard
+	for _, entry := range entries {
+		// Board names may contain dashes, the timestamp is after the last
+		// but one
+		dir := entry.Name()
+		cut := strings.LastIndex(dir, "-")
+		if !entry.IsDir() || cut < 0 {
+			continue
+		}
+		if cut = strings.LastIndex(dir[:cut], "-"); cut < 0 {
+			continue
+		}
+		deletedAt, err := time.ParseInLocation(backupLayout, dir[cut+1:], time.Local)
+		if err != nil {
+			continue
+		}
+		// The directory is named after the board sanitized, as its files
+		// are; the record keeps the name as given
+		name, path := dir[:cut], filepath.Join(s.TrashDir(), dir)
+		if record, err := storage.ReadBoard(filepath.Join(path, name+".db")); err == nil && sanitizeBoardName(record.Name) == name {
+			name = record.Name
+		}
+		trashed = append(trashed, TrashedBoard{Name: name, DeletedAt: deletedAt, Path: path})
+	}
+
+	sort.Slice(trashed, func(i, j int) bool { return trashed[i].DeletedAt.After(trashed[j].DeletedAt) })
+	return trashed, nil
+}
+
+// RestoreBoard brings the most recently deleted board of a name back from
+// the trash, by its name or the name of its files. It refuses while a board
+// of that name exists.
+func (s *System) RestoreBoard(name string) (*TrashedBoard, error) {
+	trashed, err := s.TrashedBoards()
+	if err != nil {
+		return nil, err
+	}
+
+	var found *TrashedBoard
+	for i := range trashed {
+		if sanitizeBoardName(trashed[i].Name) == sanitizeBoardName(name) {
+			found = &trashed[i]
+			break
+		}
+	}
+	if found == nil {
+		return nil, storage.Errorf(ErrNotFound, "board '%s' is not in the trash", name)
+	}
+
+	boardPath := s.GetBoardPath(name)
+	if _, err := os.Stat(boardPath); err == nil {
+		return nil, storage.Errorf(ErrInvalidInput, "board '%s' exists; delete or rename it before restoring the one in the trash", name)
+	}
+
+	entries, err := os.ReadDir(found.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trashed board: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		files = append(files, filepath.Join(found.Path, entry.Name()))
+	}
+	if err := moveFiles(files, filepath.Dir(boardPath)); err != nil {
+		return nil, fmt.Errorf("failed to restore board: %w", err)
+	}
+	if err := os.Remove(found.Path); err != nil {
+		return nil, fmt.Errorf("failed to clean up trash: %w", err)
+	}
+	return found, nil
+}
+
+// boardFileSet returns the files that make up the board stored at path: the
+// database with its WAL, shared-memory and journal files, its rules file
+// and its sandbox
+func boardFileSet(path string) []string {
+	var files []string
+	for _, db := range []string{path, sandbox.Path(path)} {
+		files = append(files, db, db+"-wal", db+"-shm", db+"-journal")
+	}
+	return append(files, automation.RulesPath(path))
+}
+
+// moveFiles moves those of the files that exist into dir. If one cannot be
+// moved, those already moved are put back.
+func moveFiles(files []string, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	var moved [][2]string
+	for _, from := range files {
+		if _, err := os.Stat(from); os.IsNotExist(err) {
+			continue
+		}
+		to := filepath.Join(dir, filepath.Base(from))
+		if err := os.Rename(from, to); err != nil {
+			for i := len(moved) - 1; i >= 0; i-- {
+				os.Rename(moved[i][1], moved[i][0])
+			}
+			return err
+		}
+		moved = append(moved, [2]string{from, to})
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package board
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/automation"
+)
+
+func TestDeleteAndRestoreBoard(t *testing.T) {
+	s := &System{configDir: t.TempDir(), defaultBoard: "default"}
+
+	created, err := s.CreateBoard("api-v2", "")
+	if err != nil {
+		t.Fatalf("CreateBoard() error = %v", err)
+	}
+	files := []string{created.Path, created.Path + "-wal", automation.RulesPath(created.Path)}
+	for _, path := range files {
+		if err := os.WriteFile(path, []byte(filepath.Base(path)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SetCurrentBoard("api-v2"); err != nil {
+		t.Fatal(err)
+	}
+
+	trashed, err := s.DeleteBoard("api-v2")
+	if err != nil {
+		t.Fatalf("DeleteBoard() error = %v", err)
+	}
+	for _, path := range files {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be moved away", filepath.Base(path))
+		}
+		if _, err := os.Stat(filepath.Join(trashed.Path, filepath.Base(path))); err != nil {
+			t.Errorf("Expected %s in the trash: %v", filepath.Base(path), err)
+		}
+	}
+	if current, _ := s.GetCurrentBoard(); current != "default" {
+		t.Errorf("Expected deleting the current board to switch to default, got %q", current)
+	}
+
+	list, err := s.TrashedBoards()
+	if err != nil || len(list) != 1 || list[0].Name != "api-v2" || list[0].Path != trashed.Path {
+		t.Fatalf("Expected the board in the trash, got %+v, %v", list, err)
+	}
+
+	// The name is taken while another board of that name exists
+	if err := os.WriteFile(created.Path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RestoreBoard("api-v2"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected restoring over an existing board to be refused, got %v", err)
+	}
+	os.Remove(created.Path)
+
+	if _, err := s.RestoreBoard("api-v2"); err != nil {
+		t.Fatalf("RestoreBoard() error = %v", err)
+	}
+	for _, path := range files {
+		if content, err := os.ReadFile(path); err != nil || string(content) != filepath.Base(path) {
+			t.Errorf("Expected %s back, got %q, %v", filepath.Base(path), content, err)
+		}
+	}
+	if list, _ := s.TrashedBoards(); len(list) != 0 {
+		t.Errorf("Expected the trash to be empty, got %+v", list)
+	}
+
+	if _, err := s.RestoreBoard("api-v2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a board not in the trash to be not found, got %v", err)
+	}
+	if _, err := s.DeleteBoard("default"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected the default board never to be deleted, got %v", err)
+	}
+}
+
+func TestTrashKeepsBoardName(t *testing.T) {
+	s := &System{configDir: t.TempDir(), defaultBoard: "default"}
+	if _, err := s.CreateBoard("my board", ""); err != nil {
+		t.Fatalf("CreateBoard() error = %v", err)
+	}
+
+	trashed, err := s.DeleteBoard("my_board")
+	if err != nil {
+		t.Fatalf("DeleteBoard() error = %v", err)
+	}
+	if trashed.Name != "my board" {
+		t.Errorf("Expected the deleted board by its recorded name, got %q", trashed.Name)
+	}
+	if list, err := s.TrashedBoards(); err != nil || len(list) != 1 || list[0].Name != "my board" {
+		t.Fatalf("Expected the board in the trash by its recorded name, got %+v, %v", list, err)
+	}
+
+	restored, err := s.RestoreBoard("my board")
+	if err != nil {
+		t.Fatalf("RestoreBoard() error = %v", err)
+	}
+	if restored.Name != "my board" {
+		t.Errorf("Expected the restored board by its recorded name, got %q", restored.Name)
+	}
+	if b, err := s.GetBoard("my board"); err != nil || b.Name != "my board" {
+		t.Errorf("Expected the board back, got %+v, %v", b, err)
+	}
+}
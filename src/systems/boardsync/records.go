@@ -0,0 +1,192 @@
+// Package boardsync keeps a board in step across machines through a git
+// repository. Each board is a JSONL file there, one task per line keyed by
+// its hash, which merges line by line; changes made on both sides are
+// settled per task, the one updated last winning.
+package boardsync
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/board"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// File returns where in the repository the board named boardName is kept,
+// named like its database so that no name reaches outside boards/
+func File(boardName string) string {
+	return path.Join("boards", board.FileStem(boardName)+".jsonl")
+}
+
+// Record is a task as kept in the repository. It leaves out what only
+// makes sense on one machine, such as IDs, links and the manual order.
+type Record struct {
+	Hash        string          `json:"hash"`
+	Title       string          `json:"title"`
+	Description string          `json:"description,omitempty"`
+	Status      task.Status     `json:"status"`
+	Priority    int             `json:"priority,omitempty"`
+	Estimate    int             `json:"estimate,omitempty"`
+	Assignee    string          `json:"assignee,omitempty"`
+	Recurrence  task.Recurrence `json:"recurrence,omitempty"`
+	Size        task.Size       `json:"size,omitempty"`
+	Energy      task.Energy     `json:"energy,omitempty"`
+	Contexts    []string        `json:"contexts,omitempty"`
+	DueAt       *time.Time      `json:"due_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	CreatedBy   string          `json:"created_by,omitempty"`
+	UpdatedBy   string          `json:"updated_by,omitempty"`
+}
+
+// FromTask converts a task to the record kept of it
+func FromTask(t *task.Task) Record {
+	r := Record{
+		Hash:        t.Hash,
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		Estimate:    t.Estimate,
+		Assignee:    t.Assignee,
+		Recurrence:  t.Recurrence,
+		Size:        t.Size,
+		Energy:      t.Energy,
+		Contexts:    t.Contexts,
+		CreatedAt:   t.CreatedAt.UTC().Truncate(time.Second),
+		UpdatedAt:   t.UpdatedAt.UTC().Truncate(time.Second),
+		CreatedBy:   t.CreatedBy,
+		UpdatedBy:   t.UpdatedBy,
+	}
+	if t.DueAt != nil {
+		due := t.DueAt.UTC().Truncate(time.Second)
+		r.DueAt = &due
+	}
+	return r
+}
+
+// ShortHash returns the start of the task's hash that is shown for it
+func (r Record) ShortHash() string {
+	return (&task.Task{Hash: r.Hash}).ShortHash()
+}
+
+// sameTask reports whether two records describe the task alike, whenever
+// and by whom each was last updated
+func sameTask(a, b Record) bool {
+	a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	a.UpdatedBy, b.UpdatedBy = "", ""
+	if len(a.Contexts) == 0 && len(b.Contexts) == 0 {
+		a.Contexts, b.Contexts = nil, nil
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// Encode writes records one per line, ordered by hash so that a change to a
+// task changes one line of the file
+func Encode(w io.Writer, records []Record) error {
+	sorted := append([]Record(nil), records...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Hash < sorted[j].Hash })
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	for _, r := range sorted {
+		if err := encoder.Encode(r); err != nil {
+			return fmt.Errorf("failed to encode task %s: %w", r.Hash, err)
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Decode reads the records Encode wrote
+func Decode(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if record.Hash == "" {
+			return nil, fmt.Errorf("line %d: task without a hash", line)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Plan is how a board and the copy of it from the repository merge
+type Plan struct {
+	Create []Record // tasks added elsewhere, to add here
+	Update []Record // tasks changed elsewhere after they were here
+	Delete []Record // tasks deleted elsewhere and left alone here
+}
+
+// Empty reports whether the board needs no change
+func (p *Plan) Empty() bool {
+	return len(p.Create) == 0 && len(p.Update) == 0 && len(p.Delete) == 0
+}
+
+// Merge works out the changes that bring the board, local, up to date with
+// remote, the copy other machines pushed, given base, the copy this machine
+// synced last. A task changed on both sides takes the change made last, and
+// the one made here when both were made in the same second. A
+// task deleted on one side and changed on the other since base is kept, as
+// a change outweighs a deletion.
+func Merge(local, base, remote []Record) *Plan {
+	index := func(records []Record) map[string]Record {
+		m := make(map[string]Record, len(records))
+		for _, r := range records {
+			m[r.Hash] = r
+		}
+		return m
+	}
+	locals, bases, remotes := index(local), index(base), index(remote)
+
+	plan := &Plan{}
+	for _, l := range local {
+		r, inRemote := remotes[l.Hash]
+		b, inBase := bases[l.Hash]
+		switch {
+		case inRemote:
+			if sameTask(l, r) {
+				continue
+			}
+			// Changed elsewhere only, or on both sides and there last
+			if (inBase && sameTask(l, b)) || r.UpdatedAt.After(l.UpdatedAt) {
+				plan.Update = append(plan.Update, r)
+			}
+		case inBase && sameTask(l, b):
+			// Deleted elsewhere, unchanged here
+			plan.Delete = append(plan.Delete, l)
+		}
+	}
+	for _, r := range remote {
+		if _, inLocal := locals[r.Hash]; inLocal {
+			continue
+		}
+		// Deleted here since the last sync, unless changed elsewhere since
+		if b, inBase := bases[r.Hash]; inBase && sameTask(r, b) {
+			continue
+		}
+		plan.Create = append(plan.Create, r)
+	}
+	return plan
+}
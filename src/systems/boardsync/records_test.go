@@ -0,0 +1,107 @@
+package boardsync
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFile(t *testing.T) {
+	for name, want := range map[string]string{
+		"work":          "boards/work.jsonl",
+		"my board":      "boards/my_board.jsonl",
+		"../../.bashrc": "boards/_______bashrc.jsonl",
+	} {
+		if got := File(name); got != want {
+			t.Errorf("File(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Hash: "bbbbbbbbbbbbbbbb", Title: "Second <b>", Status: "todo", Contexts: []string{"home"}, DueAt: &due},
+		{Hash: "aaaaaaaaaaaaaaaa", Title: "First", Status: "done", Priority: 3},
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, records); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"First"`) || !strings.Contains(lines[1], `"Second <b>"`) {
+		t.Fatalf("Expected one line per task in hash order, got:\n%s", buf.String())
+	}
+
+	decoded, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if len(decoded) != 2 || !sameTask(decoded[1], records[0]) || !sameTask(decoded[0], records[1]) {
+		t.Errorf("Round trip changed the records: %+v", decoded)
+	}
+
+	if _, err := Decode(strings.NewReader("{\"title\": \"No hash\"}\n")); err == nil {
+		t.Error("Expected a record without a hash to be refused")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 1, 1, hour, 0, 0, 0, time.UTC) }
+	record := func(hash, title string, hour int) Record {
+		return Record{Hash: hash, Title: title, Status: "todo", UpdatedAt: at(hour)}
+	}
+
+	base := []Record{
+		record("same", "Same", 1),
+		record("mine", "Mine", 1),
+		record("theirs", "Theirs", 1),
+		record("both", "Both", 1),
+		record("gone-there", "Gone there", 1),
+		record("gone-here", "Gone here", 1),
+		record("edited-there", "Edited there", 1),
+		record("edited-here", "Edited here", 1),
+	}
+	local := []Record{
+		record("same", "Same", 1),
+		record("mine", "Mine, changed", 2),
+		record("theirs", "Theirs", 1),
+		record("both", "Both, changed here", 3),
+		record("gone-there", "Gone there", 1),
+		record("edited-here", "Edited here, changed", 2),
+		record("new-here", "New here", 2),
+	}
+	remote := []Record{
+		record("same", "Same", 1),
+		record("mine", "Mine", 1),
+		record("theirs", "Theirs, changed", 2),
+		record("both", "Both, changed there", 4),
+		record("gone-here", "Gone here", 1),
+		record("edited-there", "Edited there, changed", 2),
+		record("new-there", "New there", 2),
+	}
+
+	plan := Merge(local, base, remote)
+	titles := func(records []Record) string {
+		var names []string
+		for _, r := range records {
+			names = append(names, r.Title)
+		}
+		return strings.Join(names, ", ")
+	}
+	if got, want := titles(plan.Update), "Theirs, changed, Both, changed there"; got != want {
+		t.Errorf("Update = %q, want %q", got, want)
+	}
+	if got, want := titles(plan.Create), "Edited there, changed, New there"; got != want {
+		t.Errorf("Create = %q, want %q", got, want)
+	}
+	if got, want := titles(plan.Delete), "Gone there"; got != want {
+		t.Errorf("Delete = %q, want %q", got, want)
+	}
+
+	if plan := Merge(local, base, base); !plan.Empty() {
+		t.Errorf("Expected nothing to change without remote changes, got %+v", plan)
+	}
+}
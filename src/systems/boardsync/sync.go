@@ -0,0 +1,316 @@
+package boardsync
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/git"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// Syncer syncs one board with its file in a repository
+type Syncer struct {
+	repo  *git.Repo
+	tasks *task.System
+	file  string
+
+	remote, branch string
+}
+
+// New returns a syncer of the board named boardName, whose tasks are in
+// taskSystem, through repo
+func New(repo *git.Repo, taskSystem *task.System, boardName string) *Syncer {
+	return &Syncer{repo: repo, tasks: taskSystem, file: File(boardName)}
+}
+
+// Remote returns the remote the repository pushes to, "" before Pull and
+// for a repository kept on this machine only
+func (s *Syncer) Remote() string {
+	return s.remote
+}
+
+// Pull brings in what other machines pushed and works out how it merges
+// with the board, as it was when this machine last synced it. It fetches the remote, if the repository has one, and
+// merges its branch, taking this side of any conflict: the file of the
+// board is rewritten from the merged board by Push.
+func (s *Syncer) Pull() (*Plan, error) {
+	local, err := s.records()
+	if err != nil {
+		return nil, err
+	}
+	base, err := s.base()
+	if err != nil {
+		return nil, err
+	}
+
+	s.remote, s.branch, err = s.repo.Upstream()
+	if err != nil {
+		return nil, err
+	}
+	remote, _, err := s.readFile("HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if s.remote != "" {
+		if err := s.repo.Fetch(s.remote); err != nil {
+			return nil, err
+		}
+		upstream := s.remote + "/" + s.branch
+		if s.repo.HasRevision(upstream) {
+			records, found, err := s.readFile(upstream)
+			if err != nil {
+				return nil, err
+			}
+			// A remote that never had the board changed nothing in it
+			if found {
+				remote = records
+			}
+			if err := s.repo.MergeOurs(upstream); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return Merge(local, base, remote), nil
+}
+
+// Apply changes the board as plan says. Deleted tasks go to the trash, and
+// tasks brought in keep the times they were created and updated at.
+func (s *Syncer) Apply(plan *Plan) error {
+	for _, r := range plan.Create {
+		id, err := s.tasks.HashOwner(r.Hash)
+		if err != nil {
+			return err
+		}
+		if id != 0 {
+			// Deleted here, then changed elsewhere
+			if err := s.tasks.RestoreTask(id); err != nil {
+				return fmt.Errorf("failed to restore %q: %w", r.Title, err)
+			}
+		} else {
+			created, err := s.tasks.WithActor(r.CreatedBy).CreateWithPriority(1, r.Title, r.Description, r.Priority)
+			if err != nil {
+				return fmt.Errorf("failed to add %q: %w", r.Title, err)
+			}
+			if err := s.tasks.SetHash(created.ID, r.Hash); err != nil {
+				return fmt.Errorf("failed to add %q: %w", r.Title, err)
+			}
+			id = created.ID
+		}
+		if err := s.update(id, r); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range plan.Update {
+		current, err := s.tasks.GetByHash(r.Hash)
+		if err != nil {
+			return err
+		}
+		if err := s.update(current.ID, r); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range plan.Delete {
+		current, err := s.tasks.GetByHash(r.Hash)
+		if err != nil {
+			return err
+		}
+		if err := s.tasks.SoftDelete(current.ID); err != nil {
+			return fmt.Errorf("failed to delete %q: %w", r.Title, err)
+		}
+	}
+	return nil
+}
+
+// update makes the task with id match r, field by field, as changed by
+// whoever changed it last
+func (s *Syncer) update(id int, r Record) error {
+	tasks := s.tasks.WithActor(r.UpdatedBy)
+	current, err := tasks.GetByID(id)
+	if err != nil {
+		return err
+	}
+	have := FromTask(current)
+
+	if have.Title != r.Title || have.Description != r.Description {
+		err = tasks.Update(id, r.Title, r.Description)
+	}
+	if err == nil && have.Status != r.Status {
+		err = tasks.UpdateStatus(id, r.Status)
+	}
+	if err == nil && have.Priority != r.Priority {
+		err = tasks.UpdatePriority(id, r.Priority)
+	}
+	if err == nil && have.Estimate != r.Estimate {
+		err = tasks.UpdateEstimate(id, r.Estimate)
+	}
+	if err == nil && have.Assignee != r.Assignee {
+		_, err = tasks.Assign(id, r.Assignee)
+	}
+	if err == nil && have.Recurrence != r.Recurrence {
+		err = tasks.SetRecurrence(id, r.Recurrence)
+	}
+	if err == nil && have.Size != r.Size {
+		err = tasks.SetSize(id, r.Size)
+	}
+	if err == nil && have.Energy != r.Energy {
+		err = tasks.SetEnergy(id, r.Energy)
+	}
+	if err == nil && !sameDue(have.DueAt, r.DueAt) {
+		err = tasks.SetDue(id, r.DueAt)
+	}
+	if err == nil {
+		err = s.updateContexts(id, have.Contexts, r.Contexts)
+	}
+	if err == nil {
+		err = tasks.SetTimes(id, r.CreatedAt, r.UpdatedAt)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %q: %w", r.Title, err)
+	}
+	return nil
+}
+
+// updateContexts adds and removes the contexts of a task so that it has
+// those of want
+func (s *Syncer) updateContexts(id int, have, want []string) error {
+	wanted := make(map[string]bool, len(want))
+	for _, c := range want {
+		wanted[c] = true
+	}
+	for _, c := range have {
+		if wanted[c] {
+			delete(wanted, c)
+			continue
+		}
+		if err := s.tasks.RemoveContext(id, c); err != nil {
+			return err
+		}
+	}
+	for _, c := range want {
+		if wanted[c] {
+			if err := s.tasks.AddContext(id, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sameDue(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// Push writes the board to its file, commits it with message and pushes
+// the branch, if the repository has a remote. It reports whether the board
+// had changed since the last commit.
+func (s *Syncer) Push(message string) (bool, error) {
+	records, err := s.records()
+	if err != nil {
+		return false, err
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, records); err != nil {
+		return false, err
+	}
+
+	path := filepath.Join(s.repo.Dir(), filepath.FromSlash(s.file))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	committed, err := s.repo.Commit(message, s.file)
+	if err != nil {
+		return false, err
+	}
+	if err := s.saveBase(buf.Bytes()); err != nil {
+		return committed, err
+	}
+	// Push even without a commit, for a merge or an earlier failed push
+	if s.remote != "" {
+		if err := s.repo.Push(s.remote, s.branch); err != nil {
+			return committed, err
+		}
+	}
+	return committed, nil
+}
+
+// records reads the board as records
+func (s *Syncer) records() ([]Record, error) {
+	tasks, err := s.tasks.List(1)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]Record, 0, len(tasks))
+	for _, t := range tasks {
+		records = append(records, FromTask(t))
+	}
+	return records, nil
+}
+
+// basePath returns where the board is kept as this machine last synced it.
+// It is not the committed file: a clone has that before it ever synced.
+func (s *Syncer) basePath() (string, error) {
+	return s.repo.GitPath(filepath.Join("cainban", filepath.FromSlash(s.file)))
+}
+
+// base reads the board as this machine last synced it, nil before the first
+// sync, when every task on either side is new to the other
+func (s *Syncer) base() ([]Record, error) {
+	path, err := s.basePath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	records, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// saveBase keeps data, the board as just synced, for the next sync
+func (s *Syncer) saveBase(data []byte) error {
+	path, err := s.basePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// readFile reads the board's file as of rev, reporting false when it was
+// not there
+func (s *Syncer) readFile(rev string) ([]Record, bool, error) {
+	data, found, err := s.repo.ReadFile(rev, s.file)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	records, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s as of %s: %w", s.file, rev, err)
+	}
+	return records, true, nil
+}
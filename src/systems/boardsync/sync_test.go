@@ -0,0 +1,124 @@
+package boardsync
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/git"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestSyncTwoMachines(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(name, "Test")
+	}
+	for _, name := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "test@example.com")
+	}
+
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+	if out, err := exec.Command("git", "init", "--quiet", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+
+	type machine struct {
+		tasks *task.System
+		repo  *git.Repo
+	}
+	newMachine := func(name string) machine {
+		db, err := storage.NewMemory()
+		if err != nil {
+			t.Fatalf("Failed to create test database: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		repo, err := git.Clone(remote, filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Clone failed: %v", err)
+		}
+		return machine{task.New(db.Conn()).WithActor(name), repo}
+	}
+	sync := func(m machine) {
+		t.Helper()
+		s := New(m.repo, m.tasks, "work")
+		plan, err := s.Pull()
+		if err != nil {
+			t.Fatalf("Pull failed: %v", err)
+		}
+		if err := s.Apply(plan); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if _, err := s.Push("Sync board 'work'"); err != nil {
+			t.Fatalf("Push failed: %v", err)
+		}
+	}
+
+	laptop, desktop := newMachine("laptop"), newMachine("desktop")
+
+	// A task added on the laptop shows up on the desktop, hash and all
+	written, _ := laptop.tasks.CreateWithPriority(1, "Write the report", "", "high")
+	laptop.tasks.AddContext(written.ID, "office")
+	sync(laptop)
+	sync(desktop)
+	there, err := desktop.tasks.GetByHash(written.Hash)
+	if err != nil {
+		t.Fatalf("Expected the task on the desktop: %v", err)
+	}
+	if there.Priority != task.PriorityHigh || len(there.Contexts) != 1 || !there.UpdatedAt.Equal(written.UpdatedAt) || there.CreatedBy != "laptop" {
+		t.Errorf("Task arrived as %+v", there)
+	}
+
+	// Changes on both sides merge; deleting on one side deletes on the other
+	desktop.tasks.UpdateStatus(there.ID, task.StatusDoing)
+	doomed, _ := desktop.tasks.Create(1, "Throwaway", "")
+	sync(desktop)
+	laptopOnly, _ := laptop.tasks.Create(1, "Laptop only", "")
+	sync(laptop)
+	laptop.tasks.SoftDelete(mustFind(t, laptop.tasks, doomed.Hash).ID)
+	sync(laptop)
+	sync(desktop)
+
+	if got, _ := laptop.tasks.GetByHash(written.Hash); got.Status != task.StatusDoing || got.UpdatedBy != "desktop" {
+		t.Errorf("Expected the desktop's move on the laptop, got %s by %s", got.Status, got.UpdatedBy)
+	}
+	if _, err := desktop.tasks.GetByHash(laptopOnly.Hash); err != nil {
+		t.Errorf("Expected the laptop's task on the desktop: %v", err)
+	}
+	if _, err := desktop.tasks.GetByHash(doomed.Hash); err == nil {
+		t.Error("Expected the task deleted on the laptop to be gone from the desktop")
+	}
+
+	// A sync with nothing new commits nothing
+	s := New(desktop.repo, desktop.tasks, "work")
+	plan, err := s.Pull()
+	if err != nil || !plan.Empty() {
+		t.Fatalf("Expected nothing to pull, got %+v, %v", plan, err)
+	}
+	if committed, err := s.Push("Sync board 'work'"); err != nil || committed {
+		t.Errorf("Expected nothing to commit, got %v, %v", committed, err)
+	}
+
+	// A machine cloning later takes the board rather than deleting it
+	tablet := newMachine("tablet")
+	sync(tablet)
+	sync(laptop)
+	for _, m := range []machine{tablet, laptop} {
+		if _, err := m.tasks.GetByHash(written.Hash); err != nil {
+			t.Errorf("Expected the task to survive a new machine: %v", err)
+		}
+	}
+}
+
+func mustFind(t *testing.T, tasks *task.System, hash string) *task.Task {
+	t.Helper()
+	found, err := tasks.GetByHash(hash)
+	if err != nil {
+		t.Fatalf("GetByHash(%s) failed: %v", hash, err)
+	}
+	return found
+}
@@ -0,0 +1,553 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// A bundle is a tar archive holding a whole board:
+//
+//	manifest.json          format, version and the SHA-256 of every other file
+//	data.json              every row of every table of the board database
+//	attachments/<name>     files kept next to the database, e.g. rules.toml
+//
+// The manifest comes first so a reader can check each file as it arrives.
+const (
+	// Extension is the file extension of bundles
+	Extension = ".cainban-bundle"
+
+	// Format identifies bundles in their manifest
+	Format = "cainban-bundle"
+	// Version is the bundle format version written by this cainban
+	Version = 1
+
+	manifestName   = "manifest.json"
+	dataName       = "data.json"
+	attachmentsDir = "attachments/"
+)
+
+// attachmentPaths maps each attachment a bundle can carry to where it is
+// stored for the board database at dbPath
+var attachmentPaths = map[string]func(dbPath string) string{
+	"rules.toml": automation.RulesPath,
+}
+
+// File is a file of the bundle as listed in its manifest
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Manifest describes a bundle and checksums its files
+type Manifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	Board     string    `json:"board"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []File    `json:"files"`
+}
+
+// Bundle is a board ready to be written to or read from a bundle file
+type Bundle struct {
+	Manifest Manifest
+	// Tables maps each table name to its rows, column name to value
+	Tables map[string][]map[string]interface{}
+	// Attachments maps attachment names to their content
+	Attachments map[string][]byte
+}
+
+// Tasks returns the number of tasks in the bundle, deleted ones included
+func (b *Bundle) Tasks() int {
+	return len(b.Tables["tasks"])
+}
+
+// Create bundles the board stored in db, including its attachments
+func Create(db *storage.DB, boardName string, now time.Time) (*Bundle, error) {
+	b := &Bundle{
+		Manifest: Manifest{
+			Format:    Format,
+			Version:   Version,
+			Board:     boardName,
+			CreatedAt: now.UTC(),
+		},
+		Tables:      make(map[string][]map[string]interface{}),
+		Attachments: make(map[string][]byte),
+	}
+
+	// Read every table in one transaction for a consistent snapshot
+	tx, err := db.Conn().BeginTx(db.Context(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read board: %w", err)
+	}
+	defer tx.Rollback()
+
+	tables, err := tableNames(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		rows, err := readTable(tx, table)
+		if err != nil {
+			return nil, err
+		}
+		b.Tables[table] = rows
+	}
+
+	for name, pathOf := range attachmentPaths {
+		content, err := os.ReadFile(pathOf(db.Path()))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment %s: %w", name, err)
+		}
+		b.Attachments[name] = content
+	}
+
+	return b, nil
+}
+
+// querier is what reading tables needs of a database or transaction
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// tableNames lists the tables of a database, leaving out SQLite's own and
+// the record of its migrations, which the database restored into has
+func tableNames(q querier) ([]string, error) {
+	rows, err := q.Query(`SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name != ? ORDER BY name`, storage.MigrationsTable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to list tables: %w", err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// columnNames lists the columns of a table in schema order
+func columnNames(q querier, table string) ([]string, error) {
+	rows, err := q.Query(fmt.Sprintf("PRAGMA table_info(%s)", quoteIdent(table)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// readTable reads all rows of a table as stored. Columns are selected as
+// +column, which SQLite leaves untouched but which has no declared type, so
+// the driver does not turn DATETIME text into time.Time on the way out.
+func readTable(q querier, table string) ([]map[string]interface{}, error) {
+	columns, err := columnNames(q, table)
+	if err != nil {
+		return nil, err
+	}
+	selects := make([]string, len(columns))
+	for i, column := range columns {
+		selects[i] = "+" + quoteIdent(column)
+	}
+
+	rows, err := q.Query(fmt.Sprintf("SELECT %s FROM %s ORDER BY rowid", strings.Join(selects, ", "), quoteIdent(table)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	result := []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			// No column is a BLOB; keep any bytes as text rather than base64
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[column] = values[i]
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// files returns the content of every file but the manifest, by name
+func (b *Bundle) files() (map[string][]byte, error) {
+	data, err := json.MarshalIndent(map[string]interface{}{"tables": b.Tables}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode board data: %w", err)
+	}
+
+	files := map[string][]byte{dataName: data}
+	for name, content := range b.Attachments {
+		files[attachmentsDir+name] = content
+	}
+	return files, nil
+}
+
+// Write writes the bundle as a tar archive, checksumming every file in the
+// manifest
+func (b *Bundle) Write(w io.Writer) error {
+	files, err := b.files()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.Manifest.Files = nil
+	for _, name := range names {
+		b.Manifest.Files = append(b.Manifest.Files, File{
+			Name:   name,
+			Size:   int64(len(files[name])),
+			SHA256: checksum(files[name]),
+		})
+	}
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	tw := tar.NewWriter(w)
+	write := func(name string, content []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: b.Manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		return nil
+	}
+
+	if err := write(manifestName, manifest); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := write(name, files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Read reads a bundle, verifying that it holds exactly the files its
+// manifest lists, each with the listed size and checksum
+func Read(r io.Reader) (*Bundle, error) {
+	tr := tar.NewReader(r)
+	files := make(map[string][]byte)
+	var manifestData []byte
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("not a readable bundle: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("corrupt bundle: %s is not a regular file", header.Name)
+		}
+
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("corrupt bundle: failed to read %s: %w", header.Name, err)
+		}
+		if header.Name == manifestName {
+			manifestData = content
+			continue
+		}
+		if _, dup := files[header.Name]; dup {
+			return nil, fmt.Errorf("corrupt bundle: %s appears twice", header.Name)
+		}
+		files[header.Name] = content
+	}
+
+	if manifestData == nil {
+		return nil, fmt.Errorf("not a cainban bundle: %s is missing", manifestName)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("corrupt bundle: invalid manifest: %w", err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("not a cainban bundle: unknown format %q", manifest.Format)
+	}
+	if manifest.Version < 1 || manifest.Version > Version {
+		return nil, fmt.Errorf("unsupported bundle version %d (this cainban reads up to %d)", manifest.Version, Version)
+	}
+
+	b := &Bundle{Manifest: manifest, Attachments: make(map[string][]byte)}
+	listed := make(map[string]bool)
+	for _, f := range manifest.Files {
+		content, ok := files[f.Name]
+		switch {
+		case listed[f.Name]:
+			return nil, fmt.Errorf("corrupt bundle: manifest lists %s twice", f.Name)
+		case !ok:
+			return nil, fmt.Errorf("corrupt bundle: %s is missing", f.Name)
+		case int64(len(content)) != f.Size:
+			return nil, fmt.Errorf("corrupt bundle: %s is %d bytes, manifest says %d", f.Name, len(content), f.Size)
+		case checksum(content) != f.SHA256:
+			return nil, fmt.Errorf("corrupt bundle: checksum mismatch for %s", f.Name)
+		}
+		listed[f.Name] = true
+
+		if name := strings.TrimPrefix(f.Name, attachmentsDir); name != f.Name {
+			if _, known := attachmentPaths[name]; !known || path.Base(name) != name {
+				return nil, fmt.Errorf("corrupt bundle: unknown attachment %s", name)
+			}
+			b.Attachments[name] = content
+		} else if f.Name != dataName {
+			return nil, fmt.Errorf("corrupt bundle: unexpected file %s", f.Name)
+		}
+	}
+	for name := range files {
+		if !listed[name] {
+			return nil, fmt.Errorf("corrupt bundle: %s is not in the manifest", name)
+		}
+	}
+	if !listed[dataName] {
+		return nil, fmt.Errorf("corrupt bundle: %s is missing", dataName)
+	}
+
+	var data struct {
+		Tables map[string][]map[string]interface{} `json:"tables"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(files[dataName]))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("corrupt bundle: invalid %s: %w", dataName, err)
+	}
+	b.Tables = data.Tables
+	for _, rows := range b.Tables {
+		for _, row := range rows {
+			for column, value := range row {
+				row[column] = fromJSON(value)
+			}
+		}
+	}
+	return b, nil
+}
+
+// fromJSON turns a decoded JSON number back into the integer or real SQLite
+// stored
+func fromJSON(value interface{}) interface{} {
+	n, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	f, _ := n.Float64()
+	return f
+}
+
+// StripExternal takes the automations that run a command or post to a URL
+// out of the bundle, and its rules file if any of its rules do, so that
+// importing a board from someone else never sets up code to run on this
+// machine. It returns a line describing each one taken out.
+func (b *Bundle) StripExternal() ([]string, error) {
+	var stripped []string
+	var kept []map[string]interface{}
+	for _, row := range b.Tables["automations"] {
+		kind, _ := row["kind"].(string)
+		if automation.Kind(kind).IsExternal() {
+			stripped = append(stripped, fmt.Sprintf("automation on entering %v: %s %v", row["status"], kind, row["target"]))
+			continue
+		}
+		kept = append(kept, row)
+	}
+	if len(stripped) > 0 {
+		b.Tables["automations"] = kept
+	}
+
+	if content, ok := b.Attachments["rules.toml"]; ok {
+		rules, err := automation.ParseRules(bytes.NewReader(content), attachmentsDir+"rules.toml")
+		if err != nil {
+			return nil, err
+		}
+		external := false
+		for _, r := range rules {
+			if r.Action.IsExternal() {
+				stripped = append(stripped, fmt.Sprintf("rules.toml, rule %s: %s %s", r.Name, r.Action, r.Target))
+				external = true
+			}
+		}
+		if external {
+			delete(b.Attachments, "rules.toml")
+		}
+	}
+	return stripped, nil
+}
+
+// Restore creates a board at dbPath holding the bundled data and writes the
+// attachments next to it. Nothing may exist at dbPath yet; on failure the
+// partly restored board is removed again.
+func (b *Bundle) Restore(dbPath string) (err error) {
+	targets := []string{dbPath}
+	for name := range b.Attachments {
+		targets = append(targets, attachmentPaths[name](dbPath))
+	}
+	for _, target := range targets {
+		if _, err := os.Stat(target); err == nil {
+			return fmt.Errorf("%s already exists", target)
+		}
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		db.Close()
+		if err != nil {
+			for _, target := range targets {
+				for _, suffix := range []string{"", "-wal", "-shm"} {
+					os.Remove(target + suffix)
+				}
+			}
+		}
+	}()
+
+	if err := b.restoreTables(db); err != nil {
+		return err
+	}
+	for name, content := range b.Attachments {
+		if err := os.WriteFile(attachmentPaths[name](dbPath), content, 0644); err != nil {
+			return fmt.Errorf("failed to write attachment %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// restoreTables replaces the rows of a freshly created board with the
+// bundled ones. Foreign keys are checked once all rows are in.
+func (b *Bundle) restoreTables(db *storage.DB) error {
+	tx, err := db.Conn().BeginTx(db.Context(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to restore board: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`PRAGMA defer_foreign_keys = ON`); err != nil {
+		return fmt.Errorf("failed to restore board: %w", err)
+	}
+
+	tables := make([]string, 0, len(b.Tables))
+	for table := range b.Tables {
+		if table != storage.MigrationsTable {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		columns, err := columnNames(tx, table)
+		if err != nil {
+			return err
+		}
+		if len(columns) == 0 {
+			return fmt.Errorf("bundle has unknown table %s; is it from a newer cainban?", table)
+		}
+		known := make(map[string]bool, len(columns))
+		for _, column := range columns {
+			known[column] = true
+		}
+
+		if _, err := tx.Exec(fmt.Sprintf("DELETE FROM %s", quoteIdent(table))); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+		for i, row := range b.Tables[table] {
+			names := make([]string, 0, len(row))
+			for column := range row {
+				if !known[column] {
+					return fmt.Errorf("bundle has unknown column %s.%s; is it from a newer cainban?", table, column)
+				}
+				names = append(names, column)
+			}
+			sort.Strings(names)
+
+			quoted := make([]string, len(names))
+			placeholders := make([]string, len(names))
+			values := make([]interface{}, len(names))
+			for j, column := range names {
+				quoted[j] = quoteIdent(column)
+				placeholders[j] = "?"
+				values[j] = row[column]
+			}
+			query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", quoteIdent(table), strings.Join(quoted, ", "), strings.Join(placeholders, ", "))
+			if _, err := tx.Exec(query, values...); err != nil {
+				return fmt.Errorf("failed to restore row %d of %s: %w", i+1, table, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to restore board: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// withBoard runs fn against the board database at path
+func withBoard(t *testing.T, path string, fn func(db *storage.DB, taskSystem *task.System)) {
+	t.Helper()
+	db, err := storage.New(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer db.Close()
+	fn(db, task.New(db.Conn()))
+}
+
+// exportBoard bundles a board with a few tasks, a link, a comment, a due
+// date and a rules file
+func exportBoard(t *testing.T) []byte {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "source.db")
+	rules := "[rules.ship]\non = \"moved\"\nto = \"done\"\naction = \"comment\"\ntext = \"Shipped\"\n"
+	if err := os.WriteFile(automation.RulesPath(dbPath), []byte(rules), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+
+	var buf bytes.Buffer
+	withBoard(t, dbPath, func(db *storage.DB, taskSystem *task.System) {
+		schema, _ := taskSystem.CreateWithPriority(1, "Schema", "Tables and indexes", "high")
+		api, _ := taskSystem.Create(1, "API", "")
+		due := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)
+		if err := taskSystem.SetDue(api.ID, &due); err != nil {
+			t.Fatalf("Failed to set due date: %v", err)
+		}
+		if err := taskSystem.LinkTasks(schema.ID, api.ID, task.LinkTypeBlocks); err != nil {
+			t.Fatalf("Failed to link tasks: %v", err)
+		}
+		if err := taskSystem.AddContext(api.ID, "@backend"); err != nil {
+			t.Fatalf("Failed to add context: %v", err)
+		}
+		if _, err := taskSystem.AddComment(api.ID, "alice", "Needs the schema first"); err != nil {
+			t.Fatalf("Failed to comment: %v", err)
+		}
+
+		b, err := Create(db, "source", time.Now())
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if b.Tasks() != 2 {
+			t.Errorf("Expected 2 tasks in the bundle, got %d", b.Tasks())
+		}
+		if err := b.Write(&buf); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	})
+	return buf.Bytes()
+}
+
+func TestBundle_RoundTrip(t *testing.T) {
+	data := exportBoard(t)
+
+	b, err := Read(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if b.Manifest.Board != "source" || b.Manifest.Version != Version {
+		t.Errorf("Unexpected manifest: %+v", b.Manifest)
+	}
+
+	dbPath := filepath.Join(t.TempDir(), "copy.db")
+	if err := b.Restore(dbPath); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if err := b.Restore(dbPath); err == nil {
+		t.Error("Expected an error restoring over an existing board")
+	}
+
+	rules, err := os.ReadFile(automation.RulesPath(dbPath))
+	if err != nil || !strings.Contains(string(rules), "Shipped") {
+		t.Errorf("Expected the rules file to be restored, got %q (%v)", rules, err)
+	}
+
+	withBoard(t, dbPath, func(db *storage.DB, taskSystem *task.System) {
+		api, err := taskSystem.GetByID(2)
+		if err != nil {
+			t.Fatalf("Failed to get restored task: %v", err)
+		}
+		if api.DueAt == nil || !api.DueAt.Equal(time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC)) {
+			t.Errorf("Expected the due date to survive, got %v", api.DueAt)
+		}
+		if !reflect.DeepEqual(api.Contexts, []string{"@backend"}) {
+			t.Errorf("Expected contexts to survive, got %v", api.Contexts)
+		}
+		links, err := taskSystem.ListLinks()
+		if err != nil || len(links) != 1 || links[0].FromTaskID != 1 || links[0].ToTaskID != 2 {
+			t.Errorf("Expected Schema to still block API, got %v (%v)", links, err)
+		}
+		comments, err := taskSystem.ListComments(2)
+		if err != nil || len(comments) != 1 || comments[0].Author != "alice" {
+			t.Errorf("Expected the comment to survive, got %v (%v)", comments, err)
+		}
+
+		// New tasks continue after the restored IDs
+		added, err := taskSystem.Create(1, "Docs", "")
+		if err != nil || added.ID != 3 {
+			t.Errorf("Expected the next task to get ID 3, got %v (%v)", added, err)
+		}
+	})
+
+	// Exporting the copy gives the same data
+	withBoard(t, dbPath, func(db *storage.DB, taskSystem *task.System) {
+		again, err := Create(db, "copy", time.Now())
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if again.Tasks() != 3 {
+			t.Errorf("Expected 3 tasks, got %d", again.Tasks())
+		}
+	})
+}
+
+func TestBundle_StripExternal(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "source.db")
+	rules := "[rules.deploy]\non = \"moved\"\nto = \"done\"\naction = \"webhook\"\nurl = \"https://ci.example.com/deploy\"\n"
+	if err := os.WriteFile(automation.RulesPath(dbPath), []byte(rules), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+
+	var b *Bundle
+	withBoard(t, dbPath, func(db *storage.DB, taskSystem *task.System) {
+		automations := automation.New(db.Conn())
+		if _, err := automations.Add(task.StatusDone, automation.KindCommand, "make deploy"); err != nil {
+			t.Fatalf("Failed to add automation: %v", err)
+		}
+		if _, err := automations.Add(task.StatusDoing, automation.KindWebhook, "https://chat.example.com/hook"); err != nil {
+			t.Fatalf("Failed to add automation: %v", err)
+		}
+		var err error
+		if b, err = Create(db, "source", time.Now()); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	})
+
+	stripped, err := b.StripExternal()
+	if err != nil {
+		t.Fatalf("StripExternal failed: %v", err)
+	}
+	if len(stripped) != 3 || !strings.Contains(stripped[0], "make deploy") || !strings.Contains(stripped[2], "https://ci.example.com/deploy") {
+		t.Errorf("Expected both automations and the webhook rule, got %q", stripped)
+	}
+
+	copyPath := filepath.Join(t.TempDir(), "copy.db")
+	if err := b.Restore(copyPath); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if _, err := os.Stat(automation.RulesPath(copyPath)); !os.IsNotExist(err) {
+		t.Errorf("Expected no rules file, got %v", err)
+	}
+	withBoard(t, copyPath, func(db *storage.DB, taskSystem *task.System) {
+		list, err := automation.New(db.Conn()).List()
+		if err != nil || len(list) != 0 {
+			t.Errorf("Expected no automations, got %v (%v)", list, err)
+		}
+	})
+
+	// A bundle with nothing external is left as it is
+	b, err = Read(bytes.NewReader(exportBoard(t)))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if stripped, err := b.StripExternal(); err != nil || len(stripped) != 0 || b.Attachments["rules.toml"] == nil {
+		t.Errorf("Expected nothing stripped, got %q (%v)", stripped, err)
+	}
+}
+
+// rewrite copies a bundle through edit, which returns the new content of
+// each file or nil to drop it
+func rewrite(t *testing.T, data []byte, edit func(name string, content []byte) []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tr := tar.NewReader(bytes.NewReader(data))
+	tw := tar.NewWriter(&buf)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read bundle: %v", err)
+		}
+		content, _ := io.ReadAll(tr)
+		if content = edit(header.Name, content); content == nil {
+			continue
+		}
+		header.Size = int64(len(content))
+		tw.WriteHeader(header)
+		tw.Write(content)
+	}
+	tw.Close()
+	return buf.Bytes()
+}
+
+func TestRead_Corruption(t *testing.T) {
+	data := exportBoard(t)
+
+	cases := map[string]struct {
+		data []byte
+		want string
+	}{
+		"tampered data": {rewrite(t, data, func(name string, content []byte) []byte {
+			if name == dataName {
+				return bytes.Replace(content, []byte("Schema"), []byte("Schemo"), 1)
+			}
+			return content
+		}), "checksum mismatch for data.json"},
+		"truncated attachment": {rewrite(t, data, func(name string, content []byte) []byte {
+			if name == "attachments/rules.toml" {
+				return content[:10]
+			}
+			return content
+		}), "attachments/rules.toml is 10 bytes"},
+		"missing file": {rewrite(t, data, func(name string, content []byte) []byte {
+			if name == dataName {
+				return nil
+			}
+			return content
+		}), "data.json is missing"},
+		"missing manifest": {rewrite(t, data, func(name string, content []byte) []byte {
+			if name == manifestName {
+				return nil
+			}
+			return content
+		}), "manifest.json is missing"},
+		"newer version": {rewrite(t, data, func(name string, content []byte) []byte {
+			if name == manifestName {
+				return bytes.Replace(content, []byte(`"version": 1`), []byte(`"version": 99`), 1)
+			}
+			return content
+		}), "unsupported bundle version 99"},
+		"cut short": {data[:len(data)/2], "bundle"},
+		"not a tar": {[]byte("id,title\n1,Schema\n"), "bundle"},
+	}
+
+	for name, c := range cases {
+		_, err := Read(bytes.NewReader(c.data))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, c.want, err)
+		}
+	}
+}
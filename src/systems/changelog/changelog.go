@@ -0,0 +1,176 @@
+// Package changelog holds the release notes built into cainban and picks
+// out those a user has not seen yet.
+package changelog
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+//go:embed CHANGELOG.md
+var notes string
+
+// Unreleased is the version of the notes of changes not released yet, and
+// of builds that are not releases. It is newer than any version.
+const Unreleased = "Unreleased"
+
+// breakingPrefix marks an item that may need scripts or agents to change
+const breakingPrefix = "**Breaking:**"
+
+// Release is the notes of one version
+type Release struct {
+	Version  string    `json:"version"`
+	Date     string    `json:"date,omitempty"`
+	Sections []Section `json:"sections"`
+}
+
+// Section is a group of changes in a release, such as "New commands"
+type Section struct {
+	Title string `json:"title"`
+	Items []Item `json:"items"`
+}
+
+// Item is one change
+type Item struct {
+	Text     string `json:"text"`
+	Breaking bool   `json:"breaking,omitempty"`
+}
+
+// Releases returns the built-in release notes, newest first
+func Releases() ([]Release, error) {
+	return Parse(notes)
+}
+
+// Parse reads release notes: "## <version>" headings, optionally followed
+// by " - <date>", with "### <title>" sections of "- " items. Anything
+// before the first release is ignored.
+func Parse(text string) ([]Release, error) {
+	var releases []Release
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \r")
+		switch {
+		case strings.HasPrefix(line, "## "):
+			version, date, _ := strings.Cut(strings.TrimPrefix(line, "## "), " - ")
+			releases = append(releases, Release{Version: strings.TrimSpace(version), Date: strings.TrimSpace(date)})
+
+		case strings.HasPrefix(line, "### "):
+			if len(releases) == 0 {
+				return nil, fmt.Errorf("line %d: section outside a release", n+1)
+			}
+			r := &releases[len(releases)-1]
+			r.Sections = append(r.Sections, Section{Title: strings.TrimPrefix(line, "### ")})
+
+		case strings.HasPrefix(line, "- "):
+			if len(releases) == 0 {
+				continue
+			}
+			r := &releases[len(releases)-1]
+			if len(r.Sections) == 0 {
+				return nil, fmt.Errorf("line %d: item outside a section", n+1)
+			}
+			item := Item{Text: strings.TrimPrefix(line, "- ")}
+			if strings.HasPrefix(item.Text, breakingPrefix) {
+				item.Text, item.Breaking = strings.TrimSpace(strings.TrimPrefix(item.Text, breakingPrefix)), true
+			}
+			s := &r.Sections[len(r.Sections)-1]
+			s.Items = append(s.Items, item)
+		}
+	}
+	return releases, nil
+}
+
+// Between returns the releases newer than from, up to and including to,
+// newest first. An empty from has every release up to to.
+func Between(releases []Release, from, to string) []Release {
+	var picked []Release
+	for _, r := range releases {
+		if Compare(r.Version, to) <= 0 && (from == "" || Compare(r.Version, from) > 0) {
+			picked = append(picked, r)
+		}
+	}
+	return picked
+}
+
+// Compare orders two versions such as "0.2.1" or "v0.3.0-2-gabc123",
+// returning -1, 0 or 1. Only the major, minor and patch numbers count;
+// Unreleased, and anything that is not a version at all, such as the
+// commit a development build reports, is newer than every version.
+func Compare(a, b string) int {
+	va, okA := parse(a)
+	vb, okB := parse(b)
+	switch {
+	case !okA && !okB:
+		return 0
+	case !okA:
+		return 1
+	case !okB:
+		return -1
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Normalize returns a version as it appears in the notes: "v0.3.0-2-gabc"
+// is "0.3.0", and anything that is not a version is Unreleased
+func Normalize(version string) string {
+	v, ok := parse(version)
+	if !ok {
+		return Unreleased
+	}
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// parse reads the major, minor and patch numbers of a version
+func parse(version string) ([3]int, bool) {
+	var v [3]int
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	// Drop pre-release and build suffixes, and git describe's distance
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// seenFile records, in the config directory, the version whose notes were
+// shown last
+const seenFile = "whatsnew-seen"
+
+// LastSeen returns the version whose notes were last shown from dir, or ""
+// if they never were
+func LastSeen(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, seenFile))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// MarkSeen records that the notes up to version have been shown
+func MarkSeen(dir, version string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, seenFile), []byte(version+"\n"), 0644)
+}
@@ -0,0 +1,106 @@
+package changelog
+
+import "testing"
+
+func TestReleases_BuiltIn(t *testing.T) {
+	releases, err := Releases()
+	if err != nil {
+		t.Fatalf("Releases() error = %v", err)
+	}
+	if len(releases) == 0 {
+		t.Fatal("Expected built-in release notes")
+	}
+	for i, r := range releases {
+		if i > 0 && Compare(releases[i-1].Version, r.Version) <= 0 {
+			t.Errorf("Expected %s to come after %s, newest first", r.Version, releases[i-1].Version)
+		}
+		if len(r.Sections) == 0 {
+			t.Errorf("Expected release %s to have notes", r.Version)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	releases, err := Parse(`# Changelog
+
+Intro text.
+
+## 0.3.0 - 2026-10-01
+
+### New commands
+- ` + "`cainban backup`" + `
+
+### MCP
+- **Breaking:** list_tasks pages its results
+- New tool
+
+## 0.2.1
+### Changes
+- Boards
+`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(releases) != 2 || releases[0].Version != "0.3.0" || releases[0].Date != "2026-10-01" || releases[1].Version != "0.2.1" {
+		t.Fatalf("Unexpected releases: %+v", releases)
+	}
+	mcp := releases[0].Sections[1]
+	if mcp.Title != "MCP" || len(mcp.Items) != 2 || !mcp.Items[0].Breaking || mcp.Items[0].Text != "list_tasks pages its results" || mcp.Items[1].Breaking {
+		t.Errorf("Unexpected MCP section: %+v", mcp)
+	}
+
+	if _, err := Parse("## 1.0.0\n- an item before any section\n"); err == nil {
+		t.Error("Expected an item outside a section to be an error")
+	}
+}
+
+func TestCompareAndBetween(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.2.1", "0.2.1", 0},
+		{"v0.2.1", "0.2.1", 0},
+		{"0.2.1", "0.10.0", -1},
+		{"v0.3.0-2-gabc123-dirty", "0.3.0", 0},
+		{"1.0.0", "0.9.9", 1},
+		{Unreleased, "9.9.9", 1},
+		{"8796cbd", Unreleased, 0},
+	}
+	for _, tt := range tests {
+		if got := Compare(tt.a, tt.b); got != tt.want {
+			t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	releases := []Release{{Version: Unreleased}, {Version: "0.3.0"}, {Version: "0.2.1"}, {Version: "0.2.0"}}
+	versions := func(rs []Release) []string {
+		var v []string
+		for _, r := range rs {
+			v = append(v, r.Version)
+		}
+		return v
+	}
+	if got := versions(Between(releases, "0.2.0", "0.3.0")); len(got) != 2 || got[0] != "0.3.0" || got[1] != "0.2.1" {
+		t.Errorf("Between(0.2.0, 0.3.0) = %v", got)
+	}
+	if got := versions(Between(releases, "0.3.0", Unreleased)); len(got) != 1 || got[0] != Unreleased {
+		t.Errorf("Between(0.3.0, Unreleased) = %v", got)
+	}
+	if got := Between(releases, "0.3.0", "0.3.0"); len(got) != 0 {
+		t.Errorf("Expected nothing new once seen, got %v", versions(got))
+	}
+}
+
+func TestSeen(t *testing.T) {
+	dir := t.TempDir()
+	if seen := LastSeen(dir); seen != "" {
+		t.Errorf("LastSeen() = %q before anything was shown", seen)
+	}
+	if err := MarkSeen(dir, "0.3.0"); err != nil {
+		t.Fatalf("MarkSeen() error = %v", err)
+	}
+	if seen := LastSeen(dir); seen != "0.3.0" {
+		t.Errorf("LastSeen() = %q, want 0.3.0", seen)
+	}
+}
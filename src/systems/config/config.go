@@ -0,0 +1,407 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats understood by the CLI
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config holds user preferences loaded from ~/.cainban/config.toml
+type Config struct {
+	DefaultPriority string            `json:"default_priority"`
+	DefaultBoard    string            `json:"default_board"`
+	OutputFormat    string            `json:"output_format"`
+	Theme           string            `json:"theme"`
+	Keymap          string            `json:"keymap"`
+	Keys            map[string]string `json:"keys,omitempty"`
+	Editor          string            `json:"editor"`
+	User            string            `json:"user,omitempty"`
+	HandoffWebhook  string            `json:"handoff_webhook,omitempty"`
+	BusyTimeout     time.Duration     `json:"busy_timeout"`
+	CommandTimeout  time.Duration     `json:"command_timeout"`
+	CommandMemoryMB int               `json:"command_memory_mb"`
+	AutoBackup      bool              `json:"auto_backup"`
+	AutoBoard       bool              `json:"auto_board"`
+	KeepBackups     int               `json:"keep_backups"`
+	StaleDays       int               `json:"stale_days"`
+	FuzzyMatch      bool              `json:"fuzzy_match"`
+	SyncDir         string            `json:"sync_dir,omitempty"`
+	WIPLimits       map[string]int    `json:"wip_limits"`
+
+	path string
+}
+
+// Default returns the configuration used when no config file exists
+func Default() *Config {
+	return &Config{
+		DefaultPriority: "none",
+		DefaultBoard:    "default",
+		OutputFormat:    FormatText,
+		Theme:           "auto",
+		Keymap:          "default",
+		BusyTimeout:     5 * time.Second,
+		CommandTimeout:  time.Minute,
+		AutoBackup:      true,
+		KeepBackups:     10,
+		StaleDays:       7,
+		FuzzyMatch:      true,
+		Keys:            make(map[string]string),
+		WIPLimits:       make(map[string]int),
+	}
+}
+
+// DefaultPath returns the location of the user's config file, that of the
+// profile in use
+func DefaultPath() string {
+	return filepath.Join(Dir(), "config.toml")
+}
+
+// Load reads a config file, falling back to defaults for anything it does
+// not set. A missing file is not an error.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	cfg.path = path
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	values, err := parse(bufio.NewScanner(file))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	if err := cfg.apply(values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// Path returns the file the config was loaded from
+func (c *Config) Path() string {
+	return c.path
+}
+
+// EditorCommand returns the editor to launch for long-form text, preferring
+// the config file over $VISUAL and $EDITOR
+func (c *Config) EditorCommand() string {
+	if c.Editor != "" {
+		return c.Editor
+	}
+	if visual := os.Getenv("VISUAL"); visual != "" {
+		return visual
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+// UserEnv names the environment variable giving the name changes are
+// recorded under, over the user setting, e.g. for an agent's MCP server
+const UserEnv = "CAINBAN_USER"
+
+// BoardEnv names the environment variable selecting the board to work on
+// over the current board, for one command or a whole agent session, without
+// changing the board that is current. cainban --board sets it.
+const BoardEnv = "CAINBAN_BOARD"
+
+// UserName returns the name to record the user's own actions under, such
+// as reactions and changes to tasks: $CAINBAN_USER, the config file or else
+// $USER
+func (c *Config) UserName() string {
+	if user := strings.TrimSpace(os.Getenv(UserEnv)); user != "" {
+		return user
+	}
+	if c.User != "" {
+		return c.User
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "anonymous"
+}
+
+// SyncDirectory returns the git repository `cainban sync` keeps boards in,
+// sync in the profile's directory unless the config file says otherwise
+func (c *Config) SyncDirectory() string {
+	if c.SyncDir != "" {
+		return c.SyncDir
+	}
+	return filepath.Join(Dir(), "sync")
+}
+
+// WIPLimit returns the work-in-progress limit for a status, or 0 if unlimited
+func (c *Config) WIPLimit(status string) int {
+	return c.WIPLimits[status]
+}
+
+// apply copies parsed values onto the config, validating known keys
+func (c *Config) apply(values map[string]interface{}) error {
+	for key, value := range values {
+		switch {
+		case key == "default_priority":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			c.DefaultPriority = str
+		case key == "default_board":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			c.DefaultBoard = str
+		case key == "output_format":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			if str != FormatText && str != FormatJSON {
+				return fmt.Errorf("output_format must be %q or %q", FormatText, FormatJSON)
+			}
+			c.OutputFormat = str
+		case key == "theme":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			c.Theme = str
+		case key == "keymap":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			c.Keymap = str
+		case strings.HasPrefix(key, "keys."):
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			c.Keys[strings.TrimPrefix(key, "keys.")] = str
+		case key == "editor":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			c.Editor = str
+		case key == "user":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			c.User = str
+		case key == "handoff_webhook":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			c.HandoffWebhook = str
+		case key == "busy_timeout":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			timeout, err := time.ParseDuration(str)
+			if err != nil || timeout < 0 {
+				return fmt.Errorf("busy_timeout must be a duration such as \"5s\"")
+			}
+			c.BusyTimeout = timeout
+		case key == "command_timeout":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			timeout, err := time.ParseDuration(str)
+			if err != nil || timeout <= 0 {
+				return fmt.Errorf("command_timeout must be a positive duration such as \"1m\"")
+			}
+			c.CommandTimeout = timeout
+		case key == "command_memory_mb":
+			limit, ok := value.(int)
+			if !ok || limit < 0 {
+				return fmt.Errorf("command_memory_mb must be a non-negative integer")
+			}
+			c.CommandMemoryMB = limit
+		case key == "auto_backup":
+			on, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("auto_backup must be true or false")
+			}
+			c.AutoBackup = on
+		case key == "auto_board":
+			on, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("auto_board must be true or false")
+			}
+			c.AutoBoard = on
+		case key == "keep_backups":
+			keep, ok := value.(int)
+			if !ok || keep < 0 {
+				return fmt.Errorf("keep_backups must be a non-negative integer")
+			}
+			c.KeepBackups = keep
+		case key == "stale_days":
+			days, ok := value.(int)
+			if !ok || days < 0 {
+				return fmt.Errorf("stale_days must be a non-negative integer")
+			}
+			c.StaleDays = days
+		case key == "fuzzy_match":
+			on, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("fuzzy_match must be true or false")
+			}
+			c.FuzzyMatch = on
+		case key == "sync_dir":
+			str, err := asString(key, value)
+			if err != nil {
+				return err
+			}
+			c.SyncDir = str
+		case strings.HasPrefix(key, "wip_limits."):
+			limit, ok := value.(int)
+			if !ok || limit < 0 {
+				return fmt.Errorf("%s must be a non-negative integer", key)
+			}
+			c.WIPLimits[strings.TrimPrefix(key, "wip_limits.")] = limit
+		default:
+			// Unknown keys are ignored so newer config files keep working
+			// with older binaries
+		}
+	}
+	return nil
+}
+
+func asString(key string, value interface{}) (string, error) {
+	str, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	return str, nil
+}
+
+// Parse reads a TOML file in the subset understood by cainban (see parse),
+// for other files that share the config syntax. Keys inside a section are
+// returned as "section.key".
+func Parse(r io.Reader) (map[string]interface{}, error) {
+	return parse(bufio.NewScanner(r))
+}
+
+// parse reads the subset of TOML used by cainban: [section] headers and
+// key = value pairs where values are quoted strings, integers or booleans.
+// Keys inside a section are returned as "section.key".
+func parse(scanner *bufio.Scanner) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	section := ""
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: unterminated section header", lineNumber)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section == "" {
+				return nil, fmt.Errorf("line %d: empty section name", lineNumber)
+			}
+			continue
+		}
+
+		key, raw, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		raw = strings.TrimSpace(raw)
+		if key == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNumber)
+		}
+
+		value, err := parseValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		if section != "" {
+			key = section + "." + key
+		}
+		values[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// parseValue converts a raw TOML value into a string, int or bool
+func parseValue(raw string) (interface{}, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case strings.HasPrefix(raw, `"`):
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return nil, fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case raw == "true" || raw == "false":
+		return raw == "true", nil
+	default:
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported value %s", raw)
+		}
+		return value, nil
+	}
+}
+
+// stripComment removes a trailing # comment that is not inside a string
+func stripComment(line string) string {
+	inString := byte(0)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case inString != 0:
+			if c == '\\' && inString == '"' {
+				i++
+			} else if c == inString {
+				inString = 0
+			}
+		case c == '"' || c == '\'':
+			inString = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_MissingFileUsesDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.toml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.DefaultBoard != "default" || cfg.OutputFormat != FormatText || cfg.DefaultPriority != "none" {
+		t.Errorf("Unexpected defaults: %+v", cfg)
+	}
+	// Capping memory breaks runtimes that reserve address space up front
+	if cfg.CommandMemoryMB != 0 {
+		t.Errorf("CommandMemoryMB = %d, want no cap by default", cfg.CommandMemoryMB)
+	}
+}
+
+func TestLoad_ParsesValues(t *testing.T) {
+	path := writeConfig(t, `
+# cainban settings
+default_priority = "medium"
+default_board = 'work'   # trailing comment
+output_format = "json"
+theme = "light"
+editor = "code --wait"
+user = "alice"
+handoff_webhook = "https://hooks.example.com/cainban"
+keymap = "vim"
+busy_timeout = "10s"
+command_timeout = "30s"
+command_memory_mb = 256
+auto_backup = false
+auto_board = true
+keep_backups = 3
+stale_days = 14
+fuzzy_match = false
+sync_dir = "/data/cainban-sync"
+
+[wip_limits]
+doing = 3
+
+[keys]
+down = "j s"
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.DefaultPriority != "medium" {
+		t.Errorf("DefaultPriority = %q, want medium", cfg.DefaultPriority)
+	}
+	if cfg.DefaultBoard != "work" {
+		t.Errorf("DefaultBoard = %q, want work", cfg.DefaultBoard)
+	}
+	if cfg.OutputFormat != FormatJSON {
+		t.Errorf("OutputFormat = %q, want json", cfg.OutputFormat)
+	}
+	if cfg.HandoffWebhook != "https://hooks.example.com/cainban" {
+		t.Errorf("HandoffWebhook = %q", cfg.HandoffWebhook)
+	}
+	if cfg.BusyTimeout != 10*time.Second {
+		t.Errorf("BusyTimeout = %v, want 10s", cfg.BusyTimeout)
+	}
+	if cfg.CommandTimeout != 30*time.Second || cfg.CommandMemoryMB != 256 {
+		t.Errorf("CommandTimeout = %v, CommandMemoryMB = %d, want 30s and 256", cfg.CommandTimeout, cfg.CommandMemoryMB)
+	}
+	if cfg.AutoBackup || cfg.KeepBackups != 3 || !cfg.AutoBoard {
+		t.Errorf("AutoBackup = %v, KeepBackups = %d, AutoBoard = %v, want false, 3 and true", cfg.AutoBackup, cfg.KeepBackups, cfg.AutoBoard)
+	}
+	if cfg.StaleDays != 14 || cfg.FuzzyMatch {
+		t.Errorf("StaleDays = %d, FuzzyMatch = %v, want 14 and false", cfg.StaleDays, cfg.FuzzyMatch)
+	}
+	if cfg.SyncDirectory() != "/data/cainban-sync" {
+		t.Errorf("SyncDirectory() = %q, want /data/cainban-sync", cfg.SyncDirectory())
+	}
+	if cfg.EditorCommand() != "code --wait" {
+		t.Errorf("EditorCommand() = %q, want code --wait", cfg.EditorCommand())
+	}
+	if cfg.UserName() != "alice" {
+		t.Errorf("UserName() = %q, want alice", cfg.UserName())
+	}
+	t.Setenv(UserEnv, "claude")
+	if cfg.UserName() != "claude" {
+		t.Errorf("UserName() = %q, want $%s over the config file", cfg.UserName(), UserEnv)
+	}
+	if cfg.WIPLimit("doing") != 3 || cfg.WIPLimit("todo") != 0 {
+		t.Errorf("WIPLimits = %v, want doing=3", cfg.WIPLimits)
+	}
+	if cfg.Keymap != "vim" || cfg.Keys["down"] != "j s" {
+		t.Errorf("Keymap = %q, Keys = %v, want vim with down rebound", cfg.Keymap, cfg.Keys)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing equals", "theme dark"},
+		{"unterminated section", "[wip_limits"},
+		{"bad output format", `output_format = "xml"`},
+		{"non-string theme", "theme = 3"},
+		{"negative wip limit", "[wip_limits]\ndoing = -1"},
+		{"bad string", `editor = "vim`},
+		{"bad busy timeout", `busy_timeout = "soon"`},
+		{"zero command timeout", `command_timeout = "0s"`},
+		{"negative command memory", `command_memory_mb = -1`},
+		{"negative stale days", `stale_days = -1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(writeConfig(t, tt.content)); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestStripComment(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`theme = "dark" # comment`, `theme = "dark" `},
+		{`editor = "vim # not a comment"`, `editor = "vim # not a comment"`},
+		{`# whole line`, ``},
+	}
+
+	for _, tt := range tests {
+		if got := stripComment(tt.in); got != tt.want {
+			t.Errorf("stripComment(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
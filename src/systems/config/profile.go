@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// ProfileEnv names the environment variable selecting a profile: a config
+// file and boards of its own, so that e.g. personal and employer boards
+// never share storage or credentials. cainban --profile sets it, which
+// passes the profile on to the commands cainban runs itself.
+const ProfileEnv = "CAINBAN_PROFILE"
+
+// profilePattern is what a profile name may look like, so that it always
+// names a directory inside the profiles directory
+var profilePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidateProfile reports whether name can name a profile
+func ValidateProfile(name string) error {
+	if !profilePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile '%s' (use letters, digits, '-' or '_')", name)
+	}
+	return nil
+}
+
+// DefaultProfile names the default profile, the one in use without a
+// profile selected
+const DefaultProfile = "default"
+
+// Profile returns the profile in use, or "" for the default profile
+func Profile() string {
+	if name := os.Getenv(ProfileEnv); name != DefaultProfile {
+		return name
+	}
+	return ""
+}
+
+// Dir returns the directory of the profile in use, which holds its config
+// file and its boards
+func Dir() string {
+	return ProfileDir(Profile())
+}
+
+// ProfileDir returns the directory of a profile: ~/.cainban for the
+// default profile ("" or DefaultProfile) and ~/.cainban/profiles/<name>
+// for the others
+func ProfileDir(name string) string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	dir := filepath.Join(homeDir, ".cainban")
+	if name == "" || name == DefaultProfile {
+		return dir
+	}
+	return filepath.Join(dir, "profiles", name)
+}
+
+// Profiles lists the profiles other than the default one, by name. A
+// profile exists once something has been saved in it.
+func Profiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(ProfileDir(""), "profiles"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && entry.Name() != DefaultProfile && ValidateProfile(entry.Name()) == nil {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(ProfileEnv, "")
+
+	root := filepath.Join(home, ".cainban")
+	if got := DefaultPath(); got != filepath.Join(root, "config.toml") {
+		t.Errorf("Expected the default profile's config in %s, got %s", root, got)
+	}
+
+	t.Setenv(ProfileEnv, "work")
+	workDir := filepath.Join(root, "profiles", "work")
+	if got := Dir(); got != workDir {
+		t.Errorf("Expected the work profile in %s, got %s", workDir, got)
+	}
+	if got := DefaultPath(); got != filepath.Join(workDir, "config.toml") {
+		t.Errorf("Expected the work profile's own config, got %s", got)
+	}
+
+	// The default profile can be named too
+	t.Setenv(ProfileEnv, DefaultProfile)
+	if Profile() != "" || Dir() != root {
+		t.Errorf("Expected %q to select the default profile, got %q in %s", DefaultProfile, Profile(), Dir())
+	}
+
+	if names, err := Profiles(); err != nil || names != nil {
+		t.Errorf("Expected no profiles yet, got %v, %v", names, err)
+	}
+	for _, name := range []string{"work", "personal", "default", ".hidden"} {
+		if err := os.MkdirAll(filepath.Join(root, "profiles", name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := Profiles()
+	if err != nil {
+		t.Fatalf("Profiles() error = %v", err)
+	}
+	if want := []string{"personal", "work"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Profiles() = %v, want %v", names, want)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	for _, name := range []string{"work", "client-a", "home_2"} {
+		if err := ValidateProfile(name); err != nil {
+			t.Errorf("ValidateProfile(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../work", "a/b", "-flag", "."} {
+		if err := ValidateProfile(name); err == nil {
+			t.Errorf("Expected ValidateProfile(%q) to fail", name)
+		}
+	}
+}
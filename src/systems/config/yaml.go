@@ -0,0 +1,243 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a significant line of a YAML file with its indentation
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// ParseYAML reads the subset of YAML used by cainban's mapping files:
+// nested mappings by indentation, block ("- item") and flow ("[a, b]")
+// lists of scalars, and plain, single- or double-quoted scalars. Scalars
+// are returned as strings, lists as []string and mappings as
+// map[string]interface{}; an empty value is the empty string.
+func ParseYAML(r io.Reader) (map[string]interface{}, error) {
+	var lines []yamlLine
+	scanner := bufio.NewScanner(r)
+	number := 0
+	for scanner.Scan() {
+		number++
+		raw := strings.TrimRight(stripYAMLComment(scanner.Text()), " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if text == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: indent with spaces, not tabs", number)
+		}
+		lines = append(lines, yamlLine{number: number, indent: len(raw) - len(text), text: text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	if lines[0].indent != 0 || isYAMLListItem(lines[0].text) {
+		return nil, fmt.Errorf("line %d: expected a top-level key", lines[0].number)
+	}
+
+	values, rest, err := parseYAMLMapping(lines, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("line %d: unexpected indentation", rest[0].number)
+	}
+	return values, nil
+}
+
+// parseYAMLMapping reads the keys at one indentation and returns the lines
+// after them
+func parseYAMLMapping(lines []yamlLine, indent int) (map[string]interface{}, []yamlLine, error) {
+	values := make(map[string]interface{})
+	for len(lines) > 0 && lines[0].indent == indent {
+		line := lines[0]
+		lines = lines[1:]
+		if isYAMLListItem(line.text) {
+			return nil, nil, fmt.Errorf("line %d: list item where a key was expected", line.number)
+		}
+
+		key, raw, err := splitYAMLKey(line.text)
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		if _, exists := values[key]; exists {
+			return nil, nil, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+
+		switch {
+		case raw != "":
+			values[key], err = parseYAMLValue(raw)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: %w", line.number, err)
+			}
+		case len(lines) > 0 && lines[0].indent >= indent && isYAMLListItem(lines[0].text):
+			// A list may sit at the indentation of its key
+			values[key], lines, err = parseYAMLList(lines, lines[0].indent)
+		case len(lines) > 0 && lines[0].indent > indent:
+			values[key], lines, err = parseYAMLMapping(lines, lines[0].indent)
+		default:
+			values[key] = ""
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(lines) > 0 && lines[0].indent > indent {
+		return nil, nil, fmt.Errorf("line %d: unexpected indentation", lines[0].number)
+	}
+	return values, lines, nil
+}
+
+// parseYAMLList reads the "- item" lines at one indentation
+func parseYAMLList(lines []yamlLine, indent int) ([]string, []yamlLine, error) {
+	var items []string
+	for len(lines) > 0 && lines[0].indent == indent && isYAMLListItem(lines[0].text) {
+		line := lines[0]
+		lines = lines[1:]
+		item, err := parseYAMLScalar(strings.TrimSpace(strings.TrimPrefix(line.text, "-")))
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		items = append(items, item)
+	}
+	if len(lines) > 0 && lines[0].indent > indent {
+		return nil, nil, fmt.Errorf("line %d: only lists of plain values are supported", lines[0].number)
+	}
+	return items, lines, nil
+}
+
+// isYAMLListItem reports whether a line is an item of a block list
+func isYAMLListItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits "key: value" at the first colon followed by a space
+// or the end of the line, outside quotes
+func splitYAMLKey(text string) (string, string, error) {
+	end := -1
+	if text[0] == '"' || text[0] == '\'' {
+		end = closingQuote(text)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+	}
+	for i := end + 1; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			key, err := parseYAMLScalar(strings.TrimSpace(text[:i]))
+			if err != nil {
+				return "", "", err
+			}
+			if key == "" {
+				return "", "", fmt.Errorf("missing key")
+			}
+			return key, strings.TrimSpace(text[i+1:]), nil
+		}
+	}
+	return "", "", fmt.Errorf("expected key: value")
+}
+
+// parseYAMLValue converts the value after a key: a flow list or a scalar
+func parseYAMLValue(raw string) (interface{}, error) {
+	if !strings.HasPrefix(raw, "[") {
+		return parseYAMLScalar(raw)
+	}
+	if !strings.HasSuffix(raw, "]") {
+		return nil, fmt.Errorf("unterminated list %s", raw)
+	}
+
+	items := []string{}
+	inner := strings.TrimSpace(raw[1 : len(raw)-1])
+	for inner != "" {
+		end := strings.Index(inner, ",")
+		if inner[0] == '"' || inner[0] == '\'' {
+			closing := closingQuote(inner)
+			if closing < 0 {
+				return nil, fmt.Errorf("unterminated string in %s", raw)
+			}
+			end = strings.Index(inner[closing:], ",")
+			if end >= 0 {
+				end += closing
+			}
+		}
+		if end < 0 {
+			end = len(inner)
+		}
+		item, err := parseYAMLScalar(strings.TrimSpace(inner[:end]))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if end == len(inner) {
+			break
+		}
+		inner = strings.TrimSpace(inner[end+1:])
+	}
+	return items, nil
+}
+
+// parseYAMLScalar unquotes a quoted scalar; plain ones are kept as written
+func parseYAMLScalar(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", raw)
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		if closingQuote(raw) != len(raw)-1 {
+			return "", fmt.Errorf("invalid string %s", raw)
+		}
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	}
+	return raw, nil
+}
+
+// closingQuote returns the index of the quote closing the string that
+// starts text, or -1. In single quotes a doubled quote is an escaped one.
+func closingQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripYAMLComment removes a # comment, which starts a line or follows a
+// space, unless it is inside a quoted scalar
+func stripYAMLComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		// Quotes only open a string at the start of a key, value or item
+		if (c == '"' || c == '\'') && (i == 0 || strings.ContainsRune(" [,", rune(line[i-1]))) {
+			if end := closingQuote(line[i:]); end > 0 {
+				i += end
+				continue
+			}
+		}
+		if c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
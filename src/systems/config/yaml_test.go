@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	input := `# Mapping for the ACME project
+---
+statuses:
+  In Review: doing      # custom workflow state
+  "QA: ready": doing
+  Won't Do: done
+priorities:
+  P0: critical
+  'P1 ''urgent''': 3
+labels:
+  - client-a
+  - "frontend #2"
+tags: [alpha, "b, c", 'd']
+keep_labels: true
+empty:
+`
+	values, err := ParseYAML(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseYAML failed: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"statuses": map[string]interface{}{
+			"In Review": "doing",
+			"QA: ready": "doing",
+			"Won't Do":  "done",
+		},
+		"priorities": map[string]interface{}{
+			"P0":          "critical",
+			"P1 'urgent'": "3",
+		},
+		"labels":      []string{"client-a", "frontend #2"},
+		"tags":        []string{"alpha", "b, c", "d"},
+		"keep_labels": "true",
+		"empty":       "",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("ParseYAML() =\n%#v\nexpected\n%#v", values, expected)
+	}
+}
+
+func TestParseYAML_Errors(t *testing.T) {
+	invalid := map[string]string{
+		"no colon":          "statuses\n",
+		"bad indentation":   "statuses:\n  a: b\n    c: d\n",
+		"tab indentation":   "statuses:\n\ta: b\n",
+		"duplicate key":     "a: 1\na: 2\n",
+		"top-level list":    "- a\n",
+		"nested list items": "labels:\n  - a\n    - b\n",
+		"unterminated list": "tags: [a, b\n",
+		"bad string":        "a: \"unterminated\n",
+	}
+	for name, input := range invalid {
+		if _, err := ParseYAML(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package dateparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHour is the time of day used when only a date is given
+const DefaultHour = 9
+
+// maxRelative bounds relative expressions such as "in 3 weeks"
+const maxRelative = 100 * 365 * 24 * time.Hour
+
+var (
+	relativePattern = regexp.MustCompile(`^in\s+(\d+|an?)\s*([a-z]+)$`)
+	clockPattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+// units maps relative duration words to their length
+var units = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// weekdays maps day names and abbreviations to time.Weekday
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// absoluteLayouts are the explicit date formats accepted, in now's location
+var absoluteLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// Parse interprets a human-friendly time expression relative to now.
+// Supported forms include "in 2 hours", "in 30m", "tomorrow", "tomorrow 9am",
+// "friday 14:30", "next monday", "17:00", "2026-10-20" and
+// "2026-10-20 14:00". Dates without a time default to DefaultHour o'clock.
+func Parse(input string, now time.Time) (time.Time, error) {
+	s := strings.ToLower(strings.Join(strings.Fields(input), " "))
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty time expression")
+	}
+
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(input)); err == nil {
+		return t, nil
+	}
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			if layout == "2006-01-02" {
+				t = atClock(t, DefaultHour, 0)
+			}
+			return t, nil
+		}
+	}
+
+	if m := relativePattern.FindStringSubmatch(s); m != nil {
+		unit, ok := units[m[2]]
+		if !ok {
+			return time.Time{}, fmt.Errorf("unknown time unit %q", m[2])
+		}
+		n := 1
+		if m[1] != "a" && m[1] != "an" {
+			var err error
+			// Past the limit, n * unit would overflow into the past
+			if n, err = strconv.Atoi(m[1]); err != nil || time.Duration(n) > maxRelative/unit {
+				return time.Time{}, fmt.Errorf("%q is too far ahead", input)
+			}
+		}
+		return now.Add(time.Duration(n) * unit), nil
+	}
+
+	// Split "<day> [at] <clock>" into its parts
+	day, clock := s, ""
+	if i := strings.LastIndex(s, " "); i >= 0 && clockPattern.MatchString(s[i+1:]) {
+		day, clock = strings.TrimSuffix(s[:i], " at"), s[i+1:]
+		if day == "at" {
+			day = ""
+		}
+	} else if clockPattern.MatchString(s) && !isBareNumber(s) {
+		day, clock = "", s
+	}
+
+	date, err := parseDay(day, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if clock == "" {
+		return atClock(date, DefaultHour, 0), nil
+	}
+
+	hour, minute, err := parseClock(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t := atClock(date, hour, minute)
+
+	// A bare time that has already passed today means tomorrow
+	if day == "" && !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// atClock returns the given time of day on date's day. Building the time
+// from its parts keeps the wall clock right across daylight saving changes.
+func atClock(date time.Time, hour, minute int) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
+}
+
+// parseDay resolves a day expression to midnight on that day
+func parseDay(day string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch day {
+	case "", "today", "tonight":
+		return today, nil
+	case "tomorrow", "tmr", "tmrw":
+		return today.AddDate(0, 0, 1), nil
+	}
+
+	name := strings.TrimPrefix(day, "next ")
+	if weekday, ok := weekdays[name]; ok {
+		// Always move forward: "friday" on a Friday means next week
+		days := (int(weekday) - int(today.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return today.AddDate(0, 0, days), nil
+	}
+
+	if day == "next week" {
+		return today.AddDate(0, 0, 7), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time expression %q", day)
+}
+
+// parseClock reads "9", "9am", "9:30pm" or "17:30"
+func parseClock(clock string) (int, int, error) {
+	m := clockPattern.FindStringSubmatch(clock)
+	if m == nil {
+		return 0, 0, fmt.Errorf("invalid time of day %q", clock)
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+
+	switch m[3] {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid time of day %q", clock)
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, fmt.Errorf("invalid time of day %q", clock)
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time of day %q", clock)
+	}
+	return hour, minute, nil
+}
+
+// isBareNumber reports whether s is only digits, which is too ambiguous to
+// treat as a time of day on its own
+func isBareNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
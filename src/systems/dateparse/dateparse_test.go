@@ -0,0 +1,89 @@
+package dateparse
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	// Wednesday afternoon
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		input string
+		want  time.Time
+	}{
+		{"in 2 hours", now.Add(2 * time.Hour)},
+		{"in 30m", now.Add(30 * time.Minute)},
+		{"in an hour", now.Add(time.Hour)},
+		{"In 3 Days", now.AddDate(0, 0, 3)},
+		{"in 1 week", now.AddDate(0, 0, 7)},
+		{"tomorrow", at(15, 9, 0)},
+		{"tomorrow 9am", at(15, 9, 0)},
+		{"tomorrow at 14:30", at(15, 14, 30)},
+		{"today 5pm", at(14, 17, 0)},
+		{"at 5pm", at(14, 17, 0)},
+		{"17:45", at(14, 17, 45)},
+		{"9am", at(15, 9, 0)}, // already passed today
+		{"12am", at(15, 0, 0)},
+		{"friday", at(16, 9, 0)},
+		{"next monday 10:00", at(19, 10, 0)},
+		{"wednesday", at(21, 9, 0)}, // a week ahead, never today
+		{"next week", at(21, 9, 0)},
+		{"2026-10-20", at(20, 9, 0)},
+		{"2026-10-20 14:00", at(20, 14, 0)},
+		{"2026-10-20T14:00:00Z", at(20, 14, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input, now)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+
+	for _, input := range []string{"", "someday", "in 2 fortnights", "13pm", "25:00", "9", "tomorrow 99:00", "in 99999999999999999999 weeks"} {
+		if got, err := Parse(input, now); err == nil {
+			t.Errorf("Parse(%q) = %v, want error", input, got)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"in 2 hours", "in an hour", "tomorrow 9am", "friday at 14:30", "next monday",
+		"17:00", "12am", "2026-10-20", "2026-10-20 14:00", "2026-10-20T14:00:00Z",
+		"in 99999999999999999999 weeks", "  Next   WEEK ", "at",
+	} {
+		f.Add(seed)
+	}
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		got, err := Parse(input, now)
+		if err != nil {
+			return
+		}
+		again, err := Parse(input, now)
+		if err != nil || !again.Equal(got) {
+			t.Fatalf("Parse(%q) is not deterministic: %v then %v (%v)", input, got, again, err)
+		}
+		// Everything but an absolute date lies ahead of now
+		if relativePattern.MatchString(strings.ToLower(strings.Join(strings.Fields(input), " "))) && got.Before(now) {
+			t.Errorf("Parse(%q) = %v, before now", input, got)
+		}
+	})
+}
@@ -0,0 +1,249 @@
+package demo
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// Options configure a generated board. The same seed, task count and Now
+// always produce the same board.
+type Options struct {
+	Tasks int
+	Seed  int64
+	// Now anchors the generated history, which spans the 90 days before it
+	Now time.Time
+}
+
+// Summary counts what Generate created
+type Summary struct {
+	Tasks     int `json:"tasks"`
+	Subtasks  int `json:"subtasks"`
+	Links     int `json:"links"`
+	Comments  int `json:"comments"`
+	Reactions int `json:"reactions"`
+	Events    int `json:"events"`
+}
+
+// historyDays is how far back the generated history goes
+const historyDays = 90
+
+var (
+	verbs = []string{
+		"Add", "Fix", "Refactor", "Document", "Test", "Speed up", "Design",
+		"Migrate", "Remove", "Review", "Audit", "Prototype",
+	}
+	subjects = []string{
+		"login form", "password reset", "rate limiter", "search index",
+		"billing webhook", "CSV export", "onboarding emails", "dark mode",
+		"audit log", "session handling", "image uploads", "notification settings",
+		"API pagination", "release pipeline", "error pages", "cache layer",
+		"permissions model", "mobile layout", "feature flags", "usage metrics",
+	}
+	details = []string{
+		"", "", "", " for admins", " on mobile", " in the API", " for teams",
+		" behind a flag", " (v2)",
+	}
+	descriptions = []string{
+		"",
+		"Customers keep asking for this in support tickets.",
+		"Follow-up from the last retro.",
+		"Blocks the next release; keep the scope small.",
+		"See the design doc for the agreed approach.",
+		"Needs a migration and a rollback plan.",
+	}
+	people   = []string{"alice", "bob", "carol", "dave", "erin"}
+	contexts = []string{"@backend", "@frontend", "@ops", "@deep-work", "@quick-win", "@review"}
+	comments = []string{
+		"Started on this, first pass is up for review.",
+		"Blocked on the schema change, picking something else meanwhile.",
+		"Paired on it today, should land tomorrow.",
+		"Added tests for the edge cases we found.",
+		"Can we split this? It is bigger than it looked.",
+		"Deployed to staging.",
+	}
+	emoji = []string{"👍", "🎉", "👀", "🚀", "❤️"}
+)
+
+// Generate fills the board in db with realistic tasks: titles, priorities,
+// estimates, assignees, contexts, due dates, subtasks, blocking links,
+// comments and reactions, plus a status history spread over the last 90
+// days. It is meant for a throwaway board.
+func Generate(db *storage.DB, opts Options) (*Summary, error) {
+	if opts.Tasks < 1 {
+		return nil, fmt.Errorf("number of tasks must be at least 1")
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	r := rand.New(rand.NewSource(opts.Seed))
+	taskSystem := task.New(db.Conn())
+	summary := &Summary{}
+	var created []*task.Task
+
+	for i := 0; i < opts.Tasks; i++ {
+		title := pick(r, verbs) + " " + pick(r, subjects) + pick(r, details)
+		t, err := taskSystem.CreateWithPriority(1, title, pick(r, descriptions), r.Intn(5))
+		if err != nil {
+			return summary, err
+		}
+		created = append(created, t)
+		summary.Tasks++
+
+		if err := decorate(taskSystem, r, t, opts.Now); err != nil {
+			return summary, err
+		}
+
+		// Every few tasks becomes a subtask of a recent top-level task
+		if len(created) > 3 && r.Intn(6) == 0 {
+			parent := created[len(created)-2-r.Intn(3)]
+			if parent.ParentID == nil {
+				if err := taskSystem.SetParent(t.ID, &parent.ID); err != nil {
+					return summary, err
+				}
+				t.ParentID = &parent.ID
+				summary.Subtasks++
+			}
+		}
+
+		events, err := playHistory(db, taskSystem, r, t, opts.Now)
+		if err != nil {
+			return summary, err
+		}
+		summary.Events += events
+
+		for c := r.Intn(4) - 1; c > 0; c-- {
+			if _, err := taskSystem.AddComment(t.ID, pick(r, people), pick(r, comments)); err != nil {
+				return summary, err
+			}
+			summary.Comments++
+		}
+		for c := r.Intn(5) - 2; c > 0; c-- {
+			added, err := taskSystem.React(t.ID, pick(r, people), pick(r, emoji))
+			if err != nil {
+				return summary, err
+			}
+			if added {
+				summary.Reactions++
+			}
+		}
+	}
+
+	// Earlier tasks block a few later ones; links only point forward, so
+	// they never form a cycle
+	for i, t := range created[:len(created)-1] {
+		if r.Intn(8) != 0 {
+			continue
+		}
+		later := created[i+1+r.Intn(len(created)-i-1)]
+		if err := taskSystem.LinkTasks(t.ID, later.ID, task.LinkTypeBlocks); err != nil {
+			return summary, err
+		}
+		summary.Links++
+	}
+
+	return summary, nil
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+// decorate sets the optional fields of a new task
+func decorate(taskSystem *task.System, r *rand.Rand, t *task.Task, now time.Time) error {
+	if r.Intn(3) > 0 {
+		if err := taskSystem.UpdateEstimate(t.ID, []int{1, 2, 3, 5, 8, 13}[r.Intn(6)]); err != nil {
+			return err
+		}
+	}
+	if r.Intn(4) > 0 {
+		if _, err := taskSystem.Assign(t.ID, pick(r, people)); err != nil {
+			return err
+		}
+	}
+	for c := r.Intn(3); c > 0; c-- {
+		if err := taskSystem.AddContext(t.ID, pick(r, contexts)); err != nil {
+			return err
+		}
+	}
+	if r.Intn(2) == 0 {
+		if err := taskSystem.SetSize(t.ID, []task.Size{task.SizeSmall, task.SizeMedium, task.SizeLarge}[r.Intn(3)]); err != nil {
+			return err
+		}
+	}
+	if r.Intn(3) == 0 {
+		if err := taskSystem.SetEnergy(t.ID, []task.Energy{task.EnergyLow, task.EnergyHigh}[r.Intn(2)]); err != nil {
+			return err
+		}
+	}
+	if r.Intn(5) == 0 {
+		// Due dates from two weeks ago (overdue) to four weeks ahead
+		due := now.Truncate(time.Hour).Add(time.Duration(r.Intn(42*24)-14*24) * time.Hour)
+		if err := taskSystem.SetDue(t.ID, &due); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// playHistory moves a task through the columns, about half of them to done,
+// then backdates its creation and status changes to a plausible timeline.
+// It returns the number of events recorded.
+func playHistory(db *storage.DB, taskSystem *task.System, r *rand.Rand, t *task.Task, now time.Time) (int, error) {
+	var path []task.Status
+	switch roll := r.Intn(10); {
+	case roll < 5:
+		path = []task.Status{task.StatusDoing, task.StatusDone}
+	case roll < 7:
+		path = []task.Status{task.StatusDoing}
+	case roll < 8:
+		// Started, put back, and picked up again later
+		path = []task.Status{task.StatusDoing, task.StatusTodo, task.StatusDoing}
+	}
+
+	created := now.Add(-time.Duration(r.Intn(historyDays*24*60)) * time.Minute)
+	timeline := []time.Time{created}
+	at := created
+	for _, status := range path {
+		if err := taskSystem.UpdateStatus(t.ID, status); err != nil {
+			return 0, err
+		}
+		// Each step takes up to a third of the time left until now
+		at = at.Add(time.Duration(r.Int63n(int64(now.Sub(at)/3) + 1)))
+		timeline = append(timeline, at)
+	}
+
+	rows, err := db.Conn().QueryContext(db.Context(), `SELECT id FROM task_events WHERE task_id = ? ORDER BY id`, t.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read task history: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to read task history: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	for i, id := range ids {
+		if i >= len(timeline) {
+			break
+		}
+		if _, err := db.Conn().ExecContext(db.Context(), `UPDATE task_events SET created_at = ? WHERE id = ?`, timeline[i].UTC(), id); err != nil {
+			return 0, fmt.Errorf("failed to backdate task history: %w", err)
+		}
+	}
+	_, err = db.Conn().ExecContext(db.Context(), `UPDATE tasks SET created_at = ?, updated_at = ? WHERE id = ?`,
+		created.UTC(), timeline[len(timeline)-1].UTC(), t.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to backdate task: %w", err)
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,96 @@
+package demo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// generate fills a fresh in-memory board and returns its tasks
+func generate(t *testing.T, opts Options) (*Summary, *task.System, []*task.Task) {
+	t.Helper()
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	summary, err := Generate(db, opts)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	taskSystem := task.New(db.Conn())
+	tasks, err := taskSystem.List(1)
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %v", err)
+	}
+	return summary, taskSystem, tasks
+}
+
+func TestGenerate(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	opts := Options{Tasks: 60, Seed: 7, Now: now}
+
+	summary, taskSystem, tasks := generate(t, opts)
+	if summary.Tasks != 60 || len(tasks) != 60 {
+		t.Fatalf("Expected 60 tasks, summary says %d and the board has %d", summary.Tasks, len(tasks))
+	}
+	if summary.Links == 0 || summary.Subtasks == 0 || summary.Comments == 0 {
+		t.Errorf("Expected links, subtasks and comments, got %+v", summary)
+	}
+
+	statuses := make(map[task.Status]int)
+	for _, tk := range tasks {
+		statuses[tk.Status]++
+		if tk.CreatedAt.After(now) || tk.CreatedAt.Before(now.AddDate(0, 0, -historyDays)) {
+			t.Errorf("Task #%d created at %v, outside the history window", tk.ID, tk.CreatedAt)
+		}
+
+		history, err := taskSystem.GetHistory(tk.ID)
+		if err != nil {
+			t.Fatalf("Failed to get history: %v", err)
+		}
+		if len(history) == 0 || history[0].Type != task.EventCreated {
+			t.Fatalf("Task #%d: expected the history to start with its creation, got %+v", tk.ID, history)
+		}
+		if last := history[len(history)-1]; last.Type == task.EventStatusChanged && last.ToStatus != tk.Status {
+			t.Errorf("Task #%d is %s but its history ends in %s", tk.ID, tk.Status, last.ToStatus)
+		}
+	}
+	for _, status := range []task.Status{task.StatusTodo, task.StatusDoing, task.StatusDone} {
+		if statuses[status] == 0 {
+			t.Errorf("Expected some %s tasks, got %v", status, statuses)
+		}
+	}
+
+	// The same options give the same board
+	again, _, tasksAgain := generate(t, opts)
+	if !reflect.DeepEqual(summary, again) {
+		t.Errorf("Summaries differ: %+v vs %+v", summary, again)
+	}
+	for i := range tasks {
+		a, b := tasks[i], tasksAgain[i]
+		if a.Title != b.Title || a.Status != b.Status || a.Priority != b.Priority || a.Assignee != b.Assignee || !a.CreatedAt.Equal(b.CreatedAt) {
+			t.Fatalf("Task #%d differs between runs: %+v vs %+v", a.ID, a, b)
+		}
+	}
+
+	// Another seed gives another board
+	_, _, other := generate(t, Options{Tasks: 60, Seed: 8, Now: now})
+	same := 0
+	for i := range tasks {
+		if tasks[i].Title == other[i].Title {
+			same++
+		}
+	}
+	if same == len(tasks) {
+		t.Error("Expected a different seed to generate different tasks")
+	}
+
+	if _, err := Generate(nil, Options{Tasks: 0}); err == nil {
+		t.Error("Expected an error for zero tasks")
+	}
+}
@@ -0,0 +1,223 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// FileChange is the diffstat of one file in a commit
+type FileChange struct {
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+	Binary  bool   `json:"binary,omitempty"`
+}
+
+// CommitDetail is a commit together with the files it changed
+type CommitDetail struct {
+	Commit
+	Files []FileChange `json:"files"`
+}
+
+// Show reads a single commit and its diffstat. Merge commits are diffed
+// against their first parent, so a merged pull request shows everything it
+// brought in.
+func (r *Repo) Show(hash string) (*CommitDetail, error) {
+	const (
+		fieldSep  = "\x1f"
+		recordSep = "\x1e"
+	)
+
+	out, err := r.run("show", "--numstat", "--no-renames", "--diff-merges=first-parent",
+		"--format=%H"+fieldSep+"%an"+fieldSep+"%aI"+fieldSep+"%s"+fieldSep+"%b"+recordSep, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", hash, err)
+	}
+
+	header, stat, _ := strings.Cut(out, recordSep)
+	fields := strings.SplitN(header, fieldSep, 5)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("failed to parse commit %s", hash)
+	}
+
+	date, _ := time.Parse(time.RFC3339, fields[2])
+	detail := &CommitDetail{Commit: Commit{
+		Hash:    fields[0],
+		Author:  fields[1],
+		Date:    date,
+		Subject: fields[3],
+		Body:    strings.TrimSpace(fields[4]),
+	}}
+
+	for _, line := range strings.Split(strings.TrimSpace(stat), "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		change := FileChange{Path: parts[2]}
+		if parts[0] == "-" {
+			change.Binary = true
+		} else {
+			change.Added, _ = strconv.Atoi(parts[0])
+			change.Deleted, _ = strconv.Atoi(parts[1])
+		}
+		detail.Files = append(detail.Files, change)
+	}
+
+	return detail, nil
+}
+
+// summaryMaxFiles caps the file list of a summary
+const summaryMaxFiles = 10
+
+// Summarize renders a Markdown section describing what a set of commits
+// shipped: one line per commit with its message, then the files touched
+func Summarize(commits []CommitDetail, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## What shipped (%s)\n\n", now.Format("2006-01-02"))
+
+	totals := make(map[string]*FileChange)
+	var added, deleted int
+
+	for _, c := range commits {
+		var commitAdded, commitDeleted int
+		for _, f := range c.Files {
+			commitAdded += f.Added
+			commitDeleted += f.Deleted
+
+			total, ok := totals[f.Path]
+			if !ok {
+				total = &FileChange{Path: f.Path}
+				totals[f.Path] = total
+			}
+			total.Added += f.Added
+			total.Deleted += f.Deleted
+			total.Binary = total.Binary || f.Binary
+		}
+		added += commitAdded
+		deleted += commitDeleted
+
+		fmt.Fprintf(&b, "- %s %s (%s, +%d -%d)\n", c.ShortHash(), c.Subject, plural(len(c.Files), "file"), commitAdded, commitDeleted)
+		for _, line := range descriptionLines(c.Body) {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	files := make([]*FileChange, 0, len(totals))
+	for _, f := range totals {
+		files = append(files, f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		ci, cj := files[i].Added+files[i].Deleted, files[j].Added+files[j].Deleted
+		if ci != cj {
+			return ci > cj
+		}
+		return files[i].Path < files[j].Path
+	})
+
+	if len(files) > 0 {
+		b.WriteString("\nFiles:\n")
+		for i, f := range files {
+			if i == summaryMaxFiles {
+				fmt.Fprintf(&b, "- ... and %d more\n", len(files)-summaryMaxFiles)
+				break
+			}
+			if f.Binary {
+				fmt.Fprintf(&b, "- %s (binary)\n", f.Path)
+			} else {
+				fmt.Fprintf(&b, "- %s (+%d -%d)\n", f.Path, f.Added, f.Deleted)
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "\nTotal: %s, %s, +%d -%d\n", plural(len(commits), "commit"), plural(len(files), "file"), added, deleted)
+	return b.String()
+}
+
+// descriptionLines returns the first paragraph of a commit body, such as a
+// pull request description in a merge commit, without cainban trailers
+func descriptionLines(body string) []string {
+	paragraph, _, _ := strings.Cut(body, "\n\n")
+
+	var lines []string
+	for _, line := range strings.Split(paragraph, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || trailerPattern.MatchString(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// EnrichResult describes what Enrich added to a task
+type EnrichResult struct {
+	Task    *task.Task     `json:"task"`
+	Commits []CommitDetail `json:"commits"`
+	Summary string         `json:"summary"`
+}
+
+// Enrich appends a summary of the task's linked commits to its description.
+// Commits already mentioned in the description are skipped, so running it
+// again only adds what shipped since. The result has no commits when there
+// was nothing new to add.
+func (s *System) Enrich(repo *Repo, taskSystem *task.System, taskID int, now time.Time) (*EnrichResult, error) {
+	t, err := taskSystem.GetByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := s.ListRefs(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EnrichResult{Task: t}
+	for _, ref := range refs {
+		if ref.Kind != RefCommit {
+			continue
+		}
+		detail, err := repo.Show(ref.Ref)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(t.Description, detail.ShortHash()) {
+			continue
+		}
+		result.Commits = append(result.Commits, *detail)
+	}
+
+	if len(result.Commits) == 0 {
+		return result, nil
+	}
+
+	// Oldest first reads like a changelog
+	sort.SliceStable(result.Commits, func(i, j int) bool {
+		return result.Commits[i].Date.Before(result.Commits[j].Date)
+	})
+
+	result.Summary = Summarize(result.Commits, now)
+	description := result.Summary
+	if existing := strings.TrimRight(t.Description, "\n"); existing != "" {
+		description = existing + "\n\n" + result.Summary
+	}
+
+	if err := taskSystem.Update(t.ID, t.Title, description); err != nil {
+		return nil, err
+	}
+	t.Description = description
+
+	return result, nil
+}
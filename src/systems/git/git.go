@@ -0,0 +1,240 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reference is a mention of a task in a commit message
+type Reference struct {
+	TaskID int  `json:"task_id"`
+	Closes bool `json:"closes"`
+}
+
+var (
+	// trailerPattern matches "cainban:#42" and "Cainban: #42" trailers
+	trailerPattern = regexp.MustCompile(`(?i)\bcainban:\s*#(\d+)`)
+
+	// closingPattern matches pull request language such as "closes #42"
+	closingPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s+#(\d+)`)
+)
+
+// ParseReferences extracts the tasks a commit message refers to, sorted by
+// task ID. A task mentioned with closing language is marked as closed.
+func ParseReferences(message string) []Reference {
+	refs := make(map[int]bool)
+
+	for _, match := range trailerPattern.FindAllStringSubmatch(message, -1) {
+		if id, err := strconv.Atoi(match[1]); err == nil {
+			if _, seen := refs[id]; !seen {
+				refs[id] = false
+			}
+		}
+	}
+	for _, match := range closingPattern.FindAllStringSubmatch(message, -1) {
+		if id, err := strconv.Atoi(match[1]); err == nil {
+			refs[id] = true
+		}
+	}
+
+	result := make([]Reference, 0, len(refs))
+	for id, closes := range refs {
+		result = append(result, Reference{TaskID: id, Closes: closes})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].TaskID < result[j].TaskID })
+
+	return result
+}
+
+// BranchName derives a branch name such as "task/42-fix-login-bug" from a task
+func BranchName(taskID int, title string) string {
+	const maxSlug = 40
+
+	var slug strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			slug.WriteRune(r)
+			lastDash = false
+		} else if !lastDash {
+			slug.WriteRune('-')
+			lastDash = true
+		}
+	}
+
+	name := strings.Trim(slug.String(), "-")
+	if len(name) > maxSlug {
+		name = strings.TrimRight(name[:maxSlug], "-")
+	}
+
+	if name == "" {
+		return fmt.Sprintf("task/%d", taskID)
+	}
+	return fmt.Sprintf("task/%d-%s", taskID, name)
+}
+
+// Commit is a commit read from the repository log
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+// ShortHash returns the abbreviated commit hash
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// Message returns the full commit message
+func (c Commit) Message() string {
+	if c.Body == "" {
+		return c.Subject
+	}
+	return c.Subject + "\n\n" + c.Body
+}
+
+// Repo runs git commands in a working directory
+type Repo struct {
+	dir string
+}
+
+// Open returns the repository containing dir
+func Open(dir string) (*Repo, error) {
+	repo := &Repo{dir: dir}
+	top, err := repo.run("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %w", err)
+	}
+	repo.dir = top
+	return repo, nil
+}
+
+// Dir returns the top-level directory of the repository
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// CreateBranch creates a branch from HEAD and checks it out, or just checks
+// it out when it already exists. It reports whether the branch was created.
+func (r *Repo) CreateBranch(name string) (bool, error) {
+	if _, err := r.run("rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+		if _, err := r.run("checkout", name); err != nil {
+			return false, fmt.Errorf("failed to check out branch: %w", err)
+		}
+		return false, nil
+	}
+
+	if _, err := r.run("checkout", "-b", name); err != nil {
+		return false, fmt.Errorf("failed to create branch: %w", err)
+	}
+	return true, nil
+}
+
+// Log returns up to limit commits reachable from HEAD, newest first.
+// A limit of zero returns the whole history.
+func (r *Repo) Log(limit int) ([]Commit, error) {
+	const (
+		fieldSep  = "\x1f"
+		recordSep = "\x1e"
+	)
+
+	args := []string{"log", "--format=%H" + fieldSep + "%an" + fieldSep + "%aI" + fieldSep + "%s" + fieldSep + "%b" + recordSep}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", limit))
+	}
+
+	out, err := r.run(args...)
+	if err != nil {
+		// A repository without commits has no log rather than an error
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read git log: %w", err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(out, recordSep) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, fieldSep, 5)
+		if len(fields) != 5 {
+			continue
+		}
+
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commits = append(commits, Commit{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Date:    date,
+			Subject: fields[3],
+			Body:    strings.TrimSpace(fields[4]),
+		})
+	}
+
+	return commits, nil
+}
+
+// InstallHook writes a post-commit hook that runs the given command after
+// every commit. An existing hook that was not written by cainban is kept.
+func (r *Repo) InstallHook(command string) (string, error) {
+	hooksDir, err := r.run("rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("failed to locate hooks directory: %w", err)
+	}
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(r.dir, hooksDir)
+	}
+
+	return writeHook(filepath.Join(hooksDir, "post-commit"), command)
+}
+
+// hookMarker identifies hooks written by cainban so they can be updated
+const hookMarker = "# installed by cainban"
+
+// writeHook writes an executable shell hook running command
+func writeHook(path, command string) (string, error) {
+	if data, err := os.ReadFile(path); err == nil && !strings.Contains(string(data), hookMarker) {
+		return "", fmt.Errorf("%s already exists; add '%s' to it manually", path, command)
+	}
+
+	content := "#!/bin/sh\n" + hookMarker + "\n" + command + "\n"
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		return "", fmt.Errorf("failed to write hook: %w", err)
+	}
+	return path, nil
+}
+
+// run executes git in the repository and returns trimmed standard output
+func (r *Repo) run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
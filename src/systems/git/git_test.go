@@ -0,0 +1,236 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestParseReferences(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []Reference
+	}{
+		{"no references", "Refactor parser", []Reference{}},
+		{"trailer", "Add login form\n\ncainban:#42", []Reference{{TaskID: 42}}},
+		{"trailer with space", "Add login form\n\nCainban: #7", []Reference{{TaskID: 7}}},
+		{"closing keyword", "Fix crash, closes #3", []Reference{{TaskID: 3, Closes: true}}},
+		{"fixes and resolves", "Fixes #1 and resolved #2", []Reference{{TaskID: 1, Closes: true}, {TaskID: 2, Closes: true}}},
+		{"trailer and close", "cainban:#5\ncainban:#6\nCloses #6", []Reference{{TaskID: 5}, {TaskID: 6, Closes: true}}},
+		{"plain hash is ignored", "See #9 for context", []Reference{}},
+		{"word boundary", "prefixes #4", []Reference{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseReferences(tt.message)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseReferences(%q) = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBranchName(t *testing.T) {
+	tests := []struct {
+		id    int
+		title string
+		want  string
+	}{
+		{42, "Fix login bug", "task/42-fix-login-bug"},
+		{7, "  Add OAuth2 (Google) support!  ", "task/7-add-oauth2-google-support"},
+		{3, "!!!", "task/3"},
+		{9, "A very long task title that keeps going well past the limit", "task/9-a-very-long-task-title-that-keeps-going"},
+	}
+
+	for _, tt := range tests {
+		if got := BranchName(tt.id, tt.title); got != tt.want {
+			t.Errorf("BranchName(%d, %q) = %q, want %q", tt.id, tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestTaskRefs(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	refSystem := New(db.Conn())
+	created, _ := task.New(db.Conn()).Create(1, "Task", "")
+
+	added, err := refSystem.AddRef(created.ID, RefCommit, "abc123", "Add login")
+	if err != nil || !added {
+		t.Fatalf("Failed to add ref: added=%v err=%v", added, err)
+	}
+	added, err = refSystem.AddRef(created.ID, RefCommit, "abc123", "Add login")
+	if err != nil || added {
+		t.Errorf("Expected duplicate ref to be ignored: added=%v err=%v", added, err)
+	}
+	if _, err := refSystem.AddRef(999, RefBranch, "task/999", ""); err == nil {
+		t.Error("Expected error linking missing task")
+	}
+
+	refs, err := refSystem.ListRefs(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to list refs: %v", err)
+	}
+	if len(refs) != 1 || refs[0].Ref != "abc123" || refs[0].Kind != RefCommit {
+		t.Errorf("Unexpected refs: %+v", refs)
+	}
+}
+
+func TestRepoBranchAndLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	gitCmd := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	gitCmd("init", "-q")
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+
+	commits, err := repo.Log(0)
+	if err != nil || len(commits) != 0 {
+		t.Fatalf("Expected empty log, got %v, %v", commits, err)
+	}
+
+	gitCmd("commit", "-q", "--allow-empty", "-m", "Initial commit")
+	gitCmd("commit", "-q", "--allow-empty", "-m", "Add login form", "-m", "cainban:#42")
+
+	created, err := repo.CreateBranch("task/42-login")
+	if err != nil || !created {
+		t.Fatalf("Failed to create branch: created=%v err=%v", created, err)
+	}
+	created, err = repo.CreateBranch("task/42-login")
+	if err != nil || created {
+		t.Errorf("Expected existing branch to be checked out: created=%v err=%v", created, err)
+	}
+
+	commits, err = repo.Log(0)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("Expected 2 commits, got %d", len(commits))
+	}
+	if commits[0].Subject != "Add login form" || commits[0].Body != "cainban:#42" || commits[0].Author != "Test" {
+		t.Errorf("Unexpected newest commit: %+v", commits[0])
+	}
+
+	limited, _ := repo.Log(1)
+	if len(limited) != 1 {
+		t.Errorf("Expected 1 commit with limit, got %d", len(limited))
+	}
+
+	path, err := repo.InstallHook("cainban git sync")
+	if err != nil {
+		t.Fatalf("Failed to install hook: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "cainban git sync") || filepath.Base(path) != "post-commit" {
+		t.Errorf("Unexpected hook %s:\n%s", path, data)
+	}
+
+	// Foreign hooks are left alone
+	os.WriteFile(path, []byte("#!/bin/sh\necho custom\n"), 0755)
+	if _, err := repo.InstallHook("cainban git sync"); err == nil {
+		t.Error("Expected error overwriting a foreign hook")
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	gitCmd := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	gitCmd("init", "-q")
+	os.WriteFile(filepath.Join(dir, "login.go"), []byte("package login\n\nfunc Login() {}\n"), 0644)
+	gitCmd("add", ".")
+	gitCmd("commit", "-q", "-m", "Add login handler", "-m", "Checks the password hash.\n\ncainban:#1")
+	first := gitCmd("rev-parse", "HEAD")
+
+	repo, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Failed to open repo: %v", err)
+	}
+
+	detail, err := repo.Show(first)
+	if err != nil {
+		t.Fatalf("Failed to show commit: %v", err)
+	}
+	if len(detail.Files) != 1 || detail.Files[0].Path != "login.go" || detail.Files[0].Added != 3 {
+		t.Errorf("Unexpected diffstat: %+v", detail.Files)
+	}
+
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	refSystem := New(db.Conn())
+	created, _ := taskSystem.Create(1, "Login", "Users can log in")
+	refSystem.AddRef(created.ID, RefCommit, first, "Add login handler")
+
+	result, err := refSystem.Enrich(repo, taskSystem, created.ID, time.Now())
+	if err != nil {
+		t.Fatalf("Failed to enrich task: %v", err)
+	}
+	if len(result.Commits) != 1 {
+		t.Fatalf("Expected 1 summarized commit, got %d", len(result.Commits))
+	}
+
+	got, _ := taskSystem.GetByID(created.ID)
+	for _, want := range []string{"Users can log in\n\n## What shipped", first[:7] + " Add login handler", "Checks the password hash.", "login.go (+3 -0)"} {
+		if !strings.Contains(got.Description, want) {
+			t.Errorf("Description missing %q:\n%s", want, got.Description)
+		}
+	}
+	if strings.Contains(got.Description, "cainban:#1") {
+		t.Errorf("Description should not include trailers:\n%s", got.Description)
+	}
+
+	// Nothing new to add the second time
+	result, err = refSystem.Enrich(repo, taskSystem, created.ID, time.Now())
+	if err != nil || len(result.Commits) != 0 {
+		t.Errorf("Expected no new commits, got %d (%v)", len(result.Commits), err)
+	}
+}
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RefKind identifies what a task reference points at
+type RefKind string
+
+const (
+	RefBranch RefKind = "branch"
+	RefCommit RefKind = "commit"
+)
+
+// TaskRef links a task to a branch or commit
+type TaskRef struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	Kind      RefKind   `json:"kind"`
+	Ref       string    `json:"ref"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// System stores the branches and commits linked to tasks
+type System struct {
+	db  *sql.DB
+	ctx context.Context
+}
+
+// New creates a new git reference system
+func New(db *sql.DB) *System {
+	return &System{db: db, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the system whose queries run under ctx
+func (s *System) WithContext(ctx context.Context) *System {
+	copy := *s
+	copy.ctx = ctx
+	return &copy
+}
+
+// AddRef links a task to a branch or commit. It reports whether the link is
+// new; linking the same ref twice is not an error.
+func (s *System) AddRef(taskID int, kind RefKind, ref, title string) (bool, error) {
+	result, err := s.db.ExecContext(s.ctx, `
+		INSERT OR IGNORE INTO task_refs (task_id, kind, ref, title) VALUES (?, ?, ?, ?)
+	`, taskID, kind, ref, title)
+	if err != nil {
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return false, fmt.Errorf("task with ID %d not found", taskID)
+		}
+		return false, fmt.Errorf("failed to link %s: %w", kind, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check link result: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ListRefs returns the refs linked to a task, oldest first
+func (s *System) ListRefs(taskID int) ([]TaskRef, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, task_id, kind, ref, COALESCE(title, ''), created_at
+		FROM task_refs WHERE task_id = ? ORDER BY created_at ASC, id ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task refs: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []TaskRef
+	for rows.Next() {
+		var ref TaskRef
+		if err := rows.Scan(&ref.ID, &ref.TaskID, &ref.Kind, &ref.Ref, &ref.Title, &ref.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task ref: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating task refs: %w", err)
+	}
+
+	return refs, nil
+}
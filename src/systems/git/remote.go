@@ -0,0 +1,141 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Init returns the repository at dir, creating the directory and an empty
+// repository there when there is none yet
+func Init(dir string) (*Repo, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	repo := &Repo{dir: dir}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		if _, err := repo.run("init", "--quiet"); err != nil {
+			return nil, fmt.Errorf("failed to create repository: %w", err)
+		}
+	}
+	return Open(dir)
+}
+
+// Clone clones the repository at url into dir, which must not exist yet
+func Clone(url, dir string) (*Repo, error) {
+	parent := &Repo{dir: filepath.Dir(dir)}
+	if err := os.MkdirAll(parent.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", parent.dir, err)
+	}
+	if _, err := parent.run("clone", "--quiet", url, dir); err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+	return Open(dir)
+}
+
+// Upstream returns the remote the repository syncs with, origin or else
+// the first one, and the branch checked out. The remote is "" when the
+// repository has none.
+func (r *Repo) Upstream() (remote, branch string, err error) {
+	branch, err = r.run("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", "", fmt.Errorf("no branch is checked out: %w", err)
+	}
+
+	out, err := r.run("remote")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list remotes: %w", err)
+	}
+	remotes := strings.Fields(out)
+	for _, name := range remotes {
+		if name == "origin" {
+			return name, branch, nil
+		}
+	}
+	if len(remotes) > 0 {
+		return remotes[0], branch, nil
+	}
+	return "", branch, nil
+}
+
+// Fetch brings the branches of remote up to date
+func (r *Repo) Fetch(remote string) error {
+	if _, err := r.run("fetch", "--quiet", remote); err != nil {
+		return fmt.Errorf("failed to fetch from %s: %w", remote, err)
+	}
+	return nil
+}
+
+// HasRevision reports whether rev names a commit, such as a remote branch
+// that has been fetched
+func (r *Repo) HasRevision(rev string) bool {
+	_, err := r.run("rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	return err == nil
+}
+
+// ReadFile returns the content of the file at path, relative to the top of
+// the repository, as of rev. It reports false when rev or the file does not
+// exist, as in a repository without commits.
+func (r *Repo) ReadFile(rev, path string) ([]byte, bool, error) {
+	object := rev + ":" + filepath.ToSlash(path)
+	if _, err := r.run("cat-file", "-e", object); err != nil {
+		return nil, false, nil
+	}
+	out, err := r.run("show", object)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", object, err)
+	}
+	return []byte(out), true, nil
+}
+
+// MergeOurs merges rev into the branch checked out, taking our side of any
+// conflicting change. A merge that still fails, as when one side deleted a
+// file the other changed, is undone.
+func (r *Repo) MergeOurs(rev string) error {
+	if _, err := r.run("merge", "--quiet", "--no-edit", "--allow-unrelated-histories", "-X", "ours", rev); err != nil {
+		_, _ = r.run("merge", "--abort")
+		return fmt.Errorf("failed to merge %s: %w", rev, err)
+	}
+	return nil
+}
+
+// GitPath returns where name is kept inside the repository's git
+// directory, for files that belong to this clone only
+func (r *Repo) GitPath(name string) (string, error) {
+	path, err := r.run("rev-parse", "--git-path", name)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate git directory: %w", err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.dir, path)
+	}
+	return path, nil
+}
+
+// Commit commits the files at paths, relative to the top of the
+// repository, with message. It reports false, and commits nothing, when
+// they have not changed.
+func (r *Repo) Commit(message string, paths ...string) (bool, error) {
+	args := append([]string{"add", "--"}, paths...)
+	if _, err := r.run(args...); err != nil {
+		return false, fmt.Errorf("failed to stage files: %w", err)
+	}
+	args = append([]string{"diff", "--cached", "--quiet", "--"}, paths...)
+	if _, err := r.run(args...); err == nil {
+		return false, nil
+	}
+	args = append([]string{"commit", "--quiet", "-m", message, "--"}, paths...)
+	if _, err := r.run(args...); err != nil {
+		return false, fmt.Errorf("failed to commit: %w", err)
+	}
+	return true, nil
+}
+
+// Push pushes branch to remote, setting it as the branch's upstream
+func (r *Repo) Push(remote, branch string) error {
+	if _, err := r.run("push", "--quiet", "--set-upstream", remote, branch); err != nil {
+		return fmt.Errorf("failed to push to %s: %w", remote, err)
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package goal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Goal is an objective that key results and their tasks roll up into
+type Goal struct {
+	ID          int          `json:"id"`
+	BoardID     int          `json:"board_id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	KeyResults  []*KeyResult `json:"key_results"`
+	Progress    float64      `json:"progress"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// KeyResult is a measurable outcome of a goal. It is either tracked against
+// a numeric target or, when no target is set, by the tasks linked to it and
+// those of its milestone.
+type KeyResult struct {
+	ID          int     `json:"id"`
+	GoalID      int     `json:"goal_id"`
+	Title       string  `json:"title"`
+	Target      int     `json:"target"`
+	Current     int     `json:"current"`
+	MilestoneID *int    `json:"milestone_id,omitempty"`
+	Milestone   string  `json:"milestone,omitempty"` // the milestone's name
+	TasksTotal  int     `json:"tasks_total"`
+	TasksDone   int     `json:"tasks_done"`
+	Progress    float64 `json:"progress"`
+}
+
+// computeProgress sets the key result's progress as a fraction from 0 to 1
+func (kr *KeyResult) computeProgress() {
+	switch {
+	case kr.Target > 0:
+		kr.Progress = float64(kr.Current) / float64(kr.Target)
+	case kr.TasksTotal > 0:
+		kr.Progress = float64(kr.TasksDone) / float64(kr.TasksTotal)
+	default:
+		kr.Progress = 0
+	}
+	if kr.Progress > 1 {
+		kr.Progress = 1
+	}
+	if kr.Progress < 0 {
+		kr.Progress = 0
+	}
+}
+
+// System handles goal operations
+type System struct {
+	db  *sql.DB
+	ctx context.Context
+}
+
+// New creates a new goal system
+func New(db *sql.DB) *System {
+	return &System{db: db, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the system whose queries run under ctx
+func (s *System) WithContext(ctx context.Context) *System {
+	copy := *s
+	copy.ctx = ctx
+	return &copy
+}
+
+// Create adds a goal to a board
+func (s *System) Create(boardID int, title, description string) (*Goal, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, fmt.Errorf("goal title cannot be empty")
+	}
+
+	query := `
+		INSERT INTO goals (board_id, title, description)
+		VALUES (?, ?, ?)
+		RETURNING id, created_at, updated_at
+	`
+
+	goal := Goal{BoardID: boardID, Title: title, Description: description}
+	err := s.db.QueryRowContext(s.ctx, query, boardID, title, description).Scan(&goal.ID, &goal.CreatedAt, &goal.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create goal: %w", err)
+	}
+
+	return &goal, nil
+}
+
+// Delete removes a goal together with its key results
+func (s *System) Delete(id int) error {
+	result, err := s.db.ExecContext(s.ctx, `DELETE FROM goals WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal: %w", err)
+	}
+	return expectOneRow(result, "goal", id)
+}
+
+// AddKeyResult adds a key result to a goal. A target of zero means progress
+// is measured by the linked tasks instead of a number.
+func (s *System) AddKeyResult(goalID int, title string, target int) (*KeyResult, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, fmt.Errorf("key result title cannot be empty")
+	}
+	if target < 0 {
+		return nil, fmt.Errorf("target cannot be negative")
+	}
+
+	var exists int
+	if err := s.db.QueryRowContext(s.ctx, `SELECT COUNT(*) FROM goals WHERE id = ?`, goalID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check goal: %w", err)
+	}
+	if exists == 0 {
+		return nil, fmt.Errorf("goal with ID %d not found", goalID)
+	}
+
+	kr := KeyResult{GoalID: goalID, Title: title, Target: target}
+	err := s.db.QueryRowContext(s.ctx, `
+		INSERT INTO key_results (goal_id, title, target) VALUES (?, ?, ?)
+		RETURNING id
+	`, goalID, title, target).Scan(&kr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key result: %w", err)
+	}
+
+	return &kr, nil
+}
+
+// DeleteKeyResult removes a key result; linked tasks are left untouched
+func (s *System) DeleteKeyResult(id int) error {
+	result, err := s.db.ExecContext(s.ctx, `DELETE FROM key_results WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete key result: %w", err)
+	}
+	return expectOneRow(result, "key result", id)
+}
+
+// SetProgress records the current value of a numeric key result
+func (s *System) SetProgress(keyResultID, current int) error {
+	result, err := s.db.ExecContext(s.ctx, `
+		UPDATE key_results SET current = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, current, keyResultID)
+	if err != nil {
+		return fmt.Errorf("failed to update key result: %w", err)
+	}
+	return expectOneRow(result, "key result", keyResultID)
+}
+
+// LinkTask rolls a task up into a key result
+func (s *System) LinkTask(keyResultID, taskID int) error {
+	_, err := s.db.ExecContext(s.ctx, `
+		INSERT OR IGNORE INTO key_result_tasks (key_result_id, task_id) VALUES (?, ?)
+	`, keyResultID, taskID)
+	if err != nil {
+		if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			return fmt.Errorf("key result %d or task %d does not exist", keyResultID, taskID)
+		}
+		return fmt.Errorf("failed to link task: %w", err)
+	}
+	return nil
+}
+
+// SetMilestone rolls the tasks of a milestone up into a key result, as
+// they are planned for it, or stops with a nil milestoneID. A key result
+// takes one milestone at most.
+func (s *System) SetMilestone(keyResultID int, milestoneID *int) error {
+	if milestoneID != nil {
+		var exists int
+		if err := s.db.QueryRowContext(s.ctx, `SELECT COUNT(*) FROM milestones WHERE id = ?`, *milestoneID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check milestone: %w", err)
+		}
+		if exists == 0 {
+			return fmt.Errorf("milestone with ID %d not found", *milestoneID)
+		}
+	}
+
+	result, err := s.db.ExecContext(s.ctx, `
+		UPDATE key_results SET milestone_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, milestoneID, keyResultID)
+	if err != nil {
+		return fmt.Errorf("failed to update key result: %w", err)
+	}
+	return expectOneRow(result, "key result", keyResultID)
+}
+
+// UnlinkTask removes a task from a key result
+func (s *System) UnlinkTask(keyResultID, taskID int) error {
+	result, err := s.db.ExecContext(s.ctx, `
+		DELETE FROM key_result_tasks WHERE key_result_id = ? AND task_id = ?
+	`, keyResultID, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to unlink task: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check unlink result: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("task %d is not linked to key result %d", taskID, keyResultID)
+	}
+	return nil
+}
+
+// List returns a board's goals with their key results and progress
+func (s *System) List(boardID int) ([]*Goal, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, board_id, title, COALESCE(description, ''), created_at, updated_at
+		FROM goals WHERE board_id = ? ORDER BY created_at ASC, id ASC
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list goals: %w", err)
+	}
+
+	var goals []*Goal
+	byID := make(map[int]*Goal)
+	for rows.Next() {
+		var goal Goal
+		if err := rows.Scan(&goal.ID, &goal.BoardID, &goal.Title, &goal.Description, &goal.CreatedAt, &goal.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan goal: %w", err)
+		}
+		goals = append(goals, &goal)
+		byID[goal.ID] = &goal
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating goals: %w", err)
+	}
+
+	keyResults, err := s.keyResults(boardID)
+	if err != nil {
+		return nil, err
+	}
+	for _, kr := range keyResults {
+		if goal, ok := byID[kr.GoalID]; ok {
+			goal.KeyResults = append(goal.KeyResults, kr)
+		}
+	}
+
+	for _, goal := range goals {
+		goal.computeProgress()
+	}
+
+	return goals, nil
+}
+
+// Get returns a single goal with its key results and progress
+func (s *System) Get(id int) (*Goal, error) {
+	var boardID int
+	err := s.db.QueryRowContext(s.ctx, `SELECT board_id FROM goals WHERE id = ?`, id).Scan(&boardID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("goal with ID %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get goal: %w", err)
+	}
+
+	goals, err := s.List(boardID)
+	if err != nil {
+		return nil, err
+	}
+	for _, goal := range goals {
+		if goal.ID == id {
+			return goal, nil
+		}
+	}
+	return nil, fmt.Errorf("goal with ID %d not found", id)
+}
+
+// keyResults loads every key result on a board with its task counts: the
+// tasks linked to it and those of its milestone, each counted once.
+// Deleted tasks no longer count towards progress.
+func (s *System) keyResults(boardID int) ([]*KeyResult, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT kr.id, kr.goal_id, kr.title, kr.target, kr.current,
+			kr.milestone_id, COALESCE(m.name, ''),
+			COUNT(t.id),
+			COALESCE(SUM(CASE WHEN t.status = 'done' THEN 1 ELSE 0 END), 0)
+		FROM key_results kr
+		JOIN goals g ON g.id = kr.goal_id
+		LEFT JOIN milestones m ON m.id = kr.milestone_id
+		LEFT JOIN tasks t ON t.deleted_at IS NULL AND (
+			t.id IN (SELECT task_id FROM key_result_tasks WHERE key_result_id = kr.id)
+			OR t.milestone_id = kr.milestone_id
+		)
+		WHERE g.board_id = ?
+		GROUP BY kr.id
+		ORDER BY kr.id ASC
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list key results: %w", err)
+	}
+	defer rows.Close()
+
+	var keyResults []*KeyResult
+	for rows.Next() {
+		var kr KeyResult
+		if err := rows.Scan(&kr.ID, &kr.GoalID, &kr.Title, &kr.Target, &kr.Current, &kr.MilestoneID, &kr.Milestone, &kr.TasksTotal, &kr.TasksDone); err != nil {
+			return nil, fmt.Errorf("failed to scan key result: %w", err)
+		}
+		kr.computeProgress()
+		keyResults = append(keyResults, &kr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating key results: %w", err)
+	}
+
+	return keyResults, nil
+}
+
+// computeProgress averages the progress of the goal's key results
+func (g *Goal) computeProgress() {
+	if len(g.KeyResults) == 0 {
+		g.Progress = 0
+		return
+	}
+
+	var total float64
+	for _, kr := range g.KeyResults {
+		total += kr.Progress
+	}
+	g.Progress = total / float64(len(g.KeyResults))
+}
+
+// expectOneRow turns an update that matched nothing into a not-found error
+func expectOneRow(result sql.Result, kind string, id int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check result: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s with ID %d not found", kind, id)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package goal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/milestone"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestGoalProgressRollsUp(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	goalSystem := New(db.Conn())
+	taskSystem := task.New(db.Conn())
+
+	goal, err := goalSystem.Create(1, "Launch v1", "Q4 objective")
+	if err != nil {
+		t.Fatalf("Failed to create goal: %v", err)
+	}
+
+	// Task-based key result: 1 of 2 linked tasks done
+	shipped, err := goalSystem.AddKeyResult(goal.ID, "Ship core features", 0)
+	if err != nil {
+		t.Fatalf("Failed to add key result: %v", err)
+	}
+	first, _ := taskSystem.Create(1, "Auth", "")
+	second, _ := taskSystem.Create(1, "Billing", "")
+	for _, id := range []int{first.ID, second.ID} {
+		if err := goalSystem.LinkTask(shipped.ID, id); err != nil {
+			t.Fatalf("Failed to link task: %v", err)
+		}
+	}
+	taskSystem.UpdateStatus(first.ID, task.StatusDone)
+
+	// Numeric key result: 30 of 100
+	users, err := goalSystem.AddKeyResult(goal.ID, "Beta users", 100)
+	if err != nil {
+		t.Fatalf("Failed to add key result: %v", err)
+	}
+	if err := goalSystem.SetProgress(users.ID, 30); err != nil {
+		t.Fatalf("Failed to set progress: %v", err)
+	}
+
+	got, err := goalSystem.Get(goal.ID)
+	if err != nil {
+		t.Fatalf("Failed to get goal: %v", err)
+	}
+	if len(got.KeyResults) != 2 {
+		t.Fatalf("Expected 2 key results, got %d", len(got.KeyResults))
+	}
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"task-based key result", got.KeyResults[0].Progress, 0.5},
+		{"numeric key result", got.KeyResults[1].Progress, 0.3},
+		{"goal", got.Progress, 0.4},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-9 {
+			t.Errorf("%s progress = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// Deleted tasks stop counting
+	taskSystem.Delete(second.ID)
+	got, _ = goalSystem.Get(goal.ID)
+	if got.KeyResults[0].TasksTotal != 1 || got.KeyResults[0].Progress != 1 {
+		t.Errorf("Expected deleted task to be excluded, got %+v", got.KeyResults[0])
+	}
+}
+
+func TestKeyResultMilestoneRollsUp(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	goalSystem := New(db.Conn())
+	taskSystem := task.New(db.Conn())
+	milestones := milestone.New(db.Conn())
+
+	goal, _ := goalSystem.Create(1, "Launch v1", "")
+	kr, err := goalSystem.AddKeyResult(goal.ID, "Release 1.0", 0)
+	if err != nil {
+		t.Fatalf("Failed to add key result: %v", err)
+	}
+	release, err := milestones.Create(1, "1.0", "", nil)
+	if err != nil {
+		t.Fatalf("Failed to create milestone: %v", err)
+	}
+	missing := 99
+	if err := goalSystem.SetMilestone(kr.ID, &missing); err == nil {
+		t.Error("Expected error for missing milestone")
+	}
+	if err := goalSystem.SetMilestone(kr.ID, &release.ID); err != nil {
+		t.Fatalf("SetMilestone() error = %v", err)
+	}
+
+	// Two tasks of the milestone, one of them also linked, and one linked
+	// task outside it: three tasks, each counted once
+	planned, _ := taskSystem.Create(1, "Installer", "")
+	both, _ := taskSystem.Create(1, "Docs", "")
+	linked, _ := taskSystem.Create(1, "Press", "")
+	for _, id := range []int{planned.ID, both.ID} {
+		if err := taskSystem.SetMilestone(id, &release.ID); err != nil {
+			t.Fatalf("Failed to plan task: %v", err)
+		}
+	}
+	for _, id := range []int{both.ID, linked.ID} {
+		if err := goalSystem.LinkTask(kr.ID, id); err != nil {
+			t.Fatalf("Failed to link task: %v", err)
+		}
+	}
+	taskSystem.UpdateStatus(planned.ID, task.StatusDone)
+
+	got, err := goalSystem.Get(goal.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	result := got.KeyResults[0]
+	if result.Milestone != "1.0" || result.TasksTotal != 3 || result.TasksDone != 1 {
+		t.Errorf("Key result = %+v, want 1 of 3 tasks done through milestone 1.0", result)
+	}
+
+	// Deleting the milestone leaves the linked tasks
+	if err := milestones.Delete(release.ID); err != nil {
+		t.Fatalf("Failed to delete milestone: %v", err)
+	}
+	got, _ = goalSystem.Get(goal.ID)
+	if result := got.KeyResults[0]; result.MilestoneID != nil || result.TasksTotal != 2 {
+		t.Errorf("Key result = %+v, want its 2 linked tasks and no milestone", result)
+	}
+}
+
+func TestKeyResultProgressIsCapped(t *testing.T) {
+	kr := KeyResult{Target: 10, Current: 25}
+	kr.computeProgress()
+	if kr.Progress != 1 {
+		t.Errorf("Progress = %v, want 1", kr.Progress)
+	}
+
+	empty := KeyResult{}
+	empty.computeProgress()
+	if empty.Progress != 0 {
+		t.Errorf("Progress without target or tasks = %v, want 0", empty.Progress)
+	}
+}
+
+func TestGoalValidation(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	goalSystem := New(db.Conn())
+
+	if _, err := goalSystem.Create(1, "  ", ""); err == nil {
+		t.Error("Expected error for empty goal title")
+	}
+	if _, err := goalSystem.AddKeyResult(99, "Orphan", 0); err == nil {
+		t.Error("Expected error for missing goal")
+	}
+	if err := goalSystem.LinkTask(99, 99); err == nil {
+		t.Error("Expected error linking missing key result")
+	}
+	if err := goalSystem.Delete(99); err == nil {
+		t.Error("Expected error deleting missing goal")
+	}
+}
@@ -0,0 +1,302 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// Edge is a dependency between two tasks: From has to be finished before To.
+// Related links are kept as edges too but carry no ordering.
+type Edge struct {
+	From    int  `json:"from"`
+	To      int  `json:"to"`
+	Related bool `json:"related,omitempty"`
+}
+
+// Graph is the dependency network of a board
+type Graph struct {
+	Tasks map[int]*task.Task `json:"tasks"`
+	Edges []Edge             `json:"edges"`
+}
+
+// Build reads the links of a board into a graph. blocked_by and depends_on
+// links are turned around so every edge points from the blocking task to
+// the blocked one. With a root task, only the tasks connected to it are
+// kept. Links to deleted tasks are ignored.
+func Build(taskSystem *task.System, boardID, rootID int) (*Graph, error) {
+	tasks, err := taskSystem.List(boardID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := taskSystem.ListLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*task.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	g := &Graph{Tasks: make(map[int]*task.Task)}
+	seen := make(map[Edge]bool)
+	for _, link := range links {
+		from, to := link.FromTaskID, link.ToTaskID
+		if byID[from] == nil || byID[to] == nil {
+			continue
+		}
+
+		edge := Edge{From: from, To: to, Related: true}
+		if blocker, blocked, ok := link.Dependency(); ok {
+			edge = Edge{From: blocker, To: blocked}
+		}
+		if seen[edge] {
+			continue
+		}
+		seen[edge] = true
+
+		g.Edges = append(g.Edges, edge)
+		g.Tasks[from] = byID[from]
+		g.Tasks[to] = byID[to]
+	}
+
+	if rootID > 0 {
+		root := byID[rootID]
+		if root == nil {
+			return nil, fmt.Errorf("task with ID %d not found", rootID)
+		}
+		g.Tasks[rootID] = root
+		g.keepConnected(rootID)
+	}
+
+	return g, nil
+}
+
+// keepConnected drops every task and edge not connected to root
+func (g *Graph) keepConnected(root int) {
+	neighbours := make(map[int][]int)
+	for _, e := range g.Edges {
+		neighbours[e.From] = append(neighbours[e.From], e.To)
+		neighbours[e.To] = append(neighbours[e.To], e.From)
+	}
+
+	connected := map[int]bool{root: true}
+	queue := []int{root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, next := range neighbours[id] {
+			if !connected[next] {
+				connected[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for id := range g.Tasks {
+		if !connected[id] {
+			delete(g.Tasks, id)
+		}
+	}
+	var edges []Edge
+	for _, e := range g.Edges {
+		if connected[e.From] {
+			edges = append(edges, e)
+		}
+	}
+	g.Edges = edges
+}
+
+// ids returns the task IDs of the graph in ascending order
+func (g *Graph) ids() []int {
+	ids := make([]int, 0, len(g.Tasks))
+	for id := range g.Tasks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// blocks returns, per task, the tasks it blocks in ascending order
+func (g *Graph) blocks() map[int][]int {
+	out := make(map[int][]int)
+	for _, e := range g.Edges {
+		if !e.Related {
+			out[e.From] = append(out[e.From], e.To)
+		}
+	}
+	for _, ids := range out {
+		sort.Ints(ids)
+	}
+	return out
+}
+
+// Cycles returns the circular dependencies of the graph, each as the path of
+// task IDs that leads back to its first task
+func (g *Graph) Cycles() [][]int {
+	const (
+		unvisited = iota
+		inProgress
+		finished
+	)
+
+	blocks := g.blocks()
+	state := make(map[int]int)
+	var path []int
+	var cycles [][]int
+
+	var visit func(id int)
+	visit = func(id int) {
+		state[id] = inProgress
+		path = append(path, id)
+		for _, next := range blocks[id] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case inProgress:
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == next {
+						cycle := append([]int{}, path[i:]...)
+						cycles = append(cycles, append(cycle, next))
+						break
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = finished
+	}
+
+	for _, id := range g.ids() {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+	return cycles
+}
+
+// FormatCycle renders a cycle as "#1 -> #2 -> #1"
+func FormatCycle(cycle []int) string {
+	parts := make([]string, len(cycle))
+	for i, id := range cycle {
+		parts[i] = fmt.Sprintf("#%d", id)
+	}
+	return strings.Join(parts, " -> ")
+}
+
+// ASCII renders the graph as a tree: every task is listed under the tasks
+// that block it, starting from the tasks nothing blocks. A task that was
+// already expanded is printed once more without its subtree.
+func (g *Graph) ASCII() string {
+	var b strings.Builder
+	blocks := g.blocks()
+
+	blocked := make(map[int]bool)
+	for _, targets := range blocks {
+		for _, id := range targets {
+			blocked[id] = true
+		}
+	}
+
+	expanded := make(map[int]bool)
+	var walk func(id int, prefix string, last bool, depth int)
+	walk = func(id int, prefix string, last bool, depth int) {
+		line, childPrefix := "", ""
+		if depth > 0 {
+			line, childPrefix = prefix+"├── ", prefix+"│   "
+			if last {
+				line, childPrefix = prefix+"└── ", prefix+"    "
+			}
+		}
+
+		children := blocks[id]
+		if expanded[id] && len(children) > 0 {
+			fmt.Fprintf(&b, "%s%s (see above)\n", line, g.label(id))
+			return
+		}
+		fmt.Fprintf(&b, "%s%s\n", line, g.label(id))
+		expanded[id] = true
+
+		for i, child := range children {
+			walk(child, childPrefix, i == len(children)-1, depth+1)
+		}
+	}
+
+	ids := g.ids()
+	var roots []int
+	for _, id := range ids {
+		if !blocked[id] {
+			roots = append(roots, id)
+		}
+	}
+	for _, id := range roots {
+		walk(id, "", true, 0)
+	}
+	// Tasks only reachable through a cycle have no root above them
+	for _, id := range ids {
+		if !expanded[id] {
+			walk(id, "", true, 0)
+		}
+	}
+
+	var related []string
+	for _, e := range g.Edges {
+		if e.Related {
+			related = append(related, fmt.Sprintf("  #%d <-> #%d", e.From, e.To))
+		}
+	}
+	if len(related) > 0 {
+		b.WriteString("\nRelated:\n")
+		b.WriteString(strings.Join(related, "\n"))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// DOT renders the graph in Graphviz DOT format
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph cainban {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, id := range g.ids() {
+		fmt.Fprintf(&b, "  t%d [label=%q];\n", id, g.label(id))
+	}
+	for _, e := range g.Edges {
+		if e.Related {
+			fmt.Fprintf(&b, "  t%d -> t%d [style=dashed, dir=none];\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&b, "  t%d -> t%d;\n", e.From, e.To)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, id := range g.ids() {
+		// Mermaid has no escape for double quotes inside a label
+		label := strings.ReplaceAll(g.label(id), `"`, "'")
+		fmt.Fprintf(&b, "  t%d[\"%s\"]\n", id, label)
+	}
+	for _, e := range g.Edges {
+		if e.Related {
+			fmt.Fprintf(&b, "  t%d -.- t%d\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(&b, "  t%d --> t%d\n", e.From, e.To)
+		}
+	}
+	return b.String()
+}
+
+func (g *Graph) label(id int) string {
+	t := g.Tasks[id]
+	return fmt.Sprintf("#%d %s [%s]", t.ID, t.Title, t.Status)
+}
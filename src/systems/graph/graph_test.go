@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestGraph(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	for _, title := range []string{"Schema", "API", "Frontend", "Docs", "Unrelated"} {
+		if _, err := taskSystem.Create(1, title, ""); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	links := []struct {
+		from, to int
+		linkType task.LinkType
+	}{
+		{1, 2, task.LinkTypeBlocks},
+		{3, 2, task.LinkTypeDependsOn},
+		{4, 3, task.LinkTypeRelated},
+	}
+	for _, l := range links {
+		if err := taskSystem.LinkTasks(l.from, l.to, l.linkType); err != nil {
+			t.Fatalf("Failed to link tasks: %v", err)
+		}
+	}
+
+	g, err := Build(taskSystem, 1, 0)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	if len(g.Tasks) != 4 {
+		t.Errorf("Expected the 4 linked tasks, got %d", len(g.Tasks))
+	}
+	if cycles := g.Cycles(); len(cycles) != 0 {
+		t.Errorf("Expected no cycles, got %v", cycles)
+	}
+
+	want := "#1 Schema [todo]\n" +
+		"└── #2 API [todo]\n" +
+		"    └── #3 Frontend [todo]\n" +
+		"#4 Docs [todo]\n" +
+		"\nRelated:\n  #4 <-> #3\n"
+	if got := g.ASCII(); got != want {
+		t.Errorf("ASCII() =\n%s\nwant\n%s", got, want)
+	}
+
+	if dot := g.DOT(); !strings.Contains(dot, "t2 -> t3;") || !strings.Contains(dot, `t1 [label="#1 Schema [todo]"]`) {
+		t.Errorf("Unexpected DOT output:\n%s", dot)
+	}
+	if mermaid := g.Mermaid(); !strings.Contains(mermaid, "t1 --> t2") || !strings.Contains(mermaid, "t4 -.- t3") {
+		t.Errorf("Unexpected Mermaid output:\n%s", mermaid)
+	}
+
+	// Frontend blocking Schema closes the loop 1 -> 2 -> 3 -> 1. LinkTasks
+	// refuses that, but boards linked before it did may still have one.
+	if _, err := db.Conn().Exec(`INSERT INTO task_links (from_task_id, to_task_id, link_type) VALUES (3, 1, 'blocks')`); err != nil {
+		t.Fatalf("Failed to link tasks: %v", err)
+	}
+	g, err = Build(taskSystem, 1, 5)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	if len(g.Tasks) != 1 || len(g.Cycles()) != 0 {
+		t.Errorf("Expected only the unlinked root task, got %d tasks", len(g.Tasks))
+	}
+
+	g, err = Build(taskSystem, 1, 2)
+	if err != nil {
+		t.Fatalf("Failed to build graph: %v", err)
+	}
+	cycles := g.Cycles()
+	if len(cycles) != 1 || FormatCycle(cycles[0]) != "#1 -> #2 -> #3 -> #1" {
+		t.Errorf("Unexpected cycles: %v", cycles)
+	}
+	if ascii := g.ASCII(); !strings.Contains(ascii, "#1 Schema [todo] (see above)") {
+		t.Errorf("Expected the cycle to be cut in the tree:\n%s", ascii)
+	}
+
+	if _, err := Build(taskSystem, 1, 99); err == nil {
+		t.Error("Expected error for missing root task")
+	}
+}
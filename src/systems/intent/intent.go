@@ -0,0 +1,189 @@
+package intent
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Command is a cainban command line interpreted from a sentence
+type Command struct {
+	Name string   `json:"command"`
+	Args []string `json:"args"`
+}
+
+// String renders the command as it would be typed in a shell
+func (c Command) String() string {
+	parts := []string{"cainban", c.Name}
+	for _, arg := range c.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t'\"") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// statuses maps the ways a column is referred to onto its status
+var statuses = map[string]string{
+	"todo": "todo", "to do": "todo", "to-do": "todo", "backlog": "todo", "not started": "todo",
+	"doing": "doing", "in progress": "doing", "progress": "doing", "in-progress": "doing", "started": "doing", "wip": "doing",
+	"done": "done", "finished": "done", "complete": "done", "completed": "done", "closed": "done",
+}
+
+// priorities maps priority words onto the levels add and priority accept
+var priorities = map[string]string{
+	"none": "none", "no": "none",
+	"low": "low", "minor": "low",
+	"medium": "medium", "normal": "medium",
+	"high": "high", "important": "high",
+	"critical": "critical", "urgent": "critical",
+}
+
+const (
+	statusWords   = `to do|to-do|todo|backlog|not started|in progress|in-progress|progress|doing|started|wip|done|finished|completed|complete|closed`
+	priorityWords = `none|no|low|minor|medium|normal|high|important|critical|urgent`
+)
+
+var (
+	nextPattern     = regexp.MustCompile(`(?i)^(?:what(?:'s| is)? next|what should i (?:do|work on|pick up)(?: next)?|next(?: task)?)$`)
+	listPattern     = regexp.MustCompile(`(?i)^(?:show|list)(?: me)?(?: all| my| the)*(?: (.+?))? (?:tasks|cards|items)$`)
+	addPattern      = regexp.MustCompile(`(?i)^(?:add|create|new|capture)(?: an?| new| another)*(?: (` + priorityWords + `)(?: priority| prio)?)?(?: task| todo| card| item)?(?::|\s+(?:to|called|named|titled|for|about))?\s+(.+)$`)
+	trailPriority   = regexp.MustCompile(`(?i)[,\s]+(?:with |at )?(` + priorityWords + `) priority$`)
+	contextPattern  = regexp.MustCompile(`(?:^|\s)(@[\w-]+)`)
+	movePattern     = regexp.MustCompile(`(?i)^(?:move|put|drag|send)\s+(.+?)\s+(?:to|into|in|back to)\s+(?:the\s+)?(` + statusWords + `)(?:\s+column)?$`)
+	startPattern    = regexp.MustCompile(`(?i)^(?:start|begin|pick up|work on)(?: working on| on)?\s+(.+)$`)
+	finishPattern   = regexp.MustCompile(`(?i)^(?:finish|complete|close|i finished|i completed|i'm done with|done with)\s+(.+)$`)
+	priorityOfTask  = regexp.MustCompile(`(?i)^(?:set|make|change)\s+(?:the\s+)?priority\s+(?:of|for|on)\s+(.+?)\s+to\s+(` + priorityWords + `)$`)
+	taskToPriority  = regexp.MustCompile(`(?i)^(?:make|set|mark|change)\s+(.+?)\s+(?:to\s+|as\s+)?(` + priorityWords + `)(?:\s+priority)?$`)
+	markPattern     = regexp.MustCompile(`(?i)^mark\s+(.+?)\s+(?:as\s+)?(` + statusWords + `)$`)
+	unassignPattern = regexp.MustCompile(`(?i)^unassign\s+(.+)$`)
+	assignPattern   = regexp.MustCompile(`(?i)^(?:assign|give)\s+(.+?)\s+to\s+(\S+)$`)
+	duePattern      = regexp.MustCompile(`(?i)^(.+?)\s+is\s+due\s+(.+)$`)
+	dueOfPattern    = regexp.MustCompile(`(?i)^(?:set\s+)?(?:the\s+)?due(?:\s+date)?\s+(?:of|for|on)\s+(.+?)\s+to\s+(.+)$`)
+)
+
+// Parse interprets a short English sentence as a cainban command. It
+// understands adding tasks ("add a high priority task to fix the login
+// bug"), moving them ("move the auth task to done", "start the docs task",
+// "mark #4 as done"), priorities ("make the auth task urgent priority"),
+// assignment ("assign the auth task to alice"), due dates ("the auth task
+// is due friday"), listing ("show doing tasks") and "what should I work
+// on next". Tasks are referred to the way the other commands accept them:
+// by ID or by a fuzzy part of their title.
+func Parse(sentence string) (*Command, error) {
+	s := strings.Join(strings.Fields(sentence), " ")
+	s = strings.TrimRight(s, ".!?")
+	if s == "" {
+		return nil, fmt.Errorf("nothing to do")
+	}
+
+	if nextPattern.MatchString(s) {
+		return &Command{Name: "next", Args: []string{}}, nil
+	}
+
+	if m := listPattern.FindStringSubmatch(s); m != nil {
+		if m[1] == "" {
+			return &Command{Name: "list", Args: []string{}}, nil
+		}
+		status, ok := statuses[strings.ToLower(m[1])]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", m[1])
+		}
+		return &Command{Name: "list", Args: []string{status}}, nil
+	}
+
+	if m := addPattern.FindStringSubmatch(s); m != nil {
+		return parseAdd(m[2], m[1])
+	}
+
+	if m := priorityOfTask.FindStringSubmatch(s); m != nil {
+		return taskCommand("priority", m[1], priorities[strings.ToLower(m[2])])
+	}
+	if m := taskToPriority.FindStringSubmatch(s); m != nil {
+		return taskCommand("priority", m[1], priorities[strings.ToLower(m[2])])
+	}
+
+	if m := movePattern.FindStringSubmatch(s); m != nil {
+		return taskCommand("move", m[1], statuses[strings.ToLower(m[2])])
+	}
+	if m := markPattern.FindStringSubmatch(s); m != nil {
+		return taskCommand("move", m[1], statuses[strings.ToLower(m[2])])
+	}
+	if m := startPattern.FindStringSubmatch(s); m != nil {
+		return taskCommand("move", m[1], "doing")
+	}
+	if m := finishPattern.FindStringSubmatch(s); m != nil {
+		return taskCommand("move", m[1], "done")
+	}
+
+	if m := unassignPattern.FindStringSubmatch(s); m != nil {
+		return taskCommand("assign", m[1])
+	}
+	if m := assignPattern.FindStringSubmatch(s); m != nil {
+		return taskCommand("assign", m[1], m[2])
+	}
+
+	if m := dueOfPattern.FindStringSubmatch(s); m != nil {
+		return taskCommand("due", m[1], m[2])
+	}
+	if m := duePattern.FindStringSubmatch(s); m != nil {
+		return taskCommand("due", m[1], m[2])
+	}
+
+	return nil, fmt.Errorf("could not understand %q", s)
+}
+
+// parseAdd builds an add command from the rest of an "add ..." sentence,
+// picking out a trailing "with high priority" and @contexts
+func parseAdd(rest, priority string) (*Command, error) {
+	if m := trailPriority.FindStringSubmatch(rest); m != nil {
+		priority = m[1]
+		rest = rest[:len(rest)-len(m[0])]
+	}
+
+	var contexts []string
+	for _, m := range contextPattern.FindAllStringSubmatch(rest, -1) {
+		contexts = append(contexts, m[1])
+	}
+	title := strings.TrimSpace(contextPattern.ReplaceAllString(rest, ""))
+	title = strings.Trim(title, `"'`)
+	if title == "" {
+		return nil, fmt.Errorf("the new task needs a title")
+	}
+
+	args := []string{title}
+	if priority != "" {
+		args = append(args, "--priority", priorities[strings.ToLower(priority)])
+	}
+	args = append(args, contexts...)
+	return &Command{Name: "add", Args: args}, nil
+}
+
+// taskCommand builds a command whose first argument refers to a task
+func taskCommand(name, ref string, args ...string) (*Command, error) {
+	ref = taskRef(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("which task?")
+	}
+	return &Command{Name: name, Args: append([]string{ref}, args...)}, nil
+}
+
+// taskRef reduces "the auth task", "task 5" or "#5" to what FindTaskByFuzzyID
+// matches on: "auth" or "5"
+func taskRef(ref string) string {
+	ref = strings.Trim(strings.TrimSpace(ref), `"'`)
+	lower := strings.ToLower(ref)
+	for _, prefix := range []string{"the ", "my ", "task ", "card "} {
+		if strings.HasPrefix(lower, prefix) {
+			ref, lower = ref[len(prefix):], lower[len(prefix):]
+		}
+	}
+	for _, suffix := range []string{" task", " card", " ticket", " one"} {
+		if strings.HasSuffix(lower, suffix) {
+			ref, lower = ref[:len(ref)-len(suffix)], lower[:len(lower)-len(suffix)]
+		}
+	}
+	return strings.Trim(strings.TrimPrefix(ref, "#"), `"'`)
+}
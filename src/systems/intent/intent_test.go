@@ -0,0 +1,51 @@
+package intent
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"add a high priority task to fix the login bug", `cainban add "fix the login bug" --priority high`},
+		{"Add task: Write release notes @docs", `cainban add "Write release notes" @docs`},
+		{"create a task called refactor storage, with urgent priority", `cainban add "refactor storage" --priority critical`},
+		{"add sign in page", `cainban add "sign in page"`},
+		{"move the auth task to done", "cainban move auth done"},
+		{"move sign in page to in progress", `cainban move "sign in page" doing`},
+		{"put #5 back to the backlog.", "cainban move 5 todo"},
+		{"start working on the docs task", "cainban move docs doing"},
+		{"finish task 7", "cainban move 7 done"},
+		{"mark the cache task as complete", "cainban move cache done"},
+		{"make the auth task urgent priority", "cainban priority auth critical"},
+		{"set the priority of #3 to low", "cainban priority 3 low"},
+		{"assign the auth task to alice", "cainban assign auth alice"},
+		{"unassign the auth task", "cainban assign auth"},
+		{"the release task is due next friday 17:00", `cainban due release "next friday 17:00"`},
+		{"show doing tasks", "cainban list doing"},
+		{"list all tasks", "cainban list"},
+		{"What should I work on next?", "cainban next"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			cmd, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if got := cmd.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, input := range []string{"", "   ", "make me a sandwich please", "show auth tasks", "add", "move the task"} {
+		if cmd, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) = %s, want error", input, cmd)
+		}
+	}
+}
@@ -0,0 +1,492 @@
+package jira
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// Issue holds the Jira fields cainban maps to and from tasks
+type Issue struct {
+	Key            string   `json:"key,omitempty"`
+	Summary        string   `json:"summary"`
+	Description    string   `json:"description,omitempty"`
+	Priority       string   `json:"priority,omitempty"`
+	Status         string   `json:"status,omitempty"`
+	StatusCategory string   `json:"status_category,omitempty"` // "new", "indeterminate" or "done"
+	Labels         []string `json:"labels,omitempty"`
+	// Hash is the hash of the task an issue was exported from, which the
+	// task keeps when the issue is imported on another board
+	Hash string `json:"hash,omitempty"`
+}
+
+// Mapping translates Jira statuses, priorities and labels to cainban. Keys
+// are matched case-insensitively.
+type Mapping struct {
+	Statuses   map[string]task.Status
+	Priorities map[string]int
+	// Labels maps labels to the GTD contexts (tags) imported tasks are put
+	// in. With KeepLabels other labels become contexts of the same name;
+	// without it they are dropped.
+	Labels     map[string]string
+	KeepLabels bool
+	// Columns renames the CSV columns read for each field (key, summary,
+	// description, priority, status, status_category and labels), for
+	// exports from trackers that name them differently
+	Columns map[string]string
+}
+
+// defaultColumns are the headers of a Jira CSV export
+var defaultColumns = map[string]string{
+	"key":             "issue key",
+	"summary":         "summary",
+	"description":     "description",
+	"priority":        "priority",
+	"status":          "status",
+	"status_category": "status category",
+	"labels":          "labels",
+}
+
+// DefaultMapping covers the statuses and priorities of a stock Jira project
+func DefaultMapping() Mapping {
+	return Mapping{
+		Statuses: map[string]task.Status{
+			"to do":                    task.StatusTodo,
+			"open":                     task.StatusTodo,
+			"backlog":                  task.StatusTodo,
+			"selected for development": task.StatusTodo,
+			"in progress":              task.StatusDoing,
+			"in review":                task.StatusDoing,
+			"review":                   task.StatusDoing,
+			"done":                     task.StatusDone,
+			"closed":                   task.StatusDone,
+			"resolved":                 task.StatusDone,
+		},
+		Priorities: map[string]int{
+			"highest":  task.PriorityCritical,
+			"blocker":  task.PriorityCritical,
+			"high":     task.PriorityHigh,
+			"critical": task.PriorityHigh,
+			"major":    task.PriorityHigh,
+			"medium":   task.PriorityMedium,
+			"low":      task.PriorityLow,
+			"minor":    task.PriorityLow,
+			"lowest":   task.PriorityLow,
+			"trivial":  task.PriorityLow,
+		},
+	}
+}
+
+// Status maps an issue's status, falling back to its status category for
+// custom workflow states and to todo when neither is known
+func (m Mapping) Status(issue Issue) task.Status {
+	if status, ok := m.Statuses[strings.ToLower(strings.TrimSpace(issue.Status))]; ok {
+		return status
+	}
+
+	switch strings.ToLower(issue.StatusCategory) {
+	case "done":
+		return task.StatusDone
+	case "indeterminate":
+		return task.StatusDoing
+	}
+	return task.StatusTodo
+}
+
+// Priority maps an issue's priority, defaulting to none when unknown
+func (m Mapping) Priority(issue Issue) int {
+	if priority, ok := m.Priorities[strings.ToLower(strings.TrimSpace(issue.Priority))]; ok {
+		return priority
+	}
+	return task.PriorityNone
+}
+
+// Contexts maps an issue's labels to contexts, in label order without
+// duplicates. Unmapped labels that are not valid context names are dropped
+// even with KeepLabels.
+func (m Mapping) Contexts(issue Issue) []string {
+	var contexts []string
+	seen := make(map[string]bool)
+	for _, label := range issue.Labels {
+		context, ok := m.Labels[strings.ToLower(label)]
+		if !ok {
+			if !m.KeepLabels {
+				continue
+			}
+			context = label
+		}
+		context, err := task.NormalizeContext(context)
+		if err != nil || seen[context] {
+			continue
+		}
+		seen[context] = true
+		contexts = append(contexts, context)
+	}
+	return contexts
+}
+
+// column returns the lowercase CSV header read for a field
+func (m Mapping) column(field string) string {
+	if name, ok := m.Columns[field]; ok {
+		return strings.ToLower(name)
+	}
+	return defaultColumns[field]
+}
+
+// exportStatuses and exportPriorities are the Jira names written on export
+var exportStatuses = map[task.Status]string{
+	task.StatusTodo:  "To Do",
+	task.StatusDoing: "In Progress",
+	task.StatusDone:  "Done",
+}
+
+var exportPriorities = map[int]string{
+	task.PriorityNone:     "",
+	task.PriorityLow:      "Low",
+	task.PriorityMedium:   "Medium",
+	task.PriorityHigh:     "High",
+	task.PriorityCritical: "Highest",
+}
+
+// FromTask converts a task to a Jira issue for export
+func FromTask(t *task.Task) Issue {
+	return Issue{
+		Summary:     t.Title,
+		Description: t.Description,
+		Priority:    exportPriorities[t.Priority],
+		Status:      exportStatuses[t.Status],
+		Hash:        t.Hash,
+	}
+}
+
+// Parse reads issues from a Jira CSV export or a JSON search result,
+// detecting the format from the first non-space character
+func Parse(r io.Reader) ([]Issue, error) {
+	return DefaultMapping().Parse(r)
+}
+
+// Parse reads issues like the package-level Parse, reading CSV columns
+// under the names the mapping gives them
+func (m Mapping) Parse(r io.Reader) ([]Issue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return ParseJSON(strings.NewReader(trimmed))
+	}
+	return m.parseCSV(strings.NewReader(string(data)))
+}
+
+// ParseCSV reads a Jira "Export CSV" file. Only the Summary column is required.
+func ParseCSV(r io.Reader) ([]Issue, error) {
+	return DefaultMapping().parseCSV(r)
+}
+
+// parseCSV reads a CSV export with the mapping's column names
+func (m Mapping) parseCSV(r io.Reader) ([]Issue, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	// Jira repeats some headers (e.g. Labels); the first occurrence wins,
+	// except for labels which are read from all of them
+	columns := make(map[string]int)
+	var labelColumns []int
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		if _, seen := columns[name]; !seen {
+			columns[name] = i
+		}
+		if name == m.column("labels") {
+			labelColumns = append(labelColumns, i)
+		}
+	}
+	if _, ok := columns[m.column("summary")]; !ok {
+		return nil, fmt.Errorf("CSV has no %s column", m.column("summary"))
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[m.column(name)]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var issues []Issue
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		issue := Issue{
+			Key:            field(record, "key"),
+			Summary:        field(record, "summary"),
+			Description:    field(record, "description"),
+			Priority:       field(record, "priority"),
+			Status:         field(record, "status"),
+			StatusCategory: field(record, "status_category"),
+		}
+		if issue.Summary == "" {
+			continue
+		}
+		// A label column holds one label, or several separated by spaces
+		for _, i := range labelColumns {
+			if i < len(record) {
+				issue.Labels = append(issue.Labels, strings.Fields(record[i])...)
+			}
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues, nil
+}
+
+// jsonIssue is an issue as returned by the Jira REST API
+type jsonIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string          `json:"summary"`
+		Description json.RawMessage `json:"description"`
+		Priority    *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Status *struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"`
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Labels []string `json:"labels"`
+	} `json:"fields"`
+
+	// Flat fields, as written by cainban's own JSON export
+	Summary        string   `json:"summary"`
+	Description    string   `json:"description"`
+	Priority       string   `json:"priority"`
+	Status         string   `json:"status"`
+	StatusCategory string   `json:"status_category"`
+	Labels         []string `json:"labels"`
+	Hash           string   `json:"hash"`
+}
+
+// ParseJSON reads a Jira REST search result ({"issues": [...]}), a bare array
+// of REST issues, or cainban's flat JSON export
+func ParseJSON(r io.Reader) ([]Issue, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	var raw []jsonIssue
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(data, &raw)
+	} else {
+		var result struct {
+			Issues []jsonIssue `json:"issues"`
+		}
+		err = json.Unmarshal(data, &result)
+		raw = result.Issues
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	var issues []Issue
+	for _, ji := range raw {
+		issue := Issue{
+			Key:            ji.Key,
+			Summary:        ji.Summary,
+			Description:    ji.Description,
+			Priority:       ji.Priority,
+			Status:         ji.Status,
+			StatusCategory: ji.StatusCategory,
+			Labels:         ji.Labels,
+			Hash:           ji.Hash,
+		}
+		if ji.Fields.Summary != "" {
+			issue.Summary = ji.Fields.Summary
+			issue.Description = descriptionText(ji.Fields.Description)
+			issue.Labels = ji.Fields.Labels
+			if ji.Fields.Priority != nil {
+				issue.Priority = ji.Fields.Priority.Name
+			}
+			if ji.Fields.Status != nil {
+				issue.Status = ji.Fields.Status.Name
+				issue.StatusCategory = ji.Fields.Status.StatusCategory.Key
+			}
+		}
+
+		issue.Summary = strings.TrimSpace(issue.Summary)
+		if issue.Summary == "" {
+			continue
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues, nil
+}
+
+// descriptionText extracts plain text from a description that is either a
+// string (REST API v2) or an Atlassian Document Format tree (v3)
+func descriptionText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	var doc adfNode
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	doc.writeText(&b)
+	return strings.TrimSpace(b.String())
+}
+
+// adfNode is a node of an Atlassian Document Format tree
+type adfNode struct {
+	Type    string    `json:"type"`
+	Text    string    `json:"text"`
+	Content []adfNode `json:"content"`
+}
+
+// writeText appends the node's text, ending block nodes with a newline
+func (n adfNode) writeText(b *strings.Builder) {
+	switch n.Type {
+	case "text":
+		b.WriteString(n.Text)
+		return
+	case "hardBreak":
+		b.WriteString("\n")
+		return
+	}
+
+	for _, child := range n.Content {
+		child.writeText(b)
+	}
+
+	switch n.Type {
+	case "paragraph", "heading", "listItem", "codeBlock", "blockquote":
+		if !strings.HasSuffix(b.String(), "\n") {
+			b.WriteString("\n")
+		}
+	}
+}
+
+// WriteCSV writes issues in a CSV layout accepted by Jira's CSV importer
+func WriteCSV(w io.Writer, issues []Issue) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"Summary", "Description", "Priority", "Status", "Issue Type"}); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, issue := range issues {
+		if err := writer.Write([]string{issue.Summary, issue.Description, issue.Priority, issue.Status, "Task"}); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes issues as {"issues": [...]} with flat fields, which
+// ParseJSON reads back
+func WriteJSON(w io.Writer, issues []Issue) error {
+	if issues == nil {
+		issues = []Issue{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(map[string][]Issue{"issues": issues}); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// ImportResult summarizes an import
+type ImportResult struct {
+	Created []*task.Task `json:"created"`
+	Skipped []Issue      `json:"skipped"`
+}
+
+// Import creates a task for each issue, in the contexts its labels map to. Issues whose summary matches the
+// title of an existing task, or exported from a task that is on the board already, are skipped so an import
+// can be re-run safely. A task created from an issue cainban exported keeps the hash of the task it came from.
+func Import(taskSystem *task.System, boardID int, issues []Issue, mapping Mapping) (*ImportResult, error) {
+	existing, err := taskSystem.List(boardID)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[string]bool)
+	hashes := make(map[string]bool)
+	for _, t := range existing {
+		titles[t.Title] = true
+		hashes[t.Hash] = true
+	}
+
+	result := &ImportResult{}
+	for _, issue := range issues {
+		if titles[issue.Summary] || (issue.Hash != "" && hashes[strings.ToLower(issue.Hash)]) {
+			result.Skipped = append(result.Skipped, issue)
+			continue
+		}
+
+		created, err := taskSystem.CreateWithPriority(boardID, issue.Summary, issue.Description, mapping.Priority(issue))
+		if err != nil {
+			return result, fmt.Errorf("failed to import %q: %w", issue.Summary, err)
+		}
+
+		// A hash that is malformed or taken by a task in the trash is left
+		// behind, and the task keeps the one it was created with
+		if issue.Hash != "" {
+			err := taskSystem.SetHash(created.ID, issue.Hash)
+			if err != nil && !errors.Is(err, task.ErrInvalidInput) {
+				return result, fmt.Errorf("failed to import %q: %w", issue.Summary, err)
+			}
+			if err == nil {
+				created.Hash = strings.ToLower(issue.Hash)
+			}
+		}
+
+		if status := mapping.Status(issue); status != task.StatusTodo {
+			if err := taskSystem.UpdateStatus(created.ID, status); err != nil {
+				return result, fmt.Errorf("failed to import %q: %w", issue.Summary, err)
+			}
+			created.Status = status
+		}
+
+		for _, context := range mapping.Contexts(issue) {
+			if err := taskSystem.AddContext(created.ID, context); err != nil {
+				return result, fmt.Errorf("failed to import %q: %w", issue.Summary, err)
+			}
+			created.Contexts = append(created.Contexts, context)
+		}
+
+		titles[issue.Summary] = true
+		hashes[created.Hash] = true
+		result.Created = append(result.Created, created)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,210 @@
+package jira
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+const sampleCSV = "\uFEFFIssue key,Summary,Description,Priority,Status,Status Category,Labels,Labels\n" +
+	"PROJ-1,Set up CI,\"Build, test\nand lint\",High,In Progress,In Progress,ci,infra\n" +
+	"PROJ-2,Write docs,,Lowest,Awaiting QA,Done,,\n" +
+	"PROJ-3,,ignored,,,,\n"
+
+const sampleJSON = `{
+  "issues": [
+    {
+      "key": "PROJ-7",
+      "fields": {
+        "summary": "Fix login",
+        "description": {
+          "type": "doc",
+          "content": [
+            {"type": "paragraph", "content": [{"type": "text", "text": "Users are "}, {"type": "text", "text": "logged out."}]},
+            {"type": "paragraph", "content": [{"type": "text", "text": "Repro attached."}]}
+          ]
+        },
+        "priority": {"name": "Highest"},
+        "status": {"name": "Ready for QA", "statusCategory": {"key": "indeterminate"}}
+      }
+    },
+    {
+      "key": "PROJ-8",
+      "fields": {
+        "summary": "Old API",
+        "description": "Plain v2 description",
+        "priority": null,
+        "status": {"name": "Closed", "statusCategory": {"key": "done"}}
+      }
+    }
+  ]
+}`
+
+func TestParseCSV(t *testing.T) {
+	issues, err := Parse(strings.NewReader(sampleCSV))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d: %+v", len(issues), issues)
+	}
+
+	want := Issue{Key: "PROJ-1", Summary: "Set up CI", Description: "Build, test\nand lint", Priority: "High", Status: "In Progress", StatusCategory: "In Progress", Labels: []string{"ci", "infra"}}
+	if !reflect.DeepEqual(issues[0], want) {
+		t.Errorf("issues[0] = %+v, want %+v", issues[0], want)
+	}
+
+	mapping := DefaultMapping()
+	if got := mapping.Status(issues[1]); got != task.StatusDone {
+		t.Errorf("Custom status with done category mapped to %s, want done", got)
+	}
+	if got := mapping.Priority(issues[1]); got != task.PriorityLow {
+		t.Errorf("Lowest priority mapped to %d, want low", got)
+	}
+
+	if _, err := ParseCSV(strings.NewReader("Key,Title\nA,B\n")); err == nil {
+		t.Error("Expected error for CSV without Summary column")
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	issues, err := Parse(strings.NewReader(sampleJSON))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d", len(issues))
+	}
+
+	if issues[0].Description != "Users are logged out.\nRepro attached." {
+		t.Errorf("ADF description = %q", issues[0].Description)
+	}
+	if issues[1].Description != "Plain v2 description" || issues[1].Priority != "" {
+		t.Errorf("Unexpected v2 issue: %+v", issues[1])
+	}
+
+	mapping := DefaultMapping()
+	tests := []struct {
+		issue    Issue
+		status   task.Status
+		priority int
+	}{
+		{issues[0], task.StatusDoing, task.PriorityCritical},
+		{issues[1], task.StatusDone, task.PriorityNone},
+	}
+	for _, tt := range tests {
+		if got := mapping.Status(tt.issue); got != tt.status {
+			t.Errorf("%s status = %s, want %s", tt.issue.Key, got, tt.status)
+		}
+		if got := mapping.Priority(tt.issue); got != tt.priority {
+			t.Errorf("%s priority = %d, want %d", tt.issue.Key, got, tt.priority)
+		}
+	}
+}
+
+func TestImportAndExportRoundTrip(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	issues, _ := ParseCSV(strings.NewReader(sampleCSV))
+
+	result, err := Import(taskSystem, 1, issues, DefaultMapping())
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.Created) != 2 || len(result.Skipped) != 0 {
+		t.Fatalf("Expected 2 created, got %+v", result)
+	}
+
+	ci, _ := taskSystem.GetByID(result.Created[0].ID)
+	if ci.Status != task.StatusDoing || ci.Priority != task.PriorityHigh || ci.Description != "Build, test\nand lint" {
+		t.Errorf("Unexpected imported task: %+v", ci)
+	}
+
+	// Re-running the import skips what is already there
+	result, err = Import(taskSystem, 1, issues, DefaultMapping())
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if len(result.Created) != 0 || len(result.Skipped) != 2 {
+		t.Errorf("Expected everything skipped, got %+v", result)
+	}
+
+	tasks, _ := taskSystem.List(1)
+	var exported []Issue
+	for _, tk := range tasks {
+		exported = append(exported, FromTask(tk))
+	}
+
+	for name, write := range map[string]func(*bytes.Buffer) error{
+		"csv":  func(b *bytes.Buffer) error { return WriteCSV(b, exported) },
+		"json": func(b *bytes.Buffer) error { return WriteJSON(b, exported) },
+	} {
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			t.Fatalf("%s export error = %v", name, err)
+		}
+		parsed, err := Parse(&buf)
+		if err != nil {
+			t.Fatalf("%s re-parse error = %v", name, err)
+		}
+		if len(parsed) != 2 {
+			t.Fatalf("%s: expected 2 issues, got %d", name, len(parsed))
+		}
+		for i, issue := range parsed {
+			if issue.Summary != exported[i].Summary || DefaultMapping().Status(issue) != tasks[i].Status ||
+				DefaultMapping().Priority(issue) != tasks[i].Priority {
+				t.Errorf("%s: round trip mismatch %+v vs %+v", name, issue, tasks[i])
+			}
+		}
+	}
+}
+
+func TestImportKeepsHashes(t *testing.T) {
+	newBoard := func() *task.System {
+		db, err := storage.NewMemory()
+		if err != nil {
+			t.Fatalf("Failed to create test database: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return task.New(db.Conn())
+	}
+
+	// A task exported on one machine...
+	source := newBoard()
+	original, _ := source.Create(1, "Set up CI", "")
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, []Issue{FromTask(original)}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	issues, err := Parse(&buf)
+	if err != nil || len(issues) != 1 || issues[0].Hash != original.Hash {
+		t.Fatalf("Expected the hash to survive the export, got %+v, %v", issues, err)
+	}
+
+	// ...is the same task once imported on another
+	target := newBoard()
+	target.Create(1, "Unrelated", "")
+	result, err := Import(target, 1, issues, DefaultMapping())
+	if err != nil || len(result.Created) != 1 {
+		t.Fatalf("Import() = %+v, %v", result, err)
+	}
+	if got, err := target.GetByHash(original.ShortHash()); err != nil || got.Title != "Set up CI" {
+		t.Errorf("Expected the imported task to keep its hash, got %v, %v", got, err)
+	}
+
+	// Renamed there, it is still recognized when imported again
+	target.Update(result.Created[0].ID, "Set up CI and CD", "")
+	result, err = Import(target, 1, issues, DefaultMapping())
+	if err != nil || len(result.Created) != 0 || len(result.Skipped) != 1 {
+		t.Errorf("Expected the renamed task to be skipped, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,112 @@
+package jira
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// LoadMapping reads a YAML mapping file on top of DefaultMapping, so it
+// only needs the statuses, priorities and labels of a non-default workflow:
+//
+//	statuses:            # source status: todo, doing or done
+//	  Ready for QA: doing
+//	  Won't Do: done
+//	priorities:          # source priority: none, low, medium, high, critical or 0-4
+//	  P1: critical
+//	labels:              # source label: context
+//	  client-acme: "@client-a"
+//	keep_labels: true    # other labels become contexts of the same name
+//	columns:             # CSV header of each field, when not Jira's
+//	  summary: Title
+//	  labels: Tags
+func LoadMapping(path string) (Mapping, error) {
+	mapping := DefaultMapping()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return mapping, fmt.Errorf("failed to open mapping file: %w", err)
+	}
+	defer file.Close()
+
+	values, err := config.ParseYAML(file)
+	if err != nil {
+		return mapping, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := mapping.apply(values); err != nil {
+		return mapping, fmt.Errorf("%s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+// apply adds the entries of a parsed mapping file to the mapping
+func (m *Mapping) apply(values map[string]interface{}) error {
+	for key, value := range values {
+		switch key {
+		case "keep_labels":
+			s, ok := value.(string)
+			keep, err := strconv.ParseBool(s)
+			if !ok || err != nil {
+				return fmt.Errorf("keep_labels must be true or false")
+			}
+			m.KeepLabels = keep
+			continue
+		case "statuses", "priorities", "labels", "columns":
+		default:
+			return fmt.Errorf("unknown key %q (use statuses, priorities, labels, keep_labels or columns)", key)
+		}
+
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s must map source names to cainban values", key)
+		}
+
+		for source, target := range entries {
+			name, ok := target.(string)
+			if !ok || name == "" {
+				return fmt.Errorf("%s: %q needs a single value", key, source)
+			}
+			source = strings.ToLower(strings.TrimSpace(source))
+
+			switch key {
+			case "statuses":
+				if !task.IsValidStatus(name) {
+					return fmt.Errorf("statuses: invalid status %q for %q (use todo, doing or done)", name, source)
+				}
+				m.Statuses[source] = task.Status(name)
+			case "priorities":
+				var level interface{} = name
+				if n, err := strconv.Atoi(name); err == nil {
+					level = n
+				}
+				priority, err := task.ParsePriority(level)
+				if err != nil {
+					return fmt.Errorf("priorities: %q: %w", source, err)
+				}
+				m.Priorities[source] = priority
+			case "labels":
+				context, err := task.NormalizeContext(name)
+				if err != nil {
+					return fmt.Errorf("labels: %q: %w", source, err)
+				}
+				if m.Labels == nil {
+					m.Labels = make(map[string]string)
+				}
+				m.Labels[source] = context
+			case "columns":
+				if _, known := defaultColumns[source]; !known {
+					return fmt.Errorf("columns: unknown field %q (use key, summary, description, priority, status, status_category or labels)", source)
+				}
+				if m.Columns == nil {
+					m.Columns = make(map[string]string)
+				}
+				m.Columns[source] = name
+			}
+		}
+	}
+	return nil
+}
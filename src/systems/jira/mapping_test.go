@@ -0,0 +1,109 @@
+package jira
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func writeMapping(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write mapping: %v", err)
+	}
+	return path
+}
+
+func TestLoadMapping(t *testing.T) {
+	mapping, err := LoadMapping(writeMapping(t, `
+statuses:
+  Ready for QA: doing
+  Won't Do: done
+priorities:
+  P1: critical
+  P3: 1
+labels:
+  client-acme: "@client-a"
+keep_labels: true
+columns:
+  summary: Title
+  status: State
+  priority: P
+  labels: Tags
+`))
+	if err != nil {
+		t.Fatalf("LoadMapping() error = %v", err)
+	}
+
+	// File entries add to the defaults
+	if got := mapping.Status(Issue{Status: "ready for qa"}); got != task.StatusDoing {
+		t.Errorf("Ready for QA mapped to %s, want doing", got)
+	}
+	if got := mapping.Status(Issue{Status: "In Progress"}); got != task.StatusDoing {
+		t.Errorf("Default status lost, got %s", got)
+	}
+	if got := mapping.Priority(Issue{Priority: "P3"}); got != task.PriorityLow {
+		t.Errorf("P3 mapped to %d, want low", got)
+	}
+
+	csv := "Key,Title,State,P,Tags,Tags\n" +
+		"A-1,Renew certificate,Won't Do,P1,client-ACME,ops\n"
+	issues, err := mapping.Parse(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(issues) != 1 || issues[0].Summary != "Renew certificate" {
+		t.Fatalf("Expected the Title column as summary, got %+v", issues)
+	}
+	if got := mapping.Contexts(issues[0]); !reflect.DeepEqual(got, []string{"@client-a", "@ops"}) {
+		t.Errorf("Contexts() = %v", got)
+	}
+
+	mapping.KeepLabels = false
+	if got := mapping.Contexts(issues[0]); !reflect.DeepEqual(got, []string{"@client-a"}) {
+		t.Errorf("Contexts() without keep_labels = %v", got)
+	}
+
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	result, err := Import(taskSystem, 1, issues, mapping)
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	imported, _ := taskSystem.GetByID(result.Created[0].ID)
+	if imported.Status != task.StatusDone || imported.Priority != task.PriorityCritical || !reflect.DeepEqual(imported.Contexts, []string{"@client-a"}) {
+		t.Errorf("Unexpected imported task: %+v", imported)
+	}
+}
+
+func TestLoadMapping_Errors(t *testing.T) {
+	invalid := map[string]string{
+		"unknown key":      "colours:\n  red: todo\n",
+		"invalid status":   "statuses:\n  QA: testing\n",
+		"invalid priority": "priorities:\n  P1: urgent\n",
+		"invalid context":  "labels:\n  x: \"two words\"\n",
+		"unknown column":   "columns:\n  assignee: Owner\n",
+		"keep_labels":      "keep_labels: sometimes\n",
+		"not a mapping":    "statuses: doing\n",
+	}
+	for name, content := range invalid {
+		if _, err := LoadMapping(writeMapping(t, content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	if _, err := LoadMapping(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Expected error for a missing mapping file")
+	}
+}
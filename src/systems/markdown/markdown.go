@@ -0,0 +1,245 @@
+// Package markdown renders the Markdown agents write into task descriptions
+// for the terminal: headings, lists, quotes and code blocks, with bold,
+// italic, code and links inline. It covers what descriptions use, not all
+// of CommonMark; anything it does not recognize is shown as written.
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Styles are how each kind of element is shown
+type Styles struct {
+	Heading lipgloss.Style
+	Bold    lipgloss.Style
+	Italic  lipgloss.Style
+	Code    lipgloss.Style
+	Link    lipgloss.Style
+	Quote   lipgloss.Style
+	Muted   lipgloss.Style
+}
+
+// DefaultStyles uses text attributes and the terminal's basic colors, so it
+// reads on dark and light backgrounds alike
+func DefaultStyles() Styles {
+	return Styles{
+		Heading: lipgloss.NewStyle().Bold(true).Underline(true),
+		Bold:    lipgloss.NewStyle().Bold(true),
+		Italic:  lipgloss.NewStyle().Italic(true),
+		Code:    lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		Link:    lipgloss.NewStyle().Foreground(lipgloss.Color("4")).Underline(true),
+		Quote:   lipgloss.NewStyle().Italic(true),
+		Muted:   lipgloss.NewStyle().Faint(true),
+	}
+}
+
+var (
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listPattern    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	taskPattern    = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	rulePattern    = regexp.MustCompile(`^\s*(-\s*){3,}$|^\s*(\*\s*){3,}$|^\s*(_\s*){3,}$`)
+)
+
+// Render renders text wrapped to width cells; a width of 0 or less does
+// not wrap. Code blocks are never wrapped.
+func Render(text string, width int, styles Styles) string {
+	var out []string
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			out = append(out, wrap(inline(strings.Join(paragraph, " "), styles), width, "", "")...)
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			flush()
+			fence := trimmed[:3]
+			if lang := strings.TrimSpace(trimmed[3:]); lang != "" {
+				out = append(out, styles.Muted.Render("  "+lang))
+			}
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				out = append(out, "  "+styles.Code.Render(strings.TrimRight(lines[i], " \t")))
+			}
+
+		case trimmed == "":
+			flush()
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+
+		case headingPattern.MatchString(trimmed):
+			flush()
+			heading := headingPattern.FindStringSubmatch(trimmed)[2]
+			for _, l := range wrap(inline(heading, styles), width, "", "") {
+				out = append(out, styles.Heading.Render(l))
+			}
+
+		case rulePattern.MatchString(line):
+			flush()
+			n := width
+			if n <= 0 || n > 40 {
+				n = 40
+			}
+			out = append(out, styles.Muted.Render(strings.Repeat("─", n)))
+
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			quote := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			bar := styles.Muted.Render("│ ")
+			for _, l := range wrap(inline(quote, styles), width, bar, bar) {
+				out = append(out, styles.Quote.Render(l))
+			}
+
+		case listPattern.MatchString(line):
+			flush()
+			m := listPattern.FindStringSubmatch(line)
+			indent := strings.Repeat(" ", len(strings.ReplaceAll(m[1], "\t", "  ")))
+			marker, item := m[2], m[3]
+			switch {
+			case taskPattern.MatchString(item):
+				t := taskPattern.FindStringSubmatch(item)
+				marker, item = "☐", t[2]
+				if t[1] != " " {
+					marker = "☑"
+				}
+			case !strings.ContainsAny(marker, ".)"):
+				marker = "•"
+			}
+			first := indent + marker + " "
+			hanging := indent + strings.Repeat(" ", lipgloss.Width(marker)+1)
+			out = append(out, wrap(inline(item, styles), width, first, hanging)...)
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return strings.Join(out, "\n")
+}
+
+// word is a word of rendered text with its width on screen
+type word struct {
+	text  string
+	width int
+}
+
+var inlinePattern = regexp.MustCompile("`([^`]+)`" + `|\*\*(.+?)\*\*|__(.+?)__|\[([^\]]+)\]\(([^)\s]+)\)|\*([^*\s][^*]*?)\*|\b_([^_\s][^_]*?)_\b`)
+
+// inline renders the inline markup of a paragraph and splits it into
+// words, each styled on its own so that wrapping never splits an escape
+// sequence
+func inline(text string, styles Styles) []word {
+	var words []word
+	add := func(s string, style *lipgloss.Style) {
+		for _, w := range strings.Fields(s) {
+			rendered := w
+			if style != nil {
+				rendered = style.Render(w)
+			}
+			words = append(words, word{rendered, lipgloss.Width(w)})
+		}
+	}
+
+	for {
+		loc := inlinePattern.FindStringSubmatchIndex(text)
+		if loc == nil {
+			add(text, nil)
+			return words
+		}
+		// Markup glued to the word before it stays part of that word
+		before := text[:loc[0]]
+		group := func(n int) string {
+			if loc[2*n] < 0 {
+				return ""
+			}
+			return text[loc[2*n]:loc[2*n+1]]
+		}
+
+		var parts []word
+		collect := func(s string, style lipgloss.Style) {
+			n := len(words)
+			add(s, &style)
+			parts = append(parts, words[n:]...)
+			words = words[:n]
+		}
+		switch {
+		case group(1) != "":
+			collect(group(1), styles.Code)
+		case group(2) != "":
+			collect(group(2), styles.Bold)
+		case group(3) != "":
+			collect(group(3), styles.Bold)
+		case group(4) != "":
+			collect(group(4), styles.Link)
+			if url := group(5); url != group(4) {
+				collect("("+url+")", styles.Muted)
+			}
+		case group(6) != "":
+			collect(group(6), styles.Italic)
+		case group(7) != "":
+			collect(group(7), styles.Italic)
+		}
+
+		add(before, nil)
+		if len(parts) > 0 && before != "" && !strings.HasSuffix(before, " ") && len(words) > 0 {
+			last := &words[len(words)-1]
+			last.text += parts[0].text
+			last.width += parts[0].width
+			parts = parts[1:]
+		}
+		words = append(words, parts...)
+
+		rest := text[loc[1]:]
+		if rest != "" && !strings.HasPrefix(rest, " ") && len(words) > 0 {
+			// Punctuation right after markup, as in "**done**."
+			end := strings.IndexByte(rest, ' ')
+			if end < 0 {
+				end = len(rest)
+			}
+			if !inlinePattern.MatchString(rest[:end]) {
+				last := &words[len(words)-1]
+				last.text += rest[:end]
+				last.width += lipgloss.Width(rest[:end])
+				rest = rest[end:]
+			}
+		}
+		text = rest
+	}
+}
+
+// wrap lays out words in lines of at most width cells, the first line
+// starting with first and the others with hanging. A word wider than a
+// line gets a line of its own.
+func wrap(words []word, width int, first, hanging string) []string {
+	var lines []string
+	line, lineWidth := first, lipgloss.Width(first)
+	empty := true
+	for _, w := range words {
+		if !empty && width > 0 && lineWidth+1+w.width > width {
+			lines = append(lines, line)
+			line, lineWidth, empty = hanging, lipgloss.Width(hanging), true
+		}
+		if !empty {
+			line += " "
+			lineWidth++
+		}
+		line += w.text
+		lineWidth += w.width
+		empty = false
+	}
+	return append(lines, line)
+}
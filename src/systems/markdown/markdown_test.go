@@ -0,0 +1,49 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{"plain text", "Just a description", 0, "Just a description"},
+		{"inline markup", "Use **bold**, *italic*, `code` and [docs](https://example.com).", 0,
+			"Use bold, italic, code and docs (https://example.com)."},
+		{"paragraphs joined and wrapped", "one two\nthree four five\n\nsix", 10,
+			"one two\nthree four\nfive\n\nsix"},
+		{"heading", "## Plan ##\nDo it", 0, "Plan\nDo it"},
+		{"lists", "- first\n* second\n  + nested\n1. numbered", 0,
+			"• first\n• second\n  • nested\n1. numbered"},
+		{"task list", "- [ ] todo\n- [x] done", 0, "☐ todo\n☑ done"},
+		{"list item wraps under its text", "- alpha beta gamma", 10, "• alpha\n  beta\n  gamma"},
+		{"quote", "> careful here", 0, "│ careful here"},
+		{"code block kept as is", "```go\nfunc  main() {}\n```\nafter", 5,
+			"  go\n  func  main() {}\nafter"},
+		{"unclosed code block", "```\ncode", 0, "  code"},
+		{"rule", "---", 5, "─────"},
+		{"blank lines collapsed", "a\n\n\n\nb\n\n", 0, "a\n\nb"},
+		{"unmatched markup shown as written", "2 * 3 and snake_case_name", 0, "2 * 3 and snake_case_name"},
+		{"markup within a word", "see **this**. ok", 0, "see this. ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.text, tt.width, DefaultStyles()); got != tt.want {
+				t.Errorf("Render(%q, %d) =\n%s\nwant\n%s", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_LongWordNotSplit(t *testing.T) {
+	url := "https://example.com/" + strings.Repeat("x", 30)
+	got := Render("see "+url+" now", 20, DefaultStyles())
+	if got != "see\n"+url+"\nnow" {
+		t.Errorf("Expected a word wider than the line on a line of its own, got\n%s", got)
+	}
+}
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// update rewrites the golden files from what the server answers now:
+//
+//	go test ./src/systems/mcp -run TestConformance -update
+//
+// Agents parse tool output, so review the diff of a rewrite like a change
+// to the protocol.
+var update = flag.Bool("update", false, "rewrite the golden files of the conformance tests")
+
+// sessionsDir holds the scripted sessions: <name>.jsonl has the messages a
+// client sends, one per line, with # comments, and <name>.golden what the
+// server answers
+const sessionsDir = "testdata/sessions"
+
+// TestConformance replays each scripted session against a server on a new
+// board and compares its responses and notifications with the golden ones
+func TestConformance(t *testing.T) {
+	sessions, err := filepath.Glob(filepath.Join(sessionsDir, "*.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) == 0 {
+		t.Fatalf("No sessions in %s", sessionsDir)
+	}
+
+	for _, session := range sessions {
+		name := strings.TrimSuffix(filepath.Base(session), ".jsonl")
+		t.Run(name, func(t *testing.T) {
+			got := replaySession(t, session)
+
+			golden := strings.TrimSuffix(session, ".jsonl") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Output of %s changed (run with -update if on purpose):\n%s", name, diffLines(string(want), string(got)))
+			}
+		})
+	}
+}
+
+// replaySession serves a session's messages through Start, as a client on
+// stdio would send them, and returns the normalized transcript
+func replaySession(t *testing.T, path string) []byte {
+	t.Helper()
+	// Board tools read the boards of the home directory
+	t.Setenv("HOME", t.TempDir())
+
+	script, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var input bytes.Buffer
+	for _, line := range strings.Split(string(script), "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			input.WriteString(line + "\n")
+		}
+	}
+
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create memory database: %v", err)
+	}
+	defer db.Close()
+
+	var output bytes.Buffer
+	server := New(task.New(db.Conn()), &input, &output)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	var transcript bytes.Buffer
+	encoder := json.NewEncoder(&transcript)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	scanner := bufio.NewScanner(&output)
+	scanner.Buffer(nil, maxMessageSize)
+	for scanner.Scan() {
+		var message interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			t.Fatalf("Server wrote a line that is not JSON: %s", scanner.Text())
+		}
+		if err := encoder.Encode(normalize(message)); err != nil {
+			t.Fatal(err)
+		}
+		transcript.WriteString("\n")
+	}
+	return transcript.Bytes()
+}
+
+var (
+	timestampPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?`)
+	datePattern      = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+)
+
+// normalize replaces what differs from one run to the next, the times
+// tasks were created and changed at and their random hashes, so that only
+// the format is compared
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if hash, ok := value.(string); ok && key == "hash" && hash != "" {
+				v[key] = "<hash>"
+				continue
+			}
+			v[key] = normalize(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = normalize(value)
+		}
+	case string:
+		return datePattern.ReplaceAllString(timestampPattern.ReplaceAllString(v, "<time>"), "<date>")
+	}
+	return v
+}
+
+// diffLines reports the first lines where want and got part ways, with a
+// little context, which is enough to find a change in a golden file
+func diffLines(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	i := 0
+	for i < len(wantLines) && i < len(gotLines) && wantLines[i] == gotLines[i] {
+		i++
+	}
+	start := max(i-3, 0)
+
+	var b strings.Builder
+	for _, line := range wantLines[start:min(i+5, len(wantLines))] {
+		b.WriteString("- " + line + "\n")
+	}
+	for _, line := range gotLines[start:min(i+5, len(gotLines))] {
+		b.WriteString("+ " + line + "\n")
+	}
+	return b.String()
+}
@@ -1,14 +1,25 @@
 package mcp
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hmain/cainban/src/systems/automation"
 	"github.com/hmain/cainban/src/systems/board"
+	"github.com/hmain/cainban/src/systems/dateparse"
+	"github.com/hmain/cainban/src/systems/sprint"
+	"github.com/hmain/cainban/src/systems/storage"
 	"github.com/hmain/cainban/src/systems/task"
+	"github.com/hmain/cainban/src/systems/webhook"
 )
 
 // Server implements the MCP (Model Context Protocol) server
@@ -17,6 +28,33 @@ type Server struct {
 	boardSystem *board.System
 	input       io.Reader
 	output      io.Writer
+
+	// handoffWebhook is posted to when a task is handed off, if set
+	handoffWebhook string
+	// wipLimit caps the tasks in progress get_next_task allows, 0 for none
+	wipLimit int
+	// automations run when a task status update moves it into a column
+	automations *automation.System
+	boardName   string
+	// db is the database of the board set with UseBoard, closed when the
+	// server moves to another; openBoard opens that other board
+	db        *storage.DB
+	openBoard BoardOpener
+	// autoBackup backs a board up before delete_board deletes it, keeping
+	// keepBackups of its backups, 0 for all
+	autoBackup  bool
+	keepBackups int
+	// hooks run before and after tasks are created, moved and deleted
+	hooks *automation.Hooks
+	// notifications are sent to the client after the current response
+	notifications []MCPNotification
+	// requestTimeout bounds the time one request may take
+	requestTimeout time.Duration
+	// actor is who changes are recorded as; without one, the client that
+	// connected, by the name it gave in initialize
+	actor, client string
+	// sprints of the board, for the sprint tools
+	sprints *sprint.System
 }
 
 // New creates a new MCP server
@@ -26,15 +64,53 @@ func New(taskSystem *task.System, input io.Reader, output io.Writer) *Server {
 		boardSystem: board.New(),
 		input:       input,
 		output:      output,
+
+		requestTimeout: defaultRequestTimeout,
 	}
 }
 
+// defaultRequestTimeout is how long a request may take before its queries
+// are cancelled and it fails
+const defaultRequestTimeout = 30 * time.Second
+
 // MCPRequest represents an MCP request
 type MCPRequest struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      interface{}     `json:"id"`
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"`
+
+	// ctx is what the request is served under, see Context
+	ctx context.Context
+}
+
+// Context returns the context the request is served under, cancelled when
+// the server stops or the request runs past its timeout
+func (r *MCPRequest) Context() context.Context {
+	if r.ctx != nil {
+		return r.ctx
+	}
+	return context.Background()
+}
+
+// tasks returns the task system with its queries bound to the context of
+// req, so they give up with the request
+func (s *Server) tasks(req *MCPRequest) *task.System {
+	return s.taskSystem.WithContext(req.Context()).WithActor(s.actorName())
+}
+
+// actorName returns who the changes made through the server are recorded
+// as: the actor set, else the client, so that an agent's changes are told
+// apart from its user's
+func (s *Server) actorName() string {
+	switch {
+	case s.actor != "":
+		return s.actor
+	case s.client != "":
+		return s.client
+	default:
+		return "mcp"
+	}
 }
 
 // MCPResponse represents an MCP response
@@ -52,6 +128,168 @@ type MCPError struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// MCPNotification is a JSON-RPC notification sent from the server to the
+// client; it has no ID and expects no response
+type MCPNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// SetHandoffWebhook configures a URL that handoff events are posted to
+func (s *Server) SetHandoffWebhook(url string) {
+	s.handoffWebhook = url
+}
+
+// SetActor sets who the changes made through the server are recorded as,
+// instead of the client
+func (s *Server) SetActor(actor string) {
+	s.actor = strings.TrimSpace(actor)
+}
+
+// SetRequestTimeout sets how long one request may take, 0 for no limit
+func (s *Server) SetRequestTimeout(timeout time.Duration) {
+	s.requestTimeout = timeout
+}
+
+// SetWIPLimit sets the limit on tasks in progress that get_next_task
+// respects
+func (s *Server) SetWIPLimit(limit int) {
+	s.wipLimit = limit
+}
+
+// SetAutomations enables the automations and rules of the board the
+// server's tasks live on
+func (s *Server) SetAutomations(automations *automation.System, boardName string) {
+	s.automations = automations
+	s.boardName = boardName
+}
+
+// SetSprints enables the sprint tools, on the sprints of the board the
+// server's tasks live on
+func (s *Server) SetSprints(sprints *sprint.System) {
+	s.sprints = sprints
+}
+
+// Board is a board's database with the systems the server works on it
+// with, set all together with UseBoard
+type Board struct {
+	Name        string
+	DB          *storage.DB
+	Tasks       *task.System
+	Sprints     *sprint.System
+	Automations *automation.System // nil to run none, e.g. in a sandbox
+	Hooks       *automation.Hooks  // nil to run none
+}
+
+// BoardOpener opens a board by name, the way the server's first board was
+// opened
+type BoardOpener func(name string) (*Board, error)
+
+// UseBoard moves the server onto a board, closing the database of the
+// board it was on before, if that came from UseBoard too
+func (s *Server) UseBoard(b *Board) {
+	if s.db != nil && s.db != b.DB {
+		if err := s.db.Close(); err != nil {
+			log.Printf("Error closing board '%s': %v", s.boardName, err)
+		}
+	}
+	s.db = b.DB
+	s.taskSystem = b.Tasks
+	s.sprints = b.Sprints
+	s.automations = b.Automations
+	s.hooks = b.Hooks
+	s.boardName = b.Name
+}
+
+// SetBoardOpener makes change_board move the server onto the board it
+// changes to, opened with open. Without one, the server stays on its board
+// and only later commands use the new one.
+func (s *Server) SetBoardOpener(open BoardOpener) {
+	s.openBoard = open
+}
+
+// Close closes the database of the board set with UseBoard
+func (s *Server) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	err := s.db.Close()
+	s.db = nil
+	return err
+}
+
+// SetBoards sets the board system the board tools work with, configured
+// like the one that picked boardName, the board the server's tasks live on
+func (s *Server) SetBoards(boards *board.System, boardName string) {
+	s.boardSystem = boards
+	s.boardName = boardName
+}
+
+// SetAutoBackup makes delete_board back a board up before deleting it,
+// keeping the latest keep backups of each board, 0 for all
+func (s *Server) SetAutoBackup(keep int) {
+	s.autoBackup = true
+	s.keepBackups = keep
+}
+
+// SetHooks enables the hooks scripts for the changes made through the
+// server
+func (s *Server) SetHooks(hooks *automation.Hooks, boardName string) {
+	s.hooks = hooks
+	s.boardName = boardName
+}
+
+// runHook runs a hook for a change. The error of a pre- hook refuses the
+// change; a post- hook's failure is only told to the client.
+func (s *Server) runHook(ctx context.Context, event automation.HookEvent) error {
+	if s.hooks == nil {
+		return nil
+	}
+	event.Board = s.boardName
+	err := s.hooks.Run(ctx, event)
+	if err != nil && !strings.HasPrefix(event.Hook, "pre-") {
+		s.notify("hook_failed", map[string]interface{}{"hook": event.Hook, "task_id": event.Task.ID, "error": err.Error()})
+		return nil
+	}
+	return err
+}
+
+// runAutomations fires pending automations and rules and tells the client
+// how they went
+func (s *Server) runAutomations(ctx context.Context) {
+	if s.automations == nil {
+		return
+	}
+
+	firings, err := s.automations.WithContext(ctx).Run(s.boardName)
+	for _, f := range firings {
+		payload := map[string]interface{}{"rule": f.Rule, "task_id": f.TaskID}
+		if f.Err != nil {
+			payload["error"] = f.Err.Error()
+			s.notify("automation_failed", payload)
+		} else {
+			s.notify("automation_fired", payload)
+		}
+	}
+	if err != nil {
+		s.notify("automation_failed", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// notify queues a notifications/message log entry for the client
+func (s *Server) notify(event string, data interface{}) {
+	s.notifications = append(s.notifications, MCPNotification{
+		JSONRPC: "2.0",
+		Method:  "notifications/message",
+		Params: map[string]interface{}{
+			"level":  "info",
+			"logger": "cainban",
+			"data":   map[string]interface{}{"event": event, "payload": data},
+		},
+	})
+}
+
 // Tool represents an MCP tool definition
 type Tool struct {
 	Name        string      `json:"name"`
@@ -59,28 +297,180 @@ type Tool struct {
 	InputSchema interface{} `json:"inputSchema"`
 }
 
-// Start starts the MCP server
-func (s *Server) Start() error {
-	decoder := json.NewDecoder(s.input)
+// Resource represents an MCP resource
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	MimeType    string `json:"mimeType"`
+}
+
+// readmeURI is the resource holding the board's readme
+const readmeURI = "cainban://board/readme"
+
+// Start serves requests until the input ends. Messages are JSON-RPC
+// requests or batches of them, one per line as the stdio transport
+// requires; a line that cannot be read as a request gets an error response
+// and the next line is served. Each request is served under ctx, with the
+// server's request timeout; once ctx is done Start returns its error.
+func (s *Server) Start(ctx context.Context) error {
+	// Read in the background, so that a client sending nothing does not
+	// keep the server from stopping
+	messages := make(chan message)
+	go func() {
+		reader := bufio.NewReader(s.input)
+		for {
+			line, tooLong, err := readMessage(reader)
+			select {
+			case messages <- message{line: line, tooLong: tooLong, err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
 	encoder := json.NewEncoder(s.output)
+	for {
+		var m message
+		select {
+		case m = <-messages:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		line, err := m.line, m.err
+		if m.tooLong {
+			if err := encoder.Encode(s.errorResponse(nil, -32600, "Request too large")); err != nil {
+				log.Printf("Error encoding response: %v", err)
+			}
+		} else if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handle(ctx, line); resp != nil {
+				if err := encoder.Encode(resp); err != nil {
+					log.Printf("Error encoding response: %v", err)
+				}
+			}
+
+			for _, n := range s.notifications {
+				if err := encoder.Encode(n); err != nil {
+					log.Printf("Error encoding notification: %v", err)
+				}
+			}
+			s.notifications = nil
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read request: %w", err)
+		}
+	}
+}
 
+// message is a line read from the client, or the error that ended the
+// input
+type message struct {
+	line    []byte
+	tooLong bool
+	err     error
+}
+
+// maxMessageSize bounds one message, so that a client never sending a
+// newline cannot make the server buffer without end
+const maxMessageSize = 4 << 20
+
+// readMessage reads the next line. A line longer than maxMessageSize is
+// skipped up to its newline and reported as too long, which puts the reader
+// back in step with the client's framing.
+func readMessage(r *bufio.Reader) (line []byte, tooLong bool, err error) {
 	for {
-		var req MCPRequest
-		if err := decoder.Decode(&req); err != nil {
-			if err == io.EOF {
-				break
+		chunk, err := r.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > maxMessageSize {
+				line, tooLong = nil, true
+			} else {
+				line = append(line, chunk...)
 			}
-			log.Printf("Error decoding request: %v", err)
-			continue
 		}
+		if err != bufio.ErrBufferFull {
+			return line, tooLong, err
+		}
+	}
+}
 
-		resp := s.handleRequest(&req)
-		if err := encoder.Encode(resp); err != nil {
-			log.Printf("Error encoding response: %v", err)
+// handle answers one message, either a single request or a batch: an array
+// of requests answered by an array of their responses, in the same order.
+// It returns nil when there is nothing to answer, as for a notification or
+// a batch of them. It knows nothing of the transport, which only frames
+// messages and writes out what handle returns.
+func (s *Server) handle(ctx context.Context, message []byte) interface{} {
+	trimmed := bytes.TrimSpace(message)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		if resp := s.serve(ctx, message); resp != nil {
+			return resp
 		}
+		return nil
 	}
 
-	return nil
+	var batch []json.RawMessage
+	if err := json.Unmarshal(trimmed, &batch); err != nil {
+		// Not valid JSON, which serve reports as a parse error
+		return s.serve(ctx, message)
+	}
+	if len(batch) == 0 {
+		return s.errorResponse(nil, -32600, "Invalid Request")
+	}
+
+	var responses []*MCPResponse
+	for _, req := range batch {
+		if resp := s.serve(ctx, req); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	if len(responses) == 0 {
+		return nil
+	}
+	return responses
+}
+
+// serve handles one request. It returns nil for notifications, which the
+// client sent without an ID and expects no response to.
+func (s *Server) serve(ctx context.Context, line []byte) (resp *MCPResponse) {
+	var req MCPRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		resp := s.errorResponse(nil, -32600, "Invalid Request")
+		if !json.Valid(line) {
+			resp = s.errorResponse(nil, -32700, "Parse error")
+		}
+		resp.Error.Data = err.Error()
+		return resp
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return s.errorResponse(req.ID, -32600, "Invalid Request")
+	}
+
+	req.ctx = ctx
+	if s.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		req.ctx, cancel = context.WithTimeout(ctx, s.requestTimeout)
+		defer cancel()
+	}
+
+	// A bug in one tool must not take the whole server down
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Panic handling %s: %v", req.Method, r)
+			resp = s.errorResponse(req.ID, -32603, "Internal error")
+		}
+		if req.ID == nil {
+			resp = nil
+		}
+	}()
+
+	return s.handleRequest(&req)
 }
 
 // handleRequest processes an MCP request
@@ -92,6 +482,10 @@ func (s *Server) handleRequest(req *MCPRequest) *MCPResponse {
 		return s.handleToolsList(req)
 	case "tools/call":
 		return s.handleToolsCall(req)
+	case "resources/list":
+		return s.handleResourcesList(req)
+	case "resources/read":
+		return s.handleResourcesRead(req)
 	default:
 		return &MCPResponse{
 			JSONRPC: "2.0",
@@ -106,10 +500,21 @@ func (s *Server) handleRequest(req *MCPRequest) *MCPResponse {
 
 // handleInitialize handles the initialize request
 func (s *Server) handleInitialize(req *MCPRequest) *MCPResponse {
+	var params struct {
+		ClientInfo struct {
+			Name string `json:"name"`
+		} `json:"clientInfo"`
+	}
+	if len(req.Params) > 0 && json.Unmarshal(req.Params, &params) == nil {
+		s.client = strings.TrimSpace(params.ClientInfo.Name)
+	}
+
 	result := map[string]interface{}{
 		"protocolVersion": "2024-11-05",
 		"capabilities": map[string]interface{}{
-			"tools": map[string]interface{}{},
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
+			"logging":   map[string]interface{}{},
 		},
 		"serverInfo": map[string]interface{}{
 			"name":    "cainban",
@@ -124,6 +529,67 @@ func (s *Server) handleInitialize(req *MCPRequest) *MCPResponse {
 	}
 }
 
+// handleResourcesList handles the resources/list request. The board's
+// readme is listed once it has one, so clients load the board's
+// conventions along with its tools.
+func (s *Server) handleResourcesList(req *MCPRequest) *MCPResponse {
+	resources := []Resource{}
+
+	readme, err := s.tasks(req).GetReadme()
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to get board readme: %v", err))
+	}
+	if readme != nil {
+		resources = append(resources, Resource{
+			URI:         readmeURI,
+			Name:        "Board README",
+			Description: "Goals, conventions and glossary of the board; read before working on its tasks",
+			MimeType:    "text/markdown",
+		})
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  map[string]interface{}{"resources": resources},
+	}
+}
+
+// handleResourcesRead handles the resources/read request
+func (s *Server) handleResourcesRead(req *MCPRequest) *MCPResponse {
+	var params struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return s.errorResponse(req.ID, -32602, "Invalid params")
+	}
+	if params.URI != readmeURI {
+		return s.errorResponse(req.ID, codeNotFound, fmt.Sprintf("Resource not found: %s", params.URI))
+	}
+
+	readme, err := s.tasks(req).GetReadme()
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to get board readme: %v", err))
+	}
+	if readme == nil {
+		return s.errorResponse(req.ID, codeNotFound, "The board has no readme")
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"contents": []map[string]interface{}{
+				{
+					"uri":      readmeURI,
+					"mimeType": "text/markdown",
+					"text":     readme.Content,
+				},
+			},
+		},
+	}
+}
+
 // handleToolsList handles the tools/list request
 func (s *Server) handleToolsList(req *MCPRequest) *MCPResponse {
 	tools := []Tool{
@@ -173,6 +639,45 @@ func (s *Server) handleToolsList(req *MCPRequest) *MCPResponse {
 						"description": "Filter by status (todo, doing, done)",
 						"enum":        []string{"todo", "doing", "done"},
 					},
+					"blocked": map[string]interface{}{
+						"type":        "boolean",
+						"description": "true for only tasks waiting on unfinished blockers, false for only tasks that can be worked on now",
+					},
+					"sprint": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "Only the tasks planned for a sprint: its ID or name, \"current\" for the one under way, or \"none\" for the tasks planned for no sprint",
+					},
+					"page": map[string]interface{}{
+						"type":        "integer",
+						"description": "The page of tasks to return, from 1; the response tells how many pages there are",
+						"default":     1,
+					},
+					"page_size": map[string]interface{}{
+						"type":        "integer",
+						"description": fmt.Sprintf("Tasks per page (at most %d)", maxPageSize),
+						"default":     defaultPageSize,
+					},
+				},
+			},
+		},
+		{
+			Name:        "get_next_task",
+			Description: "Get the single best task to work on now: the most pressing unblocked todo task by due date and priority, or a task in progress to finish when the WIP limit is reached",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "get_board_summary",
+			Description: "Get an overview of the board in one call: task counts per column, open high-priority tasks, overdue tasks and recently completed tasks",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"days": map[string]interface{}{
+						"type":        "integer",
+						"description": "How many days back counts as recently completed (default 7)",
+					},
 				},
 			},
 		},
@@ -183,8 +688,8 @@ func (s *Server) handleToolsList(req *MCPRequest) *MCPResponse {
 				"type": "object",
 				"properties": map[string]interface{}{
 					"id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The task ID",
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
 					},
 					"status": map[string]interface{}{
 						"type":        "string",
@@ -202,8 +707,8 @@ func (s *Server) handleToolsList(req *MCPRequest) *MCPResponse {
 				"type": "object",
 				"properties": map[string]interface{}{
 					"id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The task ID",
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
 					},
 				},
 				"required": []string{"id"},
@@ -216,8 +721,8 @@ func (s *Server) handleToolsList(req *MCPRequest) *MCPResponse {
 				"type": "object",
 				"properties": map[string]interface{}{
 					"id": map[string]interface{}{
-						"type":        "integer",
-						"description": "Task ID to update",
+						"type":        []string{"integer", "string"},
+						"description": "Task ID or hash to update",
 					},
 					"priority": map[string]interface{}{
 						"description": "Priority level (none, low, medium, high, critical or 0-4)",
@@ -237,8 +742,8 @@ func (s *Server) handleToolsList(req *MCPRequest) *MCPResponse {
 				"type": "object",
 				"properties": map[string]interface{}{
 					"id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The task ID",
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
 					},
 					"title": map[string]interface{}{
 						"type":        "string",
@@ -253,126 +758,426 @@ func (s *Server) handleToolsList(req *MCPRequest) *MCPResponse {
 			},
 		},
 		{
-			Name:        "list_boards",
-			Description: "List all available kanban boards",
+			Name:        "assign_task",
+			Description: "Assign a task to a person or agent, warning when their capacity is exceeded",
 			InputSchema: map[string]interface{}{
-				"type":       "object",
-				"properties": map[string]interface{}{},
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
+					},
+					"assignee": map[string]interface{}{
+						"type":        "string",
+						"description": "Who to assign the task to (empty to unassign)",
+					},
+				},
+				"required": []string{"id", "assignee"},
 			},
 		},
 		{
-			Name:        "link_tasks",
-			Description: "Create a link between two tasks",
+			Name:        "react_to_task",
+			Description: "React to a task with an emoji to signal agreement or attention without a comment; each actor counts once per emoji",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"from_task_id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The ID of the source task",
+					"id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
 					},
-					"to_task_id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The ID of the target task",
+					"emoji": map[string]interface{}{
+						"type":        "string",
+						"description": "The reaction, e.g. 👍 or 👀",
 					},
-					"link_type": map[string]interface{}{
+					"actor": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of link (blocks, blocked_by, related, depends_on)",
-						"enum":        []string{"blocks", "blocked_by", "related", "depends_on"},
-						"default":     "blocks",
+						"description": "Who is reacting, e.g. the agent's name",
+					},
+					"remove": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Take the reaction back instead",
 					},
 				},
-				"required": []string{"from_task_id", "to_task_id"},
+				"required": []string{"id", "emoji", "actor"},
 			},
 		},
 		{
-			Name:        "unlink_tasks",
-			Description: "Remove a link between two tasks",
+			Name:        "set_task_context",
+			Description: "Save working state for a task (files touched, decisions, next steps) so a later session can resume it",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"from_task_id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The ID of the source task",
-					},
-					"to_task_id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The ID of the target task",
+					"id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
 					},
-					"link_type": map[string]interface{}{
+					"content": map[string]interface{}{
 						"type":        "string",
-						"description": "Type of link to remove (blocks, blocked_by, related, depends_on)",
-						"enum":        []string{"blocks", "blocked_by", "related", "depends_on"},
-						"default":     "blocks",
+						"description": "The working state, typically Markdown",
+					},
+					"append": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Append to the existing context instead of replacing it",
+						"default":     false,
 					},
 				},
-				"required": []string{"from_task_id", "to_task_id"},
+				"required": []string{"id", "content"},
 			},
 		},
 		{
-			Name:        "get_task_links",
-			Description: "Get all links for a specific task",
+			Name:        "get_task_context",
+			Description: "Load the working state saved for a task",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"task_id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The task ID to get links for",
+					"id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
 					},
 				},
-				"required": []string{"task_id"},
+				"required": []string{"id"},
 			},
 		},
 		{
-			Name:        "delete_task",
-			Description: "Delete a task (soft delete by default)",
+			Name:        "append_worklog",
+			Description: "Record a step of your work on a task: what you did, the files you changed and how it went, so the trace stays with the task",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"task_id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The task ID to delete",
+					"id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
 					},
-					"hard_delete": map[string]interface{}{
-						"type":        "boolean",
-						"description": "Whether to permanently delete the task",
-						"default":     false,
+					"summary": map[string]interface{}{
+						"type":        "string",
+						"description": "What was done in this step",
+					},
+					"files_changed": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Paths of the files created, changed or deleted",
+					},
+					"outcome": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"success", "partial", "failed", "blocked"},
+						"description": "How the step ended",
 					},
 				},
-				"required": []string{"task_id"},
+				"required": []string{"id", "summary"},
 			},
 		},
 		{
-			Name:        "restore_task",
-			Description: "Restore a soft-deleted task",
+			Name:        "get_worklogs",
+			Description: "Read the worklog of a task, oldest entry first, to see what was already tried",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"task_id": map[string]interface{}{
-						"type":        "integer",
-						"description": "The task ID to restore",
+					"id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
 					},
 				},
-				"required": []string{"task_id"},
+				"required": []string{"id"},
 			},
 		},
 		{
-			Name:        "change_board",
-			Description: "Change the active kanban board",
+			Name:        "handoff_task",
+			Description: "Hand a task off to another agent: reassigns it and leaves a context note as a comment",
 			InputSchema: map[string]interface{}{
 				"type": "object",
 				"properties": map[string]interface{}{
-					"board_name": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
+					},
+					"agent": map[string]interface{}{
 						"type":        "string",
-						"description": "The name of the board to switch to",
+						"description": "The agent or person taking over the task",
+					},
+					"note": map[string]interface{}{
+						"type":        "string",
+						"description": "Context for the new assignee: what is done, what is next, gotchas",
 					},
 				},
-				"required": []string{"board_name"},
+				"required": []string{"id", "agent"},
 			},
 		},
-	}
-
-	return &MCPResponse{
-		JSONRPC: "2.0",
+		{
+			Name:        "claim_task",
+			Description: "Claim a task before working on it, moving it to doing, so that other agents on the board do not pick it too. Fails if another agent has claimed it",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
+					},
+				},
+				"required": []string{"id"},
+			},
+		},
+		{
+			Name:        "release_task",
+			Description: "Give up your claim on a task you are not going to finish, leaving it in doing for another agent to claim",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash",
+					},
+				},
+				"required": []string{"id"},
+			},
+		},
+		{
+			Name:        "search_all_boards",
+			Description: "Fuzzy-search task titles across every board, tagging each result with its board name",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "Words to look for in task titles",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+		{
+			Name:        "list_boards",
+			Description: "List all available kanban boards",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "link_tasks",
+			Description: "Create a link between two tasks",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"from_task_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The ID or hash of the source task",
+					},
+					"to_task_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The ID or hash of the target task",
+					},
+					"link_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of link (blocks, blocked_by, related, depends_on)",
+						"enum":        []string{"blocks", "blocked_by", "related", "depends_on"},
+						"default":     "blocks",
+					},
+				},
+				"required": []string{"from_task_id", "to_task_id"},
+			},
+		},
+		{
+			Name:        "unlink_tasks",
+			Description: "Remove a link between two tasks",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"from_task_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The ID or hash of the source task",
+					},
+					"to_task_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The ID or hash of the target task",
+					},
+					"link_type": map[string]interface{}{
+						"type":        "string",
+						"description": "Type of link to remove (blocks, blocked_by, related, depends_on)",
+						"enum":        []string{"blocks", "blocked_by", "related", "depends_on"},
+						"default":     "blocks",
+					},
+				},
+				"required": []string{"from_task_id", "to_task_id"},
+			},
+		},
+		{
+			Name:        "get_task_links",
+			Description: "Get all links for a specific task",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash to get links for",
+					},
+				},
+				"required": []string{"task_id"},
+			},
+		},
+		{
+			Name:        "delete_task",
+			Description: "Delete a task (soft delete by default)",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The task ID or hash to delete",
+					},
+					"hard_delete": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Whether to permanently delete the task",
+						"default":     false,
+					},
+				},
+				"required": []string{"task_id"},
+			},
+		},
+		{
+			Name:        "restore_task",
+			Description: "Restore a soft-deleted task",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"task_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "The task ID to restore",
+					},
+				},
+				"required": []string{"task_id"},
+			},
+		},
+		{
+			Name:        "change_board",
+			Description: "Change the active kanban board",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"board_name": map[string]interface{}{
+						"type":        "string",
+						"description": "The name of the board to switch to",
+					},
+				},
+				"required": []string{"board_name"},
+			},
+		},
+		{
+			Name:        "create_board",
+			Description: "Create a kanban board, e.g. for a new project",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"board_name": map[string]interface{}{
+						"type":        "string",
+						"description": "The name of the new board",
+					},
+					"description": map[string]interface{}{
+						"type":        "string",
+						"description": "What the board is for",
+					},
+					"switch": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Make the new board the active one, as cainban init does",
+					},
+				},
+				"required": []string{"board_name"},
+			},
+		},
+		{
+			Name:        "delete_board",
+			Description: "Move a kanban board to the trash, from where cainban board restore brings it back. A remote board only loses its local copy.",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"board_name": map[string]interface{}{
+						"type":        "string",
+						"description": "The name of the board to delete",
+					},
+					"confirm": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Must be true: the board is deleted only when confirmed",
+					},
+				},
+				"required": []string{"board_name", "confirm"},
+			},
+		},
+		{
+			Name:        "list_sprints",
+			Description: "List the sprints of the board with how much of each is done",
+			InputSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{},
+			},
+		},
+		{
+			Name:        "create_sprint",
+			Description: "Create a sprint, two weeks long unless given an end date",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "The sprint name, e.g. \"Sprint 12\"",
+					},
+					"start": map[string]interface{}{
+						"type":        "string",
+						"description": "The first day, e.g. \"monday\" or \"2026-11-02\" (defaults to today)",
+					},
+					"end": map[string]interface{}{
+						"type":        "string",
+						"description": "The last day (defaults to two weeks from the start)",
+					},
+					"capacity": map[string]interface{}{
+						"type":        "integer",
+						"description": "Points of estimated work the sprint can take; planning past them warns (0: no limit)",
+						"minimum":     0,
+					},
+				},
+				"required": []string{"name"},
+			},
+		},
+		{
+			Name:        "get_sprint",
+			Description: "Get a sprint with the tasks planned for it and its burndown, the work left each day against the ideal",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"sprint": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The sprint ID or name, or \"current\" for the one under way",
+						"default":     "current",
+					},
+				},
+			},
+		},
+		{
+			Name:        "plan_sprint",
+			Description: "Plan tasks for a sprint, or take tasks out of theirs",
+			InputSchema: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"sprint": map[string]interface{}{
+						"type":        []string{"integer", "string"},
+						"description": "The sprint ID or name, or \"current\" for the one under way",
+					},
+					"add": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": []string{"integer", "string"}},
+						"description": "IDs or hashes of the tasks to plan for the sprint",
+					},
+					"remove": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": []string{"integer", "string"}},
+						"description": "IDs or hashes of the tasks to take out of the sprint",
+					},
+				},
+				"required": []string{"sprint"},
+			},
+		},
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
 		ID:      req.ID,
 		Result: map[string]interface{}{
 			"tools": tools,
@@ -399,33 +1204,91 @@ func (s *Server) handleToolsCall(req *MCPRequest) *MCPResponse {
 		}
 	}
 
-	switch params.Name {
+	resp := s.callTool(req, params.Name, params.Arguments)
+	// A remote board's copy sends the call's changes now, rather than
+	// when the server stops
+	var flushErr error
+	if s.db != nil {
+		if flushErr = s.db.Flush(); flushErr != nil {
+			log.Printf("Error sending board '%s': %v", s.boardName, flushErr)
+		}
+	}
+	// Tell the client which board the call worked on, since change_board
+	// can move the server onto another
+	if result, ok := resp.Result.(map[string]interface{}); ok && s.boardName != "" {
+		result["active_board"] = s.boardName
+		if flushErr != nil {
+			result["warning"] = flushErr.Error()
+		}
+	}
+	return resp
+}
+
+// callTool serves a tool call by the tool's name
+func (s *Server) callTool(req *MCPRequest, name string, args map[string]interface{}) *MCPResponse {
+	switch name {
 	case "create_task":
-		return s.handleCreateTask(req, params.Arguments)
+		return s.handleCreateTask(req, args)
 	case "list_tasks":
-		return s.handleListTasks(req, params.Arguments)
+		return s.handleListTasks(req, args)
+	case "get_next_task":
+		return s.handleGetNextTask(req, args)
+	case "get_board_summary":
+		return s.handleGetBoardSummary(req, args)
 	case "update_task_status":
-		return s.handleUpdateTaskStatus(req, params.Arguments)
+		return s.handleUpdateTaskStatus(req, args)
 	case "get_task":
-		return s.handleGetTask(req, params.Arguments)
+		return s.handleGetTask(req, args)
 	case "update_task_priority":
-		return s.handleUpdateTaskPriority(req, params.Arguments)
+		return s.handleUpdateTaskPriority(req, args)
 	case "update_task":
-		return s.handleUpdateTask(req, params.Arguments)
+		return s.handleUpdateTask(req, args)
+	case "assign_task":
+		return s.handleAssignTask(req, args)
+	case "react_to_task":
+		return s.handleReactToTask(req, args)
+	case "handoff_task":
+		return s.handleHandoffTask(req, args)
+	case "claim_task":
+		return s.handleClaimTask(req, args)
+	case "release_task":
+		return s.handleReleaseTask(req, args)
+	case "set_task_context":
+		return s.handleSetTaskContext(req, args)
+	case "get_task_context":
+		return s.handleGetTaskContext(req, args)
+	case "append_worklog":
+		return s.handleAppendWorklog(req, args)
+	case "get_worklogs":
+		return s.handleGetWorklogs(req, args)
+	case "search_all_boards":
+		return s.handleSearchAllBoards(req, args)
 	case "list_boards":
-		return s.handleListBoards(req, params.Arguments)
+		return s.handleListBoards(req, args)
 	case "change_board":
-		return s.handleChangeBoard(req, params.Arguments)
+		return s.handleChangeBoard(req, args)
+	case "create_board":
+		return s.handleCreateBoard(req, args)
+	case "delete_board":
+		return s.handleDeleteBoard(req, args)
 	case "link_tasks":
-		return s.handleLinkTasks(req, params.Arguments)
+		return s.handleLinkTasks(req, args)
 	case "unlink_tasks":
-		return s.handleUnlinkTasks(req, params.Arguments)
+		return s.handleUnlinkTasks(req, args)
 	case "get_task_links":
-		return s.handleGetTaskLinks(req, params.Arguments)
+		return s.handleGetTaskLinks(req, args)
 	case "delete_task":
-		return s.handleDeleteTask(req, params.Arguments)
+		return s.handleDeleteTask(req, args)
 	case "restore_task":
-		return s.handleRestoreTask(req, params.Arguments)
+		return s.handleRestoreTask(req, args)
+	case "list_sprints":
+		return s.handleListSprints(req, args)
+	case "create_sprint":
+		return s.handleCreateSprint(req, args)
+	case "get_sprint":
+		return s.handleGetSprint(req, args)
+	case "plan_sprint":
+		return s.handlePlanSprint(req, args)
 	default:
 		return &MCPResponse{
 			JSONRPC: "2.0",
@@ -455,19 +1318,30 @@ func (s *Server) handleCreateTask(req *MCPRequest, args map[string]interface{})
 	var createdTask *task.Task
 	var err error
 
+	priority, hasPriority := args["priority"]
+	if hasPriority && !task.IsValidPriority(priority) {
+		return s.errorResponse(req.ID, -32602, "Invalid priority level")
+	}
+	draft := &task.Task{Title: title, Description: description, Status: task.StatusTodo}
+	if hasPriority {
+		draft.Priority, _ = task.ParsePriority(priority)
+	}
+	if err := s.runHook(req.Context(), automation.HookEvent{Hook: automation.HookPreCreate, Task: draft, ToStatus: task.StatusTodo}); err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to create task: %v", err))
+	}
+
 	// Check if priority is provided
-	if priority, hasPriority := args["priority"]; hasPriority {
-		if !task.IsValidPriority(priority) {
-			return s.errorResponse(req.ID, -32602, "Invalid priority level")
-		}
-		createdTask, err = s.taskSystem.CreateWithPriority(boardID, title, description, priority)
+	if hasPriority {
+		createdTask, err = s.tasks(req).CreateWithPriority(boardID, title, description, priority)
 	} else {
-		createdTask, err = s.taskSystem.Create(boardID, title, description)
+		createdTask, err = s.tasks(req).Create(boardID, title, description)
 	}
 
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to create task: %v", err))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to create task: %v", err))
 	}
+	s.runHook(req.Context(), automation.HookEvent{Hook: automation.HookPostCreate, Task: createdTask, ToStatus: createdTask.Status})
+	s.runAutomations(req.Context())
 
 	priorityStr := ""
 	if createdTask.Priority > 0 {
@@ -489,6 +1363,13 @@ func (s *Server) handleCreateTask(req *MCPRequest, args map[string]interface{})
 	}
 }
 
+// defaultPageSize and maxPageSize bound how many tasks list_tasks returns
+// at once, so that a large board does not flood the agent's context
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
 // handleListTasks handles the list_tasks tool call
 func (s *Server) handleListTasks(req *MCPRequest, args map[string]interface{}) *MCPResponse {
 	boardID := 1 // Default board
@@ -496,26 +1377,60 @@ func (s *Server) handleListTasks(req *MCPRequest, args map[string]interface{}) *
 		boardID = int(bid)
 	}
 
-	var tasks []*task.Task
-	var err error
-
+	// Tasks come in the order the board keeps its columns in
+	opts := task.ListOptions{BoardOrder: true}
 	if statusStr, ok := args["status"].(string); ok {
-		status := task.Status(statusStr)
 		if !task.IsValidStatus(statusStr) {
 			return s.errorResponse(req.ID, -32602, "Invalid status")
 		}
-		tasks, err = s.taskSystem.ListByStatus(boardID, status)
-	} else {
-		tasks, err = s.taskSystem.List(boardID)
+		opts.Status = task.Status(statusStr)
 	}
+	if blocked, ok := args["blocked"].(bool); ok {
+		opts.Blocked = &blocked
+	}
+	if ref, ok := args["sprint"]; ok {
+		if value, _ := ref.(string); strings.EqualFold(value, sprint.None) {
+			opts.NoSprint = true
+		} else {
+			sp, errResp := s.sprintArg(req, args, "sprint")
+			if errResp != nil {
+				return errResp
+			}
+			opts.SprintID = sp.ID
+		}
+	}
+
+	page, pageSize := 1, defaultPageSize
+	if p, ok := args["page"].(float64); ok {
+		if page = int(p); page < 1 {
+			return s.errorResponse(req.ID, -32602, "page must be 1 or more")
+		}
+	}
+	if size, ok := args["page_size"].(float64); ok {
+		if pageSize = int(size); pageSize < 1 || pageSize > maxPageSize {
+			return s.errorResponse(req.ID, -32602, fmt.Sprintf("page_size must be between 1 and %d", maxPageSize))
+		}
+	}
+	opts.Limit, opts.Offset = pageSize, (page-1)*pageSize
 
+	total, err := s.tasks(req).CountWith(boardID, opts)
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to list tasks: %v", err))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to list tasks: %v", err))
 	}
+	tasks, err := s.tasks(req).ListWith(boardID, opts)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to list tasks: %v", err))
+	}
+	pages := (total + pageSize - 1) / pageSize
 
 	// Format tasks for display with board context
 	var content []map[string]interface{}
-	if len(tasks) == 0 {
+	if len(tasks) == 0 && total > 0 {
+		content = append(content, map[string]interface{}{
+			"type": "text",
+			"text": fmt.Sprintf("No tasks on page %d; there are %d pages", page, pages),
+		})
+	} else if len(tasks) == 0 {
 		content = append(content, map[string]interface{}{
 			"type": "text",
 			"text": "No tasks found in current board",
@@ -527,44 +1442,71 @@ func (s *Server) handleListTasks(req *MCPRequest, args map[string]interface{}) *
 			tasksByStatus[t.Status] = append(tasksByStatus[t.Status], t)
 		}
 
+		// Column definitions tell the agent what each status means
+		notes, _ := s.tasks(req).ColumnNotes()
+
 		statuses := []task.Status{task.StatusTodo, task.StatusDoing, task.StatusDone}
 		for _, status := range statuses {
 			if statusTasks, exists := tasksByStatus[status]; exists && len(statusTasks) > 0 {
+				heading := fmt.Sprintf("\n%s:", strings.ToUpper(string(status)))
+				if note, ok := notes[status]; ok {
+					heading += " " + note.Note
+				}
 				content = append(content, map[string]interface{}{
 					"type": "text",
-					"text": fmt.Sprintf("\n%s:", strings.ToUpper(string(status))),
+					"text": heading,
 				})
 				for _, t := range statusTasks {
 					priorityStr := ""
 					if t.Priority > 0 {
 						priorityStr = fmt.Sprintf(" [%s]", task.GetPriorityName(t.Priority))
 					}
+					blockedStr := ""
+					if t.IsBlocked() {
+						ids := make([]string, len(t.BlockedBy))
+						for i, id := range t.BlockedBy {
+							ids[i] = fmt.Sprintf("#%d", id)
+						}
+						blockedStr = " 🚫 blocked by " + strings.Join(ids, ", ")
+					}
+					if len(t.Reactions) > 0 {
+						blockedStr += "  " + task.FormatReactions(t.Reactions)
+					}
 					content = append(content, map[string]interface{}{
 						"type": "text",
-						"text": fmt.Sprintf("• #%d%s %s", t.ID, priorityStr, t.Title),
+						"text": fmt.Sprintf("• #%d%s %s%s", t.ID, priorityStr, t.Title, blockedStr),
 					})
 				}
 			}
 		}
 	}
 
+	if page < pages {
+		content = append(content, map[string]interface{}{
+			"type": "text",
+			"text": fmt.Sprintf("\nPage %d of %d (%d tasks); call again with page %d for more", page, pages, total, page+1),
+		})
+	}
+
 	return &MCPResponse{
 		JSONRPC: "2.0",
 		ID:      req.ID,
 		Result: map[string]interface{}{
 			"content": content,
 			"tasks":   tasks,
+			"page":    page,
+			"pages":   pages,
+			"total":   total,
 		},
 	}
 }
 
 // handleUpdateTaskStatus handles the update_task_status tool call
 func (s *Server) handleUpdateTaskStatus(req *MCPRequest, args map[string]interface{}) *MCPResponse {
-	idFloat, ok := args["id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "id is required and must be a number")
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
 	}
-	id := int(idFloat)
 
 	statusStr, ok := args["status"].(string)
 	if !ok {
@@ -576,9 +1518,28 @@ func (s *Server) handleUpdateTaskStatus(req *MCPRequest, args map[string]interfa
 	}
 
 	status := task.Status(statusStr)
-	if err := s.taskSystem.UpdateStatus(id, status); err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to update task status: %v", err))
+	var move automation.HookEvent
+	if s.hooks != nil {
+		t, err := s.tasks(req).GetByID(id)
+		if err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to update task status: %v", err))
+		}
+		move = automation.HookEvent{Hook: automation.HookPreMove, Task: t, FromStatus: t.Status, ToStatus: status}
+		if err := s.runHook(req.Context(), move); err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to update task status: %v", err))
+		}
+	}
+	if err := s.tasks(req).UpdateStatus(id, status); err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to update task status: %v", err))
+	}
+	if s.hooks != nil {
+		if t, err := s.tasks(req).GetByID(id); err == nil {
+			move.Task = t
+		}
+		move.Hook = automation.HookPostMove
+		s.runHook(req.Context(), move)
 	}
+	s.runAutomations(req.Context())
 
 	return &MCPResponse{
 		JSONRPC: "2.0",
@@ -596,15 +1557,14 @@ func (s *Server) handleUpdateTaskStatus(req *MCPRequest, args map[string]interfa
 
 // handleGetTask handles the get_task tool call
 func (s *Server) handleGetTask(req *MCPRequest, args map[string]interface{}) *MCPResponse {
-	idFloat, ok := args["id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "id is required and must be a number")
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
 	}
-	id := int(idFloat)
 
-	t, err := s.taskSystem.GetByID(id)
+	t, err := s.tasks(req).GetByID(id)
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to get task: %v", err))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to get task: %v", err))
 	}
 
 	return &MCPResponse{
@@ -622,13 +1582,96 @@ func (s *Server) handleGetTask(req *MCPRequest, args map[string]interface{}) *MC
 	}
 }
 
+// handleGetNextTask handles the get_next_task tool call
+func (s *Server) handleGetNextTask(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	pick, err := s.tasks(req).Next(1, s.wipLimit, time.Now())
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to pick next task: %v", err))
+	}
+
+	text := "No unblocked todo tasks to pick up"
+	if pick != nil {
+		text = fmt.Sprintf("#%d [%s] %s\nWhy: %s", pick.Task.ID, pick.Task.Status, pick.Task.Title, pick.Reason)
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": text,
+				},
+			},
+			"next": pick,
+		},
+	}
+}
+
+// handleGetBoardSummary handles the get_board_summary tool call
+func (s *Server) handleGetBoardSummary(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	days := 7
+	if value, ok := args["days"]; ok {
+		daysFloat, ok := value.(float64)
+		if !ok || daysFloat < 1 {
+			return s.errorResponse(req.ID, -32602, "days must be a positive number")
+		}
+		days = int(daysFloat)
+	}
+
+	now := time.Now()
+	summary, err := s.tasks(req).Summarize(1, now, now.AddDate(0, 0, -days))
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to summarize board: %v", err))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "todo: %d, doing: %d, done: %d (%d blocked)\n",
+		summary.Counts[task.StatusTodo], summary.Counts[task.StatusDoing], summary.Counts[task.StatusDone], summary.Blocked)
+	sections := []struct {
+		title string
+		tasks []*task.Task
+	}{
+		{"High priority", summary.HighPriority},
+		{"Overdue", summary.Overdue},
+		{fmt.Sprintf("Completed in the last %d days", days), summary.RecentlyCompleted},
+	}
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n%s:\n", section.title)
+		if len(section.tasks) == 0 {
+			b.WriteString("  none\n")
+		}
+		for _, t := range section.tasks {
+			fmt.Fprintf(&b, "  #%d [%s] %s", t.ID, t.Status, t.Title)
+			if t.IsOverdue(now) {
+				fmt.Fprintf(&b, " (due %s)", t.DueAt.Format("2006-01-02 15:04"))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": strings.TrimRight(b.String(), "\n"),
+				},
+			},
+			"summary": summary,
+		},
+	}
+}
+
 // handleUpdateTaskPriority handles the update_task_priority tool call
 func (s *Server) handleUpdateTaskPriority(req *MCPRequest, args map[string]interface{}) *MCPResponse {
-	idFloat, ok := args["id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "Invalid or missing task ID")
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
 	}
-	id := int(idFloat)
 
 	priority, ok := args["priority"]
 	if !ok {
@@ -639,8 +1682,8 @@ func (s *Server) handleUpdateTaskPriority(req *MCPRequest, args map[string]inter
 		return s.errorResponse(req.ID, -32602, "Invalid priority level")
 	}
 
-	if err := s.taskSystem.UpdatePriority(id, priority); err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to update task priority: %v", err))
+	if err := s.tasks(req).UpdatePriority(id, priority); err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to update task priority: %v", err))
 	}
 
 	priorityLevel, _ := task.ParsePriority(priority)
@@ -662,11 +1705,10 @@ func (s *Server) handleUpdateTaskPriority(req *MCPRequest, args map[string]inter
 
 // handleUpdateTask handles the update_task tool call
 func (s *Server) handleUpdateTask(req *MCPRequest, args map[string]interface{}) *MCPResponse {
-	idFloat, ok := args["id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "id is required and must be a number")
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
 	}
-	id := int(idFloat)
 
 	title, ok := args["title"].(string)
 	if !ok {
@@ -675,8 +1717,402 @@ func (s *Server) handleUpdateTask(req *MCPRequest, args map[string]interface{})
 
 	description, _ := args["description"].(string)
 
-	if err := s.taskSystem.Update(id, title, description); err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to update task: %v", err))
+	if err := s.tasks(req).Update(id, title, description); err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to update task: %v", err))
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": fmt.Sprintf("Updated task #%d: %s", id, title),
+				},
+			},
+		},
+	}
+}
+
+// handleAssignTask handles the assign_task tool call
+func (s *Server) handleAssignTask(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
+	}
+
+	assignee, ok := args["assignee"].(string)
+	if !ok {
+		return s.errorResponse(req.ID, -32602, "assignee is required and must be a string")
+	}
+
+	warning, err := s.tasks(req).Assign(id, assignee)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to assign task: %v", err))
+	}
+
+	text := fmt.Sprintf("Assigned task #%d to %s", id, assignee)
+	if assignee == "" {
+		text = fmt.Sprintf("Unassigned task #%d", id)
+	}
+
+	result := map[string]interface{}{}
+	if warning != nil {
+		text += fmt.Sprintf("\nWarning: %s", warning)
+		result["capacity_warning"] = warning
+	}
+	result["content"] = []map[string]interface{}{
+		{
+			"type": "text",
+			"text": text,
+		},
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  result,
+	}
+}
+
+// handleReactToTask handles the react_to_task tool call
+func (s *Server) handleReactToTask(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
+	}
+
+	emoji, ok := args["emoji"].(string)
+	if !ok {
+		return s.errorResponse(req.ID, -32602, "emoji is required and must be a string")
+	}
+	actor, ok := args["actor"].(string)
+	if !ok {
+		return s.errorResponse(req.ID, -32602, "actor is required and must be a string")
+	}
+	remove, _ := args["remove"].(bool)
+
+	var text string
+	if remove {
+		removed, err := s.tasks(req).Unreact(id, actor, emoji)
+		if err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to remove reaction: %v", err))
+		}
+		text = fmt.Sprintf("Removed %s's %s from task #%d", actor, emoji, id)
+		if !removed {
+			text = fmt.Sprintf("%s had not reacted %s to task #%d", actor, emoji, id)
+		}
+	} else {
+		added, err := s.tasks(req).React(id, actor, emoji)
+		if err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to add reaction: %v", err))
+		}
+		text = fmt.Sprintf("%s reacted %s to task #%d", actor, emoji, id)
+		if !added {
+			text = fmt.Sprintf("%s already reacted %s to task #%d", actor, emoji, id)
+		}
+	}
+
+	reactions, err := s.tasks(req).ListReactions(id)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to load reactions: %v", err))
+	}
+	if len(reactions) > 0 {
+		text += "\nReactions: " + task.FormatReactions(reactions)
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"reactions": reactions,
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": text,
+				},
+			},
+		},
+	}
+}
+
+// handleHandoffTask handles the handoff_task tool call
+func (s *Server) handleHandoffTask(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
+	}
+
+	agent, ok := args["agent"].(string)
+	if !ok {
+		return s.errorResponse(req.ID, -32602, "agent is required and must be a string")
+	}
+
+	note, _ := args["note"].(string)
+
+	handoff, err := s.tasks(req).Handoff(id, agent, note)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to hand off task: %v", err))
+	}
+
+	s.notify("task_handoff", handoff)
+
+	text := fmt.Sprintf("Handed off task #%d to %s", id, handoff.To)
+	if s.handoffWebhook != "" {
+		board := s.currentBoard()
+		if err := webhook.Post(s.handoffWebhook, "task_handoff", board, handoff); err != nil {
+			text += fmt.Sprintf("\nWarning: %v", err)
+		}
+	}
+	if handoff.CapacityWarning != nil {
+		text += fmt.Sprintf("\nWarning: %s", handoff.CapacityWarning)
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": text,
+				},
+			},
+			"handoff": handoff,
+		},
+	}
+}
+
+// handleClaimTask handles the claim_task tool call. Moving the task to
+// doing runs the move hooks, as update_task_status would.
+func (s *Server) handleClaimTask(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
+	}
+
+	t, err := s.tasks(req).GetByID(id)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to claim task: %v", err))
+	}
+	move := automation.HookEvent{Hook: automation.HookPreMove, Task: t, FromStatus: t.Status, ToStatus: task.StatusDoing}
+	moves := s.hooks != nil && t.Status != task.StatusDoing && t.InProgressBy == ""
+	if moves {
+		if err := s.runHook(req.Context(), move); err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to claim task: %v", err))
+		}
+	}
+
+	claimed, err := s.tasks(req).Claim(id)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to claim task: %v", err))
+	}
+	if moves {
+		move.Hook, move.Task = automation.HookPostMove, claimed
+		s.runHook(req.Context(), move)
+	}
+	s.notify("task_claimed", claimed)
+	s.runAutomations(req.Context())
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": fmt.Sprintf("Claimed task #%d \"%s\" as %s", claimed.ID, claimed.Title, claimed.InProgressBy),
+				},
+			},
+			"task": claimed,
+		},
+	}
+}
+
+// handleReleaseTask handles the release_task tool call
+func (s *Server) handleReleaseTask(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
+	}
+
+	released, err := s.tasks(req).Release(id, false)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to release task: %v", err))
+	}
+
+	text := fmt.Sprintf("Released task #%d", id)
+	if !released {
+		text = fmt.Sprintf("Task #%d was not claimed", id)
+	} else {
+		s.notify("task_released", map[string]interface{}{"task_id": id, "by": s.actorName()})
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": text,
+				},
+			},
+			"released": released,
+		},
+	}
+}
+
+// handleSetTaskContext handles the set_task_context tool call
+func (s *Server) handleSetTaskContext(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
+	}
+
+	content, ok := args["content"].(string)
+	if !ok {
+		return s.errorResponse(req.ID, -32602, "content is required and must be a string")
+	}
+
+	save := s.tasks(req).SetCheckpoint
+	if appendMode, _ := args["append"].(bool); appendMode {
+		save = s.tasks(req).AppendCheckpoint
+	}
+
+	checkpoint, err := save(id, content)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to save task context: %v", err))
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": fmt.Sprintf("Saved context for task #%d (%d bytes)", id, len(checkpoint.Content)),
+				},
+			},
+		},
+	}
+}
+
+// handleGetTaskContext handles the get_task_context tool call
+func (s *Server) handleGetTaskContext(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
+	}
+
+	checkpoint, err := s.tasks(req).GetCheckpoint(id)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to get task context: %v", err))
+	}
+
+	text := fmt.Sprintf("No context saved for task #%d", id)
+	if checkpoint != nil {
+		text = checkpoint.Content
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": text,
+				},
+			},
+			"context": checkpoint,
+		},
+	}
+}
+
+// handleAppendWorklog handles the append_worklog tool call
+func (s *Server) handleAppendWorklog(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
+	}
+
+	summary, ok := args["summary"].(string)
+	if !ok {
+		return s.errorResponse(req.ID, -32602, "summary is required and must be a string")
+	}
+	outcome, _ := args["outcome"].(string)
+	entry := task.Worklog{Summary: summary, Outcome: task.Outcome(outcome)}
+	if files, ok := args["files_changed"]; ok {
+		list, ok := files.([]interface{})
+		if !ok {
+			return s.errorResponse(req.ID, -32602, "files_changed must be an array of strings")
+		}
+		for _, file := range list {
+			path, ok := file.(string)
+			if !ok {
+				return s.errorResponse(req.ID, -32602, "files_changed must be an array of strings")
+			}
+			entry.FilesChanged = append(entry.FilesChanged, path)
+		}
+	}
+
+	worklog, err := s.tasks(req).AppendWorklog(id, entry)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to append to worklog: %v", err))
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": fmt.Sprintf("Added worklog entry #%d to task #%d", worklog.ID, id),
+				},
+			},
+			"worklog": worklog,
+		},
+	}
+}
+
+// handleGetWorklogs handles the get_worklogs tool call
+func (s *Server) handleGetWorklogs(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	id, errResp := s.taskIDArg(req, args, "id")
+	if errResp != nil {
+		return errResp
+	}
+
+	worklogs, err := s.tasks(req).ListWorklogs(id)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to get worklogs: %v", err))
+	}
+
+	text := fmt.Sprintf("No worklog for task #%d", id)
+	if len(worklogs) > 0 {
+		var b strings.Builder
+		for i, w := range worklogs {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "%s %s", w.CreatedAt.UTC().Format(time.RFC3339), w.Summary)
+			if w.Agent != "" {
+				fmt.Fprintf(&b, " (%s)", w.Agent)
+			}
+			if w.Outcome != task.OutcomeNone {
+				fmt.Fprintf(&b, " [%s]", w.Outcome)
+			}
+			if len(w.FilesChanged) > 0 {
+				fmt.Fprintf(&b, "\n  files: %s", strings.Join(w.FilesChanged, ", "))
+			}
+		}
+		text = b.String()
+	}
+	if worklogs == nil {
+		worklogs = []task.Worklog{}
 	}
 
 	return &MCPResponse{
@@ -686,13 +2122,70 @@ func (s *Server) handleUpdateTask(req *MCPRequest, args map[string]interface{})
 			"content": []map[string]interface{}{
 				{
 					"type": "text",
-					"text": fmt.Sprintf("Updated task #%d: %s", id, title),
+					"text": text,
 				},
 			},
+			"worklogs": worklogs,
 		},
 	}
 }
 
+// Error codes of failed tool calls beyond those of JSON-RPC. Not found is
+// MCP's code for a missing resource, used for any record that is missing;
+// cancelled is the Language Server Protocol's, for a request that ran out
+// of time or was served when the server stopped.
+const (
+	codeNotFound  = -32002
+	codeAmbiguous = -32003
+	codeConflict  = -32004
+	codeCancelled = -32800
+)
+
+// errorCode is the error code for err: that of its kind, so an agent can
+// tell a missing task from an ambiguous reference or a bad argument, and
+// an internal error when it has none
+func errorCode(err error) int {
+	switch {
+	case errors.Is(err, task.ErrNotFound):
+		return codeNotFound
+	case errors.Is(err, task.ErrAmbiguous):
+		return codeAmbiguous
+	case errors.Is(err, task.ErrInvalidInput):
+		return -32602
+	case errors.Is(err, task.ErrConflict):
+		return codeConflict
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return codeCancelled
+	}
+	return -32603
+}
+
+// taskIDArg reads the argument name of a tool call, a task ID or a task
+// hash such as "3f9a2c1", as a task ID. When it is missing or no task has
+// the hash, the returned response reports the error.
+func (s *Server) taskIDArg(req *MCPRequest, args map[string]interface{}, name string) (int, *MCPResponse) {
+	return s.taskIDValue(req, args[name], name)
+}
+
+// taskIDValue reads a task ID or hash given as the argument name, or as an
+// element of it
+func (s *Server) taskIDValue(req *MCPRequest, arg interface{}, name string) (int, *MCPResponse) {
+	switch value := arg.(type) {
+	case float64:
+		return int(value), nil
+	case string:
+		if id, err := strconv.Atoi(value); err == nil {
+			return id, nil
+		}
+		t, err := s.tasks(req).GetByHash(value)
+		if err != nil {
+			return 0, s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to find task: %v", err))
+		}
+		return t.ID, nil
+	}
+	return 0, s.errorResponse(req.ID, -32602, name+" is required and must be a task ID or hash")
+}
+
 // errorResponse creates an error response
 func (s *Server) errorResponse(id interface{}, code int, message string) *MCPResponse {
 	return &MCPResponse{
@@ -709,10 +2202,10 @@ func (s *Server) errorResponse(id interface{}, code int, message string) *MCPRes
 func (s *Server) handleListBoards(req *MCPRequest, args map[string]interface{}) *MCPResponse {
 	boards, err := s.boardSystem.ListBoards()
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to list boards: %v", err))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to list boards: %v", err))
 	}
 
-	currentBoard, _ := s.boardSystem.GetCurrentBoard()
+	currentBoard := s.currentBoard()
 
 	var content []map[string]interface{}
 	if len(boards) == 0 {
@@ -727,9 +2220,12 @@ func (s *Server) handleListBoards(req *MCPRequest, args map[string]interface{})
 		})
 		for _, b := range boards {
 			marker := ""
-			if b.Name == currentBoard {
+			if b.Path == s.boardSystem.GetBoardPath(currentBoard) {
 				marker = " (current)"
 			}
+			if b.Description != "" {
+				marker += ": " + b.Description
+			}
 			content = append(content, map[string]interface{}{
 				"type": "text",
 				"text": fmt.Sprintf("• %s%s", b.Name, marker),
@@ -747,6 +2243,42 @@ func (s *Server) handleListBoards(req *MCPRequest, args map[string]interface{})
 	}
 }
 
+// handleSearchAllBoards handles the search_all_boards tool call
+func (s *Server) handleSearchAllBoards(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	query, ok := args["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return s.errorResponse(req.ID, -32602, "query is required and must be a non-empty string")
+	}
+
+	matches, err := s.boardSystem.SearchAllBoards(query)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to search boards: %v", err))
+	}
+
+	var content []map[string]interface{}
+	if len(matches) == 0 {
+		content = append(content, map[string]interface{}{
+			"type": "text",
+			"text": fmt.Sprintf("No tasks found matching '%s' on any board", query),
+		})
+	}
+	for _, t := range matches {
+		content = append(content, map[string]interface{}{
+			"type": "text",
+			"text": fmt.Sprintf("• %s:#%d [%s] %s", t.Board, t.ID, t.Status, t.Title),
+		})
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": content,
+			"tasks":   matches,
+		},
+	}
+}
+
 // handleChangeBoard handles the change_board tool call
 func (s *Server) handleChangeBoard(req *MCPRequest, args map[string]interface{}) *MCPResponse {
 	boardName, ok := args["board_name"].(string)
@@ -754,15 +2286,15 @@ func (s *Server) handleChangeBoard(req *MCPRequest, args map[string]interface{})
 		return s.errorResponse(req.ID, -32602, "board_name is required and must be a string")
 	}
 
-	// Check if board exists
-	_, err := s.boardSystem.GetBoard(boardName)
+	// Check if board exists, and change to it under its own name
+	found, err := s.boardSystem.GetBoard(boardName)
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Board '%s' not found", boardName))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Board '%s' not found", boardName))
 	}
+	boardName = found.Name
 
-	// Set as current board
-	if err := s.boardSystem.SetCurrentBoard(boardName); err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to change board: %v", err))
+	if err := s.changeBoard(boardName); err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to change board: %v", err))
 	}
 
 	return &MCPResponse{
@@ -779,15 +2311,167 @@ func (s *Server) handleChangeBoard(req *MCPRequest, args map[string]interface{})
 	}
 }
 
+// currentBoard returns the board the server works on, or the current board
+// when it was not told
+func (s *Server) currentBoard() string {
+	if s.boardName != "" {
+		return s.boardName
+	}
+	name, _ := s.boardSystem.GetCurrentBoard()
+	return name
+}
+
+// changeBoard makes a board the current one and, with a BoardOpener, moves
+// the server onto it. The server stays where it is if the board cannot be
+// opened. When the board was chosen for the server with --board or
+// CAINBAN_BOARD, only the server moves and the current board is left alone.
+func (s *Server) changeBoard(boardName string) error {
+	override := s.boardSystem.Override()
+	if override != "" && s.openBoard == nil {
+		return storage.Errorf(storage.ErrInvalidInput, "the server works on board '%s', set with --board or CAINBAN_BOARD", override)
+	}
+	var opened *Board
+	if s.openBoard != nil {
+		var err error
+		if opened, err = s.openBoard(boardName); err != nil {
+			return err
+		}
+	}
+	if override != "" {
+		s.UseBoard(opened)
+		return nil
+	}
+	if err := s.boardSystem.SetCurrentBoard(boardName); err != nil {
+		if opened != nil {
+			opened.DB.Close()
+		}
+		return err
+	}
+	if opened != nil {
+		s.UseBoard(opened)
+	}
+	return nil
+}
+
+// handleCreateBoard handles the create_board tool call
+func (s *Server) handleCreateBoard(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	boardName, ok := args["board_name"].(string)
+	if !ok || strings.TrimSpace(boardName) == "" {
+		return s.errorResponse(req.ID, -32602, "board_name is required and must be a string")
+	}
+	description, _ := args["description"].(string)
+
+	created, err := s.boardSystem.CreateBoard(boardName, description)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to create board: %v", err))
+	}
+
+	text := fmt.Sprintf("Created board '%s'", boardName)
+	if switchTo, _ := args["switch"].(bool); switchTo {
+		if err := s.changeBoard(boardName); err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Created board '%s' but failed to change to it: %v", boardName, err))
+		}
+		text += " and changed to it"
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": text,
+				},
+			},
+			"board": created,
+		},
+	}
+}
+
+// handleDeleteBoard handles the delete_board tool call. Like cainban board
+// delete, it only removes the local copy of a remote board, refuses the
+// default and repo-local boards, and backs the board up first with
+// SetAutoBackup; it also refuses the board the server's tasks live on.
+func (s *Server) handleDeleteBoard(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	boardName, ok := args["board_name"].(string)
+	if !ok || strings.TrimSpace(boardName) == "" {
+		return s.errorResponse(req.ID, -32602, "board_name is required and must be a string")
+	}
+	if confirm, _ := args["confirm"].(bool); !confirm {
+		return s.errorResponse(req.ID, -32602, fmt.Sprintf("Deleting board '%s' must be confirmed with confirm set to true", boardName))
+	}
+	if boardName == s.boardName {
+		return s.errorResponse(req.ID, -32602, fmt.Sprintf("Board '%s' is the one this server works on; delete it with cainban board delete", boardName))
+	}
+
+	r, err := s.boardSystem.Remote(boardName)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to delete board: %v", err))
+	}
+	var text string
+	if r != nil {
+		// Only the local copy goes; the board stays where it is kept
+		if err := s.boardSystem.RemoveRemote(boardName); err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to delete board: %v", err))
+		}
+		text = fmt.Sprintf("Removed remote board '%s'; the board at %s is left as it is", boardName, r.URL)
+	} else {
+		if err := s.boardSystem.CheckDeletable(boardName); err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to delete board: %v", err))
+		}
+		if s.autoBackup {
+			if err := s.backupBoard(boardName); err != nil {
+				return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to back up board '%s' before deleting it: %v", boardName, err))
+			}
+		}
+		trashed, err := s.boardSystem.DeleteBoard(boardName)
+		if err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to delete board: %v", err))
+		}
+		text = fmt.Sprintf("Deleted board '%s'; bring it back with: cainban board restore %s", boardName, trashed.Name)
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": text,
+				},
+			},
+		},
+	}
+}
+
+// backupBoard backs up a board the server does not work on, pruning its
+// older backups
+func (s *Server) backupBoard(boardName string) error {
+	db, err := storage.New(s.boardSystem.GetBoardPath(boardName))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if err := db.Backup(s.boardSystem.BackupPath(boardName, time.Now())); err != nil {
+		return err
+	}
+	if s.keepBackups > 0 {
+		_, err = s.boardSystem.PruneBackups(boardName, s.keepBackups)
+	}
+	return err
+}
+
 func (s *Server) handleLinkTasks(req *MCPRequest, args map[string]interface{}) *MCPResponse {
-	fromTaskID, ok := args["from_task_id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "from_task_id is required and must be an integer")
+	fromTaskID, errResp := s.taskIDArg(req, args, "from_task_id")
+	if errResp != nil {
+		return errResp
 	}
 
-	toTaskID, ok := args["to_task_id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "to_task_id is required and must be an integer")
+	toTaskID, errResp := s.taskIDArg(req, args, "to_task_id")
+	if errResp != nil {
+		return errResp
 	}
 
 	linkType := "blocks" // default
@@ -795,9 +2479,9 @@ func (s *Server) handleLinkTasks(req *MCPRequest, args map[string]interface{}) *
 		linkType = lt
 	}
 
-	err := s.taskSystem.LinkTasks(int(fromTaskID), int(toTaskID), task.LinkType(linkType))
+	err := s.tasks(req).LinkTasks(fromTaskID, toTaskID, task.LinkType(linkType))
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to link tasks: %v", err))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to link tasks: %v", err))
 	}
 
 	return &MCPResponse{
@@ -807,7 +2491,7 @@ func (s *Server) handleLinkTasks(req *MCPRequest, args map[string]interface{}) *
 			"content": []map[string]interface{}{
 				{
 					"type": "text",
-					"text": fmt.Sprintf("Linked task %d %s task %d", int(fromTaskID), linkType, int(toTaskID)),
+					"text": fmt.Sprintf("Linked task %d %s task %d", fromTaskID, linkType, toTaskID),
 				},
 			},
 		},
@@ -815,14 +2499,14 @@ func (s *Server) handleLinkTasks(req *MCPRequest, args map[string]interface{}) *
 }
 
 func (s *Server) handleUnlinkTasks(req *MCPRequest, args map[string]interface{}) *MCPResponse {
-	fromTaskID, ok := args["from_task_id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "from_task_id is required and must be an integer")
+	fromTaskID, errResp := s.taskIDArg(req, args, "from_task_id")
+	if errResp != nil {
+		return errResp
 	}
 
-	toTaskID, ok := args["to_task_id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "to_task_id is required and must be an integer")
+	toTaskID, errResp := s.taskIDArg(req, args, "to_task_id")
+	if errResp != nil {
+		return errResp
 	}
 
 	linkType := "blocks" // default
@@ -830,9 +2514,9 @@ func (s *Server) handleUnlinkTasks(req *MCPRequest, args map[string]interface{})
 		linkType = lt
 	}
 
-	err := s.taskSystem.UnlinkTasks(int(fromTaskID), int(toTaskID), task.LinkType(linkType))
+	err := s.tasks(req).UnlinkTasks(fromTaskID, toTaskID, task.LinkType(linkType))
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to unlink tasks: %v", err))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to unlink tasks: %v", err))
 	}
 
 	return &MCPResponse{
@@ -842,7 +2526,7 @@ func (s *Server) handleUnlinkTasks(req *MCPRequest, args map[string]interface{})
 			"content": []map[string]interface{}{
 				{
 					"type": "text",
-					"text": fmt.Sprintf("Unlinked task %d %s task %d", int(fromTaskID), linkType, int(toTaskID)),
+					"text": fmt.Sprintf("Unlinked task %d %s task %d", fromTaskID, linkType, toTaskID),
 				},
 			},
 		},
@@ -850,14 +2534,14 @@ func (s *Server) handleUnlinkTasks(req *MCPRequest, args map[string]interface{})
 }
 
 func (s *Server) handleGetTaskLinks(req *MCPRequest, args map[string]interface{}) *MCPResponse {
-	taskID, ok := args["task_id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "task_id is required and must be an integer")
+	taskID, errResp := s.taskIDArg(req, args, "task_id")
+	if errResp != nil {
+		return errResp
 	}
 
-	links, err := s.taskSystem.GetTaskLinks(int(taskID))
+	links, err := s.tasks(req).GetTaskLinks(taskID)
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to get task links: %v", err))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to get task links: %v", err))
 	}
 
 	if len(links) == 0 {
@@ -868,7 +2552,7 @@ func (s *Server) handleGetTaskLinks(req *MCPRequest, args map[string]interface{}
 				"content": []map[string]interface{}{
 					{
 						"type": "text",
-						"text": fmt.Sprintf("Task %d has no links", int(taskID)),
+						"text": fmt.Sprintf("Task %d has no links", taskID),
 					},
 				},
 			},
@@ -877,7 +2561,7 @@ func (s *Server) handleGetTaskLinks(req *MCPRequest, args map[string]interface{}
 
 	var linkTexts []string
 	for _, link := range links {
-		if link.FromTaskID == int(taskID) {
+		if link.FromTaskID == taskID {
 			linkTexts = append(linkTexts, fmt.Sprintf("• %s task %d", link.LinkType, link.ToTaskID))
 		} else {
 			linkTexts = append(linkTexts, fmt.Sprintf("• %s by task %d", link.LinkType, link.FromTaskID))
@@ -891,7 +2575,7 @@ func (s *Server) handleGetTaskLinks(req *MCPRequest, args map[string]interface{}
 			"content": []map[string]interface{}{
 				{
 					"type": "text",
-					"text": fmt.Sprintf("Task %d links:\n%s", int(taskID), strings.Join(linkTexts, "\n")),
+					"text": fmt.Sprintf("Task %d links:\n%s", taskID, strings.Join(linkTexts, "\n")),
 				},
 			},
 		},
@@ -899,9 +2583,9 @@ func (s *Server) handleGetTaskLinks(req *MCPRequest, args map[string]interface{}
 }
 
 func (s *Server) handleDeleteTask(req *MCPRequest, args map[string]interface{}) *MCPResponse {
-	taskID, ok := args["task_id"].(float64)
-	if !ok {
-		return s.errorResponse(req.ID, -32602, "task_id is required and must be an integer")
+	taskID, errResp := s.taskIDArg(req, args, "task_id")
+	if errResp != nil {
+		return errResp
 	}
 
 	hardDelete := false
@@ -909,16 +2593,25 @@ func (s *Server) handleDeleteTask(req *MCPRequest, args map[string]interface{})
 		hardDelete = hd
 	}
 
-	var err error
+	// A task already in the trash can still be deleted for good
+	deleted, err := s.tasks(req).GetByID(taskID)
+	if err != nil {
+		deleted = &task.Task{ID: taskID}
+	}
+	if err := s.runHook(req.Context(), automation.HookEvent{Hook: automation.HookPreDelete, Task: deleted, FromStatus: deleted.Status}); err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to delete task: %v", err))
+	}
+
 	if hardDelete {
-		err = s.taskSystem.HardDelete(int(taskID))
+		err = s.tasks(req).HardDelete(taskID)
 	} else {
-		err = s.taskSystem.SoftDelete(int(taskID))
+		err = s.tasks(req).SoftDelete(taskID)
 	}
 
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to delete task: %v", err))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to delete task: %v", err))
 	}
+	s.runHook(req.Context(), automation.HookEvent{Hook: automation.HookPostDelete, Task: deleted, FromStatus: deleted.Status})
 
 	var deleteType string
 	if hardDelete {
@@ -934,7 +2627,7 @@ func (s *Server) handleDeleteTask(req *MCPRequest, args map[string]interface{})
 			"content": []map[string]interface{}{
 				{
 					"type": "text",
-					"text": fmt.Sprintf("Task %d %s", int(taskID), deleteType),
+					"text": fmt.Sprintf("Task %d %s", taskID, deleteType),
 				},
 			},
 		},
@@ -947,9 +2640,9 @@ func (s *Server) handleRestoreTask(req *MCPRequest, args map[string]interface{})
 		return s.errorResponse(req.ID, -32602, "task_id is required and must be an integer")
 	}
 
-	err := s.taskSystem.RestoreTask(int(taskID))
+	err := s.tasks(req).RestoreTask(int(taskID))
 	if err != nil {
-		return s.errorResponse(req.ID, -32603, fmt.Sprintf("Failed to restore task: %v", err))
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to restore task: %v", err))
 	}
 
 	return &MCPResponse{
@@ -965,3 +2658,286 @@ func (s *Server) handleRestoreTask(req *MCPRequest, args map[string]interface{})
 		},
 	}
 }
+
+// sprintSystem returns the sprint system with its queries bound to the
+// context of req, or a response reporting that sprints are not enabled
+func (s *Server) sprintSystem(req *MCPRequest) (*sprint.System, *MCPResponse) {
+	if s.sprints == nil {
+		return nil, s.errorResponse(req.ID, -32603, "Sprints are not available on this server")
+	}
+	return s.sprints.WithContext(req.Context()), nil
+}
+
+// sprintArg reads the argument name of a tool call, a sprint ID, name or
+// "current", as the sprint it refers to. When no sprint matches, the
+// returned response reports the error.
+func (s *Server) sprintArg(req *MCPRequest, args map[string]interface{}, name string) (*sprint.Sprint, *MCPResponse) {
+	sprints, errResp := s.sprintSystem(req)
+	if errResp != nil {
+		return nil, errResp
+	}
+	var ref string
+	switch value := args[name].(type) {
+	case float64:
+		ref = strconv.Itoa(int(value))
+	case string:
+		ref = value
+	default:
+		return nil, s.errorResponse(req.ID, -32602, name+" must be a sprint ID or name, or \"current\"")
+	}
+	sp, err := sprints.Find(1, ref, time.Now())
+	if err != nil {
+		return nil, s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to find sprint: %v", err))
+	}
+	return sp, nil
+}
+
+// formatSprint describes a sprint in a line: its dates and how much of it
+// is done, in points when its tasks are estimated
+func formatSprint(sp *sprint.Sprint) string {
+	done := fmt.Sprintf("%d/%d tasks done", sp.DoneTasks, sp.Tasks)
+	if sp.Points > 0 {
+		done = fmt.Sprintf("%d/%d points done", sp.DonePoints, sp.Points)
+	}
+	if sp.Capacity > 0 {
+		done += fmt.Sprintf(", capacity %d points", sp.Capacity)
+	}
+	return fmt.Sprintf("#%d %s, %s to %s, %s", sp.ID, sp.Name, sp.Start, sp.End, done)
+}
+
+// handleListSprints handles the list_sprints tool call
+func (s *Server) handleListSprints(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	sprints, errResp := s.sprintSystem(req)
+	if errResp != nil {
+		return errResp
+	}
+	list, err := sprints.List(1)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to list sprints: %v", err))
+	}
+
+	text := "No sprints on the board"
+	if len(list) > 0 {
+		now := time.Now()
+		lines := make([]string, len(list))
+		for i, sp := range list {
+			lines[i] = "• " + formatSprint(sp)
+			if sp.IsCurrent(now) {
+				lines[i] += " (current)"
+			}
+		}
+		text = strings.Join(lines, "\n")
+	}
+	if list == nil {
+		list = []*sprint.Sprint{}
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": text,
+				},
+			},
+			"sprints": list,
+		},
+	}
+}
+
+// handleCreateSprint handles the create_sprint tool call
+func (s *Server) handleCreateSprint(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	sprints, errResp := s.sprintSystem(req)
+	if errResp != nil {
+		return errResp
+	}
+	name, ok := args["name"].(string)
+	if !ok {
+		return s.errorResponse(req.ID, -32602, "name is required and must be a string")
+	}
+
+	now := time.Now()
+	start := now
+	if value, ok := args["start"].(string); ok {
+		var err error
+		if start, err = dateparse.Parse(value, now); err != nil {
+			return s.errorResponse(req.ID, -32602, fmt.Sprintf("Invalid start: %v", err))
+		}
+	}
+	end := start.Add(sprint.DefaultLength - 24*time.Hour)
+	if value, ok := args["end"].(string); ok {
+		var err error
+		if end, err = dateparse.Parse(value, now); err != nil {
+			return s.errorResponse(req.ID, -32602, fmt.Sprintf("Invalid end: %v", err))
+		}
+	}
+
+	capacity := 0
+	if value, ok := args["capacity"]; ok {
+		points, ok := value.(float64)
+		if !ok || points < 0 {
+			return s.errorResponse(req.ID, -32602, "capacity must be a number of points, 0 or more")
+		}
+		capacity = int(points)
+	}
+
+	created, err := sprints.Create(1, name, start, end)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to create sprint: %v", err))
+	}
+	if capacity > 0 {
+		if err := sprints.SetCapacity(created.ID, capacity); err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to set sprint capacity: %v", err))
+		}
+		created.Capacity = capacity
+	}
+	s.notify("sprint_created", created)
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": fmt.Sprintf("Created sprint #%d %s, %s to %s", created.ID, created.Name, created.Start, created.End),
+				},
+			},
+			"sprint": created,
+		},
+	}
+}
+
+// handleGetSprint handles the get_sprint tool call
+func (s *Server) handleGetSprint(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	if _, ok := args["sprint"]; !ok {
+		args = map[string]interface{}{"sprint": sprint.Current}
+	}
+	sp, errResp := s.sprintArg(req, args, "sprint")
+	if errResp != nil {
+		return errResp
+	}
+
+	burndown, err := s.sprints.WithContext(req.Context()).Burndown(sp.ID, time.Now())
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to get burndown: %v", err))
+	}
+	tasks, err := s.tasks(req).ListWith(1, task.ListOptions{SprintID: sp.ID, BoardOrder: true})
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to list sprint tasks: %v", err))
+	}
+	if tasks == nil {
+		tasks = []*task.Task{}
+	}
+
+	var b strings.Builder
+	b.WriteString(formatSprint(sp))
+	for _, t := range tasks {
+		fmt.Fprintf(&b, "\n• #%d [%s] %s", t.ID, t.Status, t.Title)
+	}
+	if n := len(burndown.Days); n > 0 && burndown.Total > 0 {
+		last := burndown.Days[n-1]
+		fmt.Fprintf(&b, "\nDay %d of %d: %d %s left, %.1f on the ideal line", n, sp.Days(), last.Remaining, burndown.Unit, last.Ideal)
+	}
+	if burndown.Unestimated > 0 {
+		fmt.Fprintf(&b, "\n%d tasks without an estimate are not counted in the burndown", burndown.Unestimated)
+	}
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result: map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": b.String(),
+				},
+			},
+			"sprint":   sp,
+			"tasks":    tasks,
+			"burndown": burndown,
+		},
+	}
+}
+
+// handlePlanSprint handles the plan_sprint tool call
+func (s *Server) handlePlanSprint(req *MCPRequest, args map[string]interface{}) *MCPResponse {
+	sp, errResp := s.sprintArg(req, args, "sprint")
+	if errResp != nil {
+		return errResp
+	}
+
+	// Every task is resolved before any is moved, so a bad reference
+	// leaves the sprint as it was
+	var add, remove []int
+	for name, ids := range map[string]*[]int{"add": &add, "remove": &remove} {
+		value, ok := args[name]
+		if !ok {
+			continue
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return s.errorResponse(req.ID, -32602, name+" must be an array of task IDs or hashes")
+		}
+		for _, ref := range list {
+			id, errResp := s.taskIDValue(req, ref, name)
+			if errResp != nil {
+				return errResp
+			}
+			*ids = append(*ids, id)
+		}
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return s.errorResponse(req.ID, -32602, "add or remove is required")
+	}
+
+	tasks := s.tasks(req)
+	for _, id := range remove {
+		t, err := tasks.GetByID(id)
+		if err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to plan sprint: %v", err))
+		}
+		if t.SprintID == nil || *t.SprintID != sp.ID {
+			return s.errorResponse(req.ID, -32602, fmt.Sprintf("Task #%d is not planned for sprint %s", id, sp.Name))
+		}
+	}
+	for _, id := range add {
+		if err := tasks.SetSprint(id, &sp.ID); err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to plan sprint: %v", err))
+		}
+	}
+	for _, id := range remove {
+		if err := tasks.SetSprint(id, nil); err != nil {
+			return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to plan sprint: %v", err))
+		}
+	}
+
+	// The sprint again, with the tasks just planned counted
+	planned, err := s.sprints.WithContext(req.Context()).Get(sp.ID)
+	if err != nil {
+		return s.errorResponse(req.ID, errorCode(err), fmt.Sprintf("Failed to get sprint: %v", err))
+	}
+	s.notify("sprint_planned", planned)
+
+	text := fmt.Sprintf("Planned %d and took out %d tasks: %s", len(add), len(remove), formatSprint(planned))
+	result := map[string]interface{}{}
+	if warning := planned.CheckCapacity(); warning != nil {
+		text += fmt.Sprintf("\nWarning: %s", warning)
+		result["capacity_warning"] = warning
+	}
+	result["content"] = []map[string]interface{}{
+		{
+			"type": "text",
+			"text": text,
+		},
+	}
+	result["sprint"] = planned
+
+	return &MCPResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  result,
+	}
+}
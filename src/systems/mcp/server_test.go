@@ -2,9 +2,22 @@ package mcp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/board"
+	"github.com/hmain/cainban/src/systems/config"
+	"github.com/hmain/cainban/src/systems/sprint"
 	"github.com/hmain/cainban/src/systems/storage"
 	"github.com/hmain/cainban/src/systems/task"
 )
@@ -51,6 +64,46 @@ func TestServer_Initialize(t *testing.T) {
 	}
 }
 
+func TestServer_ReadmeResource(t *testing.T) {
+	server := setupTestServer(t)
+
+	list := func() []Resource {
+		resp := server.handleRequest(&MCPRequest{JSONRPC: "2.0", ID: 1, Method: "resources/list"})
+		if resp.Error != nil {
+			t.Fatalf("List resources should not return error: %v", resp.Error)
+		}
+		return resp.Result.(map[string]interface{})["resources"].([]Resource)
+	}
+
+	if resources := list(); len(resources) != 0 {
+		t.Errorf("Expected no resources without a readme, got %v", resources)
+	}
+
+	if _, err := server.taskSystem.SetReadme("# Conventions\n\nBranch per task."); err != nil {
+		t.Fatalf("Failed to set readme: %v", err)
+	}
+	resources := list()
+	if len(resources) != 1 || resources[0].URI != readmeURI {
+		t.Fatalf("Expected the readme resource, got %v", resources)
+	}
+
+	resp := server.handleRequest(&MCPRequest{JSONRPC: "2.0", ID: 2, Method: "resources/read",
+		Params: json.RawMessage(`{"uri": "cainban://board/readme"}`)})
+	if resp.Error != nil {
+		t.Fatalf("Read resource should not return error: %v", resp.Error)
+	}
+	contents := resp.Result.(map[string]interface{})["contents"].([]map[string]interface{})
+	if len(contents) != 1 || contents[0]["text"] != "# Conventions\n\nBranch per task." {
+		t.Errorf("Unexpected resource contents: %v", contents)
+	}
+
+	resp = server.handleRequest(&MCPRequest{JSONRPC: "2.0", ID: 3, Method: "resources/read",
+		Params: json.RawMessage(`{"uri": "cainban://board/missing"}`)})
+	if resp.Error == nil {
+		t.Error("Expected error for unknown resource")
+	}
+}
+
 func TestServer_ToolsList(t *testing.T) {
 	server := setupTestServer(t)
 
@@ -77,9 +130,11 @@ func TestServer_ToolsList(t *testing.T) {
 	}
 
 	expectedTools := []string{
-		"create_task", "list_tasks", "update_task_status", "get_task",
-		"update_task_priority", "update_task", "list_boards", "change_board",
+		"create_task", "list_tasks", "get_next_task", "get_board_summary", "update_task_status", "get_task",
+		"update_task_priority", "update_task", "assign_task", "react_to_task", "set_task_context", "get_task_context", "append_worklog", "get_worklogs", "handoff_task", "claim_task", "release_task", "search_all_boards", "list_boards", "change_board",
+		"create_board", "delete_board",
 		"link_tasks", "unlink_tasks", "get_task_links", "delete_task", "restore_task",
+		"list_sprints", "create_sprint", "get_sprint", "plan_sprint",
 	}
 	if len(tools) != len(expectedTools) {
 		t.Errorf("Expected %d tools, got %d", len(expectedTools), len(tools))
@@ -161,6 +216,118 @@ func TestServer_ListTasks(t *testing.T) {
 	}
 }
 
+func TestServer_ListTasksPages(t *testing.T) {
+	server := setupTestServer(t)
+	for i := 1; i <= 5; i++ {
+		server.handleCreateTask(&MCPRequest{ID: i}, map[string]interface{}{"title": fmt.Sprintf("Task %d", i)})
+	}
+
+	list := func(args map[string]interface{}) map[string]interface{} {
+		resp := server.handleListTasks(&MCPRequest{ID: 1}, args)
+		if resp.Error != nil {
+			t.Fatalf("list_tasks %v: %v", args, resp.Error)
+		}
+		return resp.Result.(map[string]interface{})
+	}
+
+	first := list(map[string]interface{}{"page_size": 2.0})
+	if tasks := first["tasks"].([]*task.Task); len(tasks) != 2 || tasks[0].Title != "Task 1" {
+		t.Errorf("Expected the first two tasks, got %v", tasks)
+	}
+	if first["pages"] != 3 || first["total"] != 5 || !strings.Contains(fmt.Sprint(first["content"]), "call again with page 2") {
+		t.Errorf("Expected page 1 of 3 with a pointer to the next, got %v", first)
+	}
+
+	last := list(map[string]interface{}{"page_size": 2.0, "page": 3.0})
+	if tasks := last["tasks"].([]*task.Task); len(tasks) != 1 || tasks[0].Title != "Task 5" {
+		t.Errorf("Expected the last task alone, got %v", tasks)
+	}
+	if strings.Contains(fmt.Sprint(last["content"]), "call again") {
+		t.Errorf("Expected no pointer past the last page, got %v", last["content"])
+	}
+
+	for _, args := range []map[string]interface{}{{"page": 0.0}, {"page_size": float64(maxPageSize + 1)}} {
+		if resp := server.handleListTasks(&MCPRequest{ID: 1}, args); resp.Error == nil {
+			t.Errorf("Expected list_tasks %v to fail", args)
+		}
+	}
+}
+
+func TestServer_GetNextTask(t *testing.T) {
+	server := setupTestServer(t)
+
+	server.handleCreateTask(&MCPRequest{ID: 1}, map[string]interface{}{"title": "Low", "priority": "low"})
+	server.handleCreateTask(&MCPRequest{ID: 2}, map[string]interface{}{"title": "High", "priority": "high"})
+
+	resp := server.handleGetNextTask(&MCPRequest{ID: 3}, map[string]interface{}{})
+	if resp.Error != nil {
+		t.Fatalf("Get next task should not return error: %v", resp.Error)
+	}
+
+	pick, ok := resp.Result.(map[string]interface{})["next"].(*task.Pick)
+	if !ok || pick == nil || pick.Task.Title != "High" {
+		t.Errorf("Expected the high priority task, got %+v", resp.Result)
+	}
+}
+
+func TestServer_GetBoardSummary(t *testing.T) {
+	server := setupTestServer(t)
+
+	server.handleCreateTask(&MCPRequest{ID: 1}, map[string]interface{}{"title": "Low", "priority": "low"})
+	server.handleCreateTask(&MCPRequest{ID: 2}, map[string]interface{}{"title": "Urgent", "priority": "critical"})
+
+	resp := server.handleGetBoardSummary(&MCPRequest{ID: 3}, map[string]interface{}{"days": float64(3)})
+	if resp.Error != nil {
+		t.Fatalf("Get board summary should not return error: %v", resp.Error)
+	}
+
+	result := resp.Result.(map[string]interface{})
+	summary := result["summary"].(*task.Summary)
+	if summary.Counts[task.StatusTodo] != 2 || len(summary.HighPriority) != 1 || summary.HighPriority[0].Title != "Urgent" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	text := result["content"].([]map[string]interface{})[0]["text"].(string)
+	if !strings.Contains(text, "todo: 2, doing: 0, done: 0") || !strings.Contains(text, "Completed in the last 3 days:\n  none") {
+		t.Errorf("Unexpected text:\n%s", text)
+	}
+
+	resp = server.handleGetBoardSummary(&MCPRequest{ID: 4}, map[string]interface{}{"days": "week"})
+	if resp.Error == nil {
+		t.Error("Expected error for invalid days")
+	}
+}
+
+func TestServer_ReactToTask(t *testing.T) {
+	server := setupTestServer(t)
+
+	createResp := server.handleCreateTask(&MCPRequest{ID: 1}, map[string]interface{}{"title": "Ship it"})
+	created := createResp.Result.(map[string]interface{})["task"].(*task.Task)
+
+	args := map[string]interface{}{"id": float64(created.ID), "emoji": "👍", "actor": "claude"}
+	resp := server.handleReactToTask(&MCPRequest{ID: 2}, args)
+	if resp.Error != nil {
+		t.Fatalf("React to task should not return error: %v", resp.Error)
+	}
+	reactions := resp.Result.(map[string]interface{})["reactions"].([]task.Reaction)
+	if len(reactions) != 1 || reactions[0].Count != 1 || reactions[0].Actors[0] != "claude" {
+		t.Errorf("Unexpected reactions: %+v", reactions)
+	}
+
+	args["remove"] = true
+	resp = server.handleReactToTask(&MCPRequest{ID: 3}, args)
+	if resp.Error != nil {
+		t.Fatalf("Removing a reaction should not return error: %v", resp.Error)
+	}
+	if reactions := resp.Result.(map[string]interface{})["reactions"].([]task.Reaction); len(reactions) != 0 {
+		t.Errorf("Expected no reactions left, got %+v", reactions)
+	}
+
+	resp = server.handleReactToTask(&MCPRequest{ID: 4}, map[string]interface{}{"id": float64(created.ID), "emoji": "👍"})
+	if resp.Error == nil {
+		t.Error("Expected error without an actor")
+	}
+}
+
 func TestServer_UpdateTaskStatus(t *testing.T) {
 	server := setupTestServer(t)
 
@@ -187,6 +354,426 @@ func TestServer_UpdateTaskStatus(t *testing.T) {
 	}
 }
 
+func TestServer_TaskHashes(t *testing.T) {
+	server := setupTestServer(t)
+
+	created := server.handleCreateTask(&MCPRequest{ID: 1}, map[string]interface{}{"title": "Found by hash"})
+	taskData := created.Result.(map[string]interface{})["task"].(*task.Task)
+
+	// A hash, a numeric string and a number all name the task
+	for _, id := range []interface{}{taskData.ShortHash(), taskData.Hash, "1", float64(1)} {
+		resp := server.handleGetTask(&MCPRequest{ID: 2}, map[string]interface{}{"id": id})
+		if resp.Error != nil {
+			t.Fatalf("get_task with id %v failed: %v", id, resp.Error)
+		}
+		if got := resp.Result.(map[string]interface{})["task"].(*task.Task); got.ID != taskData.ID {
+			t.Errorf("get_task with id %v found task #%d", id, got.ID)
+		}
+	}
+
+	resp := server.handleGetTask(&MCPRequest{ID: 3}, map[string]interface{}{"id": "ffffffff"})
+	if resp.Error == nil || resp.Error.Code != codeNotFound {
+		t.Errorf("Expected an unknown hash to be not found, got %+v", resp.Error)
+	}
+	resp = server.handleGetTask(&MCPRequest{ID: 4}, map[string]interface{}{"id": true})
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected an invalid id to be refused, got %+v", resp.Error)
+	}
+}
+
+func TestServer_RecordsTheClient(t *testing.T) {
+	server := setupTestServer(t)
+
+	server.handleRequest(&MCPRequest{ID: 1, Method: "initialize", Params: json.RawMessage(`{"clientInfo": {"name": "claude-code", "version": "1.0"}}`)})
+	created := server.handleCreateTask(&MCPRequest{ID: 2}, map[string]interface{}{"title": "Made by an agent"})
+	if got := created.Result.(map[string]interface{})["task"].(*task.Task); got.CreatedBy != "claude-code" {
+		t.Errorf("CreatedBy = %q, want the client", got.CreatedBy)
+	}
+
+	server.SetActor("reviewer-bot")
+	created = server.handleCreateTask(&MCPRequest{ID: 3}, map[string]interface{}{"title": "Made by a named agent"})
+	if got := created.Result.(map[string]interface{})["task"].(*task.Task); got.CreatedBy != "reviewer-bot" {
+		t.Errorf("CreatedBy = %q, want the actor set", got.CreatedBy)
+	}
+}
+
+func TestServer_ClaimTask(t *testing.T) {
+	alpha := setupTestServer(t)
+	alpha.SetActor("alpha")
+	beta := New(alpha.taskSystem, &bytes.Buffer{}, &bytes.Buffer{})
+	beta.SetActor("beta")
+
+	created, _ := alpha.taskSystem.Create(1, "Shared work", "")
+	args := map[string]interface{}{"id": float64(created.ID)}
+
+	resp := alpha.handleClaimTask(&MCPRequest{ID: 1}, args)
+	if resp.Error != nil {
+		t.Fatalf("claim_task error = %v", resp.Error.Message)
+	}
+	if got := resp.Result.(map[string]interface{})["task"].(*task.Task); got.InProgressBy != "alpha" || got.Status != task.StatusDoing {
+		t.Errorf("Expected the task in doing, claimed by alpha, got %+v", got)
+	}
+
+	resp = beta.handleClaimTask(&MCPRequest{ID: 2}, args)
+	if resp.Error == nil || resp.Error.Code != codeConflict {
+		t.Errorf("Expected beta's claim to fail with %d, got %+v", codeConflict, resp.Error)
+	}
+	if resp := beta.handleReleaseTask(&MCPRequest{ID: 3}, args); resp.Error == nil || resp.Error.Code != codeConflict {
+		t.Errorf("Expected beta's release to fail with %d, got %+v", codeConflict, resp.Error)
+	}
+
+	if resp := alpha.handleReleaseTask(&MCPRequest{ID: 4}, args); resp.Error != nil || resp.Result.(map[string]interface{})["released"] != true {
+		t.Fatalf("Expected alpha to release the task, got %+v", resp)
+	}
+	if resp := beta.handleClaimTask(&MCPRequest{ID: 5}, args); resp.Error != nil {
+		t.Errorf("Expected beta to claim the released task, got %v", resp.Error.Message)
+	}
+}
+
+func TestServer_Worklogs(t *testing.T) {
+	server := setupTestServer(t)
+	server.SetActor("coder")
+	created, _ := server.taskSystem.Create(1, "Speed up parser", "")
+
+	resp := server.handleAppendWorklog(&MCPRequest{ID: 1}, map[string]interface{}{
+		"id":            created.Hash,
+		"summary":       "Cached the token table",
+		"files_changed": []interface{}{"lexer.go", "lexer_test.go"},
+		"outcome":       "success",
+	})
+	if resp.Error != nil {
+		t.Fatalf("append_worklog error = %v", resp.Error.Message)
+	}
+	resp = server.handleAppendWorklog(&MCPRequest{ID: 2}, map[string]interface{}{"id": float64(created.ID), "summary": "Tried", "files_changed": "lexer.go"})
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected files_changed that is not an array to be refused, got %+v", resp.Error)
+	}
+
+	resp = server.handleGetWorklogs(&MCPRequest{ID: 3}, map[string]interface{}{"id": float64(created.ID)})
+	if resp.Error != nil {
+		t.Fatalf("get_worklogs error = %v", resp.Error.Message)
+	}
+	worklogs := resp.Result.(map[string]interface{})["worklogs"].([]task.Worklog)
+	if len(worklogs) != 1 || worklogs[0].Agent != "coder" || len(worklogs[0].FilesChanged) != 2 {
+		t.Errorf("Unexpected worklogs: %+v", worklogs)
+	}
+}
+
+func TestServer_Sprints(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create memory database: %v", err)
+	}
+	defer db.Close()
+	server := New(task.New(db.Conn()), &bytes.Buffer{}, &bytes.Buffer{})
+
+	if resp := server.handleListSprints(&MCPRequest{ID: 1}, nil); resp.Error == nil {
+		t.Error("Expected the sprint tools to fail without sprints set")
+	}
+	server.SetSprints(sprint.New(db.Conn()))
+
+	resp := server.handleCreateSprint(&MCPRequest{ID: 2}, map[string]interface{}{"name": "Sprint 1", "start": "today"})
+	if resp.Error != nil {
+		t.Fatalf("create_sprint error = %v", resp.Error.Message)
+	}
+	created := resp.Result.(map[string]interface{})["sprint"].(*sprint.Sprint)
+	if created.Days() != 14 {
+		t.Errorf("Expected a two-week sprint by default, got %s to %s", created.Start, created.End)
+	}
+
+	planned, _ := server.taskSystem.Create(1, "Planned", "")
+	other, _ := server.taskSystem.Create(1, "Backlog", "")
+	resp = server.handlePlanSprint(&MCPRequest{ID: 3}, map[string]interface{}{"sprint": "current", "add": []interface{}{planned.Hash, "999"}})
+	if resp.Error == nil || resp.Error.Code != codeNotFound {
+		t.Errorf("Expected planning a missing task to fail as not found, got %+v", resp.Error)
+	}
+	resp = server.handlePlanSprint(&MCPRequest{ID: 4}, map[string]interface{}{"sprint": "sprint 1", "add": []interface{}{planned.Hash}})
+	if resp.Error != nil {
+		t.Fatalf("plan_sprint error = %v", resp.Error.Message)
+	}
+	if sp := resp.Result.(map[string]interface{})["sprint"].(*sprint.Sprint); sp.Tasks != 1 {
+		t.Errorf("Expected 1 task planned, got %d", sp.Tasks)
+	}
+	resp = server.handlePlanSprint(&MCPRequest{ID: 5}, map[string]interface{}{"sprint": float64(created.ID), "remove": []interface{}{float64(other.ID)}})
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected taking out a task not in the sprint to be refused, got %+v", resp.Error)
+	}
+
+	resp = server.handleGetSprint(&MCPRequest{ID: 6}, map[string]interface{}{})
+	if resp.Error != nil {
+		t.Fatalf("get_sprint error = %v", resp.Error.Message)
+	}
+	result := resp.Result.(map[string]interface{})
+	if tasks := result["tasks"].([]*task.Task); len(tasks) != 1 || tasks[0].ID != planned.ID {
+		t.Errorf("Expected the planned task in the sprint, got %v", tasks)
+	}
+	if burndown := result["burndown"].(*sprint.Burndown); burndown.Total != 1 || len(burndown.Days) != 1 {
+		t.Errorf("Unexpected burndown: %+v", burndown)
+	}
+
+	for sprintArg, want := range map[string]int{"current": planned.ID, "none": other.ID} {
+		resp = server.handleListTasks(&MCPRequest{ID: 7}, map[string]interface{}{"sprint": sprintArg})
+		if resp.Error != nil {
+			t.Fatalf("list_tasks error = %v", resp.Error.Message)
+		}
+		if tasks := resp.Result.(map[string]interface{})["tasks"].([]*task.Task); len(tasks) != 1 || tasks[0].ID != want {
+			t.Errorf("list_tasks with sprint %s = %v, want task %d", sprintArg, tasks, want)
+		}
+	}
+}
+
+func TestServer_SprintCapacity(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create memory database: %v", err)
+	}
+	defer db.Close()
+	server := New(task.New(db.Conn()), &bytes.Buffer{}, &bytes.Buffer{})
+	server.SetSprints(sprint.New(db.Conn()))
+
+	resp := server.handleCreateSprint(&MCPRequest{ID: 1}, map[string]interface{}{"name": "Sprint 1", "capacity": float64(-1)})
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected a negative capacity to be refused, got %+v", resp.Error)
+	}
+	resp = server.handleCreateSprint(&MCPRequest{ID: 2}, map[string]interface{}{"name": "Sprint 1", "capacity": float64(5)})
+	if resp.Error != nil {
+		t.Fatalf("create_sprint error = %v", resp.Error.Message)
+	}
+	if created := resp.Result.(map[string]interface{})["sprint"].(*sprint.Sprint); created.Capacity != 5 {
+		t.Errorf("Capacity = %d, want 5", created.Capacity)
+	}
+
+	small, _ := server.taskSystem.Create(1, "Small", "")
+	large, _ := server.taskSystem.Create(1, "Large", "")
+	for _, estimate := range []struct{ id, points int }{{small.ID, 3}, {large.ID, 5}} {
+		if err := server.taskSystem.UpdateEstimate(estimate.id, estimate.points); err != nil {
+			t.Fatalf("Failed to estimate task: %v", err)
+		}
+	}
+
+	resp = server.handlePlanSprint(&MCPRequest{ID: 3}, map[string]interface{}{"sprint": "current", "add": []interface{}{float64(small.ID)}})
+	if resp.Error != nil {
+		t.Fatalf("plan_sprint error = %v", resp.Error.Message)
+	}
+	if warning, ok := resp.Result.(map[string]interface{})["capacity_warning"]; ok {
+		t.Errorf("Expected no warning within capacity, got %v", warning)
+	}
+
+	// Past the capacity the tasks are still planned, with a warning
+	resp = server.handlePlanSprint(&MCPRequest{ID: 4}, map[string]interface{}{"sprint": "current", "add": []interface{}{float64(large.ID)}})
+	if resp.Error != nil {
+		t.Fatalf("plan_sprint error = %v", resp.Error.Message)
+	}
+	result := resp.Result.(map[string]interface{})
+	warning, ok := result["capacity_warning"].(*sprint.CapacityWarning)
+	if !ok || warning.Points != 8 || warning.Capacity != 5 {
+		t.Errorf("Unexpected capacity warning: %+v", result["capacity_warning"])
+	}
+	if sp := result["sprint"].(*sprint.Sprint); sp.Tasks != 2 {
+		t.Errorf("Expected both tasks planned, got %d", sp.Tasks)
+	}
+	if text := result["content"].([]map[string]interface{})[0]["text"].(string); !strings.Contains(text, "Warning: ") {
+		t.Errorf("Expected the warning in the text, got %q", text)
+	}
+}
+
+func TestServer_BoardLifecycle(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := setupTestServer(t)
+	server.SetBoards(board.New(), "default")
+	server.SetAutoBackup(0)
+
+	resp := server.handleCreateBoard(&MCPRequest{ID: 1}, map[string]interface{}{"board_name": "webshop", "description": "The new shop", "switch": true})
+	if resp.Error != nil {
+		t.Fatalf("create_board error = %v", resp.Error.Message)
+	}
+	if created := resp.Result.(map[string]interface{})["board"].(*board.Board); created.Description != "The new shop" {
+		t.Errorf("Unexpected board: %+v", created)
+	}
+	var reported map[string]interface{}
+	data, _ := json.Marshal(resp.Result.(map[string]interface{})["board"])
+	json.Unmarshal(data, &reported)
+	if _, ok := reported["id"]; ok || reported["name"] != "webshop" || reported["path"] != server.boardSystem.GetBoardPath("webshop") {
+		t.Errorf("Expected the board reported by its name and path, got %v", reported)
+	}
+	if current, _ := server.boardSystem.GetCurrentBoard(); current != "webshop" {
+		t.Errorf("Current board = %q, want the created board", current)
+	}
+	resp = server.handleCreateBoard(&MCPRequest{ID: 2}, map[string]interface{}{"board_name": "webshop"})
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected creating a board twice to be refused, got %+v", resp.Error)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"board_name": "webshop"},
+		{"board_name": "default", "confirm": true},
+	} {
+		if resp := server.handleDeleteBoard(&MCPRequest{ID: 3}, args); resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("Expected delete_board %v to be refused, got %+v", args, resp.Error)
+		}
+	}
+	resp = server.handleDeleteBoard(&MCPRequest{ID: 4}, map[string]interface{}{"board_name": "missing", "confirm": true})
+	if resp.Error == nil || resp.Error.Code != codeNotFound {
+		t.Errorf("Expected deleting a missing board to fail as not found, got %+v", resp.Error)
+	}
+
+	resp = server.handleDeleteBoard(&MCPRequest{ID: 5}, map[string]interface{}{"board_name": "webshop", "confirm": true})
+	if resp.Error != nil {
+		t.Fatalf("delete_board error = %v", resp.Error.Message)
+	}
+	if _, err := server.boardSystem.GetBoard("webshop"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected the board to be gone, got %v", err)
+	}
+	if backups, _ := server.boardSystem.Backups("webshop"); len(backups) != 1 {
+		t.Errorf("Expected the board backed up before deleting it, got %v", backups)
+	}
+}
+
+func TestServer_ChangeBoardReopens(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	boards := board.New()
+	for _, name := range []string{"first", "second", "broken"} {
+		if _, err := boards.CreateBoard(name, ""); err != nil {
+			t.Fatalf("Failed to create board: %v", err)
+		}
+	}
+	open := func(name string) (*Board, error) {
+		if name == "broken" {
+			return nil, fmt.Errorf("board '%s' cannot be opened", name)
+		}
+		db, err := storage.New(boards.GetBoardPath(name))
+		if err != nil {
+			return nil, err
+		}
+		return &Board{Name: name, DB: db, Tasks: task.New(db.Conn()), Sprints: sprint.New(db.Conn())}, nil
+	}
+
+	first, err := open("first")
+	if err != nil {
+		t.Fatalf("Failed to open board: %v", err)
+	}
+	server := New(first.Tasks, &bytes.Buffer{}, &bytes.Buffer{})
+	server.UseBoard(first)
+	server.SetBoardOpener(open)
+	server.SetBoards(boards, "first")
+	defer server.Close()
+	if _, err := first.Tasks.Create(1, "On the first board", ""); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	call := func(id int, tool string, args map[string]interface{}) *MCPResponse {
+		params, _ := json.Marshal(map[string]interface{}{"name": tool, "arguments": args})
+		return server.handleToolsCall(&MCPRequest{ID: id, Params: params})
+	}
+	resp := call(1, "change_board", map[string]interface{}{"board_name": "broken"})
+	if resp.Error == nil {
+		t.Error("Expected changing to a board that cannot be opened to fail")
+	}
+	if current, _ := boards.GetCurrentBoard(); current != "default" || server.boardName != "first" {
+		t.Errorf("Expected the failed change to leave the boards alone, got %q and %q", current, server.boardName)
+	}
+
+	resp = call(2, "change_board", map[string]interface{}{"board_name": "second"})
+	if resp.Error != nil {
+		t.Fatalf("change_board error = %v", resp.Error.Message)
+	}
+	if active := resp.Result.(map[string]interface{})["active_board"]; active != "second" {
+		t.Errorf("active_board = %v, want second", active)
+	}
+	resp = call(3, "list_tasks", map[string]interface{}{})
+	if tasks := resp.Result.(map[string]interface{})["tasks"].([]*task.Task); len(tasks) != 0 {
+		t.Errorf("Expected the tasks of the new board, got %v", tasks)
+	}
+	if err := first.DB.Conn().Ping(); err == nil {
+		t.Error("Expected the database of the first board to be closed")
+	}
+}
+
+func TestServer_ChangeBoardUnderOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("CAINBAN_BOARD", "first")
+	boards := board.New()
+	for _, name := range []string{"first", "second"} {
+		if _, err := boards.CreateBoard(name, ""); err != nil {
+			t.Fatalf("Failed to create board: %v", err)
+		}
+	}
+	open := func(name string) (*Board, error) {
+		db, err := storage.New(boards.GetBoardPath(name))
+		if err != nil {
+			return nil, err
+		}
+		return &Board{Name: name, DB: db, Tasks: task.New(db.Conn())}, nil
+	}
+
+	first, err := open("first")
+	if err != nil {
+		t.Fatalf("Failed to open board: %v", err)
+	}
+	server := New(first.Tasks, &bytes.Buffer{}, &bytes.Buffer{})
+	server.UseBoard(first)
+	server.SetBoards(boards, "first")
+	defer server.Close()
+
+	resp := server.handleChangeBoard(&MCPRequest{ID: 1}, map[string]interface{}{"board_name": "second"})
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected changing board without an opener under an override to be refused, got %+v", resp.Error)
+	}
+
+	server.SetBoardOpener(open)
+	resp = server.handleChangeBoard(&MCPRequest{ID: 2}, map[string]interface{}{"board_name": "second"})
+	if resp.Error != nil {
+		t.Fatalf("change_board error = %v", resp.Error.Message)
+	}
+	if server.boardName != "second" {
+		t.Errorf("Server board = %q, want second", server.boardName)
+	}
+	resp = server.handleCreateBoard(&MCPRequest{ID: 3}, map[string]interface{}{"board_name": "third", "switch": true})
+	if resp.Error != nil {
+		t.Fatalf("create_board error = %v", resp.Error.Message)
+	}
+	if server.boardName != "third" {
+		t.Errorf("Server board = %q, want third", server.boardName)
+	}
+	if _, err := os.Stat(filepath.Join(config.Dir(), "current-board")); !os.IsNotExist(err) {
+		t.Errorf("Expected the current board left alone under an override, got %v", err)
+	}
+}
+
+func TestServer_FlushesAfterEachCall(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	taskSystem := task.New(db.Conn())
+	server := New(taskSystem, &bytes.Buffer{}, &bytes.Buffer{})
+	server.UseBoard(&Board{Name: "team", DB: db, Tasks: taskSystem})
+	defer server.Close()
+
+	flushes := 0
+	var flushErr error
+	db.OnFlush(func() error {
+		flushes++
+		return flushErr
+	})
+	call := func(id int, tool string, args map[string]interface{}) map[string]interface{} {
+		params, _ := json.Marshal(map[string]interface{}{"name": tool, "arguments": args})
+		resp := server.handleToolsCall(&MCPRequest{ID: id, Params: params})
+		if resp.Error != nil {
+			t.Fatalf("%s error = %v", tool, resp.Error.Message)
+		}
+		return resp.Result.(map[string]interface{})
+	}
+
+	if result := call(1, "create_task", map[string]interface{}{"title": "Sent at once"}); flushes != 1 || result["warning"] != nil {
+		t.Errorf("Expected one flush and no warning, got %d and %v", flushes, result["warning"])
+	}
+	flushErr = fmt.Errorf("the board changed on the remote")
+	if result := call(2, "create_task", map[string]interface{}{"title": "Not sent"}); result["warning"] != "the board changed on the remote" {
+		t.Errorf("Expected the failed send as a warning, got %v", result["warning"])
+	}
+}
+
 func TestServer_ErrorHandling(t *testing.T) {
 	server := setupTestServer(t)
 
@@ -249,4 +836,487 @@ func TestServer_ErrorHandling(t *testing.T) {
 			t.Errorf("Expected error code -32602, got %d", resp.Error.Code)
 		}
 	})
+
+	t.Run("MissingTask", func(t *testing.T) {
+		resp := server.handleGetTask(&MCPRequest{ID: 1}, map[string]interface{}{"id": 999.0})
+
+		if resp.Error == nil || resp.Error.Code != codeNotFound {
+			t.Fatalf("Expected error code %d for a missing task, got %+v", codeNotFound, resp.Error)
+		}
+		if !strings.Contains(resp.Error.Message, "not found") {
+			t.Errorf("Expected the message to say what is missing, got %q", resp.Error.Message)
+		}
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		created, err := server.taskSystem.Create(1, "Valid title", "")
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		resp := server.handleUpdateTask(&MCPRequest{ID: 1}, map[string]interface{}{"id": float64(created.ID), "title": "   "})
+
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("Expected error code -32602 for an empty title, got %+v", resp.Error)
+		}
+	})
+}
+
+// serveAll runs the server over input and returns the messages it wrote,
+// failing if it does not finish
+func serveAll(t *testing.T, input io.Reader) []map[string]interface{} {
+	t.Helper()
+	var messages []map[string]interface{}
+	for _, line := range serveLines(t, input) {
+		messages = append(messages, line...)
+	}
+	return messages
+}
+
+// serveLines is serveAll keeping the messages of each output line apart; a
+// batch response is one line holding several
+func serveLines(t *testing.T, input io.Reader) [][]map[string]interface{} {
+	t.Helper()
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create memory database: %v", err)
+	}
+	defer db.Close()
+
+	output := &bytes.Buffer{}
+	server := New(task.New(db.Conn()), input, output)
+	done := make(chan error, 1)
+	go func() { done <- server.Start(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Start failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server did not finish serving its input")
+	}
+
+	var lines [][]map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var messages []map[string]interface{}
+		if strings.HasPrefix(line, "[") {
+			if err := json.Unmarshal([]byte(line), &messages); err != nil || len(messages) == 0 {
+				t.Fatalf("Server wrote an invalid batch %q: %v", line, err)
+			}
+		} else {
+			var message map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &message); err != nil {
+				t.Fatalf("Server wrote invalid JSON %q: %v", line, err)
+			}
+			messages = append(messages, message)
+		}
+		for _, message := range messages {
+			if message["jsonrpc"] != "2.0" {
+				t.Fatalf("Server wrote a message without jsonrpc 2.0: %q", line)
+			}
+		}
+		lines = append(lines, messages)
+	}
+	return lines
+}
+
+func TestServer_StartRecoversFromMalformedInput(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize"`,
+		`[]`,
+		`{"jsonrpc": "1.0", "id": 2, "method": "tools/list"}`,
+		`{"jsonrpc": "2.0", "method": "notifications/initialized"}`,
+		``,
+		`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "create_task", "arguments": {"title": 42}}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "tools/call", "params": {"name": "create_task", "arguments": {"title": "Still serving"}}}`,
+	}, "\n")
+
+	messages := serveAll(t, strings.NewReader(input))
+	codes := []interface{}{-32700.0, -32600.0, -32600.0, -32602.0, nil}
+	if len(messages) != len(codes) {
+		t.Fatalf("Expected %d responses, got %d: %v", len(codes), len(messages), messages)
+	}
+	for i, code := range codes {
+		var got interface{}
+		if e, ok := messages[i]["error"].(map[string]interface{}); ok {
+			got = e["code"]
+		}
+		if got != code {
+			t.Errorf("Response %d: expected error code %v, got %v", i+1, code, messages[i])
+		}
+	}
+	if messages[4]["id"] != 4.0 {
+		t.Errorf("Expected the last response to answer request 4, got %v", messages[4])
+	}
+}
+
+func TestServer_StartResynchronizes(t *testing.T) {
+	initialize := `{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`
+	errorCode := func(message map[string]interface{}) interface{} {
+		if e, ok := message["error"].(map[string]interface{}); ok {
+			return e["code"]
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name  string
+		input io.Reader
+		codes []interface{}
+	}{
+		{"requests split across reads",
+			iotest.OneByteReader(strings.NewReader(initialize + "\n" + initialize + "\n")),
+			[]interface{}{nil, nil}},
+		{"binary garbage",
+			strings.NewReader("\x00\xff\xfe{{{\n" + initialize),
+			[]interface{}{-32700.0, nil}},
+		{"oversized line",
+			io.MultiReader(strings.NewReader(`{"jsonrpc": "2.0", "id": 1, "method": "`),
+				strings.NewReader(strings.Repeat("x", maxMessageSize)), strings.NewReader("\"}\n"+initialize)),
+			[]interface{}{-32600.0, nil}},
+		{"truncated last request",
+			strings.NewReader(initialize + "\n" + `{"jsonrpc": "2.0", "id": 9, "meth`),
+			[]interface{}{nil, -32700.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages := serveAll(t, tt.input)
+			if len(messages) != len(tt.codes) {
+				t.Fatalf("Expected %d responses, got %d: %v", len(tt.codes), len(messages), messages)
+			}
+			for i, code := range tt.codes {
+				if got := errorCode(messages[i]); got != code {
+					t.Errorf("Response %d: expected error code %v, got %v", i+1, code, messages[i])
+				}
+				if code == -32700.0 && messages[i]["error"].(map[string]interface{})["data"] == nil {
+					t.Errorf("Response %d: expected the parse error to say what is wrong", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestServer_StartBatches(t *testing.T) {
+	input := strings.Join([]string{
+		`[{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "create_task", "arguments": {"title": "Batched"}}},` +
+			`{"jsonrpc": "2.0", "method": "notifications/initialized"},` +
+			`{"jsonrpc": "2.0", "id": "b", "method": "nope"},` +
+			`42,` +
+			`{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"name": "list_tasks", "arguments": {}}}]`,
+		`[{"jsonrpc": "2.0", "method": "notifications/initialized"}]`,
+		`[]`,
+		`[{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`,
+	}, "\n")
+
+	lines := serveLines(t, strings.NewReader(input))
+	if len(lines) != 3 {
+		t.Fatalf("Expected a batch response and two errors, got %v", lines)
+	}
+
+	// Responses come back in request order, without the notification
+	batch := lines[0]
+	ids := []interface{}{1.0, "b", nil, 3.0}
+	if len(batch) != len(ids) {
+		t.Fatalf("Expected %d responses in the batch, got %v", len(ids), batch)
+	}
+	for i, id := range ids {
+		if batch[i]["id"] != id {
+			t.Errorf("Response %d: expected id %v, got %v", i+1, id, batch[i])
+		}
+	}
+	if batch[1]["error"].(map[string]interface{})["code"] != -32601.0 || batch[2]["error"].(map[string]interface{})["code"] != -32600.0 {
+		t.Errorf("Expected method-not-found and invalid-request errors, got %v and %v", batch[1], batch[2])
+	}
+	if text := fmt.Sprint(batch[3]["result"]); !strings.Contains(text, "Batched") {
+		t.Errorf("Expected later requests in a batch to see earlier ones, got %s", text)
+	}
+
+	// An empty batch is invalid and a broken one cannot be parsed
+	for i, code := range []float64{-32600, -32700} {
+		if got := lines[1+i][0]["error"].(map[string]interface{})["code"]; got != code {
+			t.Errorf("Expected error %v, got %v", code, lines[1+i])
+		}
+	}
+}
+
+func FuzzServer(f *testing.F) {
+	// Tools such as change_board write to the home directory
+	f.Setenv("HOME", f.TempDir())
+
+	for _, seed := range []string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`,
+		`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "create_task", "arguments": {"title": "A", "priority": "high"}}}`,
+		`{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"name": "list_tasks", "arguments": {"status": "todo"}}}`,
+		`{"jsonrpc": "2.0", "id": "x", "method": "tools/call", "params": {"name": "update_task_status", "arguments": {"id": 1, "status": "done"}}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"name": "link_tasks", "arguments": {"from_task_id": 1, "to_task_id": 1}}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "resources/read", "params": {"uri": "cainban://board/readme"}}`,
+		"{\"jsonrpc\": \"2.0\", \"id\": 1, \"method\": \"initialize\"}\n{\"jsonrpc\"",
+		`{"id": {"nested": [1]}, "method": 5}`,
+		`[{"jsonrpc": "2.0", "id": 1, "method": "initialize"}, {"jsonrpc": "2.0", "method": "notifications/initialized"}, 7]`,
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		lines := 0
+		for _, line := range strings.Split(input, "\n") {
+			if strings.TrimSpace(line) != "" {
+				lines++
+			}
+		}
+		responses := 0
+		for _, line := range serveLines(t, strings.NewReader(input)) {
+			// Server notifications have a method, responses do not
+			if _, ok := line[0]["method"]; !ok {
+				responses++
+			}
+		}
+		if responses > lines {
+			t.Errorf("%d responses to %d lines of %q", responses, lines, input)
+		}
+	})
+}
+
+func TestServer_AssignTaskCapacityWarning(t *testing.T) {
+	server := setupTestServer(t)
+
+	created, err := server.taskSystem.Create(1, "Big task", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	server.taskSystem.UpdateEstimate(created.ID, 8)
+	server.taskSystem.SetCapacity("agent-1", 5)
+
+	resp := server.handleAssignTask(&MCPRequest{ID: 1}, map[string]interface{}{
+		"id":       float64(created.ID),
+		"assignee": "agent-1",
+	})
+
+	if resp.Error != nil {
+		t.Fatalf("Assign task should not return error: %v", resp.Error)
+	}
+
+	result := resp.Result.(map[string]interface{})
+	if _, ok := result["capacity_warning"]; !ok {
+		t.Error("Expected capacity warning when assigning beyond capacity")
+	}
+}
+
+func TestServer_HandoffTaskNotifies(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create memory database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	created, err := taskSystem.Create(1, "Wire up API", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	input := bytes.NewBufferString(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"handoff_task","arguments":{"id":` +
+		fmt.Sprint(created.ID) + `,"agent":"reviewer","note":"ready for review"}}}` + "\n")
+	output := &bytes.Buffer{}
+	if err := New(taskSystem, input, output).Start(context.Background()); err != nil {
+		t.Fatalf("Failed to run server: %v", err)
+	}
+
+	decoder := json.NewDecoder(output)
+	var resp MCPResponse
+	if err := decoder.Decode(&resp); err != nil || resp.Error != nil {
+		t.Fatalf("Handoff should succeed: %v %v", err, resp.Error)
+	}
+
+	var notification MCPNotification
+	if err := decoder.Decode(&notification); err != nil {
+		t.Fatalf("Expected a notification after the response: %v", err)
+	}
+	if notification.Method != "notifications/message" {
+		t.Errorf("Notification method = %q", notification.Method)
+	}
+
+	got, _ := taskSystem.GetByID(created.ID)
+	if got.Assignee != "reviewer" {
+		t.Errorf("Assignee = %q, want reviewer", got.Assignee)
+	}
+	comments, _ := taskSystem.ListComments(created.ID)
+	if len(comments) != 1 {
+		t.Errorf("Expected handoff comment, got %d comments", len(comments))
+	}
+}
+
+func TestServer_UpdateStatusRunsAutomations(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create memory database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	created, err := taskSystem.Create(1, "Ship release", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	automations := automation.New(db.Conn())
+	if _, err := automations.Add(task.StatusDone, automation.KindCommand, "true"); err != nil {
+		t.Fatalf("Failed to add automation: %v", err)
+	}
+
+	input := bytes.NewBufferString(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"update_task_status","arguments":{"id":` +
+		fmt.Sprint(created.ID) + `,"status":"done"}}}` + "\n")
+	output := &bytes.Buffer{}
+	server := New(taskSystem, input, output)
+	server.SetAutomations(automations, "default")
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to run server: %v", err)
+	}
+
+	decoder := json.NewDecoder(output)
+	var resp MCPResponse
+	if err := decoder.Decode(&resp); err != nil || resp.Error != nil {
+		t.Fatalf("Status update should succeed: %v %v", err, resp.Error)
+	}
+
+	var notification MCPNotification
+	if err := decoder.Decode(&notification); err != nil {
+		t.Fatalf("Expected a notification after the response: %v", err)
+	}
+	params, _ := json.Marshal(notification.Params)
+	if !strings.Contains(string(params), `"event":"automation_fired"`) {
+		t.Errorf("Unexpected notification: %s", params)
+	}
+}
+
+func TestServer_Hooks(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create memory database: %v", err)
+	}
+	defer db.Close()
+
+	dir := t.TempDir()
+	for name, script := range map[string]string{
+		automation.HookPreMove:    `grep -q '"priority":4' && { echo 'critical tasks need a review first'; exit 1; }; exit 0`,
+		automation.HookPostDelete: "exit 1",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			t.Fatalf("Failed to write hook: %v", err)
+		}
+	}
+
+	taskSystem := task.New(db.Conn())
+	urgent, _ := taskSystem.CreateWithPriority(1, "Hotfix", "", 4)
+	routine, _ := taskSystem.Create(1, "Tidy up", "")
+
+	input := bytes.NewBufferString(fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"update_task_status","arguments":{"id":%d,"status":"done"}}}
+{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"update_task_status","arguments":{"id":%d,"status":"done"}}}
+{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"delete_task","arguments":{"task_id":%d}}}
+`, urgent.ID, routine.ID, routine.ID))
+	output := &bytes.Buffer{}
+	server := New(taskSystem, input, output)
+	server.SetHooks(automation.NewHooks(dir), "default")
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to run server: %v", err)
+	}
+
+	decoder := json.NewDecoder(output)
+	var refused MCPResponse
+	if err := decoder.Decode(&refused); err != nil || refused.Error == nil || refused.Error.Code != -32602 ||
+		!strings.Contains(refused.Error.Message, "critical tasks need a review first") {
+		t.Fatalf("Expected the pre-move hook to refuse the move, got %+v (%v)", refused.Error, err)
+	}
+	if got, _ := taskSystem.GetByID(urgent.ID); got.Status != task.StatusTodo {
+		t.Errorf("Expected the refused task to stay in todo, got %s", got.Status)
+	}
+
+	var moved, deleted MCPResponse
+	if err := decoder.Decode(&moved); err != nil || moved.Error != nil {
+		t.Fatalf("Expected the other move to go ahead: %v %+v", err, moved.Error)
+	}
+	if err := decoder.Decode(&deleted); err != nil || deleted.Error != nil {
+		t.Fatalf("Expected the delete to go ahead despite its post- hook: %v %+v", err, deleted.Error)
+	}
+	var notification MCPNotification
+	if err := decoder.Decode(&notification); err != nil {
+		t.Fatalf("Expected a notification of the failed post-delete hook: %v", err)
+	}
+	if params, _ := json.Marshal(notification.Params); !strings.Contains(string(params), `"event":"hook_failed"`) {
+		t.Errorf("Unexpected notification: %s", params)
+	}
+}
+
+func TestServer_TaskContext(t *testing.T) {
+	server := setupTestServer(t)
+
+	created, err := server.taskSystem.Create(1, "Resume me", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	for _, content := range []string{"Touched: a.go", "Next: b.go"} {
+		resp := server.handleSetTaskContext(&MCPRequest{ID: 1}, map[string]interface{}{
+			"id":      float64(created.ID),
+			"content": content,
+			"append":  true,
+		})
+		if resp.Error != nil {
+			t.Fatalf("Set task context should not return error: %v", resp.Error)
+		}
+	}
+
+	resp := server.handleGetTaskContext(&MCPRequest{ID: 2}, map[string]interface{}{"id": float64(created.ID)})
+	if resp.Error != nil {
+		t.Fatalf("Get task context should not return error: %v", resp.Error)
+	}
+
+	checkpoint := resp.Result.(map[string]interface{})["context"].(*task.Checkpoint)
+	if checkpoint.Content != "Touched: a.go\n\nNext: b.go" {
+		t.Errorf("Unexpected context: %q", checkpoint.Content)
+	}
+}
+
+func TestServer_StartStopsWithContext(t *testing.T) {
+	// A client that never sends anything nor hangs up
+	input, writer := io.Pipe()
+	defer writer.Close()
+
+	server := setupTestServer(t)
+	server.input = input
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Start(ctx) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected Start to return context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Server kept waiting for input after its context was cancelled")
+	}
+}
+
+func TestServer_RequestTimeout(t *testing.T) {
+	server := setupTestServer(t)
+	if _, err := server.taskSystem.Create(1, "Slow task", ""); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	server.SetRequestTimeout(time.Nanosecond)
+
+	line := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_task","arguments":{"id":1}}}`
+	resp := server.serve(context.Background(), []byte(line))
+	if resp.Error == nil || resp.Error.Code != codeCancelled {
+		t.Fatalf("Expected error code %d for a request past its timeout, got %+v", codeCancelled, resp.Error)
+	}
+
+	// The timeout applies to the request, not to the server
+	server.SetRequestTimeout(time.Minute)
+	if resp := server.serve(context.Background(), []byte(line)); resp.Error != nil {
+		t.Errorf("Expected the next request to be served, got %+v", resp.Error)
+	}
 }
@@ -0,0 +1,191 @@
+package milestone
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// DateLayout is how due dates are written and stored
+const DateLayout = "2006-01-02"
+
+// Milestone groups the tasks of a board that lead to one result, such as a
+// release, optionally due by a date
+type Milestone struct {
+	ID          int    `json:"id"`
+	BoardID     int    `json:"board_id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Due         string `json:"due,omitempty"` // YYYY-MM-DD, "" for none
+
+	// The tasks of the milestone, and the percentage of them done
+	Tasks     int `json:"tasks"`
+	DoneTasks int `json:"done_tasks"`
+	Percent   int `json:"percent"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsOverdue reports whether the milestone is past its due date on now's
+// date with tasks left to do
+func (m *Milestone) IsOverdue(now time.Time) bool {
+	return m.Due != "" && m.Due < now.Format(DateLayout) && m.DoneTasks < m.Tasks
+}
+
+// System handles milestone operations
+type System struct {
+	db  *sql.DB
+	ctx context.Context
+}
+
+// New creates a new milestone system
+func New(db *sql.DB) *System {
+	return &System{db: db, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the system whose queries run under ctx
+func (s *System) WithContext(ctx context.Context) *System {
+	copy := *s
+	copy.ctx = ctx
+	return &copy
+}
+
+// Create adds a milestone to a board, due by the date of due unless it is
+// nil. Names are unique within a board and cannot be a number, which Find
+// takes for an ID.
+func (s *System) Create(boardID int, name, description string, due *time.Time) (*Milestone, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, storage.Errorf(storage.ErrInvalidInput, "milestone name cannot be empty")
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return nil, storage.Errorf(storage.ErrInvalidInput, "invalid milestone name '%s': it would be taken for a milestone ID", name)
+	}
+	m := Milestone{BoardID: boardID, Name: name, Description: strings.TrimSpace(description)}
+	if due != nil {
+		m.Due = due.Format(DateLayout)
+	}
+
+	var taken int
+	if err := s.db.QueryRowContext(s.ctx, `SELECT COUNT(*) FROM milestones WHERE board_id = ? AND name = ? COLLATE NOCASE`, boardID, name).Scan(&taken); err != nil {
+		return nil, fmt.Errorf("failed to check milestone name: %w", err)
+	}
+	if taken > 0 {
+		return nil, storage.Errorf(storage.ErrInvalidInput, "there is a milestone named '%s' already", name)
+	}
+
+	err := s.db.QueryRowContext(s.ctx, `
+		INSERT INTO milestones (board_id, name, description, due_date) VALUES (?, ?, ?, ?)
+		RETURNING id, created_at
+	`, boardID, m.Name, m.Description, m.Due).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create milestone: %w", err)
+	}
+	return &m, nil
+}
+
+// Delete removes a milestone; its tasks stay on the board, in none, and key
+// results no longer count them
+func (s *System) Delete(id int) error {
+	tx, err := s.db.BeginTx(s.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(s.ctx, `DELETE FROM milestones WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	} else if deleted == 0 {
+		return storage.Errorf(storage.ErrNotFound, "milestone with id %d not found", id)
+	}
+	if _, err := tx.ExecContext(s.ctx, `UPDATE tasks SET milestone_id = NULL WHERE milestone_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+	if _, err := tx.ExecContext(s.ctx, `UPDATE key_results SET milestone_id = NULL WHERE milestone_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete milestone: %w", err)
+	}
+	return tx.Commit()
+}
+
+// milestoneColumns lists the columns read by scanMilestone, in scan order
+const milestoneColumns = `id, board_id, name, COALESCE(description, ''), COALESCE(due_date, ''), created_at,
+	(SELECT COUNT(*) FROM tasks WHERE milestone_id = milestones.id AND deleted_at IS NULL),
+	(SELECT COUNT(*) FROM tasks WHERE milestone_id = milestones.id AND deleted_at IS NULL AND status = 'done')`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMilestone(row rowScanner) (*Milestone, error) {
+	var m Milestone
+	err := row.Scan(&m.ID, &m.BoardID, &m.Name, &m.Description, &m.Due, &m.CreatedAt, &m.Tasks, &m.DoneTasks)
+	if err != nil {
+		return nil, err
+	}
+	if m.Tasks > 0 {
+		m.Percent = m.DoneTasks * 100 / m.Tasks
+	}
+	return &m, nil
+}
+
+// List returns the milestones of a board, those due first first and those
+// without a due date last
+func (s *System) List(boardID int) ([]*Milestone, error) {
+	rows, err := s.db.QueryContext(s.ctx, `SELECT `+milestoneColumns+`
+		FROM milestones WHERE board_id = ? ORDER BY due_date = '', due_date, id`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	defer rows.Close()
+
+	var milestones []*Milestone
+	for rows.Next() {
+		m, err := scanMilestone(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan milestone: %w", err)
+		}
+		milestones = append(milestones, m)
+	}
+	return milestones, rows.Err()
+}
+
+// Get returns a milestone by its ID
+func (s *System) Get(id int) (*Milestone, error) {
+	m, err := scanMilestone(s.db.QueryRowContext(s.ctx, `SELECT `+milestoneColumns+` FROM milestones WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, storage.Errorf(storage.ErrNotFound, "milestone with id %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get milestone: %w", err)
+	}
+	return m, nil
+}
+
+// Find returns the milestone of a board that ref names, by ID or by name
+// in any case
+func (s *System) Find(boardID int, ref string) (*Milestone, error) {
+	ref = strings.TrimSpace(ref)
+	if id, err := strconv.Atoi(ref); err == nil {
+		return s.Get(id)
+	}
+
+	m, err := scanMilestone(s.db.QueryRowContext(s.ctx, `SELECT `+milestoneColumns+`
+		FROM milestones WHERE board_id = ? AND name = ? COLLATE NOCASE`, boardID, ref))
+	if err == sql.ErrNoRows {
+		return nil, storage.Errorf(storage.ErrNotFound, "no milestone named '%s'", ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find milestone: %w", err)
+	}
+	return m, nil
+}
@@ -0,0 +1,78 @@
+package milestone
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestMilestones(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	milestones := New(db.Conn())
+	tasks := task.New(db.Conn())
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	due := now.AddDate(0, 0, -1)
+	beta, err := milestones.Create(1, "Beta", "Invite the first users", &due)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	someday, err := milestones.Create(1, "Someday", "", nil)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	for _, name := range []string{"beta", "", "7"} {
+		if _, err := milestones.Create(1, name, "", nil); !errors.Is(err, storage.ErrInvalidInput) {
+			t.Errorf("Create(%q) error = %v, want ErrInvalidInput", name, err)
+		}
+	}
+
+	var created []*task.Task
+	for _, title := range []string{"Signup", "Invites", "Docs"} {
+		c, _ := tasks.Create(1, title, "")
+		if err := tasks.SetMilestone(c.ID, &beta.ID); err != nil {
+			t.Fatalf("SetMilestone() error = %v", err)
+		}
+		created = append(created, c)
+	}
+	tasks.UpdateStatus(created[0].ID, task.StatusDone)
+	missing := 999
+	if err := tasks.SetMilestone(created[1].ID, &missing); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected a missing milestone to be refused, got %v", err)
+	}
+
+	got, err := milestones.Find(1, "BETA")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if got.Tasks != 3 || got.DoneTasks != 1 || got.Percent != 33 || !got.IsOverdue(now) {
+		t.Errorf("Unexpected milestone: %+v", got)
+	}
+
+	list, _ := milestones.List(1)
+	if len(list) != 2 || list[0].ID != beta.ID || list[1].ID != someday.ID {
+		t.Errorf("Expected the milestone due first first, got %+v", list)
+	}
+	inBeta, _ := tasks.ListWith(1, task.ListOptions{MilestoneID: beta.ID})
+	if len(inBeta) != 3 {
+		t.Errorf("Expected 3 tasks in the milestone, got %d", len(inBeta))
+	}
+
+	if err := milestones.Delete(beta.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got, _ := tasks.GetByID(created[0].ID); got.MilestoneID != nil {
+		t.Errorf("Expected the task to leave the deleted milestone, got %v", *got.MilestoneID)
+	}
+	if _, err := milestones.Find(1, "Beta"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected the deleted milestone to be gone, got %v", err)
+	}
+}
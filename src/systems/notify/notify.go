@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Notifier delivers a notification to the user
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// Desktop returns a notifier that shows desktop notifications through
+// notify-send on Linux or osascript on macOS, falling back to writing to
+// fallback when neither is available
+func Desktop(fallback io.Writer) Notifier {
+	switch runtime.GOOS {
+	case "linux":
+		if path, err := exec.LookPath("notify-send"); err == nil {
+			return &command{name: path, args: func(title, message string) []string {
+				return []string{"--app-name=cainban", title, message}
+			}, fallback: Writer(fallback)}
+		}
+	case "darwin":
+		if path, err := exec.LookPath("osascript"); err == nil {
+			return &command{name: path, args: func(title, message string) []string {
+				script := fmt.Sprintf("display notification %s with title %s", appleString(message), appleString(title))
+				return []string{"-e", script}
+			}, fallback: Writer(fallback)}
+		}
+	}
+	return Writer(fallback)
+}
+
+// Writer returns a notifier that prints timestamped notifications to w
+func Writer(w io.Writer) Notifier {
+	return &writer{w: w}
+}
+
+type writer struct {
+	w io.Writer
+}
+
+func (n *writer) Notify(title, message string) error {
+	line := fmt.Sprintf("[%s] %s", time.Now().Format("2006-01-02 15:04"), title)
+	if message != "" {
+		line += ": " + message
+	}
+	_, err := fmt.Fprintln(n.w, line)
+	return err
+}
+
+// command shows notifications by running an external program
+type command struct {
+	name     string
+	args     func(title, message string) []string
+	fallback Notifier
+}
+
+func (n *command) Notify(title, message string) error {
+	if err := exec.Command(n.name, n.args(title, message)...).Run(); err != nil {
+		// Headless sessions have the binary but no notification daemon
+		return n.fallback.Notify(title, message)
+	}
+	return nil
+}
+
+// appleString quotes s as an AppleScript string literal
+func appleString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
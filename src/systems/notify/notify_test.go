@@ -0,0 +1,36 @@
+package notify
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriterNotifier(t *testing.T) {
+	var buf bytes.Buffer
+	n := Writer(&buf)
+
+	if err := n.Notify("Reminder: #3 Call the bank", "before they close"); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if err := n.Notify("No message", ""); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %q", buf.String())
+	}
+	if !strings.HasSuffix(lines[0], "] Reminder: #3 Call the bank: before they close") {
+		t.Errorf("Unexpected line %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "] No message") {
+		t.Errorf("Unexpected line %q", lines[1])
+	}
+}
+
+func TestAppleString(t *testing.T) {
+	if got := appleString(`say "hi" \ bye`); got != `"say \"hi\" \\ bye"` {
+		t.Errorf("appleString() = %s", got)
+	}
+}
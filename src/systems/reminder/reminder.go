@@ -0,0 +1,125 @@
+package reminder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Reminder is a one-off nudge about a task at a given time
+type Reminder struct {
+	ID        int        `json:"id"`
+	TaskID    int        `json:"task_id"`
+	TaskTitle string     `json:"task_title"`
+	RemindAt  time.Time  `json:"remind_at"`
+	Note      string     `json:"note,omitempty"`
+	FiredAt   *time.Time `json:"fired_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// System handles reminder operations
+type System struct {
+	db  *sql.DB
+	ctx context.Context
+}
+
+// New creates a new reminder system
+func New(db *sql.DB) *System {
+	return &System{db: db, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the system whose queries are cancelled
+// with ctx
+func (s *System) WithContext(ctx context.Context) *System {
+	copy := *s
+	copy.ctx = ctx
+	return &copy
+}
+
+// Add schedules a reminder for a task
+func (s *System) Add(taskID int, at time.Time, note string) (*Reminder, error) {
+	var title string
+	err := s.db.QueryRowContext(s.ctx, `SELECT title FROM tasks WHERE id = ? AND deleted_at IS NULL`, taskID).Scan(&title)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("task with ID %d not found", taskID)
+		}
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	r := Reminder{TaskID: taskID, TaskTitle: title, RemindAt: at.UTC().Truncate(time.Second), Note: strings.TrimSpace(note)}
+	err = s.db.QueryRowContext(s.ctx, `
+		INSERT INTO reminders (task_id, remind_at, note) VALUES (?, ?, ?)
+		RETURNING id, created_at
+	`, taskID, r.RemindAt, r.Note).Scan(&r.ID, &r.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reminder: %w", err)
+	}
+
+	return &r, nil
+}
+
+// Cancel removes a pending reminder
+func (s *System) Cancel(id int) error {
+	result, err := s.db.ExecContext(s.ctx, `DELETE FROM reminders WHERE id = ? AND fired_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel reminder: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check cancel result: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pending reminder with ID %d not found", id)
+	}
+	return nil
+}
+
+// Pending returns reminders that have not fired yet, soonest first
+func (s *System) Pending() ([]Reminder, error) {
+	return s.query(`WHERE r.fired_at IS NULL ORDER BY r.remind_at ASC, r.id ASC`)
+}
+
+// Due returns pending reminders whose time has come
+func (s *System) Due(now time.Time) ([]Reminder, error) {
+	return s.query(`WHERE r.fired_at IS NULL AND r.remind_at <= ? ORDER BY r.remind_at ASC, r.id ASC`, now.UTC())
+}
+
+// MarkFired records that a reminder has been delivered
+func (s *System) MarkFired(id int, at time.Time) error {
+	if _, err := s.db.ExecContext(s.ctx, `UPDATE reminders SET fired_at = ? WHERE id = ?`, at.UTC(), id); err != nil {
+		return fmt.Errorf("failed to mark reminder fired: %w", err)
+	}
+	return nil
+}
+
+// query selects reminders of live tasks with the given trailing clause
+func (s *System) query(clause string, args ...interface{}) ([]Reminder, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT r.id, r.task_id, t.title, r.remind_at, COALESCE(r.note, ''), r.fired_at, r.created_at
+		FROM reminders r
+		JOIN tasks t ON t.id = r.task_id AND t.deleted_at IS NULL
+		`+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []Reminder
+	for rows.Next() {
+		var r Reminder
+		if err := rows.Scan(&r.ID, &r.TaskID, &r.TaskTitle, &r.RemindAt, &r.Note, &r.FiredAt, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reminder: %w", err)
+		}
+		reminders = append(reminders, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reminders: %w", err)
+	}
+
+	return reminders, nil
+}
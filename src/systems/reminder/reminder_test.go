@@ -0,0 +1,76 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestReminderLifecycle(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	reminderSystem := New(db.Conn())
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	created, _ := taskSystem.Create(1, "Call the bank", "")
+	other, _ := taskSystem.Create(1, "Deleted later", "")
+
+	soon, err := reminderSystem.Add(created.ID, now.Add(time.Hour), "before they close")
+	if err != nil {
+		t.Fatalf("Failed to add reminder: %v", err)
+	}
+	later, _ := reminderSystem.Add(created.ID, now.Add(48*time.Hour), "")
+	if _, err := reminderSystem.Add(other.ID, now.Add(time.Minute), ""); err != nil {
+		t.Fatalf("Failed to add reminder: %v", err)
+	}
+	if _, err := reminderSystem.Add(999, now, ""); err == nil {
+		t.Error("Expected error for missing task")
+	}
+
+	// Reminders of deleted tasks are dropped
+	taskSystem.Delete(other.ID)
+
+	pending, err := reminderSystem.Pending()
+	if err != nil {
+		t.Fatalf("Failed to list pending reminders: %v", err)
+	}
+	if len(pending) != 2 || pending[0].ID != soon.ID || pending[0].TaskTitle != "Call the bank" {
+		t.Fatalf("Unexpected pending reminders: %+v", pending)
+	}
+
+	due, _ := reminderSystem.Due(now)
+	if len(due) != 0 {
+		t.Errorf("Expected nothing due yet, got %+v", due)
+	}
+
+	due, _ = reminderSystem.Due(now.Add(2 * time.Hour))
+	if len(due) != 1 || due[0].ID != soon.ID || due[0].Note != "before they close" {
+		t.Fatalf("Expected the first reminder to be due, got %+v", due)
+	}
+
+	if err := reminderSystem.MarkFired(soon.ID, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Failed to mark fired: %v", err)
+	}
+	due, _ = reminderSystem.Due(now.Add(2 * time.Hour))
+	if len(due) != 0 {
+		t.Errorf("Expected fired reminder not to be due again, got %+v", due)
+	}
+
+	if err := reminderSystem.Cancel(soon.ID); err == nil {
+		t.Error("Expected error cancelling a fired reminder")
+	}
+	if err := reminderSystem.Cancel(later.ID); err != nil {
+		t.Fatalf("Failed to cancel reminder: %v", err)
+	}
+	pending, _ = reminderSystem.Pending()
+	if len(pending) != 0 {
+		t.Errorf("Expected no pending reminders, got %+v", pending)
+	}
+}
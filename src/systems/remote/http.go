@@ -0,0 +1,195 @@
+package remote
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Timeout bounds a request to a board served over HTTP
+const Timeout = time.Minute
+
+// MaxBoardSize is the largest board a server takes
+const MaxBoardSize = 256 << 20
+
+// httpTransport reaches a board served by Handler
+type httpTransport struct {
+	url   string
+	token string
+}
+
+func (t *httpTransport) Fetch() ([]byte, string, error) {
+	resp, err := t.do(http.MethodGet, nil, "")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", t.url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to fetch %s: %s", t.url, responseError(resp))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", t.url, err)
+	}
+	version := resp.Header.Get("ETag")
+	if version == "" {
+		version = checksum(data)
+	}
+	return data, version, nil
+}
+
+func (t *httpTransport) Store(data []byte, version string) (string, error) {
+	resp, err := t.do(http.MethodPut, data, version)
+	if err != nil {
+		return "", fmt.Errorf("failed to send to %s: %w", t.url, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return "", ErrConflict
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return "", fmt.Errorf("failed to send to %s: %s", t.url, responseError(resp))
+	}
+	if version = resp.Header.Get("ETag"); version == "" {
+		version = checksum(data)
+	}
+	return version, nil
+}
+
+func (t *httpTransport) do(method string, body []byte, ifMatch string) (*http.Response, error) {
+	req, err := http.NewRequest(method, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "cainban")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/vnd.sqlite3")
+		req.Header.Set("If-Match", ifMatch)
+	}
+	return (&http.Client{Timeout: Timeout}).Do(req)
+}
+
+// responseError describes a failed response by its status and the first
+// line of its body
+func responseError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if line, _, _ := strings.Cut(strings.TrimSpace(string(body)), "\n"); line != "" {
+		return resp.Status + ": " + line
+	}
+	return resp.Status
+}
+
+// server serves one board to httpTransport
+type server struct {
+	db    *storage.DB
+	token string
+	mu    sync.Mutex // one request at a time, so a PUT checks the version it replaces
+}
+
+// Handler serves the board in db: GET returns it, with its version as the
+// ETag, and PUT replaces it if it is still at the version in If-Match.
+// With a token, requests must carry it as a bearer token. The board stays
+// in use meanwhile, as with a backup.
+func Handler(db *storage.DB, token string) http.Handler {
+	return &server{db: db, token: token}
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+			http.Error(w, "a valid token is required", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		data, sum, err := snapshot(s.db)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.sqlite3")
+		w.Header().Set("ETag", etag(sum))
+		w.Write(data)
+
+	case http.MethodPut:
+		s.put(w, r)
+
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "only GET and PUT are served", http.StatusMethodNotAllowed)
+	}
+}
+
+// put replaces the board with the request body
+func (s *server) put(w http.ResponseWriter, r *http.Request) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		http.Error(w, "If-Match with the version fetched is required", http.StatusPreconditionRequired)
+		return
+	}
+	_, sum, err := snapshot(s.db)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ifMatch != etag(sum) {
+		http.Error(w, ErrConflict.Error(), http.StatusPreconditionFailed)
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "cainban-remote-")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "board.db")
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBoardSize))
+	if err == nil {
+		err = os.WriteFile(path, data, 0600)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.db.RestoreFrom(path); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrInvalidInput) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if _, sum, err = snapshot(s.db); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", etag(sum))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func etag(sum string) string {
+	return `"` + sum + `"`
+}
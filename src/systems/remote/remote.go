@@ -0,0 +1,171 @@
+// Package remote reaches boards kept on another machine, over ssh or HTTP.
+// A remote board is worked on in a local copy: fetched whole before a
+// command and sent back whole after it, if it changed, unless the board
+// changed on the remote meanwhile.
+package remote
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// ErrConflict reports a board that changed on the remote since it was
+// fetched, so that sending the local copy would undo someone else's work
+var ErrConflict = errors.New("the board changed on the remote since it was fetched")
+
+// Transport moves a board's database file to and from where it is kept
+type Transport interface {
+	// Fetch returns the board's database and the version it is at
+	Fetch() (data []byte, version string, err error)
+	// Store replaces the board with data if it is still at version, ""
+	// for a board that does not exist yet, and returns the new version.
+	// It returns ErrConflict if the board is at another version.
+	Store(data []byte, version string) (string, error)
+}
+
+// Open returns the transport to the board at rawURL: ssh://[user@]host[:port]/path
+// for a database file reached over ssh, a path starting /~/ for one in the
+// home directory there, or http:// and https:// for one served by
+// cainban board serve, which token, if any, is sent to.
+func Open(rawURL, token string) (Transport, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, storage.Errorf(storage.ErrInvalidInput, "invalid remote URL %q: %w", rawURL, err)
+	}
+	switch u.Scheme {
+	case "ssh":
+		if u.Host == "" || u.Path == "" || u.Path == "/" {
+			return nil, storage.Errorf(storage.ErrInvalidInput, "invalid remote URL %q: want ssh://[user@]host[:port]/path/to/board.db", rawURL)
+		}
+		// ssh would take a host starting with - for an option
+		t := newSSH(u)
+		if strings.HasPrefix(t.host, "-") {
+			return nil, storage.Errorf(storage.ErrInvalidInput, "invalid remote URL %q: host or user starts with -", rawURL)
+		}
+		return t, nil
+	case "http", "https":
+		if u.Host == "" {
+			return nil, storage.Errorf(storage.ErrInvalidInput, "invalid remote URL %q: no host", rawURL)
+		}
+		return &httpTransport{url: u.String(), token: token}, nil
+	default:
+		return nil, storage.Errorf(storage.ErrInvalidInput, "invalid remote URL %q: want ssh://, http:// or https://", rawURL)
+	}
+}
+
+// Copy is a board fetched into a local database, to send back once it
+// has been worked on. It is safe for concurrent use.
+type Copy struct {
+	mu        sync.Mutex
+	transport Transport
+	db        *storage.DB
+	version   string // version of the board on the remote
+	sum       string // checksum of the local copy as fetched or last sent
+}
+
+// Fetch replaces the board in db with the one transport reaches
+func Fetch(transport Transport, db *storage.DB) (*Copy, error) {
+	c := &Copy{transport: transport, db: db}
+	if err := c.Refresh(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Refresh fetches the board again, replacing the local copy and whatever
+// changes it has not sent
+func (c *Copy) Refresh() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, version, err := c.transport.Fetch()
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "cainban-remote-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "board.db")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write fetched board: %w", err)
+	}
+	if err := c.db.RestoreFrom(path); err != nil {
+		return fmt.Errorf("fetched board: %w", err)
+	}
+
+	_, sum, err := snapshot(c.db)
+	if err != nil {
+		return err
+	}
+	c.version, c.sum = version, sum
+	return nil
+}
+
+// Publish sends the board in db to where transport reaches, where there
+// must be no board yet, and returns the copy to send later changes from
+func Publish(transport Transport, db *storage.DB) (*Copy, error) {
+	c := &Copy{transport: transport, db: db}
+	if _, err := c.Send(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Send sends the local copy back if it changed since it was fetched or last
+// sent, reporting whether it did
+func (c *Copy) Send() (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, sum, err := snapshot(c.db)
+	if err != nil {
+		return false, err
+	}
+	if sum == c.sum {
+		return false, nil
+	}
+
+	version, err := c.transport.Store(data, c.version)
+	if err != nil {
+		return false, err
+	}
+	c.version, c.sum = version, sum
+	return true, nil
+}
+
+// snapshot returns a consistent copy of the database in db as one file,
+// with its checksum
+func snapshot(db *storage.DB) ([]byte, string, error) {
+	dir, err := os.MkdirTemp("", "cainban-remote-")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "board.db")
+	if err := db.Backup(path); err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return data, checksum(data), nil
+}
+
+// checksum returns the hex SHA-256 of data, the version of a board kept as
+// a plain file
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
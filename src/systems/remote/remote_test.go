@@ -0,0 +1,140 @@
+package remote
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// openBoard opens a board database in a test directory
+func openBoard(t *testing.T, name string) (*storage.DB, *task.System) {
+	t.Helper()
+	db, err := storage.New(filepath.Join(t.TempDir(), name+".db"))
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, task.New(db.Conn())
+}
+
+// testTransport runs the same changes on two machines sharing a board
+// through transport
+func testTransport(t *testing.T, transport Transport) {
+	t.Helper()
+	laptopDB, laptop := openBoard(t, "laptop")
+	desktopDB, desktop := openBoard(t, "desktop")
+
+	onLaptop, err := Fetch(transport, laptopDB)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if sent, err := onLaptop.Send(); err != nil || sent {
+		t.Fatalf("Expected nothing to send before a change, got %v, %v", sent, err)
+	}
+	laptop.Create(1, "Write the report", "")
+	if sent, err := onLaptop.Send(); err != nil || !sent {
+		t.Fatalf("Expected the change to be sent, got %v, %v", sent, err)
+	}
+
+	onDesktop, err := Fetch(transport, desktopDB)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	tasks, _ := desktop.List(1)
+	if len(tasks) != 1 || tasks[0].Title != "Write the report" {
+		t.Fatalf("Expected the laptop's task on the desktop, got %v", tasks)
+	}
+
+	// The laptop sends again; the desktop, behind now, is refused
+	laptop.Create(1, "From the laptop", "")
+	if _, err := onLaptop.Send(); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	desktop.Create(1, "From the desktop", "")
+	if _, err := onDesktop.Send(); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+
+	// Refreshed, the desktop works on top of the laptop's changes
+	if err := onDesktop.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	desktop.Create(1, "From the desktop, again", "")
+	if sent, err := onDesktop.Send(); err != nil || !sent {
+		t.Errorf("Expected the change to be sent after a refresh, got %v, %v", sent, err)
+	}
+	if tasks, _ := desktop.List(1); len(tasks) != 3 {
+		t.Errorf("Expected the laptop's two tasks and the desktop's, got %v", tasks)
+	}
+}
+
+func TestHTTP(t *testing.T) {
+	db, _ := openBoard(t, "served")
+	srv := httptest.NewServer(Handler(db, "s3cret"))
+	defer srv.Close()
+
+	if _, _, err := (&httpTransport{url: srv.URL}).Fetch(); err == nil {
+		t.Error("Expected a request without the token to be refused")
+	}
+
+	transport, err := Open(srv.URL+"/", "s3cret")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	testTransport(t, transport)
+}
+
+func TestSSH(t *testing.T) {
+	if _, err := exec.LookPath("sha256sum"); err != nil {
+		t.Skip("sha256sum not available")
+	}
+	// Stand in for ssh: drop the port, -- and the host, run the command here
+	dir := t.TempDir()
+	fake := filepath.Join(dir, "ssh")
+	script := "#!/bin/sh\n[ \"$1\" = -p ] && shift 2\n[ \"$1\" = -- ] || exit 1\nshift 2\nexec sh -c \"$1\"\n"
+	if err := os.WriteFile(fake, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := sshCommand
+	sshCommand = fake
+	defer func() { sshCommand = old }()
+
+	transport, err := Open("ssh://me@host:2222"+filepath.Join(dir, "it's shared", "team.db"), "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, _, err := transport.Fetch(); err == nil {
+		t.Error("Expected fetching a board that is not there to fail")
+	}
+
+	db, _ := openBoard(t, "new")
+	if _, err := Publish(transport, db); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if _, err := Publish(transport, db); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected publishing over a board to conflict, got %v", err)
+	}
+	testTransport(t, transport)
+}
+
+func TestOpen(t *testing.T) {
+	for _, rawURL := range []string{"ftp://host/board.db", "ssh://host", "https://", "/path/board.db", "ssh://-oProxyCommand=x/board.db", "ssh://-oProxyCommand=x@host/board.db"} {
+		if _, err := Open(rawURL, ""); !errors.Is(err, storage.ErrInvalidInput) {
+			t.Errorf("Open(%q) error = %v, want ErrInvalidInput", rawURL, err)
+		}
+	}
+
+	transport, err := Open("ssh://host/~/boards/team.db", "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := transport.(*sshTransport).path; got != `"$HOME"/'boards/team.db'` {
+		t.Errorf("path = %s, want the home directory expanded", got)
+	}
+}
@@ -0,0 +1,92 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// sshCommand is the ssh client run to reach a host; tests replace it
+var sshCommand = "ssh"
+
+// conflictStatus is the exit status of the store script when the board
+// changed since it was fetched
+const conflictStatus = 3
+
+// sshTransport reaches a board's database file over ssh, with nothing but
+// a POSIX shell and sha256sum or shasum on the host
+type sshTransport struct {
+	url  string
+	host string // [user@]host
+	port string
+	path string // shell word for the file, quoted
+}
+
+func newSSH(u *url.URL) *sshTransport {
+	host := u.Hostname()
+	if u.User != nil {
+		host = u.User.Username() + "@" + host
+	}
+
+	// A path starting /~/ is relative to the home directory, which the
+	// quoting would keep the shell from expanding
+	path := shellQuote(u.Path)
+	if rest, ok := strings.CutPrefix(u.Path, "/~/"); ok {
+		path = `"$HOME"/` + shellQuote(rest)
+	}
+	return &sshTransport{url: u.String(), host: host, port: u.Port(), path: path}
+}
+
+func (t *sshTransport) Fetch() ([]byte, string, error) {
+	data, err := t.run("cat -- "+t.path, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", t.url, err)
+	}
+	return data, checksum(data), nil
+}
+
+func (t *sshTransport) Store(data []byte, version string) (string, error) {
+	script := fmt.Sprintf(`f=%s
+sum=$( (sha256sum "$f" || shasum -a 256 "$f") 2>/dev/null | cut -c1-64)
+[ "$sum" = %s ] || exit %d
+mkdir -p "$(dirname "$f")" && cat > "$f.upload" && mv -f "$f.upload" "$f"`, t.path, shellQuote(version), conflictStatus)
+
+	if _, err := t.run(script, data); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == conflictStatus {
+			return "", ErrConflict
+		}
+		return "", fmt.Errorf("failed to send to %s: %w", t.url, err)
+	}
+	return checksum(data), nil
+}
+
+// run runs script in the shell on the host, with stdin as its input
+func (t *sshTransport) run(script string, stdin []byte) ([]byte, error) {
+	var args []string
+	if t.port != "" {
+		args = append(args, "-p", t.port)
+	}
+	args = append(args, "--", t.host, "sh -c "+shellQuote(script))
+
+	cmd := exec.Command(sshCommand, args...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// shellQuote quotes s as a single word for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// StaleTask is a task that has been in progress for too long
+type StaleTask struct {
+	*task.Task
+	DoingSince time.Time `json:"doing_since"`
+}
+
+// Attention is what on a board needs looking at: unfinished tasks past
+// their due date and tasks stuck in doing
+type Attention struct {
+	Board   string       `json:"board"`
+	Overdue []*task.Task `json:"overdue"`
+	Stale   []StaleTask  `json:"stale"`
+}
+
+// Empty reports whether nothing needs attention
+func (a *Attention) Empty() bool {
+	return len(a.Overdue) == 0 && len(a.Stale) == 0
+}
+
+// Attention collects the overdue tasks of a board, earliest due first, and
+// the tasks that moved to doing more than staleAfter before now, longest
+// stuck first. A staleAfter of 0 or less looks for overdue tasks only.
+func (s *System) Attention(boardName string, boardID int, staleAfter time.Duration, now time.Time) (*Attention, error) {
+	tasks, err := task.New(s.db).List(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	attention := &Attention{Board: boardName, Overdue: []*task.Task{}, Stale: []StaleTask{}}
+	for _, t := range tasks {
+		if t.IsOverdue(now) {
+			attention.Overdue = append(attention.Overdue, t)
+		}
+	}
+	sort.SliceStable(attention.Overdue, func(i, j int) bool {
+		return attention.Overdue[i].DueAt.Before(*attention.Overdue[j].DueAt)
+	})
+
+	if staleAfter <= 0 {
+		return attention, nil
+	}
+	byID := make(map[int]*task.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+	since, err := s.doingSince(boardID)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range since {
+		if t := byID[entry.taskID]; t != nil && now.Sub(entry.at) > staleAfter {
+			attention.Stale = append(attention.Stale, StaleTask{Task: t, DoingSince: entry.at})
+		}
+	}
+	return attention, nil
+}
+
+// doingSince returns when each task of a board now in doing last moved
+// there, earliest first
+func (s *System) doingSince(boardID int) ([]statusEvent, error) {
+	query := `
+		SELECT e.task_id, MAX(e.created_at) AS doing_at
+		FROM task_events e
+		JOIN tasks t ON t.id = e.task_id
+		WHERE t.board_id = ? AND t.status = 'doing' AND t.deleted_at IS NULL
+			AND e.event_type IN ('created', 'status_changed') AND e.to_status = 'doing'
+		GROUP BY e.task_id
+		ORDER BY doing_at, e.task_id
+	`
+
+	rows, err := s.db.QueryContext(s.ctx, query, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tasks in progress: %w", err)
+	}
+	defer rows.Close()
+
+	var events []statusEvent
+	for rows.Next() {
+		event := statusEvent{status: string(task.StatusDoing)}
+		var doingAt string
+		if err := rows.Scan(&event.taskID, &doingAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task in progress: %w", err)
+		}
+		if event.at, err = parseTimestamp(doingAt); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tasks in progress: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,78 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestAttention(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	reportSystem := New(db.Conn())
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	create := func(title string, status task.Status, due *time.Time, doingAt time.Time) *task.Task {
+		created, err := taskSystem.Create(1, title, "")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if due != nil {
+			if err := taskSystem.SetDue(created.ID, due); err != nil {
+				t.Fatalf("Failed to set due date: %v", err)
+			}
+		}
+		if status != task.StatusTodo {
+			if err := taskSystem.UpdateStatus(created.ID, status); err != nil {
+				t.Fatalf("Failed to move task: %v", err)
+			}
+		}
+		_, err = db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id = ? AND to_status = 'doing'`,
+			doingAt.Format("2006-01-02 15:04:05"), created.ID)
+		if err != nil {
+			t.Fatalf("Failed to backdate event: %v", err)
+		}
+		return created
+	}
+	at := func(days int) *time.Time {
+		due := now.AddDate(0, 0, days)
+		return &due
+	}
+
+	lateDoc := create("Write the docs", task.StatusTodo, at(-1), now)
+	lateRelease := create("Ship the release", task.StatusTodo, at(-3), now)
+	create("Due next week", task.StatusTodo, at(7), now)
+	create("Done late", task.StatusDone, at(-5), now)
+	stuck := create("Migrate the database", task.StatusDoing, nil, now.AddDate(0, 0, -10))
+	create("Started yesterday", task.StatusDoing, nil, now.AddDate(0, 0, -1))
+
+	attention, err := reportSystem.Attention("work", 1, 7*24*time.Hour, now)
+	if err != nil {
+		t.Fatalf("Attention() error = %v", err)
+	}
+	if len(attention.Overdue) != 2 || attention.Overdue[0].ID != lateRelease.ID || attention.Overdue[1].ID != lateDoc.ID {
+		t.Errorf("Expected the two unfinished overdue tasks, earliest due first, got %v", attention.Overdue)
+	}
+	if len(attention.Stale) != 1 || attention.Stale[0].ID != stuck.ID {
+		t.Fatalf("Expected the task in doing for 10 days to be stale, got %v", attention.Stale)
+	}
+	if want := now.AddDate(0, 0, -10); !attention.Stale[0].DoingSince.Equal(want) {
+		t.Errorf("DoingSince = %v, want %v", attention.Stale[0].DoingSince, want)
+	}
+
+	// Without a stale limit only overdue tasks need attention
+	attention, err = reportSystem.Attention("work", 1, 0, now)
+	if err != nil {
+		t.Fatalf("Attention() error = %v", err)
+	}
+	if len(attention.Stale) != 0 || attention.Empty() {
+		t.Errorf("Expected overdue tasks only, got %+v", attention)
+	}
+}
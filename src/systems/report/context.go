@@ -0,0 +1,178 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// contextDescriptionLimit caps the characters of a description included in
+// a context export, so one long task cannot crowd out the others
+const contextDescriptionLimit = 400
+
+// EstimateTokens approximates how many tokens text takes up in an LLM
+// context window. Tokenizers average about four characters per token on
+// English prose, but split short words and punctuation finer, so the
+// estimate is the larger of characters/4 and words*4/3.
+func EstimateTokens(text string) int {
+	byChars := (utf8.RuneCountInString(text) + 3) / 4
+	byWords := (len(strings.Fields(text))*4 + 2) / 3
+	return max(byChars, byWords)
+}
+
+// BoardContext is a board rendered for an LLM context window
+type BoardContext struct {
+	Board    string `json:"board"`
+	Text     string `json:"text"`
+	Tokens   int    `json:"tokens"` // estimated, see EstimateTokens
+	Included []int  `json:"included"`
+	Omitted  int    `json:"omitted"`
+}
+
+// contextEntry is one task of a context export
+type contextEntry struct {
+	section string
+	task    *task.Task
+	blocks  []int
+}
+
+// Context renders the open tasks of a board as Markdown for an LLM, most
+// relevant first: tasks in progress, then tasks blocking other open tasks,
+// then the remaining todo tasks by priority. With maxTokens > 0 the export
+// is cut to fit the budget: descriptions are dropped from tasks that do not
+// fit with them, and the tasks that do not fit at all are omitted and
+// counted.
+func (s *System) Context(boardName string, boardID, maxTokens int) (*BoardContext, error) {
+	taskSystem := task.New(s.db)
+	tasks, err := taskSystem.List(boardID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := taskSystem.ListLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	open := make(map[int]*task.Task)
+	for _, t := range tasks {
+		if t.Status != task.StatusDone {
+			open[t.ID] = t
+		}
+	}
+
+	blocks := make(map[int][]int)
+	for _, l := range links {
+		blocker, blocked, ok := l.Dependency()
+		if ok && open[blocker] != nil && open[blocked] != nil {
+			blocks[blocker] = append(blocks[blocker], blocked)
+		}
+	}
+	for _, ids := range blocks {
+		sort.Ints(ids)
+	}
+
+	// List orders by priority, highest first
+	var doing, blockers, todo []contextEntry
+	for _, t := range tasks {
+		switch {
+		case open[t.ID] == nil:
+		case t.Status == task.StatusDoing:
+			doing = append(doing, contextEntry{section: "In progress", task: t, blocks: blocks[t.ID]})
+		case len(blocks[t.ID]) > 0:
+			blockers = append(blockers, contextEntry{section: "Blocking other tasks", task: t, blocks: blocks[t.ID]})
+		default:
+			todo = append(todo, contextEntry{section: "Up next", task: t})
+		}
+	}
+	entries := append(append(doing, blockers...), todo...)
+
+	ctx := &BoardContext{Board: boardName, Included: []int{}}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Board: %s\n", boardName)
+	if len(entries) == 0 {
+		b.WriteString("\nNo open tasks.\n")
+	}
+
+	// Reserve room for the note about omitted tasks
+	budget := maxTokens
+	if budget > 0 {
+		budget -= EstimateTokens(omittedNote(len(entries)))
+	}
+
+	section := ""
+	for i, e := range entries {
+		heading := ""
+		if e.section != section {
+			heading = fmt.Sprintf("\n## %s\n", e.section)
+		}
+
+		text := heading + formatContextEntry(e, true)
+		if budget > 0 && EstimateTokens(b.String()+text) > budget {
+			text = heading + formatContextEntry(e, false)
+			if EstimateTokens(b.String()+text) > budget {
+				ctx.Omitted = len(entries) - i
+				break
+			}
+		}
+
+		b.WriteString(text)
+		section = e.section
+		ctx.Included = append(ctx.Included, e.task.ID)
+	}
+
+	if ctx.Omitted > 0 {
+		b.WriteString(omittedNote(ctx.Omitted))
+	}
+
+	ctx.Text = b.String()
+	ctx.Tokens = EstimateTokens(ctx.Text)
+	return ctx, nil
+}
+
+// formatContextEntry renders a task as a Markdown list item, optionally
+// followed by its (truncated) description
+func formatContextEntry(e contextEntry, withDescription bool) string {
+	t := e.task
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "- #%d %s", t.ID, t.Title)
+
+	var details []string
+	if t.Priority > task.PriorityNone {
+		details = append(details, task.GetPriorityName(t.Priority)+" priority")
+	}
+	if t.Assignee != "" {
+		details = append(details, "assigned to "+t.Assignee)
+	}
+	details = append(details, t.Contexts...)
+	if len(details) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+	}
+	if len(e.blocks) > 0 {
+		ids := make([]string, len(e.blocks))
+		for i, id := range e.blocks {
+			ids[i] = fmt.Sprintf("#%d", id)
+		}
+		fmt.Fprintf(&b, " blocks %s", strings.Join(ids, ", "))
+	}
+	b.WriteString("\n")
+
+	description := strings.TrimSpace(t.Description)
+	if withDescription && description != "" {
+		if utf8.RuneCountInString(description) > contextDescriptionLimit {
+			description = string([]rune(description)[:contextDescriptionLimit]) + "..."
+		}
+		for _, line := range strings.Split(description, "\n") {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+	}
+
+	return b.String()
+}
+
+func omittedNote(n int) string {
+	return fmt.Sprintf("\n(%d more open tasks omitted to fit the token budget)\n", n)
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"abcdefgh", 2},
+		{"a b c", 4},
+		{"Fix the login form validation", 8},
+	}
+
+	for _, tt := range tests {
+		if got := EstimateTokens(tt.text); got != tt.want {
+			t.Errorf("EstimateTokens(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestContext(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	reportSystem := New(db.Conn())
+
+	create := func(title, priority string, status task.Status) *task.Task {
+		created, err := taskSystem.CreateWithPriority(1, title, strings.Repeat("Some details. ", 20), priority)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if err := taskSystem.UpdateStatus(created.ID, status); err != nil {
+			t.Fatalf("Failed to move task: %v", err)
+		}
+		return created
+	}
+
+	low := create("Polish docs", "low", task.StatusTodo)
+	high := create("Add search", "high", task.StatusTodo)
+	schema := create("Migrate schema", "low", task.StatusTodo)
+	doing := create("Fix login", "medium", task.StatusDoing)
+	create("Ship beta", "critical", task.StatusDone)
+
+	if err := taskSystem.LinkTasks(schema.ID, high.ID, task.LinkTypeBlocks); err != nil {
+		t.Fatalf("Failed to link tasks: %v", err)
+	}
+
+	full, err := reportSystem.Context("work", 1, 0)
+	if err != nil {
+		t.Fatalf("Failed to build context: %v", err)
+	}
+	want := []int{doing.ID, schema.ID, high.ID, low.ID}
+	if len(full.Included) != len(want) {
+		t.Fatalf("Expected tasks %v, got %v", want, full.Included)
+	}
+	for i := range want {
+		if full.Included[i] != want[i] {
+			t.Fatalf("Expected tasks %v, got %v", want, full.Included)
+		}
+	}
+	if !strings.Contains(full.Text, "blocks #2") || strings.Contains(full.Text, "Ship beta") {
+		t.Errorf("Unexpected context:\n%s", full.Text)
+	}
+
+	// Half the budget still fits every task once descriptions are dropped
+	budget := full.Tokens / 2
+	short, err := reportSystem.Context("work", 1, budget)
+	if err != nil {
+		t.Fatalf("Failed to build context: %v", err)
+	}
+	if short.Tokens > budget || short.Omitted != 0 {
+		t.Errorf("Expected all tasks in %d tokens, got %d tokens with %d omitted", budget, short.Tokens, short.Omitted)
+	}
+
+	budget = 40
+	cut, err := reportSystem.Context("work", 1, budget)
+	if err != nil {
+		t.Fatalf("Failed to build context: %v", err)
+	}
+	if cut.Tokens > budget {
+		t.Errorf("Context uses %d tokens, budget is %d", cut.Tokens, budget)
+	}
+	if cut.Omitted == 0 || len(cut.Included)+cut.Omitted != len(want) || cut.Included[0] != doing.ID {
+		t.Errorf("Expected the lowest ranked tasks to be omitted, got %v (%d omitted)", cut.Included, cut.Omitted)
+	}
+	if !strings.Contains(cut.Text, "omitted to fit the token budget") {
+		t.Errorf("Expected a note about omitted tasks:\n%s", cut.Text)
+	}
+}
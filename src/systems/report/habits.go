@@ -0,0 +1,155 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// HabitWindow is the number of recent periods shown for each habit
+const HabitWindow = 14
+
+// Habit summarizes how consistently a recurring task gets done
+type Habit struct {
+	TaskID     int             `json:"task_id"`
+	Title      string          `json:"title"`
+	Recurrence task.Recurrence `json:"recurrence"`
+	Streak     int             `json:"streak"`
+	BestStreak int             `json:"best_streak"`
+	Completed  int             `json:"completed"`
+	Periods    int             `json:"periods"`
+	Rate       float64         `json:"rate"`
+	Recent     []bool          `json:"recent"` // last HabitWindow periods, oldest first
+}
+
+// Habits reports streaks and completion rates for every recurring task on a
+// board. Periods are measured in now's location. The completion rate covers
+// the last HabitWindow periods, or fewer if the task is younger than that.
+func (s *System) Habits(boardID int, now time.Time) ([]Habit, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, title, recurrence, created_at FROM tasks
+		WHERE board_id = ? AND recurrence != '' AND deleted_at IS NULL
+		ORDER BY id ASC
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recurring tasks: %w", err)
+	}
+
+	var habits []Habit
+	var created []time.Time
+	for rows.Next() {
+		var h Habit
+		var createdAt time.Time
+		if err := rows.Scan(&h.TaskID, &h.Title, &h.Recurrence, &createdAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan recurring task: %w", err)
+		}
+		habits = append(habits, h)
+		created = append(created, createdAt)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recurring tasks: %w", err)
+	}
+
+	for i := range habits {
+		done, err := s.completionPeriods(habits[i].TaskID, habits[i].Recurrence, now.Location())
+		if err != nil {
+			return nil, err
+		}
+		habits[i].score(done, created[i].In(now.Location()), now)
+	}
+
+	return habits, nil
+}
+
+// completionPeriods returns the start of every period in which a task was
+// moved to done
+func (s *System) completionPeriods(taskID int, recurrence task.Recurrence, loc *time.Location) (map[time.Time]bool, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT created_at FROM task_events
+		WHERE task_id = ? AND event_type = 'status_changed' AND to_status = 'done'
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completions: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[time.Time]bool)
+	for rows.Next() {
+		var completedAt time.Time
+		if err := rows.Scan(&completedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan completion: %w", err)
+		}
+		done[recurrence.PeriodStart(completedAt.In(loc))] = true
+	}
+
+	return done, rows.Err()
+}
+
+// score fills in streaks and rates from the periods with a completion
+func (h *Habit) score(done map[time.Time]bool, created, now time.Time) {
+	r := h.Recurrence
+	current := r.PeriodStart(now)
+	first := r.PeriodStart(created)
+
+	// The current period still counts towards the streak until it is over
+	period := current
+	if !done[period] {
+		period = r.PreviousPeriod(period)
+	}
+	for done[period] {
+		h.Streak++
+		period = r.PreviousPeriod(period)
+	}
+
+	run := 0
+	for period := first; !period.After(current); period = r.NextPeriod(period) {
+		if done[period] {
+			run++
+			if run > h.BestStreak {
+				h.BestStreak = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	h.Recent = make([]bool, HabitWindow)
+	period = current
+	for i := HabitWindow - 1; i >= 0; i-- {
+		if period.Before(first) {
+			break
+		}
+		h.Periods++
+		if done[period] {
+			h.Recent[i] = true
+			h.Completed++
+		}
+		period = r.PreviousPeriod(period)
+	}
+
+	if h.Periods > 0 {
+		h.Rate = float64(h.Completed) / float64(h.Periods)
+	}
+}
+
+// Row draws the recent periods as filled and empty squares, with dots for
+// periods before the task existed
+func (h Habit) Row() string {
+	var b strings.Builder
+	for i, done := range h.Recent {
+		switch {
+		case i < len(h.Recent)-h.Periods:
+			b.WriteString("·")
+		case done:
+			b.WriteString("■")
+		default:
+			b.WriteString("□")
+		}
+	}
+	return b.String()
+}
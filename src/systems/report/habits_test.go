@@ -0,0 +1,91 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestHabits(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	reportSystem := New(db.Conn())
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	// Creates a daily habit ten days ago and completes it the given number
+	// of days before now
+	habit := func(title string, daysAgo ...int) {
+		created, err := taskSystem.Create(1, title, "")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if err := taskSystem.SetRecurrence(created.ID, task.RecurrenceDaily); err != nil {
+			t.Fatalf("Failed to set recurrence: %v", err)
+		}
+		_, err = db.Conn().Exec(`UPDATE tasks SET created_at = ? WHERE id = ?`,
+			now.AddDate(0, 0, -9).Format("2006-01-02 15:04:05"), created.ID)
+		if err != nil {
+			t.Fatalf("Failed to backdate task: %v", err)
+		}
+		for _, days := range daysAgo {
+			_, err := db.Conn().Exec(`INSERT INTO task_events (task_id, event_type, from_status, to_status, created_at) VALUES (?, 'status_changed', 'todo', 'done', ?)`,
+				created.ID, now.AddDate(0, 0, -days).Format("2006-01-02 15:04:05"))
+			if err != nil {
+				t.Fatalf("Failed to record completion: %v", err)
+			}
+		}
+	}
+
+	habit("Done today", 0, 1, 2, 5, 6, 7, 8)
+	habit("Pending today", 1, 2)
+	habit("Lapsed", 3)
+
+	// One-off tasks are not habits
+	if _, err := taskSystem.Create(1, "One-off", ""); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	habits, err := reportSystem.Habits(1, now)
+	if err != nil {
+		t.Fatalf("Habits() error = %v", err)
+	}
+	if len(habits) != 3 {
+		t.Fatalf("Expected 3 habits, got %d", len(habits))
+	}
+
+	tests := []struct {
+		title     string
+		streak    int
+		best      int
+		completed int
+		row       string
+	}{
+		{"Done today", 3, 4, 7, "····□■■■■□□■■■"},
+		{"Pending today", 2, 2, 2, "····□□□□□□□■■□"},
+		{"Lapsed", 0, 1, 1, "····□□□□□□■□□□"},
+	}
+	for i, tt := range tests {
+		h := habits[i]
+		if h.Title != tt.title {
+			t.Fatalf("habits[%d] = %q, want %q", i, h.Title, tt.title)
+		}
+		if h.Streak != tt.streak || h.BestStreak != tt.best || h.Completed != tt.completed {
+			t.Errorf("%s: streak=%d best=%d completed=%d, want %d %d %d",
+				tt.title, h.Streak, h.BestStreak, h.Completed, tt.streak, tt.best, tt.completed)
+		}
+		if h.Periods != 10 {
+			t.Errorf("%s: periods = %d, want 10", tt.title, h.Periods)
+		}
+		if h.Row() != tt.row {
+			t.Errorf("%s: row = %s, want %s", tt.title, h.Row(), tt.row)
+		}
+	}
+}
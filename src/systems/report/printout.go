@@ -0,0 +1,229 @@
+package report
+
+import (
+	"database/sql"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+const (
+	// HighlightDays is how far back the printout looks for finished tasks
+	HighlightDays = 7
+	// UpcomingDays is how far ahead the printout looks for due dates
+	UpcomingDays = 14
+
+	// printoutItems caps each list so the printout fits on one page
+	printoutItems = 8
+)
+
+// PrintoutColumn is one column of the board as the printout shows it
+type PrintoutColumn struct {
+	Status task.Status  `json:"status"`
+	Count  int          `json:"count"`
+	Points int          `json:"points"`
+	Tasks  []*task.Task `json:"tasks"` // highest priority first
+}
+
+// Printout is a dated one-page summary of a board for a wall or a weekly
+// email: its columns, what got done lately, what is stuck and what is due
+type Printout struct {
+	Board       string           `json:"board"`
+	Description string           `json:"description,omitempty"`
+	Date        time.Time        `json:"date"`
+	Columns     []PrintoutColumn `json:"columns"`
+	// Highlights are the tasks finished in the last HighlightDays days
+	Highlights []*task.Task `json:"highlights"`
+	Blocked    []*task.Task `json:"blocked"`
+	// Upcoming are the unfinished tasks overdue or due within
+	// UpcomingDays days, soonest first
+	Upcoming []*task.Task `json:"upcoming"`
+}
+
+// Printout collects the summary of a board as of now
+func (s *System) Printout(boardName string, boardID int, now time.Time) (*Printout, error) {
+	var description sql.NullString
+	err := s.db.QueryRowContext(s.ctx, `SELECT description FROM boards WHERE id = ?`, boardID).Scan(&description)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to read board: %w", err)
+	}
+
+	doneIDs, err := s.doneSince(boardID, now.AddDate(0, 0, -HighlightDays))
+	if err != nil {
+		return nil, err
+	}
+	tasks, err := task.New(s.db).List(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Printout{
+		Board:       boardName,
+		Description: description.String,
+		Date:        now,
+		Highlights:  []*task.Task{},
+		Blocked:     []*task.Task{},
+		Upcoming:    []*task.Task{},
+	}
+
+	byID := make(map[int]*task.Task, len(tasks))
+	columns := make(map[task.Status]*PrintoutColumn)
+	for _, status := range []task.Status{task.StatusTodo, task.StatusDoing, task.StatusDone} {
+		p.Columns = append(p.Columns, PrintoutColumn{Status: status, Tasks: []*task.Task{}})
+	}
+	for i := range p.Columns {
+		columns[p.Columns[i].Status] = &p.Columns[i]
+	}
+
+	// List orders by priority, highest first
+	for _, t := range tasks {
+		byID[t.ID] = t
+		if column := columns[t.Status]; column != nil {
+			column.Count++
+			column.Points += t.Estimate
+			column.Tasks = append(column.Tasks, t)
+		}
+		if t.IsBlocked() {
+			p.Blocked = append(p.Blocked, t)
+		}
+		if t.Status != task.StatusDone && t.DueAt != nil && t.DueAt.Before(now.AddDate(0, 0, UpcomingDays)) {
+			p.Upcoming = append(p.Upcoming, t)
+		}
+	}
+	sort.SliceStable(p.Upcoming, func(i, j int) bool {
+		return p.Upcoming[i].DueAt.Before(*p.Upcoming[j].DueAt)
+	})
+
+	// Latest first: the most recent wins are the ones worth reading
+	for i := len(doneIDs) - 1; i >= 0; i-- {
+		if t := byID[doneIDs[i]]; t != nil {
+			p.Highlights = append(p.Highlights, t)
+		}
+	}
+
+	return p, nil
+}
+
+// printoutStyle lays the page out as a grid of boxes that fits one sheet
+// when printed
+const printoutStyle = `
+@page { size: A4; margin: 12mm; }
+* { box-sizing: border-box; }
+body { font: 10pt/1.35 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #111; margin: 0 auto; max-width: 190mm; padding: 8mm 0; }
+header { display: flex; justify-content: space-between; align-items: baseline; border-bottom: 2px solid #111; padding-bottom: 2mm; margin-bottom: 4mm; }
+h1 { font-size: 18pt; margin: 0; }
+header p { margin: 1mm 0 0; color: #555; }
+.date { font-size: 11pt; white-space: nowrap; }
+.grid { display: grid; grid-template-columns: repeat(3, 1fr); gap: 4mm; margin-bottom: 4mm; }
+section { border: 1px solid #bbb; border-radius: 2mm; padding: 2.5mm 3mm; break-inside: avoid; }
+h2 { font-size: 11pt; margin: 0 0 1.5mm; display: flex; justify-content: space-between; }
+h2 small { font-weight: normal; color: #555; }
+ul { list-style: none; margin: 0; padding: 0; }
+li { padding: 0.8mm 0; border-top: 1px dotted #ddd; }
+li:first-child { border-top: none; }
+.id { color: #777; font-variant-numeric: tabular-nums; }
+.meta { color: #555; font-size: 8.5pt; }
+.overdue { color: #b00020; font-weight: bold; }
+.empty, .more { color: #777; font-style: italic; }
+footer { color: #777; font-size: 8pt; text-align: right; }
+`
+
+// HTML renders the printout as a self-contained page; print it to paper or
+// to PDF from any browser
+func (p *Printout) HTML() string {
+	var b strings.Builder
+	esc := html.EscapeString
+
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s — %s</title>\n", esc(p.Board), p.Date.Format("2006-01-02"))
+	fmt.Fprintf(&b, "<style>%s</style>\n</head>\n<body>\n", printoutStyle)
+
+	b.WriteString("<header>\n<div>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", esc(p.Board))
+	if p.Description != "" {
+		fmt.Fprintf(&b, "<p>%s</p>\n", esc(p.Description))
+	}
+	fmt.Fprintf(&b, "</div>\n<div class=\"date\">%s</div>\n</header>\n", p.Date.Format("Monday, 2 January 2006"))
+
+	b.WriteString("<div class=\"grid\">\n")
+	for _, column := range p.Columns {
+		summary := fmt.Sprintf("%d tasks", column.Count)
+		if column.Points > 0 {
+			summary += fmt.Sprintf(", %d pts", column.Points)
+		}
+		tasks := column.Tasks
+		if column.Status == task.StatusDone {
+			// Finished work is covered by the highlights
+			tasks = nil
+		}
+		p.writeSection(&b, strings.ToUpper(string(column.Status)), summary, tasks, "nothing here")
+	}
+	b.WriteString("</div>\n<div class=\"grid\">\n")
+	p.writeSection(&b, "Highlights", fmt.Sprintf("done in the last %d days", HighlightDays), p.Highlights, "nothing finished")
+	p.writeSection(&b, "Blocked", fmt.Sprintf("%d tasks", len(p.Blocked)), p.Blocked, "nothing blocked")
+	p.writeSection(&b, "Upcoming", fmt.Sprintf("due within %d days", UpcomingDays), p.Upcoming, "nothing due")
+	b.WriteString("</div>\n")
+
+	fmt.Fprintf(&b, "<footer>cainban board report, %s</footer>\n</body>\n</html>\n", p.Date.Format("2006-01-02 15:04"))
+	return b.String()
+}
+
+// writeSection renders a box listing up to printoutItems tasks
+func (p *Printout) writeSection(b *strings.Builder, title, summary string, tasks []*task.Task, empty string) {
+	esc := html.EscapeString
+	fmt.Fprintf(b, "<section>\n<h2>%s <small>%s</small></h2>\n", esc(title), esc(summary))
+	if tasks == nil {
+		b.WriteString("</section>\n")
+		return
+	}
+
+	b.WriteString("<ul>\n")
+	for i, t := range tasks {
+		if i == printoutItems {
+			fmt.Fprintf(b, "<li class=\"more\">and %d more</li>\n", len(tasks)-i)
+			break
+		}
+		id := fmt.Sprintf("#%d", t.ID)
+		if t.Ref != "" {
+			id = t.Ref
+		}
+		fmt.Fprintf(b, "<li><span class=\"id\">%s</span> %s%s</li>\n", esc(id), esc(t.Title), p.details(t))
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintf(b, "<li class=\"empty\">%s</li>\n", esc(empty))
+	}
+	b.WriteString("</ul>\n</section>\n")
+}
+
+// details renders priority, owner, due date and blockers of a task
+func (p *Printout) details(t *task.Task) string {
+	var details []string
+	if t.Priority > 0 {
+		details = append(details, task.GetPriorityName(t.Priority))
+	}
+	if t.Assignee != "" {
+		details = append(details, "@"+html.EscapeString(t.Assignee))
+	}
+	if t.DueAt != nil && t.Status != task.StatusDone {
+		due := "due " + t.DueAt.Local().Format("Mon Jan 2")
+		if t.IsOverdue(p.Date) {
+			due = `<span class="overdue">overdue since ` + t.DueAt.Local().Format("Mon Jan 2") + `</span>`
+		}
+		details = append(details, due)
+	}
+	if t.IsBlocked() {
+		ids := make([]string, len(t.BlockedBy))
+		for i, id := range t.BlockedBy {
+			ids[i] = fmt.Sprintf("#%d", id)
+		}
+		details = append(details, "waiting on "+strings.Join(ids, ", "))
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return ` <span class="meta">` + strings.Join(details, " · ") + `</span>`
+}
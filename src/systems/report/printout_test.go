@@ -0,0 +1,117 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestPrintout(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+	if err := db.SetBoardDescription("Launch <the> thing"); err != nil {
+		t.Fatalf("SetBoardDescription: %v", err)
+	}
+
+	taskSystem := task.New(db.Conn())
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+
+	create := func(title string, status task.Status) *task.Task {
+		created, err := taskSystem.Create(1, title, "")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if status != task.StatusTodo {
+			if err := taskSystem.UpdateStatus(created.ID, status); err != nil {
+				t.Fatalf("Failed to move task: %v", err)
+			}
+		}
+		return created
+	}
+	due := func(id int, at time.Time) {
+		if err := taskSystem.SetDue(id, &at); err != nil {
+			t.Fatalf("SetDue: %v", err)
+		}
+	}
+
+	shipped := create("Ship the beta", task.StatusDone)
+	old := create("Old news", task.StatusDone)
+	_, err = db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id = ? AND to_status = 'done'`,
+		now.AddDate(0, 0, -HighlightDays-1).Format("2006-01-02 15:04:05"), old.ID)
+	if err != nil {
+		t.Fatalf("Failed to backdate event: %v", err)
+	}
+	_, err = db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id = ? AND to_status = 'done'`,
+		now.Add(-time.Hour).Format("2006-01-02 15:04:05"), shipped.ID)
+	if err != nil {
+		t.Fatalf("Failed to backdate event: %v", err)
+	}
+
+	docs := create("Write <docs>", task.StatusDoing)
+	blocker := create("Sign the contract", task.StatusTodo)
+	if err := taskSystem.LinkTasks(blocker.ID, docs.ID, task.LinkTypeBlocks); err != nil {
+		t.Fatalf("LinkTasks: %v", err)
+	}
+	due(blocker.ID, now.AddDate(0, 0, -1))
+	later := create("Plan the launch party", task.StatusTodo)
+	due(later.ID, now.AddDate(0, 0, 3))
+	farOff := create("Annual review", task.StatusTodo)
+	due(farOff.ID, now.AddDate(0, 0, UpcomingDays+1))
+
+	p, err := New(db.Conn()).Printout("launch", 1, now)
+	if err != nil {
+		t.Fatalf("Printout: %v", err)
+	}
+
+	if p.Description != "Launch <the> thing" {
+		t.Errorf("description %q", p.Description)
+	}
+	counts := map[task.Status]int{}
+	for _, column := range p.Columns {
+		counts[column.Status] = column.Count
+	}
+	if counts[task.StatusTodo] != 3 || counts[task.StatusDoing] != 1 || counts[task.StatusDone] != 2 {
+		t.Errorf("column counts %v, want todo 3, doing 1, done 2", counts)
+	}
+	if titles(p.Highlights) != "Ship the beta" {
+		t.Errorf("highlights %q, want only the task done this week", titles(p.Highlights))
+	}
+	if titles(p.Blocked) != "Write <docs>" {
+		t.Errorf("blocked %q", titles(p.Blocked))
+	}
+	if titles(p.Upcoming) != "Sign the contract, Plan the launch party" {
+		t.Errorf("upcoming %q, want overdue first and nothing past %d days", titles(p.Upcoming), UpcomingDays)
+	}
+
+	page := p.HTML()
+	for _, want := range []string{
+		"<h1>launch</h1>",
+		"Launch &lt;the&gt; thing",
+		"Friday, 16 October 2026",
+		"Write &lt;docs&gt;",
+		"overdue since",
+		"waiting on #" + strconv.Itoa(blocker.ID),
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML is missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<docs>") {
+		t.Error("HTML does not escape task titles")
+	}
+}
+
+func titles(tasks []*task.Task) string {
+	names := make([]string, len(tasks))
+	for i, t := range tasks {
+		names[i] = t.Title
+	}
+	return strings.Join(names, ", ")
+}
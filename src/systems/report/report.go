@@ -0,0 +1,129 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// WeekVelocity summarizes the work completed in a single week
+type WeekVelocity struct {
+	WeekStart time.Time `json:"week_start"`
+	Points    int       `json:"points"`
+	Tasks     int       `json:"tasks"`
+}
+
+// System builds reports from the task audit trail
+type System struct {
+	db  *sql.DB
+	ctx context.Context
+}
+
+// New creates a new report system
+func New(db *sql.DB) *System {
+	return &System{db: db, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the system whose queries run under ctx, for
+// reports that should give up after a deadline
+func (s *System) WithContext(ctx context.Context) *System {
+	copy := *s
+	copy.ctx = ctx
+	return &copy
+}
+
+// Velocity returns the story points completed per week for the last n weeks,
+// oldest week first. A task counts towards the week of its most recent move
+// to done, and only while it is still done.
+func (s *System) Velocity(boardID, weeks int, now time.Time) ([]WeekVelocity, error) {
+	if weeks <= 0 {
+		return nil, fmt.Errorf("weeks must be positive")
+	}
+
+	currentWeek := WeekStart(now)
+	firstWeek := currentWeek.AddDate(0, 0, -7*(weeks-1))
+
+	result := make([]WeekVelocity, weeks)
+	for i := range result {
+		result[i].WeekStart = firstWeek.AddDate(0, 0, 7*i)
+	}
+
+	query := `
+		SELECT t.estimate, MAX(e.created_at)
+		FROM tasks t
+		JOIN task_events e ON e.task_id = t.id
+		WHERE t.board_id = ? AND t.status = 'done' AND t.deleted_at IS NULL
+			AND e.event_type = 'status_changed' AND e.to_status = 'done'
+		GROUP BY t.id
+	`
+
+	rows, err := s.db.QueryContext(s.ctx, query, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completed tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var estimate int
+		var completedAt string
+		if err := rows.Scan(&estimate, &completedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan completed task: %w", err)
+		}
+
+		completed, err := parseTimestamp(completedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		index := int(WeekStart(completed).Sub(firstWeek).Hours() / (24 * 7))
+		if index < 0 || index >= weeks {
+			continue
+		}
+		result[index].Points += estimate
+		result[index].Tasks++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating completed tasks: %w", err)
+	}
+
+	return result, nil
+}
+
+// AverageVelocity returns the mean points per week across the given weeks
+func AverageVelocity(weeks []WeekVelocity) float64 {
+	if len(weeks) == 0 {
+		return 0
+	}
+
+	total := 0
+	for _, w := range weeks {
+		total += w.Points
+	}
+	return float64(total) / float64(len(weeks))
+}
+
+// WeekStart returns midnight UTC on the Monday of the week containing t
+func WeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7 // Monday = 0
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// parseTimestamp parses the timestamps SQLite returns from aggregate queries,
+// which lose the DATETIME column type and come back as text
+func parseTimestamp(value string) (time.Time, error) {
+	layouts := []string{
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05Z",
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999-07:00",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", value)
+}
@@ -0,0 +1,93 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestWeekStart(t *testing.T) {
+	tests := []struct {
+		name string
+		in   time.Time
+		want time.Time
+	}{
+		{"monday", time.Date(2026, 10, 12, 15, 0, 0, 0, time.UTC), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+		{"wednesday", time.Date(2026, 10, 14, 9, 30, 0, 0, time.UTC), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+		{"sunday", time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC), time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeekStart(tt.in); !got.Equal(tt.want) {
+				t.Errorf("WeekStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVelocity(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	reportSystem := New(db.Conn())
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	complete := func(title string, points int, doneAt time.Time) {
+		created, err := taskSystem.Create(1, title, "")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if err := taskSystem.UpdateEstimate(created.ID, points); err != nil {
+			t.Fatalf("Failed to set estimate: %v", err)
+		}
+		if err := taskSystem.UpdateStatus(created.ID, task.StatusDone); err != nil {
+			t.Fatalf("Failed to complete task: %v", err)
+		}
+		_, err = db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id = ? AND to_status = 'done'`,
+			doneAt.Format("2006-01-02 15:04:05"), created.ID)
+		if err != nil {
+			t.Fatalf("Failed to backdate event: %v", err)
+		}
+	}
+
+	complete("This week", 3, now.AddDate(0, 0, -1))
+	complete("Also this week", 2, now)
+	complete("Last week", 5, now.AddDate(0, 0, -7))
+	complete("Too old", 8, now.AddDate(0, 0, -60))
+
+	// Unfinished work never counts
+	if _, err := taskSystem.Create(1, "Still todo", ""); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	weeks, err := reportSystem.Velocity(1, 4, now)
+	if err != nil {
+		t.Fatalf("Velocity() error = %v", err)
+	}
+
+	if len(weeks) != 4 {
+		t.Fatalf("Expected 4 weeks, got %d", len(weeks))
+	}
+
+	if weeks[3].Points != 5 || weeks[3].Tasks != 2 {
+		t.Errorf("Current week = %d pts / %d tasks, want 5 / 2", weeks[3].Points, weeks[3].Tasks)
+	}
+	if weeks[2].Points != 5 || weeks[2].Tasks != 1 {
+		t.Errorf("Previous week = %d pts / %d tasks, want 5 / 1", weeks[2].Points, weeks[2].Tasks)
+	}
+	if weeks[0].Points != 0 {
+		t.Errorf("Oldest week = %d pts, want 0", weeks[0].Points)
+	}
+
+	if avg := AverageVelocity(weeks); avg != 2.5 {
+		t.Errorf("AverageVelocity() = %v, want 2.5", avg)
+	}
+}
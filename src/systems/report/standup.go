@@ -0,0 +1,178 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// Standup is what a board has to say at a daily standup: what got done
+// since a point in time, what is in progress and what is stuck
+type Standup struct {
+	Board   string       `json:"board"`
+	Since   time.Time    `json:"since"`
+	Done    []*task.Task `json:"done"`
+	Doing   []*task.Task `json:"doing"`
+	Blocked []*task.Task `json:"blocked"`
+}
+
+// PreviousWorkday returns local midnight at the start of the last working
+// day before now: yesterday, or Friday when now is a Monday or a weekend
+func PreviousWorkday(now time.Time) time.Time {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day = day.AddDate(0, 0, -1)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// Standup collects the tasks moved to done since the given time (and still
+// done), the tasks in progress and the open tasks waiting on unfinished
+// blockers. Tasks done since are ordered by when they were finished.
+func (s *System) Standup(boardName string, boardID int, since time.Time) (*Standup, error) {
+	doneIDs, err := s.doneSince(boardID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := task.New(s.db).List(boardID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*task.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+
+	standup := &Standup{
+		Board:   boardName,
+		Since:   since,
+		Done:    []*task.Task{},
+		Doing:   []*task.Task{},
+		Blocked: []*task.Task{},
+	}
+	for _, id := range doneIDs {
+		if t := byID[id]; t != nil {
+			standup.Done = append(standup.Done, t)
+		}
+	}
+	// List orders by priority, highest first
+	for _, t := range tasks {
+		if t.Status == task.StatusDoing {
+			standup.Doing = append(standup.Doing, t)
+		}
+		if t.IsBlocked() {
+			standup.Blocked = append(standup.Blocked, t)
+		}
+	}
+
+	return standup, nil
+}
+
+// doneSince returns the tasks of a board whose latest move to done was at
+// or after since and that are still done, in the order they were finished
+func (s *System) doneSince(boardID int, since time.Time) ([]int, error) {
+	query := `
+		SELECT e.task_id, MAX(e.created_at) AS done_at
+		FROM task_events e
+		JOIN tasks t ON t.id = e.task_id
+		WHERE t.board_id = ? AND t.status = 'done' AND t.deleted_at IS NULL
+			AND e.event_type = 'status_changed' AND e.to_status = 'done'
+		GROUP BY e.task_id
+		HAVING done_at >= ?
+		ORDER BY done_at, e.task_id
+	`
+
+	rows, err := s.db.QueryContext(s.ctx, query, boardID, since.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completed tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		var doneAt string
+		if err := rows.Scan(&id, &doneAt); err != nil {
+			return nil, fmt.Errorf("failed to scan completed task: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating completed tasks: %w", err)
+	}
+	return ids, nil
+}
+
+// Text renders the standup for a terminal
+func (st *Standup) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Standup for board '%s'\n", st.Board)
+	st.write(&b, false)
+	return b.String()
+}
+
+// Markdown renders the standup as Markdown for pasting into chat
+func (st *Standup) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Standup: %s**\n", st.Board)
+	st.write(&b, true)
+	return b.String()
+}
+
+// write renders the three sections, as Markdown or plain text
+func (st *Standup) write(b *strings.Builder, markdown bool) {
+	sections := []struct {
+		title string
+		tasks []*task.Task
+		empty string
+	}{
+		{"Done since " + st.Since.Local().Format("Mon Jan 2 15:04"), st.Done, "nothing finished"},
+		{"In progress", st.Doing, "nothing in progress"},
+		{"Blocked", st.Blocked, "nothing blocked"},
+	}
+
+	for _, section := range sections {
+		if markdown {
+			fmt.Fprintf(b, "\n**%s**\n", section.title)
+		} else {
+			fmt.Fprintf(b, "\n%s:\n", section.title)
+		}
+		for _, t := range section.tasks {
+			if markdown {
+				fmt.Fprintf(b, "- #%d %s%s\n", t.ID, t.Title, standupDetails(t))
+			} else {
+				fmt.Fprintf(b, "  #%d %s%s\n", t.ID, t.Title, standupDetails(t))
+			}
+		}
+		if len(section.tasks) == 0 {
+			if markdown {
+				fmt.Fprintf(b, "- _%s_\n", section.empty)
+			} else {
+				fmt.Fprintf(b, "  (%s)\n", section.empty)
+			}
+		}
+	}
+}
+
+// standupDetails renders who owns a task and what blocks it
+func standupDetails(t *task.Task) string {
+	var details []string
+	if t.Assignee != "" {
+		details = append(details, "@"+t.Assignee)
+	}
+	if t.IsBlocked() {
+		ids := make([]string, len(t.BlockedBy))
+		for i, id := range t.BlockedBy {
+			ids[i] = fmt.Sprintf("#%d", id)
+		}
+		details = append(details, "waiting on "+strings.Join(ids, ", "))
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(details, "; ") + ")"
+}
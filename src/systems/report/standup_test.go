@@ -0,0 +1,111 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestPreviousWorkday(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want int // day of October 2026
+	}{
+		{"wednesday", time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), 13},
+		{"monday", time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC), 16},
+		{"sunday", time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC), 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := time.Date(2026, 10, tt.want, 0, 0, 0, 0, time.UTC)
+			if got := PreviousWorkday(tt.now); !got.Equal(want) {
+				t.Errorf("PreviousWorkday() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestStandup(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	reportSystem := New(db.Conn())
+
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	since := PreviousWorkday(now)
+
+	create := func(title string, status task.Status, doneAt time.Time) *task.Task {
+		created, err := taskSystem.Create(1, title, "")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if status != task.StatusTodo {
+			if err := taskSystem.UpdateStatus(created.ID, status); err != nil {
+				t.Fatalf("Failed to move task: %v", err)
+			}
+		}
+		if status == task.StatusDone {
+			_, err = db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id = ? AND to_status = 'done'`,
+				doneAt.Format("2006-01-02 15:04:05"), created.ID)
+			if err != nil {
+				t.Fatalf("Failed to backdate event: %v", err)
+			}
+		}
+		return created
+	}
+
+	create("Shipped late", task.StatusDone, now.Add(-2*time.Hour))
+	create("Shipped early", task.StatusDone, now.Add(-20*time.Hour))
+	create("Shipped last week", task.StatusDone, now.AddDate(0, 0, -7))
+	doing := create("Writing docs", task.StatusDoing, time.Time{})
+	blocked := create("Release", task.StatusTodo, time.Time{})
+	if err := taskSystem.LinkTasks(doing.ID, blocked.ID, task.LinkTypeBlocks); err != nil {
+		t.Fatalf("Failed to link tasks: %v", err)
+	}
+	if _, err := taskSystem.Assign(doing.ID, "alice"); err != nil {
+		t.Fatalf("Failed to assign task: %v", err)
+	}
+
+	standup, err := reportSystem.Standup("default", 1, since)
+	if err != nil {
+		t.Fatalf("Standup() error = %v", err)
+	}
+
+	if len(standup.Done) != 2 || standup.Done[0].Title != "Shipped early" || standup.Done[1].Title != "Shipped late" {
+		t.Errorf("Expected the two tasks done since yesterday in order, got %v", standup.Done)
+	}
+	if len(standup.Doing) != 1 || standup.Doing[0].ID != doing.ID {
+		t.Errorf("Expected the task in progress, got %v", standup.Doing)
+	}
+	if len(standup.Blocked) != 1 || standup.Blocked[0].ID != blocked.ID {
+		t.Errorf("Expected the blocked task, got %v", standup.Blocked)
+	}
+
+	md := standup.Markdown()
+	for _, want := range []string{"**In progress**", "- #4 Writing docs (@alice)", "- #5 Release (waiting on #4)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() missing %q:\n%s", want, md)
+		}
+	}
+	if text := standup.Text(); !strings.Contains(text, "Blocked:\n  #5 Release") {
+		t.Errorf("Unexpected text:\n%s", text)
+	}
+
+	// Nothing finished since now
+	standup, err = reportSystem.Standup("default", 1, now)
+	if err != nil {
+		t.Fatalf("Standup() error = %v", err)
+	}
+	if len(standup.Done) != 0 || !strings.Contains(standup.Text(), "(nothing finished)") {
+		t.Errorf("Expected nothing done, got:\n%s", standup.Text())
+	}
+}
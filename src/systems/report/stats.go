@@ -0,0 +1,213 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FlowPoint is one sample of a cumulative flow diagram
+type FlowPoint struct {
+	At     time.Time      `json:"at"`
+	Counts map[string]int `json:"counts"`
+}
+
+// BoardStats summarizes the flow of work through a board
+type BoardStats struct {
+	Counts           map[string]int `json:"counts"`
+	CompletedTasks   int            `json:"completed_tasks"`
+	AverageCycleTime time.Duration  `json:"average_cycle_time"`
+	Throughput       []WeekVelocity `json:"throughput"`
+	Flow             []FlowPoint    `json:"flow"`
+}
+
+// flowStatuses is the stacking order used by the cumulative flow diagram
+var flowStatuses = []string{"done", "doing", "todo"}
+
+// statusEvent is a status transition read from the audit trail
+type statusEvent struct {
+	taskID int
+	status string
+	at     time.Time
+}
+
+// Stats computes column counts, cycle time, weekly throughput and a weekly
+// cumulative flow for the last n weeks from the status-change history
+func (s *System) Stats(boardID, weeks int, now time.Time) (*BoardStats, error) {
+	throughput, err := s.Velocity(boardID, weeks, now)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &BoardStats{
+		Counts:     make(map[string]int),
+		Throughput: throughput,
+	}
+
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT status, COUNT(*) FROM tasks
+		WHERE board_id = ? AND deleted_at IS NULL
+		GROUP BY status
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan task count: %w", err)
+		}
+		stats.Counts[status] = count
+	}
+	rows.Close()
+
+	events, err := s.statusEvents(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.CompletedTasks, stats.AverageCycleTime = cycleTime(events)
+
+	// Sample the flow at the end of each week, with the last point at now
+	for _, week := range throughput {
+		at := week.WeekStart.AddDate(0, 0, 7)
+		if at.After(now) {
+			at = now
+		}
+		stats.Flow = append(stats.Flow, FlowPoint{At: at, Counts: statusCountsAt(events, at)})
+	}
+
+	return stats, nil
+}
+
+// statusEvents loads every creation and status change for live tasks on a
+// board in chronological order
+func (s *System) statusEvents(boardID int) ([]statusEvent, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT e.task_id, e.to_status, e.created_at
+		FROM task_events e
+		JOIN tasks t ON t.id = e.task_id
+		WHERE t.board_id = ? AND t.deleted_at IS NULL
+			AND e.event_type IN ('created', 'status_changed')
+		ORDER BY e.created_at ASC, e.id ASC
+	`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []statusEvent
+	for rows.Next() {
+		var event statusEvent
+		var at time.Time
+		if err := rows.Scan(&event.taskID, &event.status, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan task event: %w", err)
+		}
+		event.at = at.UTC()
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// cycleTime averages the time from creation to the latest move to done for
+// tasks that are currently done
+func cycleTime(events []statusEvent) (int, time.Duration) {
+	created := make(map[int]time.Time)
+	finished := make(map[int]time.Time)
+	current := make(map[int]string)
+
+	for _, e := range events {
+		if _, ok := created[e.taskID]; !ok {
+			created[e.taskID] = e.at
+		}
+		if e.status == "done" {
+			finished[e.taskID] = e.at
+		}
+		current[e.taskID] = e.status
+	}
+
+	var total time.Duration
+	count := 0
+	for id, status := range current {
+		if status != "done" {
+			continue
+		}
+		total += finished[id].Sub(created[id])
+		count++
+	}
+
+	if count == 0 {
+		return 0, 0
+	}
+	return count, total / time.Duration(count)
+}
+
+// statusCountsAt replays events to count tasks per status at a point in time
+func statusCountsAt(events []statusEvent, at time.Time) map[string]int {
+	current := make(map[int]string)
+	for _, e := range events {
+		if e.at.After(at) {
+			break
+		}
+		current[e.taskID] = e.status
+	}
+
+	counts := make(map[string]int)
+	for _, status := range current {
+		counts[status]++
+	}
+	return counts
+}
+
+// RenderFlow draws the cumulative flow as horizontal stacked bars, one row per
+// sample, scaled so the largest total fits in width characters
+func RenderFlow(flow []FlowPoint, width int) string {
+	symbols := map[string]string{"done": "█", "doing": "▓", "todo": "░"}
+
+	maxTotal := 0
+	for _, point := range flow {
+		total := 0
+		for _, status := range flowStatuses {
+			total += point.Counts[status]
+		}
+		if total > maxTotal {
+			maxTotal = total
+		}
+	}
+
+	var b strings.Builder
+	for _, point := range flow {
+		var bar strings.Builder
+		total := 0
+		for _, status := range flowStatuses {
+			count := point.Counts[status]
+			total += count
+			if maxTotal > 0 {
+				bar.WriteString(strings.Repeat(symbols[status], count*width/maxTotal))
+			}
+		}
+		fmt.Fprintf(&b, "  %s  %s %d\n", point.At.Format("2006-01-02"), bar.String(), total)
+	}
+	b.WriteString("  █ done  ▓ doing  ░ todo\n")
+
+	return b.String()
+}
+
+// FormatDuration renders a cycle time in days and hours
+func FormatDuration(d time.Duration) string {
+	if d < time.Minute {
+		return "<1m"
+	}
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days == 0 {
+		if hours == 0 {
+			return fmt.Sprintf("%dm", int(d.Minutes()))
+		}
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dd %dh", days, hours)
+}
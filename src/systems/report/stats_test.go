@@ -0,0 +1,97 @@
+package report
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestStats(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	reportSystem := New(db.Conn())
+
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	backdate := func(taskID int, toStatus string, at time.Time) {
+		_, err := db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id = ? AND to_status = ?`,
+			at.Format("2006-01-02 15:04:05"), taskID, toStatus)
+		if err != nil {
+			t.Fatalf("Failed to backdate event: %v", err)
+		}
+	}
+
+	// Finished task: created 10 days ago, done 2 days ago
+	finished, _ := taskSystem.Create(1, "Finished", "")
+	taskSystem.UpdateStatus(finished.ID, task.StatusDone)
+	backdate(finished.ID, "todo", now.AddDate(0, 0, -10))
+	backdate(finished.ID, "done", now.AddDate(0, 0, -2))
+
+	// In progress task created 2 days ago
+	inProgress, _ := taskSystem.Create(1, "In progress", "")
+	taskSystem.UpdateStatus(inProgress.ID, task.StatusDoing)
+	backdate(inProgress.ID, "todo", now.AddDate(0, 0, -2))
+	backdate(inProgress.ID, "doing", now.AddDate(0, 0, -1))
+
+	// Fresh todo
+	fresh, _ := taskSystem.Create(1, "Fresh", "")
+	backdate(fresh.ID, "todo", now)
+
+	stats, err := reportSystem.Stats(1, 2, now)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	if stats.Counts["todo"] != 1 || stats.Counts["doing"] != 1 || stats.Counts["done"] != 1 {
+		t.Errorf("Counts = %v, want one task per column", stats.Counts)
+	}
+
+	if stats.CompletedTasks != 1 || stats.AverageCycleTime != 8*24*time.Hour {
+		t.Errorf("Cycle time = %v over %d tasks, want 192h over 1", stats.AverageCycleTime, stats.CompletedTasks)
+	}
+
+	if len(stats.Flow) != 2 {
+		t.Fatalf("Expected 2 flow points, got %d", len(stats.Flow))
+	}
+
+	// End of last week: only the finished task existed, still in todo
+	previous := stats.Flow[0].Counts
+	if previous["todo"] != 1 || previous["done"] != 0 {
+		t.Errorf("Previous week flow = %v, want 1 todo", previous)
+	}
+
+	latest := stats.Flow[1].Counts
+	if latest["todo"] != 1 || latest["doing"] != 1 || latest["done"] != 1 {
+		t.Errorf("Latest flow = %v, want one task per column", latest)
+	}
+
+	rendered := RenderFlow(stats.Flow, 20)
+	if !strings.Contains(rendered, "2026-10-14") || !strings.Contains(rendered, "█ done") {
+		t.Errorf("RenderFlow() output missing expected content:\n%s", rendered)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want string
+	}{
+		{0, "<1m"},
+		{45 * time.Minute, "45m"},
+		{5 * time.Hour, "5h"},
+		{50 * time.Hour, "2d 2h"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.in); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
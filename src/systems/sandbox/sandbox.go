@@ -0,0 +1,532 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// A sandbox of the board stored at <name>.db lives next to it in two files:
+// <name>.sandbox.db, the copy that is worked on, and <name>.sandbox-base.db,
+// the board as it was when the sandbox started. Diffing the two tells what
+// the sandbox changed even when the board itself moved on in the meantime.
+const (
+	sandboxSuffix = ".sandbox.db"
+	baseSuffix    = ".sandbox-base.db"
+)
+
+// Path returns the sandbox copy of the board stored at dbPath
+func Path(dbPath string) string {
+	return strings.TrimSuffix(dbPath, ".db") + sandboxSuffix
+}
+
+// IsSandbox reports whether path is the sandbox copy of a board
+func IsSandbox(path string) bool {
+	return strings.HasSuffix(path, sandboxSuffix)
+}
+
+func basePath(dbPath string) string {
+	return strings.TrimSuffix(dbPath, ".db") + baseSuffix
+}
+
+// Active reports whether the board stored at dbPath has a sandbox
+func Active(dbPath string) bool {
+	_, err := os.Stat(Path(dbPath))
+	return err == nil
+}
+
+// Start forks the board stored at dbPath into a sandbox
+func Start(dbPath string) error {
+	if Active(dbPath) {
+		return fmt.Errorf("a sandbox is already active for this board")
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for _, path := range []string{basePath(dbPath), Path(dbPath)} {
+		// VACUUM INTO takes a consistent copy even with a WAL in use
+		if _, err := db.Conn().ExecContext(db.Context(), `VACUUM INTO ?`, path); err != nil {
+			discard(dbPath)
+			return fmt.Errorf("failed to copy board: %w", err)
+		}
+	}
+	return nil
+}
+
+// Discard drops the sandbox of the board stored at dbPath without applying it
+func Discard(dbPath string) error {
+	if !Active(dbPath) {
+		return fmt.Errorf("no sandbox is active for this board")
+	}
+	return discard(dbPath)
+}
+
+func discard(dbPath string) error {
+	var firstErr error
+	for _, path := range []string{Path(dbPath), basePath(dbPath)} {
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			if err := os.Remove(path + suffix); err != nil && !os.IsNotExist(err) && firstErr == nil {
+				firstErr = fmt.Errorf("failed to remove sandbox: %w", err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// ChangeKind says what happened to a task in the sandbox
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// FieldChange is one field of a task that differs from the base
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// TaskChange is a task the sandbox added, removed or changed. Task is the
+// sandbox version, or the base version of a removed task.
+type TaskChange struct {
+	Kind   ChangeKind    `json:"kind"`
+	Task   *task.Task    `json:"task"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// LinkChange is a link the sandbox added or removed
+type LinkChange struct {
+	Kind ChangeKind    `json:"kind"`
+	Link task.TaskLink `json:"link"`
+}
+
+// Diff is everything the sandbox changed since it started
+type Diff struct {
+	Tasks []TaskChange `json:"tasks"`
+	Links []LinkChange `json:"links"`
+}
+
+// Empty reports whether the sandbox changed nothing
+func (d *Diff) Empty() bool {
+	return len(d.Tasks) == 0 && len(d.Links) == 0
+}
+
+// field is a task attribute compared and applied by the sandbox
+type field struct {
+	name string
+	get  func(t *task.Task) string
+	set  func(s *task.System, t *task.Task, value string) error
+}
+
+var fields = []field{
+	{"title", func(t *task.Task) string { return t.Title }, func(s *task.System, t *task.Task, v string) error {
+		return s.Update(t.ID, v, t.Description)
+	}},
+	{"description", func(t *task.Task) string { return t.Description }, func(s *task.System, t *task.Task, v string) error {
+		return s.Update(t.ID, t.Title, v)
+	}},
+	{"status", func(t *task.Task) string { return string(t.Status) }, func(s *task.System, t *task.Task, v string) error {
+		return s.UpdateStatus(t.ID, task.Status(v))
+	}},
+	{"priority", func(t *task.Task) string { return task.GetPriorityName(t.Priority) }, func(s *task.System, t *task.Task, v string) error {
+		return s.UpdatePriority(t.ID, v)
+	}},
+	{"estimate", func(t *task.Task) string { return strconv.Itoa(t.Estimate) }, func(s *task.System, t *task.Task, v string) error {
+		points, _ := strconv.Atoi(v)
+		return s.UpdateEstimate(t.ID, points)
+	}},
+	{"assignee", func(t *task.Task) string { return t.Assignee }, func(s *task.System, t *task.Task, v string) error {
+		_, err := s.Assign(t.ID, v)
+		return err
+	}},
+	{"recurrence", func(t *task.Task) string { return string(t.Recurrence) }, func(s *task.System, t *task.Task, v string) error {
+		return s.SetRecurrence(t.ID, task.Recurrence(v))
+	}},
+	{"size", func(t *task.Task) string { return string(t.Size) }, func(s *task.System, t *task.Task, v string) error {
+		return s.SetSize(t.ID, task.Size(v))
+	}},
+	{"energy", func(t *task.Task) string { return string(t.Energy) }, func(s *task.System, t *task.Task, v string) error {
+		return s.SetEnergy(t.ID, task.Energy(v))
+	}},
+	{"due", func(t *task.Task) string {
+		if t.DueAt == nil {
+			return ""
+		}
+		return t.DueAt.UTC().Format(time.RFC3339)
+	}, func(s *task.System, t *task.Task, v string) error {
+		if v == "" {
+			return s.SetDue(t.ID, nil)
+		}
+		due, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return err
+		}
+		return s.SetDue(t.ID, &due)
+	}},
+	{"position", func(t *task.Task) string { return strconv.Itoa(t.Position) }, func(s *task.System, t *task.Task, v string) error {
+		position, _ := strconv.Atoi(v)
+		return s.SetPosition(t.ID, position)
+	}},
+	{"parent", func(t *task.Task) string {
+		if t.ParentID == nil {
+			return ""
+		}
+		return strconv.Itoa(*t.ParentID)
+	}, func(s *task.System, t *task.Task, v string) error {
+		if v == "" {
+			return s.SetParent(t.ID, nil)
+		}
+		parentID, _ := strconv.Atoi(v)
+		return s.SetParent(t.ID, &parentID)
+	}},
+	{"contexts", func(t *task.Task) string { return strings.Join(t.Contexts, " ") }, func(s *task.System, t *task.Task, v string) error {
+		want := strings.Fields(v)
+		for _, c := range t.Contexts {
+			if !contains(want, c) {
+				if err := s.RemoveContext(t.ID, c); err != nil {
+					return err
+				}
+			}
+		}
+		for _, c := range want {
+			if err := s.AddContext(t.ID, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}},
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// diffFields lists the fields that differ between two versions of a task
+func diffFields(from, to *task.Task) []FieldChange {
+	var changes []FieldChange
+	for _, f := range fields {
+		if a, b := f.get(from), f.get(to); a != b {
+			changes = append(changes, FieldChange{Field: f.name, From: a, To: b})
+		}
+	}
+	return changes
+}
+
+// snapshot is the tasks and links of one board database
+type snapshot struct {
+	tasks map[int]*task.Task
+	links []task.TaskLink
+}
+
+func readSnapshot(path string) (*snapshot, error) {
+	db, err := storage.New(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	tasks, err := taskSystem.List(1)
+	if err != nil {
+		return nil, err
+	}
+	links, err := taskSystem.ListLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &snapshot{tasks: make(map[int]*task.Task, len(tasks)), links: links}
+	for _, t := range tasks {
+		snap.tasks[t.ID] = t
+	}
+	return snap, nil
+}
+
+// linkKey identifies a link regardless of its row ID
+type linkKey struct {
+	from, to int
+	linkType task.LinkType
+}
+
+func keyOf(l task.TaskLink) linkKey {
+	return linkKey{l.FromTaskID, l.ToTaskID, l.LinkType}
+}
+
+// Compare diffs the sandbox of the board stored at dbPath against the board
+// as it was when the sandbox started
+func Compare(dbPath string) (*Diff, error) {
+	if !Active(dbPath) {
+		return nil, fmt.Errorf("no sandbox is active for this board")
+	}
+
+	base, err := readSnapshot(basePath(dbPath))
+	if err != nil {
+		return nil, err
+	}
+	work, err := readSnapshot(Path(dbPath))
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{}
+	for _, id := range sortedIDs(work.tasks, base.tasks) {
+		before, after := base.tasks[id], work.tasks[id]
+		switch {
+		case before == nil:
+			diff.Tasks = append(diff.Tasks, TaskChange{Kind: Added, Task: after})
+		case after == nil:
+			diff.Tasks = append(diff.Tasks, TaskChange{Kind: Removed, Task: before})
+		default:
+			if changes := diffFields(before, after); len(changes) > 0 {
+				diff.Tasks = append(diff.Tasks, TaskChange{Kind: Changed, Task: after, Fields: changes})
+			}
+		}
+	}
+
+	baseLinks := make(map[linkKey]bool)
+	for _, l := range base.links {
+		baseLinks[keyOf(l)] = true
+	}
+	workLinks := make(map[linkKey]bool)
+	for _, l := range work.links {
+		workLinks[keyOf(l)] = true
+		if !baseLinks[keyOf(l)] {
+			diff.Links = append(diff.Links, LinkChange{Kind: Added, Link: l})
+		}
+	}
+	for _, l := range base.links {
+		if !workLinks[keyOf(l)] {
+			diff.Links = append(diff.Links, LinkChange{Kind: Removed, Link: l})
+		}
+	}
+
+	return diff, nil
+}
+
+func sortedIDs(maps ...map[int]*task.Task) []int {
+	seen := make(map[int]bool)
+	var ids []int
+	for _, m := range maps {
+		for id := range m {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Result reports what Apply merged into the board
+type Result struct {
+	Applied []TaskChange `json:"applied"`
+	Links   []LinkChange `json:"links"`
+	// Created maps the sandbox IDs of added tasks to their IDs on the board
+	Created map[int]int `json:"created,omitempty"`
+	// Conflicts are changes skipped because the board changed the same
+	// thing since the sandbox started
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// Apply merges the sandbox of the board stored at dbPath back into the board
+// and ends the sandbox. With task IDs (as numbered in the sandbox), only
+// changes to those tasks and the links between them and unchanged tasks are
+// merged. A field the board itself changed since the sandbox started is a
+// conflict and keeps the board's value.
+func Apply(dbPath string, only []int) (*Result, error) {
+	diff, err := Compare(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	base, err := readSnapshot(basePath(dbPath))
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := storage.New(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	taskSystem := task.New(db.Conn())
+
+	selected := func(id int) bool {
+		return len(only) == 0 || containsID(only, id)
+	}
+
+	result := &Result{Created: make(map[int]int)}
+	changedTasks := make(map[int]bool)
+	for _, change := range diff.Tasks {
+		changedTasks[change.Task.ID] = true
+		if !selected(change.Task.ID) {
+			continue
+		}
+
+		applied, err := applyTask(taskSystem, base, change, result)
+		if err != nil {
+			return result, err
+		}
+		if applied {
+			result.Applied = append(result.Applied, change)
+		}
+	}
+
+	// Sandbox IDs of tasks that exist on the board under the same ID
+	boardID := func(id int) (int, bool) {
+		if created, ok := result.Created[id]; ok {
+			return created, true
+		}
+		if _, ok := base.tasks[id]; ok {
+			return id, true
+		}
+		return 0, false
+	}
+
+	// A link goes along with its tasks: both selected, or one selected and
+	// the other one unchanged
+	linkSelected := func(l task.TaskLink) bool {
+		from, to := selected(l.FromTaskID), selected(l.ToTaskID)
+		return from && to || from && !changedTasks[l.ToTaskID] || to && !changedTasks[l.FromTaskID]
+	}
+
+	for _, change := range diff.Links {
+		l := change.Link
+		if !linkSelected(l) {
+			continue
+		}
+		from, okFrom := boardID(l.FromTaskID)
+		to, okTo := boardID(l.ToTaskID)
+		if !okFrom || !okTo {
+			continue
+		}
+
+		if change.Kind == Added {
+			err = taskSystem.LinkTasks(from, to, l.LinkType)
+		} else {
+			err = taskSystem.UnlinkTasks(from, to, l.LinkType)
+		}
+		if err != nil {
+			result.Conflicts = append(result.Conflicts, fmt.Sprintf("link #%d %s #%d: %v", from, l.LinkType, to, err))
+			continue
+		}
+		result.Links = append(result.Links, change)
+	}
+
+	if err := discard(dbPath); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// applyTask merges one task change into the board. It reports whether
+// anything was applied.
+func applyTask(taskSystem *task.System, base *snapshot, change TaskChange, result *Result) (bool, error) {
+	t := change.Task
+
+	switch change.Kind {
+	case Added:
+		created, err := taskSystem.CreateWithPriority(1, t.Title, t.Description, t.Priority)
+		if err != nil {
+			return false, err
+		}
+		result.Created[t.ID] = created.ID
+		// The task keeps the hash it was shown with in the sandbox
+		if t.Hash != "" {
+			if err := taskSystem.SetHash(created.ID, t.Hash); err != nil {
+				return false, err
+			}
+		}
+		for _, f := range fields {
+			if value := f.get(t); value != f.get(created) {
+				if err := f.set(taskSystem, created, value); err != nil {
+					return false, err
+				}
+				if created, err = taskSystem.GetByID(created.ID); err != nil {
+					return false, err
+				}
+			}
+		}
+		return true, nil
+
+	case Removed:
+		current, err := taskSystem.GetByID(t.ID)
+		if err != nil || current.DeletedAt != nil {
+			// Already gone from the board
+			return false, nil
+		}
+		if len(diffFields(t, current)) > 0 {
+			result.Conflicts = append(result.Conflicts, fmt.Sprintf("#%d %s: not deleted, it was changed on the board", t.ID, t.Title))
+			return false, nil
+		}
+		return true, taskSystem.SoftDelete(t.ID)
+
+	default:
+		current, err := taskSystem.GetByID(t.ID)
+		if err != nil || current.DeletedAt != nil {
+			result.Conflicts = append(result.Conflicts, fmt.Sprintf("#%d %s: deleted on the board", t.ID, t.Title))
+			return false, nil
+		}
+
+		applied := false
+		before := base.tasks[t.ID]
+		for _, fc := range change.Fields {
+			f, ok := fieldByName(fc.Field)
+			if !ok {
+				continue
+			}
+			now := f.get(current)
+			if now == fc.To {
+				continue
+			}
+			if now != f.get(before) {
+				result.Conflicts = append(result.Conflicts, fmt.Sprintf("#%d %s: %s changed on the board to %q, kept instead of %q", t.ID, t.Title, fc.Field, now, fc.To))
+				continue
+			}
+			if err := f.set(taskSystem, current, fc.To); err != nil {
+				return applied, err
+			}
+			if current, err = taskSystem.GetByID(t.ID); err != nil {
+				return applied, err
+			}
+			applied = true
+		}
+		return applied, nil
+	}
+}
+
+func fieldByName(name string) (field, bool) {
+	for _, f := range fields {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
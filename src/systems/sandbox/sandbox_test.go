@@ -0,0 +1,171 @@
+package sandbox
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// withBoard runs fn against the board database at path
+func withBoard(t *testing.T, path string, fn func(taskSystem *task.System)) {
+	t.Helper()
+	db, err := storage.New(path)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", path, err)
+	}
+	defer db.Close()
+	fn(task.New(db.Conn()))
+}
+
+func TestSandbox(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "cainban.db")
+
+	withBoard(t, dbPath, func(taskSystem *task.System) {
+		for _, title := range []string{"Schema", "API", "Docs"} {
+			if _, err := taskSystem.Create(1, title, ""); err != nil {
+				t.Fatalf("Failed to create task: %v", err)
+			}
+		}
+	})
+
+	if err := Start(dbPath); err != nil {
+		t.Fatalf("Failed to start sandbox: %v", err)
+	}
+	if !Active(dbPath) {
+		t.Fatal("Expected sandbox to be active")
+	}
+	if err := Start(dbPath); err == nil {
+		t.Error("Expected error starting a second sandbox")
+	}
+
+	// The agent experiments in the sandbox...
+	withBoard(t, Path(dbPath), func(taskSystem *task.System) {
+		if err := taskSystem.UpdateStatus(1, task.StatusDoing); err != nil {
+			t.Fatalf("Failed to move task: %v", err)
+		}
+		if err := taskSystem.Update(2, "API", "REST endpoints"); err != nil {
+			t.Fatalf("Failed to update task: %v", err)
+		}
+		if err := taskSystem.SoftDelete(3); err != nil {
+			t.Fatalf("Failed to delete task: %v", err)
+		}
+		added, err := taskSystem.CreateWithPriority(1, "Rate limiting", "", "high")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if err := taskSystem.LinkTasks(added.ID, 2, task.LinkTypeBlocks); err != nil {
+			t.Fatalf("Failed to link tasks: %v", err)
+		}
+	})
+
+	// ...while the board gets a task and a conflicting edit of its own
+	withBoard(t, dbPath, func(taskSystem *task.System) {
+		if _, err := taskSystem.Create(1, "Hotfix", ""); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if err := taskSystem.Update(2, "API", "GraphQL"); err != nil {
+			t.Fatalf("Failed to update task: %v", err)
+		}
+	})
+
+	diff, err := Compare(dbPath)
+	if err != nil {
+		t.Fatalf("Failed to diff sandbox: %v", err)
+	}
+	kinds := make(map[int]ChangeKind)
+	for _, c := range diff.Tasks {
+		kinds[c.Task.ID] = c.Kind
+	}
+	if kinds[1] != Changed || kinds[2] != Changed || kinds[3] != Removed || kinds[4] != Added || len(kinds) != 4 {
+		t.Errorf("Unexpected task changes: %v", kinds)
+	}
+	if len(diff.Links) != 1 || diff.Links[0].Kind != Added {
+		t.Errorf("Unexpected link changes: %+v", diff.Links)
+	}
+
+	result, err := Apply(dbPath, nil)
+	if err != nil {
+		t.Fatalf("Failed to apply sandbox: %v", err)
+	}
+	if Active(dbPath) {
+		t.Error("Expected the sandbox to end after apply")
+	}
+	// Sandbox task #4 collides with the board's own #4
+	if result.Created[4] != 5 {
+		t.Errorf("Expected the added task to become #5, got %v", result.Created)
+	}
+	if len(result.Conflicts) != 1 || !strings.Contains(result.Conflicts[0], "description") {
+		t.Errorf("Expected a description conflict, got %v", result.Conflicts)
+	}
+
+	withBoard(t, dbPath, func(taskSystem *task.System) {
+		schema, _ := taskSystem.GetByID(1)
+		api, _ := taskSystem.GetByID(2)
+		hotfix, _ := taskSystem.GetByID(4)
+		added, _ := taskSystem.GetByID(5)
+
+		if schema.Status != task.StatusDoing {
+			t.Errorf("Schema status = %s, want doing", schema.Status)
+		}
+		if api.Description != "GraphQL" {
+			t.Errorf("Expected the board's description to win, got %q", api.Description)
+		}
+		if _, err := taskSystem.GetByID(3); err == nil {
+			t.Error("Expected Docs to be deleted")
+		}
+		if hotfix.Title != "Hotfix" || added.Title != "Rate limiting" || added.Priority != task.PriorityHigh {
+			t.Errorf("Unexpected tasks after apply: %+v, %+v", hotfix, added)
+		}
+
+		links, _ := taskSystem.GetTaskLinks(5)
+		if len(links) != 1 || links[0].ToTaskID != 2 {
+			t.Errorf("Expected the link to follow the new task ID, got %+v", links)
+		}
+	})
+}
+
+func TestApplySelected(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "cainban.db")
+
+	withBoard(t, dbPath, func(taskSystem *task.System) {
+		for _, title := range []string{"Keep me", "Change me"} {
+			if _, err := taskSystem.Create(1, title, ""); err != nil {
+				t.Fatalf("Failed to create task: %v", err)
+			}
+		}
+	})
+
+	if err := Start(dbPath); err != nil {
+		t.Fatalf("Failed to start sandbox: %v", err)
+	}
+	withBoard(t, Path(dbPath), func(taskSystem *task.System) {
+		for _, id := range []int{1, 2} {
+			if err := taskSystem.UpdateStatus(id, task.StatusDone); err != nil {
+				t.Fatalf("Failed to move task: %v", err)
+			}
+		}
+	})
+
+	result, err := Apply(dbPath, []int{2})
+	if err != nil {
+		t.Fatalf("Failed to apply sandbox: %v", err)
+	}
+	if len(result.Applied) != 1 || result.Applied[0].Task.ID != 2 {
+		t.Errorf("Expected only task 2 to be applied, got %+v", result.Applied)
+	}
+
+	withBoard(t, dbPath, func(taskSystem *task.System) {
+		keep, _ := taskSystem.GetByID(1)
+		change, _ := taskSystem.GetByID(2)
+		if keep.Status != task.StatusTodo || change.Status != task.StatusDone {
+			t.Errorf("Unexpected statuses: %s, %s", keep.Status, change.Status)
+		}
+	})
+
+	if err := Discard(dbPath); err == nil {
+		t.Error("Expected error discarding a sandbox that already ended")
+	}
+}
@@ -0,0 +1,181 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// KeyEnv names the environment variable holding the passphrase of the
+// encrypted secrets file, used where there is no keychain
+const KeyEnv = "CAINBAN_SECRET_KEY"
+
+// keyIterations is how many PBKDF2 rounds turn the passphrase into a key;
+// tests lower it
+var keyIterations = 600000
+
+// file keeps secrets in secrets.enc, encrypted with AES-GCM under a key
+// derived from the passphrase in KeyEnv
+type file struct {
+	path       string
+	passphrase string
+}
+
+// sealed is the content of the secrets file
+type sealed struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func newFile(dir string) (*file, error) {
+	passphrase := os.Getenv(KeyEnv)
+	if passphrase == "" {
+		return nil, fmt.Errorf("no keychain to keep secrets in: set %s to a passphrase to use an encrypted secrets file", KeyEnv)
+	}
+	return &file{path: filepath.Join(dir, "secrets.enc"), passphrase: passphrase}, nil
+}
+
+func (f *file) Backend() string {
+	return "encrypted file " + f.path
+}
+
+func (f *file) Get(name string) (string, error) {
+	secrets, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", storage.Errorf(storage.ErrNotFound, "secret '%s' not found", name)
+	}
+	return value, nil
+}
+
+func (f *file) Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return f.save(secrets)
+}
+
+func (f *file) Delete(name string) error {
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return storage.Errorf(storage.ErrNotFound, "secret '%s' not found", name)
+	}
+	delete(secrets, name)
+	return f.save(secrets)
+}
+
+func (f *file) List() ([]string, error) {
+	secrets, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// load decrypts the secrets file; a missing file holds no secrets
+func (f *file) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+
+	var s sealed
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %s is damaged", f.path)
+	}
+	gcm, err := f.cipher(s.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, s.Nonce, s.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secrets: wrong %s?", KeyEnv)
+	}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// save encrypts the secrets under a fresh salt and nonce and replaces the
+// secrets file with them
+func (f *file) save(secrets map[string]string) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+
+	s := sealed{Salt: make([]byte, 16)}
+	if _, err := rand.Read(s.Salt); err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+	gcm, err := f.cipher(s.Salt)
+	if err != nil {
+		return err
+	}
+	s.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(s.Nonce); err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+	s.Data = gcm.Seal(nil, s.Nonce, plain, nil)
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save secrets: %w", err)
+	}
+	return nil
+}
+
+// cipher returns the AES-GCM cipher of the passphrase with salt
+func (f *file) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, f.passphrase, salt, keyIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive secrets key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive secrets key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
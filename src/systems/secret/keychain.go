@@ -0,0 +1,197 @@
+package secret
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// keychain keeps secrets in the OS keychain through its command line tool:
+// security on macOS and secret-tool (the Secret Service, e.g. GNOME
+// Keyring) on Linux. The keychain cannot be asked for the names of
+// cainban's entries alone, so those are listed in a file next to the
+// config; the values never are.
+type keychain struct {
+	backend string
+	service string
+	index   string
+	run     func(stdin string, args ...string) (string, error)
+
+	getArgs    func(service, name string) []string
+	setArgs    func(service, name, value string) (args []string, stdin string)
+	deleteArgs func(service, name string) []string
+}
+
+// keychainFor returns the keychain of the given OS, or nil if it has none
+// or its tool is not installed
+func keychainFor(goos, dir, service string) *keychain {
+	k := &keychain{service: service, index: filepath.Join(dir, "secret-names")}
+
+	var program string
+	switch goos {
+	case "darwin":
+		program, k.backend = "security", "macOS keychain"
+		k.getArgs = func(service, name string) []string {
+			return []string{"find-generic-password", "-s", service, "-a", name, "-w"}
+		}
+		// security only takes the value as an argument
+		k.setArgs = func(service, name, value string) ([]string, string) {
+			return []string{"add-generic-password", "-U", "-s", service, "-a", name, "-w", value}, ""
+		}
+		k.deleteArgs = func(service, name string) []string {
+			return []string{"delete-generic-password", "-s", service, "-a", name}
+		}
+	case "linux":
+		program, k.backend = "secret-tool", "Secret Service keyring"
+		k.getArgs = func(service, name string) []string {
+			return []string{"lookup", "service", service, "account", name}
+		}
+		k.setArgs = func(service, name, value string) ([]string, string) {
+			return []string{"store", "--label", service + " " + name, "service", service, "account", name}, value
+		}
+		k.deleteArgs = func(service, name string) []string {
+			return []string{"clear", "service", service, "account", name}
+		}
+	default:
+		return nil
+	}
+
+	path, err := lookPath(program)
+	if err != nil {
+		return nil
+	}
+	k.run = func(stdin string, args ...string) (string, error) {
+		cmd := exec.Command(path, args...)
+		cmd.Stdin = strings.NewReader(stdin)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%s", msg)
+			}
+			return "", fmt.Errorf("%s: %w (set %s=%s to use the encrypted secrets file)", program, err, BackendEnv, BackendFile)
+		}
+		return string(out), nil
+	}
+	return k
+}
+
+func (k *keychain) Backend() string {
+	return k.backend
+}
+
+func (k *keychain) Get(name string) (string, error) {
+	if err := k.known(name); err != nil {
+		return "", err
+	}
+	out, err := k.run("", k.getArgs(k.service, name)...)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret '%s': %w", name, err)
+	}
+	return strings.TrimSuffix(out, "\n"), nil
+}
+
+func (k *keychain) Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	args, stdin := k.setArgs(k.service, name, value)
+	if _, err := k.run(stdin, args...); err != nil {
+		return fmt.Errorf("failed to store secret '%s': %w", name, err)
+	}
+
+	names, err := readNames(k.index)
+	if err != nil {
+		return err
+	}
+	return writeNames(k.index, append(names, name))
+}
+
+func (k *keychain) Delete(name string) error {
+	if err := k.known(name); err != nil {
+		return err
+	}
+	if _, err := k.run("", k.deleteArgs(k.service, name)...); err != nil {
+		return fmt.Errorf("failed to delete secret '%s': %w", name, err)
+	}
+
+	names, err := readNames(k.index)
+	if err != nil {
+		return err
+	}
+	var kept []string
+	for _, n := range names {
+		if n != name {
+			kept = append(kept, n)
+		}
+	}
+	return writeNames(k.index, kept)
+}
+
+func (k *keychain) List() ([]string, error) {
+	return readNames(k.index)
+}
+
+// known returns an ErrNotFound error unless cainban stored a secret name
+func (k *keychain) known(name string) error {
+	names, err := readNames(k.index)
+	if err != nil {
+		return err
+	}
+	for _, n := range names {
+		if n == name {
+			return nil
+		}
+	}
+	return storage.Errorf(storage.ErrNotFound, "secret '%s' not found", name)
+}
+
+// readNames reads the names of the secrets in the keychain, sorted
+func readNames(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret names: %w", err)
+	}
+	defer file.Close()
+
+	var names []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if name := strings.TrimSpace(scanner.Text()); name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, scanner.Err()
+}
+
+// writeNames saves the names of the secrets in the keychain, once each
+func writeNames(path string, names []string) error {
+	sort.Strings(names)
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 && names[i-1] == name {
+			continue
+		}
+		b.WriteString(name + "\n")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to save secret names: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("failed to save secret names: %w", err)
+	}
+	return nil
+}
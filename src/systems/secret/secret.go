@@ -0,0 +1,101 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// RefPrefix marks a config value as a reference to a secret, e.g.
+// handoff_webhook = "secret:slack-webhook", so that tokens and webhook URLs
+// need not be kept in plain text in config.toml
+const RefPrefix = "secret:"
+
+// BackendEnv names the environment variable choosing where secrets are
+// kept: "keychain" for the OS keychain, "file" for the encrypted secrets
+// file. Unset, the keychain is used when there is one.
+const BackendEnv = "CAINBAN_SECRETS"
+
+// Backends understood by BackendEnv
+const (
+	BackendKeychain = "keychain"
+	BackendFile     = "file"
+)
+
+// Store keeps named secrets
+type Store interface {
+	// Get returns a secret's value, or an ErrNotFound error
+	Get(name string) (string, error)
+	// Set stores a secret, replacing any earlier value
+	Set(name, value string) error
+	// Delete removes a secret, or returns an ErrNotFound error
+	Delete(name string) error
+	// List returns the names of the secrets, sorted
+	List() ([]string, error)
+	// Backend says where the secrets are kept
+	Backend() string
+}
+
+// namePattern is what a secret's name may look like
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ValidateName reports whether name can name a secret
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return storage.Errorf(storage.ErrInvalidInput, "invalid secret name '%s' (use letters, digits, '.', '-' or '_')", name)
+	}
+	return nil
+}
+
+// Open returns the secret store of a profile, whose files go in dir. The
+// profile ("" for the default one) keeps its keychain entries apart from
+// those of the other profiles.
+func Open(dir, profile string) (Store, error) {
+	service := "cainban"
+	if profile != "" {
+		service += ":" + profile
+	}
+
+	switch backend := os.Getenv(BackendEnv); backend {
+	case BackendFile:
+		return newFile(dir)
+	case BackendKeychain, "":
+		if store := keychainFor(runtime.GOOS, dir, service); store != nil {
+			return store, nil
+		}
+		if backend == BackendKeychain {
+			return nil, fmt.Errorf("no keychain found: cainban uses security on macOS and secret-tool on Linux")
+		}
+		return newFile(dir)
+	default:
+		return nil, fmt.Errorf("%s must be %q or %q, not %q", BackendEnv, BackendKeychain, BackendFile, backend)
+	}
+}
+
+// IsRef reports whether a config value refers to a secret
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefPrefix)
+}
+
+// Resolve returns a config value with a secret reference replaced by the
+// secret's value. Other values are returned as they are, without opening
+// the store.
+func Resolve(value string, open func() (Store, error)) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	store, err := open()
+	if err != nil {
+		return "", err
+	}
+	return store.Get(strings.TrimPrefix(value, RefPrefix))
+}
+
+// lookPath finds the programs the keychain is reached through; tests
+// replace it
+var lookPath = exec.LookPath
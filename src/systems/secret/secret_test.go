@@ -0,0 +1,174 @@
+package secret
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestFileStore(t *testing.T) {
+	defer func(n int) { keyIterations = n }(keyIterations)
+	keyIterations = 1000
+
+	dir := t.TempDir()
+	t.Setenv(BackendEnv, BackendFile)
+	t.Setenv(KeyEnv, "correct horse")
+
+	store, err := Open(dir, "")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if names, err := store.List(); err != nil || names != nil {
+		t.Errorf("Expected no secrets yet, got %v, %v", names, err)
+	}
+	if _, err := store.Get("github"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected a missing secret to be not found, got %v", err)
+	}
+
+	for name, value := range map[string]string{"github": "ghp_123", "slack-webhook": "https://hooks.slack.com/T0/B0/x"} {
+		if err := store.Set(name, value); err != nil {
+			t.Fatalf("Set(%q) error = %v", name, err)
+		}
+	}
+	if err := store.Set("github", "ghp_456"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := store.Set("../x", "y"); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("Expected an invalid name to be refused, got %v", err)
+	}
+
+	if value, err := store.Get("github"); err != nil || value != "ghp_456" {
+		t.Errorf("Get() = %q, %v, want the latest value", value, err)
+	}
+	if names, _ := store.List(); !reflect.DeepEqual(names, []string{"github", "slack-webhook"}) {
+		t.Errorf("List() = %v", names)
+	}
+
+	// Nothing is stored in plain text, nor readable by others
+	path := filepath.Join(dir, "secrets.enc")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "ghp_456") || strings.Contains(string(data), "github") {
+		t.Errorf("Expected the secrets file to be encrypted, got %s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the secrets file to be private, got %v", info.Mode())
+	}
+
+	t.Setenv(KeyEnv, "wrong")
+	other, err := Open(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Get("github"); err == nil {
+		t.Error("Expected the wrong passphrase to fail")
+	}
+
+	t.Setenv(KeyEnv, "correct horse")
+	if err := store.Delete("github"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete("github"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected deleting twice to be not found, got %v", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Setenv(BackendEnv, BackendFile)
+	t.Setenv(KeyEnv, "")
+	if _, err := Open(dir, ""); err == nil || !strings.Contains(err.Error(), KeyEnv) {
+		t.Errorf("Expected the file store to need %s, got %v", KeyEnv, err)
+	}
+
+	t.Setenv(BackendEnv, "vault")
+	if _, err := Open(dir, ""); err == nil {
+		t.Error("Expected an unknown backend to fail")
+	}
+
+	defer func(f func(string) (string, error)) { lookPath = f }(lookPath)
+	lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	t.Setenv(BackendEnv, BackendKeychain)
+	if _, err := Open(dir, ""); err == nil {
+		t.Error("Expected the keychain to be required when asked for")
+	}
+}
+
+func TestKeychain(t *testing.T) {
+	defer func(f func(string) (string, error)) { lookPath = f }(lookPath)
+	lookPath = func(name string) (string, error) { return "/usr/bin/" + name, nil }
+
+	k := keychainFor("linux", t.TempDir(), "cainban:work")
+	if k == nil {
+		t.Fatal("Expected a keychain on Linux")
+	}
+	if keychainFor("plan9", t.TempDir(), "cainban") != nil {
+		t.Error("Expected no keychain on plan9")
+	}
+
+	// A keychain of one entry per service and account
+	entries := map[string]string{}
+	var calls [][]string
+	k.run = func(stdin string, args ...string) (string, error) {
+		calls = append(calls, args)
+		key := strings.Join(args[len(args)-4:], " ")
+		switch args[0] {
+		case "store":
+			entries[key] = stdin
+		case "lookup":
+			return entries[key], nil
+		case "clear":
+			delete(entries, key)
+		}
+		return "", nil
+	}
+
+	if _, err := k.Get("github"); !errors.Is(err, storage.ErrNotFound) || len(calls) != 0 {
+		t.Errorf("Expected an unknown secret to be not found without asking the keychain, got %v", err)
+	}
+	if err := k.Set("github", "ghp_123"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if entries["service cainban:work account github"] != "ghp_123" {
+		t.Errorf("Expected the value to go to the keychain on stdin, got %v after %v", entries, calls)
+	}
+	if value, err := k.Get("github"); err != nil || value != "ghp_123" {
+		t.Errorf("Get() = %q, %v", value, err)
+	}
+	if names, _ := k.List(); !reflect.DeepEqual(names, []string{"github"}) {
+		t.Errorf("List() = %v", names)
+	}
+	if data, _ := os.ReadFile(k.index); strings.Contains(string(data), "ghp_123") {
+		t.Error("Expected the value to stay out of the names file")
+	}
+
+	if err := k.Delete("github"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if names, _ := k.List(); len(names) != 0 || len(entries) != 0 {
+		t.Errorf("Expected the secret to be gone, got %v and %v", names, entries)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	opened := false
+	open := func() (Store, error) {
+		opened = true
+		return nil, errors.New("no store")
+	}
+
+	if value, err := Resolve("https://example.com/hook", open); err != nil || value != "https://example.com/hook" || opened {
+		t.Errorf("Expected plain values as they are, got %q, %v (store opened: %v)", value, err, opened)
+	}
+	if _, err := Resolve("secret:slack", open); err == nil || !opened {
+		t.Error("Expected a reference to be looked up")
+	}
+}
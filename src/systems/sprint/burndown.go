@@ -0,0 +1,155 @@
+package sprint
+
+import (
+	"fmt"
+	"time"
+)
+
+// Burndown is the work left in a sprint at the end of each of its days so
+// far, against the straight line from all of it to none by the end date
+type Burndown struct {
+	Sprint *Sprint `json:"sprint"`
+	Unit   string  `json:"unit"` // "points" when the tasks are estimated, else "tasks"
+	Total  int     `json:"total"`
+	Days   []Day   `json:"days"`
+	// Unestimated counts the tasks left out of a burndown in points
+	Unestimated int `json:"unestimated,omitempty"`
+}
+
+// Day is the state of a sprint at the end of one of its days
+type Day struct {
+	Date      string  `json:"date"`
+	Remaining int     `json:"remaining"`
+	Ideal     float64 `json:"ideal"`
+}
+
+// sprintTask is a task planned for a sprint, with the statuses it went
+// through
+type sprintTask struct {
+	weight  int
+	status  string
+	changes []statusChange
+}
+
+type statusChange struct {
+	status string
+	at     time.Time
+}
+
+// statusAt returns the status of the task at a time, or "" if it did not
+// exist yet. Tasks from before the history was kept have their status now.
+func (t *sprintTask) statusAt(at time.Time) string {
+	if len(t.changes) == 0 {
+		return t.status
+	}
+	status := ""
+	for _, change := range t.changes {
+		if !change.at.Before(at) {
+			break
+		}
+		status = change.status
+	}
+	return status
+}
+
+// Burndown works out a sprint's burndown from the history of its tasks, up
+// to now's date or the end of the sprint. The work is counted in points
+// when any of the tasks is estimated, leaving out those that are not, and
+// in tasks otherwise. Tasks count from the day they were created, whenever
+// they joined the sprint.
+func (s *System) Burndown(id int, now time.Time) (*Burndown, error) {
+	sp, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	burndown := &Burndown{Sprint: sp, Unit: "tasks", Total: sp.Tasks, Days: []Day{}}
+	if sp.Points > 0 {
+		burndown.Unit, burndown.Total = "points", sp.Points
+	}
+
+	tasks, err := s.sprintTasks(id, burndown.Unit == "points")
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := time.ParseInLocation(DateLayout, sp.Start, now.Location())
+	if err != nil {
+		return nil, fmt.Errorf("invalid start of sprint %d: %w", id, err)
+	}
+	today := now.Format(DateLayout)
+	days := sp.Days()
+	for _, t := range tasks {
+		if t.weight == 0 {
+			burndown.Unestimated++
+		}
+	}
+	for i := 0; i < days; i++ {
+		date := start.AddDate(0, 0, i)
+		if date.Format(DateLayout) > today {
+			break
+		}
+		end := start.AddDate(0, 0, i+1)
+		day := Day{
+			Date:  date.Format(DateLayout),
+			Ideal: float64(burndown.Total) * float64(days-i-1) / float64(days),
+		}
+		for _, t := range tasks {
+			if status := t.statusAt(end); status != "" && status != "done" {
+				day.Remaining += t.weight
+			}
+		}
+		burndown.Days = append(burndown.Days, day)
+	}
+	return burndown, nil
+}
+
+// sprintTasks loads the tasks planned for a sprint with their history,
+// each weighing its estimate, or 1 when counting tasks
+func (s *System) sprintTasks(id int, byPoints bool) ([]*sprintTask, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, estimate, status FROM tasks WHERE sprint_id = ? AND deleted_at IS NULL
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sprint tasks: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[int]*sprintTask)
+	var tasks []*sprintTask
+	for rows.Next() {
+		var taskID int
+		t := &sprintTask{weight: 1}
+		if err := rows.Scan(&taskID, &t.weight, &t.status); err != nil {
+			return nil, fmt.Errorf("failed to scan sprint task: %w", err)
+		}
+		if !byPoints {
+			t.weight = 1
+		}
+		byID[taskID] = t
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	events, err := s.db.QueryContext(s.ctx, `
+		SELECT task_id, COALESCE(to_status, ''), created_at FROM task_events
+		WHERE task_id IN (SELECT id FROM tasks WHERE sprint_id = ? AND deleted_at IS NULL)
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sprint history: %w", err)
+	}
+	defer events.Close()
+	for events.Next() {
+		var taskID int
+		var change statusChange
+		if err := events.Scan(&taskID, &change.status, &change.at); err != nil {
+			return nil, fmt.Errorf("failed to scan sprint history: %w", err)
+		}
+		if t := byID[taskID]; t != nil && change.status != "" {
+			t.changes = append(t.changes, change)
+		}
+	}
+	return tasks, events.Err()
+}
@@ -0,0 +1,259 @@
+package sprint
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// DateLayout is how sprint dates are written and stored
+const DateLayout = "2006-01-02"
+
+// DefaultLength is the length of a sprint created without an end date
+const DefaultLength = 14 * 24 * time.Hour
+
+// Current is the reference Find takes for the sprint under way
+const Current = "current"
+
+// None stands for no sprint where a sprint is asked for, as when listing the
+// tasks planned for none, the backlog
+const None = "none"
+
+// Sprint is an iteration of a board: the tasks planned for it, to be done
+// from its start date to its end date, both included
+type Sprint struct {
+	ID      int    `json:"id"`
+	BoardID int    `json:"board_id"`
+	Name    string `json:"name"`
+	Start   string `json:"start"` // YYYY-MM-DD
+	End     string `json:"end"`
+	// Capacity is the points of estimated work the sprint can take, 0 for
+	// no limit
+	Capacity int `json:"capacity,omitempty"`
+
+	// The tasks planned for the sprint, and their estimates
+	Tasks      int `json:"tasks"`
+	DoneTasks  int `json:"done_tasks"`
+	Points     int `json:"points"`
+	DonePoints int `json:"done_points"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsCurrent reports whether the sprint is under way on now's date
+func (sp *Sprint) IsCurrent(now time.Time) bool {
+	today := now.Format(DateLayout)
+	return sp.Start <= today && today <= sp.End
+}
+
+// Days returns the length of the sprint in days
+func (sp *Sprint) Days() int {
+	start, _ := time.Parse(DateLayout, sp.Start)
+	end, _ := time.Parse(DateLayout, sp.End)
+	return int(end.Sub(start).Hours()/24) + 1
+}
+
+// CapacityWarning describes a sprint planned past its capacity
+type CapacityWarning struct {
+	Sprint   string `json:"sprint"`
+	Capacity int    `json:"capacity"`
+	Points   int    `json:"points"`
+}
+
+// String renders the warning for CLI and MCP output
+func (w *CapacityWarning) String() string {
+	return fmt.Sprintf("sprint \"%s\" is over capacity: %d of %d pts planned", w.Sprint, w.Points, w.Capacity)
+}
+
+// CheckCapacity returns a warning when the estimates of the tasks planned
+// for the sprint add up to more than its capacity, and nil otherwise
+func (sp *Sprint) CheckCapacity() *CapacityWarning {
+	if sp.Capacity == 0 || sp.Points <= sp.Capacity {
+		return nil
+	}
+	return &CapacityWarning{Sprint: sp.Name, Capacity: sp.Capacity, Points: sp.Points}
+}
+
+// System handles sprint operations
+type System struct {
+	db  *sql.DB
+	ctx context.Context
+}
+
+// New creates a new sprint system
+func New(db *sql.DB) *System {
+	return &System{db: db, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the system whose queries run under ctx
+func (s *System) WithContext(ctx context.Context) *System {
+	copy := *s
+	copy.ctx = ctx
+	return &copy
+}
+
+// Create adds a sprint to a board, from the date of start to the date of
+// end. Names are unique within a board, and cannot be a number, "current"
+// or "none", which stand for other things.
+func (s *System) Create(boardID int, name string, start, end time.Time) (*Sprint, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, storage.Errorf(storage.ErrInvalidInput, "sprint name cannot be empty")
+	}
+	if _, err := strconv.Atoi(name); err == nil || strings.EqualFold(name, Current) || strings.EqualFold(name, None) {
+		return nil, storage.Errorf(storage.ErrInvalidInput, "invalid sprint name '%s': it would be taken for a sprint ID, the current sprint or none", name)
+	}
+	sp := Sprint{BoardID: boardID, Name: name, Start: start.Format(DateLayout), End: end.Format(DateLayout)}
+	if sp.End < sp.Start {
+		return nil, storage.Errorf(storage.ErrInvalidInput, "sprint cannot end (%s) before it starts (%s)", sp.End, sp.Start)
+	}
+
+	var taken int
+	if err := s.db.QueryRowContext(s.ctx, `SELECT COUNT(*) FROM sprints WHERE board_id = ? AND name = ? COLLATE NOCASE`, boardID, name).Scan(&taken); err != nil {
+		return nil, fmt.Errorf("failed to check sprint name: %w", err)
+	}
+	if taken > 0 {
+		return nil, storage.Errorf(storage.ErrInvalidInput, "there is a sprint named '%s' already", name)
+	}
+
+	err := s.db.QueryRowContext(s.ctx, `
+		INSERT INTO sprints (board_id, name, start_date, end_date) VALUES (?, ?, ?, ?)
+		RETURNING id, created_at
+	`, boardID, sp.Name, sp.Start, sp.End).Scan(&sp.ID, &sp.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sprint: %w", err)
+	}
+	return &sp, nil
+}
+
+// SetCapacity sets the points of estimated work a sprint can take; 0
+// removes the limit. Planning past it is warned about, not refused.
+func (s *System) SetCapacity(id, points int) error {
+	if points < 0 {
+		return storage.Errorf(storage.ErrInvalidInput, "capacity cannot be negative")
+	}
+	result, err := s.db.ExecContext(s.ctx, `UPDATE sprints SET capacity = ? WHERE id = ?`, points, id)
+	if err != nil {
+		return fmt.Errorf("failed to set capacity: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to set capacity: %w", err)
+	} else if updated == 0 {
+		return storage.Errorf(storage.ErrNotFound, "sprint with id %d not found", id)
+	}
+	return nil
+}
+
+// Delete removes a sprint; its tasks stay on the board, unplanned
+func (s *System) Delete(id int) error {
+	tx, err := s.db.BeginTx(s.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(s.ctx, `DELETE FROM sprints WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	} else if deleted == 0 {
+		return storage.Errorf(storage.ErrNotFound, "sprint with id %d not found", id)
+	}
+	if _, err := tx.ExecContext(s.ctx, `UPDATE tasks SET sprint_id = NULL WHERE sprint_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete sprint: %w", err)
+	}
+	return tx.Commit()
+}
+
+// sprintColumns lists the columns read by scanSprint, in scan order
+const sprintColumns = `id, board_id, name, start_date, end_date, capacity, created_at,
+	(SELECT COUNT(*) FROM tasks WHERE sprint_id = sprints.id AND deleted_at IS NULL),
+	(SELECT COUNT(*) FROM tasks WHERE sprint_id = sprints.id AND deleted_at IS NULL AND status = 'done'),
+	(SELECT COALESCE(SUM(estimate), 0) FROM tasks WHERE sprint_id = sprints.id AND deleted_at IS NULL),
+	(SELECT COALESCE(SUM(estimate), 0) FROM tasks WHERE sprint_id = sprints.id AND deleted_at IS NULL AND status = 'done')`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSprint(row rowScanner) (*Sprint, error) {
+	var sp Sprint
+	err := row.Scan(&sp.ID, &sp.BoardID, &sp.Name, &sp.Start, &sp.End, &sp.Capacity, &sp.CreatedAt,
+		&sp.Tasks, &sp.DoneTasks, &sp.Points, &sp.DonePoints)
+	if err != nil {
+		return nil, err
+	}
+	return &sp, nil
+}
+
+// List returns the sprints of a board, earliest first
+func (s *System) List(boardID int) ([]*Sprint, error) {
+	rows, err := s.db.QueryContext(s.ctx, `SELECT `+sprintColumns+`
+		FROM sprints WHERE board_id = ? ORDER BY start_date, id`, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sprints: %w", err)
+	}
+	defer rows.Close()
+
+	var sprints []*Sprint
+	for rows.Next() {
+		sp, err := scanSprint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sprint: %w", err)
+		}
+		sprints = append(sprints, sp)
+	}
+	return sprints, rows.Err()
+}
+
+// Get returns a sprint by its ID
+func (s *System) Get(id int) (*Sprint, error) {
+	sp, err := scanSprint(s.db.QueryRowContext(s.ctx, `SELECT `+sprintColumns+` FROM sprints WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, storage.Errorf(storage.ErrNotFound, "sprint with id %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sprint: %w", err)
+	}
+	return sp, nil
+}
+
+// Find returns the sprint of a board that ref names: "current" for the one
+// under way on now's date, an ID or a name, in any case
+func (s *System) Find(boardID int, ref string, now time.Time) (*Sprint, error) {
+	ref = strings.TrimSpace(ref)
+	if strings.EqualFold(ref, Current) {
+		today := now.Format(DateLayout)
+		sp, err := scanSprint(s.db.QueryRowContext(s.ctx, `SELECT `+sprintColumns+`
+			FROM sprints WHERE board_id = ? AND start_date <= ? AND end_date >= ?
+			ORDER BY start_date DESC, id DESC LIMIT 1`, boardID, today, today))
+		if err == sql.ErrNoRows {
+			return nil, storage.Errorf(storage.ErrNotFound, "no sprint is under way on %s", today)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find sprint: %w", err)
+		}
+		return sp, nil
+	}
+	if id, err := strconv.Atoi(ref); err == nil {
+		return s.Get(id)
+	}
+
+	sp, err := scanSprint(s.db.QueryRowContext(s.ctx, `SELECT `+sprintColumns+`
+		FROM sprints WHERE board_id = ? AND name = ? COLLATE NOCASE`, boardID, ref))
+	if err == sql.ErrNoRows {
+		return nil, storage.Errorf(storage.ErrNotFound, "no sprint named '%s'", ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sprint: %w", err)
+	}
+	return sp, nil
+}
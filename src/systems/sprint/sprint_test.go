@@ -0,0 +1,176 @@
+package sprint
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestSprints(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	sprints := New(db.Conn())
+	tasks := task.New(db.Conn())
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	first, err := sprints.Create(1, "Sprint 11", now.AddDate(0, 0, -14), now.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	second, err := sprints.Create(1, "Sprint 12", now, now.AddDate(0, 0, 13))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if second.Days() != 14 || !second.IsCurrent(now) || first.IsCurrent(now) {
+		t.Errorf("Unexpected sprint dates: %+v, %+v", first, second)
+	}
+
+	for _, name := range []string{"sprint 12", "", "42", "current", "None"} {
+		if _, err := sprints.Create(1, name, now, now); !errors.Is(err, storage.ErrInvalidInput) {
+			t.Errorf("Create(%q) error = %v, want ErrInvalidInput", name, err)
+		}
+	}
+	if _, err := sprints.Create(1, "Backwards", now, now.AddDate(0, 0, -1)); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("Expected a sprint ending before it starts to be refused, got %v", err)
+	}
+
+	for ref, want := range map[string]int{"current": second.ID, "SPRINT 11": first.ID, "2": second.ID} {
+		if got, err := sprints.Find(1, ref, now); err != nil || got.ID != want {
+			t.Errorf("Find(%q) = %v, %v, want sprint %d", ref, got, err, want)
+		}
+	}
+	if _, err := sprints.Find(1, "current", now.AddDate(1, 0, 0)); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected no current sprint next year, got %v", err)
+	}
+
+	planned, _ := tasks.Create(1, "Planned", "")
+	other, _ := tasks.Create(1, "Not planned", "")
+	if err := tasks.SetSprint(planned.ID, &second.ID); err != nil {
+		t.Fatalf("SetSprint() error = %v", err)
+	}
+	missing := 999
+	if err := tasks.SetSprint(other.ID, &missing); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected planning for a missing sprint to fail, got %v", err)
+	}
+	inSprint, _ := tasks.ListWith(1, task.ListOptions{SprintID: second.ID})
+	if len(inSprint) != 1 || inSprint[0].ID != planned.ID || *inSprint[0].SprintID != second.ID {
+		t.Errorf("Expected only the planned task in the sprint, got %v", inSprint)
+	}
+
+	if err := sprints.Delete(second.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got, _ := tasks.GetByID(planned.ID); got.SprintID != nil {
+		t.Errorf("Expected the task to leave the deleted sprint, got %v", *got.SprintID)
+	}
+}
+
+func TestSprintCapacity(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	sprints := New(db.Conn())
+	tasks := task.New(db.Conn())
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	sp, err := sprints.Create(1, "Sprint 12", now, now.AddDate(0, 0, 13))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := sprints.SetCapacity(sp.ID, 8); err != nil {
+		t.Fatalf("SetCapacity() error = %v", err)
+	}
+	if err := sprints.SetCapacity(sp.ID, -1); !errors.Is(err, storage.ErrInvalidInput) {
+		t.Errorf("Expected a negative capacity to be refused, got %v", err)
+	}
+	if err := sprints.SetCapacity(999, 8); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Expected a missing sprint to be not found, got %v", err)
+	}
+
+	plan := func(title string, points int) *Sprint {
+		t.Helper()
+		planned, _ := tasks.Create(1, title, "")
+		if err := tasks.UpdateEstimate(planned.ID, points); err != nil {
+			t.Fatalf("UpdateEstimate() error = %v", err)
+		}
+		if err := tasks.SetSprint(planned.ID, &sp.ID); err != nil {
+			t.Fatalf("SetSprint() error = %v", err)
+		}
+		got, err := sprints.Get(sp.ID)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		return got
+	}
+
+	if got := plan("Schema", 5); got.Capacity != 8 || got.CheckCapacity() != nil {
+		t.Errorf("Expected 5 of 8 points to fit, got %+v", got)
+	}
+	warning := plan("API", 5).CheckCapacity()
+	if warning == nil || warning.Points != 10 || warning.Capacity != 8 {
+		t.Fatalf("Expected a warning for 10 of 8 points, got %v", warning)
+	}
+	if want := `sprint "Sprint 12" is over capacity: 10 of 8 pts planned`; warning.String() != want {
+		t.Errorf("String() = %q, want %q", warning.String(), want)
+	}
+
+	// Without a capacity, any plan fits
+	if err := sprints.SetCapacity(sp.ID, 0); err != nil {
+		t.Fatalf("SetCapacity() error = %v", err)
+	}
+	if got := plan("Docs", 3); got.CheckCapacity() != nil {
+		t.Errorf("Expected no warning without a capacity, got %v", got.CheckCapacity())
+	}
+}
+
+func TestBurndown(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	sprints := New(db.Conn())
+	tasks := task.New(db.Conn())
+	start := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	sp, _ := sprints.Create(1, "Sprint 12", start, start.AddDate(0, 0, 4))
+
+	// Two tasks of 3 and 2 points, done on the second and third days
+	for i, points := range []int{3, 2} {
+		created, _ := tasks.Create(1, "Task", "")
+		tasks.UpdateEstimate(created.ID, points)
+		tasks.SetSprint(created.ID, &sp.ID)
+		tasks.UpdateStatus(created.ID, task.StatusDone)
+		db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id = ? AND event_type = 'created'`, start.Add(-time.Hour), created.ID)
+		db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id = ? AND event_type = 'status_changed'`, start.AddDate(0, 0, i+1).Add(time.Hour), created.ID)
+	}
+
+	burndown, err := sprints.Burndown(sp.ID, start.AddDate(0, 0, 3).Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Burndown() error = %v", err)
+	}
+	if burndown.Unit != "points" || burndown.Total != 5 {
+		t.Errorf("Expected 5 points in all, got %d %s", burndown.Total, burndown.Unit)
+	}
+	want := []int{5, 2, 0, 0}
+	if len(burndown.Days) != len(want) {
+		t.Fatalf("Expected %d days so far, got %+v", len(want), burndown.Days)
+	}
+	for i, day := range burndown.Days {
+		if day.Remaining != want[i] {
+			t.Errorf("Day %s: remaining = %d, want %d", day.Date, day.Remaining, want[i])
+		}
+	}
+	if burndown.Days[0].Ideal != 4 {
+		t.Errorf("Ideal after the first day = %v, want 4", burndown.Days[0].Ideal)
+	}
+}
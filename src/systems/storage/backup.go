@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Backup copies the database to path, which must not exist yet, with
+// SQLite's online backup: the database stays in use meanwhile, and the
+// copy is a consistent snapshot of it. On failure nothing is left at path.
+func (db *DB) Backup(path string) (err error) {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	dst, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	defer func() {
+		dst.Close()
+		if err != nil {
+			os.Remove(path)
+		}
+	}()
+
+	if err := copyDatabase(db.ctx, dst, db.conn); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", db.path, err)
+	}
+	// The copy is in WAL mode like the board; a rollback journal keeps it a
+	// single file, with no -wal and -shm files appearing once it is read
+	if _, err := dst.ExecContext(db.ctx, `PRAGMA journal_mode = DELETE`); err != nil {
+		return fmt.Errorf("failed to finish backup: %w", err)
+	}
+	return nil
+}
+
+// RestoreFrom replaces everything in the database with the content of the
+// backup at path, through SQLite, so connections other processes have
+// open on it see the restored board too. A backup from an older cainban is
+// migrated up afterwards; one from a newer cainban is refused.
+func (db *DB) RestoreFrom(path string) error {
+	if _, err := ReadBoard(path); err != nil {
+		return err
+	}
+
+	src, err := sql.Open("sqlite3", "file:"+(&url.URL{Path: path}).EscapedPath()+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer src.Close()
+
+	var version int
+	exists, err := tableExists(db, src, MigrationsTable)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if version, err = schemaVersion(db.ctx, src); err != nil {
+			return err
+		}
+	}
+	if version > LatestVersion() {
+		return Errorf(ErrInvalidInput, "%s has schema version %d, newer than this cainban knows (%d); upgrade cainban", path, version, LatestVersion())
+	}
+
+	if err := copyDatabase(db.ctx, db.conn, src); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", path, err)
+	}
+	return db.migrateUp()
+}
+
+// tableExists reports whether the database on conn has a table
+func tableExists(db *DB, conn *sql.DB, table string) (bool, error) {
+	var n int
+	err := conn.QueryRowContext(db.ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to read schema: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,64 @@
+//go:build cgo
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// backupStep is how many pages copyDatabase copies at a time; writers get
+// the database between steps
+const backupStep = 256
+
+// copyDatabase copies the main database of src over that of dst with
+// SQLite's online backup API
+func copyDatabase(ctx context.Context, dst, src *sql.DB) error {
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(dstDriver interface{}) error {
+		return srcConn.Raw(func(srcDriver interface{}) error {
+			to, ok := dstDriver.(*sqlite3.SQLiteConn)
+			from, ok2 := srcDriver.(*sqlite3.SQLiteConn)
+			if !ok || !ok2 {
+				return fmt.Errorf("not a SQLite connection")
+			}
+
+			backup, err := to.Backup("main", from, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				// A busy or locked database is retried at the next step
+				done, err := backup.Step(backupStep)
+				if err != nil {
+					backup.Finish()
+					return err
+				}
+				if done {
+					break
+				}
+				select {
+				case <-ctx.Done():
+					backup.Finish()
+					return ctx.Err()
+				case <-time.After(10 * time.Millisecond):
+				}
+			}
+			return backup.Finish()
+		})
+	})
+}
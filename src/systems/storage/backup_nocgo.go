@@ -0,0 +1,15 @@
+//go:build !cgo
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// copyDatabase needs SQLite's backup API, which only cgo builds have; so
+// does the SQLite driver itself
+func copyDatabase(ctx context.Context, dst, src *sql.DB) error {
+	return fmt.Errorf("backups need cainban built with cgo")
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	db, err := New(filepath.Join(dir, "board.db"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer db.Close()
+
+	if err := db.SetBoardName("api"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Conn().Exec(`INSERT INTO tasks (board_id, title) VALUES (1, 'Kept')`); err != nil {
+		t.Fatal(err)
+	}
+
+	backupPath := filepath.Join(dir, "backups", "api.db")
+	if err := db.Backup(backupPath); err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if err := db.Backup(backupPath); err == nil {
+		t.Error("Expected a backup never to overwrite a file")
+	}
+	if record, err := ReadBoard(backupPath); err != nil || record.Name != "api" {
+		t.Errorf("Expected the backup to be the board, got %+v, %v", record, err)
+	}
+	if _, err := os.Stat(backupPath + "-wal"); !os.IsNotExist(err) {
+		t.Error("Expected the backup to be a single file")
+	}
+
+	// Changes after the backup are undone by restoring it
+	if _, err := db.Conn().Exec(`DELETE FROM tasks; INSERT INTO tasks (board_id, title) VALUES (1, 'Lost')`); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.RestoreFrom(backupPath); err != nil {
+		t.Fatalf("RestoreFrom() error = %v", err)
+	}
+	var titles string
+	if err := db.Conn().QueryRow(`SELECT GROUP_CONCAT(title) FROM tasks`).Scan(&titles); err != nil || titles != "Kept" {
+		t.Errorf("Expected the backed up tasks back, got %q, %v", titles, err)
+	}
+	if version, _ := db.SchemaVersion(); version != LatestVersion() {
+		t.Errorf("Expected the restored board at schema version %d, got %d", LatestVersion(), version)
+	}
+
+	// Only board databases are restored, and only ones this cainban knows
+	notBoard := filepath.Join(dir, "notes.txt")
+	os.WriteFile(notBoard, []byte("not a database"), 0644)
+	if err := db.RestoreFrom(notBoard); !errors.Is(err, ErrNotBoard) {
+		t.Errorf("Expected a file that is not a board to be refused, got %v", err)
+	}
+
+	newer, err := New(filepath.Join(dir, "newer.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newer.Conn().Exec(`INSERT INTO `+MigrationsTable+` (version, name) VALUES (?, 'from the future')`, LatestVersion()+1)
+	newer.Close()
+	if err := db.RestoreFrom(filepath.Join(dir, "newer.db")); err == nil || !strings.Contains(err.Error(), "upgrade cainban") {
+		t.Errorf("Expected a backup from a newer cainban to be refused, got %v", err)
+	}
+	if err := db.Conn().QueryRow(`SELECT GROUP_CONCAT(title) FROM tasks`).Scan(&titles); err != nil || titles != "Kept" {
+		t.Errorf("Expected a refused restore to leave the board alone, got %q, %v", titles, err)
+	}
+}
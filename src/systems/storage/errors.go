@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of failure shared by the systems, so the CLI and the MCP server can
+// tell the user what went wrong without parsing messages. Test for them
+// with errors.Is; the message of the error says the rest.
+var (
+	// ErrNotFound reports a task, board or other record that does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrAmbiguous reports a reference that matches more than one record
+	ErrAmbiguous = errors.New("ambiguous")
+	// ErrInvalidInput reports a value that is not acceptable, e.g. an
+	// unknown priority or an empty title
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrConflict reports a change that clashes with someone else's, e.g.
+	// claiming a task another agent has claimed
+	ErrConflict = errors.New("conflict")
+)
+
+// kindError is an error of one of the kinds above, with its own message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// Errorf formats an error like fmt.Errorf, %w included, and marks it as of
+// the given kind without changing its message
+func Errorf(kind error, format string, a ...interface{}) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, a...)}
+}
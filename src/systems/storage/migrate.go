@@ -0,0 +1,667 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MigrationsTable records the migrations a database has had, one row each.
+// It belongs to the database rather than the board, so bundles leave it
+// out.
+const MigrationsTable = "schema_migrations"
+
+// Migration is one step in the history of the board schema. Up takes a
+// database from the previous version to this one and Down takes it back;
+// a step without Down cannot be undone. Each runs in a transaction of its
+// own, together with the record of the step.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, tx *sql.Tx) error
+	Down    func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations is the history of the schema, in order. A feature that needs
+// a table, column or index adds a step at the end, with the next version;
+// steps that have been released never change, as databases out there have
+// had them already.
+var migrations = []Migration{
+	{Version: 1, Name: "initial schema", Up: createInitialSchema},
+	{
+		Version: 2,
+		Name:    "index subtasks and task numbers",
+		Up: execSQL(`
+			CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks(parent_id);
+			CREATE INDEX IF NOT EXISTS idx_tasks_number ON tasks(number);
+		`),
+		Down: execSQL(`
+			DROP INDEX IF EXISTS idx_tasks_parent;
+			DROP INDEX IF EXISTS idx_tasks_number;
+		`),
+	},
+	{
+		Version: 3,
+		Name:    "log automation commands",
+		Up: execSQL(`
+			-- What the commands of automations and rules did, newest last
+			CREATE TABLE IF NOT EXISTS command_runs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				rule TEXT NOT NULL,
+				task_id INTEGER NOT NULL,
+				command TEXT NOT NULL,
+				exit_code INTEGER NOT NULL,
+				error TEXT NOT NULL DEFAULT '',
+				output TEXT NOT NULL DEFAULT '',
+				duration_ms INTEGER NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+		`),
+		Down: execSQL(`DROP TABLE IF EXISTS command_runs`),
+	},
+	{
+		Version: 4,
+		Name:    "task attachments",
+		Up: execSQL(`
+			-- Files and URLs a task refers to, such as the files it touched
+			CREATE TABLE IF NOT EXISTS task_attachments (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				task_id INTEGER NOT NULL,
+				target TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (task_id, target),
+				FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			);
+		`),
+		Down: execSQL(`DROP TABLE IF EXISTS task_attachments`),
+	},
+	{
+		Version: 5,
+		Name:    "board settings",
+		Up: execSQL(`
+			-- Preferences of the board, such as the order of its columns
+			CREATE TABLE IF NOT EXISTS board_settings (
+				key TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+		`),
+		Down: execSQL(`DROP TABLE IF EXISTS board_settings`),
+	},
+	{
+		Version: 6,
+		Name:    "task hashes",
+		Up: execSQL(`
+			-- A random hex hash per task that, unlike its ID, stays the same
+			-- when the task is exported and imported on another machine
+			ALTER TABLE tasks ADD COLUMN hash TEXT;
+			UPDATE tasks SET hash = lower(hex(randomblob(8))) WHERE hash IS NULL;
+			CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_hash ON tasks(hash);
+		`),
+		Down: execSQL(`
+			DROP INDEX IF EXISTS idx_tasks_hash;
+			ALTER TABLE tasks DROP COLUMN hash;
+		`),
+	},
+	{
+		Version: 7,
+		Name:    "attribution",
+		Up: execSQL(`
+			-- Who created and last changed each task, and who made each
+			-- change in the audit trail: a person or an agent
+			ALTER TABLE tasks ADD COLUMN created_by TEXT DEFAULT '';
+			ALTER TABLE tasks ADD COLUMN updated_by TEXT DEFAULT '';
+			ALTER TABLE task_events ADD COLUMN actor TEXT DEFAULT '';
+		`),
+		Down: execSQL(`
+			ALTER TABLE task_events DROP COLUMN actor;
+			ALTER TABLE tasks DROP COLUMN updated_by;
+			ALTER TABLE tasks DROP COLUMN created_by;
+		`),
+	},
+	{
+		Version: 8,
+		Name:    "task claims",
+		Up: execSQL(`
+			-- The agent working on a task, so no other picks it up, and
+			-- since when
+			ALTER TABLE tasks ADD COLUMN in_progress_by TEXT DEFAULT '';
+			ALTER TABLE tasks ADD COLUMN claimed_at DATETIME NULL;
+		`),
+		Down: execSQL(`
+			ALTER TABLE tasks DROP COLUMN claimed_at;
+			ALTER TABLE tasks DROP COLUMN in_progress_by;
+		`),
+	},
+	{
+		Version: 9,
+		Name:    "task worklogs",
+		Up: execSQL(`
+			-- What an agent did on a task, one entry per step of its work;
+			-- files_changed holds one path per line
+			CREATE TABLE IF NOT EXISTS task_worklogs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				task_id INTEGER NOT NULL,
+				agent TEXT NOT NULL DEFAULT '',
+				summary TEXT NOT NULL,
+				files_changed TEXT NOT NULL DEFAULT '',
+				outcome TEXT NOT NULL DEFAULT '',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS idx_task_worklogs_task ON task_worklogs(task_id);
+		`),
+		Down: execSQL(`DROP TABLE IF EXISTS task_worklogs`),
+	},
+	{
+		Version: 10,
+		Name:    "sprints",
+		Up: execSQL(`
+			-- Iterations of a board, from start_date to end_date inclusive,
+			-- as YYYY-MM-DD, and the sprint each task is planned for. A
+			-- foreign key would keep the column from being dropped, so
+			-- deleting a sprint clears it instead.
+			CREATE TABLE IF NOT EXISTS sprints (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				board_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				start_date TEXT NOT NULL,
+				end_date TEXT NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (board_id, name),
+				FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+			);
+			ALTER TABLE tasks ADD COLUMN sprint_id INTEGER;
+			CREATE INDEX IF NOT EXISTS idx_tasks_sprint ON tasks(sprint_id);
+		`),
+		Down: execSQL(`
+			DROP INDEX IF EXISTS idx_tasks_sprint;
+			ALTER TABLE tasks DROP COLUMN sprint_id;
+			DROP TABLE IF EXISTS sprints;
+		`),
+	},
+	{
+		Version: 11,
+		Name:    "milestones",
+		Up: execSQL(`
+			-- Groups of tasks toward a result, due by an optional date as
+			-- YYYY-MM-DD, and the milestone each task belongs to, cleared
+			-- on delete like sprint_id
+			CREATE TABLE IF NOT EXISTS milestones (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				board_id INTEGER NOT NULL,
+				name TEXT NOT NULL,
+				description TEXT DEFAULT '',
+				due_date TEXT DEFAULT '',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE (board_id, name),
+				FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+			);
+			ALTER TABLE tasks ADD COLUMN milestone_id INTEGER;
+			CREATE INDEX IF NOT EXISTS idx_tasks_milestone ON tasks(milestone_id);
+		`),
+		Down: execSQL(`
+			DROP INDEX IF EXISTS idx_tasks_milestone;
+			ALTER TABLE tasks DROP COLUMN milestone_id;
+			DROP TABLE IF EXISTS milestones;
+		`),
+	},
+	{
+		Version: 12,
+		Name:    "sprint_capacity",
+		Up: execSQL(`
+			-- The points of estimated work a sprint can take, 0 for no limit
+			ALTER TABLE sprints ADD COLUMN capacity INTEGER NOT NULL DEFAULT 0;
+		`),
+		Down: execSQL(`ALTER TABLE sprints DROP COLUMN capacity`),
+	},
+	{
+		Version: 13,
+		Name:    "key_result_milestones",
+		Up: execSQL(`
+			-- The milestone whose tasks roll up into a key result
+			ALTER TABLE key_results ADD COLUMN milestone_id INTEGER;
+		`),
+		Down: execSQL(`ALTER TABLE key_results DROP COLUMN milestone_id`),
+	},
+}
+
+// Migrations returns the history of the schema, in order
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// LatestVersion returns the version of the schema this cainban creates
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// AppliedMigration is a migration a database has had
+type AppliedMigration struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// SchemaVersion returns the version of the database's schema: that of the
+// last migration it has had
+func (db *DB) SchemaVersion() (int, error) {
+	return schemaVersion(db.ctx, db.conn)
+}
+
+// AppliedMigrations lists the migrations the database has had, in order
+func (db *DB) AppliedMigrations() ([]AppliedMigration, error) {
+	rows, err := db.conn.QueryContext(db.ctx, `SELECT version, name, applied_at FROM `+MigrationsTable+` ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var applied []AppliedMigration
+	for rows.Next() {
+		var m AppliedMigration
+		if err := rows.Scan(&m.Version, &m.Name, &m.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to list migrations: %w", err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, rows.Err()
+}
+
+// MigrateDown undoes migrations, latest first, until the database is at
+// version, e.g. to go back to an older cainban. Opening the database again
+// with this cainban migrates it back up.
+func (db *DB) MigrateDown(version int) error {
+	if version < 0 {
+		return Errorf(ErrInvalidInput, "invalid schema version %d", version)
+	}
+	for i := len(migrations) - 1; i >= 0 && migrations[i].Version > version; i-- {
+		m := migrations[i]
+		err := Tx(db.ctx, db.conn, func(tx *sql.Tx) error {
+			current, err := schemaVersion(db.ctx, tx)
+			if err != nil || current < m.Version {
+				return err
+			}
+			if m.Down == nil {
+				return Errorf(ErrInvalidInput, "migration %d (%s) cannot be undone", m.Version, m.Name)
+			}
+			if err := m.Down(db.ctx, tx); err != nil {
+				return fmt.Errorf("failed to undo migration %d (%s): %w", m.Version, m.Name, err)
+			}
+			_, err = tx.ExecContext(db.ctx, `DELETE FROM `+MigrationsTable+` WHERE version = ?`, m.Version)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateUp applies the migrations the database has not had yet. Another
+// cainban may be opening the same database, so whether a step is due is
+// decided within its transaction. A database from a newer cainban is left
+// alone.
+func (db *DB) migrateUp() error {
+	_, err := db.conn.ExecContext(db.ctx, `CREATE TABLE IF NOT EXISTS `+MigrationsTable+` (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", MigrationsTable, err)
+	}
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > LatestVersion() {
+		return fmt.Errorf("database schema is at version %d, newer than this cainban knows (%d); upgrade cainban", current, LatestVersion())
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		err := Tx(db.ctx, db.conn, func(tx *sql.Tx) error {
+			current, err := schemaVersion(db.ctx, tx)
+			if err != nil || current >= m.Version {
+				return err
+			}
+			if err := m.Up(db.ctx, tx); err != nil {
+				return fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+			}
+			_, err = tx.ExecContext(db.ctx, `INSERT INTO `+MigrationsTable+` (version, name) VALUES (?, ?)`, m.Version, m.Name)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queryer is a database or a transaction, for reading the schema
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func schemaVersion(ctx context.Context, q queryer) (int, error) {
+	var version int
+	if err := q.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM `+MigrationsTable).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// execSQL returns a migration step running statements
+func execSQL(statements string) func(ctx context.Context, tx *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, statements)
+		return err
+	}
+}
+
+// createInitialSchema is the first migration: the schema as it was when
+// migrations were introduced. Databases from before then have had parts of
+// it already, so it only creates what is missing, columns of the tasks
+// table included.
+func createInitialSchema(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, initialSchema); err != nil {
+		return err
+	}
+
+	// Columns added to tasks before migrations, in the order they were
+	// introduced
+	columns := []struct {
+		name       string
+		definition string
+	}{
+		{"deleted_at", "DATETIME NULL"},
+		{"estimate", "INTEGER DEFAULT 0"},
+		{"assignee", "TEXT DEFAULT ''"},
+		{"recurrence", "TEXT DEFAULT ''"},
+		{"size", "TEXT DEFAULT ''"},
+		{"energy", "TEXT DEFAULT ''"},
+		{"due_at", "DATETIME NULL"},
+		{"position", "INTEGER DEFAULT 0"},
+		{"parent_id", "INTEGER NULL"},
+		{"rollup_subtasks", "INTEGER DEFAULT 0"},
+		{"rollup_done_subtasks", "INTEGER DEFAULT 0"},
+		{"rollup_points", "INTEGER DEFAULT 0"},
+		{"rollup_done_points", "INTEGER DEFAULT 0"},
+		{"number", "INTEGER DEFAULT 0"},
+	}
+
+	for _, col := range columns {
+		exists, err := columnExists(ctx, tx, "tasks", col.name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE tasks ADD COLUMN %s %s", col.name, col.definition))
+		if err != nil {
+			return fmt.Errorf("failed to add %s column: %w", col.name, err)
+		}
+	}
+
+	return nil
+}
+
+// columnExists reports whether a table has a column with the given name
+func columnExists(ctx context.Context, q queryer, table, column string) (bool, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, fmt.Errorf("failed to get table info: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, dataType string
+		var notNull, pk int
+		var defaultValue interface{}
+
+		err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk)
+		if err != nil {
+			return false, fmt.Errorf("failed to scan column info: %w", err)
+		}
+
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
+
+// initialSchema creates the tables of the initial schema, see
+// createInitialSchema
+const initialSchema = `
+
+	CREATE TABLE IF NOT EXISTS boards (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		description TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS tasks (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		board_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT,
+		status TEXT NOT NULL DEFAULT 'todo',
+		priority INTEGER DEFAULT 0,
+		estimate INTEGER DEFAULT 0,
+		assignee TEXT DEFAULT '',
+		recurrence TEXT DEFAULT '',
+		size TEXT DEFAULT '',
+		energy TEXT DEFAULT '',
+		due_at DATETIME NULL,
+		position INTEGER DEFAULT 0,
+		parent_id INTEGER NULL,
+		rollup_subtasks INTEGER DEFAULT 0,
+		rollup_done_subtasks INTEGER DEFAULT 0,
+		rollup_points INTEGER DEFAULT 0,
+		rollup_done_points INTEGER DEFAULT 0,
+		number INTEGER DEFAULT 0,
+		deleted_at DATETIME NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS task_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		from_task_id INTEGER NOT NULL,
+		to_task_id INTEGER NOT NULL,
+		link_type TEXT NOT NULL DEFAULT 'blocks',
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (from_task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		FOREIGN KEY (to_task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		UNIQUE(from_task_id, to_task_id, link_type)
+	);
+
+	CREATE TABLE IF NOT EXISTS task_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		event_type TEXT NOT NULL,
+		from_status TEXT,
+		to_status TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS capacities (
+		assignee TEXT PRIMARY KEY,
+		points INTEGER NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS goals (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		board_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS key_results (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		goal_id INTEGER NOT NULL,
+		title TEXT NOT NULL,
+		target INTEGER DEFAULT 0,
+		current INTEGER DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (goal_id) REFERENCES goals(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS key_result_tasks (
+		key_result_id INTEGER NOT NULL,
+		task_id INTEGER NOT NULL,
+		PRIMARY KEY (key_result_id, task_id),
+		FOREIGN KEY (key_result_id) REFERENCES key_results(id) ON DELETE CASCADE,
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS task_refs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		ref TEXT NOT NULL,
+		title TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		UNIQUE(task_id, kind, ref)
+	);
+
+	CREATE TABLE IF NOT EXISTS reminders (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		remind_at DATETIME NOT NULL,
+		note TEXT,
+		fired_at DATETIME NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS task_comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		author TEXT DEFAULT '',
+		body TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS task_reactions (
+		task_id INTEGER NOT NULL,
+		actor TEXT NOT NULL,
+		emoji TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (task_id, actor, emoji),
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS task_votes (
+		task_id INTEGER NOT NULL,
+		actor TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (task_id, actor),
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS task_checkpoints (
+		task_id INTEGER PRIMARY KEY,
+		content TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS task_contexts (
+		task_id INTEGER NOT NULL,
+		context TEXT NOT NULL,
+		PRIMARY KEY (task_id, context),
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);
+
+	-- Links to tasks on other boards. Each board keeps its own side: the
+	-- linking board an outgoing row, the target board an incoming one.
+	CREATE TABLE IF NOT EXISTS task_board_links (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		board TEXT NOT NULL,
+		remote_task_id INTEGER NOT NULL,
+		link_type TEXT NOT NULL DEFAULT 'blocks',
+		incoming BOOLEAN NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+		UNIQUE(task_id, board, remote_task_id, link_type, incoming)
+	);
+
+	-- Actions run when a task enters a column. last_event_id is the last
+	-- task_events row the automation has seen.
+	CREATE TABLE IF NOT EXISTS automations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		status TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		target TEXT NOT NULL,
+		last_event_id INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- How far the rules in a board's rules file have read task_events
+	CREATE TABLE IF NOT EXISTS automation_cursors (
+		name TEXT PRIMARY KEY,
+		last_event_id INTEGER NOT NULL
+	);
+
+	-- What it means for a task to be in a column, e.g. the definition of done
+	CREATE TABLE IF NOT EXISTS column_notes (
+		status TEXT PRIMARY KEY,
+		note TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- The board's charter in Markdown; a single row
+	CREATE TABLE IF NOT EXISTS board_readme (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		content TEXT NOT NULL,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Human-friendly task numbers (T-001); a single row, absent until
+	-- numbering is turned on
+	CREATE TABLE IF NOT EXISTS task_numbering (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		prefix TEXT NOT NULL,
+		next_number INTEGER NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_tasks_board_id ON tasks(board_id);
+	CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
+	CREATE INDEX IF NOT EXISTS idx_task_links_from ON task_links(from_task_id);
+	CREATE INDEX IF NOT EXISTS idx_task_links_to ON task_links(to_task_id);
+	CREATE INDEX IF NOT EXISTS idx_task_board_links_task ON task_board_links(task_id);
+	CREATE INDEX IF NOT EXISTS idx_task_events_task ON task_events(task_id);
+	CREATE INDEX IF NOT EXISTS idx_key_results_goal ON key_results(goal_id);
+	CREATE INDEX IF NOT EXISTS idx_task_refs_task ON task_refs(task_id);
+	CREATE INDEX IF NOT EXISTS idx_reminders_pending ON reminders(fired_at, remind_at);
+	CREATE INDEX IF NOT EXISTS idx_task_contexts_context ON task_contexts(context);
+	CREATE INDEX IF NOT EXISTS idx_task_comments_task ON task_comments(task_id);
+
+	-- Create default board if none exists
+	INSERT OR IGNORE INTO boards (id, name, description) 
+	VALUES (1, 'Default Board', 'Default kanban board');
+	`
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrations_AreInOrder(t *testing.T) {
+	for i, m := range Migrations() {
+		if m.Version != i+1 {
+			t.Errorf("Expected migration %q to have version %d, got %d", m.Name, i+1, m.Version)
+		}
+		if m.Name == "" || m.Up == nil {
+			t.Errorf("Expected migration %d to have a name and an Up step", m.Version)
+		}
+	}
+}
+
+func TestMigrate_NewDatabaseIsLatest(t *testing.T) {
+	db, err := NewMemory()
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+	defer db.Close()
+
+	if version, err := db.SchemaVersion(); err != nil || version != LatestVersion() {
+		t.Errorf("SchemaVersion() = %d, %v, want %d", version, err, LatestVersion())
+	}
+	applied, err := db.AppliedMigrations()
+	if err != nil {
+		t.Fatalf("AppliedMigrations() error = %v", err)
+	}
+	if len(applied) != len(Migrations()) || applied[0].Name != "initial schema" {
+		t.Errorf("Expected every migration to be recorded, got %+v", applied)
+	}
+}
+
+func TestMigrate_UpgradesUnversionedDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+
+	// A board from before migrations: some tables, tasks short of columns
+	conn, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = conn.Exec(`
+		CREATE TABLE boards (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL, description TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		CREATE TABLE tasks (id INTEGER PRIMARY KEY AUTOINCREMENT, board_id INTEGER NOT NULL, title TEXT NOT NULL,
+			description TEXT, status TEXT NOT NULL DEFAULT 'todo', priority INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP, updated_at DATETIME DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO boards (id, name) VALUES (1, 'Old Board');
+		INSERT INTO tasks (board_id, title) VALUES (1, 'Kept');
+	`)
+	conn.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer db.Close()
+
+	if version, _ := db.SchemaVersion(); version != LatestVersion() {
+		t.Errorf("Expected the old board to be migrated to %d, got %d", LatestVersion(), version)
+	}
+	var title, hash string
+	var number int
+	if err := db.Conn().QueryRow(`SELECT title, number, hash FROM tasks`).Scan(&title, &number, &hash); err != nil || title != "Kept" {
+		t.Errorf("Expected the task to survive with the new columns, got %q, %v", title, err)
+	}
+	if len(hash) != 16 {
+		t.Errorf("Expected the task to be given a hash, got %q", hash)
+	}
+}
+
+func TestMigrateDown(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	hasIndex := func() bool {
+		var n int
+		db.Conn().QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_tasks_parent'`).Scan(&n)
+		return n == 1
+	}
+	if !hasIndex() {
+		t.Fatal("Expected migration 2 to create idx_tasks_parent")
+	}
+
+	if err := db.MigrateDown(1); err != nil {
+		t.Fatalf("MigrateDown(1) error = %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != 1 || hasIndex() {
+		t.Errorf("Expected version 1 without the index, got version %d", version)
+	}
+
+	err = db.MigrateDown(0)
+	if !errors.Is(err, ErrInvalidInput) || !strings.Contains(err.Error(), "cannot be undone") {
+		t.Errorf("Expected the initial schema to stay, got %v", err)
+	}
+	db.Close()
+
+	// Opening it again brings it back up
+	db, err = New(dbPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if version, _ := db.SchemaVersion(); version != LatestVersion() || !hasIndex() {
+		t.Errorf("Expected reopening to migrate up again, got version %d", version)
+	}
+
+	// A database from a newer cainban is refused rather than guessed at
+	if _, err := db.Conn().Exec(`INSERT INTO `+MigrationsTable+` (version, name) VALUES (?, 'from the future')`, LatestVersion()+1); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if _, err := New(dbPath); err == nil || !strings.Contains(err.Error(), "upgrade cainban") {
+		t.Errorf("Expected a newer schema to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// DefaultBoardName is the name a new database gives its board. Boards
+// created before SetBoardName existed still carry it.
+const DefaultBoardName = "Default Board"
+
+// ErrNotBoard reports a file that is not a cainban board database
+var ErrNotBoard = Errorf(ErrInvalidInput, "not a cainban board database")
+
+// BoardRecord is what a board database records about its board
+type BoardRecord struct {
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	// Settings are the board's own settings, such as its column order;
+	// nil when it has none
+	Settings map[string]string
+}
+
+// SetBoardName records the name of the board the database holds, so that a
+// file copied or renamed behind the board system's back can be spotted
+func (db *DB) SetBoardName(name string) error {
+	_, err := db.conn.ExecContext(db.ctx, `UPDATE boards SET name = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to set board name: %w", err)
+	}
+	return nil
+}
+
+// SetBoardDescription records what the board is for
+func (db *DB) SetBoardDescription(description string) error {
+	_, err := db.conn.ExecContext(db.ctx, `UPDATE boards SET description = ?, updated_at = CURRENT_TIMESTAMP WHERE id = 1`, description)
+	if err != nil {
+		return fmt.Errorf("failed to set board description: %w", err)
+	}
+	return nil
+}
+
+// ReadBoard reads the board record of the database at path without
+// creating or migrating anything, so that it is safe on any file. It
+// returns ErrNotBoard for files that are not board databases.
+func ReadBoard(path string) (*BoardRecord, error) {
+	conn, err := sql.Open("sqlite3", "file:"+(&url.URL{Path: path}).EscapedPath()+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer conn.Close()
+
+	var tables int
+	err = conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN ('boards', 'tasks')`).Scan(&tables)
+	if err != nil || tables != 2 {
+		// Files that are not SQLite at all fail here too
+		return nil, ErrNotBoard
+	}
+
+	var record BoardRecord
+	var description sql.NullString
+	err = conn.QueryRow(`SELECT name, description, created_at, updated_at FROM boards WHERE id = 1`).
+		Scan(&record.Name, &description, &record.CreatedAt, &record.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotBoard
+		}
+		return nil, fmt.Errorf("failed to read board record: %w", err)
+	}
+	record.Description = description.String
+
+	// Boards from before the settings table have no settings
+	rows, err := conn.Query(`SELECT key, value FROM board_settings ORDER BY key`)
+	if err != nil {
+		return &record, nil
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to read board settings: %w", err)
+		}
+		if record.Settings == nil {
+			record.Settings = make(map[string]string)
+		}
+		record.Settings[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read board settings: %w", err)
+	}
+	return &record, nil
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BusyTimeout is how long a connection opened by New waits for another
+// writer, such as the TUI, the CLI or an MCP agent on the same board, to
+// release the database before giving up with a busy error. Set it before
+// calling New.
+var BusyTimeout = 5 * time.Second
+
+// retryAttempts and retryBackoff bound how Retry retries: up to
+// retryAttempts tries, waiting retryBackoff, then twice as long each time
+var (
+	retryAttempts = 5
+	retryBackoff  = 50 * time.Millisecond
+)
+
+// busyMessages are how SQLite reports SQLITE_BUSY and SQLITE_LOCKED. They
+// are matched rather than the driver's error codes, whose type only exists
+// in cgo builds.
+var busyMessages = []string{"database is locked", "database table is locked"}
+
+// IsBusy reports whether err is SQLite saying the database is busy or
+// locked by another connection, so the operation may succeed if retried
+func IsBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, msg := range busyMessages {
+		if strings.Contains(err.Error(), msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// Retry runs fn until it succeeds, fails with an error other than a busy
+// one, or has been tried a few times, backing off between tries. It stops
+// early when ctx is done.
+func Retry(ctx context.Context, fn func() error) error {
+	wait := retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsBusy(err) || attempt == retryAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
+
+// Tx runs fn in a transaction on conn and commits it, or rolls it back if
+// fn fails. The whole transaction is retried when the database is busy, so
+// fn must not have effects outside it. Connections from New take the write
+// lock when the transaction begins, so operations of several statements
+// never interleave with other writers.
+func Tx(ctx context.Context, conn *sql.DB, fn func(tx *sql.Tx) error) error {
+	return Retry(ctx, func() error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to start transaction: %w", err)
+		}
+		defer func() { _ = tx.Rollback() }() // no-op once committed
+
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	})
+}
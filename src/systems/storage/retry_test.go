@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestRetry(t *testing.T) {
+	defer func(backoff time.Duration) { retryBackoff = backoff }(retryBackoff)
+	retryBackoff = time.Millisecond
+
+	busy := sqlite3.Error{Code: sqlite3.ErrBusy}
+	if !IsBusy(fmt.Errorf("failed to move task: %w", busy)) || !IsBusy(sqlite3.Error{Code: sqlite3.ErrLocked}) {
+		t.Error("Expected busy and locked errors to be busy")
+	}
+	if IsBusy(errors.New("no such table")) || IsBusy(sqlite3.Error{Code: sqlite3.ErrConstraint}) {
+		t.Error("Expected other errors not to be busy")
+	}
+
+	tries := 0
+	err := Retry(context.Background(), func() error {
+		if tries++; tries < 3 {
+			return busy
+		}
+		return nil
+	})
+	if err != nil || tries != 3 {
+		t.Errorf("Expected success on the third try, got %v after %d", err, tries)
+	}
+
+	tries = 0
+	failure := errors.New("constraint failed")
+	if err := Retry(context.Background(), func() error { tries++; return failure }); err != failure || tries != 1 {
+		t.Errorf("Expected other errors to be returned at once, got %v after %d tries", err, tries)
+	}
+
+	tries = 0
+	if err := Retry(context.Background(), func() error { tries++; return busy }); !IsBusy(err) || tries != retryAttempts {
+		t.Errorf("Expected to give up after %d tries, got %v after %d", retryAttempts, err, tries)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tries = 0
+	if err := Retry(ctx, func() error { tries++; return busy }); !IsBusy(err) || tries != 1 {
+		t.Errorf("Expected a cancelled context to stop retrying, got %v after %d tries", err, tries)
+	}
+}
+
+func TestTx_ConcurrentWriters(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// Two connections, as the TUI and an MCP agent would have
+	var dbs []*DB
+	for i := 0; i < 2; i++ {
+		db, err := New(dbPath)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		defer db.Close()
+		dbs = append(dbs, db)
+	}
+
+	const writes = 25
+	var wg sync.WaitGroup
+	errs := make(chan error, len(dbs)*writes)
+	for _, db := range dbs {
+		wg.Add(1)
+		go func(db *DB) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				// Read, then write what was read: the two must not
+				// interleave with the other connection's
+				errs <- Tx(context.Background(), db.Conn(), func(tx *sql.Tx) error {
+					var count int
+					if err := tx.QueryRow(`SELECT COUNT(*) FROM boards`).Scan(&count); err != nil {
+						return err
+					}
+					_, err := tx.Exec(`INSERT INTO boards (name) VALUES (?)`, fmt.Sprintf("board %d", count+1))
+					return err
+				})
+			}
+		}(db)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Expected every write to succeed, got %v", err)
+		}
+	}
+
+	var boards, names int
+	err := dbs[0].Conn().QueryRow(`SELECT COUNT(*), COUNT(DISTINCT name) FROM boards`).Scan(&boards, &names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if boards != names {
+		t.Errorf("Expected every board to be named after the count it saw, got %d boards with %d names", boards, names)
+	}
+}
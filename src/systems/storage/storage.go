@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
@@ -13,6 +14,10 @@ import (
 type DB struct {
 	conn *sql.DB
 	path string
+	ctx  context.Context
+
+	beforeClose []func() error
+	onFlush     []func() error
 }
 
 // New creates a new database connection
@@ -23,7 +28,10 @@ func New(dbPath string) (*DB, error) {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
-	conn, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on&_journal_mode=WAL")
+	// _txlock=immediate makes transactions take the write lock up front, and
+	// _busy_timeout makes them wait for it rather than fail at once
+	dsn := fmt.Sprintf("%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=%d&_txlock=immediate", dbPath, BusyTimeout.Milliseconds())
+	conn, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -31,6 +39,7 @@ func New(dbPath string) (*DB, error) {
 	db := &DB{
 		conn: conn,
 		path: dbPath,
+		ctx:  context.Background(),
 	}
 
 	if err := db.initialize(); err != nil {
@@ -51,6 +60,7 @@ func NewMemory() (*DB, error) {
 	db := &DB{
 		conn: conn,
 		path: ":memory:",
+		ctx:  context.Background(),
 	}
 
 	if err := db.initialize(); err != nil {
@@ -61,111 +71,75 @@ func NewMemory() (*DB, error) {
 	return db, nil
 }
 
-// initialize creates the database schema
+// initialize creates the database schema, or brings that of an existing
+// database up to date
 func (db *DB) initialize() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS boards (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		name TEXT NOT NULL,
-		description TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS tasks (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		board_id INTEGER NOT NULL,
-		title TEXT NOT NULL,
-		description TEXT,
-		status TEXT NOT NULL DEFAULT 'todo',
-		priority INTEGER DEFAULT 0,
-		deleted_at DATETIME NULL,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (board_id) REFERENCES boards(id) ON DELETE CASCADE
-	);
-
-	CREATE TABLE IF NOT EXISTS task_links (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		from_task_id INTEGER NOT NULL,
-		to_task_id INTEGER NOT NULL,
-		link_type TEXT NOT NULL DEFAULT 'blocks',
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		FOREIGN KEY (from_task_id) REFERENCES tasks(id) ON DELETE CASCADE,
-		FOREIGN KEY (to_task_id) REFERENCES tasks(id) ON DELETE CASCADE,
-		UNIQUE(from_task_id, to_task_id, link_type)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_tasks_board_id ON tasks(board_id);
-	CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
-	CREATE INDEX IF NOT EXISTS idx_task_links_from ON task_links(from_task_id);
-	CREATE INDEX IF NOT EXISTS idx_task_links_to ON task_links(to_task_id);
-
-	-- Create default board if none exists
-	INSERT OR IGNORE INTO boards (id, name, description) 
-	VALUES (1, 'Default Board', 'Default kanban board');
-	`
-
-	_, err := db.conn.Exec(schema)
-	if err != nil {
-		return err
-	}
-
-	// Handle migration for existing databases
-	return db.migrate()
+	return db.migrateUp()
 }
 
-// migrate handles database migrations for existing databases
-func (db *DB) migrate() error {
-	// Check if deleted_at column exists
-	rows, err := db.conn.Query("PRAGMA table_info(tasks)")
-	if err != nil {
-		return fmt.Errorf("failed to get table info: %w", err)
-	}
-	defer rows.Close()
-
-	hasDeletedAt := false
-	for rows.Next() {
-		var cid int
-		var name, dataType string
-		var notNull, pk int
-		var defaultValue interface{}
-
-		err := rows.Scan(&cid, &name, &dataType, &notNull, &defaultValue, &pk)
-		if err != nil {
-			return fmt.Errorf("failed to scan column info: %w", err)
-		}
-
-		if name == "deleted_at" {
-			hasDeletedAt = true
-			break
+// Close closes the database connection, after running what BeforeClose
+// asked for
+func (db *DB) Close() error {
+	var err error
+	for _, f := range db.beforeClose {
+		if ferr := f(); ferr != nil && err == nil {
+			err = ferr
 		}
 	}
+	db.beforeClose = nil
 
-	// Add deleted_at column if it doesn't exist
-	if !hasDeletedAt {
-		_, err = db.conn.Exec("ALTER TABLE tasks ADD COLUMN deleted_at DATETIME NULL")
-		if err != nil {
-			return fmt.Errorf("failed to add deleted_at column: %w", err)
+	if db.conn != nil {
+		if cerr := db.conn.Close(); err == nil {
+			err = cerr
 		}
 	}
+	return err
+}
 
-	return nil
+// BeforeClose arranges for f to run when the database is closed, while it
+// is still open, as for a board whose changes go back where it came from
+func (db *DB) BeforeClose(f func() error) {
+	db.beforeClose = append(db.beforeClose, f)
 }
 
-// Close closes the database connection
-func (db *DB) Close() error {
-	if db.conn != nil {
-		return db.conn.Close()
+// OnFlush arranges for f to run on every Flush
+func (db *DB) OnFlush(f func() error) {
+	db.onFlush = append(db.onFlush, f)
+}
+
+// Flush runs what OnFlush asked for. Long sessions such as the TUI and the
+// MCP server flush after their changes, to pass them on as they make them
+// rather than only when they end.
+func (db *DB) Flush() error {
+	var err error
+	for _, f := range db.onFlush {
+		if ferr := f(); ferr != nil && err == nil {
+			err = ferr
+		}
 	}
-	return nil
+	return err
 }
 
-// Conn returns the underlying database connection
+// Conn returns the underlying database connection. Queries on it should
+// run under Context.
 func (db *DB) Conn() *sql.DB {
 	return db.conn
 }
 
+// WithContext returns a copy of db whose queries run under ctx, for a
+// caller that wants to cancel them or give them a deadline. The copy
+// shares the connection, which closing either closes.
+func (db *DB) WithContext(ctx context.Context) *DB {
+	copy := *db
+	copy.ctx = ctx
+	return &copy
+}
+
+// Context returns the context the queries on db run under
+func (db *DB) Context() context.Context {
+	return db.ctx
+}
+
 // Path returns the database file path
 func (db *DB) Path() string {
 	return db.path
@@ -173,5 +147,5 @@ func (db *DB) Path() string {
 
 // Ping tests the database connection
 func (db *DB) Ping() error {
-	return db.conn.Ping()
+	return db.conn.PingContext(db.ctx)
 }
@@ -88,3 +88,118 @@ func TestClose_ClosesConnection(t *testing.T) {
 		t.Error("Expected Ping() to fail after Close(), but it succeeded")
 	}
 }
+
+func TestBeforeClose_RunsWhileOpen(t *testing.T) {
+	db, err := NewMemory()
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+
+	ran := 0
+	db.BeforeClose(func() error {
+		ran++
+		return db.Ping()
+	})
+	if err := db.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if ran != 1 {
+		t.Errorf("Expected the function to run once, ran %d times", ran)
+	}
+}
+
+func TestFlush_RunsEachTime(t *testing.T) {
+	db, err := NewMemory()
+	if err != nil {
+		t.Fatalf("NewMemory() error = %v", err)
+	}
+	defer db.Close()
+
+	ran := 0
+	db.OnFlush(func() error {
+		ran++
+		return nil
+	})
+	for i := 0; i < 2; i++ {
+		if err := db.Flush(); err != nil {
+			t.Errorf("Flush() error = %v", err)
+		}
+	}
+	if ran != 2 {
+		t.Errorf("Expected the function to run on each flush, ran %d times", ran)
+	}
+}
+
+func TestWatch_SeesOtherConnections(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer db.Close()
+
+	w, err := db.Watch()
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	defer w.Close()
+
+	if changed, err := w.Changed(); err != nil || changed {
+		t.Fatalf("Changed() = %v, %v before any write", changed, err)
+	}
+
+	// Another process opening the same board and adding a task
+	other, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer other.Close()
+	if _, err := other.Conn().Exec(`INSERT INTO tasks (board_id, title) VALUES (1, 'From elsewhere')`); err != nil {
+		t.Fatalf("Failed to insert task: %v", err)
+	}
+
+	if changed, err := w.Changed(); err != nil || !changed {
+		t.Errorf("Changed() = %v, %v after another connection wrote", changed, err)
+	}
+	if changed, err := w.Changed(); err != nil || changed {
+		t.Errorf("Changed() = %v, %v with nothing new", changed, err)
+	}
+}
+
+func TestReadBoard(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "my board.db")
+	db, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if record, err := ReadBoard(dbPath); err != nil || record.Name != DefaultBoardName || record.CreatedAt.IsZero() {
+		t.Errorf("ReadBoard() = %+v, %v for a new database", record, err)
+	}
+	if err := db.SetBoardName("api"); err != nil {
+		t.Fatalf("SetBoardName() error = %v", err)
+	}
+	if err := db.SetBoardDescription("Backend services"); err != nil {
+		t.Fatalf("SetBoardDescription() error = %v", err)
+	}
+	db.Close()
+	if record, err := ReadBoard(dbPath); err != nil || record.Name != "api" || record.Description != "Backend services" {
+		t.Errorf("ReadBoard() = %+v, %v, want api", record, err)
+	}
+
+	// Other files are reported, and left as they were
+	notes := filepath.Join(dir, "notes.db")
+	os.WriteFile(notes, []byte("not a database"), 0644)
+	if _, err := ReadBoard(notes); err != ErrNotBoard {
+		t.Errorf("ReadBoard() error = %v for a text file, want ErrNotBoard", err)
+	}
+	if data, _ := os.ReadFile(notes); string(data) != "not a database" {
+		t.Errorf("ReadBoard() changed the file to %q", data)
+	}
+	if _, err := ReadBoard(filepath.Join(dir, "missing.db")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.db")); !os.IsNotExist(err) {
+		t.Error("ReadBoard() created the missing file")
+	}
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Watcher tells whether other connections, including other processes, have
+// committed changes to the database. It keeps a connection of its own
+// because SQLite's data_version only counts commits made through other
+// connections than the one asking.
+type Watcher struct {
+	conn    *sql.Conn
+	version int64
+}
+
+// Watch starts watching the database for changes
+func (db *DB) Watch() (*Watcher, error) {
+	conn, err := db.conn.Conn(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open watch connection: %w", err)
+	}
+
+	w := &Watcher{conn: conn}
+	if w.version, err = w.dataVersion(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Changed reports whether the database has changed since Watch or the last
+// call to Changed
+func (w *Watcher) Changed() (bool, error) {
+	version, err := w.dataVersion()
+	if err != nil {
+		return false, err
+	}
+	changed := version != w.version
+	w.version = version
+	return changed, nil
+}
+
+func (w *Watcher) dataVersion() (int64, error) {
+	var version int64
+	if err := w.conn.QueryRowContext(context.Background(), "PRAGMA data_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read data version: %w", err)
+	}
+	return version, nil
+}
+
+// Close releases the watch connection
+func (w *Watcher) Close() error {
+	return w.conn.Close()
+}
@@ -0,0 +1,96 @@
+package task
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Attachment is a file or URL a task refers to, such as a file an agent
+// changed while working on it
+type Attachment struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	Target    string    `json:"target"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsURL reports whether the attachment is a URL rather than a file path
+func (a Attachment) IsURL() bool {
+	return IsURL(a.Target)
+}
+
+// IsURL reports whether target is a URL, with a scheme such as https: or
+// mailto:, rather than a file path. Windows drive letters are not schemes.
+func IsURL(target string) bool {
+	u, err := url.Parse(target)
+	return err == nil && len(u.Scheme) > 1
+}
+
+// Attach records that a task refers to target, a file path or URL. Paths
+// are stored as given, so callers make them absolute if they should be.
+// Attaching the same target twice changes nothing; it reports whether the
+// attachment is new.
+func (s *System) Attach(taskID int, target string) (bool, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return false, storage.Errorf(ErrInvalidInput, "attachment cannot be empty")
+	}
+	if _, err := s.GetByID(taskID); err != nil {
+		return false, err
+	}
+
+	result, err := s.db.ExecContext(s.ctx, `
+		INSERT OR IGNORE INTO task_attachments (task_id, target) VALUES (?, ?)
+	`, taskID, target)
+	if err != nil {
+		return false, fmt.Errorf("failed to add attachment: %w", err)
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to add attachment: %w", err)
+	}
+	return added > 0, nil
+}
+
+// Detach removes an attachment from a task, reporting whether there was one
+func (s *System) Detach(taskID int, target string) (bool, error) {
+	result, err := s.db.ExecContext(s.ctx, `
+		DELETE FROM task_attachments WHERE task_id = ? AND target = ?
+	`, taskID, strings.TrimSpace(target))
+	if err != nil {
+		return false, fmt.Errorf("failed to remove attachment: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove attachment: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// ListAttachments returns the attachments of a task, oldest first
+func (s *System) ListAttachments(taskID int) ([]Attachment, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, task_id, target, created_at
+		FROM task_attachments
+		WHERE task_id = ?
+		ORDER BY created_at ASC, id ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []Attachment
+	for rows.Next() {
+		var a Attachment
+		if err := rows.Scan(&a.ID, &a.TaskID, &a.Target, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
@@ -0,0 +1,76 @@
+package task
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestAttachments(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	created, err := taskSystem.Create(1, "Fix the parser", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	for _, target := range []string{"/src/parser.go", "https://github.com/hmain/cainban/issues/7", "/src/parser_test.go"} {
+		if added, err := taskSystem.Attach(created.ID, target); err != nil || !added {
+			t.Fatalf("Attach(%s) = %v, %v", target, added, err)
+		}
+	}
+	if added, err := taskSystem.Attach(created.ID, " /src/parser.go "); err != nil || added {
+		t.Errorf("Expected attaching twice to change nothing, got %v, %v", added, err)
+	}
+	if _, err := taskSystem.Attach(created.ID, "  "); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected an empty attachment to be invalid input, got %v", err)
+	}
+	if _, err := taskSystem.Attach(999, "/src/parser.go"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected attaching to a missing task to be not found, got %v", err)
+	}
+
+	if removed, err := taskSystem.Detach(created.ID, "/src/parser_test.go"); err != nil || !removed {
+		t.Errorf("Detach() = %v, %v", removed, err)
+	}
+	if removed, _ := taskSystem.Detach(created.ID, "/src/parser_test.go"); removed {
+		t.Error("Expected detaching twice to remove nothing")
+	}
+
+	attachments, err := taskSystem.ListAttachments(created.ID)
+	if err != nil {
+		t.Fatalf("ListAttachments() error = %v", err)
+	}
+	if len(attachments) != 2 || attachments[0].Target != "/src/parser.go" || attachments[0].IsURL() || !attachments[1].IsURL() {
+		t.Errorf("Unexpected attachments: %+v", attachments)
+	}
+
+	// Attachments go with the task
+	if err := taskSystem.HardDelete(created.ID); err != nil {
+		t.Fatalf("HardDelete() error = %v", err)
+	}
+	if attachments, _ := taskSystem.ListAttachments(created.ID); len(attachments) != 0 {
+		t.Errorf("Expected the attachments to go with the task, got %+v", attachments)
+	}
+}
+
+func TestIsURL(t *testing.T) {
+	for target, want := range map[string]bool{
+		"https://example.com/a":  true,
+		"mailto:dev@example.com": true,
+		"file:///tmp/notes.md":   true,
+		"src/parser.go":          false,
+		"/home/dev/notes.md":     false,
+		`C:\work\notes.md`:       false,
+		"C:/work/notes.md":       false,
+	} {
+		if got := IsURL(target); got != want {
+			t.Errorf("IsURL(%q) = %v, want %v", target, got, want)
+		}
+	}
+}
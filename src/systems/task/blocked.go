@@ -0,0 +1,52 @@
+package task
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// blockedByColumn selects the unfinished tasks blocking a task as a
+// comma-separated list of IDs. It follows the same directions as
+// TaskLink.Dependency: the source of a blocks link, the target of a
+// blocked_by or depends_on link. Links to other boards are not included.
+const blockedByColumn = `(SELECT COALESCE(group_concat(DISTINCT blocker.id), '')
+		FROM task_links l
+		JOIN tasks blocker ON blocker.id = CASE WHEN l.link_type = 'blocks' THEN l.from_task_id ELSE l.to_task_id END
+		WHERE ((l.link_type = 'blocks' AND l.to_task_id = tasks.id)
+			OR (l.link_type IN ('blocked_by', 'depends_on') AND l.from_task_id = tasks.id))
+			AND blocker.status != 'done' AND blocker.deleted_at IS NULL)`
+
+// splitBlockers turns the IDs selected with blockedByColumn into a sorted
+// slice
+func splitBlockers(joined string) []int {
+	if joined == "" {
+		return nil
+	}
+	var ids []int
+	for _, part := range strings.Split(joined, ",") {
+		if id, err := strconv.Atoi(part); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// IsBlocked reports whether an unfinished task still waits on another
+// unfinished task
+func (t *Task) IsBlocked() bool {
+	return t.Status != StatusDone && len(t.BlockedBy) > 0
+}
+
+// FilterBlocked keeps the blocked tasks, or with blocked false the tasks
+// that can be worked on right away
+func FilterBlocked(tasks []*Task, blocked bool) []*Task {
+	var filtered []*Task
+	for _, t := range tasks {
+		if t.IsBlocked() == blocked {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,101 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// AddBoardLink records a link between a task on this board and a task on
+// another board. An outgoing link reads "taskID <linkType> board:remoteTaskID";
+// an incoming one is its mirror, stored on the board of the target task.
+func (s *System) AddBoardLink(taskID int, board string, remoteTaskID int, linkType LinkType, incoming bool) error {
+	board = strings.TrimSpace(board)
+	if board == "" {
+		return storage.Errorf(ErrInvalidInput, "board name cannot be empty")
+	}
+	if _, err := s.GetByID(taskID); err != nil {
+		return err
+	}
+
+	_, err := s.db.ExecContext(s.ctx, `
+		INSERT INTO task_board_links (task_id, board, remote_task_id, link_type, incoming)
+		VALUES (?, ?, ?, ?, ?)
+	`, taskID, board, remoteTaskID, linkType, incoming)
+	if err != nil {
+		return fmt.Errorf("failed to create task link: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveBoardLink removes a link recorded with AddBoardLink
+func (s *System) RemoveBoardLink(taskID int, board string, remoteTaskID int, linkType LinkType, incoming bool) error {
+	result, err := s.db.ExecContext(s.ctx, `
+		DELETE FROM task_board_links
+		WHERE task_id = ? AND board = ? AND remote_task_id = ? AND link_type = ? AND incoming = ?
+	`, taskID, board, remoteTaskID, linkType, incoming)
+	if err != nil {
+		return fmt.Errorf("failed to remove task link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return storage.Errorf(ErrNotFound, "no link found between task %d and %s:%d with type %s", taskID, board, remoteTaskID, linkType)
+	}
+
+	return nil
+}
+
+// RenameBoardLinks makes the links to tasks on the board named oldName
+// point to it under newName, after the board was renamed. It returns how
+// many links changed.
+func (s *System) RenameBoardLinks(oldName, newName string) (int, error) {
+	result, err := s.db.ExecContext(s.ctx, `UPDATE task_board_links SET board = ? WHERE board = ?`, newName, oldName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename board links: %w", err)
+	}
+	renamed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to check affected rows: %w", err)
+	}
+	return int(renamed), nil
+}
+
+// listBoardLinks returns the links of a task to tasks on other boards
+func (s *System) listBoardLinks(taskID int) ([]TaskLink, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, board, remote_task_id, link_type, incoming, created_at
+		FROM task_board_links
+		WHERE task_id = ?
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []TaskLink
+	for rows.Next() {
+		var link TaskLink
+		var board string
+		var remoteTaskID int
+		var incoming bool
+		if err := rows.Scan(&link.ID, &board, &remoteTaskID, &link.LinkType, &incoming, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task link: %w", err)
+		}
+
+		if incoming {
+			link.FromBoard, link.FromTaskID, link.ToTaskID = board, remoteTaskID, taskID
+		} else {
+			link.FromTaskID, link.ToBoard, link.ToTaskID = taskID, board, remoteTaskID
+		}
+		links = append(links, link)
+	}
+
+	return links, nil
+}
@@ -0,0 +1,178 @@
+package task
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Capacity is the number of story points an assignee can take on
+type Capacity struct {
+	Assignee string `json:"assignee"`
+	Points   int    `json:"points"`
+	Load     int    `json:"load"`
+}
+
+// CapacityWarning describes an assignment that exceeds configured capacity
+type CapacityWarning struct {
+	Assignee string `json:"assignee"`
+	Capacity int    `json:"capacity"`
+	Load     int    `json:"load"`
+}
+
+// String renders the warning for CLI and MCP output
+func (w *CapacityWarning) String() string {
+	return fmt.Sprintf("%s is over capacity: %d of %d pts committed", w.Assignee, w.Load, w.Capacity)
+}
+
+// Assign sets the assignee of a task, or clears it when assignee is empty.
+// The returned warning is non-nil when the assignment pushes the assignee's
+// open estimated work past their configured capacity; the assignment is
+// still made so that the caller can decide how to react.
+func (s *System) Assign(id int, assignee string) (*CapacityWarning, error) {
+	assignee = strings.TrimSpace(assignee)
+
+	query := `
+		UPDATE tasks 
+		SET assignee = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	result, err := s.db.ExecContext(s.ctx, query, assignee, s.actor, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign task: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check update result: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return nil, storage.Errorf(ErrNotFound, "task with ID %d not found", id)
+	}
+
+	if assignee == "" {
+		return nil, nil
+	}
+
+	return s.CheckCapacity(assignee)
+}
+
+// CheckCapacity compares an assignee's open estimated work with their
+// capacity, returning a warning when it is exceeded
+func (s *System) CheckCapacity(assignee string) (*CapacityWarning, error) {
+	capacity, err := s.GetCapacity(assignee)
+	if err != nil || capacity == nil {
+		return nil, err
+	}
+
+	if capacity.Load <= capacity.Points {
+		return nil, nil
+	}
+
+	return &CapacityWarning{
+		Assignee: capacity.Assignee,
+		Capacity: capacity.Points,
+		Load:     capacity.Load,
+	}, nil
+}
+
+// SetCapacity configures how many points an assignee can take on.
+// A capacity of zero removes the limit.
+func (s *System) SetCapacity(assignee string, points int) error {
+	assignee = strings.TrimSpace(assignee)
+	if assignee == "" {
+		return storage.Errorf(ErrInvalidInput, "assignee cannot be empty")
+	}
+	if points < 0 {
+		return storage.Errorf(ErrInvalidInput, "capacity cannot be negative")
+	}
+
+	if points == 0 {
+		if _, err := s.db.ExecContext(s.ctx, `DELETE FROM capacities WHERE assignee = ?`, assignee); err != nil {
+			return fmt.Errorf("failed to remove capacity: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		INSERT INTO capacities (assignee, points) VALUES (?, ?)
+		ON CONFLICT(assignee) DO UPDATE SET points = excluded.points, updated_at = CURRENT_TIMESTAMP
+	`
+	if _, err := s.db.ExecContext(s.ctx, query, assignee, points); err != nil {
+		return fmt.Errorf("failed to set capacity: %w", err)
+	}
+
+	return nil
+}
+
+// GetCapacity returns an assignee's capacity and current load, or nil when
+// no capacity is configured for them
+func (s *System) GetCapacity(assignee string) (*Capacity, error) {
+	capacity := Capacity{Assignee: assignee}
+
+	err := s.db.QueryRowContext(s.ctx, `SELECT points FROM capacities WHERE assignee = ?`, assignee).Scan(&capacity.Points)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get capacity: %w", err)
+	}
+
+	load, err := s.assignedLoad(assignee)
+	if err != nil {
+		return nil, err
+	}
+	capacity.Load = load
+
+	return &capacity, nil
+}
+
+// ListCapacities returns every configured capacity with its current load
+func (s *System) ListCapacities() ([]Capacity, error) {
+	rows, err := s.db.QueryContext(s.ctx, `SELECT assignee, points FROM capacities ORDER BY assignee`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list capacities: %w", err)
+	}
+
+	var capacities []Capacity
+	for rows.Next() {
+		var capacity Capacity
+		if err := rows.Scan(&capacity.Assignee, &capacity.Points); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan capacity: %w", err)
+		}
+		capacities = append(capacities, capacity)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating capacities: %w", err)
+	}
+
+	for i := range capacities {
+		load, err := s.assignedLoad(capacities[i].Assignee)
+		if err != nil {
+			return nil, err
+		}
+		capacities[i].Load = load
+	}
+
+	return capacities, nil
+}
+
+// assignedLoad sums the estimates of an assignee's unfinished tasks
+func (s *System) assignedLoad(assignee string) (int, error) {
+	var load int
+	err := s.db.QueryRowContext(s.ctx, `
+		SELECT COALESCE(SUM(estimate), 0) FROM tasks
+		WHERE assignee = ? AND status != 'done' AND deleted_at IS NULL
+	`, assignee).Scan(&load)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute assigned load: %w", err)
+	}
+	return load, nil
+}
@@ -0,0 +1,84 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestAssignWarnsOverCapacity(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	if err := taskSystem.SetCapacity("alice", 5); err != nil {
+		t.Fatalf("Failed to set capacity: %v", err)
+	}
+
+	first, _ := taskSystem.Create(1, "First", "")
+	second, _ := taskSystem.Create(1, "Second", "")
+	taskSystem.UpdateEstimate(first.ID, 3)
+	taskSystem.UpdateEstimate(second.ID, 3)
+
+	warning, err := taskSystem.Assign(first.ID, "alice")
+	if err != nil {
+		t.Fatalf("Failed to assign task: %v", err)
+	}
+	if warning != nil {
+		t.Errorf("Expected no warning within capacity, got %v", warning)
+	}
+
+	warning, err = taskSystem.Assign(second.ID, "alice")
+	if err != nil {
+		t.Fatalf("Failed to assign task: %v", err)
+	}
+	if warning == nil || warning.Load != 6 || warning.Capacity != 5 {
+		t.Fatalf("Expected 6/5 capacity warning, got %v", warning)
+	}
+
+	// The assignment is still made
+	assigned, _ := taskSystem.GetByID(second.ID)
+	if assigned.Assignee != "alice" {
+		t.Errorf("Assignee = %q, want alice", assigned.Assignee)
+	}
+
+	// Finished work frees up capacity
+	if err := taskSystem.UpdateStatus(first.ID, StatusDone); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	warning, err = taskSystem.CheckCapacity("alice")
+	if err != nil || warning != nil {
+		t.Errorf("Expected no warning after completing work, got %v, %v", warning, err)
+	}
+
+	// Assignees without configured capacity never warn
+	warning, err = taskSystem.Assign(first.ID, "bob")
+	if err != nil || warning != nil {
+		t.Errorf("Expected no warning without capacity, got %v, %v", warning, err)
+	}
+}
+
+func TestSetCapacityZeroRemovesLimit(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	taskSystem.SetCapacity("alice", 5)
+	taskSystem.SetCapacity("alice", 0)
+
+	capacities, err := taskSystem.ListCapacities()
+	if err != nil {
+		t.Fatalf("Failed to list capacities: %v", err)
+	}
+	if len(capacities) != 0 {
+		t.Errorf("Expected no capacities, got %v", capacities)
+	}
+}
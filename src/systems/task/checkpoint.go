@@ -0,0 +1,83 @@
+package task
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Checkpoint is free-form working state an agent stores on a task (files
+// touched, decisions made, next steps) so a later session can resume it
+type Checkpoint struct {
+	TaskID    int       `json:"task_id"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetCheckpoint stores the working context of a task, replacing any
+// previous one
+func (s *System) SetCheckpoint(taskID int, content string) (*Checkpoint, error) {
+	if strings.TrimSpace(content) == "" {
+		return nil, storage.Errorf(ErrInvalidInput, "context cannot be empty")
+	}
+
+	if _, err := s.GetByID(taskID); err != nil {
+		return nil, err
+	}
+
+	checkpoint := Checkpoint{TaskID: taskID, Content: content}
+	err := s.db.QueryRowContext(s.ctx, `
+		INSERT INTO task_checkpoints (task_id, content) VALUES (?, ?)
+		ON CONFLICT(task_id) DO UPDATE SET content = excluded.content, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`, taskID, content).Scan(&checkpoint.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save context: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
+// AppendCheckpoint adds to the working context of a task, separating the
+// new content from the existing one with a blank line
+func (s *System) AppendCheckpoint(taskID int, content string) (*Checkpoint, error) {
+	existing, err := s.GetCheckpoint(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		content = strings.TrimRight(existing.Content, "\n") + "\n\n" + content
+	}
+	return s.SetCheckpoint(taskID, content)
+}
+
+// GetCheckpoint returns the working context of a task, or nil if none has
+// been stored
+func (s *System) GetCheckpoint(taskID int) (*Checkpoint, error) {
+	if _, err := s.GetByID(taskID); err != nil {
+		return nil, err
+	}
+
+	checkpoint := Checkpoint{TaskID: taskID}
+	err := s.db.QueryRowContext(s.ctx, `SELECT content, updated_at FROM task_checkpoints WHERE task_id = ?`, taskID).
+		Scan(&checkpoint.Content, &checkpoint.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get context: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
+// ClearCheckpoint removes the working context of a task
+func (s *System) ClearCheckpoint(taskID int) error {
+	if _, err := s.db.ExecContext(s.ctx, `DELETE FROM task_checkpoints WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("failed to clear context: %w", err)
+	}
+	return nil
+}
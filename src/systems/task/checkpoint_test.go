@@ -0,0 +1,64 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestCheckpoint(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	created, err := taskSystem.Create(1, "Port the parser", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	checkpoint, err := taskSystem.GetCheckpoint(created.ID)
+	if err != nil || checkpoint != nil {
+		t.Fatalf("Expected no context on a new task, got %v, %v", checkpoint, err)
+	}
+
+	if _, err := taskSystem.SetCheckpoint(created.ID, "Files: parser.go\n"); err != nil {
+		t.Fatalf("Failed to set context: %v", err)
+	}
+	if _, err := taskSystem.AppendCheckpoint(created.ID, "Decision: keep the old lexer"); err != nil {
+		t.Fatalf("Failed to append context: %v", err)
+	}
+
+	checkpoint, err = taskSystem.GetCheckpoint(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to get context: %v", err)
+	}
+	if want := "Files: parser.go\n\nDecision: keep the old lexer"; checkpoint.Content != want {
+		t.Errorf("Content = %q, want %q", checkpoint.Content, want)
+	}
+
+	if _, err := taskSystem.SetCheckpoint(created.ID, "Replaced"); err != nil {
+		t.Fatalf("Failed to replace context: %v", err)
+	}
+	checkpoint, _ = taskSystem.GetCheckpoint(created.ID)
+	if checkpoint.Content != "Replaced" {
+		t.Errorf("Content after set = %q, want Replaced", checkpoint.Content)
+	}
+
+	if err := taskSystem.ClearCheckpoint(created.ID); err != nil {
+		t.Fatalf("Failed to clear context: %v", err)
+	}
+	if checkpoint, _ := taskSystem.GetCheckpoint(created.ID); checkpoint != nil {
+		t.Errorf("Expected context to be cleared, got %q", checkpoint.Content)
+	}
+
+	if _, err := taskSystem.SetCheckpoint(created.ID, "   "); err == nil {
+		t.Error("Expected error for empty context")
+	}
+	if _, err := taskSystem.SetCheckpoint(999, "notes"); err == nil {
+		t.Error("Expected error for missing task")
+	}
+}
@@ -0,0 +1,120 @@
+package task
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Claim marks a task as in progress by the system's actor, moving it to
+// doing, so that agents working on the board at the same time do not pick
+// the same task. Claiming a task the actor has claimed already leaves it
+// as it is. A task claimed by someone else is refused with an ErrConflict,
+// as is a done task, which has nothing left to work on.
+func (s *System) Claim(id int) (*Task, error) {
+	if s.actor == "" {
+		return nil, storage.Errorf(ErrInvalidInput, "claiming a task needs to know who claims it")
+	}
+
+	err := s.inTx(func(tx *sql.Tx) error {
+		var status Status
+		var holder string
+		err := tx.QueryRowContext(s.ctx, `SELECT status, COALESCE(in_progress_by, '') FROM tasks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&status, &holder)
+		if err == sql.ErrNoRows {
+			return storage.Errorf(ErrNotFound, "task with id %d not found", id)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to claim task: %w", err)
+		}
+
+		switch {
+		case holder == s.actor:
+			return nil
+		case holder != "":
+			return storage.Errorf(ErrConflict, "task #%d is claimed by %s", id, holder)
+		case status == StatusDone:
+			return storage.Errorf(ErrConflict, "task #%d is done", id)
+		}
+
+		_, err = tx.ExecContext(s.ctx, `
+			UPDATE tasks
+			SET status = ?, in_progress_by = ?, claimed_at = CURRENT_TIMESTAMP,
+				updated_at = CURRENT_TIMESTAMP, updated_by = ?
+			WHERE id = ?
+		`, StatusDoing, s.actor, s.actor, id)
+		if err != nil {
+			return fmt.Errorf("failed to claim task: %w", err)
+		}
+
+		if status != StatusDoing {
+			if err := s.recordEvent(tx, id, EventStatusChanged, status, StatusDoing); err != nil {
+				return err
+			}
+		}
+		return s.refreshRollups(tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(id)
+}
+
+// Release gives up the system's actor's claim on a task, leaving it in
+// doing for the actor or someone else to claim. Releasing a task claimed
+// by someone else is refused with an ErrConflict unless force is set, for
+// claims left behind by an agent that stopped. It reports whether the task
+// was claimed.
+func (s *System) Release(id int, force bool) (bool, error) {
+	var released bool
+	err := s.inTx(func(tx *sql.Tx) error {
+		var holder string
+		err := tx.QueryRowContext(s.ctx, `SELECT COALESCE(in_progress_by, '') FROM tasks WHERE id = ? AND deleted_at IS NULL`, id).Scan(&holder)
+		if err == sql.ErrNoRows {
+			return storage.Errorf(ErrNotFound, "task with id %d not found", id)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to release task: %w", err)
+		}
+
+		switch {
+		case holder == "":
+			return nil
+		case holder != s.actor && !force:
+			return storage.Errorf(ErrConflict, "task #%d is claimed by %s, not by %s", id, holder, s.actorOrAnonymous())
+		}
+
+		_, err = tx.ExecContext(s.ctx, `
+			UPDATE tasks
+			SET in_progress_by = '', claimed_at = NULL,
+				updated_at = CURRENT_TIMESTAMP, updated_by = ?
+			WHERE id = ?
+		`, s.actor, id)
+		if err != nil {
+			return fmt.Errorf("failed to release task: %w", err)
+		}
+		released = true
+		return nil
+	})
+	return released, err
+}
+
+// actorOrAnonymous names the system's actor in messages
+func (s *System) actorOrAnonymous() string {
+	if s.actor == "" {
+		return "anonymous"
+	}
+	return s.actor
+}
+
+// unclaimed returns the tasks free for the system's actor to work on: those
+// no one else has claimed
+func (s *System) unclaimed(tasks []*Task) []*Task {
+	var free []*Task
+	for _, t := range tasks {
+		if t.InProgressBy == "" || t.InProgressBy == s.actor {
+			free = append(free, t)
+		}
+	}
+	return free
+}
@@ -0,0 +1,81 @@
+package task
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestClaim(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	tasks := New(db.Conn())
+	alpha, beta := tasks.WithActor("alpha"), tasks.WithActor("beta")
+
+	first, _ := tasks.Create(1, "Fix login", "")
+	second, _ := tasks.Create(1, "Write docs", "")
+
+	if _, err := tasks.Claim(first.ID); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected a claim without an actor to be refused, got %v", err)
+	}
+
+	claimed, err := alpha.Claim(first.ID)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claimed.InProgressBy != "alpha" || claimed.ClaimedAt == nil || claimed.Status != StatusDoing {
+		t.Errorf("Expected the task in doing, claimed by alpha, got %+v", claimed)
+	}
+	if _, err := alpha.Claim(first.ID); err != nil {
+		t.Errorf("Expected claiming a task again to succeed, got %v", err)
+	}
+	if _, err := beta.Claim(first.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected beta's claim to conflict, got %v", err)
+	}
+
+	// Next leaves alpha's task to alpha
+	pick, err := beta.Next(1, 0, time.Now())
+	if err != nil || pick == nil || pick.Task.ID != second.ID {
+		t.Errorf("Expected beta to be offered the unclaimed task, got %+v, %v", pick, err)
+	}
+	if pick, _ := alpha.Next(1, 1, time.Now()); pick == nil || pick.Task.ID != first.ID {
+		t.Errorf("Expected alpha to be offered its own task, got %+v", pick)
+	}
+
+	if _, err := beta.Release(first.ID, false); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected beta's release to conflict, got %v", err)
+	}
+	if released, err := alpha.Release(first.ID, false); err != nil || !released {
+		t.Fatalf("Release() = %v, %v", released, err)
+	}
+	if released, err := alpha.Release(first.ID, false); err != nil || released {
+		t.Errorf("Expected nothing to release twice, got %v, %v", released, err)
+	}
+	if _, err := beta.Claim(first.ID); err != nil {
+		t.Fatalf("Expected beta to claim the released task, got %v", err)
+	}
+	if released, err := alpha.Release(first.ID, true); err != nil || !released {
+		t.Errorf("Expected a forced release to succeed, got %v, %v", released, err)
+	}
+
+	// Finishing a task releases it; done tasks cannot be claimed
+	beta.Claim(first.ID)
+	if err := beta.UpdateStatus(first.ID, StatusDone); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+	if got, _ := tasks.GetByID(first.ID); got.InProgressBy != "" || got.ClaimedAt != nil {
+		t.Errorf("Expected the done task to be released, got %+v", got)
+	}
+	if _, err := alpha.Claim(first.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("Expected claiming a done task to conflict, got %v", err)
+	}
+	if _, err := alpha.Claim(999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
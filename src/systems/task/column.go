@@ -0,0 +1,65 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// ColumnNote is the definition of a column: what it means for a task to be
+// in it, such as the definition of done
+type ColumnNote struct {
+	Status    Status    `json:"status"`
+	Note      string    `json:"note"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetColumnNote sets the definition of a column, replacing any previous one.
+// An empty note removes it.
+func (s *System) SetColumnNote(status Status, note string) (*ColumnNote, error) {
+	if !IsValidStatus(string(status)) {
+		return nil, storage.Errorf(ErrInvalidInput, "invalid status: %s", status)
+	}
+
+	note = strings.TrimSpace(note)
+	if note == "" {
+		if _, err := s.db.ExecContext(s.ctx, `DELETE FROM column_notes WHERE status = ?`, status); err != nil {
+			return nil, fmt.Errorf("failed to clear column note: %w", err)
+		}
+		return nil, nil
+	}
+
+	columnNote := ColumnNote{Status: status, Note: note}
+	err := s.db.QueryRowContext(s.ctx, `
+		INSERT INTO column_notes (status, note) VALUES (?, ?)
+		ON CONFLICT(status) DO UPDATE SET note = excluded.note, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`, status, note).Scan(&columnNote.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save column note: %w", err)
+	}
+
+	return &columnNote, nil
+}
+
+// ColumnNotes returns the definitions of the columns that have one, by status
+func (s *System) ColumnNotes() (map[Status]ColumnNote, error) {
+	rows, err := s.db.QueryContext(s.ctx, `SELECT status, note, updated_at FROM column_notes`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query column notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := make(map[Status]ColumnNote)
+	for rows.Next() {
+		var n ColumnNote
+		if err := rows.Scan(&n.Status, &n.Note, &n.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan column note: %w", err)
+		}
+		notes[n.Status] = n
+	}
+
+	return notes, rows.Err()
+}
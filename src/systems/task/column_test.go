@@ -0,0 +1,53 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestColumnNotes(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	notes, err := taskSystem.ColumnNotes()
+	if err != nil {
+		t.Fatalf("Failed to get column notes: %v", err)
+	}
+	if len(notes) != 0 {
+		t.Errorf("Expected no column notes, got %v", notes)
+	}
+
+	if _, err := taskSystem.SetColumnNote(StatusDone, "Merged and deployed"); err != nil {
+		t.Fatalf("Failed to set column note: %v", err)
+	}
+	if _, err := taskSystem.SetColumnNote(StatusDone, "  Merged, deployed and announced\n"); err != nil {
+		t.Fatalf("Failed to replace column note: %v", err)
+	}
+	if _, err := taskSystem.SetColumnNote(StatusDoing, "Someone is actively on it"); err != nil {
+		t.Fatalf("Failed to set column note: %v", err)
+	}
+
+	notes, _ = taskSystem.ColumnNotes()
+	if len(notes) != 2 || notes[StatusDone].Note != "Merged, deployed and announced" {
+		t.Errorf("Unexpected column notes: %v", notes)
+	}
+
+	// An empty note clears it
+	if note, err := taskSystem.SetColumnNote(StatusDoing, ""); err != nil || note != nil {
+		t.Fatalf("Failed to clear column note: %v", err)
+	}
+	notes, _ = taskSystem.ColumnNotes()
+	if _, ok := notes[StatusDoing]; ok || len(notes) != 1 {
+		t.Errorf("Expected the doing note to be cleared, got %v", notes)
+	}
+
+	if _, err := taskSystem.SetColumnNote("review", "Waiting for review"); err == nil {
+		t.Error("Expected error for invalid status")
+	}
+}
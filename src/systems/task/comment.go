@@ -0,0 +1,68 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Comment is a note left on a task by a person or agent
+type Comment struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	Author    string    `json:"author,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddComment appends a comment to a task
+func (s *System) AddComment(taskID int, author, body string) (*Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, storage.Errorf(ErrInvalidInput, "comment cannot be empty")
+	}
+
+	if _, err := s.GetByID(taskID); err != nil {
+		return nil, err
+	}
+
+	comment := Comment{TaskID: taskID, Author: strings.TrimSpace(author), Body: body}
+	err := s.db.QueryRowContext(s.ctx, `
+		INSERT INTO task_comments (task_id, author, body) VALUES (?, ?, ?)
+		RETURNING id, created_at
+	`, taskID, comment.Author, comment.Body).Scan(&comment.ID, &comment.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	return &comment, nil
+}
+
+// ListComments returns the comments on a task, oldest first
+func (s *System) ListComments(taskID int) ([]Comment, error) {
+	query := `
+		SELECT id, task_id, COALESCE(author, ''), body, created_at
+		FROM task_comments
+		WHERE task_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := s.db.QueryContext(s.ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []Comment
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(&c.ID, &c.TaskID, &c.Author, &c.Body, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
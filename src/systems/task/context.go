@@ -0,0 +1,155 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// ContextCount is a GTD context together with the number of open tasks in it
+type ContextCount struct {
+	Context string `json:"context"`
+	Count   int    `json:"count"`
+}
+
+// IsContext reports whether an argument names a GTD context, e.g. "@home"
+func IsContext(arg string) bool {
+	return len(arg) > 1 && strings.HasPrefix(arg, "@")
+}
+
+// NormalizeContext lowercases a context and ensures its leading "@". Only
+// letters, digits, '-' and '_' are allowed after the '@'.
+func NormalizeContext(context string) (string, error) {
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(context), "@"))
+	if name == "" {
+		return "", storage.Errorf(ErrInvalidInput, "context name cannot be empty")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", storage.Errorf(ErrInvalidInput, "invalid context '%s' (use letters, digits, '-' or '_')", context)
+		}
+	}
+	return "@" + name, nil
+}
+
+// splitContexts turns the space-separated contexts selected with
+// taskColumns into a sorted slice
+func splitContexts(joined string) []string {
+	contexts := strings.Fields(joined)
+	if len(contexts) == 0 {
+		return nil
+	}
+	sort.Strings(contexts)
+	return contexts
+}
+
+// AddContext puts a task in a GTD context. Adding a context the task is
+// already in is a no-op.
+func (s *System) AddContext(id int, context string) error {
+	context, err := NormalizeContext(context)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(s.ctx, `INSERT OR IGNORE INTO task_contexts (task_id, context) VALUES (?, ?)`, id, context); err != nil {
+		return fmt.Errorf("failed to add context: %w", err)
+	}
+	return nil
+}
+
+// RemoveContext takes a task out of a GTD context
+func (s *System) RemoveContext(id int, context string) error {
+	context, err := NormalizeContext(context)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(s.ctx, `DELETE FROM task_contexts WHERE task_id = ? AND context = ?`, id, context)
+	if err != nil {
+		return fmt.Errorf("failed to remove context: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check remove result: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.Errorf(ErrNotFound, "task with ID %d is not in context %s", id, context)
+	}
+	return nil
+}
+
+// ListContexts returns the contexts used on a board with their number of
+// open (not done) tasks, sorted by name
+func (s *System) ListContexts(boardID int) ([]ContextCount, error) {
+	query := `
+		SELECT c.context, SUM(CASE WHEN t.status != 'done' THEN 1 ELSE 0 END)
+		FROM task_contexts c
+		JOIN tasks t ON t.id = c.task_id AND t.deleted_at IS NULL
+		WHERE t.board_id = ?
+		GROUP BY c.context
+		ORDER BY c.context ASC
+	`
+
+	rows, err := s.db.QueryContext(s.ctx, query, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list contexts: %w", err)
+	}
+	defer rows.Close()
+
+	var contexts []ContextCount
+	for rows.Next() {
+		var c ContextCount
+		if err := rows.Scan(&c.Context, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan context: %w", err)
+		}
+		contexts = append(contexts, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating contexts: %w", err)
+	}
+
+	return contexts, nil
+}
+
+// ListByContext retrieves the tasks of a board that are in a GTD context
+func (s *System) ListByContext(boardID int, context string) ([]*Task, error) {
+	context, err := NormalizeContext(context)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.List(boardID)
+	if err != nil {
+		return nil, err
+	}
+	return FilterByContext(tasks, context), nil
+}
+
+// FilterByContext keeps the tasks that are in the given (normalized) context
+func FilterByContext(tasks []*Task, context string) []*Task {
+	var filtered []*Task
+	for _, t := range tasks {
+		if t.HasContext(context) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// HasContext reports whether the task is in the given (normalized) context
+func (t *Task) HasContext(context string) bool {
+	for _, c := range t.Contexts {
+		if c == context {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestNormalizeContext(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"@home", "@home", false},
+		{"Deep-Work", "@deep-work", false},
+		{" @Errands ", "@errands", false},
+		{"@", "", true},
+		{"@two words", "", true},
+		{"@home!", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeContext(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeContext(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeContext(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if IsContext("@") || IsContext("home") || !IsContext("@home") {
+		t.Error("IsContext misclassified an argument")
+	}
+}
+
+func TestContexts(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	write, _ := taskSystem.Create(1, "Write design doc", "")
+	shop, _ := taskSystem.Create(1, "Buy groceries", "")
+	taskSystem.Create(1, "No context", "")
+
+	for _, c := range []string{"@deep-work", "@Home"} {
+		if err := taskSystem.AddContext(write.ID, c); err != nil {
+			t.Fatalf("Failed to add context: %v", err)
+		}
+	}
+	if err := taskSystem.AddContext(write.ID, "@home"); err != nil {
+		t.Fatalf("Adding an existing context should be a no-op: %v", err)
+	}
+	if err := taskSystem.AddContext(shop.ID, "errands"); err != nil {
+		t.Fatalf("Failed to add context: %v", err)
+	}
+	if err := taskSystem.AddContext(999, "@home"); err == nil {
+		t.Error("Expected error adding context to missing task")
+	}
+
+	got, err := taskSystem.GetByID(write.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if want := []string{"@deep-work", "@home"}; !reflect.DeepEqual(got.Contexts, want) {
+		t.Errorf("Contexts = %v, want %v", got.Contexts, want)
+	}
+
+	tasks, err := taskSystem.ListByContext(1, "@deep-work")
+	if err != nil {
+		t.Fatalf("Failed to list by context: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ID != write.ID {
+		t.Errorf("ListByContext(@deep-work) = %v, want only task %d", tasks, write.ID)
+	}
+
+	taskSystem.UpdateStatus(shop.ID, StatusDone)
+	contexts, err := taskSystem.ListContexts(1)
+	if err != nil {
+		t.Fatalf("Failed to list contexts: %v", err)
+	}
+	want := []ContextCount{{"@deep-work", 1}, {"@errands", 0}, {"@home", 1}}
+	if !reflect.DeepEqual(contexts, want) {
+		t.Errorf("ListContexts() = %v, want %v", contexts, want)
+	}
+
+	if err := taskSystem.RemoveContext(write.ID, "@home"); err != nil {
+		t.Fatalf("Failed to remove context: %v", err)
+	}
+	if err := taskSystem.RemoveContext(write.ID, "@home"); err == nil {
+		t.Error("Expected error removing a context the task is not in")
+	}
+	got, _ = taskSystem.GetByID(write.ID)
+	if want := []string{"@deep-work"}; !reflect.DeepEqual(got.Contexts, want) {
+		t.Errorf("Contexts after remove = %v, want %v", got.Contexts, want)
+	}
+}
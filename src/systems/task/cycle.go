@@ -0,0 +1,77 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Dependency returns the order a link imposes: blocker has to be finished
+// before blocked. blocked_by and depends_on links point the other way
+// round; related links impose no order.
+func (l TaskLink) Dependency() (blocker, blocked int, ok bool) {
+	switch l.LinkType {
+	case LinkTypeBlocks:
+		return l.FromTaskID, l.ToTaskID, true
+	case LinkTypeBlockedBy, LinkTypeDependsOn:
+		return l.ToTaskID, l.FromTaskID, true
+	}
+	return 0, 0, false
+}
+
+// checkCycle returns an error describing the loop a new link would close,
+// if any: adding "blocker before blocked" is circular when blocked already
+// comes, directly or transitively, before blocker
+func (s *System) checkCycle(link TaskLink) error {
+	blocker, blocked, ok := link.Dependency()
+	if !ok {
+		return nil
+	}
+
+	links, err := s.ListLinks()
+	if err != nil {
+		return err
+	}
+
+	next := make(map[int][]int)
+	for _, l := range links {
+		if from, to, ok := l.Dependency(); ok {
+			next[from] = append(next[from], to)
+		}
+	}
+
+	// Breadth-first from blocked, remembering how each task was reached
+	previous := map[int]int{blocked: 0}
+	queue := []int{blocked}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if id == blocker {
+			break
+		}
+		for _, to := range next[id] {
+			if _, seen := previous[to]; !seen {
+				previous[to] = id
+				queue = append(queue, to)
+			}
+		}
+	}
+	if _, found := previous[blocker]; !found {
+		return nil
+	}
+
+	path := []int{blocker}
+	for id := blocker; id != blocked; {
+		id = previous[id]
+		path = append([]int{id}, path...)
+	}
+	path = append([]int{blocker}, path...)
+
+	steps := make([]string, len(path))
+	for i, id := range path {
+		steps[i] = fmt.Sprintf("#%d", id)
+	}
+	return storage.Errorf(ErrInvalidInput, "cannot link tasks: this would create a circular dependency %s (each task has to be finished before the next)",
+		strings.Join(steps, " -> "))
+}
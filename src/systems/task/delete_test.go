@@ -1,6 +1,9 @@
 package task
 
 import (
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/hmain/cainban/src/systems/storage"
@@ -83,6 +86,50 @@ func TestRestoreTask(t *testing.T) {
 	}
 }
 
+func TestFindDeleted(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	if _, err := taskSystem.SetNumbering("T"); err != nil {
+		t.Fatalf("Failed to number tasks: %v", err)
+	}
+	deleted, _ := taskSystem.Create(1, "Deleted", "")
+	kept, _ := taskSystem.Create(1, "Kept", "")
+	if err := taskSystem.SoftDelete(deleted.ID); err != nil {
+		t.Fatalf("Failed to soft delete task: %v", err)
+	}
+
+	// A hash of digits alone would be taken for an ID, so the hashes are
+	// given in full
+	for _, ref := range []string{strconv.Itoa(deleted.ID), deleted.Ref, deleted.Hash, strings.ToUpper(deleted.Hash)} {
+		found, err := taskSystem.FindDeleted(ref)
+		if err != nil || found.ID != deleted.ID {
+			t.Errorf("FindDeleted(%q) = %v, %v, want task %d", ref, found, err, deleted.ID)
+		}
+	}
+	for _, ref := range []string{strconv.Itoa(kept.ID), kept.Ref, kept.Hash, "Deleted"} {
+		if _, err := taskSystem.FindDeleted(ref); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindDeleted(%q) error = %v, want ErrNotFound", ref, err)
+		}
+	}
+
+	// Restored by its hash, as `cainban restore <hash>` does
+	found, err := taskSystem.FindDeleted(deleted.Hash)
+	if err != nil {
+		t.Fatalf("FindDeleted() error = %v", err)
+	}
+	if err := taskSystem.RestoreTask(found.ID); err != nil {
+		t.Fatalf("RestoreTask() error = %v", err)
+	}
+	if _, err := taskSystem.GetByHash(deleted.Hash); err != nil {
+		t.Errorf("Expected the task restored, got %v", err)
+	}
+}
+
 func TestHardDelete(t *testing.T) {
 	db, err := storage.NewMemory()
 	if err != nil {
@@ -0,0 +1,50 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// DueSoon is how close a due date has to be for the task to be treated as
+// urgent
+const DueSoon = 48 * time.Hour
+
+// SetDue sets the due date of a task, or clears it with nil
+func (s *System) SetDue(id int, due *time.Time) error {
+	var value interface{}
+	if due != nil {
+		value = due.UTC().Truncate(time.Second)
+	}
+
+	result, err := s.db.ExecContext(s.ctx, `
+		UPDATE tasks
+		SET due_at = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, value, s.actor, id)
+	if err != nil {
+		return fmt.Errorf("failed to update task due date: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update result: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.Errorf(ErrNotFound, "task with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// IsOverdue reports whether an unfinished task is past its due date
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.Status != StatusDone && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// isDueSoon reports whether an unfinished task is overdue or due within
+// DueSoon
+func (t *Task) isDueSoon(now time.Time) bool {
+	return t.Status != StatusDone && t.DueAt != nil && t.DueAt.Before(now.Add(DueSoon))
+}
@@ -0,0 +1,38 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// The kinds of failure the task system reports, shared with the other
+// systems so callers can branch on them with errors.Is wherever they came
+// from
+var (
+	ErrNotFound     = storage.ErrNotFound
+	ErrAmbiguous    = storage.ErrAmbiguous
+	ErrInvalidInput = storage.ErrInvalidInput
+	ErrConflict     = storage.ErrConflict
+)
+
+// AmbiguousError reports a reference that matches several tasks, with the
+// best of them for the caller to offer as choices. It is an ErrAmbiguous.
+type AmbiguousError struct {
+	Query   string
+	Matches []*Task
+}
+
+func (e *AmbiguousError) Error() string {
+	suggestions := make([]string, len(e.Matches))
+	for i, match := range e.Matches {
+		suggestions[i] = fmt.Sprintf("#%d %s", match.ID, match.Title)
+	}
+	return fmt.Sprintf("multiple tasks match '%s':\n%s\nPlease be more specific or use the task ID",
+		e.Query, strings.Join(suggestions, "\n"))
+}
+
+func (e *AmbiguousError) Unwrap() error {
+	return ErrAmbiguous
+}
@@ -0,0 +1,90 @@
+package task
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestErrorKinds(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	for _, title := range []string{"Deploy the API", "Deploy the web app", "Fix bug 143"} {
+		if _, err := taskSystem.Create(1, title, ""); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		kind error
+		msg  string
+	}{
+		{"missing task", errorOf(taskSystem.GetByID(99)), ErrNotFound, "task with id 99 not found"},
+		{"no match", errorOf(taskSystem.FindTaskByFuzzyID(1, "invoice")), ErrNotFound, "no tasks found matching 'invoice'"},
+		{"several matches", errorOf(taskSystem.FindTaskByFuzzyID(1, "deploy")), ErrAmbiguous, "multiple tasks match 'deploy'"},
+		{"no exact match", errorOf(taskSystem.FindTaskExact(1, "deploy")), ErrNotFound, "no task with ID, number, hash or title 'deploy'"},
+		{"mistyped ID", errorOf(taskSystem.FindTaskExact(1, "12")), ErrNotFound, "no task found with ID 12"},
+		{"ID in a title", errorOf(taskSystem.FindTaskByFuzzyID(1, "43")), ErrNotFound, "no task found with ID 43"},
+		{"empty title", errorOf(taskSystem.Create(1, " ", "")), ErrInvalidInput, "task title cannot be empty"},
+		{"bad priority", errorOf(ParsePriority("urgent")), ErrInvalidInput, "invalid priority name: urgent"},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.kind) {
+			t.Errorf("%s: expected %v to be %q", tt.name, tt.err, tt.kind)
+		}
+		for _, other := range []error{ErrNotFound, ErrAmbiguous, ErrInvalidInput} {
+			if other != tt.kind && errors.Is(tt.err, other) {
+				t.Errorf("%s: expected %v not to be %q", tt.name, tt.err, other)
+			}
+		}
+		// The kind does not show in the message
+		if got := tt.err.Error(); !strings.HasPrefix(got, tt.msg) {
+			t.Errorf("%s: expected the message to start with %q, got %q", tt.name, tt.msg, got)
+		}
+	}
+}
+
+// errorOf drops the value of a call that should have failed
+func errorOf[T any](_ T, err error) error {
+	return err
+}
+
+func TestAmbiguousError(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	for _, title := range []string{"Deploy", "Deploy", "Deploy the API"} {
+		if _, err := taskSystem.Create(1, title, ""); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	// The candidates come with the error, best match first
+	var ambiguous *AmbiguousError
+	_, err = taskSystem.FindTaskByFuzzyID(1, "deploy")
+	if !errors.As(err, &ambiguous) || len(ambiguous.Matches) != 3 || ambiguous.Query != "deploy" {
+		t.Fatalf("Expected the three matches with the error, got %v", err)
+	}
+
+	// An exact title matches in full, but may still be shared
+	found, err := taskSystem.FindTaskExact(1, " deploy the api ")
+	if err != nil || found.Title != "Deploy the API" {
+		t.Errorf("FindTaskExact() = %v, %v, expected the task titled in full", found, err)
+	}
+	if _, err := taskSystem.FindTaskExact(1, "Deploy"); !errors.As(err, &ambiguous) || len(ambiguous.Matches) != 2 {
+		t.Errorf("Expected the two tasks of the same title to be ambiguous, got %v", err)
+	}
+}
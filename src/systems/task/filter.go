@@ -0,0 +1,260 @@
+package task
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Filter selects tasks with a filter expression: conditions separated by
+// spaces, all of which must hold, such as
+//
+//	status=todo,doing tag=client-a priority>=high
+//
+// Keys are status, priority, estimate, assignee, tag (or context), title,
+// parent, blocked and overdue. Each condition compares with =, !=, <, <=, >
+// or >=; the ordering operators only apply to priority and estimate. A
+// comma separates values of which any may match, and a value with spaces
+// is quoted: title="login page". An empty expression matches every task.
+type Filter struct {
+	conditions []condition
+}
+
+// condition is one key, operator and set of values of a filter expression
+type condition struct {
+	key    string
+	op     string
+	values []string
+}
+
+// filterKeys lists the keys a filter expression can test
+var filterKeys = map[string]bool{
+	"status": true, "priority": true, "estimate": true, "assignee": true,
+	"tag": true, "title": true, "parent": true, "blocked": true, "overdue": true,
+}
+
+// filterOperators are tried longest first so that ">=" is not read as ">"
+var filterOperators = []string{"!=", ">=", "<=", "=", ">", "<"}
+
+// ParseFilter parses a filter expression
+func ParseFilter(expr string) (*Filter, error) {
+	terms, err := splitFilterTerms(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &Filter{}
+	for _, term := range terms {
+		c, err := parseCondition(term)
+		if err != nil {
+			return nil, err
+		}
+		f.conditions = append(f.conditions, c)
+	}
+	return f, nil
+}
+
+// splitFilterTerms splits an expression on spaces outside double quotes,
+// dropping the quotes
+func splitFilterTerms(expr string) ([]string, error) {
+	var terms []string
+	var term strings.Builder
+	quoted, started := false, false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			started = true
+		case r == ' ' && !quoted:
+			if started {
+				terms = append(terms, term.String())
+				term.Reset()
+				started = false
+			}
+		default:
+			term.WriteRune(r)
+			started = true
+		}
+	}
+	if quoted {
+		return nil, storage.Errorf(ErrInvalidInput, "unterminated quote in filter %q", expr)
+	}
+	if started {
+		terms = append(terms, term.String())
+	}
+	return terms, nil
+}
+
+// parseCondition parses and validates one key/operator/value term
+func parseCondition(term string) (condition, error) {
+	// The first operator in the term splits it; at the same position the
+	// longer operator wins, as it comes first in filterOperators
+	at, op := -1, ""
+	for _, candidate := range filterOperators {
+		if i := strings.Index(term, candidate); i > 0 && (at < 0 || i < at) {
+			at, op = i, candidate
+		}
+	}
+	if at < 0 {
+		return condition{}, storage.Errorf(ErrInvalidInput, "invalid filter %q (expected key=value)", term)
+	}
+
+	key := strings.ToLower(strings.TrimSpace(term[:at]))
+	if key == "context" {
+		key = "tag"
+	}
+	if !filterKeys[key] {
+		return condition{}, storage.Errorf(ErrInvalidInput, "unknown filter key %q (use status, priority, estimate, assignee, tag, title, parent, blocked or overdue)", key)
+	}
+
+	c := condition{key: key, op: op}
+	for _, value := range strings.Split(term[at+len(op):], ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			c.values = append(c.values, value)
+		}
+	}
+	if len(c.values) == 0 {
+		return condition{}, storage.Errorf(ErrInvalidInput, "filter %q has no value", term)
+	}
+
+	ordered := op != "=" && op != "!="
+	if ordered && (key != "priority" && key != "estimate" || len(c.values) > 1) {
+		return condition{}, storage.Errorf(ErrInvalidInput, "filter %q: %s only compares a single priority or estimate", term, op)
+	}
+
+	// Normalize the values now so that invalid ones are reported up front
+	for i, value := range c.values {
+		switch key {
+		case "status":
+			if !IsValidStatus(value) {
+				return condition{}, storage.Errorf(ErrInvalidInput, "invalid status %q in filter", value)
+			}
+		case "priority":
+			level, err := parseFilterPriority(value)
+			if err != nil {
+				return condition{}, err
+			}
+			c.values[i] = strconv.Itoa(level)
+		case "estimate", "parent":
+			if _, err := strconv.Atoi(value); err != nil && !(key == "parent" && value == "none") {
+				return condition{}, storage.Errorf(ErrInvalidInput, "invalid %s %q in filter", key, value)
+			}
+		case "tag":
+			context, err := NormalizeContext(value)
+			if err != nil {
+				return condition{}, err
+			}
+			c.values[i] = context
+		case "blocked", "overdue":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return condition{}, storage.Errorf(ErrInvalidInput, "invalid %s %q in filter (use true or false)", key, value)
+			}
+			c.values[i] = strconv.FormatBool(b)
+		}
+	}
+	return c, nil
+}
+
+// parseFilterPriority accepts a priority name or level
+func parseFilterPriority(value string) (int, error) {
+	if level, err := strconv.Atoi(value); err == nil {
+		return ParsePriority(level)
+	}
+	return ParsePriority(value)
+}
+
+// Match reports whether a task meets every condition of the filter
+func (f *Filter) Match(t *Task, now time.Time) bool {
+	for _, c := range f.conditions {
+		if !c.match(t, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply keeps the tasks that match the filter
+func (f *Filter) Apply(tasks []*Task, now time.Time) []*Task {
+	var filtered []*Task
+	for _, t := range tasks {
+		if f.Match(t, now) {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// match tests one condition against a task
+func (c condition) match(t *Task, now time.Time) bool {
+	switch c.key {
+	case "priority":
+		return c.compare(t.Priority)
+	case "estimate":
+		return c.compare(t.Estimate)
+	}
+
+	var actual []string
+	switch c.key {
+	case "title":
+		actual = []string{strings.ToLower(t.Title)}
+	case "status":
+		actual = []string{string(t.Status)}
+	case "assignee":
+		actual = []string{t.Assignee}
+		if t.Assignee == "" {
+			actual = []string{"none"}
+		}
+	case "tag":
+		actual = t.Contexts
+	case "parent":
+		actual = []string{"none"}
+		if t.ParentID != nil {
+			actual = []string{strconv.Itoa(*t.ParentID)}
+		}
+	case "blocked":
+		actual = []string{strconv.FormatBool(t.IsBlocked())}
+	case "overdue":
+		actual = []string{strconv.FormatBool(t.IsOverdue(now))}
+	}
+
+	found := false
+	for _, value := range c.values {
+		for _, a := range actual {
+			if c.key == "title" && strings.Contains(a, strings.ToLower(value)) || strings.EqualFold(a, value) {
+				found = true
+			}
+		}
+	}
+	if c.op == "!=" {
+		return !found
+	}
+	return found
+}
+
+// compare tests a numeric condition; its values were validated as numbers
+func (c condition) compare(actual int) bool {
+	if c.op == "=" || c.op == "!=" {
+		found := false
+		for _, value := range c.values {
+			if n, _ := strconv.Atoi(value); n == actual {
+				found = true
+			}
+		}
+		return found == (c.op == "=")
+	}
+
+	n, _ := strconv.Atoi(c.values[0])
+	switch c.op {
+	case "<":
+		return actual < n
+	case "<=":
+		return actual <= n
+	case ">":
+		return actual > n
+	default:
+		return actual >= n
+	}
+}
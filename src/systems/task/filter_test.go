@@ -0,0 +1,132 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFilter_Invalid(t *testing.T) {
+	invalid := []string{
+		"client-a",
+		"color=red",
+		"status=later",
+		"priority=urgent",
+		"title>abc",
+		"priority>=low,high",
+		"estimate=lots",
+		"blocked=maybe",
+		"tag=",
+		`title="unterminated`,
+	}
+	for _, expr := range invalid {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("ParseFilter(%q) succeeded, expected an error", expr)
+		}
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
+	parent := 1
+
+	login := &Task{Title: "Fix login page", Status: StatusTodo, Priority: PriorityHigh, Estimate: 3,
+		Assignee: "alice", Contexts: []string{"@client-a", "@work"}, DueAt: &yesterday}
+	report := &Task{Title: "Monthly report", Status: StatusDoing, Priority: PriorityLow,
+		Contexts: []string{"@client-b"}, ParentID: &parent, BlockedBy: []int{4}}
+	done := &Task{Title: "Login audit", Status: StatusDone, Priority: PriorityMedium, Estimate: 5}
+	tasks := []*Task{login, report, done}
+
+	tests := []struct {
+		expr string
+		want []*Task
+	}{
+		{"", tasks},
+		{"tag=client-a", []*Task{login}},
+		{"context=@CLIENT-B", []*Task{report}},
+		{"tag!=client-a", []*Task{report, done}},
+		{"status=todo,doing", []*Task{login, report}},
+		{"priority>=medium", []*Task{login, done}},
+		{"priority<2", []*Task{report}},
+		{"priority=3,1", []*Task{login, report}},
+		{"estimate>3", []*Task{done}},
+		{"estimate=0", []*Task{report}},
+		{"assignee=none", []*Task{report, done}},
+		{"assignee=Alice", []*Task{login}},
+		{"title=login", []*Task{login, done}},
+		{`title="login page"`, []*Task{login}},
+		{"parent=1", []*Task{report}},
+		{"parent=none", []*Task{login, done}},
+		{"blocked=true", []*Task{report}},
+		{"overdue=true", []*Task{login}},
+		{"title=login status!=done", []*Task{login}},
+	}
+
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Errorf("ParseFilter(%q): %v", tt.expr, err)
+			continue
+		}
+		got := f.Apply(tasks, now)
+		if len(got) != len(tt.want) {
+			t.Errorf("%q matched %d tasks, expected %d", tt.expr, len(got), len(tt.want))
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%q matched %q, expected %q", tt.expr, got[i].Title, tt.want[i].Title)
+			}
+		}
+	}
+}
+
+func FuzzParseFilter(f *testing.F) {
+	for _, seed := range []string{
+		"", "tag=client-a", "status=todo,doing", "priority>=medium", "estimate<=3",
+		`title="login page" assignee=none`, "parent=1", "blocked=false overdue=true",
+		`title="a\"b"`, "priority=3,1", "tag!=@work,@home",
+	} {
+		f.Add(seed)
+	}
+
+	now := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+	due := now.Add(-time.Hour)
+	parent := 1
+	tasks := []*Task{
+		{ID: 1, Title: "Fix login page", Status: StatusTodo, Priority: PriorityHigh, Estimate: 3,
+			Assignee: "alice", Contexts: []string{"@client-a"}, DueAt: &due},
+		{ID: 2, Title: "Monthly report", Status: StatusDoing, ParentID: &parent, BlockedBy: []int{1}},
+		{ID: 3, Title: "", Status: StatusDone, Priority: PriorityCritical},
+	}
+
+	f.Fuzz(func(t *testing.T, expr string) {
+		filter, err := ParseFilter(expr)
+		if err != nil {
+			return
+		}
+
+		// Apply keeps exactly the tasks that match
+		matched := filter.Apply(tasks, now)
+		var want []*Task
+		for _, task := range tasks {
+			if filter.Match(task, now) {
+				want = append(want, task)
+			}
+		}
+		if len(matched) != len(want) {
+			t.Fatalf("%q: Apply kept %d tasks, Match accepts %d", expr, len(matched), len(want))
+		}
+
+		// Conditions are ANDed, so repeating the expression changes nothing
+		twice, err := ParseFilter(expr + " " + expr)
+		if err != nil {
+			t.Fatalf("%q parses, but not twice in a row: %v", expr, err)
+		}
+		for _, task := range tasks {
+			if twice.Match(task, now) != filter.Match(task, now) {
+				t.Errorf("%q repeated matches task #%d differently", expr, task.ID)
+			}
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Handoff records one agent passing a task to another
+type Handoff struct {
+	Task            *Task            `json:"task"`
+	From            string           `json:"from,omitempty"`
+	To              string           `json:"to"`
+	Note            string           `json:"note,omitempty"`
+	Comment         *Comment         `json:"comment"`
+	CapacityWarning *CapacityWarning `json:"capacity_warning,omitempty"`
+}
+
+// Handoff reassigns a task to another agent and leaves a comment, authored
+// by the previous assignee, carrying the context note for the new one
+func (s *System) Handoff(id int, to, note string) (*Handoff, error) {
+	to = strings.TrimSpace(to)
+	if to == "" {
+		return nil, storage.Errorf(ErrInvalidInput, "handoff target cannot be empty")
+	}
+
+	t, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	from := t.Assignee
+
+	warning, err := s.Assign(id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("Handed off to @%s", to)
+	if from != "" {
+		body = fmt.Sprintf("Handed off from @%s to @%s", from, to)
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		body += ": " + note
+	}
+
+	comment, err := s.AddComment(id, from, body)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Assignee = to
+	return &Handoff{
+		Task:            t,
+		From:            from,
+		To:              to,
+		Note:            note,
+		Comment:         comment,
+		CapacityWarning: warning,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestHandoff(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	created, err := taskSystem.Create(1, "Migrate auth", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if _, err := taskSystem.Assign(created.ID, "planner"); err != nil {
+		t.Fatalf("Failed to assign task: %v", err)
+	}
+
+	handoff, err := taskSystem.Handoff(created.ID, "coder", "schema done, handlers next")
+	if err != nil {
+		t.Fatalf("Failed to hand off task: %v", err)
+	}
+	if handoff.From != "planner" || handoff.To != "coder" || handoff.Task.Assignee != "coder" {
+		t.Errorf("Unexpected handoff: %+v", handoff)
+	}
+
+	got, _ := taskSystem.GetByID(created.ID)
+	if got.Assignee != "coder" {
+		t.Errorf("Assignee = %q, want coder", got.Assignee)
+	}
+
+	comments, err := taskSystem.ListComments(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to list comments: %v", err)
+	}
+	if len(comments) != 1 {
+		t.Fatalf("Expected 1 comment, got %d", len(comments))
+	}
+	if comments[0].Author != "planner" || !strings.Contains(comments[0].Body, "schema done, handlers next") {
+		t.Errorf("Unexpected comment: %+v", comments[0])
+	}
+
+	if _, err := taskSystem.Handoff(created.ID, " ", "note"); err == nil {
+		t.Error("Expected error handing off to nobody")
+	}
+	if _, err := taskSystem.Handoff(999, "coder", ""); err == nil {
+		t.Error("Expected error handing off missing task")
+	}
+	if _, err := taskSystem.AddComment(created.ID, "coder", "  "); err == nil {
+		t.Error("Expected error adding empty comment")
+	}
+}
@@ -0,0 +1,136 @@
+package task
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// newHash is the SQL expression that draws a task's hash: 16 random hex
+// digits. Unlike the ID, which counts up on each board and so collides
+// between boards, the hash stays with the task when it is exported and
+// imported elsewhere.
+const newHash = `lower(hex(randomblob(8)))`
+
+// ShortHashLength is how many digits of its hash are shown for a task, and
+// the fewest a lookup by hash accepts
+const ShortHashLength = 7
+
+var (
+	hashPattern     = regexp.MustCompile(`^[0-9a-fA-F]{7,16}$`)
+	fullHashPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+)
+
+// ShortHash returns the start of the task's hash that is shown for it, e.g.
+// 3f9a2c1, or "" for a task without one
+func (t *Task) ShortHash() string {
+	if len(t.Hash) < ShortHashLength {
+		return t.Hash
+	}
+	return t.Hash[:ShortHashLength]
+}
+
+// IsHash reports whether ref has the form of a task hash: ShortHashLength
+// to 16 hex digits
+func IsHash(ref string) bool {
+	return hashPattern.MatchString(ref)
+}
+
+// GetByHash retrieves a task by its hash or the start of it, e.g. 3f9a2c1
+func (s *System) GetByHash(hash string) (*Task, error) {
+	if !IsHash(hash) {
+		return nil, storage.Errorf(ErrInvalidInput, "'%s' is not a task hash", hash)
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks
+		WHERE hash LIKE ? AND deleted_at IS NULL
+		ORDER BY id LIMIT 5`
+	rows, err := s.db.QueryContext(s.ctx, query, strings.ToLower(hash)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		matches = append(matches, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, storage.Errorf(ErrNotFound, "task %s not found", hash)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, &AmbiguousError{Query: hash, Matches: matches}
+}
+
+// SetHash gives a task the hash it has on another board, so that a task
+// imported from there is recognized as the same task. The hash must be in
+// full and not belong to another task.
+func (s *System) SetHash(id int, hash string) error {
+	hash = strings.ToLower(hash)
+	if !fullHashPattern.MatchString(hash) {
+		return storage.Errorf(ErrInvalidInput, "'%s' is not a full task hash of 16 hex digits", hash)
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		var owner int
+		err := tx.QueryRowContext(s.ctx, `SELECT id FROM tasks WHERE hash = ?`, hash).Scan(&owner)
+		switch {
+		case err == nil && owner != id:
+			return storage.Errorf(ErrInvalidInput, "hash %s belongs to task #%d", hash, owner)
+		case err != nil && err != sql.ErrNoRows:
+			return fmt.Errorf("failed to check task hash: %w", err)
+		}
+
+		result, err := tx.ExecContext(s.ctx, `UPDATE tasks SET hash = ? WHERE id = ? AND deleted_at IS NULL`, hash, id)
+		if err != nil {
+			return fmt.Errorf("failed to set task hash: %w", err)
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			return storage.Errorf(ErrNotFound, "task with ID %d not found", id)
+		}
+		return nil
+	})
+}
+
+// HashOwner returns the ID of the task with the full hash, in the trash or
+// not, or 0 when no task has it
+func (s *System) HashOwner(hash string) (int, error) {
+	var id int
+	err := s.db.QueryRowContext(s.ctx, `SELECT id FROM tasks WHERE hash = ?`, strings.ToLower(hash)).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up task hash: %w", err)
+	}
+	return id, nil
+}
+
+// findByHash looks ref up as a hash for the lookups that try it among other
+// kinds of reference. It returns nil and no error when ref is not a hash or
+// no task has it, so the caller goes on to the next kind, and an
+// *AmbiguousError when it starts the hashes of several tasks.
+func (s *System) findByHash(ref string) (*Task, error) {
+	if !IsHash(ref) {
+		return nil, nil
+	}
+	task, err := s.GetByHash(ref)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return task, err
+}
@@ -0,0 +1,78 @@
+package task
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestTaskHashes(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	first, err := taskSystem.Create(1, "Write the docs", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	second, err := taskSystem.Create(1, "Ship it", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	if !fullHashPattern.MatchString(first.Hash) || first.Hash == second.Hash {
+		t.Fatalf("Expected two different 16-digit hashes, got %q and %q", first.Hash, second.Hash)
+	}
+	if got := first.ShortHash(); len(got) != ShortHashLength || got != first.Hash[:ShortHashLength] {
+		t.Errorf("ShortHash() = %q for hash %q", got, first.Hash)
+	}
+
+	// The hash is read back with the task and finds it, short or in full
+	if got, _ := taskSystem.GetByID(first.ID); got.Hash != first.Hash {
+		t.Errorf("Expected the hash to be stored, got %q", got.Hash)
+	}
+	for _, ref := range []string{first.ShortHash(), first.Hash} {
+		if got, err := taskSystem.GetByHash(ref); err != nil || got.ID != first.ID {
+			t.Errorf("GetByHash(%q) = %v, %v", ref, got, err)
+		}
+		if got, err := taskSystem.FindTaskByFuzzyID(1, ref); err != nil || got.ID != first.ID {
+			t.Errorf("FindTaskByFuzzyID(%q) = %v, %v", ref, got, err)
+		}
+		if got, err := taskSystem.FindTaskExact(1, ref); err != nil || got.ID != first.ID {
+			t.Errorf("FindTaskExact(%q) = %v, %v", ref, got, err)
+		}
+	}
+	if _, err := taskSystem.GetByHash("abc"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected a too short hash to be refused, got %v", err)
+	}
+
+	// A hash from another board is taken over, but not one in use here
+	const imported = "00112233445566ff"
+	if err := taskSystem.SetHash(second.ID, "00112233445566FF"); err != nil {
+		t.Fatalf("SetHash failed: %v", err)
+	}
+	if got, err := taskSystem.GetByHash("0011223"); err != nil || got.ID != second.ID || got.Hash != imported {
+		t.Errorf("Expected the imported hash to find the task, got %v, %v", got, err)
+	}
+	if err := taskSystem.SetHash(first.ID, imported); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected a hash in use to be refused, got %v", err)
+	}
+	if err := taskSystem.SetHash(first.ID, "0011"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected a partial hash to be refused, got %v", err)
+	}
+
+	// A number is an ID, even where a hash is all digits; GetByHash finds it
+	if err := taskSystem.SetHash(first.ID, "1234567890abcdef"); err != nil {
+		t.Fatalf("SetHash failed: %v", err)
+	}
+	if _, err := taskSystem.FindTaskExact(1, "1234567"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a number that is no ID not to be found, got %v", err)
+	}
+	if got, err := taskSystem.GetByHash("1234567"); err != nil || got.ID != first.ID {
+		t.Errorf("Expected a numeric hash to be found, got %v, %v", got, err)
+	}
+}
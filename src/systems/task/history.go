@@ -0,0 +1,69 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// EventType identifies a recorded change in a task's lifecycle
+type EventType string
+
+const (
+	EventCreated       EventType = "created"
+	EventStatusChanged EventType = "status_changed"
+)
+
+// Event is an entry in a task's audit trail
+type Event struct {
+	ID         int       `json:"id"`
+	TaskID     int       `json:"task_id"`
+	Type       EventType `json:"event_type"`
+	FromStatus Status    `json:"from_status,omitempty"`
+	ToStatus   Status    `json:"to_status,omitempty"`
+	Actor      string    `json:"actor,omitempty"` // who made the change, see WithActor
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// dbExecer is satisfied by both *sql.DB and *sql.Tx
+type dbExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordEvent appends an entry to the task_events audit trail
+func (s *System) recordEvent(db dbExecer, taskID int, eventType EventType, from, to Status) error {
+	query := `INSERT INTO task_events (task_id, event_type, from_status, to_status, actor) VALUES (?, ?, ?, ?, ?)`
+	if _, err := db.ExecContext(s.ctx, query, taskID, eventType, string(from), string(to), s.actor); err != nil {
+		return fmt.Errorf("failed to record task event: %w", err)
+	}
+	return nil
+}
+
+// GetHistory returns the audit trail for a task, oldest first
+func (s *System) GetHistory(taskID int) ([]Event, error) {
+	query := `
+		SELECT id, task_id, event_type, COALESCE(from_status, ''), COALESCE(to_status, ''), COALESCE(actor, ''), created_at
+		FROM task_events
+		WHERE task_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := s.db.QueryContext(s.ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task history: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		err := rows.Scan(&event.ID, &event.TaskID, &event.Type, &event.FromStatus, &event.ToStatus, &event.Actor, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
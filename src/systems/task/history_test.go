@@ -0,0 +1,117 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestHistoryRecordsStatusChanges(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	task, err := taskSystem.Create(1, "Tracked task", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	for _, status := range []Status{StatusDoing, StatusDoing, StatusDone} {
+		if err := taskSystem.UpdateStatus(task.ID, status); err != nil {
+			t.Fatalf("Failed to update status: %v", err)
+		}
+	}
+
+	events, err := taskSystem.GetHistory(task.ID)
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+
+	// Moving to the status a task already has is not recorded
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d: %+v", len(events), events)
+	}
+
+	if events[0].Type != EventCreated || events[0].ToStatus != StatusTodo {
+		t.Errorf("First event = %+v, want created -> todo", events[0])
+	}
+	if events[2].FromStatus != StatusDoing || events[2].ToStatus != StatusDone {
+		t.Errorf("Last event = %+v, want doing -> done", events[2])
+	}
+}
+
+func TestHistoryRecordsWhoChangedWhat(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	alice := New(db.Conn()).WithActor("alice")
+	agent := alice.WithActor("claude-code")
+
+	created, err := alice.Create(1, "Shared task", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if created.CreatedBy != "alice" {
+		t.Errorf("CreatedBy = %q, want alice", created.CreatedBy)
+	}
+	if err := agent.UpdateStatus(created.ID, StatusDoing); err != nil {
+		t.Fatalf("Failed to update status: %v", err)
+	}
+	if err := agent.UpdatePriority(created.ID, PriorityHigh); err != nil {
+		t.Fatalf("Failed to update priority: %v", err)
+	}
+
+	got, _ := alice.GetByID(created.ID)
+	if got.CreatedBy != "alice" || got.UpdatedBy != "claude-code" {
+		t.Errorf("CreatedBy, UpdatedBy = %q, %q, want alice and claude-code", got.CreatedBy, got.UpdatedBy)
+	}
+
+	events, err := alice.GetHistory(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(events) != 2 || events[0].Actor != "alice" || events[1].Actor != "claude-code" {
+		t.Errorf("Expected the creation by alice and the move by claude-code, got %+v", events)
+	}
+}
+
+func TestUpdateEstimate(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	task, err := taskSystem.Create(1, "Estimated task", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	if err := taskSystem.UpdateEstimate(task.ID, 5); err != nil {
+		t.Fatalf("Failed to update estimate: %v", err)
+	}
+
+	updated, err := taskSystem.GetByID(task.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if updated.Estimate != 5 {
+		t.Errorf("Estimate = %d, want 5", updated.Estimate)
+	}
+
+	if err := taskSystem.UpdateEstimate(task.ID, -1); err == nil {
+		t.Error("Expected error for negative estimate")
+	}
+	if err := taskSystem.UpdateEstimate(999, 3); err == nil {
+		t.Error("Expected error for non-existent task")
+	}
+}
@@ -0,0 +1,223 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// prefixColumn selects the board's task number prefix, empty while
+// numbering is off
+const prefixColumn = `COALESCE((SELECT prefix FROM task_numbering WHERE task_numbering.id = 1), '')`
+
+var (
+	prefixPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{0,9}$`)
+	refPattern    = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9]*)-([0-9]+)$`)
+)
+
+// IDRange is a run of consecutive task IDs
+type IDRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+func (r IDRange) String() string {
+	if r.From == r.To {
+		return strconv.Itoa(r.From)
+	}
+	return fmt.Sprintf("%d-%d", r.From, r.To)
+}
+
+// IDReport accounts for every task ID a board has handed out. SQLite never
+// reuses an ID within a database, so a task that is gone for good leaves a
+// gap; one in the trash keeps its ID until it is purged.
+type IDReport struct {
+	Tasks   int `json:"tasks"`   // tasks on the board, trashed ones included
+	Highest int `json:"highest"` // highest ID in use, 0 without tasks
+	Next    int `json:"next"`    // ID the next task gets
+	// Gaps are the IDs of tasks deleted for good
+	Gaps []IDRange `json:"gaps"`
+	// Trashed are the IDs of soft-deleted tasks, which can be restored
+	Trashed []int `json:"trashed"`
+
+	// Prefix and NextNumber describe task numbering, empty and 0 while off
+	Prefix     string `json:"prefix,omitempty"`
+	NextNumber int    `json:"next_number,omitempty"`
+}
+
+// NextRef is the number the next task gets, e.g. T-008, or "" while
+// numbering is off
+func (r *IDReport) NextRef() string {
+	return FormatRef(r.Prefix, r.NextNumber)
+}
+
+// IDs reports how the board's task IDs have been used
+func (s *System) IDs() (*IDReport, error) {
+	rows, err := s.db.QueryContext(s.ctx, `SELECT id, deleted_at IS NOT NULL FROM tasks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task IDs: %w", err)
+	}
+	defer rows.Close()
+
+	report := &IDReport{Gaps: []IDRange{}, Trashed: []int{}}
+	for rows.Next() {
+		var id int
+		var trashed bool
+		if err := rows.Scan(&id, &trashed); err != nil {
+			return nil, fmt.Errorf("failed to list task IDs: %w", err)
+		}
+		if id > report.Highest+1 {
+			report.Gaps = append(report.Gaps, IDRange{From: report.Highest + 1, To: id - 1})
+		}
+		if trashed {
+			report.Trashed = append(report.Trashed, id)
+		}
+		report.Tasks++
+		report.Highest = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list task IDs: %w", err)
+	}
+
+	// sqlite_sequence remembers IDs handed out past the highest one still
+	// in use; it has no row before the first task
+	var sequence int
+	err = s.db.QueryRowContext(s.ctx, `SELECT seq FROM sqlite_sequence WHERE name = 'tasks'`).Scan(&sequence)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to read the task ID sequence: %w", err)
+	}
+	if sequence > report.Highest {
+		report.Gaps = append(report.Gaps, IDRange{From: report.Highest + 1, To: sequence})
+	} else {
+		sequence = report.Highest
+	}
+	report.Next = sequence + 1
+
+	err = s.db.QueryRowContext(s.ctx, `SELECT prefix, next_number FROM task_numbering WHERE id = 1`).
+		Scan(&report.Prefix, &report.NextNumber)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to read task numbering: %w", err)
+	}
+	return report, nil
+}
+
+// SetNumbering turns on human-friendly task numbers such as T-001, or
+// changes their prefix. Numbers belong to the board database, so they stay
+// the same through bundle export and import where raw IDs may not. The
+// first time, existing tasks are numbered in ID order, trashed ones too, so
+// no number is ever handed out twice. It returns how many tasks it numbered.
+func (s *System) SetNumbering(prefix string) (int, error) {
+	if !prefixPattern.MatchString(prefix) {
+		return 0, storage.Errorf(ErrInvalidInput, "invalid prefix %q: use a letter followed by up to 9 letters or digits", prefix)
+	}
+	prefix = strings.ToUpper(prefix)
+
+	var numbered int
+	err := s.inTx(func(tx *sql.Tx) error {
+		var next int
+		err := tx.QueryRowContext(s.ctx, `SELECT next_number FROM task_numbering WHERE id = 1`).Scan(&next)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to read task numbering: %w", err)
+		}
+		if err == sql.ErrNoRows {
+			if err := tx.QueryRowContext(s.ctx, `SELECT COALESCE(MAX(number), 0) + 1 FROM tasks`).Scan(&next); err != nil {
+				return fmt.Errorf("failed to read task numbers: %w", err)
+			}
+		}
+
+		rows, err := tx.QueryContext(s.ctx, `SELECT id FROM tasks WHERE number = 0 OR number IS NULL ORDER BY id`)
+		if err != nil {
+			return fmt.Errorf("failed to list unnumbered tasks: %w", err)
+		}
+		var unnumbered []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to list unnumbered tasks: %w", err)
+			}
+			unnumbered = append(unnumbered, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to list unnumbered tasks: %w", err)
+		}
+
+		for _, id := range unnumbered {
+			if _, err := tx.ExecContext(s.ctx, `UPDATE tasks SET number = ? WHERE id = ?`, next, id); err != nil {
+				return fmt.Errorf("failed to number task #%d: %w", id, err)
+			}
+			next++
+		}
+
+		_, err = tx.ExecContext(s.ctx, `
+			INSERT INTO task_numbering (id, prefix, next_number) VALUES (1, ?, ?)
+			ON CONFLICT(id) DO UPDATE SET prefix = excluded.prefix, next_number = excluded.next_number
+		`, prefix, next)
+		if err != nil {
+			return fmt.Errorf("failed to save task numbering: %w", err)
+		}
+
+		numbered = len(unnumbered)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return numbered, nil
+}
+
+// GetByRef retrieves a task by its number, e.g. T-007. The prefix must be
+// the board's, in any case.
+func (s *System) GetByRef(ref string) (*Task, error) {
+	match := refPattern.FindStringSubmatch(ref)
+	if match == nil {
+		return nil, storage.Errorf(ErrInvalidInput, "'%s' is not a task number", ref)
+	}
+	number, err := strconv.Atoi(match[2])
+	if err != nil || number == 0 {
+		return nil, storage.Errorf(ErrInvalidInput, "'%s' is not a task number", ref)
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE number = ? AND deleted_at IS NULL`
+	task, err := scanTask(s.db.QueryRowContext(s.ctx, query, number))
+	if err == sql.ErrNoRows || (err == nil && !strings.EqualFold(task.Ref, FormatRef(match[1], number))) {
+		return nil, storage.Errorf(ErrNotFound, "task %s not found", ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task: %w", err)
+	}
+	return task, nil
+}
+
+// takeNumber hands out the next task number within the transaction
+// creating a task, or 0 while numbering is off
+func takeNumber(ctx context.Context, tx *sql.Tx) (int, string, error) {
+	var number int
+	var prefix string
+	err := tx.QueryRowContext(ctx, `
+		UPDATE task_numbering SET next_number = next_number + 1 WHERE id = 1
+		RETURNING next_number - 1, prefix
+	`).Scan(&number, &prefix)
+	if err == sql.ErrNoRows {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to number task: %w", err)
+	}
+	return number, prefix, nil
+}
+
+// FormatRef renders a task number with its prefix, zero-padded to three
+// digits: T-007. It returns "" for unnumbered tasks or without a prefix.
+func FormatRef(prefix string, number int) string {
+	if prefix == "" || number <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s-%03d", prefix, number)
+}
@@ -0,0 +1,142 @@
+package task
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestIDs(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	report, err := taskSystem.IDs()
+	if err != nil {
+		t.Fatalf("IDs() on an empty board: %v", err)
+	}
+	if report.Highest != 0 || report.Next != 1 || len(report.Gaps) != 0 {
+		t.Errorf("empty board: highest %d, next %d, gaps %v; want 0, 1, none", report.Highest, report.Next, report.Gaps)
+	}
+
+	var ids []int
+	for _, title := range []string{"One", "Two", "Three", "Four", "Five", "Six"} {
+		created, err := taskSystem.Create(1, title, "")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		ids = append(ids, created.ID)
+	}
+	for _, i := range []int{1, 2, 5} {
+		if err := taskSystem.HardDelete(ids[i]); err != nil {
+			t.Fatalf("HardDelete(%d): %v", ids[i], err)
+		}
+	}
+	if err := taskSystem.SoftDelete(ids[3]); err != nil {
+		t.Fatalf("SoftDelete(%d): %v", ids[3], err)
+	}
+
+	report, err = taskSystem.IDs()
+	if err != nil {
+		t.Fatalf("IDs(): %v", err)
+	}
+	// The last task went for good, so the highest ID in use lags the sequence
+	want := &IDReport{
+		Tasks: 3, Highest: 5, Next: 7,
+		Gaps:    []IDRange{{From: 2, To: 3}, {From: 6, To: 6}},
+		Trashed: []int{4},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("IDs() = %+v, want %+v", report, want)
+	}
+	if got := report.Gaps[0].String() + "," + report.Gaps[1].String(); got != "2-3,6" {
+		t.Errorf("gaps render as %q, want 2-3,6", got)
+	}
+}
+
+func TestNumbering(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	create := func(title string) *Task {
+		created, err := taskSystem.Create(1, title, "")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		return created
+	}
+
+	before := create("Before numbering")
+	if before.Number != 0 || before.Ref != "" {
+		t.Errorf("task created while numbering is off got %d %q", before.Number, before.Ref)
+	}
+	trashed := create("Trashed")
+	if err := taskSystem.SoftDelete(trashed.ID); err != nil {
+		t.Fatalf("SoftDelete: %v", err)
+	}
+
+	if _, err := taskSystem.SetNumbering("7up"); err == nil {
+		t.Error("SetNumbering accepted a prefix starting with a digit")
+	}
+	numbered, err := taskSystem.SetNumbering("t")
+	if err != nil {
+		t.Fatalf("SetNumbering: %v", err)
+	}
+	if numbered != 2 {
+		t.Errorf("SetNumbering numbered %d existing tasks, want 2 (the trashed one too)", numbered)
+	}
+
+	got, err := taskSystem.GetByID(before.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.Number != 1 || got.Ref != "T-001" {
+		t.Errorf("existing task numbered %d %q, want 1 T-001", got.Number, got.Ref)
+	}
+
+	after := create("After numbering")
+	if after.Ref != "T-003" {
+		t.Errorf("new task got %q, want T-003 after the trashed T-002", after.Ref)
+	}
+
+	// A hard-deleted number is not handed out again
+	if err := taskSystem.HardDelete(after.ID); err != nil {
+		t.Fatalf("HardDelete: %v", err)
+	}
+	if next := create("Next"); next.Ref != "T-004" {
+		t.Errorf("task after a hard delete got %q, want T-004", next.Ref)
+	}
+
+	// Renaming the prefix keeps the numbers
+	if numbered, err := taskSystem.SetNumbering("ENG"); err != nil || numbered != 0 {
+		t.Fatalf("SetNumbering(ENG) = %d, %v; want 0, nil", numbered, err)
+	}
+	found, err := taskSystem.FindTaskByFuzzyID(1, "eng-4")
+	if err != nil {
+		t.Fatalf("FindTaskByFuzzyID(eng-4): %v", err)
+	}
+	if found.Title != "Next" || found.Ref != "ENG-004" {
+		t.Errorf("eng-4 found %q %q, want Next ENG-004", found.Title, found.Ref)
+	}
+	if _, err := taskSystem.GetByRef("T-004"); err == nil {
+		t.Error("GetByRef found a task by the old prefix")
+	}
+
+	report, err := taskSystem.IDs()
+	if err != nil {
+		t.Fatalf("IDs(): %v", err)
+	}
+	if report.NextRef() != "ENG-005" {
+		t.Errorf("next number %q, want ENG-005", report.NextRef())
+	}
+}
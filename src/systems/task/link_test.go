@@ -1,6 +1,7 @@
 package task
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/hmain/cainban/src/systems/storage"
@@ -91,3 +92,135 @@ func TestTaskLinkingValidation(t *testing.T) {
 		t.Fatal("Expected error when linking to non-existent task")
 	}
 }
+
+func TestLinkTasksRejectsCycles(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	for _, title := range []string{"Schema", "API", "Frontend", "Docs"} {
+		if _, err := taskSystem.Create(1, title, ""); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	// 1 before 2 before 3, spelled with different link types
+	if err := taskSystem.LinkTasks(1, 2, LinkTypeBlocks); err != nil {
+		t.Fatalf("Failed to link tasks: %v", err)
+	}
+	if err := taskSystem.LinkTasks(3, 2, LinkTypeDependsOn); err != nil {
+		t.Fatalf("Failed to link tasks: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		from, to int
+		linkType LinkType
+		cycle    string
+	}{
+		{"direct blocks", 2, 1, LinkTypeBlocks, "#2 -> #1 -> #2"},
+		{"transitive blocks", 3, 1, LinkTypeBlocks, "#3 -> #1 -> #2 -> #3"},
+		{"depends_on", 1, 3, LinkTypeDependsOn, "#3 -> #1 -> #2 -> #3"},
+		{"blocked_by", 1, 2, LinkTypeBlockedBy, "#2 -> #1 -> #2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := taskSystem.LinkTasks(tt.from, tt.to, tt.linkType)
+			if err == nil {
+				t.Fatal("Expected circular dependency error")
+			}
+			if !strings.Contains(err.Error(), tt.cycle) {
+				t.Errorf("Error %q does not describe the cycle %s", err, tt.cycle)
+			}
+		})
+	}
+
+	// Related links and links that keep the order are fine
+	if err := taskSystem.LinkTasks(3, 1, LinkTypeRelated); err != nil {
+		t.Errorf("Related link should be allowed: %v", err)
+	}
+	if err := taskSystem.LinkTasks(1, 3, LinkTypeBlocks); err != nil {
+		t.Errorf("Redundant but acyclic link should be allowed: %v", err)
+	}
+	if err := taskSystem.LinkTasks(4, 1, LinkTypeBlocks); err != nil {
+		t.Errorf("New blocker should be allowed: %v", err)
+	}
+}
+
+func TestBlockedBy(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	for _, title := range []string{"Schema", "API", "Frontend", "Docs"} {
+		if _, err := taskSystem.Create(1, title, ""); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	// Schema blocks API both ways round; Frontend depends on API
+	links := []struct {
+		from, to int
+		linkType LinkType
+	}{
+		{1, 2, LinkTypeBlocks},
+		{2, 1, LinkTypeBlockedBy},
+		{3, 2, LinkTypeDependsOn},
+		{4, 3, LinkTypeRelated},
+	}
+	for _, l := range links {
+		if err := taskSystem.LinkTasks(l.from, l.to, l.linkType); err != nil {
+			t.Fatalf("Failed to link tasks: %v", err)
+		}
+	}
+
+	blockedBy := func(id int) []int {
+		t.Helper()
+		task, err := taskSystem.GetByID(id)
+		if err != nil {
+			t.Fatalf("Failed to get task: %v", err)
+		}
+		return task.BlockedBy
+	}
+
+	if got := blockedBy(2); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Expected API to be blocked by #1 once, got %v", got)
+	}
+	if got := blockedBy(3); len(got) != 1 || got[0] != 2 {
+		t.Errorf("Expected Frontend to be blocked by #2, got %v", got)
+	}
+	if got := blockedBy(4); got != nil {
+		t.Errorf("Expected related links not to block, got %v", got)
+	}
+
+	tasks, err := taskSystem.List(1)
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %v", err)
+	}
+	if blocked := FilterBlocked(tasks, true); len(blocked) != 2 {
+		t.Errorf("Expected 2 blocked tasks, got %d", len(blocked))
+	}
+
+	// Finishing a blocker unblocks the task
+	if err := taskSystem.UpdateStatus(1, StatusDone); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+	if got := blockedBy(2); got != nil {
+		t.Errorf("Expected API to be unblocked once #1 is done, got %v", got)
+	}
+
+	tasks, err = taskSystem.List(1)
+	if err != nil {
+		t.Fatalf("Failed to list tasks: %v", err)
+	}
+	if unblocked := FilterBlocked(tasks, false); len(unblocked) != 3 {
+		t.Errorf("Expected 3 unblocked tasks, got %d", len(unblocked))
+	}
+}
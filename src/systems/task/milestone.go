@@ -0,0 +1,31 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// SetMilestone puts a task in a milestone, or takes it out of its
+// milestone with a nil milestoneID. See the milestone system for the
+// milestones themselves.
+func (s *System) SetMilestone(id int, milestoneID *int) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	if milestoneID != nil {
+		var exists int
+		if err := s.db.QueryRowContext(s.ctx, `SELECT COUNT(*) FROM milestones WHERE id = ?`, *milestoneID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check milestone: %w", err)
+		}
+		if exists == 0 {
+			return storage.Errorf(ErrNotFound, "milestone with id %d not found", *milestoneID)
+		}
+	}
+
+	_, err := s.db.ExecContext(s.ctx, `UPDATE tasks SET milestone_id = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ? WHERE id = ?`, milestoneID, s.actor, id)
+	if err != nil {
+		return fmt.Errorf("failed to update task milestone: %w", err)
+	}
+	return nil
+}
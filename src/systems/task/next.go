@@ -0,0 +1,76 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Pick is the task Next proposes to work on, and why
+type Pick struct {
+	Task   *Task  `json:"task"`
+	Reason string `json:"reason"`
+}
+
+// Next picks the single best task to work on. Tasks waiting on unfinished
+// blockers and tasks claimed by someone other than the system's actor are
+// never picked. With wipLimit > 0 and that many tasks already
+// in progress, it picks one of those to finish instead of starting a new
+// one. Otherwise it picks a todo task: overdue tasks and tasks due within
+// DueSoon first, soonest first, then by priority, then by due date. It
+// returns nil when there is nothing to pick.
+func (s *System) Next(boardID, wipLimit int, now time.Time) (*Pick, error) {
+	doing, err := s.ListByStatus(boardID, StatusDoing)
+	if err != nil {
+		return nil, err
+	}
+
+	if wipLimit > 0 && len(doing) >= wipLimit {
+		candidates := s.unclaimed(FilterBlocked(doing, false))
+		if len(candidates) == 0 {
+			return nil, nil
+		}
+		rankForNext(candidates, now)
+		reason := fmt.Sprintf("WIP limit reached (%d/%d in progress): finish this before starting something new", len(doing), wipLimit)
+		return &Pick{Task: candidates[0], Reason: reason}, nil
+	}
+
+	todo, err := s.ListByStatus(boardID, StatusTodo)
+	if err != nil {
+		return nil, err
+	}
+	candidates := s.unclaimed(FilterBlocked(todo, false))
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	rankForNext(candidates, now)
+
+	picked := candidates[0]
+	reason := "highest priority unblocked todo task"
+	switch {
+	case picked.IsOverdue(now):
+		reason = fmt.Sprintf("overdue since %s", picked.DueAt.In(now.Location()).Format("Mon Jan 2 15:04"))
+	case picked.isDueSoon(now):
+		reason = fmt.Sprintf("due %s", picked.DueAt.In(now.Location()).Format("Mon Jan 2 15:04"))
+	}
+	return &Pick{Task: picked, Reason: reason}, nil
+}
+
+// rankForNext orders tasks from most to least pressing
+func rankForNext(tasks []*Task, now time.Time) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if urgentA, urgentB := a.isDueSoon(now), b.isDueSoon(now); urgentA != urgentB {
+			return urgentA
+		} else if urgentA {
+			return a.DueAt.Before(*b.DueAt)
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if (a.DueAt == nil) != (b.DueAt == nil) {
+			return a.DueAt != nil
+		}
+		return a.DueAt != nil && a.DueAt.Before(*b.DueAt)
+	})
+}
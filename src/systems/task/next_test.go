@@ -0,0 +1,92 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestNext(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+	if pick, err := taskSystem.Next(1, 0, now); err != nil || pick != nil {
+		t.Fatalf("Expected nothing to pick on an empty board, got %v, %v", pick, err)
+	}
+
+	create := func(title, priority string) *Task {
+		created, err := taskSystem.CreateWithPriority(1, title, "", priority)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		return created
+	}
+	due := func(id int, in time.Duration) {
+		at := now.Add(in)
+		if err := taskSystem.SetDue(id, &at); err != nil {
+			t.Fatalf("Failed to set due date: %v", err)
+		}
+	}
+	next := func(wipLimit int) *Pick {
+		t.Helper()
+		pick, err := taskSystem.Next(1, wipLimit, now)
+		if err != nil {
+			t.Fatalf("Failed to pick next task: %v", err)
+		}
+		if pick == nil {
+			t.Fatal("Expected a task to be picked")
+		}
+		return pick
+	}
+
+	low := create("Tidy up", "low")
+	later := create("Plan Q1", "high")
+	critical := create("Fix outage", "critical")
+	blocker := create("Get credentials", "medium")
+
+	if err := taskSystem.LinkTasks(blocker.ID, critical.ID, LinkTypeBlocks); err != nil {
+		t.Fatalf("Failed to link tasks: %v", err)
+	}
+	due(later.ID, 30*24*time.Hour)
+
+	// The critical task is blocked, so the high priority one is next
+	if pick := next(0); pick.Task.ID != later.ID {
+		t.Errorf("Expected #%d, got #%d (%s)", later.ID, pick.Task.ID, pick.Reason)
+	}
+
+	// A task due soon beats priority
+	due(low.ID, 3*time.Hour)
+	if pick := next(0); pick.Task.ID != low.ID || !strings.HasPrefix(pick.Reason, "due") {
+		t.Errorf("Expected the task due soon, got #%d (%s)", pick.Task.ID, pick.Reason)
+	}
+	due(low.ID, -time.Hour)
+	if pick := next(0); pick.Task.ID != low.ID || !strings.HasPrefix(pick.Reason, "overdue") {
+		t.Errorf("Expected the overdue task, got #%d (%s)", pick.Task.ID, pick.Reason)
+	}
+	if err := taskSystem.SetDue(low.ID, nil); err != nil {
+		t.Fatalf("Failed to clear due date: %v", err)
+	}
+
+	// At the WIP limit, finish what is in progress
+	if err := taskSystem.UpdateStatus(blocker.ID, StatusDoing); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+	if pick := next(1); pick.Task.ID != blocker.ID || !strings.Contains(pick.Reason, "WIP limit") {
+		t.Errorf("Expected the task in progress, got #%d (%s)", pick.Task.ID, pick.Reason)
+	}
+	if pick := next(2); pick.Task.ID != later.ID {
+		t.Errorf("Expected #%d below the WIP limit, got #%d", later.ID, pick.Task.ID)
+	}
+
+	if err := taskSystem.SetDue(999, nil); err == nil {
+		t.Error("Expected error for missing task")
+	}
+}
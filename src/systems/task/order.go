@@ -0,0 +1,139 @@
+package task
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Orders a board can keep its columns in. Manual is the order tasks were
+// put in by hand; priority puts the highest first and is the default.
+const (
+	OrderManual   = "manual"
+	OrderPriority = "priority"
+	OrderDue      = "due"
+	OrderUpdated  = "updated"
+)
+
+// Orders lists the orders a board can keep its columns in
+var Orders = []string{OrderManual, OrderPriority, OrderDue, OrderUpdated}
+
+// Order returns the order the board keeps its columns in
+func (s *System) Order() (string, error) {
+	order, err := s.setting(settingOrder)
+	if err != nil || order == "" {
+		return OrderPriority, err
+	}
+	return order, nil
+}
+
+// SetOrder sets the order the board keeps its columns in, one of Orders.
+// The CLI list, the TUI and MCP list_tasks show tasks in it.
+func (s *System) SetOrder(order string) error {
+	valid := false
+	for _, o := range Orders {
+		valid = valid || o == order
+	}
+	if !valid {
+		return storage.Errorf(ErrInvalidInput, "invalid order %q: use one of %s", order, strings.Join(Orders, ", "))
+	}
+	value := order
+	if order == OrderPriority {
+		value = ""
+	}
+	return s.setSetting(settingOrder, value)
+}
+
+// MovePast reorders a column by hand: the task moves right past other, above
+// it when it was below and below it when it was above. In the priority
+// order a task moved past one of another priority takes that priority, as
+// grooming does, and the tasks of the priority it ends up in are positioned
+// in their new order. In the manual order the whole column is positioned
+// and priorities stay. Columns ordered by due date or update cannot be
+// reordered by hand.
+func (s *System) MovePast(id, otherID int) error {
+	t, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+	other, err := s.GetByID(otherID)
+	if err != nil {
+		return err
+	}
+	if t.ID == other.ID {
+		return storage.Errorf(ErrInvalidInput, "cannot move task #%d past itself", id)
+	}
+	if t.Status != other.Status || t.BoardID != other.BoardID {
+		return storage.Errorf(ErrInvalidInput, "tasks #%d and #%d are not in the same column", id, otherID)
+	}
+
+	order, err := s.Order()
+	if err != nil {
+		return err
+	}
+	if order != OrderManual && order != OrderPriority {
+		return storage.Errorf(ErrInvalidInput, "the board's columns are ordered by %s; set the order to manual or priority to reorder by hand", order)
+	}
+	column, err := s.ListWith(t.BoardID, ListOptions{Status: t.Status, Sort: order})
+	if err != nil {
+		return err
+	}
+	manual := order == OrderManual
+
+	index, otherIndex := -1, -1
+	for i, c := range column {
+		switch c.ID {
+		case t.ID:
+			index = i
+		case other.ID:
+			otherIndex = i
+		}
+	}
+	if index < 0 || otherIndex < 0 {
+		return storage.Errorf(ErrInvalidInput, "tasks #%d and #%d are not both on the board", id, otherID)
+	}
+
+	// Take the task out of the column and put it back on the far side of
+	// the other one
+	reordered := make([]*Task, 0, len(column))
+	for _, c := range column {
+		if c.ID == t.ID {
+			continue
+		}
+		if c.ID == other.ID && index > otherIndex {
+			reordered = append(reordered, t)
+		}
+		reordered = append(reordered, c)
+		if c.ID == other.ID && index < otherIndex {
+			reordered = append(reordered, t)
+		}
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		position := 0
+		for _, c := range reordered {
+			if !manual && c.ID != t.ID && c.Priority != other.Priority {
+				continue
+			}
+			position++
+			priority := other.Priority
+			if manual {
+				priority = c.Priority
+			}
+			if c.Priority == priority && c.Position == position {
+				continue
+			}
+			_, err := tx.ExecContext(s.ctx, `
+				UPDATE tasks SET priority = ?, position = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ?
+				WHERE id = ?
+			`, priority, position, s.actor, c.ID)
+			if err != nil {
+				return fmt.Errorf("failed to reorder task #%d: %w", c.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
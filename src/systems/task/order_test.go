@@ -0,0 +1,162 @@
+package task
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestMovePast(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	create := func(title, priority string) *Task {
+		created, err := taskSystem.CreateWithPriority(1, title, "", priority)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		return created
+	}
+	order := func() []string {
+		todo, err := taskSystem.ListByStatus(1, StatusTodo)
+		if err != nil {
+			t.Fatalf("Failed to list tasks: %v", err)
+		}
+		var titles []string
+		for _, task := range todo {
+			titles = append(titles, task.Title)
+		}
+		return titles
+	}
+	expect := func(want ...string) {
+		t.Helper()
+		got := order()
+		if len(got) != len(want) {
+			t.Fatalf("Order = %v, expected %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("Order = %v, expected %v", got, want)
+			}
+		}
+	}
+
+	urgent := create("Urgent", "high")
+	a := create("A", "low")
+	b := create("B", "low")
+	c := create("C", "low")
+	expect("Urgent", "A", "B", "C")
+
+	// Down within a priority, then back up
+	if err := taskSystem.MovePast(a.ID, b.ID); err != nil {
+		t.Fatalf("MovePast failed: %v", err)
+	}
+	expect("Urgent", "B", "A", "C")
+	if err := taskSystem.MovePast(c.ID, a.ID); err != nil {
+		t.Fatalf("MovePast failed: %v", err)
+	}
+	expect("Urgent", "B", "C", "A")
+
+	// Up past a task of a higher priority takes that priority
+	if err := taskSystem.MovePast(b.ID, urgent.ID); err != nil {
+		t.Fatalf("MovePast failed: %v", err)
+	}
+	expect("B", "Urgent", "C", "A")
+	if moved, _ := taskSystem.GetByID(b.ID); moved.Priority != PriorityHigh {
+		t.Errorf("Expected B to take the high priority, got %d", moved.Priority)
+	}
+
+	// The order survives new tasks of the same priority, which go last
+	create("D", "high")
+	expect("B", "Urgent", "D", "C", "A")
+
+	doing := create("Started", "low")
+	if err := taskSystem.UpdateStatus(doing.ID, StatusDoing); err != nil {
+		t.Fatalf("Failed to move task: %v", err)
+	}
+	if err := taskSystem.MovePast(a.ID, doing.ID); err == nil {
+		t.Error("Expected an error moving past a task in another column")
+	}
+	if err := taskSystem.MovePast(a.ID, a.ID); err == nil {
+		t.Error("Expected an error moving a task past itself")
+	}
+}
+
+func TestBoardOrder(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	create := func(title, priority string) *Task {
+		created, err := taskSystem.CreateWithPriority(1, title, "", priority)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		return created
+	}
+	expect := func(want string) {
+		t.Helper()
+		todo, err := taskSystem.ListWith(1, ListOptions{Status: StatusTodo, BoardOrder: true})
+		if err != nil {
+			t.Fatalf("Failed to list tasks: %v", err)
+		}
+		var titles []string
+		for _, task := range todo {
+			titles = append(titles, task.Title)
+		}
+		if got := strings.Join(titles, ", "); got != want {
+			t.Fatalf("Order = %q, expected %q", got, want)
+		}
+	}
+
+	a := create("A", "low")
+	b := create("B", "critical")
+	c := create("C", "medium")
+	if order, _ := taskSystem.Order(); order != OrderPriority {
+		t.Errorf("Order() = %q, expected priority by default", order)
+	}
+	expect("B, C, A")
+
+	// By hand, tasks keep their priority and the whole column is positioned
+	if err := taskSystem.SetOrder(OrderManual); err != nil {
+		t.Fatalf("SetOrder failed: %v", err)
+	}
+	expect("A, B, C")
+	if err := taskSystem.MovePast(c.ID, a.ID); err != nil {
+		t.Fatalf("MovePast failed: %v", err)
+	}
+	expect("C, A, B")
+	if moved, _ := taskSystem.GetByID(c.ID); moved.Priority != PriorityMedium {
+		t.Errorf("Expected C to keep its priority, got %d", moved.Priority)
+	}
+	create("D", "high")
+	expect("C, A, B, D")
+
+	// Ordered by update, a column cannot be reordered by hand
+	if err := taskSystem.SetOrder(OrderUpdated); err != nil {
+		t.Fatalf("SetOrder failed: %v", err)
+	}
+	if err := taskSystem.MovePast(a.ID, b.ID); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected reordering by hand to be refused, got %v", err)
+	}
+	if tasks, _ := taskSystem.List(1); tasks[0].Title != "B" {
+		t.Errorf("Expected List to keep the priority order, got %s first", tasks[0].Title)
+	}
+
+	if err := taskSystem.SetOrder("alphabetical"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected an unknown order to be refused, got %v", err)
+	}
+	if err := taskSystem.SetOrder(OrderPriority); err != nil {
+		t.Fatalf("SetOrder failed: %v", err)
+	}
+	expect("B, D, C, A")
+}
@@ -0,0 +1,175 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// ListOptions narrows and orders the tasks ListWith returns. The zero value
+// lists every task of the board in the usual order.
+type ListOptions struct {
+	Status      Status     // "" for every status
+	Priorities  []int      // any of these priorities; empty for all
+	Tag         string     // a GTD context, with or without the @
+	Assignee    string     // exact assignee
+	DueBefore   *time.Time // only tasks due before this time
+	StaleBefore *time.Time // only unfinished tasks not updated since this time
+	Blocked     *bool      // only blocked tasks, or only unblocked ones
+	SprintID    int        // only tasks planned for this sprint
+	NoSprint    bool       // only tasks planned for no sprint, the backlog
+	MilestoneID int        // only tasks of this milestone
+	Sort        string     // one of ListSorts; "" sorts by priority
+	BoardOrder  bool       // without a Sort, sort in the board's order
+	Limit       int        // at most this many tasks; 0 for no limit
+	Offset      int        // skip this many tasks first, to page through
+}
+
+// ListSorts lists the orders ListWith can sort by
+var ListSorts = []string{"created", "updated", "priority", "due", "manual"}
+
+// listSortOrders maps each sort to its ORDER BY clause. Creation is oldest
+// first, updates newest first, due dates soonest first with undated tasks
+// last, and the manual order puts tasks positioned by hand first.
+var listSortOrders = map[string]string{
+	"manual":   `position = 0, position, created_at ASC, id ASC`,
+	"created":  `created_at ASC, id ASC`,
+	"updated":  `updated_at DESC, id DESC`,
+	"priority": listOrder,
+	"due":      `due_at IS NULL, due_at ASC, ` + listOrder,
+}
+
+// ListWith retrieves the tasks of a board that match opts, filtering,
+// sorting and paging in the query
+func (s *System) ListWith(boardID int, opts ListOptions) ([]*Task, error) {
+	where, args, err := opts.where(boardID)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Limit < 0 || opts.Offset < 0 {
+		return nil, storage.Errorf(ErrInvalidInput, "limit and offset cannot be negative")
+	}
+	if opts.Sort == "" && opts.BoardOrder {
+		if opts.Sort, err = s.Order(); err != nil {
+			return nil, err
+		}
+	}
+	order := listOrder
+	if opts.Sort != "" {
+		var ok bool
+		if order, ok = listSortOrders[opts.Sort]; !ok {
+			return nil, storage.Errorf(ErrInvalidInput, "invalid sort %q: use one of %s", opts.Sort, strings.Join(ListSorts, ", "))
+		}
+	}
+
+	query := `SELECT ` + taskColumns + `
+		FROM tasks WHERE ` + where + `
+		ORDER BY ` + order
+	if opts.Limit > 0 || opts.Offset > 0 {
+		// SQLite only takes an offset after a limit; -1 is no limit
+		limit := opts.Limit
+		if limit == 0 {
+			limit = -1
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, opts.Offset)
+	}
+
+	rows, err := s.db.QueryContext(s.ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// CountWith counts the tasks ListWith returns without a limit or offset,
+// to tell how many pages there are
+func (s *System) CountWith(boardID int, opts ListOptions) (int, error) {
+	where, args, err := opts.where(boardID)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	if err := s.db.QueryRowContext(s.ctx, `SELECT COUNT(*) FROM tasks WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	return count, nil
+}
+
+// where builds the WHERE clause selecting the tasks of a board that match
+// the filters of opts
+func (opts ListOptions) where(boardID int) (string, []interface{}, error) {
+	where := []string{"board_id = ?", "deleted_at IS NULL"}
+	args := []interface{}{boardID}
+
+	if opts.Status != "" {
+		if !IsValidStatus(string(opts.Status)) {
+			return "", nil, storage.Errorf(ErrInvalidInput, "invalid status: %s", opts.Status)
+		}
+		where = append(where, "status = ?")
+		args = append(args, opts.Status)
+	}
+	if len(opts.Priorities) > 0 {
+		where = append(where, "priority IN (?"+strings.Repeat(", ?", len(opts.Priorities)-1)+")")
+		for _, p := range opts.Priorities {
+			args = append(args, p)
+		}
+	}
+	if opts.Tag != "" {
+		context, err := NormalizeContext(opts.Tag)
+		if err != nil {
+			return "", nil, err
+		}
+		where = append(where, "id IN (SELECT task_id FROM task_contexts WHERE context = ?)")
+		args = append(args, context)
+	}
+	if opts.Assignee != "" {
+		where = append(where, "assignee = ?")
+		args = append(args, opts.Assignee)
+	}
+	if opts.DueBefore != nil {
+		where = append(where, "due_at IS NOT NULL AND due_at < ?")
+		args = append(args, opts.DueBefore.UTC().Truncate(time.Second))
+	}
+	if opts.StaleBefore != nil {
+		// updated_at is CURRENT_TIMESTAMP text, so compare it as such
+		where = append(where, "status != ? AND updated_at < ?")
+		args = append(args, StatusDone, opts.StaleBefore.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if opts.Blocked != nil {
+		operator := "="
+		if *opts.Blocked {
+			operator = "!="
+		}
+		where = append(where, blockedByColumn+" "+operator+" ''")
+	}
+	if opts.SprintID != 0 {
+		where = append(where, "sprint_id = ?")
+		args = append(args, opts.SprintID)
+	}
+	if opts.NoSprint {
+		where = append(where, "sprint_id IS NULL")
+	}
+	if opts.MilestoneID != 0 {
+		where = append(where, "milestone_id = ?")
+		args = append(args, opts.MilestoneID)
+	}
+	return strings.Join(where, " AND "), args, nil
+}
@@ -0,0 +1,96 @@
+package task
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestListWith(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	now := time.Now()
+
+	create := func(title, priority, assignee, context string, due time.Duration) *Task {
+		created, err := taskSystem.CreateWithPriority(1, title, "", priority)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if assignee != "" {
+			if _, err := taskSystem.Assign(created.ID, assignee); err != nil {
+				t.Fatalf("Assign: %v", err)
+			}
+		}
+		if context != "" {
+			if err := taskSystem.AddContext(created.ID, context); err != nil {
+				t.Fatalf("AddContext: %v", err)
+			}
+		}
+		if due != 0 {
+			at := now.Add(due)
+			if err := taskSystem.SetDue(created.ID, &at); err != nil {
+				t.Fatalf("SetDue: %v", err)
+			}
+		}
+		return created
+	}
+	create("Invoice", "high", "alice", "@office", 48*time.Hour)
+	create("Backups", "critical", "bob", "", 2*time.Hour)
+	create("Newsletter", "low", "alice", "@home", 0)
+	report := create("Report", "high", "", "@office", 0)
+	if err := taskSystem.UpdateStatus(report.ID, StatusDone); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+
+	// Newsletter and Report have not been touched for ten days
+	if _, err := db.Conn().Exec(`UPDATE tasks SET updated_at = datetime('now', '-10 days') WHERE title IN ('Newsletter', 'Report')`); err != nil {
+		t.Fatalf("Failed to backdate tasks: %v", err)
+	}
+
+	soon := now.Add(24 * time.Hour)
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+	tests := []struct {
+		name string
+		opts ListOptions
+		want string
+	}{
+		{"everything", ListOptions{}, "Backups, Invoice, Report, Newsletter"},
+		{"status", ListOptions{Status: StatusDone}, "Report"},
+		{"priorities", ListOptions{Priorities: []int{PriorityHigh, PriorityLow}}, "Invoice, Report, Newsletter"},
+		{"tag", ListOptions{Tag: "office"}, "Invoice, Report"},
+		{"assignee", ListOptions{Assignee: "alice"}, "Invoice, Newsletter"},
+		{"due before", ListOptions{DueBefore: &soon}, "Backups"},
+		{"stale", ListOptions{StaleBefore: &weekAgo}, "Newsletter"},
+		{"sort by due", ListOptions{Sort: "due"}, "Backups, Invoice, Report, Newsletter"},
+		{"sort by creation", ListOptions{Sort: "created"}, "Invoice, Backups, Newsletter, Report"},
+		{"limit", ListOptions{Sort: "created", Limit: 2}, "Invoice, Backups"},
+		{"combined", ListOptions{Tag: "@office", Status: StatusTodo, Assignee: "alice"}, "Invoice"},
+	}
+	for _, tt := range tests {
+		tasks, err := taskSystem.ListWith(1, tt.opts)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		titles := make([]string, len(tasks))
+		for i, task := range tasks {
+			titles[i] = task.Title
+		}
+		if got := strings.Join(titles, ", "); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	for _, opts := range []ListOptions{{Sort: "random"}, {Limit: -1}, {Status: "later"}} {
+		if _, err := taskSystem.ListWith(1, opts); err == nil {
+			t.Errorf("ListWith(%+v) succeeded, expected an error", opts)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// maxReactionLength bounds a reaction in runes; emoji built from several
+// code points (skin tones, flags, ZWJ sequences) still fit
+const maxReactionLength = 8
+
+// reactionsColumn selects the emoji of every reaction on a task, separated
+// by spaces, oldest first
+const reactionsColumn = `(SELECT COALESCE(group_concat(emoji, ' '), '')
+		FROM (SELECT emoji FROM task_reactions WHERE task_reactions.task_id = tasks.id ORDER BY created_at, rowid))`
+
+// Reaction counts the actors who reacted to a task with the same emoji
+type Reaction struct {
+	Emoji  string   `json:"emoji"`
+	Count  int      `json:"count"`
+	Actors []string `json:"actors,omitempty"`
+}
+
+// countReactions turns the emoji selected with reactionsColumn into counts,
+// most used first and in order of first use on ties
+func countReactions(joined string) []Reaction {
+	var reactions []Reaction
+	index := make(map[string]int)
+	for _, emoji := range strings.Fields(joined) {
+		i, ok := index[emoji]
+		if !ok {
+			i = len(reactions)
+			index[emoji] = i
+			reactions = append(reactions, Reaction{Emoji: emoji})
+		}
+		reactions[i].Count++
+	}
+	sort.SliceStable(reactions, func(i, j int) bool {
+		return reactions[i].Count > reactions[j].Count
+	})
+	return reactions
+}
+
+// ValidateReaction checks that a reaction is a single short token such as
+// an emoji
+func ValidateReaction(emoji string) error {
+	if emoji == "" {
+		return storage.Errorf(ErrInvalidInput, "reaction cannot be empty")
+	}
+	if strings.IndexFunc(emoji, unicode.IsSpace) >= 0 {
+		return storage.Errorf(ErrInvalidInput, "reaction cannot contain spaces")
+	}
+	if utf8.RuneCountInString(emoji) > maxReactionLength {
+		return storage.Errorf(ErrInvalidInput, "reaction too long (max %d characters)", maxReactionLength)
+	}
+	return nil
+}
+
+// React records an actor's reaction to a task. Each actor counts once per
+// emoji, so reacting twice is not an error and changes nothing; it reports
+// whether the reaction is new.
+func (s *System) React(taskID int, actor, emoji string) (bool, error) {
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		return false, storage.Errorf(ErrInvalidInput, "actor cannot be empty")
+	}
+	if err := ValidateReaction(emoji); err != nil {
+		return false, err
+	}
+	if _, err := s.GetByID(taskID); err != nil {
+		return false, err
+	}
+
+	result, err := s.db.ExecContext(s.ctx, `
+		INSERT OR IGNORE INTO task_reactions (task_id, actor, emoji) VALUES (?, ?, ?)
+	`, taskID, actor, emoji)
+	if err != nil {
+		return false, fmt.Errorf("failed to add reaction: %w", err)
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to add reaction: %w", err)
+	}
+	return added > 0, nil
+}
+
+// Unreact removes an actor's reaction from a task, reporting whether there
+// was one
+func (s *System) Unreact(taskID int, actor, emoji string) (bool, error) {
+	result, err := s.db.ExecContext(s.ctx, `
+		DELETE FROM task_reactions WHERE task_id = ? AND actor = ? AND emoji = ?
+	`, taskID, strings.TrimSpace(actor), emoji)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// ListReactions returns the reactions on a task with the actors behind
+// each, ordered like Task.Reactions
+func (s *System) ListReactions(taskID int) ([]Reaction, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT emoji, actor FROM task_reactions
+		WHERE task_id = ?
+		ORDER BY created_at, rowid
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reactions: %w", err)
+	}
+	defer rows.Close()
+
+	var emoji []string
+	actors := make(map[string][]string)
+	for rows.Next() {
+		var e, actor string
+		if err := rows.Scan(&e, &actor); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction: %w", err)
+		}
+		emoji = append(emoji, e)
+		actors[e] = append(actors[e], actor)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	reactions := countReactions(strings.Join(emoji, " "))
+	for i := range reactions {
+		reactions[i].Actors = actors[reactions[i].Emoji]
+	}
+	return reactions, nil
+}
+
+// FormatReactions renders reaction counts the way cards show them, e.g.
+// "👍 2 👀 1"
+func FormatReactions(reactions []Reaction) string {
+	parts := make([]string, len(reactions))
+	for i, r := range reactions {
+		parts[i] = fmt.Sprintf("%s %d", r.Emoji, r.Count)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,73 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestReactions(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	created, err := taskSystem.Create(1, "Ship it", "")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	reactions := []struct{ actor, emoji string }{
+		{"alice", "👀"},
+		{"alice", "👍"},
+		{"bob", "👍"},
+		{"claude", "👍🏽"},
+	}
+	for _, r := range reactions {
+		if added, err := taskSystem.React(created.ID, r.actor, r.emoji); err != nil || !added {
+			t.Fatalf("React(%s, %s) = %v, %v", r.actor, r.emoji, added, err)
+		}
+	}
+
+	// Reacting twice counts once
+	if added, err := taskSystem.React(created.ID, "bob", "👍"); err != nil || added {
+		t.Errorf("Expected a repeated reaction to be ignored, got %v, %v", added, err)
+	}
+
+	got, err := taskSystem.GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to get task: %v", err)
+	}
+	if s := FormatReactions(got.Reactions); s != "👍 2 👀 1 👍🏽 1" {
+		t.Errorf("Reactions = %q, want 👍 2 👀 1 👍🏽 1", s)
+	}
+
+	list, err := taskSystem.ListReactions(created.ID)
+	if err != nil {
+		t.Fatalf("Failed to list reactions: %v", err)
+	}
+	if len(list) != 3 || len(list[0].Actors) != 2 || list[0].Actors[0] != "alice" || list[0].Actors[1] != "bob" {
+		t.Errorf("Unexpected reactions: %+v", list)
+	}
+
+	if removed, err := taskSystem.Unreact(created.ID, "alice", "👍"); err != nil || !removed {
+		t.Errorf("Unreact() = %v, %v", removed, err)
+	}
+	if removed, err := taskSystem.Unreact(created.ID, "alice", "👍"); err != nil || removed {
+		t.Errorf("Expected nothing left to remove, got %v, %v", removed, err)
+	}
+
+	for _, emoji := range []string{"", "thumbs up", "🎉🎉🎉🎉🎉🎉🎉🎉🎉"} {
+		if _, err := taskSystem.React(created.ID, "alice", emoji); err == nil {
+			t.Errorf("Expected error for reaction %q", emoji)
+		}
+	}
+	if _, err := taskSystem.React(created.ID, " ", "👍"); err == nil {
+		t.Error("Expected error for empty actor")
+	}
+	if _, err := taskSystem.React(999, "alice", "👍"); err == nil {
+		t.Error("Expected error for missing task")
+	}
+}
@@ -0,0 +1,52 @@
+package task
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Readme is a board's charter: Markdown describing its goals, conventions
+// and glossary, for people and agents joining the board
+type Readme struct {
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SetReadme stores the board's readme, replacing any previous one. Empty
+// content removes it.
+func (s *System) SetReadme(content string) (*Readme, error) {
+	if strings.TrimSpace(content) == "" {
+		if _, err := s.db.ExecContext(s.ctx, `DELETE FROM board_readme`); err != nil {
+			return nil, fmt.Errorf("failed to clear board readme: %w", err)
+		}
+		return nil, nil
+	}
+
+	readme := Readme{Content: content}
+	err := s.db.QueryRowContext(s.ctx, `
+		INSERT INTO board_readme (id, content) VALUES (1, ?)
+		ON CONFLICT(id) DO UPDATE SET content = excluded.content, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`, content).Scan(&readme.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save board readme: %w", err)
+	}
+
+	return &readme, nil
+}
+
+// GetReadme returns the board's readme, or nil if it has none
+func (s *System) GetReadme() (*Readme, error) {
+	var readme Readme
+	err := s.db.QueryRowContext(s.ctx, `SELECT content, updated_at FROM board_readme WHERE id = 1`).
+		Scan(&readme.Content, &readme.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get board readme: %w", err)
+	}
+	return &readme, nil
+}
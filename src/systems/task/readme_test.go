@@ -0,0 +1,43 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestReadme(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	if readme, err := taskSystem.GetReadme(); err != nil || readme != nil {
+		t.Fatalf("Expected no readme, got %v, %v", readme, err)
+	}
+
+	if _, err := taskSystem.SetReadme("# Goals\n\nShip v1"); err != nil {
+		t.Fatalf("Failed to set readme: %v", err)
+	}
+	if _, err := taskSystem.SetReadme("# Goals\n\nShip v2\n"); err != nil {
+		t.Fatalf("Failed to replace readme: %v", err)
+	}
+
+	readme, err := taskSystem.GetReadme()
+	if err != nil {
+		t.Fatalf("Failed to get readme: %v", err)
+	}
+	if readme == nil || readme.Content != "# Goals\n\nShip v2\n" {
+		t.Errorf("Unexpected readme: %+v", readme)
+	}
+
+	if _, err := taskSystem.SetReadme("  \n"); err != nil {
+		t.Fatalf("Failed to clear readme: %v", err)
+	}
+	if readme, _ := taskSystem.GetReadme(); readme != nil {
+		t.Errorf("Expected the readme to be cleared, got %q", readme.Content)
+	}
+}
@@ -0,0 +1,158 @@
+package task
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Recurrence is how often a recurring task comes back to todo
+type Recurrence string
+
+const (
+	RecurrenceNone   Recurrence = ""
+	RecurrenceDaily  Recurrence = "daily"
+	RecurrenceWeekly Recurrence = "weekly"
+)
+
+// ParseRecurrence converts a recurrence name; "none" clears recurrence
+func ParseRecurrence(name string) (Recurrence, error) {
+	switch name {
+	case "", "none":
+		return RecurrenceNone, nil
+	case string(RecurrenceDaily), string(RecurrenceWeekly):
+		return Recurrence(name), nil
+	default:
+		return RecurrenceNone, storage.Errorf(ErrInvalidInput, "invalid recurrence: %s (must be daily, weekly or none)", name)
+	}
+}
+
+// PeriodStart returns the start of the recurrence period containing t, in
+// t's location: midnight for daily tasks and Monday midnight for weekly ones
+func (r Recurrence) PeriodStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if r != RecurrenceWeekly {
+		return day
+	}
+	offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+	return day.AddDate(0, 0, -offset)
+}
+
+// PreviousPeriod returns the start of the period before the one starting at start
+func (r Recurrence) PreviousPeriod(start time.Time) time.Time {
+	if r == RecurrenceWeekly {
+		return start.AddDate(0, 0, -7)
+	}
+	return start.AddDate(0, 0, -1)
+}
+
+// NextPeriod returns the start of the period after the one starting at start
+func (r Recurrence) NextPeriod(start time.Time) time.Time {
+	if r == RecurrenceWeekly {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// SetRecurrence makes a task recurring, or one-off with RecurrenceNone
+func (s *System) SetRecurrence(id int, recurrence Recurrence) error {
+	if _, err := ParseRecurrence(string(recurrence)); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE tasks
+		SET recurrence = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	result, err := s.db.ExecContext(s.ctx, query, recurrence, s.actor, id)
+	if err != nil {
+		return fmt.Errorf("failed to update task recurrence: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update result: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return storage.Errorf(ErrNotFound, "task with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// ResetRecurring moves recurring tasks that were completed in an earlier
+// period back to todo, returning the tasks that were reset
+func (s *System) ResetRecurring(boardID int, now time.Time) ([]*Task, error) {
+	query := `
+		SELECT t.id, t.recurrence, MAX(e.created_at)
+		FROM tasks t
+		JOIN task_events e ON e.task_id = t.id AND e.to_status = 'done'
+		WHERE t.board_id = ? AND t.status = 'done' AND t.recurrence != '' AND t.deleted_at IS NULL
+		GROUP BY t.id
+	`
+
+	rows, err := s.db.QueryContext(s.ctx, query, boardID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recurring tasks: %w", err)
+	}
+
+	var due []int
+	for rows.Next() {
+		var id int
+		var recurrence Recurrence
+		var completed string // MAX() loses the column type, so parse it ourselves
+		if err := rows.Scan(&id, &recurrence, &completed); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan recurring task: %w", err)
+		}
+
+		completedAt, err := parseEventTime(completed)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		if recurrence.PeriodStart(completedAt.In(now.Location())).Before(recurrence.PeriodStart(now)) {
+			due = append(due, id)
+		}
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recurring tasks: %w", err)
+	}
+
+	var reset []*Task
+	for _, id := range due {
+		if err := s.UpdateStatus(id, StatusTodo); err != nil {
+			return nil, err
+		}
+		t, err := s.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		reset = append(reset, t)
+	}
+
+	return reset, nil
+}
+
+// parseEventTime parses a timestamp returned as text by an aggregate query
+func parseEventTime(value string) (time.Time, error) {
+	layouts := []string{
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05Z",
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999-07:00",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed to parse event time %q", value)
+}
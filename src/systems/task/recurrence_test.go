@@ -0,0 +1,83 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestRecurrencePeriodStart(t *testing.T) {
+	wednesday := time.Date(2026, 10, 14, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		recurrence Recurrence
+		want       time.Time
+	}{
+		{RecurrenceDaily, time.Date(2026, 10, 14, 0, 0, 0, 0, time.UTC)},
+		{RecurrenceWeekly, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.recurrence.PeriodStart(wednesday); !got.Equal(tt.want) {
+			t.Errorf("%s PeriodStart() = %v, want %v", tt.recurrence, got, tt.want)
+		}
+	}
+
+	if _, err := ParseRecurrence("hourly"); err == nil {
+		t.Error("Expected error for invalid recurrence")
+	}
+	if r, err := ParseRecurrence("none"); err != nil || r != RecurrenceNone {
+		t.Errorf("ParseRecurrence(none) = %q, %v", r, err)
+	}
+}
+
+func TestResetRecurring(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	now := time.Now().UTC()
+
+	daily, _ := taskSystem.Create(1, "Daily review", "")
+	oneOff, _ := taskSystem.Create(1, "One-off", "")
+	today, _ := taskSystem.Create(1, "Done today", "")
+	taskSystem.SetRecurrence(daily.ID, RecurrenceDaily)
+	taskSystem.SetRecurrence(today.ID, RecurrenceDaily)
+
+	for _, id := range []int{daily.ID, oneOff.ID, today.ID} {
+		if err := taskSystem.UpdateStatus(id, StatusDone); err != nil {
+			t.Fatalf("Failed to complete task: %v", err)
+		}
+	}
+
+	// Pretend the daily and one-off tasks were completed yesterday
+	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02 15:04:05")
+	_, err = db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id IN (?, ?) AND to_status = 'done'`,
+		yesterday, daily.ID, oneOff.ID)
+	if err != nil {
+		t.Fatalf("Failed to backdate events: %v", err)
+	}
+
+	reset, err := taskSystem.ResetRecurring(1, now)
+	if err != nil {
+		t.Fatalf("ResetRecurring() error = %v", err)
+	}
+	if len(reset) != 1 || reset[0].ID != daily.ID || reset[0].Status != StatusTodo {
+		t.Fatalf("Expected only the daily task to be reset, got %+v", reset)
+	}
+
+	for _, id := range []int{oneOff.ID, today.ID} {
+		got, _ := taskSystem.GetByID(id)
+		if got.Status != StatusDone {
+			t.Errorf("Task %d status = %s, want done", id, got.Status)
+		}
+	}
+
+	if err := taskSystem.SetRecurrence(daily.ID, "hourly"); err == nil {
+		t.Error("Expected error for invalid recurrence")
+	}
+}
@@ -0,0 +1,163 @@
+package task
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Rollup aggregates the subtasks of a parent task. A subtask with subtasks
+// of its own counts with its rolled up points instead of its estimate.
+type Rollup struct {
+	Subtasks     int `json:"subtasks"`
+	DoneSubtasks int `json:"done_subtasks"`
+	Points       int `json:"points"`
+	DonePoints   int `json:"done_points"`
+}
+
+// Percent returns how complete the subtasks are, by points when they are
+// estimated and by count when they are not
+func (r Rollup) Percent() int {
+	if r.Points > 0 {
+		return r.DonePoints * 100 / r.Points
+	}
+	if r.Subtasks > 0 {
+		return r.DoneSubtasks * 100 / r.Subtasks
+	}
+	return 0
+}
+
+// String renders the rollup as done/total points, or subtasks when none of
+// them are estimated, with the percentage complete
+func (r Rollup) String() string {
+	if r.Points > 0 {
+		return fmt.Sprintf("%d/%d pts %d%%", r.DonePoints, r.Points, r.Percent())
+	}
+	return fmt.Sprintf("%d/%d subtasks %d%%", r.DoneSubtasks, r.Subtasks, r.Percent())
+}
+
+// dbQuerier is satisfied by both *sql.DB and *sql.Tx
+type dbQuerier interface {
+	dbExecer
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// rollupQuery recomputes the stored rollup of one parent task from its
+// direct subtasks, whose own rollups are already up to date
+const rollupQuery = `
+	UPDATE tasks SET (rollup_subtasks, rollup_done_subtasks, rollup_points, rollup_done_points) = (
+		SELECT COUNT(*),
+			COALESCE(SUM(c.status = 'done'), 0),
+			COALESCE(SUM(CASE WHEN c.rollup_subtasks > 0 THEN c.rollup_points ELSE c.estimate END), 0),
+			COALESCE(SUM(CASE
+				WHEN c.status = 'done' THEN CASE WHEN c.rollup_subtasks > 0 THEN c.rollup_points ELSE c.estimate END
+				WHEN c.rollup_subtasks > 0 THEN c.rollup_done_points
+				ELSE 0 END), 0)
+		FROM tasks c
+		WHERE c.parent_id = tasks.id AND c.deleted_at IS NULL
+	)
+	WHERE id = ?
+`
+
+// refreshRollups brings the rollups of a task's parent and its ancestors up
+// to date after the task changed. Only that one branch is recomputed.
+func (s *System) refreshRollups(db dbQuerier, taskID int) error {
+	var parentID sql.NullInt64
+	err := db.QueryRowContext(s.ctx, `SELECT parent_id FROM tasks WHERE id = ?`, taskID).Scan(&parentID)
+	if err == sql.ErrNoRows || (err == nil && !parentID.Valid) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to look up parent task: %w", err)
+	}
+	return s.refreshRollupFrom(db, int(parentID.Int64))
+}
+
+// refreshRollupFrom recomputes the rollup of a parent task, then of each of
+// its ancestors in turn
+func (s *System) refreshRollupFrom(db dbQuerier, parentID int) error {
+	seen := make(map[int]bool)
+	for !seen[parentID] {
+		seen[parentID] = true
+		if _, err := db.ExecContext(s.ctx, rollupQuery, parentID); err != nil {
+			return fmt.Errorf("failed to update rollup of task %d: %w", parentID, err)
+		}
+
+		var next sql.NullInt64
+		err := db.QueryRowContext(s.ctx, `SELECT parent_id FROM tasks WHERE id = ?`, parentID).Scan(&next)
+		if err == sql.ErrNoRows || (err == nil && !next.Valid) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to look up parent task: %w", err)
+		}
+		parentID = int(next.Int64)
+	}
+	return nil
+}
+
+// SetParent makes a task a subtask of another, or a top-level task again
+// with a nil parentID. A task cannot become a subtask of itself or of one of
+// its own subtasks.
+func (s *System) SetParent(id int, parentID *int) error {
+	t, err := s.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		if *parentID == id {
+			return storage.Errorf(ErrInvalidInput, "a task cannot be its own parent")
+		}
+		ancestor, err := s.GetByID(*parentID)
+		if err != nil {
+			return storage.Errorf(ErrNotFound, "parent task not found: %w", err)
+		}
+		for ancestor.ParentID != nil {
+			if *ancestor.ParentID == id {
+				return storage.Errorf(ErrInvalidInput, "task %d is a subtask of task %d", *parentID, id)
+			}
+			if ancestor, err = s.GetByID(*ancestor.ParentID); err != nil {
+				break
+			}
+		}
+	}
+
+	return s.inTx(func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(s.ctx, `UPDATE tasks SET parent_id = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ? WHERE id = ?`, parentID, s.actor, id); err != nil {
+			return fmt.Errorf("failed to update task parent: %w", err)
+		}
+		if t.ParentID != nil {
+			if err := s.refreshRollupFrom(tx, *t.ParentID); err != nil {
+				return err
+			}
+		}
+		return s.refreshRollups(tx, id)
+	})
+}
+
+// Subtasks returns the direct subtasks of a task, in list order
+func (s *System) Subtasks(parentID int) ([]*Task, error) {
+	query := `SELECT ` + taskColumns + `
+		FROM tasks WHERE parent_id = ? AND deleted_at IS NULL
+		ORDER BY ` + listOrder
+
+	rows, err := s.db.QueryContext(s.ctx, query, parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subtasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+
+	return tasks, rows.Err()
+}
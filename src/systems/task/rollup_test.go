@@ -0,0 +1,114 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestRollup(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	create := func(title string, points int, parentID *int) *Task {
+		created, err := taskSystem.Create(1, title, "")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if points > 0 {
+			if err := taskSystem.UpdateEstimate(created.ID, points); err != nil {
+				t.Fatalf("Failed to set estimate: %v", err)
+			}
+		}
+		if parentID != nil {
+			if err := taskSystem.SetParent(created.ID, parentID); err != nil {
+				t.Fatalf("Failed to set parent: %v", err)
+			}
+		}
+		return created
+	}
+	rollup := func(id int) Rollup {
+		t.Helper()
+		got, err := taskSystem.GetByID(id)
+		if err != nil {
+			t.Fatalf("Failed to get task: %v", err)
+		}
+		if got.Rollup == nil {
+			return Rollup{}
+		}
+		return *got.Rollup
+	}
+
+	epic := create("Epic", 0, nil)
+	story := create("Story", 0, &epic.ID)
+	api := create("API", 3, &story.ID)
+	ui := create("UI", 5, &story.ID)
+	docs := create("Docs", 2, &epic.ID)
+
+	if got := rollup(story.ID); got != (Rollup{Subtasks: 2, Points: 8}) {
+		t.Errorf("Story rollup = %+v", got)
+	}
+	// The story counts with its subtasks' points, not its own estimate
+	if got := rollup(epic.ID); got != (Rollup{Subtasks: 2, Points: 10}) {
+		t.Errorf("Epic rollup = %+v", got)
+	}
+
+	if err := taskSystem.UpdateStatus(api.ID, StatusDone); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+	if got := rollup(epic.ID); got.DonePoints != 3 || got.Percent() != 30 {
+		t.Errorf("Epic rollup after API done = %+v (%d%%)", got, got.Percent())
+	}
+
+	if err := taskSystem.UpdateEstimate(ui.ID, 1); err != nil {
+		t.Fatalf("Failed to set estimate: %v", err)
+	}
+	if err := taskSystem.UpdateStatus(story.ID, StatusDone); err != nil {
+		t.Fatalf("Failed to complete task: %v", err)
+	}
+	if got := rollup(epic.ID); got != (Rollup{Subtasks: 2, DoneSubtasks: 1, Points: 6, DonePoints: 4}) {
+		t.Errorf("Epic rollup after story done = %+v", got)
+	}
+
+	if err := taskSystem.SoftDelete(docs.ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	if got := rollup(epic.ID); got.Subtasks != 1 || got.Percent() != 100 {
+		t.Errorf("Epic rollup after docs deleted = %+v", got)
+	}
+
+	if err := taskSystem.SetParent(ui.ID, nil); err != nil {
+		t.Fatalf("Failed to clear parent: %v", err)
+	}
+	if got := rollup(story.ID); got != (Rollup{Subtasks: 1, DoneSubtasks: 1, Points: 3, DonePoints: 3}) {
+		t.Errorf("Story rollup after UI moved out = %+v", got)
+	}
+
+	if err := taskSystem.HardDelete(api.ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	if got := rollup(story.ID); got != (Rollup{}) {
+		t.Errorf("Expected no rollup without subtasks, got %+v", got)
+	}
+
+	subtasks, err := taskSystem.Subtasks(epic.ID)
+	if err != nil || len(subtasks) != 1 || subtasks[0].ID != story.ID {
+		t.Errorf("Subtasks() = %v, %v", subtasks, err)
+	}
+
+	if err := taskSystem.SetParent(epic.ID, &story.ID); err == nil {
+		t.Error("Expected error making a task a subtask of its own subtask")
+	}
+	if err := taskSystem.SetParent(epic.ID, &epic.ID); err == nil {
+		t.Error("Expected error making a task its own parent")
+	}
+	missing := 999
+	if err := taskSystem.SetParent(epic.ID, &missing); err == nil {
+		t.Error("Expected error for missing parent")
+	}
+}
@@ -0,0 +1,40 @@
+package task
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Keys of the board_settings table
+const settingOrder = "order"
+
+// setting returns the value of a board setting, or "" when it is not set
+func (s *System) setting(key string) (string, error) {
+	var value string
+	err := s.db.QueryRowContext(s.ctx, `SELECT value FROM board_settings WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get board setting %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// setSetting stores a board setting; an empty value removes it
+func (s *System) setSetting(key, value string) error {
+	if value == "" {
+		if _, err := s.db.ExecContext(s.ctx, `DELETE FROM board_settings WHERE key = ?`, key); err != nil {
+			return fmt.Errorf("failed to clear board setting %s: %w", key, err)
+		}
+		return nil
+	}
+	_, err := s.db.ExecContext(s.ctx, `
+		INSERT INTO board_settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = CURRENT_TIMESTAMP
+	`, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to save board setting %s: %w", key, err)
+	}
+	return nil
+}
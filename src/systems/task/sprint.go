@@ -0,0 +1,30 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// SetSprint plans a task for a sprint, or takes it out of its sprint with
+// a nil sprintID. See the sprint system for the sprints themselves.
+func (s *System) SetSprint(id int, sprintID *int) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	if sprintID != nil {
+		var exists int
+		if err := s.db.QueryRowContext(s.ctx, `SELECT COUNT(*) FROM sprints WHERE id = ?`, *sprintID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check sprint: %w", err)
+		}
+		if exists == 0 {
+			return storage.Errorf(ErrNotFound, "sprint with id %d not found", *sprintID)
+		}
+	}
+
+	_, err := s.db.ExecContext(s.ctx, `UPDATE tasks SET sprint_id = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ? WHERE id = ?`, sprintID, s.actor, id)
+	if err != nil {
+		return fmt.Errorf("failed to update task sprint: %w", err)
+	}
+	return nil
+}
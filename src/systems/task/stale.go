@@ -0,0 +1,14 @@
+package task
+
+import "time"
+
+// IsStale reports whether an unfinished task has gone without an update
+// for longer than after. With an after of 0 or less no task is stale.
+func (t *Task) IsStale(now time.Time, after time.Duration) bool {
+	return after > 0 && t.Status != StatusDone && now.Sub(t.UpdatedAt) > after
+}
+
+// StaleDays is how many whole days a task has gone without an update
+func (t *Task) StaleDays(now time.Time) int {
+	return int(now.Sub(t.UpdatedAt).Hours() / 24)
+}
@@ -0,0 +1,32 @@
+package task
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsStale(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	tests := []struct {
+		name   string
+		status Status
+		age    time.Duration
+		after  time.Duration
+		want   bool
+	}{
+		{"untouched doing task", StatusDoing, 8 * 24 * time.Hour, week, true},
+		{"untouched todo task", StatusTodo, 30 * 24 * time.Hour, week, true},
+		{"recently updated", StatusDoing, 6 * 24 * time.Hour, week, false},
+		{"done", StatusDone, 30 * 24 * time.Hour, week, false},
+		{"no threshold", StatusDoing, 30 * 24 * time.Hour, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &Task{Status: tt.status, UpdatedAt: now.Add(-tt.age)}
+			if got := task.IsStale(now, tt.after); got != tt.want {
+				t.Errorf("IsStale() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package task
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Size is a rough t-shirt size for how long a task takes
+type Size string
+
+const (
+	SizeNone   Size = ""
+	SizeSmall  Size = "S"
+	SizeMedium Size = "M"
+	SizeLarge  Size = "L"
+)
+
+// sizeDurations is the time budget each size is assumed to need
+var sizeDurations = map[Size]time.Duration{
+	SizeSmall:  30 * time.Minute,
+	SizeMedium: 2 * time.Hour,
+	SizeLarge:  4 * time.Hour,
+}
+
+// ParseSize converts a size name (S/M/L, small/medium/large); "none" clears it
+func ParseSize(name string) (Size, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return SizeNone, nil
+	case "s", "small":
+		return SizeSmall, nil
+	case "m", "medium":
+		return SizeMedium, nil
+	case "l", "large":
+		return SizeLarge, nil
+	default:
+		return SizeNone, storage.Errorf(ErrInvalidInput, "invalid size: %s (must be S, M, L or none)", name)
+	}
+}
+
+// Duration returns the time a task of this size is assumed to need, or 0
+// when the size is unknown
+func (s Size) Duration() time.Duration {
+	return sizeDurations[s]
+}
+
+// Energy is how much focus a task demands
+type Energy string
+
+const (
+	EnergyNone Energy = ""
+	EnergyLow  Energy = "low"
+	EnergyHigh Energy = "high"
+)
+
+// ParseEnergy converts an energy name; "none" clears it
+func ParseEnergy(name string) (Energy, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return EnergyNone, nil
+	case string(EnergyLow), string(EnergyHigh):
+		return Energy(strings.ToLower(name)), nil
+	default:
+		return EnergyNone, storage.Errorf(ErrInvalidInput, "invalid energy: %s (must be low, high or none)", name)
+	}
+}
+
+// SetSize sets the size of a task, or clears it with SizeNone
+func (s *System) SetSize(id int, size Size) error {
+	if _, err := ParseSize(string(size)); err != nil {
+		return err
+	}
+	return s.setAttribute(id, "size", string(size))
+}
+
+// SetEnergy sets the energy a task demands, or clears it with EnergyNone
+func (s *System) SetEnergy(id int, energy Energy) error {
+	if _, err := ParseEnergy(string(energy)); err != nil {
+		return err
+	}
+	return s.setAttribute(id, "energy", string(energy))
+}
+
+// setAttribute updates a single text column of a live task. column must be
+// a constant, never user input.
+func (s *System) setAttribute(id int, column, value string) error {
+	query := `
+		UPDATE tasks
+		SET ` + column + ` = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	result, err := s.db.ExecContext(s.ctx, query, value, s.actor, id)
+	if err != nil {
+		return fmt.Errorf("failed to update task %s: %w", column, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update result: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return storage.Errorf(ErrNotFound, "task with ID %d not found", id)
+	}
+
+	return nil
+}
+
+// SuggestOptions constrains the tasks proposed by Suggest
+type SuggestOptions struct {
+	// Time is the time available; tasks must have a size that fits. Zero
+	// means no time limit.
+	Time time.Duration
+	// Energy is the energy available. With EnergyLow only tasks marked low
+	// energy are proposed; unlabelled tasks are assumed to need focus.
+	Energy Energy
+	// Limit caps the number of suggestions; zero means no cap
+	Limit int
+}
+
+// Suggest proposes open tasks that fit the time and energy available.
+// Tasks already in progress come first, then higher priority, then smaller
+// tasks, so that a short session finishes something.
+func (s *System) Suggest(boardID int, opts SuggestOptions) ([]*Task, error) {
+	tasks, err := s.List(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fitting []*Task
+	for _, t := range tasks {
+		if t.Status == StatusDone {
+			continue
+		}
+		if opts.Time > 0 && (t.Size == SizeNone || t.Size.Duration() > opts.Time) {
+			continue
+		}
+		if opts.Energy == EnergyLow && t.Energy != EnergyLow {
+			continue
+		}
+		fitting = append(fitting, t)
+	}
+
+	sort.SliceStable(fitting, func(i, j int) bool {
+		a, b := fitting[i], fitting[j]
+		if (a.Status == StatusDoing) != (b.Status == StatusDoing) {
+			return a.Status == StatusDoing
+		}
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		return a.Size.Duration() < b.Size.Duration()
+	})
+
+	if opts.Limit > 0 && len(fitting) > opts.Limit {
+		fitting = fitting[:opts.Limit]
+	}
+	return fitting, nil
+}
@@ -0,0 +1,97 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestParseSizeAndEnergy(t *testing.T) {
+	sizes := map[string]Size{"s": SizeSmall, "Medium": SizeMedium, "L": SizeLarge, "none": SizeNone}
+	for input, want := range sizes {
+		if got, err := ParseSize(input); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseSize("XL"); err == nil {
+		t.Error("Expected error for invalid size")
+	}
+
+	if got, err := ParseEnergy("LOW"); err != nil || got != EnergyLow {
+		t.Errorf("ParseEnergy(LOW) = %q, %v", got, err)
+	}
+	if _, err := ParseEnergy("medium"); err == nil {
+		t.Error("Expected error for invalid energy")
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	create := func(title string, priority int, size Size, energy Energy) *Task {
+		created, err := taskSystem.CreateWithPriority(1, title, "", priority)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if err := taskSystem.SetSize(created.ID, size); err != nil {
+			t.Fatalf("Failed to set size: %v", err)
+		}
+		if err := taskSystem.SetEnergy(created.ID, energy); err != nil {
+			t.Fatalf("Failed to set energy: %v", err)
+		}
+		return created
+	}
+
+	inbox := create("Clear inbox", PriorityLow, SizeSmall, EnergyLow)
+	typo := create("Fix typo", PriorityHigh, SizeSmall, EnergyLow)
+	refactor := create("Refactor parser", PriorityHigh, SizeLarge, EnergyHigh)
+	create("Unsized chore", PriorityMedium, SizeNone, EnergyLow)
+	review := create("Review PR", PriorityNone, SizeMedium, EnergyNone)
+	done := create("Done already", PriorityCritical, SizeSmall, EnergyLow)
+	taskSystem.UpdateStatus(done.ID, StatusDone)
+	taskSystem.UpdateStatus(review.ID, StatusDoing)
+
+	tests := []struct {
+		name string
+		opts SuggestOptions
+		want []int
+	}{
+		{"short and tired", SuggestOptions{Time: 30 * time.Minute, Energy: EnergyLow}, []int{typo.ID, inbox.ID}},
+		{"two hours", SuggestOptions{Time: 2 * time.Hour}, []int{review.ID, typo.ID, inbox.ID}},
+		{"half a day, limited", SuggestOptions{Time: 4 * time.Hour, Limit: 2}, []int{review.ID, typo.ID}},
+		{"large fits half a day", SuggestOptions{Time: 4 * time.Hour, Energy: EnergyHigh}, []int{review.ID, typo.ID, refactor.ID, inbox.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := taskSystem.Suggest(1, tt.opts)
+			if err != nil {
+				t.Fatalf("Failed to suggest: %v", err)
+			}
+			var ids []int
+			for _, s := range got {
+				ids = append(ids, s.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("Suggest() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Errorf("Suggest() = %v, want %v", ids, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	if err := taskSystem.SetSize(999, SizeSmall); err == nil {
+		t.Error("Expected error sizing missing task")
+	}
+}
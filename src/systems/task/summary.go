@@ -0,0 +1,87 @@
+package task
+
+import (
+	"fmt"
+	"time"
+)
+
+// summaryCompletedLimit caps the recently completed tasks in a summary
+const summaryCompletedLimit = 10
+
+// Summary is an overview of a board in one call: how full each column is
+// and the tasks that most need attention
+type Summary struct {
+	Counts            map[Status]int `json:"counts"`
+	Blocked           int            `json:"blocked"`
+	HighPriority      []*Task        `json:"high_priority"` // open, high or critical
+	Overdue           []*Task        `json:"overdue"`
+	RecentlyCompleted []*Task        `json:"recently_completed"`
+}
+
+// Summarize builds the summary of a board as of now. Recently completed
+// tasks are those last moved to done at or after since, newest first and at
+// most summaryCompletedLimit of them.
+func (s *System) Summarize(boardID int, now, since time.Time) (*Summary, error) {
+	tasks, err := s.List(boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{
+		Counts:            make(map[Status]int),
+		HighPriority:      []*Task{},
+		Overdue:           []*Task{},
+		RecentlyCompleted: []*Task{},
+	}
+	for _, status := range ValidStatuses() {
+		summary.Counts[status] = 0
+	}
+
+	byID := make(map[int]*Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+		summary.Counts[t.Status]++
+		if t.IsBlocked() {
+			summary.Blocked++
+		}
+		if t.Status == StatusDone {
+			continue
+		}
+		// List orders by priority, highest first
+		if t.Priority >= PriorityHigh {
+			summary.HighPriority = append(summary.HighPriority, t)
+		}
+		if t.IsOverdue(now) {
+			summary.Overdue = append(summary.Overdue, t)
+		}
+	}
+
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT e.task_id, MAX(e.created_at) AS done_at
+		FROM task_events e
+		JOIN tasks t ON t.id = e.task_id
+		WHERE t.board_id = ? AND t.status = 'done' AND t.deleted_at IS NULL
+			AND e.event_type = 'status_changed' AND e.to_status = 'done'
+		GROUP BY e.task_id
+		HAVING done_at >= ?
+		ORDER BY done_at DESC, e.task_id DESC
+		LIMIT ?
+	`, boardID, since.UTC().Format("2006-01-02 15:04:05"), summaryCompletedLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query completed tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var doneAt string
+		if err := rows.Scan(&id, &doneAt); err != nil {
+			return nil, fmt.Errorf("failed to scan completed task: %w", err)
+		}
+		if t := byID[id]; t != nil {
+			summary.RecentlyCompleted = append(summary.RecentlyCompleted, t)
+		}
+	}
+
+	return summary, rows.Err()
+}
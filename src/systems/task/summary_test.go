@@ -0,0 +1,72 @@
+package task
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestSummarize(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	now := time.Now()
+
+	create := func(title, priority string, status Status) *Task {
+		created, err := taskSystem.CreateWithPriority(1, title, "", priority)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if status != StatusTodo {
+			if err := taskSystem.UpdateStatus(created.ID, status); err != nil {
+				t.Fatalf("Failed to move task: %v", err)
+			}
+		}
+		return created
+	}
+
+	urgent := create("Fix outage", "critical", StatusDoing)
+	create("Polish docs", "low", StatusTodo)
+	late := create("Renew cert", "medium", StatusTodo)
+	create("Ship v1", "high", StatusDone)
+	old := create("Ship v0", "high", StatusDone)
+
+	yesterday := now.Add(-24 * time.Hour)
+	if err := taskSystem.SetDue(late.ID, &yesterday); err != nil {
+		t.Fatalf("Failed to set due date: %v", err)
+	}
+	if err := taskSystem.LinkTasks(urgent.ID, late.ID, LinkTypeBlocks); err != nil {
+		t.Fatalf("Failed to link tasks: %v", err)
+	}
+	_, err = db.Conn().Exec(`UPDATE task_events SET created_at = ? WHERE task_id = ? AND to_status = 'done'`,
+		now.AddDate(0, 0, -30).UTC().Format("2006-01-02 15:04:05"), old.ID)
+	if err != nil {
+		t.Fatalf("Failed to backdate event: %v", err)
+	}
+
+	summary, err := taskSystem.Summarize(1, now, now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+
+	if summary.Counts[StatusTodo] != 2 || summary.Counts[StatusDoing] != 1 || summary.Counts[StatusDone] != 2 {
+		t.Errorf("Unexpected counts: %v", summary.Counts)
+	}
+	if summary.Blocked != 1 {
+		t.Errorf("Blocked = %d, want 1", summary.Blocked)
+	}
+	if len(summary.HighPriority) != 1 || summary.HighPriority[0].ID != urgent.ID {
+		t.Errorf("Expected only the open critical task, got %v", summary.HighPriority)
+	}
+	if len(summary.Overdue) != 1 || summary.Overdue[0].ID != late.ID {
+		t.Errorf("Expected the overdue task, got %v", summary.Overdue)
+	}
+	if len(summary.RecentlyCompleted) != 1 || summary.RecentlyCompleted[0].Title != "Ship v1" {
+		t.Errorf("Expected only the task done this week, got %v", summary.RecentlyCompleted)
+	}
+}
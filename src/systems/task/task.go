@@ -1,11 +1,16 @@
 package task
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
 )
 
 // Status represents the state of a task
@@ -66,27 +71,27 @@ func ParsePriority(priority interface{}) (int, error) {
 	switch p := priority.(type) {
 	case int:
 		if !IsValidPriority(p) {
-			return 0, fmt.Errorf("invalid priority level: %d (must be 0-4)", p)
+			return 0, storage.Errorf(ErrInvalidInput, "invalid priority level: %d (must be 0-4)", p)
 		}
 		return p, nil
 	case float64:
 		// Handle JSON unmarshaling which converts numbers to float64
 		intVal := int(p)
 		if float64(intVal) != p {
-			return 0, fmt.Errorf("priority must be a whole number")
+			return 0, storage.Errorf(ErrInvalidInput, "priority must be a whole number")
 		}
 		if !IsValidPriority(intVal) {
-			return 0, fmt.Errorf("invalid priority level: %d (must be 0-4)", intVal)
+			return 0, storage.Errorf(ErrInvalidInput, "invalid priority level: %d (must be 0-4)", intVal)
 		}
 		return intVal, nil
 	case string:
 		level, exists := PriorityLevels[strings.ToLower(p)]
 		if !exists {
-			return 0, fmt.Errorf("invalid priority name: %s (must be none, low, medium, high, critical)", p)
+			return 0, storage.Errorf(ErrInvalidInput, "invalid priority name: %s (must be none, low, medium, high, critical)", p)
 		}
 		return level, nil
 	default:
-		return 0, fmt.Errorf("priority must be int or string")
+		return 0, storage.Errorf(ErrInvalidInput, "priority must be int or string")
 	}
 }
 
@@ -123,10 +128,14 @@ const (
 	LinkTypeDependsOn LinkType = "depends_on" // Task A depends on Task B
 )
 
-// TaskLink represents a relationship between two tasks
+// TaskLink represents a relationship between two tasks. FromBoard and
+// ToBoard name the board of a task on another board and are empty for tasks
+// on the board the link was read from.
 type TaskLink struct {
 	ID         int       `json:"id"`
+	FromBoard  string    `json:"from_board,omitempty"`
 	FromTaskID int       `json:"from_task_id"`
+	ToBoard    string    `json:"to_board,omitempty"`
 	ToTaskID   int       `json:"to_task_id"`
 	LinkType   LinkType  `json:"link_type"`
 	CreatedAt  time.Time `json:"created_at"`
@@ -135,24 +144,130 @@ type TaskLink struct {
 // Task represents a kanban task
 type Task struct {
 	ID          int        `json:"id"`
+	Number      int        `json:"number,omitempty"` // per-board sequence, see SetNumbering
+	Ref         string     `json:"ref,omitempty"`    // Number with the board's prefix, e.g. T-007
+	Hash        string     `json:"hash,omitempty"`   // random hex that stays with the task across boards
 	BoardID     int        `json:"board_id"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Status      Status     `json:"status"`
 	Priority    int        `json:"priority"`
+	Estimate    int        `json:"estimate"`
+	Assignee    string     `json:"assignee,omitempty"`
+	Recurrence  Recurrence `json:"recurrence,omitempty"`
+	Contexts    []string   `json:"contexts,omitempty"`
+	BlockedBy   []int      `json:"blocked_by,omitempty"` // unfinished blocking tasks
+	Reactions   []Reaction `json:"reactions,omitempty"`
+	Votes       int        `json:"votes,omitempty"`
+	Size        Size       `json:"size,omitempty"`
+	Energy      Energy     `json:"energy,omitempty"`
+	DueAt       *time.Time `json:"due_at,omitempty"`
+	Position    int        `json:"position,omitempty"`
+	ParentID    *int       `json:"parent_id,omitempty"`
+	SprintID    *int       `json:"sprint_id,omitempty"`
+	MilestoneID *int       `json:"milestone_id,omitempty"`
+	Rollup      *Rollup    `json:"rollup,omitempty"` // nil without subtasks
 	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+	CreatedBy   string     `json:"created_by,omitempty"` // who created the task, see WithActor
+	UpdatedBy   string     `json:"updated_by,omitempty"` // who changed it last
+	// InProgressBy is the agent that claimed the task, see Claim
+	InProgressBy string     `json:"in_progress_by,omitempty"`
+	ClaimedAt    *time.Time `json:"claimed_at,omitempty"`
+}
+
+// taskColumns lists the columns read by scanTask, in scan order
+const taskColumns = `id, board_id, title, description, status, priority, estimate, assignee, recurrence, size, energy, due_at, position, parent_id, rollup_subtasks, rollup_done_subtasks, rollup_points, rollup_done_points, deleted_at, created_at, updated_at,
+	(SELECT COALESCE(group_concat(context, ' '), '') FROM task_contexts WHERE task_contexts.task_id = tasks.id),
+	` + blockedByColumn + `,
+	` + reactionsColumn + `,
+	` + votesColumn + `,
+	number, ` + prefixColumn + `, COALESCE(hash, ''), COALESCE(created_by, ''), COALESCE(updated_by, ''),
+	COALESCE(in_progress_by, ''), claimed_at, sprint_id, milestone_id`
+
+// listOrder orders tasks by priority, highest first. Within a priority,
+// tasks positioned by grooming come first in their accepted order, then the
+// rest oldest first.
+const listOrder = `priority DESC, position = 0, position, created_at ASC`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTask reads a task selected with taskColumns
+func scanTask(row rowScanner) (*Task, error) {
+	var task Task
+	var contexts, blockedBy, reactions, prefix string
+	var rollup Rollup
+	err := row.Scan(
+		&task.ID, &task.BoardID, &task.Title, &task.Description,
+		&task.Status, &task.Priority, &task.Estimate, &task.Assignee,
+		&task.Recurrence, &task.Size, &task.Energy,
+		&task.DueAt,
+		&task.Position, &task.ParentID,
+		&rollup.Subtasks, &rollup.DoneSubtasks, &rollup.Points, &rollup.DonePoints,
+		&task.DeletedAt, &task.CreatedAt, &task.UpdatedAt,
+		&contexts, &blockedBy, &reactions, &task.Votes,
+		&task.Number, &prefix, &task.Hash, &task.CreatedBy, &task.UpdatedBy,
+		&task.InProgressBy, &task.ClaimedAt, &task.SprintID, &task.MilestoneID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	task.Contexts = splitContexts(contexts)
+	task.BlockedBy = splitBlockers(blockedBy)
+	task.Reactions = countReactions(reactions)
+	task.Ref = FormatRef(prefix, task.Number)
+	if rollup.Subtasks > 0 {
+		task.Rollup = &rollup
+	}
+	return &task, nil
 }
 
 // System handles task operations
 type System struct {
-	db *sql.DB
+	db  *sql.DB
+	ctx context.Context
+
+	// actor is who the changes made through the system are recorded as
+	actor string
 }
 
 // New creates a new task system
 func New(db *sql.DB) *System {
-	return &System{db: db}
+	return &System{db: db, ctx: context.Background()}
+}
+
+// WithContext returns a copy of the system whose queries run under ctx, so
+// that a caller serving a request can cancel them or give them a deadline.
+// The copy shares the database connection.
+func (s *System) WithContext(ctx context.Context) *System {
+	copy := *s
+	copy.ctx = ctx
+	return &copy
+}
+
+// WithActor returns a copy of the system that records the tasks it creates
+// and changes, and the audit trail entries it adds, as made by actor: a
+// person, or an agent working for one. The copy shares the database
+// connection.
+func (s *System) WithActor(actor string) *System {
+	copy := *s
+	copy.actor = strings.TrimSpace(actor)
+	return &copy
+}
+
+// Actor returns who the changes made through the system are recorded as
+func (s *System) Actor() string {
+	return s.actor
+}
+
+// inTx runs fn in a transaction, retried if another writer holds the
+// database, so the statements of one operation are applied together
+func (s *System) inTx(fn func(tx *sql.Tx) error) error {
+	return storage.Tx(s.ctx, s.db, fn)
 }
 
 // Create creates a new task
@@ -167,23 +282,36 @@ func (s *System) CreateWithPriority(boardID int, title, description string, prio
 	}
 
 	if !IsValidPriority(priority) {
-		return nil, fmt.Errorf("invalid priority level")
+		return nil, storage.Errorf(ErrInvalidInput, "invalid priority level")
 	}
 
 	priorityLevel, _ := ParsePriority(priority)
 
-	query := `
-		INSERT INTO tasks (board_id, title, description, status, priority)
-		VALUES (?, ?, ?, ?, ?)
-		RETURNING id, created_at, updated_at
-	`
-
 	var task Task
-	err := s.db.QueryRow(query, boardID, title, description, StatusTodo, priorityLevel).Scan(
-		&task.ID, &task.CreatedAt, &task.UpdatedAt,
-	)
+	var prefix string
+	err := s.inTx(func(tx *sql.Tx) error {
+		var err error
+		task.Number, prefix, err = takeNumber(s.ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO tasks (board_id, title, description, status, priority, number, hash, created_by, updated_by)
+			VALUES (?, ?, ?, ?, ?, ?, ` + newHash + `, ?, ?)
+			RETURNING id, hash, created_at, updated_at
+		`
+		err = tx.QueryRowContext(s.ctx, query, boardID, title, description, StatusTodo, priorityLevel, task.Number, s.actor, s.actor).Scan(
+			&task.ID, &task.Hash, &task.CreatedAt, &task.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+
+		return s.recordEvent(tx, task.ID, EventCreated, "", StatusTodo)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create task: %w", err)
+		return nil, err
 	}
 
 	task.BoardID = boardID
@@ -191,142 +319,114 @@ func (s *System) CreateWithPriority(boardID int, title, description string, prio
 	task.Description = description
 	task.Status = StatusTodo
 	task.Priority = priorityLevel
+	task.Ref = FormatRef(prefix, task.Number)
+	task.CreatedBy, task.UpdatedBy = s.actor, s.actor
 
 	return &task, nil
 }
 
 // GetByID retrieves a task by ID
 func (s *System) GetByID(id int) (*Task, error) {
-	query := `
-		SELECT id, board_id, title, description, status, priority, deleted_at, created_at, updated_at
-		FROM tasks WHERE id = ? AND deleted_at IS NULL
-	`
+	query := `SELECT ` + taskColumns + ` FROM tasks WHERE id = ? AND deleted_at IS NULL`
 
-	var task Task
-	err := s.db.QueryRow(query, id).Scan(
-		&task.ID, &task.BoardID, &task.Title, &task.Description,
-		&task.Status, &task.Priority, &task.DeletedAt, &task.CreatedAt, &task.UpdatedAt,
-	)
+	task, err := scanTask(s.db.QueryRowContext(s.ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("task with id %d not found", id)
+			return nil, storage.Errorf(ErrNotFound, "task with id %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
-	return &task, nil
+	return task, nil
 }
 
 // List retrieves all tasks for a board
 func (s *System) List(boardID int) ([]*Task, error) {
-	query := `
-		SELECT id, board_id, title, description, status, priority, deleted_at, created_at, updated_at
-		FROM tasks WHERE board_id = ? AND deleted_at IS NULL
-		ORDER BY priority DESC, created_at ASC
-	`
-
-	rows, err := s.db.Query(query, boardID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list tasks: %w", err)
-	}
-	defer rows.Close()
-
-	var tasks []*Task
-	for rows.Next() {
-		var task Task
-		err := rows.Scan(
-			&task.ID, &task.BoardID, &task.Title, &task.Description,
-			&task.Status, &task.Priority, &task.DeletedAt, &task.CreatedAt, &task.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
-		}
-		tasks = append(tasks, &task)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating tasks: %w", err)
-	}
-
-	return tasks, nil
+	return s.ListWith(boardID, ListOptions{})
 }
 
 // ListByStatus retrieves tasks by status for a board
 func (s *System) ListByStatus(boardID int, status Status) ([]*Task, error) {
-	query := `
-		SELECT id, board_id, title, description, status, priority, deleted_at, created_at, updated_at
-		FROM tasks WHERE board_id = ? AND status = ? AND deleted_at IS NULL
-		ORDER BY priority DESC, created_at ASC
-	`
-
-	rows, err := s.db.Query(query, boardID, status)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list tasks by status: %w", err)
-	}
-	defer rows.Close()
-
-	var tasks []*Task
-	for rows.Next() {
-		var task Task
-		err := rows.Scan(
-			&task.ID, &task.BoardID, &task.Title, &task.Description,
-			&task.Status, &task.Priority, &task.DeletedAt, &task.CreatedAt, &task.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
-		}
-		tasks = append(tasks, &task)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating tasks: %w", err)
-	}
-
-	return tasks, nil
+	return s.ListWith(boardID, ListOptions{Status: status})
 }
 
-// UpdateStatus updates a task's status
+// UpdateStatus updates a task's status. A task leaving doing is no longer
+// in progress, so its claim is released.
 func (s *System) UpdateStatus(id int, status Status) error {
 	if !IsValidStatus(string(status)) {
-		return fmt.Errorf("invalid status: %s", status)
+		return storage.Errorf(ErrInvalidInput, "invalid status: %s", status)
 	}
 
-	query := `
-		UPDATE tasks 
-		SET status = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
-	`
+	return s.inTx(func(tx *sql.Tx) error {
+		var current Status
+		err := tx.QueryRowContext(s.ctx, `SELECT status FROM tasks WHERE id = ?`, id).Scan(&current)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return storage.Errorf(ErrNotFound, "task with id %d not found", id)
+			}
+			return fmt.Errorf("failed to update task status: %w", err)
+		}
 
-	result, err := s.db.Exec(query, status, id)
-	if err != nil {
-		return fmt.Errorf("failed to update task status: %w", err)
-	}
+		query := `
+			UPDATE tasks 
+			SET status = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ?,
+				in_progress_by = CASE WHEN ? = 'doing' THEN in_progress_by ELSE '' END,
+				claimed_at = CASE WHEN ? = 'doing' THEN claimed_at END
+			WHERE id = ?
+		`
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
+		if _, err := tx.ExecContext(s.ctx, query, status, s.actor, status, status, id); err != nil {
+			return fmt.Errorf("failed to update task status: %w", err)
+		}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("task with id %d not found", id)
-	}
+		if current != status {
+			if err := s.recordEvent(tx, id, EventStatusChanged, current, status); err != nil {
+				return err
+			}
+		}
 
-	return nil
+		return s.refreshRollups(tx, id)
+	})
 }
 
 // Update updates a task's title and description
 func (s *System) Update(id int, title, description string) error {
-	if err := ValidateTitle(title); err != nil {
-		return err
+	return s.UpdateFields(id, UpdateOptions{Title: &title, Description: &description})
+}
+
+// UpdateOptions is a partial update of a task: fields left nil keep their
+// value
+type UpdateOptions struct {
+	Title       *string
+	Description *string
+	// AppendDescription is added to the end of the description, after the
+	// new one if Description is set, on a line of its own
+	AppendDescription string
+}
+
+// UpdateFields changes only the fields of a task that opts sets
+func (s *System) UpdateFields(id int, opts UpdateOptions) error {
+	if opts.Title != nil {
+		if err := ValidateTitle(*opts.Title); err != nil {
+			return err
+		}
+	}
+	if opts.Title == nil && opts.Description == nil && opts.AppendDescription == "" {
+		return storage.Errorf(ErrInvalidInput, "nothing to update")
 	}
 
+	// ?1 and ?2 are NULL for fields left as they are
 	query := `
-		UPDATE tasks 
-		SET title = ?, description = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
+		UPDATE tasks
+		SET title = COALESCE(?1, title),
+			description = CASE WHEN ?3 = '' THEN COALESCE(?2, description)
+				ELSE COALESCE(NULLIF(COALESCE(?2, description), '') || char(10), '') || ?3 END,
+			updated_at = CURRENT_TIMESTAMP,
+			updated_by = ?5
+		WHERE id = ?4
 	`
 
-	result, err := s.db.Exec(query, title, description, id)
+	result, err := s.db.ExecContext(s.ctx, query, opts.Title, opts.Description, opts.AppendDescription, id, s.actor)
 	if err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
 	}
@@ -337,7 +437,7 @@ func (s *System) Update(id int, title, description string) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("task with id %d not found", id)
+		return storage.Errorf(ErrNotFound, "task with id %d not found", id)
 	}
 
 	return nil
@@ -357,11 +457,11 @@ func (s *System) UpdatePriority(id int, priority interface{}) error {
 
 	query := `
 		UPDATE tasks 
-		SET priority = ?, updated_at = CURRENT_TIMESTAMP 
+		SET priority = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ?
 		WHERE id = ?
 	`
 
-	result, err := s.db.Exec(query, priorityLevel, id)
+	result, err := s.db.ExecContext(s.ctx, query, priorityLevel, s.actor, id)
 	if err != nil {
 		return fmt.Errorf("failed to update task priority: %w", err)
 	}
@@ -372,16 +472,47 @@ func (s *System) UpdatePriority(id int, priority interface{}) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("task with ID %d not found", id)
+		return storage.Errorf(ErrNotFound, "task with ID %d not found", id)
 	}
 
 	return nil
 }
 
+// UpdateEstimate sets a task's estimate in story points
+func (s *System) UpdateEstimate(id int, points int) error {
+	if err := ValidateEstimate(points); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE tasks 
+		SET estimate = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`
+
+	return s.inTx(func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(s.ctx, query, points, s.actor, id)
+		if err != nil {
+			return fmt.Errorf("failed to update task estimate: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check update result: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return storage.Errorf(ErrNotFound, "task with ID %d not found", id)
+		}
+
+		return s.refreshRollups(tx, id)
+	})
+}
+
 // SearchTasks performs fuzzy search on task titles
 func (s *System) SearchTasks(boardID int, query string) ([]*Task, error) {
 	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
+		return nil, storage.Errorf(ErrInvalidInput, "search query cannot be empty")
 	}
 
 	tasks, err := s.List(boardID)
@@ -389,8 +520,17 @@ func (s *System) SearchTasks(boardID int, query string) ([]*Task, error) {
 		return nil, err
 	}
 
+	return RankTasks(tasks, query), nil
+}
+
+// RankTasks keeps the tasks whose titles fuzzy match a query, best match
+// first; tasks that match equally well keep their order. An empty query
+// keeps every task.
+func RankTasks(tasks []*Task, query string) []*Task {
 	query = strings.ToLower(strings.TrimSpace(query))
-	var matches []*Task
+	if query == "" {
+		return tasks
+	}
 
 	// Score each task based on fuzzy match quality
 	type taskMatch struct {
@@ -407,33 +547,39 @@ func (s *System) SearchTasks(boardID int, query string) ([]*Task, error) {
 	}
 
 	// Sort by score (highest first)
-	for i := 0; i < len(scored); i++ {
-		for j := i + 1; j < len(scored); j++ {
-			if scored[j].score > scored[i].score {
-				scored[i], scored[j] = scored[j], scored[i]
-			}
-		}
-	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
 
 	// Extract tasks from scored results
+	var matches []*Task
 	for _, match := range scored {
 		matches = append(matches, match.task)
 	}
 
-	return matches, nil
+	return matches
+}
+
+// MatchScore rates how well a task's title matches a search query, with 0
+// meaning no match. Scores are comparable across boards.
+func MatchScore(t *Task, query string) int {
+	return fuzzyMatchScore(strings.ToLower(t.Title), strings.ToLower(strings.TrimSpace(query)))
 }
 
-// FindTaskByFuzzyID attempts to find a task by ID or fuzzy title match
+// FindTaskByFuzzyID attempts to find a task by ID, task number, hash or
+// fuzzy title match. A number is only ever an ID, so a mistyped one is not
+// found rather than taken for a task with the number in its title.
 func (s *System) FindTaskByFuzzyID(boardID int, idOrQuery string) (*Task, error) {
-	// First try to parse as ID
 	if id, err := strconv.Atoi(idOrQuery); err == nil {
-		// Check if the ID exists
-		task, err := s.GetByID(id)
-		if err == nil {
-			return task, nil
-		}
-		// If ID doesn't exist, fall through to fuzzy search
-		// This allows searching for tasks with numbers in titles even if the number doesn't correspond to an existing ID
+		return s.getByNumber(id)
+	}
+
+	// Then as a task number such as T-007, or a hash such as 3f9a2c1
+	if task, err := s.GetByRef(idOrQuery); err == nil {
+		return task, nil
+	}
+	if task, err := s.findByHash(idOrQuery); task != nil || err != nil {
+		return task, err
 	}
 
 	// Try fuzzy search
@@ -443,28 +589,59 @@ func (s *System) FindTaskByFuzzyID(boardID int, idOrQuery string) (*Task, error)
 	}
 
 	if len(matches) == 0 {
-		// If it was a number that didn't match an ID and no fuzzy matches, give a clear error
-		if _, numErr := strconv.Atoi(idOrQuery); numErr == nil {
-			return nil, fmt.Errorf("no task found with ID %s and no tasks found matching '%s'", idOrQuery, idOrQuery)
-		}
-		return nil, fmt.Errorf("no tasks found matching '%s'", idOrQuery)
+		return nil, storage.Errorf(ErrNotFound, "no tasks found matching '%s'", idOrQuery)
 	}
 
 	if len(matches) == 1 {
 		return matches[0], nil
 	}
 
-	// Multiple matches - return error with suggestions
-	var suggestions []string
-	for i, match := range matches {
-		if i >= 5 { // Limit to top 5 suggestions
-			break
+	// Multiple matches - return error with the top 5 as suggestions
+	return nil, &AmbiguousError{Query: idOrQuery, Matches: matches[:min(len(matches), 5)]}
+}
+
+// FindTaskExact finds a task by ID, task number, hash or title without fuzzy
+// matching: a title must match in full, ignoring case and surrounding
+// spaces, so a mistyped reference is not found rather than taken for
+// another task
+func (s *System) FindTaskExact(boardID int, ref string) (*Task, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return s.getByNumber(id)
+	}
+	if task, err := s.GetByRef(ref); err == nil {
+		return task, nil
+	}
+	if task, err := s.findByHash(ref); task != nil || err != nil {
+		return task, err
+	}
+
+	tasks, err := s.List(boardID)
+	if err != nil {
+		return nil, err
+	}
+	var matches []*Task
+	for _, task := range tasks {
+		if strings.EqualFold(strings.TrimSpace(task.Title), strings.TrimSpace(ref)) {
+			matches = append(matches, task)
 		}
-		suggestions = append(suggestions, fmt.Sprintf("#%d %s", match.ID, match.Title))
 	}
+	switch {
+	case len(matches) == 0:
+		return nil, storage.Errorf(ErrNotFound, "no task with ID, number, hash or title '%s' (fuzzy matching is off)", ref)
+	case len(matches) > 1:
+		return nil, &AmbiguousError{Query: ref, Matches: matches[:min(len(matches), 5)]}
+	}
+	return matches[0], nil
+}
 
-	return nil, fmt.Errorf("multiple tasks match '%s':\n%s\nPlease be more specific or use the task ID",
-		idOrQuery, strings.Join(suggestions, "\n"))
+// getByNumber gets the task a reference that is a number refers to, the
+// task with that ID
+func (s *System) getByNumber(id int) (*Task, error) {
+	task, err := s.GetByID(id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, storage.Errorf(ErrNotFound, "no task found with ID %d", id)
+	}
+	return task, err
 }
 
 // fuzzyMatchScore calculates a fuzzy match score between title and query
@@ -502,80 +679,148 @@ func fuzzyMatchScore(title, query string) int {
 
 // SoftDelete marks a task as deleted without removing it from database
 func (s *System) SoftDelete(taskID int) error {
-	query := `UPDATE tasks SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL`
-	result, err := s.db.Exec(query, taskID)
-	if err != nil {
-		return fmt.Errorf("failed to soft delete task: %w", err)
-	}
+	query := `UPDATE tasks SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, updated_by = ? WHERE id = ? AND deleted_at IS NULL`
+	return s.inTx(func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(s.ctx, query, s.actor, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to soft delete task: %w", err)
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to check affected rows: %w", err)
-	}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check affected rows: %w", err)
+		}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("task %d not found or already deleted", taskID)
-	}
+		if rowsAffected == 0 {
+			return storage.Errorf(ErrNotFound, "task %d not found or already deleted", taskID)
+		}
 
-	return nil
+		return s.refreshRollups(tx, taskID)
+	})
 }
 
 // HardDelete permanently removes a task and all its links
 func (s *System) HardDelete(taskID int) error {
-	// Start transaction
-	tx, err := s.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
-	}
-	defer func() {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			// Rollback error is expected if transaction was committed successfully
-			// This is normal behavior, so we explicitly ignore the error
-			_ = rbErr
+	return s.inTx(func(tx *sql.Tx) error {
+		// Delete task links first (foreign key constraints)
+		_, err := tx.ExecContext(s.ctx, `DELETE FROM task_links WHERE from_task_id = ? OR to_task_id = ?`, taskID, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to delete task links: %w", err)
+		}
+		_, err = tx.ExecContext(s.ctx, `DELETE FROM task_board_links WHERE task_id = ?`, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to delete task links: %w", err)
 		}
-	}()
 
-	// Delete task links first (foreign key constraints)
-	_, err = tx.Exec(`DELETE FROM task_links WHERE from_task_id = ? OR to_task_id = ?`, taskID, taskID)
-	if err != nil {
-		return fmt.Errorf("failed to delete task links: %w", err)
-	}
+		// Subtasks become top-level tasks, and the parent loses the task
+		var parentID sql.NullInt64
+		if err := tx.QueryRowContext(s.ctx, `SELECT parent_id FROM tasks WHERE id = ?`, taskID).Scan(&parentID); err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to look up parent task: %w", err)
+		}
+		if _, err := tx.ExecContext(s.ctx, `UPDATE tasks SET parent_id = NULL WHERE parent_id = ?`, taskID); err != nil {
+			return fmt.Errorf("failed to detach subtasks: %w", err)
+		}
 
-	// Delete the task
-	result, err := tx.Exec(`DELETE FROM tasks WHERE id = ?`, taskID)
-	if err != nil {
-		return fmt.Errorf("failed to delete task: %w", err)
-	}
+		// Delete the task
+		result, err := tx.ExecContext(s.ctx, `DELETE FROM tasks WHERE id = ?`, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to delete task: %w", err)
+		}
 
-	rowsAffected, err := result.RowsAffected()
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return storage.Errorf(ErrNotFound, "task %d not found", taskID)
+		}
+
+		if parentID.Valid {
+			if err := s.refreshRollupFrom(tx, int(parentID.Int64)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// FindDeleted finds a soft-deleted task by ID, task number or hash, for
+// restoring it. Titles are not matched: deleted tasks are out of sight, so
+// a title could not be checked against them.
+func (s *System) FindDeleted(ref string) (*Task, error) {
+	rows, err := s.db.QueryContext(s.ctx, `SELECT `+taskColumns+` FROM tasks WHERE deleted_at IS NOT NULL ORDER BY id`)
 	if err != nil {
-		return fmt.Errorf("failed to check affected rows: %w", err)
+		return nil, fmt.Errorf("failed to list deleted tasks: %w", err)
 	}
+	defer rows.Close()
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("task %d not found", taskID)
+	id, idErr := strconv.Atoi(ref)
+	var matches []*Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		switch {
+		case idErr == nil:
+			if task.ID == id {
+				matches = append(matches, task)
+			}
+		case task.Ref != "" && strings.EqualFold(task.Ref, ref):
+			matches = append(matches, task)
+		case IsHash(ref) && strings.HasPrefix(task.Hash, strings.ToLower(ref)):
+			matches = append(matches, task)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list deleted tasks: %w", err)
 	}
 
-	return tx.Commit()
+	switch len(matches) {
+	case 0:
+		return nil, storage.Errorf(ErrNotFound, "no deleted task with ID, number or hash '%s'", ref)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, &AmbiguousError{Query: ref, Matches: matches[:min(len(matches), 5)]}
 }
 
 // RestoreTask restores a soft-deleted task
 func (s *System) RestoreTask(taskID int) error {
-	query := `UPDATE tasks SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NOT NULL`
-	result, err := s.db.Exec(query, taskID)
-	if err != nil {
-		return fmt.Errorf("failed to restore task: %w", err)
-	}
+	query := `UPDATE tasks SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP, updated_by = ? WHERE id = ? AND deleted_at IS NOT NULL`
+	return s.inTx(func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(s.ctx, query, s.actor, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to restore task: %w", err)
+		}
 
-	rowsAffected, err := result.RowsAffected()
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check affected rows: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return storage.Errorf(ErrNotFound, "task %d not found or not deleted", taskID)
+		}
+
+		return s.refreshRollups(tx, taskID)
+	})
+}
+
+// SetTimes sets when a task was created and last updated, for a task
+// brought over from another board that keeps the times it has there
+func (s *System) SetTimes(id int, createdAt, updatedAt time.Time) error {
+	const layout = "2006-01-02 15:04:05"
+	result, err := s.db.ExecContext(s.ctx, `UPDATE tasks SET created_at = ?, updated_at = ? WHERE id = ?`,
+		createdAt.UTC().Format(layout), updatedAt.UTC().Format(layout), id)
 	if err != nil {
-		return fmt.Errorf("failed to check affected rows: %w", err)
+		return fmt.Errorf("failed to set task times: %w", err)
 	}
-
-	if rowsAffected == 0 {
-		return fmt.Errorf("task %d not found or not deleted", taskID)
+	if n, _ := result.RowsAffected(); n == 0 {
+		return storage.Errorf(ErrNotFound, "task with ID %d not found", id)
 	}
-
 	return nil
 }
 
@@ -583,19 +828,23 @@ func (s *System) RestoreTask(taskID int) error {
 func (s *System) LinkTasks(fromTaskID, toTaskID int, linkType LinkType) error {
 	// Validate tasks exist
 	if _, err := s.GetByID(fromTaskID); err != nil {
-		return fmt.Errorf("from task not found: %w", err)
+		return storage.Errorf(ErrNotFound, "from task not found: %w", err)
 	}
 	if _, err := s.GetByID(toTaskID); err != nil {
-		return fmt.Errorf("to task not found: %w", err)
+		return storage.Errorf(ErrNotFound, "to task not found: %w", err)
 	}
 
 	// Prevent self-linking
 	if fromTaskID == toTaskID {
-		return fmt.Errorf("cannot link task to itself")
+		return storage.Errorf(ErrInvalidInput, "cannot link task to itself")
+	}
+
+	if err := s.checkCycle(TaskLink{FromTaskID: fromTaskID, ToTaskID: toTaskID, LinkType: linkType}); err != nil {
+		return err
 	}
 
 	query := `INSERT INTO task_links (from_task_id, to_task_id, link_type) VALUES (?, ?, ?)`
-	_, err := s.db.Exec(query, fromTaskID, toTaskID, linkType)
+	_, err := s.db.ExecContext(s.ctx, query, fromTaskID, toTaskID, linkType)
 	if err != nil {
 		return fmt.Errorf("failed to create task link: %w", err)
 	}
@@ -606,7 +855,7 @@ func (s *System) LinkTasks(fromTaskID, toTaskID int, linkType LinkType) error {
 // UnlinkTasks removes a link between two tasks
 func (s *System) UnlinkTasks(fromTaskID, toTaskID int, linkType LinkType) error {
 	query := `DELETE FROM task_links WHERE from_task_id = ? AND to_task_id = ? AND link_type = ?`
-	result, err := s.db.Exec(query, fromTaskID, toTaskID, linkType)
+	result, err := s.db.ExecContext(s.ctx, query, fromTaskID, toTaskID, linkType)
 	if err != nil {
 		return fmt.Errorf("failed to remove task link: %w", err)
 	}
@@ -617,7 +866,7 @@ func (s *System) UnlinkTasks(fromTaskID, toTaskID int, linkType LinkType) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("no link found between tasks %d and %d with type %s", fromTaskID, toTaskID, linkType)
+		return storage.Errorf(ErrNotFound, "no link found between tasks %d and %d with type %s", fromTaskID, toTaskID, linkType)
 	}
 
 	return nil
@@ -632,7 +881,7 @@ func (s *System) GetTaskLinks(taskID int) ([]TaskLink, error) {
 		ORDER BY created_at DESC
 	`
 
-	rows, err := s.db.Query(query, taskID, taskID)
+	rows, err := s.db.QueryContext(s.ctx, query, taskID, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query task links: %w", err)
 	}
@@ -648,6 +897,39 @@ func (s *System) GetTaskLinks(taskID int) ([]TaskLink, error) {
 		links = append(links, link)
 	}
 
+	boardLinks, err := s.listBoardLinks(taskID)
+	if err != nil {
+		return nil, err
+	}
+	links = append(links, boardLinks...)
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].CreatedAt.After(links[j].CreatedAt)
+	})
+
+	return links, nil
+}
+
+// ListLinks returns every link between tasks on this board
+func (s *System) ListLinks() ([]TaskLink, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, from_task_id, to_task_id, link_type, created_at
+		FROM task_links
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query task links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []TaskLink
+	for rows.Next() {
+		var link TaskLink
+		if err := rows.Scan(&link.ID, &link.FromTaskID, &link.ToTaskID, &link.LinkType, &link.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan task link: %w", err)
+		}
+		links = append(links, link)
+	}
+
 	return links, nil
 }
 
@@ -655,10 +937,21 @@ func (s *System) GetTaskLinks(taskID int) ([]TaskLink, error) {
 func ValidateTitle(title string) error {
 	title = strings.TrimSpace(title)
 	if title == "" {
-		return fmt.Errorf("task title cannot be empty")
+		return storage.Errorf(ErrInvalidInput, "task title cannot be empty")
 	}
 	if len(title) > 255 {
-		return fmt.Errorf("task title cannot exceed 255 characters")
+		return storage.Errorf(ErrInvalidInput, "task title cannot exceed 255 characters")
+	}
+	return nil
+}
+
+// ValidateEstimate validates a story point estimate
+func ValidateEstimate(points int) error {
+	if points < 0 {
+		return storage.Errorf(ErrInvalidInput, "estimate cannot be negative")
+	}
+	if points > 1000 {
+		return storage.Errorf(ErrInvalidInput, "estimate cannot exceed 1000 points")
 	}
 	return nil
 }
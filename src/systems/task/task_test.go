@@ -1,8 +1,12 @@
 package task
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
 )
 
 // setupTestSystem is commented out until storage system integration is complete
@@ -82,3 +86,93 @@ func TestValidStatuses(t *testing.T) {
 // ✅ Update task details
 // ✅ Delete task
 // ✅ Error handling for non-existent tasks
+
+func TestWithContext(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	created, err := taskSystem.Create(1, "Write the release notes", "")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bound := taskSystem.WithContext(ctx)
+	if _, err := bound.GetByID(created.ID); err != nil {
+		t.Fatalf("Expected queries to run under a live context, got %v", err)
+	}
+
+	cancel()
+	if _, err := bound.GetByID(created.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled context to stop the query, got %v", err)
+	}
+	if err := bound.UpdateStatus(created.ID, StatusDoing); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled context to stop the update, got %v", err)
+	}
+
+	// The system it was made from is not affected
+	if got, err := taskSystem.GetByID(created.ID); err != nil || got.Status != StatusTodo {
+		t.Errorf("Expected the task untouched and readable, got %v, %v", got, err)
+	}
+}
+
+func TestUpdateFields(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+	created, err := taskSystem.Create(1, "Original title", "Original description")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	check := func(step, title, description string) {
+		t.Helper()
+		got, err := taskSystem.GetByID(created.ID)
+		if err != nil {
+			t.Fatalf("%s: failed to get task: %v", step, err)
+		}
+		if got.Title != title || got.Description != description {
+			t.Errorf("%s: got %q / %q, want %q / %q", step, got.Title, got.Description, title, description)
+		}
+	}
+	str := func(s string) *string { return &s }
+
+	if err := taskSystem.UpdateFields(created.ID, UpdateOptions{Description: str("New description")}); err != nil {
+		t.Fatalf("UpdateFields() error = %v", err)
+	}
+	check("description only", "Original title", "New description")
+
+	if err := taskSystem.UpdateFields(created.ID, UpdateOptions{Title: str("New title")}); err != nil {
+		t.Fatalf("UpdateFields() error = %v", err)
+	}
+	check("title only", "New title", "New description")
+
+	if err := taskSystem.UpdateFields(created.ID, UpdateOptions{AppendDescription: "More"}); err != nil {
+		t.Fatalf("UpdateFields() error = %v", err)
+	}
+	check("append", "New title", "New description\nMore")
+
+	if err := taskSystem.UpdateFields(created.ID, UpdateOptions{Description: str(""), AppendDescription: "Fresh"}); err != nil {
+		t.Fatalf("UpdateFields() error = %v", err)
+	}
+	check("append to a cleared description", "New title", "Fresh")
+
+	if err := taskSystem.UpdateFields(created.ID, UpdateOptions{Title: str("  ")}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected an empty title to be refused, got %v", err)
+	}
+	if err := taskSystem.UpdateFields(created.ID, UpdateOptions{}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected an update of nothing to be refused, got %v", err)
+	}
+	if err := taskSystem.UpdateFields(999, UpdateOptions{Title: str("Missing")}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a missing task to be not found, got %v", err)
+	}
+	check("after refused updates", "New title", "Fresh")
+}
@@ -0,0 +1,130 @@
+package task
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// votesColumn selects the number of votes on a task
+const votesColumn = `(SELECT COUNT(*) FROM task_votes WHERE task_votes.task_id = tasks.id)`
+
+// Vote records an actor's vote for a task. Each actor has one vote per
+// task; it reports whether the vote is new.
+func (s *System) Vote(taskID int, actor string) (bool, error) {
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		return false, storage.Errorf(ErrInvalidInput, "actor cannot be empty")
+	}
+	if _, err := s.GetByID(taskID); err != nil {
+		return false, err
+	}
+
+	result, err := s.db.ExecContext(s.ctx, `INSERT OR IGNORE INTO task_votes (task_id, actor) VALUES (?, ?)`, taskID, actor)
+	if err != nil {
+		return false, fmt.Errorf("failed to add vote: %w", err)
+	}
+	added, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to add vote: %w", err)
+	}
+	return added > 0, nil
+}
+
+// Unvote withdraws an actor's vote for a task, reporting whether there was
+// one
+func (s *System) Unvote(taskID int, actor string) (bool, error) {
+	result, err := s.db.ExecContext(s.ctx, `DELETE FROM task_votes WHERE task_id = ? AND actor = ?`, taskID, strings.TrimSpace(actor))
+	if err != nil {
+		return false, fmt.Errorf("failed to remove vote: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to remove vote: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// GroomingEntry is a backlog task in the order proposed by votes
+type GroomingEntry struct {
+	Task     *Task `json:"task"`
+	Priority int   `json:"priority"` // proposed
+	Position int   `json:"position"` // proposed, from 1
+}
+
+// Changed reports whether accepting the entry would change its task
+func (e GroomingEntry) Changed() bool {
+	return e.Task.Priority != e.Priority || e.Task.Position != e.Position
+}
+
+// ProposeGrooming orders the backlog (the todo column) by votes, most first,
+// keeping the current order between tasks with as many votes. The priority
+// levels already in the backlog are dealt out again in that order, so the
+// most voted tasks take the highest ones and the backlog as a whole is no
+// more or less urgent than before.
+func (s *System) ProposeGrooming(boardID int) ([]GroomingEntry, error) {
+	backlog, err := s.ListByStatus(boardID, StatusTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	// ListByStatus returns the backlog in its current order, highest
+	// priority first
+	priorities := make([]int, len(backlog))
+	for i, t := range backlog {
+		priorities[i] = t.Priority
+	}
+
+	sort.SliceStable(backlog, func(i, j int) bool {
+		return backlog[i].Votes > backlog[j].Votes
+	})
+
+	entries := make([]GroomingEntry, len(backlog))
+	for i, t := range backlog {
+		entries[i] = GroomingEntry{Task: t, Priority: priorities[i], Position: i + 1}
+	}
+	return entries, nil
+}
+
+// SetPosition places a task within its priority; 0 leaves it unpositioned
+func (s *System) SetPosition(id, position int) error {
+	if position < 0 {
+		return storage.Errorf(ErrInvalidInput, "position cannot be negative")
+	}
+	result, err := s.db.ExecContext(s.ctx, `UPDATE tasks SET position = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ? WHERE id = ?`, position, s.actor, id)
+	if err != nil {
+		return fmt.Errorf("failed to update task position: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check update result: %w", err)
+	}
+	if rowsAffected == 0 {
+		return storage.Errorf(ErrNotFound, "task with ID %d not found", id)
+	}
+	return nil
+}
+
+// AcceptGrooming stores the proposed priorities and positions of a
+// grooming session
+func (s *System) AcceptGrooming(entries []GroomingEntry) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		for _, e := range entries {
+			if !e.Changed() {
+				continue
+			}
+			_, err := tx.ExecContext(s.ctx, `
+				UPDATE tasks SET priority = ?, position = ?, updated_at = CURRENT_TIMESTAMP, updated_by = ?
+				WHERE id = ?
+			`, e.Priority, e.Position, s.actor, e.Task.ID)
+			if err != nil {
+				return fmt.Errorf("failed to reorder task #%d: %w", e.Task.ID, err)
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,106 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestGrooming(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := New(db.Conn())
+
+	create := func(title, priority string) *Task {
+		created, err := taskSystem.CreateWithPriority(1, title, "", priority)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		return created
+	}
+	vote := func(id int, actors ...string) {
+		for _, actor := range actors {
+			if added, err := taskSystem.Vote(id, actor); err != nil || !added {
+				t.Fatalf("Vote(%d, %s) = %v, %v", id, actor, added, err)
+			}
+		}
+	}
+
+	urgent := create("Urgent but unloved", "high")
+	create("Nice to have", "low")
+	popular := create("Everyone wants this", "none")
+	liked := create("Some want this", "low")
+
+	vote(popular.ID, "alice", "bob", "carol")
+	vote(liked.ID, "alice")
+	if added, err := taskSystem.Vote(popular.ID, "bob"); err != nil || added {
+		t.Errorf("Expected a second vote by the same actor to be ignored, got %v, %v", added, err)
+	}
+
+	entries, err := taskSystem.ProposeGrooming(1)
+	if err != nil {
+		t.Fatalf("ProposeGrooming() error = %v", err)
+	}
+
+	want := []struct {
+		title    string
+		priority int
+	}{
+		{"Everyone wants this", PriorityHigh},
+		{"Some want this", PriorityLow},
+		{"Urgent but unloved", PriorityLow},
+		{"Nice to have", PriorityNone},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %d", len(want), len(entries))
+	}
+	for i, w := range want {
+		if entries[i].Task.Title != w.title || entries[i].Priority != w.priority || entries[i].Position != i+1 {
+			t.Errorf("Entry %d = %s (priority %d, position %d), want %s (priority %d)",
+				i, entries[i].Task.Title, entries[i].Priority, entries[i].Position, w.title, w.priority)
+		}
+	}
+	if entries[0].Task.Votes != 3 {
+		t.Errorf("Votes = %d, want 3", entries[0].Task.Votes)
+	}
+
+	if err := taskSystem.AcceptGrooming(entries); err != nil {
+		t.Fatalf("AcceptGrooming() error = %v", err)
+	}
+
+	// Equal priorities keep the accepted order instead of falling back to age
+	backlog, err := taskSystem.ListByStatus(1, StatusTodo)
+	if err != nil {
+		t.Fatalf("Failed to list backlog: %v", err)
+	}
+	for i, w := range want {
+		if backlog[i].Title != w.title {
+			t.Errorf("Backlog[%d] = %s, want %s", i, backlog[i].Title, w.title)
+		}
+	}
+
+	// Accepting again changes nothing
+	entries, err = taskSystem.ProposeGrooming(1)
+	if err != nil {
+		t.Fatalf("ProposeGrooming() error = %v", err)
+	}
+	for _, e := range entries {
+		if e.Changed() {
+			t.Errorf("Expected a groomed backlog to be stable, %s would change", e.Task.Title)
+		}
+	}
+
+	if removed, err := taskSystem.Unvote(popular.ID, "alice"); err != nil || !removed {
+		t.Errorf("Unvote() = %v, %v", removed, err)
+	}
+	if _, err := taskSystem.Vote(urgent.ID, ""); err == nil {
+		t.Error("Expected error for empty actor")
+	}
+	if _, err := taskSystem.Vote(999, "alice"); err == nil {
+		t.Error("Expected error for missing task")
+	}
+}
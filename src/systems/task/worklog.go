@@ -0,0 +1,112 @@
+package task
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+// Outcome is how a step of work on a task ended
+type Outcome string
+
+const (
+	OutcomeNone    Outcome = ""
+	OutcomeSuccess Outcome = "success"
+	OutcomePartial Outcome = "partial"
+	OutcomeFailed  Outcome = "failed"
+	OutcomeBlocked Outcome = "blocked"
+)
+
+// ParseOutcome converts an outcome name; "" and "none" leave it unset
+func ParseOutcome(name string) (Outcome, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none":
+		return OutcomeNone, nil
+	case string(OutcomeSuccess), string(OutcomePartial), string(OutcomeFailed), string(OutcomeBlocked):
+		return Outcome(strings.ToLower(strings.TrimSpace(name))), nil
+	default:
+		return OutcomeNone, storage.Errorf(ErrInvalidInput, "invalid outcome: %s (must be success, partial, failed or blocked)", name)
+	}
+}
+
+// Worklog is an entry in the trace of the work done on a task, such as one
+// step of an agent's session, so it stays with the task rather than in a
+// chat history
+type Worklog struct {
+	ID           int       `json:"id"`
+	TaskID       int       `json:"task_id"`
+	Agent        string    `json:"agent,omitempty"`
+	Summary      string    `json:"summary"`
+	FilesChanged []string  `json:"files_changed,omitempty"`
+	Outcome      Outcome   `json:"outcome,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AppendWorklog adds an entry to the worklog of a task. The entry is
+// recorded as made by the system's actor unless it names its agent; its
+// ID, task and time are filled in.
+func (s *System) AppendWorklog(taskID int, entry Worklog) (*Worklog, error) {
+	entry.Summary = strings.TrimSpace(entry.Summary)
+	if entry.Summary == "" {
+		return nil, storage.Errorf(ErrInvalidInput, "worklog summary cannot be empty")
+	}
+	outcome, err := ParseOutcome(string(entry.Outcome))
+	if err != nil {
+		return nil, err
+	}
+	entry.Outcome = outcome
+	if entry.Agent = strings.TrimSpace(entry.Agent); entry.Agent == "" {
+		entry.Agent = s.actor
+	}
+	var files []string
+	for _, file := range entry.FilesChanged {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	entry.FilesChanged = files
+
+	if _, err := s.GetByID(taskID); err != nil {
+		return nil, err
+	}
+
+	entry.TaskID = taskID
+	err = s.db.QueryRowContext(s.ctx, `
+		INSERT INTO task_worklogs (task_id, agent, summary, files_changed, outcome) VALUES (?, ?, ?, ?, ?)
+		RETURNING id, created_at
+	`, taskID, entry.Agent, entry.Summary, strings.Join(files, "\n"), entry.Outcome).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add worklog entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// ListWorklogs returns the worklog of a task, oldest entry first
+func (s *System) ListWorklogs(taskID int) ([]Worklog, error) {
+	rows, err := s.db.QueryContext(s.ctx, `
+		SELECT id, task_id, agent, summary, files_changed, outcome, created_at
+		FROM task_worklogs
+		WHERE task_id = ?
+		ORDER BY created_at ASC, id ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query worklog: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Worklog
+	for rows.Next() {
+		var w Worklog
+		var files string
+		if err := rows.Scan(&w.ID, &w.TaskID, &w.Agent, &w.Summary, &files, &w.Outcome, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan worklog entry: %w", err)
+		}
+		if files != "" {
+			w.FilesChanged = strings.Split(files, "\n")
+		}
+		entries = append(entries, w)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,58 @@
+package task
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestWorklog(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	tasks := New(db.Conn()).WithActor("coder")
+	created, _ := tasks.Create(1, "Speed up parser", "")
+
+	first, err := tasks.AppendWorklog(created.ID, Worklog{
+		Summary:      "Profiled the tokenizer",
+		FilesChanged: []string{"parser.go", " ", "lexer.go"},
+		Outcome:      "Success",
+	})
+	if err != nil {
+		t.Fatalf("AppendWorklog() error = %v", err)
+	}
+	if first.Agent != "coder" || first.Outcome != OutcomeSuccess || len(first.FilesChanged) != 2 {
+		t.Errorf("Unexpected entry: %+v", first)
+	}
+	if _, err := tasks.AppendWorklog(created.ID, Worklog{Agent: "reviewer", Summary: "Tests still fail on Windows", Outcome: OutcomeBlocked}); err != nil {
+		t.Fatalf("AppendWorklog() error = %v", err)
+	}
+
+	entries, err := tasks.ListWorklogs(created.ID)
+	if err != nil {
+		t.Fatalf("ListWorklogs() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %+v", entries)
+	}
+	if !reflect.DeepEqual(entries[0].FilesChanged, []string{"parser.go", "lexer.go"}) {
+		t.Errorf("FilesChanged = %q", entries[0].FilesChanged)
+	}
+	if entries[1].Agent != "reviewer" || entries[1].FilesChanged != nil {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+
+	for _, entry := range []Worklog{{Summary: " "}, {Summary: "Done", Outcome: "great"}} {
+		if _, err := tasks.AppendWorklog(created.ID, entry); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("AppendWorklog(%+v) error = %v, want ErrInvalidInput", entry, err)
+		}
+	}
+	if _, err := tasks.AppendWorklog(999, Worklog{Summary: "Lost"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
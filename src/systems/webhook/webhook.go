@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Timeout bounds how long a webhook may take so a slow endpoint never
+// blocks the CLI for long
+const Timeout = 10 * time.Second
+
+// Payload is the JSON body posted to webhooks
+type Payload struct {
+	Event string      `json:"event"`
+	Board string      `json:"board,omitempty"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data"`
+}
+
+// Post sends an event as JSON to url. Any non-2xx response is an error.
+func Post(url, event, board string, data interface{}) error {
+	body, err := json.Marshal(Payload{Event: event, Board: board, Time: time.Now().UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cainban")
+
+	client := &http.Client{Timeout: Timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
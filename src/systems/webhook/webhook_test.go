@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPost(t *testing.T) {
+	var received Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Failed to decode payload: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	if err := Post(server.URL, "task_handoff", "work", map[string]int{"task_id": 7}); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if received.Event != "task_handoff" || received.Board != "work" || received.Time.IsZero() {
+		t.Errorf("Unexpected payload: %+v", received)
+	}
+	if data, ok := received.Data.(map[string]interface{}); !ok || data["task_id"] != float64(7) {
+		t.Errorf("Unexpected payload data: %v", received.Data)
+	}
+}
+
+func TestPost_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if err := Post(server.URL, "task_handoff", "", nil); err == nil {
+		t.Error("Expected error for 500 response")
+	}
+	if err := Post("://bad", "task_handoff", "", nil); err == nil {
+		t.Error("Expected error for invalid URL")
+	}
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// AsyncDoneMsg carries the result of the operation started by runAsync
+// with the same ID
+type AsyncDoneMsg struct {
+	ID  int
+	Msg tea.Msg
+}
+
+// asyncOp is an operation running in the background while the board stays
+// usable; cancel tells it to give up
+type asyncOp struct {
+	id     int
+	label  string
+	cancel context.CancelFunc
+}
+
+// newSpinner returns the spinner shown while an operation runs
+func newSpinner(palette Palette) spinner.Model {
+	return spinner.New(
+		spinner.WithSpinner(spinner.MiniDot),
+		spinner.WithStyle(lipgloss.NewStyle().Foreground(palette.Primary)),
+	)
+}
+
+// runAsync runs work in the background, showing label with a spinner in
+// the status bar until it is done. Esc cancels it: the context handed to
+// work, which its queries should run under, is cancelled and whatever it
+// returns is thrown away. Only one
+// operation runs at a time; starting another cancels the first.
+func (m *Model) runAsync(label string, work func(ctx context.Context) tea.Msg) tea.Cmd {
+	m.cancelAsync()
+	m.asyncID++
+	ctx, cancel := context.WithCancel(context.Background())
+	m.busy = &asyncOp{id: m.asyncID, label: label, cancel: cancel}
+
+	id := m.asyncID
+	run := func() tea.Msg {
+		return AsyncDoneMsg{ID: id, Msg: work(ctx)}
+	}
+	if m.replaying {
+		// A macro waits for each key's commands, so the spinner would
+		// only hold it up
+		return run
+	}
+	return tea.Batch(run, m.spinner.Tick)
+}
+
+// cancelAsync stops waiting for the running operation, if any, and
+// reports whether there was one
+func (m *Model) cancelAsync() bool {
+	if m.busy == nil {
+		return false
+	}
+	m.busy.cancel()
+	m.busy = nil
+	return true
+}
+
+// finishAsync hands the result of an operation on to Update, unless the
+// operation was cancelled or replaced in the meantime
+func (m Model) finishAsync(msg AsyncDoneMsg) (tea.Model, tea.Cmd) {
+	if m.busy == nil || msg.ID != m.busy.id {
+		return m, nil
+	}
+	m.busy.cancel()
+	m.busy = nil
+	return m.Update(msg.Msg)
+}
+
+// handleCancelKey cancels the running operation with a notice
+func (m Model) handleCancelKey() (tea.Model, tea.Cmd) {
+	label := m.busy.label
+	m.cancelAsync()
+	return m, m.showNotice(fmt.Sprintf("Cancelled: %s", label))
+}
+
+// renderBusy is the status bar while an operation runs
+func (m Model) renderBusy() string {
+	return m.spinner.View() + " " + m.busy.label + "… • esc: cancel"
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/storage"
+)
+
+func TestRunAsync(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	model := NewModel(db, Options{Board: "test", Theme: "no-color"})
+	model = run(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	// The operation reports back with a notice once it is done
+	release := make(chan struct{})
+	work := func(ctx context.Context) tea.Msg {
+		select {
+		case <-release:
+			return ErrorMsg{Err: context.DeadlineExceeded}
+		case <-ctx.Done():
+			return ErrorMsg{Err: ctx.Err()}
+		}
+	}
+	update := func(msg tea.Msg) tea.Cmd {
+		updated, cmd := model.Update(msg)
+		m := updated.(Model)
+		model = &m
+		return cmd
+	}
+
+	// The batch holds the work and the spinner tick; only the work is run
+	cmd := model.runAsync("Syncing", work)
+	first := cmd().(tea.BatchMsg)[0]
+	if view := model.View(); !strings.Contains(view, "Syncing… • esc: cancel") {
+		t.Errorf("Expected the spinner in the status bar, got:\n%s", view)
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.busy != nil {
+		t.Fatal("Expected esc to cancel the operation")
+	}
+	if !strings.Contains(model.notice, "Cancelled: Syncing") {
+		t.Errorf("Expected a notice of the cancellation, got %q", model.notice)
+	}
+
+	// The cancelled operation sees its context done, and its result is
+	// thrown away
+	model.notice = ""
+	update(first())
+	if model.notice != "" {
+		t.Errorf("Expected the result of a cancelled operation to be ignored, got notice %q", model.notice)
+	}
+
+	// A finished operation hands its result on
+	second := model.runAsync("Syncing", work)().(tea.BatchMsg)[0]
+	close(release)
+	update(second())
+	if model.busy != nil {
+		t.Error("Expected the operation to be done")
+	}
+	if !strings.Contains(model.notice, "deadline exceeded") {
+		t.Errorf("Expected the result to be handled, got notice %q", model.notice)
+	}
+
+	// Without an operation, esc is left to the board
+	update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.busy != nil || strings.Contains(model.notice, "Cancelled") {
+		t.Errorf("Expected esc to do nothing special, got notice %q", model.notice)
+	}
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+const (
+	// cardIndent lines the details of a card up under its title
+	cardIndent = "    "
+	// cardDescriptionLines and cardBadgeLines cap how tall a detailed
+	// card grows
+	cardDescriptionLines = 2
+	cardBadgeLines       = 2
+)
+
+// cardDetails renders the lines a detailed card shows under its title: a
+// snippet of the description, then badges for tags, the due date, blockers
+// and links, each fitted to width
+func (m Model) cardDetails(t *task.Task, width int, now time.Time) []string {
+	palette := m.styles.Palette
+	muted := lipgloss.NewStyle().Foreground(palette.Muted)
+	danger := lipgloss.NewStyle().Foreground(palette.Danger).Bold(true)
+
+	var lines []string
+	for _, line := range fitWords(strings.Fields(t.Description), width, cardDescriptionLines) {
+		lines = append(lines, muted.Render(line))
+	}
+
+	badges := append([]string(nil), t.Contexts...)
+	if t.DueAt != nil {
+		due := "📅 " + formatCardDate(*t.DueAt, now)
+		if t.IsOverdue(now) {
+			due = danger.Render("📅 overdue " + formatCardDate(*t.DueAt, now))
+		}
+		badges = append(badges, due)
+	}
+	if t.IsBlocked() {
+		ids := make([]string, len(t.BlockedBy))
+		for i, id := range t.BlockedBy {
+			ids[i] = fmt.Sprintf("#%d", id)
+		}
+		badges = append(badges, danger.Render("🚫 waiting on "+strings.Join(ids, ",")))
+	}
+	if n := m.links[t.ID]; n == 1 {
+		badges = append(badges, "🔗 1 link")
+	} else if n > 1 {
+		badges = append(badges, fmt.Sprintf("🔗 %d links", n))
+	}
+	lines = append(lines, fitWords(badges, width, cardBadgeLines)...)
+
+	for i, line := range lines {
+		lines[i] = cardIndent + line
+	}
+	return lines
+}
+
+// formatCardDate shows a due date briefly: today, tomorrow, a weekday within
+// the week, otherwise the date
+func formatCardDate(due, now time.Time) string {
+	due = due.Local()
+	day := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	}
+	switch days := int(day(due).Sub(day(now.Local())).Hours() / 24); {
+	case days == 0:
+		return "today"
+	case days == 1:
+		return "tomorrow"
+	case days > 1 && days < 7:
+		return due.Format("Mon")
+	default:
+		return due.Format("Jan 2")
+	}
+}
+
+// fitWords lays words out in at most maxLines lines of width cells,
+// breaking only between words. A word too wide for a line is cut, and when
+// the words run out of lines the last one ends in "…". Words may be styled.
+func fitWords(words []string, width, maxLines int) []string {
+	var lines []string
+	line := ""
+	for _, word := range words {
+		word = ansi.Truncate(word, width, "…")
+		switch {
+		case line == "":
+			line = word
+		case ansi.StringWidth(line)+1+ansi.StringWidth(word) <= width:
+			line += " " + word
+		case len(lines) == maxLines-1:
+			return append(lines, ansi.Truncate(line+" …", width, "…"))
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
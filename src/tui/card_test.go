@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestFitWords(t *testing.T) {
+	tests := []struct {
+		words    string
+		width    int
+		maxLines int
+		want     []string
+	}{
+		{"", 10, 2, nil},
+		{"fits on one", 20, 2, []string{"fits on one"}},
+		{"breaks between the words", 12, 3, []string{"breaks", "between the", "words"}},
+		{"runs out of lines before the end", 12, 2, []string{"runs out of", "lines befor…"}},
+		{"unbreakablewordthatistoolong", 10, 2, []string{"unbreakab…"}},
+	}
+	for _, tt := range tests {
+		if got := fitWords(strings.Fields(tt.words), tt.width, tt.maxLines); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fitWords(%q, %d, %d) = %q, want %q", tt.words, tt.width, tt.maxLines, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCardDate(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local) // a Wednesday
+	tests := []struct {
+		days int
+		want string
+	}{
+		{0, "today"}, {1, "tomorrow"}, {3, "Sat"}, {9, "Oct 23"}, {-2, "Oct 12"},
+	}
+	for _, tt := range tests {
+		if got := formatCardDate(now.AddDate(0, 0, tt.days), now); got != tt.want {
+			t.Errorf("formatCardDate(%+d days) = %q, want %q", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestDetailedCards(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	blocker, _ := taskSystem.Create(1, "Get sign-off", "")
+	report, _ := taskSystem.Create(1, "Write the report", "Summarize the quarter for the board meeting")
+	if err := taskSystem.AddContext(report.ID, "@office"); err != nil {
+		t.Fatalf("AddContext: %v", err)
+	}
+	due := time.Now().AddDate(0, 0, -1)
+	if err := taskSystem.SetDue(report.ID, &due); err != nil {
+		t.Fatalf("SetDue: %v", err)
+	}
+	if err := taskSystem.LinkTasks(blocker.ID, report.ID, task.LinkTypeBlocks); err != nil {
+		t.Fatalf("LinkTasks: %v", err)
+	}
+
+	model := NewModel(db, Options{Board: "test", Theme: "no-color"})
+	model = run(model, tea.WindowSizeMsg{Width: 160, Height: 50})
+	model = run(model, model.refreshTasks()())
+	column := func() string {
+		return model.viewports[ColumnTodo].View()
+	}
+
+	if strings.Contains(column(), "Summarize") {
+		t.Fatalf("Expected plain cards by default, got:\n%s", column())
+	}
+
+	model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if !model.detailed {
+		t.Fatal("Expected i to turn on detailed cards")
+	}
+	view := column()
+	for _, want := range []string{"Summarize the quarter", "@office", "overdue", "waiting on #" + strconv.Itoa(blocker.ID), "1 link"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the detailed card to show %q, got:\n%s", want, view)
+		}
+	}
+
+	model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if model.detailed || strings.Contains(column(), "Summarize") {
+		t.Errorf("Expected i to turn detailed cards off again, got:\n%s", column())
+	}
+}
@@ -1,7 +1,11 @@
 package tui
 
 import (
+	"time"
+
 	"github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/milestone"
 	"github.com/hmain/cainban/src/systems/task"
 )
 
@@ -9,7 +13,35 @@ import (
 
 // TasksRefreshedMsg is sent when tasks are refreshed from the database
 type TasksRefreshedMsg struct {
-	Tasks map[task.Status][]*task.Task
+	Tasks       map[task.Status][]*task.Task
+	Contexts    []string
+	Milestones  []*milestone.Milestone
+	ColumnNotes map[task.Status]task.ColumnNote
+	// Links counts the links of each task
+	Links map[int]int
+}
+
+// DatabasePolledMsg is sent after checking the database for changes made
+// outside the TUI
+type DatabasePolledMsg struct {
+	Changed bool
+}
+
+// TaskDeletedMsg is sent when a task has been deleted; Hard is true when it
+// is gone for good rather than in the trash
+type TaskDeletedMsg struct {
+	Task *task.Task
+	Hard bool
+}
+
+// TaskRestoredMsg is sent when a deleted task has been brought back
+type TaskRestoredMsg struct {
+	Task *task.Task
+}
+
+// NoticeExpiredMsg is sent when the status bar notice with ID should go
+type NoticeExpiredMsg struct {
+	ID int
 }
 
 // ErrorMsg is sent when an error occurs
@@ -19,75 +51,223 @@ type ErrorMsg struct {
 
 // Commands for the TUI
 
+// pollInterval is how often the database is checked for changes made
+// outside the TUI
+const pollInterval = time.Second
+
+// watchDatabase checks the database for changes after pollInterval. It
+// does nothing when the board is not being watched.
+func (m Model) watchDatabase() tea.Cmd {
+	if m.watcher == nil {
+		return nil
+	}
+	return tea.Tick(pollInterval, func(_ time.Time) tea.Msg {
+		// A failed check counts as no change and is retried next time
+		changed, _ := m.watcher.Changed()
+		return DatabasePolledMsg{Changed: changed}
+	})
+}
+
 // refreshTasks loads all tasks from the database
 func (m Model) refreshTasks() tea.Cmd {
 	return func() tea.Msg {
 		// Get current board ID (assuming board ID 1 for now)
 		boardID := 1 // TODO: Get actual board ID from board system
 		
+		// Bring recurring tasks completed in an earlier period back to todo
+		_, _ = m.taskSystem.ResetRecurring(boardID, time.Now())
+		
 		// Load tasks by status
 		tasks := make(map[task.Status][]*task.Task)
 		
-		// Load todo tasks
-		todoTasks, err := m.taskSystem.ListByStatus(boardID, task.StatusTodo)
+		// Load todo tasks, each column in the board's order, of the active
+		// milestone only when there is one
+		todoTasks, err := m.taskSystem.ListWith(boardID, task.ListOptions{Status: task.StatusTodo, BoardOrder: true, MilestoneID: m.milestone})
 		if err == nil {
 			tasks[task.StatusTodo] = todoTasks
 		}
 		
 		// Load doing tasks  
-		doingTasks, err := m.taskSystem.ListByStatus(boardID, task.StatusDoing)
+		doingTasks, err := m.taskSystem.ListWith(boardID, task.ListOptions{Status: task.StatusDoing, BoardOrder: true, MilestoneID: m.milestone})
 		if err == nil {
 			tasks[task.StatusDoing] = doingTasks
 		}
 		
 		// Load done tasks
-		doneTasks, err := m.taskSystem.ListByStatus(boardID, task.StatusDone) 
+		doneTasks, err := m.taskSystem.ListWith(boardID, task.ListOptions{Status: task.StatusDone, BoardOrder: true, MilestoneID: m.milestone})
 		if err == nil {
 			tasks[task.StatusDone] = doneTasks
 		}
 		
-		return TasksRefreshedMsg{Tasks: tasks}
+		// Only show tasks in the active GTD context
+		if m.context != "" {
+			for status, statusTasks := range tasks {
+				tasks[status] = task.FilterByContext(statusTasks, m.context)
+			}
+		}
+		
+		var contexts []string
+		if counts, err := m.taskSystem.ListContexts(boardID); err == nil {
+			for _, c := range counts {
+				contexts = append(contexts, c.Context)
+			}
+		}
+		
+		var milestones []*milestone.Milestone
+		if m.milestoneSystem != nil {
+			milestones, _ = m.milestoneSystem.List(boardID)
+		}
+		
+		columnNotes, _ := m.taskSystem.ColumnNotes()
+		
+		links := make(map[int]int)
+		if all, err := m.taskSystem.ListLinks(); err == nil {
+			for _, link := range all {
+				links[link.FromTaskID]++
+				links[link.ToTaskID]++
+			}
+		}
+		
+		refreshed := TasksRefreshedMsg{Tasks: tasks, Contexts: contexts, Milestones: milestones, ColumnNotes: columnNotes, Links: links}
+		
+		// The copy of a remote board sends the changes as they are made,
+		// not only when the TUI quits
+		if m.storage != nil {
+			if err := m.storage.Flush(); err != nil {
+				return tea.BatchMsg{
+					func() tea.Msg { return refreshed },
+					func() tea.Msg { return ErrorMsg{Err: err} },
+				}
+			}
+		}
+		return refreshed
 	}
 }
 
 // moveTask moves a task to a new status
 func (m Model) moveTask(taskID int, newStatus task.Status) tea.Cmd {
 	return func() tea.Msg {
-		err := m.taskSystem.UpdateStatus(taskID, newStatus)
+		if m.hooks == nil {
+			if err := m.taskSystem.UpdateStatus(taskID, newStatus); err != nil {
+				return ErrorMsg{Err: err}
+			}
+			
+			// Refresh tasks after move
+			return m.refreshTasks()()
+		}
+		
+		t, err := m.taskSystem.GetByID(taskID)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		from := t.Status
+		if err := m.runHook(automation.HookPreMove, t, from, newStatus); err != nil {
+			return ErrorMsg{Err: err}
+		}
+		if err := m.taskSystem.UpdateStatus(taskID, newStatus); err != nil {
+			return ErrorMsg{Err: err}
+		}
+		t.Status = newStatus
+		return m.afterHook(automation.HookPostMove, t, from, newStatus, m.refreshTasks()())
+	}
+}
+
+// setPriority changes the priority of a task
+func (m Model) setPriority(taskID, priority int) tea.Cmd {
+	return func() tea.Msg {
+		err := m.taskSystem.UpdatePriority(taskID, priority)
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
 		
-		// Refresh tasks after move
+		// Refresh tasks after the change
 		return m.refreshTasks()()
 	}
 }
 
-// deleteTask deletes a task
-func (m Model) deleteTask(taskID int) tea.Cmd {
+// reorderTask moves a task past another one in its column
+func (m Model) reorderTask(taskID, otherID int) tea.Cmd {
 	return func() tea.Msg {
-		err := m.taskSystem.Delete(taskID)
+		err := m.taskSystem.MovePast(taskID, otherID)
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
 		
-		// Refresh tasks after deletion
+		// Refresh tasks after reordering
 		return m.refreshTasks()()
 	}
 }
 
+// deleteTask moves a task to the trash, or deletes it for good when hard
+// is true
+func (m Model) deleteTask(t *task.Task, hard bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.runHook(automation.HookPreDelete, t, "", ""); err != nil {
+			return ErrorMsg{Err: err}
+		}
+		var err error
+		if hard {
+			err = m.taskSystem.HardDelete(t.ID)
+		} else {
+			err = m.taskSystem.Delete(t.ID)
+		}
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return m.afterHook(automation.HookPostDelete, t, "", "", TaskDeletedMsg{Task: t, Hard: hard})
+	}
+}
+
+// restoreTask brings a task back from the trash
+func (m Model) restoreTask(t *task.Task) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.taskSystem.RestoreTask(t.ID); err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return TaskRestoredMsg{Task: t}
+	}
+}
+
+// noticeTimeout is how long a status bar notice stays up
+const noticeTimeout = 5 * time.Second
+
+// showNotice puts a message in the status bar for noticeTimeout
+func (m *Model) showNotice(notice string) tea.Cmd {
+	m.notice = notice
+	m.noticeID++
+	if m.replaying {
+		// The notice left at the end of the replay expires then
+		return nil
+	}
+	return expireNotice(m.noticeID)
+}
+
+// expireNotice clears notice id once it has been shown long enough
+func expireNotice(id int) tea.Cmd {
+	return tea.Tick(noticeTimeout, func(_ time.Time) tea.Msg {
+		return NoticeExpiredMsg{ID: id}
+	})
+}
+
 // createTask creates a new task
 func (m Model) createTask(title, description string) tea.Cmd {
 	return func() tea.Msg {
 		// Get current board ID (assuming board ID 1 for now)
 		boardID := 1 // TODO: Get actual board ID from board system
 		
-		_, err := m.taskSystem.Create(boardID, title, description)
+		// Bring recurring tasks completed in an earlier period back to todo
+		_, _ = m.taskSystem.ResetRecurring(boardID, time.Now())
+		
+		draft := &task.Task{BoardID: boardID, Title: title, Description: description, Status: task.StatusTodo}
+		if err := m.runHook(automation.HookPreCreate, draft, "", task.StatusTodo); err != nil {
+			return ErrorMsg{Err: err}
+		}
+		created, err := m.taskSystem.Create(boardID, title, description)
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
 		
 		// Refresh tasks after creation
-		return m.refreshTasks()()
+		return m.afterHook(automation.HookPostCreate, created, "", task.StatusTodo, m.refreshTasks()())
 	}
 }
\ No newline at end of file
@@ -0,0 +1,121 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hmain/cainban/src/systems/markdown"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+const (
+	// splitMinWidth is how wide the terminal has to be for the board to
+	// share it with a detail pane
+	splitMinWidth = 160
+	// detailMinWidth and detailMaxWidth bound the detail pane, which
+	// takes about a third of the terminal
+	detailMinWidth = 40
+	detailMaxWidth = 70
+)
+
+// selectedTaskInFocus returns the selected task of the focused column, or
+// nil when the column is empty
+func (m Model) selectedTaskInFocus() *task.Task {
+	tasks := m.tasks[m.columnToStatus(m.focused)]
+	if index := m.selectedTask[m.focused]; index < len(tasks) {
+		return tasks[index]
+	}
+	return nil
+}
+
+// renderDetailPane shows everything about the selected task in a pane of
+// width by height cells, following the selection as it moves
+func (m Model) renderDetailPane(width, height int) string {
+	palette := m.styles.Palette
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(palette.Border).
+		Padding(1).
+		Width(width - 2).
+		Height(height - 2)
+	inner := width - 4
+	muted := lipgloss.NewStyle().Foreground(palette.Muted)
+
+	t := m.selectedTaskInFocus()
+	if t == nil {
+		return style.Render(muted.Render("No task selected"))
+	}
+
+	danger := lipgloss.NewStyle().Foreground(palette.Danger).Bold(true)
+	wrap := lipgloss.NewStyle().Width(inner)
+	now := time.Now()
+
+	id := fmt.Sprintf("#%d", t.ID)
+	if t.Ref != "" {
+		id += " " + t.Ref
+	}
+	lines := []string{
+		wrap.Copy().Bold(true).Foreground(palette.Primary).Render(t.Title),
+		muted.Render(fmt.Sprintf("%s • %s • %s priority", id, t.Status, task.GetPriorityName(t.Priority))),
+		"",
+	}
+
+	field := func(name, value string) {
+		lines = append(lines, wrap.Render(muted.Render(name+": ")+value))
+	}
+	if t.Assignee != "" {
+		field("Assignee", t.Assignee)
+	}
+	if t.Estimate > 0 {
+		field("Estimate", fmt.Sprintf("%d points", t.Estimate))
+	}
+	if t.DueAt != nil {
+		due := t.DueAt.Local().Format("Mon Jan 2 15:04")
+		if t.IsOverdue(now) {
+			due = danger.Render("overdue since " + due)
+		}
+		field("Due", due)
+	}
+	if t.Recurrence != task.RecurrenceNone {
+		field("Repeats", string(t.Recurrence))
+	}
+	if len(t.Contexts) > 0 {
+		field("Tags", strings.Join(t.Contexts, " "))
+	}
+	if t.IsBlocked() {
+		ids := make([]string, len(t.BlockedBy))
+		for i, id := range t.BlockedBy {
+			ids[i] = fmt.Sprintf("#%d", id)
+		}
+		field("Waiting on", danger.Render(strings.Join(ids, ", ")))
+	}
+	if n := m.links[t.ID]; n > 0 {
+		field("Links", fmt.Sprintf("%d", n))
+	}
+	if t.Rollup != nil {
+		field("Subtasks", t.Rollup.String())
+	}
+	if len(t.Reactions) > 0 {
+		field("Reactions", task.FormatReactions(t.Reactions))
+	}
+	if t.Hash != "" {
+		field("Hash", t.ShortHash())
+	}
+	field("Created", t.CreatedAt.Local().Format("Jan 2 2006 15:04"))
+	if t.IsStale(now, m.staleAfter) {
+		field("Updated", danger.Render(fmt.Sprintf("💤 %s, no update for %d days", t.UpdatedAt.Local().Format("Jan 2 2006 15:04"), t.StaleDays(now))))
+	}
+
+	if description := strings.TrimSpace(t.Description); description != "" {
+		lines = append(lines, "", markdown.Render(description, inner, palette.MarkdownStyles()))
+	}
+
+	// Cut what does not fit rather than let the pane outgrow the board
+	content := strings.Split(strings.Join(lines, "\n"), "\n")
+	if max := height - 4; max > 0 && len(content) > max {
+		content = append(content[:max-1], muted.Render("…"))
+	}
+	return style.Render(strings.Join(content, "\n"))
+}
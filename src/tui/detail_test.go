@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestSplitView(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	invoice, _ := taskSystem.Create(1, "Send the invoice", "Net 30, to the **finance** address\n\n- [ ] attach the receipts")
+	if _, err := taskSystem.Assign(invoice.ID, "alice"); err != nil {
+		t.Fatalf("Assign: %v", err)
+	}
+	if _, err := taskSystem.Create(1, "Book the venue", "Somewhere with a projector"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	model := NewModel(db, Options{Board: "test", Theme: "no-color"})
+	model = run(model, model.refreshTasks()())
+
+	model = run(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	if view := model.View(); strings.Contains(view, "Net 30") {
+		t.Errorf("Expected no detail pane on a narrow terminal, got:\n%s", view)
+	}
+
+	model = run(model, tea.WindowSizeMsg{Width: 180, Height: 40})
+	view := model.View()
+	for _, want := range []string{"Assignee: alice", "Net 30, to the finance address", "☐ attach the receipts"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the detail pane to show %q, got:\n%s", want, view)
+		}
+	}
+	for _, line := range strings.Split(view, "\n") {
+		if width := ansi.StringWidth(line); width > 180 {
+			t.Fatalf("Expected the split view to fit the terminal, got a line %d wide:\n%s", width, view)
+		}
+	}
+
+	// The pane follows the selection
+	model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if view := model.View(); !strings.Contains(view, "Somewhere with a projector") {
+		t.Errorf("Expected the detail pane to follow the selection, got:\n%s", view)
+	}
+}
+
+func TestStaleTasks(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	migrate, _ := taskSystem.Create(1, "Migrate the database", "")
+	if _, err := taskSystem.Create(1, "Book the venue", ""); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := db.Conn().Exec(`UPDATE tasks SET updated_at = datetime('now', '-10 days') WHERE id = ?`, migrate.ID); err != nil {
+		t.Fatalf("Failed to backdate task: %v", err)
+	}
+
+	model := NewModel(db, Options{Board: "test", Theme: "no-color", StaleAfter: 7 * 24 * time.Hour})
+	model = run(model, model.refreshTasks()())
+	model = run(model, tea.WindowSizeMsg{Width: 180, Height: 40})
+
+	view := model.View()
+	if !strings.Contains(view, "Migrate the database 💤") || strings.Contains(view, "Book the venue 💤") {
+		t.Errorf("Expected only the task without an update for 10 days to be marked stale, got:\n%s", view)
+	}
+	for i, tk := range model.tasks[task.StatusTodo] {
+		if tk.ID == migrate.ID {
+			model.selectedTask[ColumnTodo] = i
+		}
+	}
+	if view := model.View(); !strings.Contains(view, "no update for 10 days") {
+		t.Errorf("Expected the detail pane to say how long the task went without an update, got:\n%s", view)
+	}
+}
@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// FocusTickMsg advances the focus timer; ID tells the ticks of the current
+// focus session apart from those of an earlier one
+type FocusTickMsg struct {
+	ID   int
+	Time time.Time
+}
+
+// focusTick ticks the focus timer once a second
+func focusTick(id int) tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return FocusTickMsg{ID: id, Time: t}
+	})
+}
+
+// criterionPattern matches a checklist item in a description, which the
+// focus view shows as an acceptance criterion: "- [ ] tests pass"
+var criterionPattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.+)$`)
+
+// criterion is an acceptance criterion of a task
+type criterion struct {
+	text string
+	done bool
+}
+
+// acceptanceCriteria collects the checklist items of a description
+func acceptanceCriteria(description string) []criterion {
+	var criteria []criterion
+	for _, line := range strings.Split(description, "\n") {
+		if match := criterionPattern.FindStringSubmatch(line); match != nil {
+			criteria = append(criteria, criterion{text: strings.TrimSpace(match[2]), done: match[1] != " "})
+		}
+	}
+	return criteria
+}
+
+// handleFocus enters the focus view on the selected task of the doing
+// column, or says there is nothing to focus on
+func (m Model) handleFocus() (tea.Model, tea.Cmd) {
+	doing := m.tasks[task.StatusDoing]
+	if len(doing) == 0 {
+		return m, m.showNotice("Nothing in doing to focus on; start a task first")
+	}
+	index := m.selectedTask[ColumnDoing]
+	if index >= len(doing) {
+		index = len(doing) - 1
+	}
+
+	m.focusTask = doing[index]
+	m.focusStarted = time.Now()
+	m.focusNow = m.focusStarted
+	m.focusID++
+	m.currentView = ViewFocus
+	if m.replaying {
+		// The timer starts once the macro is done
+		return m, nil
+	}
+	return m, focusTick(m.focusID)
+}
+
+// handleFocusKeys processes keyboard input for the focus view
+func (m Model) handleFocusKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "esc" {
+		m.leaveFocus()
+		return m, nil
+	}
+
+	switch m.keymap.action(msg.String()) {
+	case ActionQuit:
+		return m, tea.Quit
+
+	case ActionFocus:
+		m.leaveFocus()
+		return m, nil
+
+	case ActionAdvance:
+		// Finishing the task is what the focus was for
+		done := m.focusTask
+		m.leaveFocus()
+		notice := m.showNotice(fmt.Sprintf("Done: #%d %q after %s of focus", done.ID, done.Title, formatTimer(m.focusNow.Sub(m.focusStarted))))
+		return m, tea.Batch(m.moveTask(done.ID, task.StatusDone), notice)
+	}
+
+	return m, nil
+}
+
+// leaveFocus returns to the board; ticks still on their way are ignored
+func (m *Model) leaveFocus() {
+	m.currentView = ViewKanban
+	m.focusTask = nil
+	m.updateViewportContent()
+}
+
+// refreshFocus picks up changes to the focused task made elsewhere, and
+// leaves the focus view when it is gone or no longer in doing
+func (m *Model) refreshFocus() {
+	for _, t := range m.loaded[task.StatusDoing] {
+		if t.ID == m.focusTask.ID {
+			m.focusTask = t
+			return
+		}
+	}
+	m.leaveFocus()
+}
+
+// renderFocusView shows the focused task alone: its title, acceptance
+// criteria (or description) and how long it has had focus
+func (m Model) renderFocusView() string {
+	t := m.focusTask
+	palette := m.styles.Palette
+	muted := lipgloss.NewStyle().Foreground(palette.Muted)
+
+	width := 70
+	if m.width > 0 && m.width-8 < width {
+		width = m.width - 8
+	}
+	if width < 20 {
+		width = 20
+	}
+	text := lipgloss.NewStyle().Width(width)
+
+	id := fmt.Sprintf("#%d", t.ID)
+	if t.Ref != "" {
+		id += " " + t.Ref
+	}
+
+	var b strings.Builder
+	b.WriteString(muted.Render("FOCUS • "+m.currentBoard) + "\n\n")
+	b.WriteString(muted.Render(id) + "\n")
+	b.WriteString(text.Copy().Bold(true).Foreground(palette.Primary).Render(t.Title) + "\n\n")
+
+	if criteria := acceptanceCriteria(t.Description); len(criteria) > 0 {
+		b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(palette.Secondary).Render("Acceptance criteria") + "\n")
+		for _, c := range criteria {
+			box, style := "☐", lipgloss.NewStyle()
+			if c.done {
+				box, style = "☑", muted.Copy().Strikethrough(true)
+			}
+			b.WriteString(text.Render(box+" "+style.Render(c.text)) + "\n")
+		}
+		b.WriteString("\n")
+	} else if description := strings.TrimSpace(t.Description); description != "" {
+		b.WriteString(text.Render(description) + "\n\n")
+	}
+
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("⏱  "+formatTimer(m.focusNow.Sub(m.focusStarted))) + "\n\n")
+
+	k := m.keymap
+	b.WriteString(muted.Render(fmt.Sprintf("%s: done • %s/esc: back to the board • %s: quit",
+		k.keyNames(ActionAdvance, ","), k.keyNames(ActionFocus, ","), k.keyNames(ActionQuit, ","))))
+
+	if m.width <= 0 || m.height <= 0 {
+		return b.String()
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, b.String())
+}
+
+// formatTimer shows a duration as a clock: 04:05, or 1:02:03 past an hour
+func formatTimer(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestAcceptanceCriteria(t *testing.T) {
+	description := "Ship it.\n\n- [ ] tests pass\n  * [x] docs written\n- not a criterion\n+ [X] reviewed"
+	want := []criterion{{"tests pass", false}, {"docs written", true}, {"reviewed", true}}
+	if got := acceptanceCriteria(description); !reflect.DeepEqual(got, want) {
+		t.Errorf("acceptanceCriteria = %v, want %v", got, want)
+	}
+}
+
+func TestFormatTimer(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "00:00"}, {65 * time.Second, "01:05"}, {time.Hour + 2*time.Minute + 3*time.Second, "1:02:03"},
+	}
+	for _, tt := range tests {
+		if got := formatTimer(tt.d); got != tt.want {
+			t.Errorf("formatTimer(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFocus(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	model := NewModel(db, Options{Board: "test", Theme: "no-color"})
+	model = run(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	model = run(model, model.refreshTasks()())
+
+	// Focus starts a timer, so press keys without running the commands
+	update := func(msg tea.Msg) tea.Cmd {
+		updated, cmd := model.Update(msg)
+		m := updated.(Model)
+		model = &m
+		return cmd
+	}
+	press := func(key string) tea.Cmd {
+		return update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	press("f")
+	if model.currentView == ViewFocus || !strings.Contains(model.notice, "Nothing in doing") {
+		t.Fatalf("Expected a notice with nothing in doing, got view %v and %q", model.currentView, model.notice)
+	}
+
+	report, _ := taskSystem.Create(1, "Write the report", "- [ ] numbers checked\n- [x] outline")
+	if err := taskSystem.UpdateStatus(report.ID, task.StatusDoing); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	model = run(model, model.refreshTasks()())
+
+	if cmd := press("f"); cmd == nil || model.currentView != ViewFocus {
+		t.Fatal("Expected f to focus on the doing task and start its timer")
+	}
+	model.focusNow = model.focusStarted.Add(90 * time.Second)
+	view := model.View()
+	for _, want := range []string{"Write the report", "Acceptance criteria", "☐ numbers checked", "☑ outline", "01:30"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("Expected the focus view to show %q, got:\n%s", want, view)
+		}
+	}
+
+	// Ticks of an earlier focus are dropped
+	if cmd := update(FocusTickMsg{ID: model.focusID - 1, Time: time.Now()}); cmd != nil {
+		t.Error("Expected a stale tick not to tick again")
+	}
+
+	press("f")
+	if model.currentView != ViewKanban {
+		t.Fatal("Expected f to leave the focus view")
+	}
+
+	press("f")
+	cmd := update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.currentView != ViewKanban || !strings.Contains(model.notice, "Done: #") {
+		t.Fatalf("Expected enter to finish the task, got view %v and %q", model.currentView, model.notice)
+	}
+	// The first command moves the task, the second expires the notice
+	model = run(model, cmd().(tea.BatchMsg)[0]())
+	if done, _ := taskSystem.GetByID(report.ID); done.Status != task.StatusDone {
+		t.Errorf("Expected the focused task to be done, got %s", done.Status)
+	}
+}
@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// runHook runs a hook of the board, if the model has hooks. A failing pre-
+// hook refuses the change with its error.
+func (m Model) runHook(hook string, t *task.Task, from, to task.Status) error {
+	if m.hooks == nil {
+		return nil
+	}
+	return m.hooks.Run(context.Background(), automation.HookEvent{
+		Hook:       hook,
+		Board:      m.currentBoard,
+		Task:       t,
+		FromStatus: from,
+		ToStatus:   to,
+	})
+}
+
+// afterHook runs a post- hook and returns msg, along with an error when the
+// hook failed; the change has already happened, so it is not undone
+func (m Model) afterHook(hook string, t *task.Task, from, to task.Status, msg tea.Msg) tea.Msg {
+	if err := m.runHook(hook, t, from, to); err != nil {
+		return tea.BatchMsg{
+			func() tea.Msg { return msg },
+			func() tea.Msg { return ErrorMsg{Err: err} },
+		}
+	}
+	return msg
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestHooks(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	dir := t.TempDir()
+	writeHook := func(name, script string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script), 0755); err != nil {
+			t.Fatalf("Failed to write hook: %v", err)
+		}
+	}
+	writeHook(automation.HookPreDelete, "echo 'not on this board'; exit 1")
+	writeHook(automation.HookPostMove, "exit 2")
+
+	taskSystem := task.New(db.Conn())
+	fix, _ := taskSystem.Create(1, "Fix the parser", "")
+	model := NewModel(db, Options{Board: "test", Theme: "no-color", Hooks: automation.NewHooks(dir)})
+
+	// A refusing pre- hook stops the delete
+	msg, ok := model.deleteTask(fix, false)().(ErrorMsg)
+	if !ok || !strings.Contains(msg.Err.Error(), "pre-delete hook refused: not on this board") {
+		t.Errorf("Expected the delete to be refused, got %v", msg)
+	}
+	if _, err := taskSystem.GetByID(fix.ID); err != nil {
+		t.Errorf("Expected the task to be kept: %v", err)
+	}
+
+	// A failing post- hook is reported, but the move stands
+	batch, ok := model.moveTask(fix.ID, task.StatusDoing)().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("Expected the refresh and the hook's error, got %v", batch)
+	}
+	if _, ok := batch[0]().(TasksRefreshedMsg); !ok {
+		t.Error("Expected the tasks to be refreshed after the move")
+	}
+	if msg, ok := batch[1]().(ErrorMsg); !ok || !strings.Contains(msg.Err.Error(), "post-move hook") {
+		t.Errorf("Expected the post-move hook to fail, got %v", msg)
+	}
+	if moved, _ := taskSystem.GetByID(fix.ID); moved.Status != task.StatusDoing {
+		t.Errorf("Expected the task to be moved, status is %s", moved.Status)
+	}
+}
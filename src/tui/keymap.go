@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action is something a key does on the kanban board
+type Action string
+
+// Actions that can be bound to keys, named as in the [keys] config section
+const (
+	ActionLeft      Action = "left"
+	ActionRight     Action = "right"
+	ActionDown      Action = "down"
+	ActionUp        Action = "up"
+	ActionMoveDown  Action = "move_down"
+	ActionMoveUp    Action = "move_up"
+	ActionAdvance   Action = "advance"
+	ActionPriority  Action = "priority"
+	ActionNew       Action = "new"
+	ActionEdit      Action = "edit"
+	ActionDelete    Action = "delete"
+	ActionUndo      Action = "undo"
+	ActionOpen      Action = "open"
+	ActionDetails   Action = "details"
+	ActionFocus     Action = "focus"
+	ActionLanes     Action = "swimlanes"
+	ActionRecord    Action = "record"
+	ActionReplay    Action = "replay"
+	ActionContext   Action = "context"
+	ActionMilestone Action = "milestone"
+	ActionSearch    Action = "search"
+	ActionClear     Action = "clear"
+	ActionRefresh   Action = "refresh"
+	ActionHelp      Action = "help"
+	ActionQuit      Action = "quit"
+)
+
+// actionHelp describes each action in the help view, in the order shown
+var actionHelp = []struct {
+	section string
+	action  Action
+	help    string
+}{
+	{"NAVIGATION", ActionLeft, "Move to left column"},
+	{"NAVIGATION", ActionRight, "Move to right column"},
+	{"NAVIGATION", ActionDown, "Navigate down in current column (auto-scroll)"},
+	{"NAVIGATION", ActionUp, "Navigate up in current column (auto-scroll)"},
+	{"NAVIGATION", ActionMoveDown, "Move the selected task down in its column"},
+	{"NAVIGATION", ActionMoveUp, "Move the selected task up in its column"},
+	{"TASK ACTIONS", ActionAdvance, "Move task to next status (todo → doing → done)"},
+	{"TASK ACTIONS", ActionPriority, "Raise the task's priority one level (critical wraps to none)"},
+	{"TASK ACTIONS", ActionNew, "Create new task"},
+	{"TASK ACTIONS", ActionEdit, "Edit selected task"},
+	{"TASK ACTIONS", ActionDelete, "Delete selected task (asks first: y trash, D for good)"},
+	{"TASK ACTIONS", ActionUndo, "Undo the last delete"},
+	{"TASK ACTIONS", ActionOpen, "Open a file or URL attached to the task"},
+	{"OTHER", ActionFocus, "Focus: show only the selected doing task, full screen, with a timer"},
+	{"MACROS", ActionRecord, "Record keys into a register (a-z); press again to stop"},
+	{"MACROS", ActionReplay, "Replay the keys in a register (a-z, or again for the last one)"},
+	{"OTHER", ActionLanes, "Cycle swimlanes: group the columns by priority, tag, assignee, none"},
+	{"OTHER", ActionDetails, "Show/hide descriptions, tags, due dates and links on the cards"},
+	{"OTHER", ActionContext, "Cycle GTD context filter (@home, @deep-work, ..., all)"},
+	{"OTHER", ActionMilestone, "Cycle milestone filter (each milestone, then all)"},
+	{"OTHER", ActionSearch, "Search: filter all columns as you type"},
+	{"OTHER", ActionClear, "Clear the search"},
+	{"OTHER", ActionRefresh, "Refresh tasks from database (changes made elsewhere show up on their own)"},
+	{"OTHER", ActionHelp, "Show/hide this help"},
+	{"OTHER", ActionQuit, "Quit application"},
+}
+
+// Keymap binds each action to the keys that trigger it, as bubbletea names
+// them ("j", "down", "shift+up", "ctrl+c", ...)
+type Keymap map[Action][]string
+
+// keymapProfiles are the built-in keymaps: vim and arrow keys both work by
+// default, the others keep to one style and leave the other keys free
+var keymapProfiles = map[string]Keymap{
+	"default": {
+		ActionLeft: {"h", "left"}, ActionRight: {"l", "right"},
+		ActionDown: {"j", "down"}, ActionUp: {"k", "up"},
+		ActionMoveDown: {"J", "shift+down"}, ActionMoveUp: {"K", "shift+up"},
+	},
+	"vim": {
+		ActionLeft: {"h"}, ActionRight: {"l"},
+		ActionDown: {"j"}, ActionUp: {"k"},
+		ActionMoveDown: {"J"}, ActionMoveUp: {"K"},
+	},
+	"arrows": {
+		ActionLeft: {"left"}, ActionRight: {"right"},
+		ActionDown: {"down"}, ActionUp: {"up"},
+		ActionMoveDown: {"shift+down"}, ActionMoveUp: {"shift+up"},
+	},
+}
+
+// commonKeys are the bindings every profile shares
+var commonKeys = Keymap{
+	ActionAdvance: {"enter"}, ActionPriority: {"p"},
+	ActionNew: {"n"}, ActionEdit: {"e"},
+	ActionDelete: {"d"}, ActionUndo: {"u"}, ActionOpen: {"o"},
+	ActionDetails: {"i"}, ActionFocus: {"f"},
+	ActionContext: {"c"}, ActionMilestone: {"m"}, ActionLanes: {"g"},
+	ActionRecord: {"Q"}, ActionReplay: {"@"},
+	ActionSearch: {"/"}, ActionClear: {"esc"},
+	ActionRefresh: {"r"}, ActionHelp: {"?"}, ActionQuit: {"q", "ctrl+c"},
+}
+
+// DefaultKeymap returns the keys the TUI uses unless configured otherwise
+func DefaultKeymap() Keymap {
+	keymap, _ := NewKeymap("default", nil)
+	return keymap
+}
+
+// NewKeymap starts from a built-in profile ("default", "vim" or "arrows")
+// and rebinds the actions in overrides, each to a space-separated list of
+// keys. A key bound to two actions is an error, so a rebinding has to free
+// the key it takes from another action.
+func NewKeymap(profile string, overrides map[string]string) (Keymap, error) {
+	if profile == "" {
+		profile = "default"
+	}
+	base, ok := keymapProfiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("unknown keymap %q (want default, vim or arrows)", profile)
+	}
+
+	keymap := make(Keymap)
+	for _, bindings := range []Keymap{commonKeys, base} {
+		for action, keys := range bindings {
+			keymap[action] = keys
+		}
+	}
+
+	for name, keys := range overrides {
+		action := Action(name)
+		if _, known := keymap[action]; !known {
+			return nil, fmt.Errorf("unknown key action %q (want one of %s)", name, strings.Join(actionNames(), ", "))
+		}
+		var bound []string
+		for _, key := range strings.Fields(keys) {
+			if key == "space" {
+				key = " "
+			}
+			bound = append(bound, key)
+		}
+		if len(bound) == 0 {
+			return nil, fmt.Errorf("no keys given for %q", name)
+		}
+		keymap[action] = bound
+	}
+
+	// Report conflicts in a stable order
+	seen := make(map[string]Action)
+	for _, entry := range actionHelp {
+		for _, key := range keymap[entry.action] {
+			if other, taken := seen[key]; taken {
+				return nil, fmt.Errorf("key %q is bound to both %s and %s", key, other, entry.action)
+			}
+			seen[key] = entry.action
+		}
+	}
+	return keymap, nil
+}
+
+// action returns the action bound to a key, or "" for none
+func (k Keymap) action(key string) Action {
+	for action, keys := range k {
+		for _, bound := range keys {
+			if bound == key {
+				return action
+			}
+		}
+	}
+	return ""
+}
+
+// keyNames renders the keys bound to an action for the help view and
+// status bar, e.g. "j, ↓"
+func (k Keymap) keyNames(action Action, sep string) string {
+	names := make([]string, len(k[action]))
+	for i, key := range k[action] {
+		names[i] = displayKey(key)
+	}
+	return strings.Join(names, sep)
+}
+
+// displayKey shows a key the way the help has always shown it
+func displayKey(key string) string {
+	if key == " " {
+		return "space"
+	}
+	if ctrl, ok := strings.CutPrefix(key, "ctrl+"); ok {
+		return "^" + strings.ToUpper(ctrl)
+	}
+	return strings.NewReplacer("left", "←", "right", "→", "up", "↑", "down", "↓").Replace(key)
+}
+
+// actionNames lists the names of the actions that can be bound
+func actionNames() []string {
+	names := make([]string, 0, len(actionHelp))
+	for _, entry := range actionHelp {
+		names = append(names, string(entry.action))
+	}
+	sort.Strings(names)
+	return names
+}
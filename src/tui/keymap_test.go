@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestNewKeymap(t *testing.T) {
+	keymap, err := NewKeymap("arrows", map[string]string{"down": "down s", "help": "f1"})
+	if err != nil {
+		t.Fatalf("NewKeymap failed: %v", err)
+	}
+	if keymap.action("j") != "" || keymap.action("down") != ActionDown || keymap.action("s") != ActionDown {
+		t.Errorf("Expected the arrows profile with s added for down, got %v", keymap)
+	}
+	if keymap.action("?") != "" || keymap.action("f1") != ActionHelp {
+		t.Errorf("Expected help moved to f1, got %v", keymap[ActionHelp])
+	}
+
+	errors := []struct {
+		profile   string
+		overrides map[string]string
+		want      string
+	}{
+		{"emacs", nil, "unknown keymap"},
+		{"default", map[string]string{"fly": "f"}, "unknown key action"},
+		{"default", map[string]string{"down": "  "}, "no keys"},
+		// d still deletes, so it cannot also move down
+		{"default", map[string]string{"down": "d"}, `key "d" is bound to both down and delete`},
+	}
+	for _, e := range errors {
+		if _, err := NewKeymap(e.profile, e.overrides); err == nil || !strings.Contains(err.Error(), e.want) {
+			t.Errorf("NewKeymap(%q, %v): expected an error containing %q, got %v", e.profile, e.overrides, e.want, err)
+		}
+	}
+}
+
+func TestRemappedKeys(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	taskSystem.Create(1, "First", "")
+	taskSystem.Create(1, "Second", "")
+
+	keymap, err := NewKeymap("default", map[string]string{"down": "s", "up": "w"})
+	if err != nil {
+		t.Fatalf("NewKeymap failed: %v", err)
+	}
+	model := NewModel(db, Options{Board: "test", Keymap: keymap})
+	model = run(model, model.refreshTasks()())
+
+	model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	if model.selectedTask[ColumnTodo] != 0 {
+		t.Error("Expected j to do nothing once down is rebound")
+	}
+	model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if model.selectedTask[ColumnTodo] != 1 {
+		t.Error("Expected s to move down")
+	}
+
+	// The help shows the keys in effect
+	model.currentView = ViewHelp
+	if help := model.View(); !strings.Contains(help, "s          Navigate down") || strings.Contains(help, "j, ↓") {
+		t.Errorf("Expected the help to show the rebound keys, got:\n%s", help)
+	}
+}
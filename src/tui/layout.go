@@ -0,0 +1,163 @@
+package tui
+
+// Constraint bounds the size of a pane along one axis: at least Min cells,
+// at most Max (0 for no limit), and a share of the space left over after
+// every pane has its Min in proportion to Flex
+type Constraint struct {
+	Min, Max, Flex int
+}
+
+// Distribute splits total cells among panes. Each pane gets its Min first;
+// when total cannot cover the minimums they all shrink in proportion. The
+// rest goes to the flexible panes by Flex, and what a pane cannot take past
+// its Max goes to the others. Space no pane can take is left over.
+func Distribute(total int, constraints []Constraint) []int {
+	sizes := make([]int, len(constraints))
+	if total <= 0 {
+		return sizes
+	}
+
+	minimum := 0
+	for i, c := range constraints {
+		sizes[i] = c.Min
+		minimum += c.Min
+	}
+	if total < minimum {
+		given := 0
+		for i, c := range constraints {
+			sizes[i] = c.Min * total / minimum
+			given += sizes[i]
+		}
+		for i := 0; given < total; i = (i + 1) % len(sizes) {
+			if sizes[i] < constraints[i].Min {
+				sizes[i]++
+				given++
+			}
+		}
+		return sizes
+	}
+
+	remaining := total - minimum
+	for remaining > 0 {
+		// Share what is left among the panes that can still grow
+		flex := 0
+		for i, c := range constraints {
+			if c.Flex > 0 && (c.Max == 0 || sizes[i] < c.Max) {
+				flex += c.Flex
+			}
+		}
+		if flex == 0 {
+			break
+		}
+
+		given := 0
+		for i, c := range constraints {
+			if c.Flex == 0 || c.Max != 0 && sizes[i] >= c.Max {
+				continue
+			}
+			share := remaining * c.Flex / flex
+			if share == 0 {
+				// Hand out the last cells one at a time, first pane first
+				share = 1
+			}
+			if share > remaining-given {
+				share = remaining - given
+			}
+			if c.Max != 0 && sizes[i]+share > c.Max {
+				share = c.Max - sizes[i]
+			}
+			sizes[i] += share
+			given += share
+		}
+		if given == 0 {
+			break
+		}
+		remaining -= given
+	}
+	return sizes
+}
+
+const (
+	// columnChrome is what a column adds around its content width: a
+	// border and a margin cell on each side
+	columnChrome = 4
+	// columnPadding is the padding inside a column's content width
+	columnPadding = 2
+	// boardChrome is the lines around the columns: the header, the status
+	// bar and a blank line after one and before the other
+	boardChrome = 4
+	// columnLines is the lines of a column that are not its viewport: the
+	// border and padding above and below, the title, the column
+	// definition and a blank line
+	columnLines = 7
+
+	// fallbackColumnWidth and fallbackColumnHeight are used before the
+	// terminal size is known
+	fallbackColumnWidth  = 35
+	fallbackColumnHeight = 20
+	// minColumnHeight keeps a few tasks visible on very short terminals
+	minColumnHeight = 10
+)
+
+// Constraints of the panes across the terminal: three even columns and, on
+// wide terminals, a detail pane about a third of the width
+var (
+	columnConstraint = Constraint{Min: 24, Max: 94, Flex: 2}
+	detailConstraint = Constraint{Min: detailMinWidth, Max: detailMaxWidth, Flex: 3}
+)
+
+// Layout is the size of each pane of the board view. Widths are outer
+// widths, borders and margins included; ColumnHeight is the outer height of
+// the columns and of the detail pane.
+type Layout struct {
+	Columns      [3]int
+	Detail       int
+	ColumnHeight int
+}
+
+// computeLayout lays the board out in a terminal of width by height cells
+func computeLayout(width, height int) Layout {
+	var l Layout
+	if width <= 0 {
+		for col := range l.Columns {
+			l.Columns[col] = fallbackColumnWidth + columnChrome
+		}
+	} else {
+		constraints := []Constraint{columnConstraint, columnConstraint, columnConstraint}
+		if width >= splitMinWidth {
+			constraints = append(constraints, detailConstraint)
+		}
+		sizes := Distribute(width, constraints)
+		copy(l.Columns[:], sizes)
+		if len(sizes) > len(l.Columns) {
+			l.Detail = sizes[len(l.Columns)]
+		}
+	}
+
+	l.ColumnHeight = fallbackColumnHeight
+	if height > 0 {
+		l.ColumnHeight = height - boardChrome
+	}
+	if l.ColumnHeight < minColumnHeight {
+		l.ColumnHeight = minColumnHeight
+	}
+	return l
+}
+
+// ContentWidth is the width of a column inside its border and margins,
+// padding included, as lipgloss takes it
+func (l Layout) ContentWidth(col Column) int {
+	if width := l.Columns[col] - columnChrome; width > columnPadding {
+		return width
+	}
+	return columnPadding + 1
+}
+
+// ViewportWidth and ViewportHeight are the size of a column's task list
+func (l Layout) ViewportWidth(col Column) int {
+	return l.ContentWidth(col) - columnPadding
+}
+
+func (l Layout) ViewportHeight() int {
+	return l.ColumnHeight - columnLines
+}
@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestDistribute(t *testing.T) {
+	even := Constraint{Min: 10, Flex: 1}
+	tests := []struct {
+		name        string
+		total       int
+		constraints []Constraint
+		want        []int
+	}{
+		{"nothing to share", 0, []Constraint{even, even}, []int{0, 0}},
+		{"even split", 30, []Constraint{even, even, even}, []int{10, 10, 10}},
+		{"leftover cells go first come", 32, []Constraint{even, even, even}, []int{11, 11, 10}},
+		{"flex weights", 40, []Constraint{{Flex: 1}, {Flex: 3}}, []int{10, 30}},
+		{"max passes the rest on", 50, []Constraint{{Min: 10, Max: 15, Flex: 1}, even}, []int{15, 35}},
+		{"everyone at max", 100, []Constraint{{Max: 20, Flex: 1}, {Max: 30, Flex: 1}}, []int{20, 30}},
+		{"fixed panes keep their min", 50, []Constraint{{Min: 8}, even}, []int{8, 42}},
+		{"too small shrinks evenly", 15, []Constraint{even, even, even}, []int{5, 5, 5}},
+		{"too small keeps proportions", 20, []Constraint{{Min: 10}, {Min: 30}}, []int{5, 15}},
+	}
+	for _, tt := range tests {
+		if got := Distribute(tt.total, tt.constraints); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Distribute(%d) = %v, want %v", tt.name, tt.total, got, tt.want)
+		}
+	}
+}
+
+// TestLayoutGeometries renders the board in many terminal sizes and checks
+// that it fills the terminal without running past it
+func TestLayoutGeometries(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	for i := 0; i < 30; i++ {
+		title := fmt.Sprintf("Task %d with a title long enough to need cutting in narrow columns", i)
+		created, err := taskSystem.Create(1, title, "A description that wraps in the detail pane")
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if i%3 == 1 {
+			if err := taskSystem.UpdateStatus(created.ID, task.StatusDoing); err != nil {
+				t.Fatalf("UpdateStatus: %v", err)
+			}
+		}
+	}
+	if _, err := taskSystem.SetColumnNote(task.StatusTodo, "Everything not started yet, however long this definition of the column runs"); err != nil {
+		t.Fatalf("SetColumnNote: %v", err)
+	}
+
+	geometries := []struct{ width, height int }{
+		{60, 14}, {72, 16}, {80, 24}, {80, 25}, {90, 30}, {100, 30}, {100, 40},
+		{110, 20}, {120, 30}, {120, 40}, {132, 43}, {140, 35}, {150, 40},
+		{159, 45}, {160, 45}, {170, 50}, {180, 50}, {200, 60}, {220, 60},
+		{240, 70}, {256, 64}, {300, 80}, {400, 100},
+	}
+	for _, g := range geometries {
+		t.Run(fmt.Sprintf("%dx%d", g.width, g.height), func(t *testing.T) {
+			layout := computeLayout(g.width, g.height)
+			used := layout.Detail
+			for _, width := range layout.Columns {
+				used += width
+				if width-layout.Columns[0] > 1 || layout.Columns[0]-width > 1 {
+					t.Errorf("Expected even columns, got %v", layout.Columns)
+				}
+			}
+			if used > g.width {
+				t.Errorf("Layout %+v is %d wide, past the terminal", layout, used)
+			}
+			if (layout.Detail > 0) != (g.width >= splitMinWidth) {
+				t.Errorf("Expected the detail pane from %d columns on, got %+v", splitMinWidth, layout)
+			}
+			if used < g.width && layout.Columns[0] < columnConstraint.Max && layout.Detail < detailMaxWidth {
+				t.Errorf("Layout %+v leaves %d columns unused", layout, g.width-used)
+			}
+
+			model := NewModel(db, Options{Board: "test", Theme: "no-color"})
+			model = run(model, tea.WindowSizeMsg{Width: g.width, Height: g.height})
+			model = run(model, model.refreshTasks()())
+			view := model.View()
+			lines := strings.Split(view, "\n")
+			if len(lines) > g.height {
+				t.Errorf("Board is %d lines tall, past the terminal:\n%s", len(lines), view)
+			}
+			if g.height-len(lines) > 1 {
+				t.Errorf("Board is %d lines tall, leaving the terminal half empty", len(lines))
+			}
+			for _, line := range lines {
+				if width := ansi.StringWidth(line); width > g.width {
+					t.Fatalf("A line is %d wide, past the terminal:\n%s", width, line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// MacroStepMsg carries a macro replay forward: the messages produced by the
+// last replayed key, to be handled before the rest of the keys
+type MacroStepMsg struct {
+	Msgs []tea.Msg
+	Keys []tea.KeyMsg
+}
+
+// replay runs cmd, the effect of the last replayed key, and then goes on
+// with the rest of the keys
+func replay(cmd tea.Cmd, keys []tea.KeyMsg) tea.Cmd {
+	return func() tea.Msg {
+		return MacroStepMsg{Msgs: drain(cmd), Keys: keys}
+	}
+}
+
+// drain runs a command and its batched commands, collecting their messages.
+// Nothing started during a replay may tick (see showNotice), so it returns
+// as soon as the board is updated.
+func drain(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case nil:
+		return nil
+	case tea.BatchMsg:
+		var msgs []tea.Msg
+		for _, cmd := range msg {
+			msgs = append(msgs, drain(cmd)...)
+		}
+		return msgs
+	default:
+		return []tea.Msg{msg}
+	}
+}
+
+// replayStep handles what the last replayed key brought about, then
+// presses the next one. A key's effects settle before the next key, so a
+// macro sees the board as the user did while recording it.
+func (m Model) replayStep(step MacroStepMsg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	for _, msg := range step.Msgs {
+		if _, ok := msg.(tea.QuitMsg); ok {
+			return m, tea.Quit
+		}
+		updated, cmd := m.Update(msg)
+		m = updated.(Model)
+		cmds = append(cmds, cmd)
+	}
+	if cmd := tea.Batch(cmds...); cmd != nil {
+		return m, replay(cmd, step.Keys)
+	}
+
+	if len(step.Keys) == 0 {
+		// Start the timers held back during the replay
+		m.replaying = false
+		var cmds []tea.Cmd
+		if m.notice != "" {
+			cmds = append(cmds, expireNotice(m.noticeID))
+		}
+		if m.currentView == ViewFocus {
+			cmds = append(cmds, focusTick(m.focusID))
+		}
+		return m, tea.Batch(cmds...)
+	}
+
+	updated, cmd := m.handleKeyPress(step.Keys[0])
+	m = updated.(Model)
+	return m, replay(cmd, step.Keys[1:])
+}
+
+// handleMacroKey handles Q and @ on the board: Q asks for a register to
+// record into, or stops recording; @ asks for a register to replay
+func (m Model) handleMacroKey(action Action) (tea.Model, tea.Cmd) {
+	if m.replaying {
+		// Replays neither record nor nest
+		return m, nil
+	}
+
+	if m.recording != 0 {
+		// The key pressed is not part of the macro
+		m.recorded = m.recorded[:len(m.recorded)-1]
+		if action == ActionReplay {
+			return m, m.showNotice(fmt.Sprintf("Recording @%c • %s: stop recording before replaying", m.recording, m.keymap.keyNames(ActionRecord, ",")))
+		}
+		if m.macros == nil {
+			m.macros = make(map[rune][]tea.KeyMsg)
+		}
+		m.macros[m.recording] = m.recorded
+		notice := fmt.Sprintf("Recorded %d keys into @%c • %s%c: replay", len(m.recorded), m.recording, m.keymap.keyNames(ActionReplay, ","), m.recording)
+		m.recording, m.recorded = 0, nil
+		return m, m.showNotice(notice)
+	}
+
+	m.macroPrompt = action
+	if action == ActionRecord {
+		return m, m.showNotice("Record a macro into register (a-z)…")
+	}
+	return m, m.showNotice("Replay the macro in register (a-z, or @ for the last one)…")
+}
+
+// handleRegisterKey takes the register a pending Q or @ asked for
+func (m Model) handleRegisterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	action := m.macroPrompt
+	m.macroPrompt = ""
+	m.notice = ""
+
+	key := msg.String()
+	var register rune
+	switch {
+	case action == ActionReplay && m.keymap.action(key) == ActionReplay:
+		if register = m.lastMacro; register == 0 {
+			return m, m.showNotice("No macro replayed yet")
+		}
+	case len(key) == 1 && key[0] >= 'a' && key[0] <= 'z':
+		register = rune(key[0])
+	case key == "esc":
+		return m, nil
+	default:
+		return m, m.showNotice(fmt.Sprintf("%q is not a register; registers are a-z", key))
+	}
+
+	if action == ActionRecord {
+		m.recording, m.recorded = register, nil
+		return m, m.showNotice(fmt.Sprintf("Recording @%c • %s: stop", register, m.keymap.keyNames(ActionRecord, ",")))
+	}
+
+	keys := m.macros[register]
+	if len(keys) == 0 {
+		return m, m.showNotice(fmt.Sprintf("Register @%c is empty", register))
+	}
+	m.lastMacro = register
+	m.replaying = true
+	return m, replay(nil, keys)
+}
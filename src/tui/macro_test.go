@@ -0,0 +1,85 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestMacros(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	for _, title := range []string{"One", "Two", "Three"} {
+		if _, err := taskSystem.Create(1, title, ""); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	model := NewModel(db, Options{Board: "test"})
+	model = run(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	model = run(model, model.refreshTasks()())
+
+	// Q and @ show notices, which tick, so their commands are not run
+	press := func(key string) tea.Cmd {
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m := updated.(Model)
+		model = &m
+		return cmd
+	}
+	// replayAll runs a replay to its end, leaving the timers it starts
+	replayAll := func(cmd tea.Cmd) {
+		for model.replaying {
+			if cmd == nil {
+				t.Fatal("Expected the replay to go on")
+			}
+			updated, next := model.Update(cmd())
+			m := updated.(Model)
+			model, cmd = &m, next
+		}
+	}
+
+	// Triage the first card: raise it to critical and start it
+	press("Q")
+	press("a")
+	if model.recording != 'a' || !strings.Contains(model.View(), "[recording @a]") {
+		t.Fatalf("Expected Q a to record into a, recording %q", model.recording)
+	}
+	model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+	model = run(model, tea.KeyMsg{Type: tea.KeyEnter})
+	press("Q")
+	if model.recording != 0 || len(model.macros['a']) != 2 {
+		t.Fatalf("Expected Q to stop recording two keys, got %d keys", len(model.macros['a']))
+	}
+
+	press("@")
+	replayAll(press("a"))
+	press("@")
+	replayAll(press("@"))
+
+	doing, err := taskSystem.ListByStatus(1, task.StatusDoing)
+	if err != nil {
+		t.Fatalf("ListByStatus: %v", err)
+	}
+	if len(doing) != 3 {
+		t.Fatalf("Expected the macro to start every card, got %d doing", len(doing))
+	}
+	for _, d := range doing {
+		if d.Priority != task.PriorityCritical {
+			t.Errorf("Expected %q to be critical, got priority %d", d.Title, d.Priority)
+		}
+	}
+
+	press("@")
+	press("b")
+	if !strings.Contains(model.notice, "@b is empty") {
+		t.Errorf("Expected a notice about the empty register, got %q", model.notice)
+	}
+}
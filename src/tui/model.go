@@ -4,9 +4,14 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/hmain/cainban/src/systems/automation"
+	"github.com/hmain/cainban/src/systems/milestone"
 	"github.com/hmain/cainban/src/systems/task"
 	"github.com/hmain/cainban/src/systems/board"
 	"github.com/hmain/cainban/src/systems/storage"
@@ -30,6 +35,9 @@ type Model struct {
 	taskSystem  *task.System
 	boardSystem *board.System
 	storage     *storage.DB
+	// watcher spots changes made by other terminals and MCP agents; nil
+	// when the board is not being watched
+	watcher *storage.Watcher
 
 	// UI State
 	width  int
@@ -39,20 +47,100 @@ type Model struct {
 	currentView View
 	focused     Column
 	
-	// Task data
-	tasks map[task.Status][]*task.Task
+	// Task data: loaded holds the tasks of the active context, tasks the
+	// ones shown, which the search narrows further
+	tasks  map[task.Status][]*task.Task
+	loaded map[task.Status][]*task.Task
+	
+	// Search query typed after "/"; searching is true while it is edited
+	query     string
+	searching bool
 	
 	// Current board
 	currentBoard string
 	
+	// Hooks run on creates, moves and deletes, nil when none run
+	hooks *automation.Hooks
+	
+	// Unfinished tasks without an update for this long are marked stale
+	staleAfter time.Duration
+	
+	// GTD context filter ("" shows all tasks) and the contexts to cycle through
+	context  string
+	contexts []string
+	
+	// Milestone filter (0 shows all tasks) and the milestones to cycle
+	// through, with how much of each is done
+	milestoneSystem *milestone.System
+	milestone       int
+	milestones      []*milestone.Milestone
+	
+	// detailed cards show a description snippet and badges under each
+	// title; links counts the links of each task for the badges
+	detailed bool
+	links    map[int]int
+	
+	// macros holds the keys recorded into registers a-z. recording is the
+	// register keys are being recorded into (0 when none), macroPrompt the
+	// action waiting for a register, lastMacro the register @@ replays and
+	// replaying is set while a macro runs
+	macros      map[rune][]tea.KeyMsg
+	recording   rune
+	recorded    []tea.KeyMsg
+	macroPrompt Action
+	lastMacro   rune
+	replaying   bool
+	
+	// swimlanes groups each column into lanes, with a header per lane
+	swimlanes Swimlanes
+	
+	// focusTask is the task of the focus view, which has had focus since
+	// focusStarted; focusNow is the time of the last timer tick and
+	// focusID tells the ticks of this focus apart from an earlier one
+	focusTask    *task.Task
+	focusStarted time.Time
+	focusNow     time.Time
+	focusID      int
+	
+	// Column definitions, shown under the title of the focused column
+	columnNotes map[task.Status]task.ColumnNote
+	
 	// Selected task indices for each column
 	selectedTask map[Column]int
+	// confirmDelete is the task waiting for the user to confirm its
+	// deletion; nil when nothing is being deleted
+	confirmDelete *task.Task
+	// deleted is the last task moved to the trash, which u brings back
+	deleted *task.Task
+	// openChoices are the attachments o asks to pick one of to open; nil
+	// when it is not asking
+	openChoices []task.Attachment
+	
+	// busy is the operation running in the background, shown with spinner
+	// in the status bar; nil when there is none. asyncID numbers the
+	// operations so the result of a cancelled one is recognized
+	busy    *asyncOp
+	asyncID int
+	spinner spinner.Model
+	
+	// notice is a transient message shown in the status bar; noticeID
+	// tells its expiry apart from that of an older notice
+	notice   string
+	noticeID int
+	
+	// selectID is a task to select in the focused column once the tasks
+	// are reloaded, e.g. after a priority change moves it; 0 for none
+	selectID int
 	
 	// Viewports for each column (handles scrolling)
 	viewports map[Column]viewport.Model
 	
+	// Key bindings of the kanban view
+	keymap Keymap
+	
 	// Styles
-	styles Styles
+	styles  Styles
+	palette Palette
 }
 
 // View represents different TUI views
@@ -62,6 +150,7 @@ const (
 	ViewKanban View = iota
 	ViewHelp
 	ViewTaskDetail
+	ViewFocus
 )
 
 // Column represents kanban board columns
@@ -84,17 +173,43 @@ type Styles struct {
 	TaskPriority   map[int]lipgloss.Style
 	Help           lipgloss.Style
 	StatusBar      lipgloss.Style
+	Palette        Palette
+}
+
+// Options configures the TUI at startup
+type Options struct {
+	// Board is the name of the board being displayed
+	Board string
+	// Theme selects the color palette, one of Themes
+	Theme string
+	// Keymap binds the kanban view's keys; nil uses DefaultKeymap
+	Keymap Keymap
+	// Hooks run on creates, moves and deletes; nil runs none
+	Hooks *automation.Hooks
+	// StaleAfter is how long an unfinished task may go without an update
+	// before it is marked stale; 0 marks none
+	StaleAfter time.Duration
+	// Actor is who the changes made in the TUI are recorded as
+	Actor string
 }
 
 // NewModel creates a new TUI model
-func NewModel(db *storage.DB) *Model {
-	taskSystem := task.New(db.Conn())
+func NewModel(db *storage.DB, opts Options) *Model {
+	taskSystem := task.New(db.Conn()).WithActor(opts.Actor)
 	boardSystem := board.New()
 	
-	currentBoard, _ := boardSystem.GetCurrentBoard()
+	currentBoard := opts.Board
+	if currentBoard == "" {
+		currentBoard, _ = boardSystem.GetCurrentBoard()
+	}
 	if currentBoard == "" {
 		currentBoard = "default"
 	}
+	palette := PaletteForTheme(opts.Theme)
+	keymap := opts.Keymap
+	if keymap == nil {
+		keymap = DefaultKeymap()
+	}
 	
 	// Initialize selectedTask map with all columns set to 0
 	selectedTaskMap := make(map[Column]int)
@@ -111,14 +226,20 @@ func NewModel(db *storage.DB) *Model {
 	model := &Model{
 		taskSystem:   taskSystem,
 		boardSystem:  boardSystem,
+		milestoneSystem: milestone.New(db.Conn()),
 		storage:      db,
 		currentView:  ViewKanban,
 		focused:      ColumnTodo,
 		tasks:        make(map[task.Status][]*task.Task),
 		currentBoard: currentBoard,
+		hooks:        opts.Hooks,
+		staleAfter:   opts.StaleAfter,
 		selectedTask: selectedTaskMap,
 		viewports:    viewportMap,
-		styles:       DefaultStyles(), // Will be updated when window size is received
+		keymap:       keymap,
+		styles:       ThemedStyles(palette, 30, 20), // Will be updated when window size is received
+		palette:      palette,
+		spinner:      newSpinner(palette),
 		width:        0, // Will be set by first WindowSizeMsg
 		height:       0, // Will be set by first WindowSizeMsg
 	}
@@ -132,6 +253,7 @@ func (m Model) Init() tea.Cmd {
 	debugLog("[DEBUG] TUI Init() called with dimensions %dx%d\n", m.width, m.height)
 	return tea.Batch(
 		m.refreshTasks(),
+		m.watchDatabase(),
 		tea.WindowSize(), // Request current window size immediately
 		func() tea.Msg {
 			// Initialize viewport content after a short delay to ensure tasks are loaded
@@ -140,97 +262,20 @@ func (m Model) Init() tea.Cmd {
 	)
 }
 
-// calculateColumnWidth returns the optimal column width based on terminal width
-// IMPROVED ALGORITHM - addresses user feedback about narrow columns
+// layout sizes the panes of the board to the terminal
+func (m Model) layout() Layout {
+	return computeLayout(m.width, m.height)
+}
+
+// calculateColumnWidth returns the content width of the columns, padding
+// included
 func (m Model) calculateColumnWidth() int {
-	if m.width <= 0 {
-		return 35 // Better fallback default for modern terminals
-	}
-	
-	// DEBUG: Log width calculation
-	debugLog("[WIDTH] Terminal width: %d\n", m.width)
-	
-	// IMPROVED: Dynamic reserved space calculation
-	// Base: 3 columns × 2 borders each = 6, plus some spacing
-	reservedSpace := 10
-	// Adjust based on terminal size for better utilization
-	if m.width > 150 {
-		reservedSpace = 15
-	}
-	
-	availableWidth := m.width - reservedSpace
-	
-	// DEBUG: Log available space
-	debugLog("[WIDTH] Available width: %d (reserved: %d)\n", availableWidth, reservedSpace)
-	
-	// Divide remaining space among 3 columns
-	columnWidth := availableWidth / 3
-	
-	// IMPROVED: Dynamic minimum width based on terminal size
-	var minWidth int
-	if m.width < 90 {
-		minWidth = 25 // Very small terminals
-	} else if m.width < 120 {
-		minWidth = 30 // Small terminals
-	} else {
-		minWidth = 35 // Normal and large terminals
-	}
-	
-	if columnWidth < minWidth {
-		debugLog("[WIDTH] Using minimum width: %d\n", minWidth)
-		// Safety check: ensure we don't overflow the terminal
-		maxPossible := (m.width - 6) / 3 // Absolute minimum space needed
-		if minWidth > maxPossible && maxPossible > 10 {
-			debugLog("[WIDTH] Adjusting minimum to prevent overflow: %d\n", maxPossible)
-			return maxPossible
-		}
-		return minWidth
-	}
-	
-	// IMPROVED: Progressive maximum width based on terminal size
-	var maxWidth int
-	switch {
-	case m.width < 100:
-		maxWidth = 40 // Very small terminals
-	case m.width < 150:
-		maxWidth = 50 // Medium terminals
-	case m.width < 200:
-		maxWidth = 70 // Large terminals
-	default:
-		maxWidth = 90 // Very large terminals
-	}
-	
-	if columnWidth > maxWidth {
-		debugLog("[WIDTH] Using maximum width: %d\n", maxWidth)
-		return maxWidth
-	}
-	
-	debugLog("[WIDTH] Calculated column width: %d\n", columnWidth)
-	return columnWidth
+	return m.layout().ContentWidth(ColumnTodo)
 }
 
-// calculateColumnHeight returns the optimal column height based on terminal height
+// calculateColumnHeight returns the outer height of the columns
 func (m Model) calculateColumnHeight() int {
-	if m.height <= 0 {
-		debugLog("[HEIGHT] No terminal height set, using fallback: 20\n")
-		return 20 // Fallback to default
-	}
-	
-	// Reserve space for header, status bar, and margins
-	reservedHeight := 6 // Header + status bar + margins
-	availableHeight := m.height - reservedHeight
-	
-	debugLog("[HEIGHT] Terminal: %d, Reserved: %d, Available: %d\n", m.height, reservedHeight, availableHeight)
-	
-	// Ensure minimum height
-	minHeight := 10
-	if availableHeight < minHeight {
-		debugLog("[HEIGHT] Available height %d < min %d, using minimum\n", availableHeight, minHeight)
-		return minHeight
-	}
-	
-	debugLog("[HEIGHT] Final column height: %d\n", availableHeight)
-	return availableHeight
+	return m.layout().ColumnHeight
 }
 
 // updateStyles recalculates and updates styles based on current dimensions
@@ -245,12 +290,13 @@ func (m Model) updateStyles() Model {
 	// DEBUG: Log calculated dimensions
 	debugLog("[DEBUG] Calculated column dimensions: %dx%d\n", columnWidth, columnHeight)
 	
-	m.styles = DefaultStylesWithDimensions(columnWidth, columnHeight)
+	m.styles = ThemedStyles(m.palette, columnWidth, columnHeight)
 	
 	// Update viewport dimensions
+	layout := m.layout()
 	for col, vp := range m.viewports {
-		vp.Width = columnWidth - 4  // Account for borders and padding
-		vp.Height = columnHeight - 3 // Account for title and borders
+		vp.Width = layout.ViewportWidth(col)
+		vp.Height = layout.ViewportHeight()
 		m.viewports[col] = vp
 		debugLog("[VIEWPORT] Updated viewport %d to %dx%d\n", col, vp.Width, vp.Height)
 	}
@@ -288,52 +334,76 @@ func (m *Model) updateViewportContent() {
 		status := m.columnToStatus(col)
 		tasks := m.tasks[status]
 		
-		// Generate content for this column
+		// Generate content for this column, noting the lines of the
+		// selected task
 		var content []string
+		first, last := 0, 0
+		detailWidth := m.viewports[col].Width - len(cardIndent)
+		if detailWidth < 10 {
+			detailWidth = 10
+		}
+		now := time.Now()
+		counts := m.swimlanes.laneCounts(tasks)
 		
 		if len(tasks) == 0 {
 			content = append(content, "No tasks")
 		} else {
 			for i, t := range tasks {
+				if m.detailed && i > 0 {
+					content = append(content, "")
+				}
+				if m.swimlanes != SwimlanesOff {
+					if name := m.swimlanes.laneOf(t).name; i == 0 || name != m.swimlanes.laneOf(tasks[i-1]).name {
+						content = append(content, m.laneHeader(name, counts[name], m.viewports[col].Width))
+					}
+				}
 				isSelected := i == m.selectedTask[col] && col == m.focused
-				taskLine := m.renderTaskLine(t, isSelected)
+				if i == m.selectedTask[col] {
+					first = len(content)
+				}
+				// A title wider than the column would wrap and push the
+				// column past its height
+				taskLine := ansi.Truncate(m.renderTaskLine(t, isSelected), m.viewports[col].Width, "…")
 				content = append(content, taskLine)
+				if m.detailed {
+					content = append(content, m.cardDetails(t, detailWidth, now)...)
+				}
+				if i == m.selectedTask[col] {
+					last = len(content) - 1
+				}
 			}
 		}
 		
 		// Set the content in the viewport
 		vp := m.viewports[col]
 		vp.SetContent(strings.Join(content, "\n"))
+		m.viewports[col] = vp
 		
 		// Scroll to keep selected item visible
-		m.scrollToSelectedTask(col)
-		
-		m.viewports[col] = vp
+		m.scrollToSelectedTask(col, first, last)
 		
 		debugLog("[VIEWPORT] Column %d: %d tasks, %d lines\n", col, len(tasks), len(content))
 	}
 }
 
-// scrollToSelectedTask scrolls the viewport to keep the selected task visible
-func (m *Model) scrollToSelectedTask(col Column) {
-	selectedIndex := m.selectedTask[col]
+// scrollToSelectedTask scrolls the viewport to keep the selected task,
+// which takes lines first to last, visible
+func (m *Model) scrollToSelectedTask(col Column, first, last int) {
 	vp := m.viewports[col]
 	
-	// Calculate the line number of the selected task (0-indexed)
-	lineNumber := selectedIndex
-	
 	// Get viewport dimensions
 	viewportHeight := vp.Height
 	
-	// If the selected task is outside the visible area, scroll to it
-	if lineNumber < vp.YOffset {
+	// If the selected task is outside the visible area, scroll to it; a
+	// card taller than the viewport shows its title
+	if first < vp.YOffset || last-first >= viewportHeight {
 		// Selected task is above visible area, scroll up
-		vp.YOffset = lineNumber
-		debugLog("[SCROLL] Scrolling up to line %d for column %d\n", lineNumber, col)
-	} else if lineNumber >= vp.YOffset+viewportHeight {
+		vp.YOffset = first
+		debugLog("[SCROLL] Scrolling up to line %d for column %d\n", first, col)
+	} else if last >= vp.YOffset+viewportHeight {
 		// Selected task is below visible area, scroll down
-		vp.YOffset = lineNumber - viewportHeight + 1
-		debugLog("[SCROLL] Scrolling down to line %d for column %d\n", lineNumber, col)
+		vp.YOffset = last - viewportHeight + 1
+		debugLog("[SCROLL] Scrolling down to line %d for column %d\n", last, col)
 	}
 	
 	// Ensure we don't scroll past the content
@@ -367,7 +437,22 @@ func (m Model) renderTaskLine(t *task.Task, selected bool) string {
 		priority = "🔥"
 	}
 	
-	return fmt.Sprintf("%s%s %s", prefix, priority, t.Title)
+	// Tasks waiting on unfinished blockers get a badge in front of the title
+	title := t.Title
+	if t.IsBlocked() {
+		title = "🚫 " + title
+	}
+	if len(t.Reactions) > 0 {
+		title += "  " + task.FormatReactions(t.Reactions)
+	}
+	if t.Rollup != nil {
+		title += " [" + t.Rollup.String() + "]"
+	}
+	if t.IsStale(time.Now(), m.staleAfter) {
+		title += " 💤"
+	}
+	
+	return fmt.Sprintf("%s%s %s", prefix, priority, title)
 }
 
 // columnToStatus converts a column to its corresponding task status
@@ -1,7 +1,13 @@
 package tui
 
 import (
+	"reflect"
+	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
 )
 
 func TestCalculateColumnWidth(t *testing.T) {
@@ -104,4 +110,268 @@ func TestCalculateColumnHeight(t *testing.T) {
 				tt.terminalHeight, result, tt.description)
 		})
 	}
-}
\ No newline at end of file
+}
+func TestCycleContext(t *testing.T) {
+	model := &Model{
+		contexts:     []string{"@deep-work", "@home"},
+		selectedTask: map[Column]int{ColumnTodo: 3},
+	}
+
+	want := []string{"@deep-work", "@home", "", "@deep-work"}
+	for _, expected := range want {
+		model.cycleContext()
+		if model.context != expected {
+			t.Errorf("cycleContext() = %q, expected %q", model.context, expected)
+		}
+	}
+
+	if model.selectedTask[ColumnTodo] != 0 {
+		t.Errorf("Expected selection reset after switching context, got %d", model.selectedTask[ColumnTodo])
+	}
+}
+
+func TestCycleMilestone(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	model := NewModel(db, Options{Board: "test"})
+	beta, _ := model.milestoneSystem.Create(1, "Beta", "", nil)
+	model.milestoneSystem.Create(1, "GA", "", nil)
+	inBeta, _ := model.taskSystem.Create(1, "Signup", "")
+	model.taskSystem.Create(1, "Unrelated", "")
+	model.taskSystem.SetMilestone(inBeta.ID, &beta.ID)
+
+	refreshed := model.refreshTasks()().(TasksRefreshedMsg)
+	if len(refreshed.Milestones) != 2 {
+		t.Fatalf("Expected 2 milestones to cycle through, got %d", len(refreshed.Milestones))
+	}
+	model.milestones = refreshed.Milestones
+
+	model.cycleMilestone()
+	if ms := model.activeMilestone(); ms == nil || ms.ID != beta.ID {
+		t.Fatalf("cycleMilestone() = %v, expected Beta", ms)
+	}
+	todo := model.refreshTasks()().(TasksRefreshedMsg).Tasks[task.StatusTodo]
+	if len(todo) != 1 || todo[0].ID != inBeta.ID {
+		t.Errorf("Expected only the milestone's task, got %v", todo)
+	}
+
+	model.cycleMilestone()
+	model.cycleMilestone()
+	if model.milestone != 0 {
+		t.Errorf("Expected all tasks after the last milestone, got milestone %d", model.milestone)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	model := NewModel(db, Options{Board: "test"})
+	press := func(keys ...tea.KeyMsg) {
+		for _, key := range keys {
+			updated, _ := model.Update(key)
+			m := updated.(Model)
+			model = &m
+		}
+	}
+	refreshed, _ := model.Update(TasksRefreshedMsg{Tasks: map[task.Status][]*task.Task{
+		task.StatusTodo: {
+			{ID: 1, Title: "Write release notes", Status: task.StatusTodo},
+			{ID: 2, Title: "Login page styling", Status: task.StatusTodo},
+		},
+		task.StatusDoing: {{ID: 3, Title: "Fix login redirect", Status: task.StatusDoing}},
+		task.StatusDone:  {{ID: 4, Title: "Set up CI", Status: task.StatusDone}},
+	}})
+	m := refreshed.(Model)
+	model = &m
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("login")})
+	if !model.searching || model.query != "login" {
+		t.Fatalf("Expected to be searching for 'login', got %v %q", model.searching, model.query)
+	}
+	counts := []int{len(model.tasks[task.StatusTodo]), len(model.tasks[task.StatusDoing]), len(model.tasks[task.StatusDone])}
+	if counts[0] != 1 || counts[1] != 1 || counts[2] != 0 {
+		t.Errorf("Expected 1/1/0 matching tasks per column, got %v", counts)
+	}
+
+	// Enter keeps the results; keys then navigate again
+	press(tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if model.searching || model.query != "login" || model.focused != ColumnDoing {
+		t.Errorf("Expected to keep the search and move to doing, got %v %q %v", model.searching, model.query, model.focused)
+	}
+
+	// A refresh keeps the search applied
+	refreshed, _ = model.Update(TasksRefreshedMsg{Tasks: model.loaded})
+	m = refreshed.(Model)
+	model = &m
+	if len(model.tasks[task.StatusTodo]) != 1 {
+		t.Errorf("Expected the search to survive a refresh, got %d todo tasks", len(model.tasks[task.StatusTodo]))
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.query != "" || len(model.tasks[task.StatusTodo]) != 2 {
+		t.Errorf("Expected esc to clear the search, got %q with %d todo tasks", model.query, len(model.tasks[task.StatusTodo]))
+	}
+}
+
+// run feeds a message to the model, then whatever its command returns, and
+// so on until there is nothing left to do
+func run(model *Model, msg tea.Msg) *Model {
+	updated, cmd := model.Update(msg)
+	m := updated.(Model)
+	if cmd != nil {
+		if next := cmd(); next != nil {
+			return run(&m, next)
+		}
+	}
+	return &m
+}
+
+func TestReorder(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	for _, title := range []string{"First", "Second", "Third"} {
+		if _, err := taskSystem.Create(1, title, ""); err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+	}
+
+	model := NewModel(db, Options{Board: "test"})
+	send := func(msg tea.Msg) { model = run(model, msg) }
+	titles := func() []string {
+		var titles []string
+		for _, tk := range model.tasks[task.StatusTodo] {
+			titles = append(titles, tk.Title)
+		}
+		return titles
+	}
+
+	send(model.refreshTasks()())
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("J")})
+	if got := titles(); !reflect.DeepEqual(got, []string{"Second", "First", "Third"}) {
+		t.Errorf("Expected First to move down, got %v", got)
+	}
+	if model.selectedTask[ColumnTodo] != 1 {
+		t.Errorf("Expected the selection to follow the task, got %d", model.selectedTask[ColumnTodo])
+	}
+
+	// The top task cannot move further up
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	send(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	if got := titles(); !reflect.DeepEqual(got, []string{"First", "Second", "Third"}) {
+		t.Errorf("Expected First back on top, got %v", got)
+	}
+	if model.selectedTask[ColumnTodo] != 0 {
+		t.Errorf("Expected the selection to stay on top, got %d", model.selectedTask[ColumnTodo])
+	}
+}
+
+func TestPriority(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	taskSystem.CreateWithPriority(1, "Urgent", "", "high")
+	minor, _ := taskSystem.Create(1, "Minor", "")
+
+	model := NewModel(db, Options{Board: "test"})
+	model = run(model, model.refreshTasks()())
+	model = run(model, tea.KeyMsg{Type: tea.KeyDown})
+	selected := func() *task.Task {
+		return model.tasks[task.StatusTodo][model.selectedTask[ColumnTodo]]
+	}
+
+	// Critical moves the task to the top, and the selection follows it
+	model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4")})
+	if got := selected(); got.ID != minor.ID || got.Priority != task.PriorityCritical || model.selectedTask[ColumnTodo] != 0 {
+		t.Fatalf("Expected Minor selected at the top as critical, got #%d %q at %d", got.ID, got.Title, model.selectedTask[ColumnTodo])
+	}
+
+	// p raises the priority, wrapping from critical back to none
+	model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if got := selected(); got.ID != minor.ID || got.Priority != task.PriorityNone {
+		t.Errorf("Expected Minor back to no priority, got #%d with %d", got.ID, got.Priority)
+	}
+	model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if got, _ := taskSystem.GetByID(minor.ID); got.Priority != task.PriorityLow {
+		t.Errorf("Expected p to raise none to low, got %d", got.Priority)
+	}
+}
+
+func TestDeleteConfirmation(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	doomed, _ := taskSystem.Create(1, "Doomed", "")
+
+	model := NewModel(db, Options{Board: "test"})
+	model = run(model, model.refreshTasks()())
+	// press sends a key without running the command it returns
+	press := func(key string) tea.Cmd {
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m := updated.(Model)
+		model = &m
+		return cmd
+	}
+
+	// d only asks; anything but y or D keeps the task
+	if cmd := press("d"); cmd != nil || model.confirmDelete == nil || model.confirmDelete.ID != doomed.ID {
+		t.Fatalf("Expected d to ask for confirmation, got %v", model.confirmDelete)
+	}
+	press("n")
+	if model.confirmDelete != nil {
+		t.Error("Expected n to dismiss the confirmation")
+	}
+	if _, err := taskSystem.GetByID(doomed.ID); err != nil {
+		t.Fatalf("Expected the task to survive: %v", err)
+	}
+
+	// y moves it to the trash and tells how to undo that
+	press("d")
+	msg := press("y")()
+	if _, err := taskSystem.GetByID(doomed.ID); err == nil {
+		t.Fatal("Expected y to delete the task")
+	}
+	updated, _ := model.Update(msg)
+	m := updated.(Model)
+	model = &m
+	if !strings.Contains(model.notice, "u: undo") || model.deleted == nil {
+		t.Errorf("Expected an undo hint, got %q", model.notice)
+	}
+
+	// u brings it back
+	press("u")()
+	if _, err := taskSystem.GetByID(doomed.ID); err != nil {
+		t.Fatalf("Expected u to restore the task: %v", err)
+	}
+
+	// D deletes it for good
+	model = run(model, model.refreshTasks()())
+	press("d")
+	msg = press("D")()
+	if deleted, ok := msg.(TaskDeletedMsg); !ok || !deleted.Hard {
+		t.Fatalf("Expected a hard delete, got %#v", msg)
+	}
+	if err := taskSystem.RestoreTask(doomed.ID); err == nil {
+		t.Error("Expected a hard-deleted task to be beyond restoring")
+	}
+}
@@ -0,0 +1,124 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// AttachmentsLoadedMsg carries the attachments of a task o was pressed on
+type AttachmentsLoadedMsg struct {
+	Task        *task.Task
+	Attachments []task.Attachment
+}
+
+// AttachmentOpenedMsg is sent once an attachment has been handed to the
+// program that opens it
+type AttachmentOpenedMsg struct {
+	Target string
+}
+
+// maxOpenChoices is how many attachments o offers to pick from by number
+const maxOpenChoices = 9
+
+// openCommand returns the command that opens target, a file or URL, with
+// the desktop's default program for it
+var openCommand = func(target string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		return exec.Command("xdg-open", target)
+	}
+}
+
+// handleOpen loads the attachments of the selected task to open one
+func (m Model) handleOpen() (tea.Model, tea.Cmd) {
+	t := m.selectedTaskInFocus()
+	if t == nil {
+		return m, nil
+	}
+	taskSystem := m.taskSystem
+	return m, func() tea.Msg {
+		attachments, err := taskSystem.ListAttachments(t.ID)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return AttachmentsLoadedMsg{Task: t, Attachments: attachments}
+	}
+}
+
+// handleAttachmentsLoaded opens a task's only attachment, or asks which
+// one to open when it has several
+func (m Model) handleAttachmentsLoaded(msg AttachmentsLoadedMsg) (tea.Model, tea.Cmd) {
+	switch len(msg.Attachments) {
+	case 0:
+		return m, m.showNotice(fmt.Sprintf("#%d has no attachments; add them with: cainban attach %d <path|url>", msg.Task.ID, msg.Task.ID))
+	case 1:
+		return m, openAttachment(msg.Attachments[0])
+	}
+	if len(msg.Attachments) > maxOpenChoices {
+		msg.Attachments = msg.Attachments[:maxOpenChoices]
+	}
+	m.openChoices = msg.Attachments
+	return m, nil
+}
+
+// handleOpenChoiceKeys takes the number of the attachment to open; any
+// other key opens nothing
+func (m Model) handleOpenChoiceKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choices := m.openChoices
+	m.openChoices = nil
+
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if len(key) == 1 && key[0] >= '1' && int(key[0]-'0') <= len(choices) {
+		return m, openAttachment(choices[key[0]-'1'])
+	}
+	return m, nil
+}
+
+// renderOpenChoices is the status bar while o waits for an attachment to
+// be picked
+func (m Model) renderOpenChoices() string {
+	choices := make([]string, len(m.openChoices))
+	for i, a := range m.openChoices {
+		name := a.Target
+		if !a.IsURL() {
+			name = filepath.Base(name)
+		}
+		choices[i] = fmt.Sprintf("%d: %s", i+1, name)
+	}
+	prompt := lipgloss.NewStyle().Foreground(m.styles.Palette.Primary).Bold(true)
+	return prompt.Render("Open") + "  " + strings.Join(choices, " • ") + "  " +
+		lipgloss.NewStyle().Foreground(m.styles.Palette.Muted).Render("esc: cancel")
+}
+
+// openAttachment opens an attachment without waiting for the program that
+// shows it, which keeps its output off the board
+func openAttachment(a task.Attachment) tea.Cmd {
+	return func() tea.Msg {
+		if !a.IsURL() {
+			if _, err := os.Stat(a.Target); err != nil {
+				return ErrorMsg{Err: fmt.Errorf("cannot open %s: %w", a.Target, err)}
+			}
+		}
+		cmd := openCommand(a.Target)
+		if err := cmd.Start(); err != nil {
+			return ErrorMsg{Err: fmt.Errorf("cannot open %s: %w", a.Target, err)}
+		}
+		go cmd.Wait()
+		return AttachmentOpenedMsg{Target: a.Target}
+	}
+}
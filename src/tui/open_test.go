@@ -0,0 +1,86 @@
+package tui
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestOpenAttachment(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	bare, _ := taskSystem.Create(1, "Bare", "")
+	fix, _ := taskSystem.Create(1, "Fix the parser", "")
+	notes := filepath.Join(t.TempDir(), "notes.md")
+	for _, target := range []string{"https://example.com/issue/7", notes} {
+		if _, err := taskSystem.Attach(fix.ID, target); err != nil {
+			t.Fatalf("Attach: %v", err)
+		}
+	}
+
+	var opened []string
+	defer func(original func(string) *exec.Cmd) { openCommand = original }(openCommand)
+	openCommand = func(target string) *exec.Cmd {
+		opened = append(opened, target)
+		return exec.Command("true")
+	}
+
+	model := NewModel(db, Options{Board: "test", Theme: "no-color"})
+	model = run(model, tea.WindowSizeMsg{Width: 120, Height: 40})
+	model = run(model, model.refreshTasks()())
+
+	// Opening shows a notice, which ticks, so the last command is not run
+	press := func(key string) {
+		updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		for {
+			m := updated.(Model)
+			model = &m
+			if cmd == nil || model.notice != "" {
+				return
+			}
+			updated, cmd = model.Update(cmd())
+		}
+	}
+	selectTask := func(id int) {
+		for i, tk := range model.tasks[task.StatusTodo] {
+			if tk.ID == id {
+				model.selectedTask[ColumnTodo] = i
+			}
+		}
+	}
+
+	selectTask(bare.ID)
+	press("o")
+	if !strings.Contains(model.notice, "no attachments") {
+		t.Errorf("Expected a notice that there is nothing to open, got %q", model.notice)
+	}
+
+	model.notice = ""
+	selectTask(fix.ID)
+	press("o")
+	if len(model.openChoices) != 2 || !strings.Contains(model.View(), "2: notes.md") {
+		t.Fatalf("Expected to be asked which of two attachments to open, got:\n%s", model.View())
+	}
+	press("1")
+	if len(opened) != 1 || opened[0] != "https://example.com/issue/7" || model.openChoices != nil {
+		t.Errorf("Expected 1 to open the URL, opened %v", opened)
+	}
+
+	// A file that is gone is not handed to the opener
+	model.notice = ""
+	press("o")
+	press("2")
+	if len(opened) != 1 || !strings.Contains(model.notice, "cannot open") {
+		t.Errorf("Expected the missing file not to be opened, opened %v with notice %q", opened, model.notice)
+	}
+}
@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// pickerHeight is how many matching tasks the picker lists at once
+const pickerHeight = 10
+
+// PickerModel is an fzf-style fuzzy finder over tasks: typing narrows the
+// list using the same scoring as `cainban search`, enter picks the
+// highlighted task and then, when there are actions, one of them
+type PickerModel struct {
+	tasks   []*task.Task
+	actions []string
+	theme   Palette
+
+	query    string
+	matches  []*task.Task
+	cursor   int
+	offset   int
+	picked   *task.Task
+	action   string
+	choosing bool // picking an action for the picked task
+	canceled bool
+}
+
+// NewPicker creates a picker over tasks. With no actions it finishes as soon
+// as a task is picked.
+func NewPicker(tasks []*task.Task, actions []string, theme string) PickerModel {
+	return PickerModel{
+		tasks:   tasks,
+		actions: actions,
+		theme:   PaletteForTheme(theme),
+		matches: tasks,
+	}
+}
+
+// Result returns the picked task and action; the task is nil when the
+// picker was canceled
+func (m PickerModel) Result() (*task.Task, string) {
+	if m.canceled {
+		return nil, ""
+	}
+	return m.picked, m.action
+}
+
+// Init implements tea.Model
+func (m PickerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model
+func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "ctrl+c":
+		m.canceled = true
+		return m, tea.Quit
+	case "esc":
+		if m.choosing {
+			m.choosing = false
+			m.cursor, m.offset = 0, 0
+			return m, nil
+		}
+		m.canceled = true
+		return m, tea.Quit
+	case "up", "ctrl+p", "ctrl+k":
+		m.move(-1)
+		return m, nil
+	case "down", "ctrl+n", "ctrl+j", "tab":
+		m.move(1)
+		return m, nil
+	case "enter":
+		return m.choose()
+	}
+
+	if m.choosing {
+		return m, nil
+	}
+
+	switch key.Type {
+	case tea.KeyBackspace:
+		if runes := []rune(m.query); len(runes) > 0 {
+			m.setQuery(string(runes[:len(runes)-1]))
+		}
+	case tea.KeyCtrlU:
+		m.setQuery("")
+	case tea.KeyRunes, tea.KeySpace:
+		m.setQuery(m.query + string(key.Runes))
+	}
+	return m, nil
+}
+
+// setQuery filters the tasks again and highlights the best match
+func (m *PickerModel) setQuery(query string) {
+	m.query = query
+	m.matches = task.RankTasks(m.tasks, query)
+	m.cursor, m.offset = 0, 0
+}
+
+// move moves the highlight, scrolling the list to keep it visible
+func (m *PickerModel) move(delta int) {
+	count := len(m.matches)
+	if m.choosing {
+		count = len(m.actions)
+	}
+	if count == 0 {
+		return
+	}
+	m.cursor = (m.cursor + delta + count) % count
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+pickerHeight {
+		m.offset = m.cursor - pickerHeight + 1
+	}
+}
+
+// choose picks the highlighted task or action
+func (m PickerModel) choose() (tea.Model, tea.Cmd) {
+	if m.choosing {
+		m.action = m.actions[m.cursor]
+		return m, tea.Quit
+	}
+	if len(m.matches) == 0 {
+		return m, nil
+	}
+
+	m.picked = m.matches[m.cursor]
+	if len(m.actions) == 0 {
+		return m, tea.Quit
+	}
+	m.choosing = true
+	m.cursor, m.offset = 0, 0
+	return m, nil
+}
+
+// View implements tea.Model
+func (m PickerModel) View() string {
+	prompt := lipgloss.NewStyle().Foreground(m.theme.Primary).Bold(true)
+	selected := lipgloss.NewStyle().Foreground(m.theme.Text).Background(m.theme.Selected).Bold(true)
+	muted := lipgloss.NewStyle().Foreground(m.theme.Muted)
+
+	var b strings.Builder
+	if m.choosing {
+		b.WriteString(prompt.Render(fmt.Sprintf("#%d %s", m.picked.ID, m.picked.Title)) + "\n")
+		for i, action := range m.actions {
+			line := "  " + action
+			if i == m.cursor {
+				line = selected.Render("▶ " + action)
+			}
+			b.WriteString(line + "\n")
+		}
+		b.WriteString(muted.Render("↑/↓ choose • enter run • esc back"))
+		return b.String()
+	}
+
+	b.WriteString(prompt.Render("> ") + m.query + "█\n")
+	end := m.offset + pickerHeight
+	if end > len(m.matches) {
+		end = len(m.matches)
+	}
+	for i := m.offset; i < end; i++ {
+		t := m.matches[i]
+		line := fmt.Sprintf("#%-4d %-5s %s", t.ID, t.Status, t.Title)
+		if t.Priority > task.PriorityNone {
+			line += " [" + task.GetPriorityName(t.Priority) + "]"
+		}
+		if i == m.cursor {
+			b.WriteString(selected.Render("▶ "+line) + "\n")
+		} else {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	b.WriteString(muted.Render(fmt.Sprintf("%d/%d • type to filter • ↑/↓ move • enter pick • esc cancel", len(m.matches), len(m.tasks))))
+	return b.String()
+}
+
+// Pick runs the picker inline on the terminal, drawing on stderr so that
+// stdout stays free for the result. It returns a nil task if canceled.
+func Pick(tasks []*task.Task, actions []string, theme string) (*task.Task, string, error) {
+	program := tea.NewProgram(NewPicker(tasks, actions, theme), tea.WithOutput(os.Stderr))
+	final, err := program.Run()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to run picker: %w", err)
+	}
+	picked, action := final.(PickerModel).Result()
+	return picked, action, nil
+}
@@ -0,0 +1,71 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func typeKeys(m PickerModel, keys ...tea.KeyMsg) PickerModel {
+	for _, key := range keys {
+		updated, _ := m.Update(key)
+		m = updated.(PickerModel)
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestPicker(t *testing.T) {
+	tasks := []*task.Task{
+		{ID: 1, Title: "Write release notes", Status: task.StatusTodo},
+		{ID: 2, Title: "Fix login redirect", Status: task.StatusDoing},
+		{ID: 3, Title: "Login page styling", Status: task.StatusTodo},
+	}
+	actions := []string{"show", "done"}
+
+	m := NewPicker(tasks, actions, "dark")
+	if len(m.matches) != 3 {
+		t.Fatalf("Expected every task before typing, got %d", len(m.matches))
+	}
+
+	m = typeKeys(m, runes("log"), runes("i"), runes("n"))
+	if len(m.matches) != 2 {
+		t.Fatalf("Expected 2 matches for 'login', got %d", len(m.matches))
+	}
+
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyDown})
+	if m.query != "logi" || m.cursor != 1 {
+		t.Errorf("query = %q, cursor = %d", m.query, m.cursor)
+	}
+
+	// Wraps around past the last match
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.choosing || m.picked.ID != m.matches[0].ID {
+		t.Fatalf("Expected to choose an action for the first match, got %+v", m.picked)
+	}
+
+	// Esc goes back to the task list, then the action is picked
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.choosing || m.canceled {
+		t.Fatal("Expected esc to return to the task list")
+	}
+	m = typeKeys(m, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnter})
+	picked, action := m.Result()
+	if picked == nil || action != "done" {
+		t.Errorf("Result() = %v, %q", picked, action)
+	}
+
+	m = typeKeys(NewPicker(tasks, nil, "dark"), runes("zzz"), tea.KeyMsg{Type: tea.KeyEnter})
+	if picked, _ := m.Result(); picked != nil {
+		t.Errorf("Expected nothing picked without matches, got %+v", picked)
+	}
+
+	m = typeKeys(NewPicker(tasks, nil, "dark"), tea.KeyMsg{Type: tea.KeyEsc})
+	if picked, _ := m.Result(); picked != nil || !m.canceled {
+		t.Error("Expected esc to cancel the picker")
+	}
+}
@@ -1,10 +1,140 @@
 package tui
 
 import (
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/charmbracelet/lipgloss"
+	"github.com/hmain/cainban/src/systems/markdown"
 	"github.com/hmain/cainban/src/systems/task"
 )
 
+// Themes lists the theme names ParseTheme accepts. "auto" follows the
+// terminal: no colors under NO_COLOR, otherwise dark or light to match its
+// background.
+var Themes = []string{"auto", "dark", "light", "solarized", "high-contrast", "no-color"}
+
+// Palette is the set of colors a theme is built from
+type Palette struct {
+	Primary    lipgloss.Color
+	Secondary  lipgloss.Color
+	Warning    lipgloss.Color
+	Danger     lipgloss.Color
+	Muted      lipgloss.Color
+	Text       lipgloss.Color
+	Background lipgloss.Color
+	Surface    lipgloss.Color
+	Border     lipgloss.Color
+	Selected   lipgloss.Color
+	Success    lipgloss.Color
+
+	// Monochrome palettes have no colors at all, so selection is shown
+	// in reverse video instead
+	Monochrome bool
+}
+
+// DarkPalette is the default purple-on-dark theme
+var DarkPalette = Palette{
+	Primary:    lipgloss.Color("#7C3AED"), // Purple
+	Secondary:  lipgloss.Color("#3B82F6"), // Blue
+	Warning:    lipgloss.Color("#F59E0B"), // Yellow
+	Danger:     lipgloss.Color("#EF4444"), // Red
+	Muted:      lipgloss.Color("#6B7280"), // Gray
+	Text:       lipgloss.Color("#F9FAFB"), // Near white
+	Background: lipgloss.Color("#1F2937"), // Dark gray
+	Surface:    lipgloss.Color("#374151"), // Medium gray
+	Border:     lipgloss.Color("#4B5563"), // Light gray
+	Selected:   lipgloss.Color("#312E81"), // Indigo
+	Success:    lipgloss.Color("#10B981"), // Green
+}
+
+// LightPalette suits terminals with a light background
+var LightPalette = Palette{
+	Primary:    lipgloss.Color("#6D28D9"),
+	Secondary:  lipgloss.Color("#1D4ED8"),
+	Warning:    lipgloss.Color("#B45309"),
+	Danger:     lipgloss.Color("#B91C1C"),
+	Muted:      lipgloss.Color("#6B7280"),
+	Text:       lipgloss.Color("#111827"),
+	Background: lipgloss.Color("#F9FAFB"),
+	Surface:    lipgloss.Color("#E5E7EB"),
+	Border:     lipgloss.Color("#9CA3AF"),
+	Selected:   lipgloss.Color("#DDD6FE"),
+	Success:    lipgloss.Color("#047857"),
+}
+
+// SolarizedPalette is Solarized dark
+var SolarizedPalette = Palette{
+	Primary:    lipgloss.Color("#6C71C4"), // Violet
+	Secondary:  lipgloss.Color("#268BD2"), // Blue
+	Warning:    lipgloss.Color("#B58900"), // Yellow
+	Danger:     lipgloss.Color("#DC322F"), // Red
+	Muted:      lipgloss.Color("#586E75"), // base01
+	Text:       lipgloss.Color("#93A1A1"), // base1
+	Background: lipgloss.Color("#002B36"), // base03
+	Surface:    lipgloss.Color("#073642"), // base02
+	Border:     lipgloss.Color("#586E75"), // base01
+	Selected:   lipgloss.Color("#0B4F5F"),
+	Success:    lipgloss.Color("#859900"), // Green
+}
+
+// HighContrastPalette keeps to the bright basic ANSI colors on black,
+// which every terminal renders at full strength
+var HighContrastPalette = Palette{
+	Primary:    lipgloss.Color("14"), // Bright cyan
+	Secondary:  lipgloss.Color("15"), // White
+	Warning:    lipgloss.Color("11"), // Bright yellow
+	Danger:     lipgloss.Color("9"),  // Bright red
+	Muted:      lipgloss.Color("7"),  // Light gray
+	Text:       lipgloss.Color("15"),
+	Background: lipgloss.Color("0"), // Black
+	Surface:    lipgloss.Color("0"),
+	Border:     lipgloss.Color("15"),
+	Selected:   lipgloss.Color("4"),  // Blue
+	Success:    lipgloss.Color("10"), // Bright green
+}
+
+// NoColorPalette leaves every color to the terminal
+var NoColorPalette = Palette{Monochrome: true}
+
+var palettes = map[string]Palette{
+	"dark":          DarkPalette,
+	"light":         LightPalette,
+	"solarized":     SolarizedPalette,
+	"high-contrast": HighContrastPalette,
+	"no-color":      NoColorPalette,
+}
+
+// ParseTheme returns the palette for one of Themes. An empty name means
+// "auto".
+func ParseTheme(theme string) (Palette, error) {
+	if palette, ok := palettes[theme]; ok {
+		return palette, nil
+	}
+	if theme != "" && theme != "auto" {
+		return Palette{}, fmt.Errorf("unknown theme %q (want %s)", theme, strings.Join(Themes, ", "))
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return NoColorPalette, nil
+	}
+	if !lipgloss.HasDarkBackground() {
+		return LightPalette, nil
+	}
+	return DarkPalette, nil
+}
+
+// PaletteForTheme returns the palette for a theme name, following the
+// terminal as "auto" does for names it does not know
+func PaletteForTheme(theme string) Palette {
+	palette, err := ParseTheme(theme)
+	if err != nil {
+		palette, _ = ParseTheme("auto")
+	}
+	return palette
+}
+
 // DefaultStyles returns the default styling configuration
 func DefaultStyles() Styles {
 	return DefaultStylesWithDimensions(30, 20) // Default fallback dimensions
@@ -12,20 +142,25 @@ func DefaultStyles() Styles {
 
 // DefaultStylesWithDimensions returns styling with custom column dimensions
 func DefaultStylesWithDimensions(columnWidth, columnHeight int) Styles {
+	return ThemedStyles(DarkPalette, columnWidth, columnHeight)
+}
+
+// ThemedStyles returns styling built from a palette with custom column dimensions
+func ThemedStyles(palette Palette, columnWidth, columnHeight int) Styles {
 	// Color palette
 	var (
-		primary    = lipgloss.Color("#7C3AED")  // Purple
-		secondary  = lipgloss.Color("#3B82F6")  // Blue
-		warning    = lipgloss.Color("#F59E0B")  // Yellow
-		danger     = lipgloss.Color("#EF4444")  // Red
-		muted      = lipgloss.Color("#6B7280")  // Gray
-		background = lipgloss.Color("#1F2937")  // Dark gray
-		surface    = lipgloss.Color("#374151")  // Medium gray
-		border     = lipgloss.Color("#4B5563")  // Light gray
+		primary    = palette.Primary
+		secondary  = palette.Secondary
+		warning    = palette.Warning
+		danger     = palette.Danger
+		muted      = palette.Muted
+		background = palette.Background
+		surface    = palette.Surface
+		border     = palette.Border
 	)
 
 	base := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#F9FAFB")).
+		Foreground(palette.Text).
 		Background(background).
 		Align(lipgloss.Left). // Horizontal alignment
 		AlignVertical(lipgloss.Top) // Vertical alignment - start at top
@@ -63,7 +198,8 @@ func DefaultStylesWithDimensions(columnWidth, columnHeight int) Styles {
 
 	taskSelected := taskBase.Copy().
 		BorderForeground(primary).
-		Background(lipgloss.Color("#312E81"))
+		Background(palette.Selected).
+		Reverse(palette.Monochrome)
 
 	help := lipgloss.NewStyle().
 		Foreground(muted).
@@ -79,9 +215,9 @@ func DefaultStylesWithDimensions(columnWidth, columnHeight int) Styles {
 	// Priority styles
 	priorityStyles := map[int]lipgloss.Style{
 		task.PriorityNone:     taskBase.Copy().BorderForeground(muted),
-		task.PriorityLow:      taskBase.Copy().BorderForeground(lipgloss.Color("#059669")),
+		task.PriorityLow:      taskBase.Copy().BorderForeground(palette.Success),
 		task.PriorityMedium:   taskBase.Copy().BorderForeground(warning),
-		task.PriorityHigh:     taskBase.Copy().BorderForeground(lipgloss.Color("#DC2626")),
+		task.PriorityHigh:     taskBase.Copy().BorderForeground(danger),
 		task.PriorityCritical: taskBase.Copy().BorderForeground(danger).Bold(true),
 	}
 
@@ -95,6 +231,7 @@ func DefaultStylesWithDimensions(columnWidth, columnHeight int) Styles {
 		TaskPriority: priorityStyles,
 		Help:         help,
 		StatusBar:    statusBar,
+		Palette:      palette,
 	}
 }
 
@@ -109,11 +246,11 @@ func (s Styles) PriorityIndicator(priority int) string {
 	}
 	
 	colors := map[int]lipgloss.Color{
-		task.PriorityNone:     lipgloss.Color("#6B7280"),
-		task.PriorityLow:      lipgloss.Color("#059669"),
-		task.PriorityMedium:   lipgloss.Color("#F59E0B"),
-		task.PriorityHigh:     lipgloss.Color("#DC2626"),
-		task.PriorityCritical: lipgloss.Color("#EF4444"),
+		task.PriorityNone:     s.Palette.Muted,
+		task.PriorityLow:      s.Palette.Success,
+		task.PriorityMedium:   s.Palette.Warning,
+		task.PriorityHigh:     s.Palette.Danger,
+		task.PriorityCritical: s.Palette.Danger,
 	}
 	
 	indicator := indicators[priority]
@@ -123,15 +260,26 @@ func (s Styles) PriorityIndicator(priority int) string {
 }
 
 // StatusColor returns the color for a given task status
-func StatusColor(status task.Status) lipgloss.Color {
+func (p Palette) StatusColor(status task.Status) lipgloss.Color {
 	switch status {
-	case task.StatusTodo:
-		return lipgloss.Color("#6B7280")  // Gray
 	case task.StatusDoing:
-		return lipgloss.Color("#3B82F6")  // Blue
+		return p.Secondary
 	case task.StatusDone:
-		return lipgloss.Color("#10B981")  // Green
+		return p.Success
 	default:
-		return lipgloss.Color("#6B7280")
+		return p.Muted
 	}
-}
\ No newline at end of file
+}
+// MarkdownStyles returns how task descriptions' Markdown is shown in this
+// palette
+func (p Palette) MarkdownStyles() markdown.Styles {
+	return markdown.Styles{
+		Heading: lipgloss.NewStyle().Bold(true).Foreground(p.Primary),
+		Bold:    lipgloss.NewStyle().Bold(true),
+		Italic:  lipgloss.NewStyle().Italic(true),
+		Code:    lipgloss.NewStyle().Foreground(p.Warning),
+		Link:    lipgloss.NewStyle().Foreground(p.Secondary).Underline(true),
+		Quote:   lipgloss.NewStyle().Italic(true),
+		Muted:   lipgloss.NewStyle().Foreground(p.Muted),
+	}
+}
@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTheme(t *testing.T) {
+	for _, theme := range Themes {
+		if _, err := ParseTheme(theme); err != nil {
+			t.Errorf("ParseTheme(%q): %v", theme, err)
+		}
+	}
+	if palette, _ := ParseTheme("solarized"); palette != SolarizedPalette {
+		t.Errorf("ParseTheme(solarized) = %v", palette)
+	}
+	if _, err := ParseTheme("neon"); err == nil || !strings.Contains(err.Error(), "unknown theme") {
+		t.Errorf("ParseTheme(neon): expected an unknown theme error, got %v", err)
+	}
+	if palette := PaletteForTheme("neon"); palette.Text == "" && !palette.Monochrome {
+		t.Errorf("PaletteForTheme(neon) should fall back to auto, got %v", palette)
+	}
+
+	// NO_COLOR turns auto colorless, but a theme asked for by name wins
+	t.Setenv("NO_COLOR", "1")
+	if palette, _ := ParseTheme("auto"); !palette.Monochrome {
+		t.Errorf("auto under NO_COLOR = %v, want no colors", palette)
+	}
+	if palette, _ := ParseTheme("dark"); palette != DarkPalette {
+		t.Errorf("dark under NO_COLOR = %v, want the dark palette", palette)
+	}
+}
+
+func TestNoColorStyles(t *testing.T) {
+	styles := ThemedStyles(NoColorPalette, 30, 20)
+	if !styles.TaskSelected.GetReverse() {
+		t.Error("without colors the selected task should be shown in reverse video")
+	}
+	if styles.Task.GetReverse() {
+		t.Error("unselected tasks should not be reversed")
+	}
+}
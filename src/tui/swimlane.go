@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+// Swimlanes groups the tasks of each column into lanes; "" shows one lane
+type Swimlanes string
+
+const (
+	SwimlanesOff      Swimlanes = ""
+	SwimlanesPriority Swimlanes = "priority"
+	SwimlanesTag      Swimlanes = "tag"
+	SwimlanesAssignee Swimlanes = "assignee"
+)
+
+// swimlaneModes is the order g cycles through
+var swimlaneModes = []Swimlanes{SwimlanesOff, SwimlanesPriority, SwimlanesTag, SwimlanesAssignee}
+
+// lane is the swimlane of a task: its name, and rank to order lanes by
+type lane struct {
+	name string
+	rank string
+}
+
+// laneOf returns the lane a task belongs to. Priority lanes run from
+// critical down, tag and assignee lanes alphabetically with the tasks
+// without one last. A task with several tags goes in the lane of the first.
+func (s Swimlanes) laneOf(t *task.Task) lane {
+	switch s {
+	case SwimlanesPriority:
+		return lane{task.GetPriorityName(t.Priority), fmt.Sprintf("%d", 9-t.Priority)}
+	case SwimlanesTag:
+		if len(t.Contexts) == 0 {
+			return lane{"no tag", "\xff"}
+		}
+		return lane{t.Contexts[0], strings.ToLower(t.Contexts[0])}
+	case SwimlanesAssignee:
+		if t.Assignee == "" {
+			return lane{"unassigned", "\xff"}
+		}
+		return lane{t.Assignee, strings.ToLower(t.Assignee)}
+	}
+	return lane{}
+}
+
+// group orders tasks lane by lane, keeping their order within a lane
+func (s Swimlanes) group(tasks []*task.Task) []*task.Task {
+	if s == SwimlanesOff {
+		return tasks
+	}
+	grouped := append([]*task.Task(nil), tasks...)
+	sort.SliceStable(grouped, func(i, j int) bool {
+		return s.laneOf(grouped[i]).rank < s.laneOf(grouped[j]).rank
+	})
+	return grouped
+}
+
+// cycleSwimlanes switches to the next way of grouping the columns
+func (m *Model) cycleSwimlanes() {
+	next := SwimlanesOff
+	for i, s := range swimlaneModes {
+		if s == m.swimlanes && i+1 < len(swimlaneModes) {
+			next = swimlaneModes[i+1]
+		}
+	}
+
+	// Keep the selected tasks selected as they move between lanes
+	selected := make(map[Column]int, len(m.selectedTask))
+	for col, index := range m.selectedTask {
+		if tasks := m.tasks[m.columnToStatus(col)]; index < len(tasks) {
+			selected[col] = tasks[index].ID
+		}
+	}
+	m.swimlanes = next
+	m.applySearch()
+	for col, id := range selected {
+		for i, t := range m.tasks[m.columnToStatus(col)] {
+			if t.ID == id {
+				m.selectedTask[col] = i
+			}
+		}
+	}
+	m.updateViewportContent()
+}
+
+// laneHeader renders the line that opens a lane of count tasks
+func (m Model) laneHeader(name string, count, width int) string {
+	label := fmt.Sprintf("── %s (%d) ", name, count)
+	if fill := width - ansi.StringWidth(label); fill > 0 {
+		label += strings.Repeat("─", fill)
+	}
+	label = ansi.Truncate(label, width, "")
+	return lipgloss.NewStyle().Foreground(m.styles.Palette.Secondary).Bold(true).Render(label)
+}
+
+// laneCounts counts the tasks in each lane of a column
+func (s Swimlanes) laneCounts(tasks []*task.Task) map[string]int {
+	counts := make(map[string]int)
+	for _, t := range tasks {
+		counts[s.laneOf(t).name]++
+	}
+	return counts
+}
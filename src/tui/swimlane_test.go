@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/storage"
+	"github.com/hmain/cainban/src/systems/task"
+)
+
+func TestSwimlanes(t *testing.T) {
+	db, err := storage.NewMemory()
+	if err != nil {
+		t.Fatalf("Failed to create test database: %v", err)
+	}
+	defer db.Close()
+
+	taskSystem := task.New(db.Conn())
+	create := func(title string, priority int, assignee, context string) *task.Task {
+		created, err := taskSystem.CreateWithPriority(1, title, "", priority)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if assignee != "" {
+			if _, err := taskSystem.Assign(created.ID, assignee); err != nil {
+				t.Fatalf("Assign: %v", err)
+			}
+		}
+		if context != "" {
+			if err := taskSystem.AddContext(created.ID, context); err != nil {
+				t.Fatalf("AddContext: %v", err)
+			}
+		}
+		return created
+	}
+	create("Tidy the wiki", 0, "", "@office")
+	outage := create("Fix the outage", 4, "sam", "")
+	create("Plan the offsite", 2, "ana", "@office")
+
+	model := NewModel(db, Options{Board: "test", Theme: "no-color"})
+	model = run(model, tea.WindowSizeMsg{Width: 160, Height: 50})
+	model = run(model, model.refreshTasks()())
+	model.selectedTask[ColumnTodo] = 0 // the outage, the highest priority
+	lanes := func() []string {
+		var names []string
+		for _, line := range strings.Split(model.viewports[ColumnTodo].View(), "\n") {
+			if strings.HasPrefix(line, "── ") {
+				names = append(names, strings.TrimRight(strings.TrimPrefix(line, "── "), "─ "))
+			}
+		}
+		return names
+	}
+
+	if got := lanes(); len(got) != 0 {
+		t.Fatalf("Expected no lanes by default, got %q", got)
+	}
+
+	tests := []struct {
+		mode Swimlanes
+		want string
+	}{
+		{SwimlanesPriority, "critical (1), medium (1), none (1)"},
+		{SwimlanesTag, "@office (2), no tag (1)"},
+		{SwimlanesAssignee, "ana (1), sam (1), unassigned (1)"},
+		{SwimlanesOff, ""},
+	}
+	for _, tt := range tests {
+		model = run(model, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+		if model.swimlanes != tt.mode {
+			t.Fatalf("Expected g to switch to %q lanes, got %q", tt.mode, model.swimlanes)
+		}
+		if got := strings.Join(lanes(), ", "); got != tt.want {
+			t.Errorf("%q lanes = %q, want %q", tt.mode, got, tt.want)
+		}
+		if selected := model.tasks[task.StatusTodo][model.selectedTask[ColumnTodo]]; selected.ID != outage.ID {
+			t.Errorf("%q lanes: expected the selection to stay on the outage, got %q", tt.mode, selected.Title)
+		}
+	}
+}
@@ -9,9 +9,15 @@ import (
 )
 
 // Run starts the TUI application
-func Run(db *storage.DB) error {
+func Run(db *storage.DB, opts Options) error {
 	// Create the model
-	model := NewModel(db)
+	model := NewModel(db, opts)
+	
+	// Show tasks added from other terminals or by MCP agents as they come
+	if watcher, err := db.Watch(); err == nil {
+		defer watcher.Close()
+		model.watcher = watcher
+	}
 	
 	// Create the program
 	program := tea.NewProgram(
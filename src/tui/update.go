@@ -1,8 +1,12 @@
 package tui
 
 import (
+	"context"
+	"fmt"
 	"time"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbletea"
+	"github.com/hmain/cainban/src/systems/milestone"
 	"github.com/hmain/cainban/src/systems/task"
 )
 
@@ -35,18 +39,91 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, tea.Tick(1, func(_ time.Time) tea.Msg { return nil })
 		
 	case tea.KeyMsg:
+		if m.replaying && m.keymap.action(msg.String()) != ActionQuit {
+			// Keys pressed while a macro runs would land anywhere in it
+			return m, nil
+		}
 		return m.handleKeyPress(msg)
 		
+	case MacroStepMsg:
+		return m.replayStep(msg)
+		
+	case AsyncDoneMsg:
+		return m.finishAsync(msg)
+		
+	case spinner.TickMsg:
+		if m.busy == nil {
+			// Let the spinner stop once the operation is done
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+		
 	case TasksRefreshedMsg:
-		m.tasks = msg.Tasks
+		m.loaded = msg.Tasks
+		m.contexts = msg.Contexts
+		m.milestones = msg.Milestones
+		m.columnNotes = msg.ColumnNotes
+		m.links = msg.Links
+		if m.focusTask != nil {
+			m.refreshFocus()
+		}
+		// Keep the search applied to the refreshed tasks
+		m.applySearch()
+		if m.selectID != 0 {
+			for i, t := range m.tasks[m.columnToStatus(m.focused)] {
+				if t.ID == m.selectID {
+					m.selectedTask[m.focused] = i
+				}
+			}
+			m.selectID = 0
+		}
 		// Update viewport content when tasks change
 		m.updateViewportContent()
 		return m, nil
 		
-	case ErrorMsg:
-		// Handle errors (could show in status bar)
+	case DatabasePolledMsg:
+		// Keep watching, and reload when another process changed the board
+		if msg.Changed {
+			return m, tea.Batch(m.refreshTasks(), m.watchDatabase())
+		}
+		return m, m.watchDatabase()
+		
+	case TaskDeletedMsg:
+		notice := fmt.Sprintf("Deleted #%d %q for good", msg.Task.ID, msg.Task.Title)
+		if !msg.Hard {
+			m.deleted = msg.Task
+			notice = fmt.Sprintf("Deleted #%d %q • u: undo", msg.Task.ID, msg.Task.Title)
+		}
+		return m, tea.Batch(m.refreshTasks(), m.showNotice(notice))
+		
+	case TaskRestoredMsg:
+		m.selectID = msg.Task.ID
+		return m, tea.Batch(m.refreshTasks(), m.showNotice(fmt.Sprintf("Restored #%d %q", msg.Task.ID, msg.Task.Title)))
+		
+	case AttachmentsLoadedMsg:
+		return m.handleAttachmentsLoaded(msg)
+		
+	case AttachmentOpenedMsg:
+		return m, m.showNotice("Opened " + msg.Target)
+		
+	case FocusTickMsg:
+		if m.currentView != ViewFocus || msg.ID != m.focusID {
+			return m, nil
+		}
+		m.focusNow = msg.Time
+		return m, focusTick(m.focusID)
+		
+	case NoticeExpiredMsg:
+		if msg.ID == m.noticeID {
+			m.notice = ""
+		}
 		return m, nil
 		
+	case ErrorMsg:
+		return m, m.showNotice("Error: " + msg.Err.Error())
+		
 	case string:
 		if msg == "init_viewports" {
 			// Initialize viewport content
@@ -60,13 +137,34 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKeyPress processes keyboard input
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.macroPrompt != "" {
+		return m.handleRegisterKey(msg)
+	}
+	if m.recording != 0 {
+		m.recorded = append(m.recorded, msg)
+	}
+	if m.busy != nil && msg.Type == tea.KeyEsc {
+		return m.handleCancelKey()
+	}
+	
 	switch m.currentView {
 	case ViewKanban:
+		if m.confirmDelete != nil {
+			return m.handleConfirmDeleteKeys(msg)
+		}
+		if m.openChoices != nil {
+			return m.handleOpenChoiceKeys(msg)
+		}
+		if m.searching {
+			return m.handleSearchKeys(msg)
+		}
 		return m.handleKanbanKeys(msg)
 	case ViewHelp:
 		return m.handleHelpKeys(msg)
 	case ViewTaskDetail:
 		return m.handleTaskDetailKeys(msg)
+	case ViewFocus:
+		return m.handleFocusKeys(msg)
 	}
 	
 	return m, nil
@@ -77,31 +175,70 @@ func (m Model) handleKanbanKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 	
-	switch msg.String() {
-	case "q", "ctrl+c":
+	switch m.keymap.action(msg.String()) {
+	case ActionQuit:
 		return m, tea.Quit
 		
-	case "?":
+	case ActionHelp:
 		m.currentView = ViewHelp
 		return m, nil
 		
-	case "r":
+	case ActionRefresh:
+		// The queries are bound to the operation, so esc stops them
+		bound := m
+		return m, m.runAsync("Refreshing tasks", func(ctx context.Context) tea.Msg {
+			bound.taskSystem = bound.taskSystem.WithContext(ctx)
+			if bound.milestoneSystem != nil {
+				bound.milestoneSystem = bound.milestoneSystem.WithContext(ctx)
+			}
+			return bound.refreshTasks()()
+		})
+		
+	case ActionContext:
+		m.cycleContext()
+		return m, m.refreshTasks()
+		
+	case ActionMilestone:
+		m.cycleMilestone()
 		return m, m.refreshTasks()
 		
+	case ActionSearch:
+		m.searching = true
+		return m, nil
+		
+	case ActionFocus:
+		return m.handleFocus()
+		
+	case ActionRecord, ActionReplay:
+		return m.handleMacroKey(m.keymap.action(msg.String()))
+		
+	case ActionLanes:
+		m.cycleSwimlanes()
+		return m, nil
+		
+	case ActionDetails:
+		m.detailed = !m.detailed
+		m.updateViewportContent()
+		return m, nil
+		
+	case ActionClear:
+		m.setQuery("")
+		return m, nil
+		
 	// Navigation
-	case "h", "left":
+	case ActionLeft:
 		if m.focused > ColumnTodo {
 			m.focused--
 		}
 		return m, nil
 		
-	case "l", "right":
+	case ActionRight:
 		if m.focused < ColumnDone {
 			m.focused++
 		}
 		return m, nil
 		
-	case "j", "down":
+	case ActionDown:
 		m.moveSelectionDown()
 		// Also update the focused viewport to handle scrolling
 		vp := m.viewports[m.focused]
@@ -110,7 +247,7 @@ func (m Model) handleKanbanKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 		return m, tea.Batch(cmds...)
 		
-	case "k", "up":
+	case ActionUp:
 		m.moveSelectionUp()
 		// Also update the focused viewport to handle scrolling
 		vp := m.viewports[m.focused]
@@ -119,32 +256,127 @@ func (m Model) handleKanbanKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, cmd)
 		return m, tea.Batch(cmds...)
 		
+	// Reorder the selected task within its column
+	case ActionMoveDown:
+		return m.handleReorder(1)
+		
+	case ActionMoveUp:
+		return m.handleReorder(-1)
+		
 	// Task actions
-	case "enter":
+	case ActionAdvance:
 		return m.handleTaskAction()
 		
-	case "n":
+	case ActionPriority:
+		return m.handlePriority(-1)
+		
+	case ActionNew:
 		// TODO: Open new task dialog
 		return m, nil
 		
-	case "d":
+	case ActionDelete:
 		return m.handleDeleteTask()
 		
-	case "e":
+	case ActionUndo:
+		if m.deleted == nil {
+			return m, nil
+		}
+		restored := m.deleted
+		m.deleted = nil
+		return m, m.restoreTask(restored)
+		
+	case ActionOpen:
+		return m.handleOpen()
+		
+	case ActionEdit:
 		// TODO: Edit task
 		return m, nil
-		
+	}
+	
+	// The digits set a priority directly, unless bound to something else
+	switch msg.String() {
+	case "0", "1", "2", "3", "4":
+		return m.handlePriority(int(msg.String()[0] - '0'))
+	}
+	
 	// Pass other keys to focused viewport for scrolling (pgup/pgdn, etc.)
-	default:
-		vp := m.viewports[m.focused]
-		vp, cmd = vp.Update(msg)
-		m.viewports[m.focused] = vp
-		return m, cmd
+	vp := m.viewports[m.focused]
+	vp, cmd = vp.Update(msg)
+	m.viewports[m.focused] = vp
+	return m, cmd
+}
+
+// handleSearchKeys edits the search query, narrowing the columns as it is
+// typed. Enter keeps the results to work with, esc clears the search.
+func (m Model) handleSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.searching = false
+		m.setQuery("")
+	case tea.KeyEnter:
+		m.searching = false
+	case tea.KeyBackspace:
+		if query := []rune(m.query); len(query) > 0 {
+			m.setQuery(string(query[:len(query)-1]))
+		}
+	case tea.KeyCtrlU:
+		m.setQuery("")
+	case tea.KeyUp:
+		m.moveSelectionUp()
+	case tea.KeyDown:
+		m.moveSelectionDown()
+	case tea.KeyLeft:
+		if m.focused > ColumnTodo {
+			m.focused--
+			m.updateViewportContent()
+		}
+	case tea.KeyRight:
+		if m.focused < ColumnDone {
+			m.focused++
+			m.updateViewportContent()
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.setQuery(m.query + string(msg.Runes))
 	}
 	
 	return m, nil
 }
 
+// setQuery changes the search query and filters the columns again
+func (m *Model) setQuery(query string) {
+	if query == m.query {
+		return
+	}
+	m.query = query
+	for col := range m.selectedTask {
+		m.selectedTask[col] = 0
+	}
+	m.applySearch()
+	m.updateViewportContent()
+}
+
+// applySearch shows the loaded tasks that match the search query in every
+// column, best matches first, using the same scoring as cainban search
+func (m *Model) applySearch() {
+	tasks := make(map[task.Status][]*task.Task, len(m.loaded))
+	for status, statusTasks := range m.loaded {
+		tasks[status] = m.swimlanes.group(task.RankTasks(statusTasks, m.query))
+	}
+	m.tasks = tasks
+	
+	// Keep the selections within the narrowed columns
+	for col, selected := range m.selectedTask {
+		if count := len(m.tasks[m.columnToStatus(col)]); selected >= count {
+			m.selectedTask[col] = 0
+			if count > 0 {
+				m.selectedTask[col] = count - 1
+			}
+		}
+	}
+}
+
 // handleHelpKeys processes keyboard input for the help view
 func (m Model) handleHelpKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -167,6 +399,61 @@ func (m Model) handleTaskDetailKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// cycleContext switches to the next GTD context, returning to all tasks
+// after the last one
+func (m *Model) cycleContext() {
+	next := ""
+	if m.context == "" {
+		if len(m.contexts) > 0 {
+			next = m.contexts[0]
+		}
+	} else {
+		for i, c := range m.contexts {
+			if c == m.context && i+1 < len(m.contexts) {
+				next = m.contexts[i+1]
+			}
+		}
+	}
+	
+	m.context = next
+	for col := range m.selectedTask {
+		m.selectedTask[col] = 0
+	}
+}
+
+// cycleMilestone switches to the next milestone, returning to all tasks
+// after the last one
+func (m *Model) cycleMilestone() {
+	next := 0
+	if m.milestone == 0 {
+		if len(m.milestones) > 0 {
+			next = m.milestones[0].ID
+		}
+	} else {
+		for i, ms := range m.milestones {
+			if ms.ID == m.milestone && i+1 < len(m.milestones) {
+				next = m.milestones[i+1].ID
+			}
+		}
+	}
+	
+	m.milestone = next
+	for col := range m.selectedTask {
+		m.selectedTask[col] = 0
+	}
+}
+
+// activeMilestone returns the milestone the board is filtered by, nil when
+// it shows all tasks
+func (m Model) activeMilestone() *milestone.Milestone {
+	for _, ms := range m.milestones {
+		if ms.ID == m.milestone {
+			return ms
+		}
+	}
+	return nil
+}
+
 // moveSelectionDown moves the selection down in the current column
 func (m *Model) moveSelectionDown() {
 	currentStatus := m.columnToStatus(m.focused)
@@ -222,6 +509,47 @@ func (m Model) handleTaskAction() (tea.Model, tea.Cmd) {
 	return m, m.moveTask(selectedTask.ID, newStatus)
 }
 
+// handleReorder moves the selected task past its neighbor above (-1) or
+// below (1), keeping it selected
+func (m Model) handleReorder(delta int) (tea.Model, tea.Cmd) {
+	// Search results are ranked by match, not in the column's order
+	if m.query != "" {
+		return m, nil
+	}
+	
+	tasks := m.tasks[m.columnToStatus(m.focused)]
+	selectedIndex := m.selectedTask[m.focused]
+	target := selectedIndex + delta
+	if selectedIndex >= len(tasks) || target < 0 || target >= len(tasks) {
+		return m, nil
+	}
+	
+	m.selectedTask[m.focused] = target
+	return m, m.reorderTask(tasks[selectedIndex].ID, tasks[target].ID)
+}
+
+// handlePriority sets the priority of the selected task (0 none to 4
+// critical); -1 raises it one level, wrapping from critical back to none.
+// The task stays selected wherever its new priority puts it.
+func (m Model) handlePriority(priority int) (tea.Model, tea.Cmd) {
+	tasks := m.tasks[m.columnToStatus(m.focused)]
+	selectedIndex := m.selectedTask[m.focused]
+	if selectedIndex >= len(tasks) {
+		return m, nil
+	}
+	
+	selectedTask := tasks[selectedIndex]
+	if priority < 0 {
+		priority = (selectedTask.Priority + 1) % (task.PriorityCritical + 1)
+	}
+	if priority == selectedTask.Priority {
+		return m, nil
+	}
+	
+	m.selectID = selectedTask.ID
+	return m, m.setPriority(selectedTask.ID, priority)
+}
+
 // handleDeleteTask handles deleting the selected task
 func (m Model) handleDeleteTask() (tea.Model, tea.Cmd) {
 	currentStatus := m.columnToStatus(m.focused)
@@ -236,8 +564,27 @@ func (m Model) handleDeleteTask() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	
-	selectedTask := tasks[selectedIndex]
-	return m, m.deleteTask(selectedTask.ID)
+	// Nothing is deleted until the user confirms
+	m.confirmDelete = tasks[selectedIndex]
+	return m, nil
+}
+
+// handleConfirmDeleteKeys answers the delete confirmation: y or enter
+// moves the task to the trash, D deletes it for good and anything else
+// keeps it
+func (m Model) handleConfirmDeleteKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	t := m.confirmDelete
+	m.confirmDelete = nil
+	
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "y", "enter":
+		return m, m.deleteTask(t, false)
+	case "D":
+		return m, m.deleteTask(t, true)
+	}
+	return m, nil
 }
 
 
@@ -3,8 +3,10 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"time"
 	
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/hmain/cainban/src/systems/task"
 )
 
@@ -17,6 +19,8 @@ func (m Model) View() string {
 		return m.renderHelpView()
 	case ViewTaskDetail:
 		return m.renderTaskDetailView()
+	case ViewFocus:
+		return m.renderFocusView()
 	default:
 		return m.renderKanbanView()
 	}
@@ -28,14 +32,59 @@ func (m Model) renderKanbanView() string {
 	
 	// Simple header
 	header := fmt.Sprintf("Cainban - %s", m.currentBoard)
+	if m.context != "" {
+		header += fmt.Sprintf(" [context %s]", m.context)
+	}
+	if ms := m.activeMilestone(); ms != nil {
+		header += fmt.Sprintf(" [milestone %s, %d%% done]", ms.Name, ms.Percent)
+	}
+	if m.recording != 0 {
+		header += fmt.Sprintf(" [recording @%c]", m.recording)
+	}
+	if m.swimlanes != SwimlanesOff {
+		header += fmt.Sprintf(" [lanes by %s]", m.swimlanes)
+	}
+	if m.query != "" && !m.searching {
+		header += fmt.Sprintf(" [search %q]", m.query)
+	}
 	
-	// Render columns using viewports
+	// Render columns using viewports, with the selected task beside them
+	// on wide terminals
 	columns := m.renderViewportColumns()
+	if layout := m.layout(); layout.Detail > 0 {
+		columns = lipgloss.JoinHorizontal(lipgloss.Top, columns, m.renderDetailPane(layout.Detail, layout.ColumnHeight))
+	}
 	
 	// Simple status bar  
-	statusBar := "h/l: columns • j/k: navigate • PgUp/PgDn: scroll • enter: move • q: quit"
+	k := m.keymap
+	statusBar := fmt.Sprintf("%s/%s: columns • %s/%s: navigate • PgUp/PgDn: scroll • %s: move • %s: details • %s: context • %s: search • %s: quit",
+		k.keyNames(ActionLeft, ","), k.keyNames(ActionRight, ","), k.keyNames(ActionDown, ","), k.keyNames(ActionUp, ","),
+		k.keyNames(ActionAdvance, ","), k.keyNames(ActionDetails, ","), k.keyNames(ActionContext, ","), k.keyNames(ActionSearch, ","), k.keyNames(ActionQuit, ","))
+	if m.confirmDelete != nil {
+		danger := lipgloss.NewStyle().Foreground(m.styles.Palette.Danger).Bold(true)
+		statusBar = danger.Render(fmt.Sprintf("Delete #%d %q?", m.confirmDelete.ID, m.confirmDelete.Title)) + "  " +
+			lipgloss.NewStyle().Foreground(m.styles.Palette.Muted).Render("y: move to trash • D: delete for good • n/esc: keep it")
+	} else if m.openChoices != nil {
+		statusBar = m.renderOpenChoices()
+	} else if m.searching {
+		prompt := lipgloss.NewStyle().Foreground(m.styles.Palette.Primary).Bold(true)
+		statusBar = prompt.Render("/") + m.query + "█  " +
+			lipgloss.NewStyle().Foreground(m.styles.Palette.Muted).Render("type to filter • ↑/↓/←/→: select • enter: done • esc: clear")
+	} else if m.busy != nil {
+		statusBar = m.renderBusy()
+	} else if m.notice != "" {
+		statusBar = lipgloss.NewStyle().Foreground(m.styles.Palette.Warning).Render(m.notice)
+	} else if m.query != "" {
+		statusBar = k.keyNames(ActionClear, ",") + ": clear search • " + statusBar
+	}
 	
-	// Simple layout - no complex styling for now
+	// Simple layout - no complex styling for now. The header and status
+	// bar are cut to the terminal, as a wrapped line would push the board
+	// off the top.
+	if m.width > 0 {
+		header = ansi.Truncate(header, m.width, "…")
+		statusBar = ansi.Truncate(statusBar, m.width, "…")
+	}
 	content := header + "\n\n" + columns + "\n\n" + statusBar
 	
 	debugLog("[RENDER] Viewport-based rendering complete\n")
@@ -69,18 +118,20 @@ func (m Model) renderViewportColumn(col Column, title string) string {
 	tasks := m.tasks[status]
 	titleWithCount := fmt.Sprintf("%s (%d)", title, len(tasks))
 	
-	// Simple column style
+	// Simple column style, all columns as tall as the layout allows
+	layout := m.layout()
 	columnStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		Padding(1).
 		Margin(0, 1).
-		Width(m.calculateColumnWidth())
+		Width(layout.ContentWidth(col)).
+		Height(layout.ColumnHeight - 2)
 	
 	// Highlight focused column
 	if col == m.focused {
-		columnStyle = columnStyle.BorderForeground(lipgloss.Color("#7C3AED"))
+		columnStyle = columnStyle.BorderForeground(m.styles.Palette.Primary)
 	} else {
-		columnStyle = columnStyle.BorderForeground(lipgloss.Color("#4B5563"))
+		columnStyle = columnStyle.BorderForeground(m.styles.Palette.Border)
 	}
 	
 	// Get viewport content
@@ -99,8 +150,19 @@ func (m Model) renderViewportColumn(col Column, title string) string {
 		}
 	}
 	
-	// Combine title, scroll info, and viewport content
-	header := titleWithCount + scrollInfo
+	// Combine title, scroll info, and viewport content, each header line
+	// cut to the column so that it does not wrap
+	header := ansi.Truncate(titleWithCount+scrollInfo, vp.Width, "…")
+	
+	// The focused column shows its definition, like a tooltip
+	if note, ok := m.columnNotes[status]; ok && col == m.focused {
+		noteText := ansi.Truncate(strings.ReplaceAll(note.Note, "\n", " "), vp.Width, "...")
+		header += "\n" + lipgloss.NewStyle().
+			Foreground(m.styles.Palette.Muted).
+			Italic(true).
+			Render(noteText)
+	}
+	
 	content := header + "\n\n" + viewportContent
 	
 	debugLog("[VIEWPORT] Column %d: title=%s, viewport_lines=%d\n", 
@@ -173,7 +235,7 @@ func (m Model) renderColumn(col Column, title string, status task.Status) string
 	// Tasks with virtual scrolling
 	if len(tasks) == 0 {
 		emptyMsg := m.styles.Task.Copy().
-			Foreground(lipgloss.Color("#6B7280")).
+			Foreground(m.styles.Palette.Muted).
 			Italic(true).
 			Render("No tasks")
 		content = append(content, emptyMsg)
@@ -186,7 +248,7 @@ func (m Model) renderColumn(col Column, title string, status task.Status) string
 		// Add scroll indicators if needed
 		if startIndex > 0 {
 			scrollIndicator := m.styles.Task.Copy().
-				Foreground(lipgloss.Color("#6B7280")).
+				Foreground(m.styles.Palette.Muted).
 				Italic(true).
 				Render("▲ (" + fmt.Sprintf("%d more above", startIndex) + ")")
 			content = append(content, scrollIndicator)
@@ -204,7 +266,7 @@ func (m Model) renderColumn(col Column, title string, status task.Status) string
 		if endIndex < len(tasks) {
 			remaining := len(tasks) - endIndex
 			scrollIndicator := m.styles.Task.Copy().
-				Foreground(lipgloss.Color("#6B7280")).
+				Foreground(m.styles.Palette.Muted).
 				Italic(true).
 				Render("▼ (" + fmt.Sprintf("%d more below", remaining) + ")")
 			content = append(content, scrollIndicator)
@@ -218,7 +280,7 @@ func (m Model) renderColumn(col Column, title string, status task.Status) string
 	columnStyle := m.styles.Column
 	if col == m.focused {
 		columnStyle = columnStyle.Copy().
-			BorderForeground(lipgloss.Color("#7C3AED")).
+			BorderForeground(m.styles.Palette.Primary).
 			BorderStyle(lipgloss.ThickBorder())
 	}
 	
@@ -238,10 +300,27 @@ func (m Model) renderTask(t *task.Task, selected bool) string {
 		maxTitleLength = 15 // Minimum readable length
 	}
 	
+	// Tasks waiting on unfinished blockers get a badge in front of the title
+	badge := ""
+	if t.IsBlocked() {
+		badge = "🚫 "
+		maxTitleLength -= 3
+	}
+	
 	title := t.Title
 	if len(title) > maxTitleLength {
 		title = title[:maxTitleLength-3] + "..."
 	}
+	title = badge + title
+	if len(t.Reactions) > 0 {
+		title += "  " + task.FormatReactions(t.Reactions)
+	}
+	if t.Rollup != nil {
+		title += " [" + t.Rollup.String() + "]"
+	}
+	if t.IsStale(time.Now(), m.staleAfter) {
+		title += " 💤"
+	}
 	
 	// Task content
 	taskContent := fmt.Sprintf("%s %s", priority, title)
@@ -266,15 +345,17 @@ func (m Model) renderStatusBar() string {
 	
 	switch m.currentView {
 	case ViewKanban:
+		k := m.keymap
 		help = append(help, 
-			"h/l: columns", 
-			"j/k: navigate tasks",
-			"enter: move task",
-			"n: new task",
-			"d: delete",
-			"r: refresh",
-			"?: help",
-			"q: quit",
+			k.keyNames(ActionLeft, ",")+"/"+k.keyNames(ActionRight, ",")+": columns", 
+			k.keyNames(ActionDown, ",")+"/"+k.keyNames(ActionUp, ",")+": navigate tasks",
+			k.keyNames(ActionAdvance, ",")+": move task",
+			k.keyNames(ActionPriority, ",")+": priority",
+			k.keyNames(ActionNew, ",")+": new task",
+			k.keyNames(ActionDelete, ",")+": delete",
+			k.keyNames(ActionRefresh, ",")+": refresh",
+			k.keyNames(ActionHelp, ",")+": help",
+			k.keyNames(ActionQuit, ",")+": quit",
 		)
 	}
 	
@@ -284,30 +365,32 @@ func (m Model) renderStatusBar() string {
 
 // renderHelpView renders the help screen
 func (m Model) renderHelpView() string {
-	helpContent := `
-Cainban - Terminal Kanban Board
-
-NAVIGATION:
-  h, ←     Move to left column
-  l, →     Move to right column  
-  j, ↓     Navigate down in current column (auto-scroll)
-  k, ↑     Navigate up in current column (auto-scroll)
-  PgUp     Scroll viewport up
-  PgDn     Scroll viewport down
-  Home     Go to top of column
-  End      Go to bottom of column
-
-TASK ACTIONS:
-  enter    Move task to next status (todo → doing → done)
-  n        Create new task
-  e        Edit selected task
-  d        Delete selected task
-  
-OTHER:
-  r        Refresh tasks from database
-  ?        Show/hide this help
-  q, ^C    Quit application
-
+	var b strings.Builder
+	b.WriteString("\nCainban - Terminal Kanban Board\n")
+	line := func(keys, help string) {
+		fmt.Fprintf(&b, "  %-10s %s\n", keys, help)
+	}
+	
+	// The bindings come from the keymap, so they are the ones in effect
+	section := ""
+	for _, entry := range actionHelp {
+		if entry.section != section {
+			if section == "NAVIGATION" {
+				line("PgUp", "Scroll viewport up")
+				line("PgDn", "Scroll viewport down")
+				line("Home", "Go to top of column")
+				line("End", "Go to bottom of column")
+			}
+			section = entry.section
+			fmt.Fprintf(&b, "\n%s:\n", section)
+		}
+		line(m.keymap.keyNames(entry.action, ", "), entry.help)
+		if entry.action == ActionPriority {
+			line("0-4", "Set the task's priority: 0 none, 1 low, 2 medium, 3 high, 4 critical")
+		}
+	}
+	
+	b.WriteString(`
 COLUMNS:
   📝 Todo    Tasks that need to be done
   🔄 Doing   Tasks currently in progress  
@@ -321,10 +404,10 @@ PRIORITY INDICATORS:
   🔥 (red) Critical priority
 
 Press any key to return to the kanban board...
-`
+`)
 
 	return m.styles.Base.Render(
-		m.styles.Help.Render(helpContent),
+		m.styles.Help.Render(b.String()),
 	)
 }
 